@@ -139,4 +139,4 @@ func main() {
 	// 10. 清理
 	time.Sleep(1 * time.Second)
 	fmt.Println("✅ CRD监控演示完成！")
-}
\ No newline at end of file
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yourusername/k8s-llm-monitor/internal/cli"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+)
+
+// 探测请求体的大小上限
+const maxProbeBody = 4 << 10
+
+func main() {
+	cmd := &cobra.Command{
+		Use:          "probe-agent",
+		Short:        "Network probe agent: runs RTT/HTTP/DNS probes on behalf of the monitor server",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := logging.Configure(config.LoggingConfig{
+				Level:  viper.GetString("log-level"),
+				Format: viper.GetString("log-format"),
+			}, "probe-agent"); err != nil {
+				return err
+			}
+			run(viper.GetInt("port"), strings.TrimSpace(viper.GetString("token")))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Int("port", probe.DefaultPort, "HTTP server port")
+	flags.String("token", "", "Bearer token required from the monitor server (empty disables authentication)")
+	flags.String("log-level", "info", "Log level (debug, info, warn, error)")
+	flags.String("log-format", "json", "Log format (json or text)")
+	if err := cli.BindEnvFlags(flags, map[string]string{
+		"port":       "PROBE_PORT",
+		"token":      "PROBE_TOKEN",
+		"log-level":  "LOG_LEVEL",
+		"log-format": "LOG_FORMAT",
+	}); err != nil {
+		log.Fatalf("Failed to bind flags: %v", err)
+	}
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run 启动探测Agent
+func run(port int, token string) {
+	nodeName := os.Getenv("NODE_NAME")
+	if nodeName == "" {
+		nodeName = "unknown-node"
+	}
+
+	log.Printf("Starting probe agent...")
+	log.Printf("Node: %s", nodeName)
+	log.Printf("Port: %d", port)
+	if token == "" {
+		log.Printf("Warning: PROBE_TOKEN not set, probe API is unauthenticated")
+	}
+
+	mux := http.NewServeMux()
+
+	// 健康检查
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "healthy",
+			"node_name": nodeName,
+			"timestamp": time.Now(),
+		})
+	})
+
+	// 执行一次探测
+	mux.HandleFunc("/api/v1/probe", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" && !validToken(r, token) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req probe.Request
+		if err := httpjson.Decode(w, r, &req, maxProbeBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%v", err))
+			return
+		}
+
+		result := probe.Run(r.Context(), req)
+		result.Node = nodeName
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   result,
+		})
+	})
+
+	server := &http.Server{
+		Addr:         ":" + strconv.Itoa(port),
+		Handler:      mux,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: probe.MaxTimeout*probe.MaxCount + 15*time.Second,
+	}
+
+	go func() {
+		log.Printf("HTTP Server starting on port %d", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	// 优雅关闭
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down probe agent...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("Probe agent exited")
+}
+
+// validToken 检查请求的Bearer token
+func validToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -2,14 +2,16 @@ package main
 
 import (
 	"context"
-	"flag"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/yourusername/k8s-llm-monitor/internal/cli"
 	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
 	"github.com/yourusername/k8s-llm-monitor/internal/scheduler"
 
 	"k8s.io/client-go/dynamic"
@@ -18,16 +20,42 @@ import (
 )
 
 func main() {
-	var configPath string
+	var opts cli.ConfigOptions
 	var interval time.Duration
-	flag.StringVar(&configPath, "config", "./configs/config.yaml", "config file path")
-	flag.DurationVar(&interval, "interval", 15*time.Second, "scheduling reconcile interval")
-	flag.Parse()
 
-	cfg, err := config.Load(configPath)
+	cmd := &cobra.Command{
+		Use:   "scheduler",
+		Short: "SchedulingRequest controller",
+		Long: `SchedulingRequest controller.
+
+Every config field can be overridden with a flag of the same dotted name,
+e.g. --k8s.kubeconfig=~/.kube/config.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			run(&opts, interval)
+			return nil
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&interval, "interval", 15*time.Second, "scheduling reconcile interval")
+	if err := cli.BindConfigFlags(cmd.Flags()); err != nil {
+		log.Fatalf("Failed to register config flags: %v", err)
+	}
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run 启动调度控制器
+func run(opts *cli.ConfigOptions, interval time.Duration) {
+	cfg, err := opts.Load(context.Background())
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := logging.Configure(cfg.Logging, "scheduler"); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
 
 	k8sClient, err := k8s.NewClient(&cfg.K8s)
 	if err != nil {
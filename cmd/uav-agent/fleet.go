@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+)
+
+// homeSpacing 同一个Agent模拟的相邻两架UAV起飞点之间的距离（米）
+const homeSpacing = 30.0
+
+// fleet 一个Agent模拟的UAV，下标即UAV的序号（上报中的 index、接口的 uav 查询参数）
+type fleet []*uav.MAVLinkSimulator
+
+// newFleet 创建 count 架UAV：第一架沿用节点的UAV ID和随机起飞点，其余的ID加上序号，
+// 起飞点依次向东偏移 homeSpacing 米，飞行参数按序号轮流使用内置参数
+func newFleet(uavID, nodeName string, count int, fences []uav.Geofence) (fleet, error) {
+	if count < 1 || count > models.MaxUAVsPerAgent {
+		return nil, fmt.Errorf("uav count must be between 1 and %d, got %d", models.MaxUAVsPerAgent, count)
+	}
+
+	uavs := make(fleet, count)
+	uavs[0] = uav.NewMAVLinkSimulator(uavID, nodeName)
+	first := uavs[0].GetState().GPS
+	base := uav.GeoPoint{Latitude: first.Latitude, Longitude: first.Longitude}
+	for i := 1; i < count; i++ {
+		home := base.Offset(0, float64(i)*homeSpacing)
+		uavs[i] = uav.NewMAVLinkSimulatorWithOptions(fmt.Sprintf("%s-%d", uavID, i), nodeName, uav.SimulatorOptions{
+			Home:    &home,
+			Profile: uav.FlightProfiles[i%len(uav.FlightProfiles)],
+		})
+	}
+
+	if len(fences) > 0 {
+		for _, simulator := range uavs {
+			if err := simulator.SetGeofences(fences); err != nil {
+				return nil, fmt.Errorf("failed to set geofences: %w", err)
+			}
+		}
+	}
+	return uavs, nil
+}
+
+// pick 按查询参数 uav 选择请求操作的UAV（省略时为第一架），序号无效时返回错误
+func (f fleet) pick(r *http.Request) (*uav.MAVLinkSimulator, error) {
+	value := r.URL.Query().Get("uav")
+	if value == "" {
+		return f[0], nil
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil || index < 0 || index >= len(f) {
+		return nil, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found",
+			Message: fmt.Sprintf("uav must be an index between 0 and %d, got %q", len(f)-1, value)}
+	}
+	return f[index], nil
+}
+
+// handle 选择请求操作的UAV后调用 handler
+func (f fleet) handle(handler func(http.ResponseWriter, *http.Request, *uav.MAVLinkSimulator)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		simulator, err := f.pick(r)
+		if err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		handler(w, r, simulator)
+	}
+}
+
+// summary 各架UAV的序号、ID、飞行参数和当前飞行状态
+func (f fleet) summary() []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(f))
+	for i, simulator := range f {
+		state := simulator.GetState()
+		result = append(result, map[string]interface{}{
+			"index":             i,
+			"uav_id":            state.UAVID,
+			"profile":           state.Profile,
+			"mode":              state.Flight.Mode,
+			"armed":             state.Flight.Armed,
+			"latitude":          state.GPS.Latitude,
+			"longitude":         state.GPS.Longitude,
+			"relative_altitude": state.GPS.RelativeAltitude,
+			"battery_percent":   state.Battery.RemainingPercent,
+		})
+	}
+	return result
+}
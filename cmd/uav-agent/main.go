@@ -1,12 +1,11 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
@@ -15,36 +14,186 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yourusername/k8s-llm-monitor/internal/cli"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/mtls"
+	"github.com/yourusername/k8s-llm-monitor/internal/uavagent"
 	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
 )
 
+// 控制命令的请求限制
+const (
+	maxCommandBody = 64 << 10
+	maxMissionBody = 1 << 20
+)
+
 func main() {
-	var port int
-	var masterURL string
-	var reportInterval time.Duration
+	cmd := &cobra.Command{
+		Use:          "uav-agent",
+		Short:        "UAV agent: MAVLink simulator with telemetry reporting",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tlsOpts := mtls.Options{
+				CertFile:    viper.GetString("tls-cert"),
+				KeyFile:     viper.GetString("tls-key"),
+				CAFile:      viper.GetString("tls-ca"),
+				AllowedSANs: viper.GetStringSlice("tls-master-sans"),
+			}
+			if err := logging.Configure(config.LoggingConfig{
+				Level:  viper.GetString("log-level"),
+				Format: viper.GetString("log-format"),
+			}, "uav-agent"); err != nil {
+				return err
+			}
+			signingKey, err := loadSigningKey(viper.GetString("signing-key"), viper.GetString("signing-key-file"))
+			if err != nil {
+				return err
+			}
+			var fences []uav.Geofence
+			if file := viper.GetString("geofence-file"); file != "" {
+				if fences, err = loadGeofences(file); err != nil {
+					return err
+				}
+			}
+			run(viper.GetInt("port"), viper.GetString("master-url"), viper.GetDuration("report-interval"), tlsOpts, signingKey, fences, viper.GetInt("uav-count"))
+			return nil
+		},
+	}
 
-	flag.IntVar(&port, "port", 9090, "HTTP server port")
-	flag.StringVar(&masterURL, "master-url", "", "Master server base URL for UAV reports")
-	flag.DurationVar(&reportInterval, "report-interval", 0, "Interval for uploading UAV telemetry")
-	flag.Parse()
+	flags := cmd.Flags()
+	flags.Int("port", 9090, "HTTP server port")
+	flags.String("master-url", "", "Master server base URL for UAV reports")
+	flags.Duration("report-interval", 0, "Interval for uploading UAV telemetry")
+	flags.String("tls-cert", "", "Certificate file; enables mTLS for the agent API and reports")
+	flags.String("tls-key", "", "Private key file for --tls-cert")
+	flags.String("tls-ca", "", "CA bundle used to verify the master")
+	flags.StringSlice("tls-master-sans", nil, "SAN patterns accepted for the master (report target and command callers)")
+	flags.String("signing-key", "", "Per-node HMAC key used to sign telemetry reports")
+	flags.String("signing-key-file", "", "File containing the per-node HMAC signing key (e.g. a mounted Secret)")
+	flags.String("geofence-file", "", "JSON file with the geofence polygons/cylinders the UAV must stay inside")
+	flags.Int("uav-count", 1, "Number of UAVs to simulate, each with its own ID, home position and flight profile")
+	flags.String("log-level", "info", "Log level (debug, info, warn, error)")
+	flags.String("log-format", "json", "Log format (json or text)")
+	if err := cli.BindEnvFlags(flags, map[string]string{
+		"master-url":       "MASTER_URL",
+		"report-interval":  "REPORT_INTERVAL",
+		"tls-cert":         "TLS_CERT_FILE",
+		"tls-key":          "TLS_KEY_FILE",
+		"tls-ca":           "TLS_CA_FILE",
+		"tls-master-sans":  "TLS_MASTER_SANS",
+		"signing-key":      "UAV_SIGNING_KEY",
+		"signing-key-file": "UAV_SIGNING_KEY_FILE",
+		"geofence-file":    "GEOFENCE_FILE",
+		"uav-count":        "UAV_COUNT",
+		"log-level":        "LOG_LEVEL",
+		"log-format":       "LOG_FORMAT",
+	}); err != nil {
+		log.Fatalf("Failed to bind flags: %v", err)
+	}
+	if err := viper.BindPFlag("port", flags.Lookup("port")); err != nil {
+		log.Fatalf("Failed to bind flags: %v", err)
+	}
 
-	if masterURL == "" {
-		masterURL = os.Getenv("MASTER_URL")
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
 	}
+}
 
-	masterURL = strings.TrimSpace(masterURL)
-	if masterURL != "" && !strings.HasPrefix(masterURL, "http://") && !strings.HasPrefix(masterURL, "https://") {
-		masterURL = "http://" + masterURL
+// writeMissionResult 返回任务命令的结果和当前的任务进度
+func writeMissionResult(w http.ResponseWriter, simulator *uav.MAVLinkSimulator, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": message,
+		"data":    simulator.GetMissionProgress(),
+	})
+}
+
+// writeGeofences 返回当前的地理围栏和UAV相对围栏的状态
+func writeGeofences(w http.ResponseWriter, simulator *uav.MAVLinkSimulator, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	response := map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"fences": simulator.GetGeofences(),
+			"status": simulator.GetState().Geofence,
+		},
 	}
+	if message != "" {
+		response["message"] = message
+	}
+	json.NewEncoder(w).Encode(response)
+}
 
-	if reportInterval <= 0 {
-		if envInterval := strings.TrimSpace(os.Getenv("REPORT_INTERVAL")); envInterval != "" {
-			if parsed, err := time.ParseDuration(envInterval); err == nil {
-				reportInterval = parsed
-			} else {
-				log.Printf("Invalid REPORT_INTERVAL value %q: %v", envInterval, err)
-			}
+// loadGeofences 从JSON文件读取地理围栏列表
+func loadGeofences(path string) ([]uav.Geofence, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geofence file: %w", err)
+	}
+	var fences []uav.Geofence
+	if err := json.Unmarshal(data, &fences); err != nil {
+		return nil, fmt.Errorf("invalid geofence file %s: %w", path, err)
+	}
+	if err := uav.ValidateGeofences(fences); err != nil {
+		return nil, fmt.Errorf("invalid geofence file %s: %w", path, err)
+	}
+	return fences, nil
+}
+
+// writeCommandError 将控制和任务命令的错误映射为HTTP状态码：参数或航点不合法为400，
+// 解锁/起飞条件不满足（GPS、电量、传感器、地理围栏）为422，当前状态不允许（未解锁、仍在空中、任务状态）为409
+func writeCommandError(w http.ResponseWriter, err error) {
+	apiErr := &httpjson.Error{Status: http.StatusConflict, Message: err.Error()}
+	switch {
+	case errors.Is(err, uav.ErrInvalidCommand), errors.Is(err, uav.ErrInvalidMission):
+		apiErr.Status, apiErr.Code = http.StatusBadRequest, httpjson.CodeInvalidRequest
+	case errors.Is(err, uav.ErrNoGPSFix):
+		apiErr.Status, apiErr.Code = http.StatusUnprocessableEntity, "no_gps_fix"
+	case errors.Is(err, uav.ErrLowBattery):
+		apiErr.Status, apiErr.Code = http.StatusUnprocessableEntity, "low_battery"
+	case errors.Is(err, uav.ErrSensorsUnhealthy):
+		apiErr.Status, apiErr.Code = http.StatusUnprocessableEntity, "sensors_unhealthy"
+	case errors.Is(err, uav.ErrOutsideGeofence):
+		apiErr.Status, apiErr.Code = http.StatusUnprocessableEntity, "outside_geofence"
+	case errors.Is(err, uav.ErrNotArmed):
+		apiErr.Code = "not_armed"
+	case errors.Is(err, uav.ErrInFlight):
+		apiErr.Code = "in_flight"
+	case errors.Is(err, uav.ErrNoMission), errors.Is(err, uav.ErrMissionInProgress), errors.Is(err, uav.ErrMissionNotActive):
+		apiErr.Code = "mission_state"
+	default:
+		apiErr.Status, apiErr.Code = http.StatusInternalServerError, "internal_error"
+	}
+	httpjson.WriteError(w, apiErr)
+}
+
+// loadSigningKey 读取上报签名密钥，文件优先于直接传入的值
+func loadSigningKey(key, file string) (string, error) {
+	if file == "" {
+		return strings.TrimSpace(key), nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read signing key: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// run 启动UAV Agent
+func run(port int, masterURL string, reportInterval time.Duration, tlsOpts mtls.Options, signingKey string, fences []uav.Geofence, uavCount int) {
+	masterURL = strings.TrimSpace(masterURL)
+	if masterURL != "" && !strings.HasPrefix(masterURL, "http://") && !strings.HasPrefix(masterURL, "https://") {
+		if tlsOpts.CertFile != "" {
+			masterURL = "https://" + masterURL
+		} else {
+			masterURL = "http://" + masterURL
 		}
 	}
 
@@ -73,9 +222,30 @@ func main() {
 	log.Printf("Port: %d", port)
 
 	// 创建MAVLink模拟器
-	simulator := uav.NewMAVLinkSimulator(uavID, nodeName)
-	simulator.Start()
-	log.Printf("MAVLink simulator started")
+	uavs, err := newFleet(uavID, nodeName, uavCount, fences)
+	if err != nil {
+		log.Fatalf("Failed to create UAV simulators: %v", err)
+	}
+	if len(fences) > 0 {
+		log.Printf("Geofence enabled: %d fences", len(fences))
+	}
+	for _, simulator := range uavs {
+		simulator.Start()
+	}
+	log.Printf("MAVLink simulator started (%d UAVs)", len(uavs))
+
+	// mTLS：命令接口只接受Master的证书
+	var serverTLS *tls.Config
+	command := func(handler http.HandlerFunc) http.HandlerFunc { return handler }
+	if tlsOpts.CertFile != "" {
+		if serverTLS, err = mtls.ServerConfig(tlsOpts, tls.VerifyClientCertIfGiven); err != nil {
+			log.Fatalf("Failed to set up mTLS: %v", err)
+		}
+		command = func(handler http.HandlerFunc) http.HandlerFunc {
+			return mtls.RequireSAN(tlsOpts.AllowedSANs, handler)
+		}
+		log.Printf("mTLS enabled (master SANs: %v)", tlsOpts.AllowedSANs)
+	}
 
 	// 设置HTTP路由
 	mux := http.NewServeMux()
@@ -86,14 +256,29 @@ func main() {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":    "healthy",
 			"uav_id":    uavID,
+			"uav_count": len(uavs),
 			"node_name": nodeName,
 			"node_ip":   nodeIP,
 			"timestamp": time.Now(),
 		})
 	})
 
+	// 本Agent模拟的所有UAV，其他接口通过查询参数 uav=<序号> 选择UAV（省略时为第一架）
+	mux.HandleFunc("/api/v1/uavs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   uavs.summary(),
+		})
+	})
+
 	// 获取完整状态
-	mux.HandleFunc("/api/v1/state", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/state", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
@@ -106,10 +291,10 @@ func main() {
 			"status": "success",
 			"data":   state,
 		})
-	})
+	}))
 
 	// 获取GPS数据
-	mux.HandleFunc("/api/v1/gps", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/gps", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		state := simulator.GetState()
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -117,10 +302,10 @@ func main() {
 			"status": "success",
 			"data":   state.GPS,
 		})
-	})
+	}))
 
 	// 获取姿态数据
-	mux.HandleFunc("/api/v1/attitude", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/attitude", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		state := simulator.GetState()
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -128,10 +313,10 @@ func main() {
 			"status": "success",
 			"data":   state.Attitude,
 		})
-	})
+	}))
 
 	// 获取电池数据
-	mux.HandleFunc("/api/v1/battery", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/battery", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		state := simulator.GetState()
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -139,10 +324,10 @@ func main() {
 			"status": "success",
 			"data":   state.Battery,
 		})
-	})
+	}))
 
 	// 获取飞行数据
-	mux.HandleFunc("/api/v1/flight", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/flight", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		state := simulator.GetState()
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -150,51 +335,48 @@ func main() {
 			"status": "success",
 			"data":   state.Flight,
 		})
-	})
+	}))
 
 	// 控制接口 - 解锁
-	mux.HandleFunc("/api/v1/command/arm", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/arm", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		err := simulator.Arm()
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-
-		if err != nil {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"status":  "error",
-				"message": err.Error(),
-			})
+		if err := simulator.Arm(); err != nil {
+			writeCommandError(w, err)
 			return
 		}
-
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": "Armed successfully",
 		})
-	})
+	})))
 
 	// 控制接口 - 上锁
-	mux.HandleFunc("/api/v1/command/disarm", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/disarm", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		simulator.Disarm()
+		if err := simulator.Disarm(); err != nil {
+			writeCommandError(w, err)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": "Disarmed successfully",
 		})
-	})
+	})))
 
 	// 控制接口 - 起飞
-	mux.HandleFunc("/api/v1/command/takeoff", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/takeoff", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
@@ -204,58 +386,66 @@ func main() {
 			Altitude float64 `json:"altitude"`
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := httpjson.Decode(w, r, &req, maxCommandBody); err != nil {
+			httpjson.WriteError(w, err)
 			return
 		}
 
 		if req.Altitude == 0 {
 			req.Altitude = 50.0 // 默认高度50米
 		}
-
-		simulator.TakeOff(req.Altitude)
+		if err := simulator.TakeOff(req.Altitude); err != nil {
+			writeCommandError(w, err)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": fmt.Sprintf("Taking off to %.1fm", req.Altitude),
 		})
-	})
+	})))
 
 	// 控制接口 - 降落
-	mux.HandleFunc("/api/v1/command/land", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/land", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		simulator.Land()
+		if err := simulator.Land(); err != nil {
+			writeCommandError(w, err)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": "Landing initiated",
 		})
-	})
+	})))
 
 	// 控制接口 - 返航
-	mux.HandleFunc("/api/v1/command/rtl", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/rtl", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		simulator.ReturnToLaunch()
+		if err := simulator.ReturnToLaunch(); err != nil {
+			writeCommandError(w, err)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": "Returning to launch",
 		})
-	})
+	})))
 
 	// 控制接口 - 设置飞行模式
-	mux.HandleFunc("/api/v1/command/mode", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/command/mode", command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
@@ -265,19 +455,103 @@ func main() {
 			Mode string `json:"mode"`
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := httpjson.Decode(w, r, &req, maxCommandBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		if req.Mode == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("mode is required"))
 			return
 		}
 
-		simulator.SetFlightMode(req.Mode)
+		if err := simulator.SetFlightMode(req.Mode); err != nil {
+			writeCommandError(w, err)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "success",
 			"message": fmt.Sprintf("Flight mode set to %s", req.Mode),
 		})
-	})
+	})))
+
+	// 任务接口 - GET查询任务进度，POST上传航点列表（替换之前的任务）
+	uploadMission := command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
+		var req struct {
+			Waypoints []uav.Waypoint `json:"waypoints"`
+		}
+		if err := httpjson.Decode(w, r, &req, maxMissionBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := simulator.UploadMission(req.Waypoints); err != nil {
+			writeCommandError(w, err)
+			return
+		}
+		writeMissionResult(w, simulator, fmt.Sprintf("Mission uploaded: %d waypoints", len(req.Waypoints)))
+	}))
+	mux.HandleFunc("/api/v1/mission", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "success",
+				"data":   simulator.GetMissionProgress(),
+			})
+		case http.MethodPost:
+			uploadMission(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// 任务接口 - 开始（或恢复）、暂停、中止
+	for action, run := range map[string]func(*uav.MAVLinkSimulator) error{
+		"start": (*uav.MAVLinkSimulator).StartMission,
+		"pause": (*uav.MAVLinkSimulator).PauseMission,
+		"abort": (*uav.MAVLinkSimulator).AbortMission,
+	} {
+		mux.HandleFunc("/api/v1/mission/"+action, command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			if err := run(simulator); err != nil {
+				writeCommandError(w, err)
+				return
+			}
+			writeMissionResult(w, simulator, "Mission "+action+" accepted")
+		})))
+	}
+
+	// 地理围栏接口 - GET查询围栏和当前状态，POST替换围栏（空列表关闭围栏检查）
+	setGeofences := command(uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
+		var req struct {
+			Fences []uav.Geofence `json:"fences"`
+		}
+		if err := httpjson.Decode(w, r, &req, maxMissionBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := simulator.SetGeofences(req.Fences); err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		writeGeofences(w, simulator, fmt.Sprintf("Geofence updated: %d fences", len(req.Fences)))
+	}))
+	mux.HandleFunc("/api/v1/geofence", uavs.handle(func(w http.ResponseWriter, r *http.Request, simulator *uav.MAVLinkSimulator) {
+		switch r.Method {
+		case http.MethodGet:
+			writeGeofences(w, simulator, "")
+		case http.MethodPost:
+			setGeofences(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -285,12 +559,20 @@ func main() {
 		Handler:      mux,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
+		TLSConfig:    serverTLS,
 	}
 
 	// 启动服务器
 	go func() {
-		log.Printf("HTTP Server starting on port %d", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if serverTLS != nil {
+			log.Printf("HTTPS Server starting on port %d", port)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("HTTP Server starting on port %d", port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed to start: %v", err)
 		}
 	}()
@@ -300,7 +582,27 @@ func main() {
 
 	if masterURL != "" {
 		log.Printf("Telemetry reporting enabled: %s (interval %s)", masterURL, reportInterval)
-		go startUAVReportLoop(reportCtx, masterURL, reportInterval, nodeName, nodeIP, uavID, simulator)
+		client := &http.Client{Timeout: 15 * time.Second}
+		if tlsOpts.CertFile != "" {
+			var err error
+			if client, err = mtls.HTTPClient(tlsOpts, 15*time.Second); err != nil {
+				log.Fatalf("Failed to create mTLS client: %v", err)
+			}
+		}
+		if signingKey != "" {
+			log.Printf("Telemetry reports are signed with the node key")
+		}
+		for index, simulator := range uavs {
+			go uavagent.RunReportLoop(reportCtx, uavagent.ReportOptions{
+				Client:     client,
+				MasterURL:  masterURL,
+				Interval:   reportInterval,
+				NodeName:   nodeName,
+				NodeIP:     nodeIP,
+				Index:      index,
+				SigningKey: signingKey,
+			}, simulator)
+		}
 	} else {
 		log.Printf("Master URL not configured. Telemetry reporting disabled")
 	}
@@ -312,7 +614,9 @@ func main() {
 	log.Println("Shutting down UAV agent...")
 	reportCancel()
 
-	simulator.Stop()
+	for _, simulator := range uavs {
+		simulator.Stop()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -322,95 +626,3 @@ func main() {
 
 	log.Println("UAV agent exited")
 }
-
-func startUAVReportLoop(ctx context.Context, masterURL string, interval time.Duration, nodeName, nodeIP, uavID string, simulator *uav.MAVLinkSimulator) {
-	if interval <= 0 {
-		interval = 15 * time.Second
-	}
-
-	endpoint := strings.TrimRight(masterURL, "/") + "/api/v1/uav/report"
-	heartbeatSeconds := int(interval.Seconds())
-	if heartbeatSeconds <= 0 {
-		heartbeatSeconds = 15
-	}
-
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	sendReport := func() {
-		if err := ctx.Err(); err != nil {
-			return
-		}
-
-		reportCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
-
-		state := simulator.GetState()
-		stateCopy := state
-
-		report := models.UAVReport{
-			NodeName:                 nodeName,
-			NodeIP:                   nodeIP,
-			UAVID:                    uavID,
-			Source:                   "agent",
-			Status:                   "active",
-			Timestamp:                time.Now().UTC(),
-			HeartbeatIntervalSeconds: heartbeatSeconds,
-			State:                    &stateCopy,
-			Metadata: map[string]string{
-				"agent": "go-uav-agent",
-			},
-		}
-
-		if report.NodeName == "" {
-			report.NodeName = "unknown-node"
-		}
-		if report.UAVID == "" {
-			report.UAVID = fmt.Sprintf("UAV-%s", report.NodeName)
-		}
-
-		payload, err := json.Marshal(report)
-		if err != nil {
-			log.Printf("Failed to marshal UAV report: %v", err)
-			return
-		}
-
-		req, err := http.NewRequestWithContext(reportCtx, http.MethodPost, endpoint, bytes.NewReader(payload))
-		if err != nil {
-			log.Printf("Failed to create UAV report request: %v", err)
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Failed to send UAV report to %s: %v", endpoint, err)
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode >= 300 {
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-			log.Printf("UAV report rejected (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
-			return
-		}
-
-		log.Printf("UAV report delivered (status %s)", resp.Status)
-	}
-
-	sendReport()
-
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("UAV report loop stopped")
-			return
-		case <-ticker.C:
-			sendReport()
-		}
-	}
-}
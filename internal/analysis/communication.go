@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// InterpretRequest LLM解读通信分析的选项
+type InterpretRequest struct {
+	Profile string            // 指定LLM配置，可选
+	Stream  llm.StreamHandler // 非空时流式调用模型，逐段回调模型输出
+}
+
+// InterpretCommunication 由LLM解读规则检查的原始数据，给出按置信度排序的诊断，
+// 并以模型的结论和置信度替换规则结论。失败时 result 保持不变
+func InterpretCommunication(ctx context.Context, service *llm.Service, templates *prompts.Registry, result *models.CommunicationAnalysis, request InterpretRequest) error {
+	client, err := service.Client(TypePodCommunication, request.Profile)
+	if err != nil {
+		return err
+	}
+
+	prompt, err := templates.Render(prompts.PodCommunication, map[string]interface{}{
+		"pod_a": result.PodA,
+		"pod_b": result.PodB,
+		"findings": map[string]interface{}{
+			"checks":          result.Findings,
+			"rule_status":     result.Status,
+			"rule_issues":     result.Issues,
+			"rule_confidence": result.Confidence,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	llmRequest := llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	}
+	var resp *llm.Response
+	if request.Stream != nil {
+		resp, err = client.Stream(ctx, llmRequest, request.Stream)
+	} else {
+		resp, err = client.Chat(ctx, llmRequest)
+	}
+	if err != nil {
+		return err
+	}
+
+	var output struct {
+		Status     string                          `json:"status"`
+		Confidence float64                         `json:"confidence"`
+		Summary    string                          `json:"summary"`
+		Diagnoses  []models.CommunicationDiagnosis `json:"diagnoses"`
+	}
+	if err := llm.DecodeJSON(resp.Content, &output); err != nil {
+		return err
+	}
+
+	for i := range output.Diagnoses {
+		output.Diagnoses[i].Confidence = clamp01(output.Diagnoses[i].Confidence)
+	}
+	sort.SliceStable(output.Diagnoses, func(i, j int) bool {
+		return output.Diagnoses[i].Confidence > output.Diagnoses[j].Confidence
+	})
+
+	switch status := strings.ToLower(strings.TrimSpace(output.Status)); status {
+	case "connected", "disconnected", "unknown":
+		result.Status = status
+	}
+	// 模型未给出整体置信度时使用最可能诊断的置信度
+	confidence := clamp01(output.Confidence)
+	if confidence == 0 && len(output.Diagnoses) > 0 {
+		confidence = output.Diagnoses[0].Confidence
+	}
+	if confidence > 0 {
+		result.Confidence = confidence
+	}
+
+	result.Method = "llm"
+	result.Summary = output.Summary
+	result.Diagnoses = output.Diagnoses
+	result.Model = resp.Model
+	result.Prompt = prompt.Template
+	return nil
+}
+
+func clamp01(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
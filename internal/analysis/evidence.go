@@ -0,0 +1,302 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 证据数量上限，避免提示词过长
+const (
+	maxEvidenceEvents = 50
+	maxEvidencePods   = 30
+	maxEvidenceNodes  = 30
+)
+
+// Sources 分析所需的数据来源，各项均可为空（开发模式下没有K8s连接）
+type Sources struct {
+	Metrics *metrics.Manager
+	K8s     *k8s.Client
+	Events  *events.History
+	History Retriever // 历史检索（analysis.rag）
+}
+
+// PastCase 与当前情况相似的历史记录（指标快照、故障或UAV遥测的摘要）
+type PastCase struct {
+	Kind    string    `json:"kind"`
+	Ref     string    `json:"ref,omitempty"` // 关联对象，如故障ID或UAV节点名
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+	Score   float64   `json:"score"` // 相似度（0-1）
+}
+
+// Retriever 检索 before 之前与 text 描述的情况相似的历史记录
+type Retriever interface {
+	Similar(ctx context.Context, text string, before time.Time, limit int) ([]PastCase, error)
+}
+
+// Scope 收集证据的范围
+type Scope struct {
+	Namespace string        // 为空表示所有命名空间
+	Pod       string        // 关注的Pod名称，可选
+	Since     time.Duration // 事件时间窗口
+}
+
+// NodeEvidence 节点状态摘要
+type NodeEvidence struct {
+	Name            string   `json:"name"`
+	Healthy         bool     `json:"healthy"`
+	Conditions      []string `json:"conditions,omitempty"`
+	CPUUsageRate    float64  `json:"cpu_usage_rate"`
+	MemoryUsageRate float64  `json:"memory_usage_rate"`
+	DiskUsageRate   float64  `json:"disk_usage_rate"`
+}
+
+// PodEvidence Pod状态摘要
+type PodEvidence struct {
+	Name            string  `json:"name"` // namespace/name
+	Node            string  `json:"node,omitempty"`
+	Phase           string  `json:"phase"`
+	Ready           bool    `json:"ready"`
+	Restarts        int32   `json:"restarts"`
+	CPUUsageRate    float64 `json:"cpu_usage_rate"`
+	MemoryUsageRate float64 `json:"memory_usage_rate"`
+}
+
+// Evidence 交给LLM的集群现状
+type Evidence struct {
+	Scope   Scope                        `json:"-"`
+	Cluster *metricstypes.ClusterMetrics `json:"cluster,omitempty"`
+	Nodes   []NodeEvidence               `json:"nodes,omitempty"`
+	Pods    []PodEvidence                `json:"pods,omitempty"`
+	Events  []*models.EventInfo          `json:"events,omitempty"`
+	Gaps    []string                     `json:"gaps,omitempty"`         // 不可用的数据来源
+	Similar []PastCase                   `json:"similar_past,omitempty"` // 相似的历史情况
+
+	SnapshotTime time.Time `json:"-"` // 使用的指标快照时间，没有指标时为零值
+}
+
+// Situation 当前情况的文字描述（异常节点、异常Pod和Warning事件），用于检索相似的历史
+func (e *Evidence) Situation(symptom string) string {
+	var b strings.Builder
+	if symptom != "" {
+		b.WriteString(symptom)
+		b.WriteString(". ")
+	}
+	for _, node := range e.Nodes {
+		if !node.Healthy {
+			fmt.Fprintf(&b, "Node %s unhealthy %s. ", node.Name, strings.Join(node.Conditions, " "))
+		}
+	}
+	for _, pod := range e.Pods {
+		fmt.Fprintf(&b, "Pod %s %s ready=%t restarts=%d. ", pod.Name, pod.Phase, pod.Ready, pod.Restarts)
+	}
+	for _, event := range e.Events {
+		if strings.EqualFold(event.Type, "Warning") {
+			fmt.Fprintf(&b, "%s %s/%s: %s. ", event.Reason, event.Namespace, event.ObjectName, event.Message)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Fingerprint 证据对应的集群状态指纹，用于LLM回答缓存
+func (e *Evidence) Fingerprint() string {
+	return snapshotFingerprint(e.SnapshotTime)
+}
+
+// Fingerprint 当前集群状态指纹（最新指标快照的时间），用于LLM回答缓存
+func (s Sources) Fingerprint() string {
+	if s.Metrics == nil {
+		return snapshotFingerprint(time.Time{})
+	}
+	snapshot := s.Metrics.ViewSnapshot()
+	if snapshot == nil {
+		return snapshotFingerprint(time.Time{})
+	}
+	return snapshotFingerprint(snapshot.Timestamp)
+}
+
+// snapshotFingerprint 没有指标快照时提示词本身就是全部状态，使用固定指纹
+func snapshotFingerprint(t time.Time) string {
+	if t.IsZero() {
+		return "no-metrics"
+	}
+	return "snapshot@" + t.UTC().Format(time.RFC3339Nano)
+}
+
+// Collect 收集范围内的事件、Pod指标和节点状态；单个来源失败时记录在 Gaps 中
+func (s Sources) Collect(ctx context.Context, scope Scope) *Evidence {
+	if scope.Since <= 0 {
+		scope.Since = time.Hour
+	}
+	evidence := &Evidence{Scope: scope}
+
+	if s.Metrics != nil {
+		snapshot := s.Metrics.GetLatestSnapshot()
+		if snapshot != nil {
+			evidence.SnapshotTime = snapshot.Timestamp
+			evidence.Cluster = snapshot.ClusterMetrics
+			evidence.Nodes = SummarizeNodes(snapshot.NodeMetrics, maxEvidenceNodes)
+			evidence.Pods = podEvidence(snapshot.PodMetrics, scope)
+		}
+	} else {
+		evidence.Gaps = append(evidence.Gaps, "metrics collection not available")
+	}
+
+	recent, err := s.recentEvents(ctx, scope)
+	if err != nil {
+		evidence.Gaps = append(evidence.Gaps, err.Error())
+	}
+	evidence.Events = recent
+	return evidence
+}
+
+// Recall 检索与当前情况相似的历史（不含分析时间窗口内的记录），结果写入 Similar；检索失败时记录在 Gaps 中
+func (s Sources) Recall(ctx context.Context, evidence *Evidence, symptom string) {
+	if s.History == nil {
+		return
+	}
+	situation := evidence.Situation(symptom)
+	if situation == "" {
+		return
+	}
+	similar, err := s.History.Similar(ctx, situation, time.Now().Add(-evidence.Scope.Since), 0)
+	if err != nil {
+		evidence.Gaps = append(evidence.Gaps, fmt.Sprintf("history search failed: %v", err))
+		return
+	}
+	evidence.Similar = similar
+}
+
+// recentEvents 优先从事件历史查询，没有历史记录时直接读取K8s事件
+func (s Sources) recentEvents(ctx context.Context, scope Scope) ([]*models.EventInfo, error) {
+	if s.Events != nil {
+		result, err := s.Events.Query(ctx, events.Query{
+			From:      time.Now().Add(-scope.Since),
+			Namespace: scope.Namespace,
+			Object:    scope.Pod,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("event history unavailable: %w", err)
+		}
+		return limitEvents(result), nil
+	}
+
+	if s.K8s == nil {
+		return nil, fmt.Errorf("event source not available")
+	}
+	result, err := s.K8s.GetEvents(scope.Namespace, maxEvidenceEvents*4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	cutoff := time.Now().Add(-scope.Since)
+	filtered := result[:0]
+	for _, event := range result {
+		if event.Timestamp.Before(cutoff) || (scope.Pod != "" && event.ObjectName != scope.Pod) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Timestamp.Before(filtered[j].Timestamp) })
+	return limitEvents(filtered), nil
+}
+
+// limitEvents 保留最近的事件，Warning 事件优先
+func limitEvents(list []*models.EventInfo) []*models.EventInfo {
+	if len(list) <= maxEvidenceEvents {
+		return list
+	}
+	warnings := make([]*models.EventInfo, 0, maxEvidenceEvents)
+	for i := len(list) - 1; i >= 0 && len(warnings) < maxEvidenceEvents; i-- {
+		if strings.EqualFold(list[i].Type, "Warning") {
+			warnings = append(warnings, list[i])
+		}
+	}
+	for i := len(list) - 1; i >= 0 && len(warnings) < maxEvidenceEvents; i-- {
+		if !strings.EqualFold(list[i].Type, "Warning") {
+			warnings = append(warnings, list[i])
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Timestamp.Before(warnings[j].Timestamp) })
+	return warnings
+}
+
+// SummarizeNodes 节点摘要，异常节点排在前面；limit<=0 表示不限制
+func SummarizeNodes(nodes map[string]*metricstypes.NodeMetrics, limit int) []NodeEvidence {
+	result := make([]NodeEvidence, 0, len(nodes))
+	for name, node := range nodes {
+		result = append(result, NodeEvidence{
+			Name:            name,
+			Healthy:         node.Healthy,
+			Conditions:      node.Conditions,
+			CPUUsageRate:    node.CPUUsageRate,
+			MemoryUsageRate: node.MemoryUsageRate,
+			DiskUsageRate:   node.DiskUsageRate,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Healthy != result[j].Healthy {
+			return !result[i].Healthy
+		}
+		return result[i].Name < result[j].Name
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// podEvidence 范围内的Pod摘要；指定Pod时始终包含该Pod，其余只保留有异常迹象的Pod
+func podEvidence(pods map[string]*metricstypes.PodMetrics, scope Scope) []PodEvidence {
+	result := make([]PodEvidence, 0)
+	for key, pod := range pods {
+		if scope.Namespace != "" && pod.Namespace != scope.Namespace {
+			continue
+		}
+		target := scope.Pod != "" && pod.PodName == scope.Pod
+		if !target && !PodSuspicious(pod) {
+			continue
+		}
+		result = append(result, SummarizePod(key, pod))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Restarts != result[j].Restarts {
+			return result[i].Restarts > result[j].Restarts
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > maxEvidencePods {
+		result = result[:maxEvidencePods]
+	}
+	return result
+}
+
+// SummarizePod Pod摘要，key为 namespace/name
+func SummarizePod(key string, pod *metricstypes.PodMetrics) PodEvidence {
+	return PodEvidence{
+		Name:            key,
+		Node:            pod.NodeName,
+		Phase:           pod.Phase,
+		Ready:           pod.Ready,
+		Restarts:        pod.Restarts,
+		CPUUsageRate:    pod.CPUUsageRate,
+		MemoryUsageRate: pod.MemoryUsageRate,
+	}
+}
+
+// PodSuspicious Pod是否有异常迹象（未就绪、重启、非正常阶段或资源接近上限）
+func PodSuspicious(pod *metricstypes.PodMetrics) bool {
+	if pod.Phase == "Succeeded" {
+		return false
+	}
+	return !pod.Ready || pod.Restarts > 0 || pod.Phase != "Running" ||
+		pod.CPUUsageRate >= 90 || pod.MemoryUsageRate >= 90
+}
@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// TypeExplain 指标解释在 llm.routing 中使用的分析类型
+const TypeExplain = "explain"
+
+// 指标解释的查询范围
+const (
+	DefaultExplainWindow = time.Hour
+	MaxExplainWindow     = 7 * 24 * time.Hour
+	explainBaseline      = 24 * time.Hour // 与窗口之前这段时间的数据比较
+	explainPoints        = 30             // 交给模型的序列点数
+	maxExplainAnomalies  = 5
+)
+
+var (
+	// ErrInvalidTarget 无法识别的指标对象
+	ErrInvalidTarget = metrics.ErrInvalidTarget
+	// ErrNoSamples 查询范围内没有该指标的样本
+	ErrNoSamples = errors.New("no samples for metric")
+	// ErrMetricsUnavailable 无法读取历史样本
+	ErrMetricsUnavailable = errors.New("metric history unavailable")
+)
+
+// metricDescriptions 常用指标的单位和含义，帮助模型正确解读数值
+var metricDescriptions = map[string]string{
+	"cpu_usage":         "CPU usage in millicores",
+	"cpu_usage_rate":    "CPU usage as a percentage of capacity (node) or of the limit/request (pod)",
+	"memory_usage":      "memory usage in bytes",
+	"memory_usage_rate": "memory usage as a percentage of capacity (node) or of the limit (pod)",
+	"disk_usage_rate":   "root filesystem usage in percent",
+	"network_latency":   "node network latency in milliseconds",
+	"restarts":          "cumulative container restart count of the pod",
+	"restart_rate":      "container restarts of the pod per hour since the previous collection",
+	"cpu_usage_trend":   "change of CPU usage since the previous collection in cores per second",
+	"gpu_usage":         "sum of the utilization of the GPUs used by the pod, 100 per fully used GPU (shared GPUs split evenly)",
+	"gpu_usage_rate":    "GPU usage of the pod as a percentage of its allocated GPUs",
+	"gpu_memory_used":   "GPU memory used by the pod in MiB",
+	"network_rx_rate":   "bytes per second received on the node's default interface",
+	"network_tx_rate":   "bytes per second transmitted on the node's default interface",
+	"rtt_ms":            "round-trip time between two pods in milliseconds",
+	"packet_loss":       "packet loss between two pods in percent",
+	"bandwidth_mbps":    "measured bandwidth between two pods in Mbit/s",
+	"connected":         "1 when the two pods could reach each other, 0 otherwise",
+	"battery_percent":   "UAV battery level in percent",
+	"battery_voltage":   "UAV battery voltage in volts",
+	"altitude":          "UAV altitude above mean sea level in meters",
+	"relative_altitude": "UAV altitude above the takeoff point in meters",
+	"ground_speed":      "UAV ground speed in m/s",
+}
+
+// ExplainRequest 指标解释请求
+type ExplainRequest struct {
+	Target  string // node/worker-1、pod/default/web-0、pair/default/a|default/b、uav/edge-1
+	Metric  string
+	From    time.Time
+	To      time.Time // 为零值时表示现在
+	Profile string
+}
+
+// MetricExplanation 指标解释结果
+type MetricExplanation struct {
+	Target      string                   `json:"target"`
+	Metric      string                   `json:"metric"`
+	From        time.Time                `json:"from"`
+	To          time.Time                `json:"to"`
+	Samples     int                      `json:"samples"`
+	Current     float64                  `json:"current"`
+	Summary     map[string]float64       `json:"summary"`            // 窗口内的 avg、min、max、p95、last
+	Baseline    map[string]float64       `json:"baseline,omitempty"` // 窗口之前24小时的 avg、p95、max
+	Series      []metrics.AggregatePoint `json:"series"`
+	Meaning     string                   `json:"meaning"`
+	Status      string                   `json:"status"` // normal, watch, concerning
+	Explanation string                   `json:"explanation"`
+	NextSteps   []string                 `json:"next_steps"`
+	Model       string                   `json:"model"`
+	Prompt      string                   `json:"prompt"`
+	Usage       llm.Usage                `json:"usage"`
+}
+
+// ParseMetricTarget 将 node/worker-1 形式的对象拆分为类型和样本键（uav的样本键为节点名）
+func ParseMetricTarget(target string) (string, string, error) {
+	return metrics.ParseTarget(target)
+}
+
+// ExplainMetric 读取对象最近的指标序列，由LLM说明指标含义、当前值是否值得关注以及下一步检查什么
+func ExplainMetric(ctx context.Context, service *llm.Service, templates *prompts.Registry, store storage.Store, sources Sources, request ExplainRequest) (*MetricExplanation, error) {
+	kind, key, err := ParseMetricTarget(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	to := request.To
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	from := request.From
+	if from.IsZero() {
+		from = to.Add(-DefaultExplainWindow)
+	}
+
+	step := to.Sub(from) / explainPoints
+	if step < time.Minute {
+		step = time.Minute
+	}
+	series, err := metrics.Aggregate(ctx, store, metrics.AggregateQuery{
+		Target:       kind,
+		Key:          key,
+		Metric:       request.Metric,
+		Aggregations: []string{"avg", "min", "max", "p95", "last"},
+		From:         from,
+		To:           to,
+		Step:         step.Truncate(time.Minute),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+	}
+	if series.Samples == 0 {
+		return nil, fmt.Errorf("%w %s of %s since %s", ErrNoSamples, request.Metric, request.Target, from.Format(time.RFC3339))
+	}
+
+	result := &MetricExplanation{
+		Target:    request.Target,
+		Metric:    request.Metric,
+		From:      from,
+		To:        to,
+		Samples:   series.Samples,
+		Current:   series.Summary["last"],
+		Summary:   series.Summary,
+		Series:    series.Series,
+		NextSteps: []string{},
+	}
+	baseline, err := metrics.Aggregate(ctx, store, metrics.AggregateQuery{
+		Target:       kind,
+		Key:          key,
+		Metric:       request.Metric,
+		Aggregations: []string{"avg", "p95", "max"},
+		From:         from.Add(-explainBaseline),
+		To:           from,
+	})
+	if err == nil && baseline.Samples > 0 {
+		result.Baseline = baseline.Summary
+	}
+
+	client, err := service.Client(TypeExplain, request.Profile)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{
+		"window":   map[string]interface{}{"from": from, "to": to, "step": series.Step},
+		"samples":  series.Samples,
+		"current":  result.Current,
+		"summary":  result.Summary,
+		"baseline": result.Baseline,
+		"series":   result.Series,
+	}
+	if description, ok := metricDescriptions[request.Metric]; ok {
+		data["description"] = description
+	}
+	if state := explainContext(ctx, sources, kind, key, request.Metric, from); len(state) > 0 {
+		data["context"] = state
+	}
+	prompt, err := templates.Render(prompts.MetricExplanation, map[string]interface{}{
+		"target": request.Target,
+		"metric": request.Metric,
+		"data":   data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Meaning     string   `json:"meaning"`
+		Status      string   `json:"status"`
+		Explanation string   `json:"explanation"`
+		NextSteps   []string `json:"next_steps"`
+	}
+	resp, err := chatJSON(ctx, client, prompt, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Meaning = output.Meaning
+	result.Explanation = output.Explanation
+	switch status := strings.ToLower(strings.TrimSpace(output.Status)); status {
+	case "normal", "watch", "concerning":
+		result.Status = status
+	}
+	if output.NextSteps != nil {
+		result.NextSteps = output.NextSteps
+	}
+	result.Model = resp.Model
+	result.Prompt = prompt.Template
+	result.Usage = resp.Usage
+	return result, nil
+}
+
+// explainContext 对象的当前状态和窗口内检测到的异常
+func explainContext(ctx context.Context, sources Sources, kind, key, metric string, from time.Time) map[string]interface{} {
+	state := make(map[string]interface{})
+	if sources.Metrics == nil {
+		return state
+	}
+
+	name := strings.TrimPrefix(key, kind+"/")
+	switch kind {
+	case metrics.TargetNode:
+		if node, err := sources.Metrics.GetNodeMetrics(name); err == nil {
+			state["node"] = node
+		}
+	case metrics.TargetPod:
+		namespace, pod, _ := strings.Cut(name, "/")
+		if podMetrics, err := sources.Metrics.GetPodMetrics(namespace, pod); err == nil {
+			state["pod"] = podMetrics
+		}
+	case metrics.TargetUAV:
+		if uav, ok := sources.Metrics.GetSingleUAVMetrics(name); ok {
+			state["uav"] = uav
+		}
+	}
+
+	if detector := sources.Metrics.Anomalies(); detector != nil {
+		anomalies, err := detector.List(ctx, metrics.AnomalyFilter{Target: kind, Key: name, Metric: metric, From: from, Limit: maxExplainAnomalies})
+		if err == nil && len(anomalies) > 0 {
+			state["anomalies"] = anomalies
+		}
+	}
+	return state
+}
@@ -0,0 +1,347 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+)
+
+// TypeLogSummary 日志摘要在 llm.routing 中使用的分析类型
+const TypeLogSummary = "log_summary"
+
+// 日志摘要的限制
+const (
+	DefaultLogLines = 500
+	MaxLogLines     = 5000
+
+	logChunkBytes   = 12 << 10 // 每个分块的大小（约3000 token）
+	maxLogChunks    = 8        // 超过时丢弃最早的日志
+	maxLogLineBytes = 1000     // 超长的行截断
+	logChunkWorkers = 3
+	excerptContext  = 2  // 引用行前后附带的行数
+	maxExcerptLines = 40 // 单个片段的最大行数
+	maxExcerpts     = 20
+)
+
+// LogSummaryRequest 日志摘要请求
+type LogSummaryRequest struct {
+	Pod       string // namespace/name
+	Container string
+	Previous  bool   // 上一次容器实例的日志
+	Profile   string // 指定LLM配置，可选
+}
+
+// LogError 日志中的一类错误
+type LogError struct {
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+	Severity string `json:"severity,omitempty"`
+	Lines    []int  `json:"lines,omitempty"`
+}
+
+// LogStackTrace 日志中的堆栈
+type LogStackTrace struct {
+	Exception string `json:"exception"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// LogCause 可能的原因
+type LogCause struct {
+	Cause      string  `json:"cause"`
+	Confidence float64 `json:"confidence"`
+	Lines      []int   `json:"lines,omitempty"`
+}
+
+// LogExcerpt 摘要引用的原始日志片段（行号从1开始，对应本次读取的日志）
+type LogExcerpt struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Text      string `json:"text"`
+}
+
+// LogSummary 日志摘要结果
+type LogSummary struct {
+	Pod            string          `json:"pod"`
+	Container      string          `json:"container,omitempty"`
+	Previous       bool            `json:"previous,omitempty"`
+	LinesAnalyzed  int             `json:"lines_analyzed"`
+	Truncated      bool            `json:"truncated"` // 日志超过分析上限，最早的部分未分析
+	Chunks         int             `json:"chunks"`
+	Summary        string          `json:"summary"`
+	Errors         []LogError      `json:"errors"`
+	StackTraces    []LogStackTrace `json:"stack_traces"`
+	ProbableCauses []LogCause      `json:"probable_causes"`
+	Excerpts       []LogExcerpt    `json:"excerpts"`
+	Model          string          `json:"model"`
+	Prompt         string          `json:"prompt"`
+	Usage          llm.Usage       `json:"usage"`
+}
+
+// logChunkSummary 模型对一个分块（或合并后）的输出
+type logChunkSummary struct {
+	Summary        string          `json:"summary"`
+	Errors         []LogError      `json:"errors"`
+	StackTraces    []LogStackTrace `json:"stack_traces"`
+	ProbableCauses []LogCause      `json:"probable_causes"`
+}
+
+// logChunk 带行号的日志分块
+type logChunk struct {
+	index int
+	text  string
+}
+
+// SummarizeLogs 将日志按大小分块，由LLM分别总结后合并为一个摘要，并附上摘要引用的原始日志片段
+func SummarizeLogs(ctx context.Context, service *llm.Service, templates *prompts.Registry, request LogSummaryRequest, logs string) (*LogSummary, error) {
+	lines := splitLogLines(logs)
+	result := &LogSummary{
+		Pod:            request.Pod,
+		Container:      request.Container,
+		Previous:       request.Previous,
+		LinesAnalyzed:  len(lines),
+		Errors:         []LogError{},
+		StackTraces:    []LogStackTrace{},
+		ProbableCauses: []LogCause{},
+		Excerpts:       []LogExcerpt{},
+	}
+	if len(lines) == 0 {
+		result.Summary = "No log output."
+		return result, nil
+	}
+
+	client, err := service.Client(TypeLogSummary, request.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, first := chunkLogLines(lines)
+	if first > 0 {
+		result.Truncated = true
+		result.LinesAnalyzed = len(lines) - first
+	}
+	result.Chunks = len(chunks)
+
+	var usage llm.Usage
+	type chunkResult struct {
+		summary logChunkSummary
+		resp    *llm.Response
+		prompt  string
+	}
+	summaries, err := workpool.Map(ctx, workpool.Options{Concurrency: logChunkWorkers}, chunks,
+		func(c logChunk) string { return fmt.Sprintf("chunk %d", c.index+1) },
+		func(ctx context.Context, c logChunk) (chunkResult, error) {
+			prompt, err := templates.Render(prompts.LogSummary, map[string]interface{}{
+				"pod":       request.Pod,
+				"container": request.Container,
+				"part":      fmt.Sprintf("%d/%d", c.index+1, len(chunks)),
+				"logs":      c.text,
+			})
+			if err != nil {
+				return chunkResult{}, err
+			}
+			var summary logChunkSummary
+			resp, err := chatJSON(ctx, client, prompt, &summary)
+			return chunkResult{summary: summary, resp: resp, prompt: prompt.Template}, err
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := summaries[0].summary
+	result.Prompt = summaries[0].prompt
+	result.Model = summaries[0].resp.Model
+	for _, s := range summaries {
+		usage.Add(s.resp.Usage)
+	}
+
+	// 多个分块时再由模型合并
+	if len(summaries) > 1 {
+		parts := make([]logChunkSummary, len(summaries))
+		for i, s := range summaries {
+			parts[i] = s.summary
+		}
+		prompt, err := templates.Render(prompts.LogSummaryMerge, map[string]interface{}{
+			"pod":       request.Pod,
+			"summaries": parts,
+		})
+		if err != nil {
+			return nil, err
+		}
+		merged = logChunkSummary{}
+		resp, err := chatJSON(ctx, client, prompt, &merged)
+		if err != nil {
+			return nil, err
+		}
+		usage.Add(resp.Usage)
+		result.Prompt += "," + prompt.Template
+		result.Model = resp.Model
+	}
+
+	result.Summary = merged.Summary
+	if merged.Errors != nil {
+		result.Errors = merged.Errors
+	}
+	if merged.StackTraces != nil {
+		result.StackTraces = merged.StackTraces
+	}
+	if merged.ProbableCauses != nil {
+		result.ProbableCauses = merged.ProbableCauses
+		for i := range result.ProbableCauses {
+			result.ProbableCauses[i].Confidence = clamp01(result.ProbableCauses[i].Confidence)
+		}
+		sort.SliceStable(result.ProbableCauses, func(i, j int) bool {
+			return result.ProbableCauses[i].Confidence > result.ProbableCauses[j].Confidence
+		})
+	}
+	result.Excerpts = logExcerpts(lines, merged)
+	result.Usage = usage
+	return result, nil
+}
+
+// chatJSON 以JSON模式调用模型并解析输出
+func chatJSON(ctx context.Context, client llm.Client, prompt *prompts.Rendered, v interface{}) (*llm.Response, error) {
+	resp, err := client.Chat(ctx, llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := llm.DecodeJSON(resp.Content, v); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// splitLogLines 按行拆分日志，去掉末尾空行并截断超长的行
+func splitLogLines(logs string) []string {
+	logs = strings.TrimRight(logs, "\n")
+	if strings.TrimSpace(logs) == "" {
+		return nil
+	}
+	lines := strings.Split(logs, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if len(line) > maxLogLineBytes {
+			cut := maxLogLineBytes
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			line = line[:cut] + "…"
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// chunkLogLines 将带行号的日志按大小分块；超过分块上限时只保留最近的日志，返回第一个被分析的行的下标
+func chunkLogLines(lines []string) ([]logChunk, int) {
+	numbered := make([]string, len(lines))
+	for i, line := range lines {
+		numbered[i] = fmt.Sprintf("%d| %s\n", i+1, line)
+	}
+
+	// 每个分块至少填充到 logChunkBytes 减去一行的长度，按此预算可保证不超过 maxLogChunks 个分块
+	budget := maxLogChunks * (logChunkBytes - maxLogLineBytes - 16)
+	first, total := len(numbered), 0
+	for first > 0 && total+len(numbered[first-1]) <= budget {
+		first--
+		total += len(numbered[first])
+	}
+
+	var chunks []logChunk
+	var current strings.Builder
+	for _, line := range numbered[first:] {
+		if current.Len() > 0 && current.Len()+len(line) > logChunkBytes {
+			chunks = append(chunks, logChunk{index: len(chunks), text: current.String()})
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, logChunk{index: len(chunks), text: current.String()})
+	}
+	return chunks, first
+}
+
+// logExcerpts 根据摘要引用的行号截取原始日志（不使用模型复述的内容）
+func logExcerpts(lines []string, summary logChunkSummary) []LogExcerpt {
+	type span struct{ start, end int }
+	var spans []span
+	addLine := func(line int) {
+		spans = append(spans, span{line - excerptContext, line + excerptContext})
+	}
+	for _, e := range summary.Errors {
+		// 同一类错误只引用前两处
+		for i, line := range e.Lines {
+			if i == 2 {
+				break
+			}
+			addLine(line)
+		}
+	}
+	for _, trace := range summary.StackTraces {
+		end := trace.EndLine
+		if end < trace.StartLine {
+			end = trace.StartLine
+		}
+		if end-trace.StartLine >= maxExcerptLines {
+			end = trace.StartLine + maxExcerptLines - 1
+		}
+		spans = append(spans, span{trace.StartLine, end})
+	}
+	for _, cause := range summary.ProbableCauses {
+		for _, line := range cause.Lines {
+			addLine(line)
+		}
+	}
+
+	// 限定在日志范围内，排序后合并重叠的片段
+	valid := spans[:0]
+	for _, s := range spans {
+		if s.end < 1 || s.start > len(lines) {
+			continue
+		}
+		if s.start < 1 {
+			s.start = 1
+		}
+		if s.end > len(lines) {
+			s.end = len(lines)
+		}
+		valid = append(valid, s)
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].start < valid[j].start })
+
+	excerpts := []LogExcerpt{}
+	for _, s := range valid {
+		if n := len(excerpts); n > 0 && s.start <= excerpts[n-1].EndLine+1 {
+			last := &excerpts[n-1]
+			if s.end <= last.EndLine {
+				continue
+			}
+			if s.end-last.StartLine < maxExcerptLines {
+				last.EndLine = s.end
+				last.Text = strings.Join(lines[last.StartLine-1:last.EndLine], "\n")
+				continue
+			}
+			// 合并后过长时从上一个片段之后开始新的片段
+			s.start = last.EndLine + 1
+		}
+		if len(excerpts) == maxExcerpts {
+			break
+		}
+		excerpts = append(excerpts, LogExcerpt{
+			StartLine: s.start,
+			EndLine:   s.end,
+			Text:      strings.Join(lines[s.start-1:s.end], "\n"),
+		})
+	}
+	return excerpts
+}
@@ -0,0 +1,168 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// TypeNetworkPolicy 网络策略建议在 llm.routing 中使用的分析类型
+const TypeNetworkPolicy = "network_policy"
+
+// 期望的连通性
+const (
+	ConnectivityAllow = "allow"
+	ConnectivityDeny  = "deny"
+)
+
+// ErrInvalidPolicy 模型生成的网络策略无法解析或不完整
+var ErrInvalidPolicy = errors.New("model returned an invalid NetworkPolicy")
+
+// yamlSeparator YAML多文档分隔行
+var yamlSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// PolicyRequest 网络策略建议请求
+type PolicyRequest struct {
+	Source       string
+	Destination  string
+	Connectivity string            // allow 或 deny
+	Ports        []models.PortRule // 为空表示所有端口
+	Profile      string
+}
+
+// SuggestedPolicy 生成的一个网络策略
+type SuggestedPolicy struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	YAML      string `json:"yaml"`
+}
+
+// PolicyInteraction 新策略与现有策略的相互影响
+type PolicyInteraction struct {
+	Policy string `json:"policy"`
+	Effect string `json:"effect"`
+}
+
+// PolicySuggestion 网络策略建议
+type PolicySuggestion struct {
+	Source       string                       `json:"source"`
+	Destination  string                       `json:"destination"`
+	Connectivity string                       `json:"connectivity"`
+	Ports        []models.PortRule            `json:"ports"`
+	YAML         string                       `json:"yaml"` // 所有策略，以 --- 分隔，可直接 kubectl apply -f
+	Policies     []SuggestedPolicy            `json:"policies"`
+	Explanation  string                       `json:"explanation"`
+	Interactions []PolicyInteraction          `json:"interactions"`
+	Warnings     []string                     `json:"warnings"`
+	Context      *models.NetworkPolicyContext `json:"context"`
+	Model        string                       `json:"model"`
+	Prompt       string                       `json:"prompt"`
+	Usage        llm.Usage                    `json:"usage"`
+}
+
+// SuggestNetworkPolicy 根据两个工作负载的现有网络策略，由LLM生成实现期望连通性的NetworkPolicy并说明与现有策略的关系。
+// 生成的YAML会被解析校验，只能位于两个工作负载所在的命名空间
+func SuggestNetworkPolicy(ctx context.Context, service *llm.Service, templates *prompts.Registry, current *models.NetworkPolicyContext, request PolicyRequest) (*PolicySuggestion, error) {
+	client, err := service.Client(TypeNetworkPolicy, request.Profile)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := templates.Render(prompts.NetworkPolicy, map[string]interface{}{
+		"source":       request.Source,
+		"destination":  request.Destination,
+		"connectivity": request.Connectivity,
+		"ports":        request.Ports,
+		"context":      current,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Policies []struct {
+			YAML string `json:"yaml"`
+		} `json:"policies"`
+		Explanation  string              `json:"explanation"`
+		Interactions []PolicyInteraction `json:"interactions"`
+		Warnings     []string            `json:"warnings"`
+	}
+	resp, err := chatJSON(ctx, client, prompt, &output)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Policies) == 0 {
+		return nil, fmt.Errorf("%w: no policies in the response", ErrInvalidPolicy)
+	}
+
+	result := &PolicySuggestion{
+		Source:       request.Source,
+		Destination:  request.Destination,
+		Connectivity: request.Connectivity,
+		Ports:        request.Ports,
+		Policies:     make([]SuggestedPolicy, 0, len(output.Policies)),
+		Explanation:  output.Explanation,
+		Interactions: []PolicyInteraction{},
+		Warnings:     []string{},
+		Context:      current,
+		Model:        resp.Model,
+		Prompt:       prompt.Template,
+		Usage:        resp.Usage,
+	}
+	if result.Ports == nil {
+		result.Ports = []models.PortRule{}
+	}
+	namespaces := map[string]bool{current.Source.Namespace: true, current.Destination.Namespace: true}
+	var documents []string
+	for _, p := range output.Policies {
+		// 一项中可能包含多个以 --- 分隔的清单
+		for _, text := range yamlSeparator.Split(p.YAML, -1) {
+			if text = strings.TrimSpace(text); text != "" {
+				documents = append(documents, text)
+			}
+		}
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("%w: empty manifests", ErrInvalidPolicy)
+	}
+	for i, text := range documents {
+		policy, err := parsePolicy(text, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("%w: policy %d: %v", ErrInvalidPolicy, i+1, err)
+		}
+		result.Policies = append(result.Policies, SuggestedPolicy{Name: policy.Name, Namespace: policy.Namespace, YAML: text + "\n"})
+	}
+	result.YAML = strings.Join(documents, "\n---\n") + "\n"
+	if output.Interactions != nil {
+		result.Interactions = output.Interactions
+	}
+	if output.Warnings != nil {
+		result.Warnings = output.Warnings
+	}
+	return result, nil
+}
+
+// parsePolicy 解析并校验一个NetworkPolicy清单
+func parsePolicy(text string, namespaces map[string]bool) (*networkingv1.NetworkPolicy, error) {
+	var policy networkingv1.NetworkPolicy
+	if err := yaml.UnmarshalStrict([]byte(text), &policy); err != nil {
+		return nil, err
+	}
+	switch {
+	case policy.APIVersion != "networking.k8s.io/v1" || policy.Kind != "NetworkPolicy":
+		return nil, fmt.Errorf("expected networking.k8s.io/v1 NetworkPolicy, got %s %s", policy.APIVersion, policy.Kind)
+	case policy.Name == "":
+		return nil, errors.New("metadata.name is required")
+	case !namespaces[policy.Namespace]:
+		return nil, fmt.Errorf("namespace %q is not the namespace of either workload", policy.Namespace)
+	}
+	return &policy, nil
+}
@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+)
+
+// RootCauseRequest 根因分析请求
+type RootCauseRequest struct {
+	Scope   Scope
+	Symptom string // 用户描述的现象，可选
+	Profile string // 指定LLM配置，可选
+
+	Stream llm.StreamHandler // 非空时流式调用模型，逐段回调模型输出
+}
+
+// ProbableCause 可能的根因
+type ProbableCause struct {
+	Cause      string   `json:"cause"`
+	Confidence float64  `json:"confidence"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+// RemediationStep 修复建议
+type RemediationStep struct {
+	Action  string `json:"action"`
+	Command string `json:"command,omitempty"`
+	Risk    string `json:"risk,omitempty"`
+}
+
+// RootCauseResult 根因分析结果
+type RootCauseResult struct {
+	Summary        string            `json:"summary"`
+	ProbableCauses []ProbableCause   `json:"probable_causes"`
+	Remediation    []RemediationStep `json:"remediation"`
+	Evidence       *Evidence         `json:"evidence"`
+	Model          string            `json:"model"`
+	Prompt         string            `json:"prompt"` // 使用的提示词模板版本
+	Usage          llm.Usage         `json:"usage"`
+	Cached         bool              `json:"cached,omitempty"` // 复用了缓存的回答
+}
+
+// RootCause 收集集群现状并调用LLM分析根因
+func RootCause(ctx context.Context, service *llm.Service, templates *prompts.Registry, sources Sources, request RootCauseRequest) (*RootCauseResult, error) {
+	client, err := service.Client(TypeRootCause, request.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := sources.Collect(ctx, request.Scope)
+	sources.Recall(ctx, evidence, request.Symptom)
+	prompt, err := templates.Render(prompts.RootCause, map[string]interface{}{
+		"scope":    describeScope(evidence.Scope),
+		"symptom":  request.Symptom,
+		"evidence": evidence,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 相同范围的提示词且指标快照未更新时复用缓存的回答
+	ctx = llm.WithFingerprint(ctx, evidence.Fingerprint())
+	llmRequest := llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	}
+	var resp *llm.Response
+	if request.Stream != nil {
+		resp, err = client.Stream(ctx, llmRequest, request.Stream)
+	} else {
+		resp, err = client.Chat(ctx, llmRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Summary        string            `json:"summary"`
+		ProbableCauses []ProbableCause   `json:"probable_causes"`
+		Remediation    []RemediationStep `json:"remediation"`
+	}
+	if err := llm.DecodeJSON(resp.Content, &output); err != nil {
+		return nil, err
+	}
+
+	result := &RootCauseResult{
+		Summary:        output.Summary,
+		ProbableCauses: output.ProbableCauses,
+		Remediation:    output.Remediation,
+		Evidence:       evidence,
+		Model:          resp.Model,
+		Prompt:         prompt.Template,
+		Usage:          resp.Usage,
+		Cached:         resp.Cached,
+	}
+	if result.Summary == "" && len(result.ProbableCauses) > 0 {
+		result.Summary = result.ProbableCauses[0].Cause
+	}
+	return result, nil
+}
+
+// describeScope 分析范围的文字描述
+func describeScope(scope Scope) string {
+	namespace := scope.Namespace
+	if namespace == "" {
+		namespace = "all namespaces"
+	} else {
+		namespace = "namespace " + namespace
+	}
+	if scope.Pod != "" {
+		return fmt.Sprintf("pod %s in %s, events from the last %s", scope.Pod, namespace, scope.Since)
+	}
+	return fmt.Sprintf("%s, events from the last %s", namespace, scope.Since)
+}
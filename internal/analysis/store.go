@@ -0,0 +1,160 @@
+package analysis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// Collection 分析结果在存储中的集合名
+const Collection = "analyses"
+
+// 分析类型
+const (
+	TypePodCommunication = "pod_communication"
+	TypeRootCause        = "root_cause"
+	TypeAnomaly          = "anomaly"
+)
+
+// ErrNotFound 分析结果不存在
+var ErrNotFound = errors.New("analysis not found")
+
+// Store 分析结果存储
+type Store struct {
+	store storage.Store
+}
+
+// Filter 分析结果查询条件
+type Filter struct {
+	Type   string
+	Status string
+	Pod    string // namespace/name，匹配任意关联Pod
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// NewStore 创建分析结果存储
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// Save 保存一次分析结果，返回带ID的记录
+func (s *Store) Save(ctx context.Context, analysisType, status, summary string, pods []string, result interface{}) (*models.AnalysisRecord, error) {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analysis result: %w", err)
+	}
+
+	record := &models.AnalysisRecord{
+		ID:        newID(),
+		Type:      analysisType,
+		Status:    status,
+		Pods:      pods,
+		Summary:   summary,
+		Result:    payload,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analysis record: %w", err)
+	}
+
+	if err := s.store.Put(ctx, Collection, record.ID, data); err != nil {
+		return nil, fmt.Errorf("failed to store analysis %s: %w", record.ID, err)
+	}
+
+	if err := s.store.Append(ctx, Collection, &storage.Record{
+		ID:        record.ID,
+		Key:       analysisType,
+		Timestamp: record.CreatedAt,
+		Data:      data,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to index analysis %s: %w", record.ID, err)
+	}
+
+	return record, nil
+}
+
+// Get 根据ID获取分析结果
+func (s *Store) Get(ctx context.Context, id string) (*models.AnalysisRecord, error) {
+	data, err := s.store.Get(ctx, Collection, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	var record models.AnalysisRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode analysis %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+// List 查询分析结果（按时间升序）
+func (s *Store) List(ctx context.Context, filter Filter) ([]*models.AnalysisRecord, error) {
+	records, err := s.store.List(ctx, Collection, storage.Query{
+		Key:  filter.Type,
+		From: filter.From,
+		To:   filter.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list analyses: %w", err)
+	}
+
+	result := make([]*models.AnalysisRecord, 0, len(records))
+	for _, rec := range records {
+		var record models.AnalysisRecord
+		if err := json.Unmarshal(rec.Data, &record); err != nil {
+			continue
+		}
+		if filter.Status != "" && !strings.EqualFold(record.Status, filter.Status) {
+			continue
+		}
+		if filter.Pod != "" && !containsPod(record.Pods, filter.Pod) {
+			continue
+		}
+		result = append(result, &record)
+	}
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result, nil
+}
+
+// containsPod 判断Pod列表中是否包含目标Pod（未指定namespace时按default处理）
+func containsPod(pods []string, target string) bool {
+	if !strings.Contains(target, "/") {
+		target = "default/" + target
+	}
+	for _, pod := range pods {
+		if !strings.Contains(pod, "/") {
+			pod = "default/" + pod
+		}
+		if pod == target {
+			return true
+		}
+	}
+	return false
+}
+
+// newID 生成分析结果ID
+func newID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("ana-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("ana-%s-%s", time.Now().UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
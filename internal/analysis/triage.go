@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// triageWindow 分级时附带的事件时间窗口
+const triageWindow = 15 * time.Minute
+
+// ErrNoAnomalyDetector 未启用异常检测
+var ErrNoAnomalyDetector = errors.New("anomaly detection is not enabled")
+
+// errNoTriage 模型输出中缺少某个异常的分级
+var errNoTriage = errors.New("model response has no triage for anomaly")
+
+// TriageAnomalies 由LLM对一批异常分级（严重程度、可能原因、后续步骤），结果保存到检测器。
+// profile 为空时按 llm.routing.anomaly 选择配置；LLM调用失败时在每个异常上记录错误
+func TriageAnomalies(ctx context.Context, service *llm.Service, templates *prompts.Registry, sources Sources, anomalies []*metrics.Anomaly, profile string) error {
+	if sources.Metrics == nil || sources.Metrics.Anomalies() == nil {
+		return ErrNoAnomalyDetector
+	}
+	detector := sources.Metrics.Anomalies()
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	triages, err := triage(ctx, service, templates, sources, anomalies, profile)
+	var errs []error
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, a := range anomalies {
+		result, itemErr := triages[a.ID], err
+		if itemErr == nil && result == nil {
+			itemErr = fmt.Errorf("%w: %s", errNoTriage, a.ID)
+			errs = append(errs, itemErr)
+		}
+		if saveErr := detector.SetTriage(ctx, a.ID, result, itemErr); saveErr != nil {
+			errs = append(errs, saveErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func triage(ctx context.Context, service *llm.Service, templates *prompts.Registry, sources Sources, anomalies []*metrics.Anomaly, profile string) (map[string]*metrics.AnomalyTriage, error) {
+	client, err := service.Client(TypeAnomaly, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	view := make([]*metrics.Anomaly, len(anomalies))
+	uavs := make(map[string]*models.UAVMetricsEntry)
+	for i, a := range anomalies {
+		copied := *a
+		copied.Triage = nil
+		copied.TriageError = ""
+		view[i] = &copied
+		if a.Target == metrics.TargetUAV {
+			if state, ok := sources.Metrics.GetSingleUAVMetrics(a.Key); ok {
+				uavs[a.Key] = state
+			}
+		}
+	}
+	background := map[string]interface{}{"cluster": sources.Collect(ctx, Scope{Since: triageWindow})}
+	if len(uavs) > 0 {
+		background["uavs"] = uavs
+	}
+
+	prompt, err := templates.Render(prompts.AnomalyTriage, map[string]interface{}{
+		"anomalies": view,
+		"context":   background,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Chat(ctx, llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Summary   string `json:"summary"`
+		Anomalies []struct {
+			ID          string   `json:"id"`
+			Severity    string   `json:"severity"`
+			LikelyCause string   `json:"likely_cause"`
+			NextSteps   []string `json:"next_steps"`
+		} `json:"anomalies"`
+	}
+	if err := llm.DecodeJSON(resp.Content, &output); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := make(map[string]*metrics.AnomalyTriage, len(output.Anomalies))
+	for _, item := range output.Anomalies {
+		severity := strings.ToLower(strings.TrimSpace(item.Severity))
+		switch severity {
+		case "critical", "warning", "info":
+		default:
+			severity = "warning"
+		}
+		result[item.ID] = &metrics.AnomalyTriage{
+			Severity:    severity,
+			LikelyCause: item.LikelyCause,
+			NextSteps:   item.NextSteps,
+			Summary:     output.Summary,
+			Model:       resp.Model,
+			Prompt:      prompt.Template,
+			GeneratedAt: now,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,199 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// checkpointBucket 导出进度在存储中的bucket
+const checkpointBucket = "archive"
+
+const dayLayout = "2006-01-02"
+
+// Uploader 对象上传接口
+type Uploader interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// Exporter 归档导出器：按天将集合中的记录打包为JSONL.gz并上传到对象存储
+type Exporter struct {
+	store       storage.Store
+	uploader    Uploader
+	prefix      string
+	collections []string
+	interval    time.Duration
+	lookback    int // 首次运行时回溯导出的天数
+	logger      *logrus.Entry
+}
+
+// ExporterConfig 导出器配置
+type ExporterConfig struct {
+	Prefix       string
+	Collections  []string
+	Interval     time.Duration
+	LookbackDays int
+}
+
+// checkpoint 集合的导出进度
+type checkpoint struct {
+	LastDay    string    `json:"last_day"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// NewExporter 创建归档导出器
+func NewExporter(store storage.Store, uploader Uploader, cfg ExporterConfig) *Exporter {
+	logger := logging.For("archive")
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = 7
+	}
+
+	return &Exporter{
+		store:       store,
+		uploader:    uploader,
+		prefix:      cfg.Prefix,
+		collections: cfg.Collections,
+		interval:    cfg.Interval,
+		lookback:    cfg.LookbackDays,
+		logger:      logger,
+	}
+}
+
+// Start 启动定时导出循环
+func (e *Exporter) Start(ctx context.Context) {
+	e.logger.Infof("Archive exporter started (collections: %v, interval: %s)", e.collections, e.interval)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, collection := range e.collections {
+			if err := e.ExportPending(ctx, collection); err != nil {
+				e.logger.Warnf("Failed to archive collection %s: %v", collection, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExportPending 导出集合中所有已结束且尚未归档的日期（UTC）
+func (e *Exporter) ExportPending(ctx context.Context, collection string) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	next := today.AddDate(0, 0, -e.lookback)
+	cp, err := e.loadCheckpoint(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		last, err := time.Parse(dayLayout, cp.LastDay)
+		if err == nil {
+			next = last.AddDate(0, 0, 1)
+		}
+	}
+
+	for day := next; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		count, err := e.ExportDay(ctx, collection, day)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			e.logger.Infof("Archived %d records of %s for %s", count, collection, day.Format(dayLayout))
+		}
+
+		if err := e.saveCheckpoint(ctx, collection, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportDay 导出集合某一天的记录，返回导出的记录数（无记录时不上传）
+func (e *Exporter) ExportDay(ctx context.Context, collection string, day time.Time) (int, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	records, err := e.store.List(ctx, collection, storage.Query{
+		From: day,
+		To:   day.Add(24*time.Hour - time.Nanosecond),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s records: %w", collection, err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return 0, fmt.Errorf("failed to encode record: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	if err := e.uploader.PutObject(ctx, e.objectKey(collection, day), buf.Bytes(), "application/gzip"); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+// objectKey 按日期分区生成对象路径，例如 prefix/events/dt=2024-01-02/events-2024-01-02.jsonl.gz
+func (e *Exporter) objectKey(collection string, day time.Time) string {
+	date := day.Format(dayLayout)
+	return path.Join(e.prefix, collection, "dt="+date, fmt.Sprintf("%s-%s.jsonl.gz", collection, date))
+}
+
+func (e *Exporter) loadCheckpoint(ctx context.Context, collection string) (*checkpoint, error) {
+	data, err := e.store.Get(ctx, checkpointBucket, collection)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archive checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to decode archive checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func (e *Exporter) saveCheckpoint(ctx context.Context, collection string, day time.Time) error {
+	data, err := json.Marshal(checkpoint{
+		LastDay:    day.Format(dayLayout),
+		ExportedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.store.Put(ctx, checkpointBucket, collection, data); err != nil {
+		return fmt.Errorf("failed to save archive checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package assistant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+)
+
+// maxIterations 单次提问中模型调用工具的最大轮数，超过后要求模型直接作答
+const maxIterations = 6
+
+// SystemPrompt 集群问答的系统提示词
+const SystemPrompt = `You are an assistant for operators of a Kubernetes cluster that also manages edge nodes with attached UAVs (drones).
+Answer questions about the current and recent state of the cluster using the provided tools. Always call tools to get data instead of guessing; call several tools if needed.
+All tools are read-only. If the data needed to answer is not available, say so.
+Answer concisely in the language of the question. Mention specific pod, node or UAV names and the values that support the answer.`
+
+// Step 模型在一次提问中发起的工具调用
+type Step struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+	Error     string          `json:"error,omitempty"`
+	Duration  time.Duration   `json:"duration_ns"`
+}
+
+// Answer 问答结果
+type Answer struct {
+	RunID      string    `json:"run_id"` // 工具调用审计日志的 resource
+	Answer     string    `json:"answer"`
+	Steps      []Step    `json:"steps"`
+	Iterations int       `json:"iterations"`
+	Model      string    `json:"model"`
+	Usage      llm.Usage `json:"usage"`
+	Cached     bool      `json:"cached,omitempty"` // 所有模型调用均命中回答缓存
+}
+
+// Observer 接收问答过程中的中间结果（用于流式输出），字段均可为空
+type Observer struct {
+	Token llm.StreamHandler // 模型输出片段；设置后以流式方式调用模型
+	Step  func(Step)        // 每次工具调用完成后回调
+}
+
+// runKey 上下文中问答信息的键
+type runKey struct{}
+
+// runInfo 一次问答的标识和所属分析
+type runInfo struct {
+	id       string
+	analysis string
+}
+
+// WithAnalysis 标记问答所属的分析（如 query、chat/<会话ID>），记录在工具调用的审计日志中
+func WithAnalysis(ctx context.Context, analysis string) context.Context {
+	run := runFrom(ctx)
+	run.analysis = analysis
+	return context.WithValue(ctx, runKey{}, run)
+}
+
+func runFrom(ctx context.Context) runInfo {
+	run, _ := ctx.Value(runKey{}).(runInfo)
+	return run
+}
+
+func newRunID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("run-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("run-%s-%s", time.Now().UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
+
+// Ask 执行工具调用循环直到模型给出最终回答。
+// messages 为完整的对话（含系统提示词），返回值中的对话包含本轮的工具调用和回答，可用于多轮对话
+func Ask(ctx context.Context, client llm.Client, tools *Toolset, messages []llm.Message) (*Answer, []llm.Message, error) {
+	return AskStream(ctx, client, tools, messages, Observer{})
+}
+
+// AskStream 与 Ask 相同，同时将模型输出和工具调用实时交给 observer
+func AskStream(ctx context.Context, client llm.Client, tools *Toolset, messages []llm.Message, observer Observer) (*Answer, []llm.Message, error) {
+	run := runFrom(ctx)
+	run.id = newRunID()
+	ctx = context.WithValue(ctx, runKey{}, run)
+	answer := &Answer{RunID: run.id, Steps: []Step{}, Cached: true}
+
+	for iteration := 1; ; iteration++ {
+		request := llm.Request{Messages: messages}
+		// 达到轮数上限后不再提供工具，要求模型根据已有结果作答
+		if iteration < maxIterations {
+			request.Tools = tools.Specs()
+		}
+
+		var resp *llm.Response
+		var err error
+		if observer.Token != nil {
+			resp, err = client.Stream(ctx, request, observer.Token)
+		} else {
+			resp, err = client.Chat(ctx, request)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		answer.Iterations = iteration
+		answer.Model = resp.Model
+		answer.Usage.Add(resp.Usage)
+		answer.Cached = answer.Cached && resp.Cached
+
+		messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: resp.Content, ToolCalls: resp.ToolCalls})
+		if len(resp.ToolCalls) == 0 {
+			answer.Answer = resp.Content
+			return answer, messages, nil
+		}
+		if iteration >= maxIterations {
+			return nil, nil, fmt.Errorf("model kept calling tools after %d iterations", maxIterations)
+		}
+
+		for _, call := range resp.ToolCalls {
+			start := time.Now()
+			content, err := tools.Call(ctx, call)
+			step := Step{Tool: call.Name, Arguments: call.Arguments, Duration: time.Since(start)}
+			if !json.Valid(step.Arguments) {
+				step.Arguments, _ = json.Marshal(string(call.Arguments))
+			}
+			if err != nil {
+				// 工具错误交给模型处理（例如换一个参数重试或说明数据不可用）
+				step.Error = err.Error()
+				data, _ := json.Marshal(map[string]string{"error": err.Error()})
+				content = string(data)
+			}
+			answer.Steps = append(answer.Steps, step)
+			if observer.Step != nil {
+				observer.Step(step)
+			}
+			messages = append(messages, llm.Message{Role: llm.RoleTool, Content: content, ToolCallID: call.ID})
+		}
+	}
+}
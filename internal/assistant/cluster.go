@@ -0,0 +1,566 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 工具返回条目数量的默认值和上限
+const (
+	defaultToolLimit = 50
+	maxToolLimit     = 200
+)
+
+// errNoMetrics 指标采集未启用
+var errNoMetrics = errors.New("metrics collection is not available")
+
+// errNoK8s 没有K8s连接（开发模式）
+var errNoK8s = errors.New("kubernetes connection is not available")
+
+// 日志工具的默认行数和上限
+const (
+	defaultLogLines = 100
+	maxLogLines     = 500
+)
+
+// 工具访问K8s API的超时
+const (
+	logsTimeout         = 15 * time.Second
+	connectivityTimeout = 60 * time.Second
+)
+
+// clusterTools 基于指标管理器、K8s客户端和事件历史实现的工具
+type clusterTools struct {
+	analysis.Sources
+}
+
+// ClusterTools 集群状态的只读查询工具；sources.History 不为空时包括历史检索
+func ClusterTools(sources analysis.Sources) []Tool {
+	c := clusterTools{sources}
+	tools := []Tool{
+		{
+			Tool: llm.Tool{
+				Name:        "get_cluster_summary",
+				Description: "Get cluster-wide totals: node and pod counts, CPU/memory usage rates, GPU counts, overall health status and issues.",
+				Parameters:  schema(map[string]interface{}{}),
+			},
+			Run: c.clusterSummary,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "list_nodes",
+				Description: "List nodes with health, conditions (MemoryPressure, DiskPressure...) and CPU/memory/disk usage rates (0-100). Unhealthy nodes come first.",
+				Parameters:  schema(map[string]interface{}{}),
+			},
+			Run: c.listNodes,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "get_node_metrics",
+				Description: "Get detailed metrics of one node: CPU/memory/disk/network usage, capacity and allocatable resources, pod count, conditions and GPUs.",
+				Parameters: schema(map[string]interface{}{
+					"node": stringProp("Node name"),
+				}),
+			},
+			Run: c.getNodeMetrics,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "list_pods",
+				Description: "List pods with phase, readiness, total restart count, node and CPU/memory usage rates (0-100, relative to limits).",
+				Parameters: schema(map[string]interface{}{
+					"namespace":     stringProp("Only pods in this namespace"),
+					"only_problems": boolProp("Only pods that are not ready, restarted, not running or near their limits"),
+					"sort_by":       stringProp("Sort order, descending: restarts (default), cpu or memory"),
+					"limit":         intProp("Maximum number of pods to return (default 50)"),
+				}),
+			},
+			Run: c.listPods,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "get_pod_logs",
+				Description: "Get the last lines of a pod's container logs. Use previous=true to read the logs of the crashed container instance before the last restart.",
+				Parameters: schema(map[string]interface{}{
+					"pod":        stringProp("Pod as namespace/name"),
+					"container":  stringProp("Container name, required for pods with several containers"),
+					"tail_lines": intProp("Number of lines from the end (default 100, at most 500)"),
+					"previous":   boolProp("Read the previous (terminated) container instance"),
+				}),
+			},
+			Run: c.getPodLogs,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "list_events",
+				Description: "List Kubernetes events in ascending time order (e.g. BackOff, OOMKilling, FailedScheduling, Unhealthy). Use this to find what happened within a time window, such as restarts in the last hour.",
+				Parameters: schema(map[string]interface{}{
+					"namespace": stringProp("Only events in this namespace"),
+					"since":     stringProp("Time window as a Go duration, e.g. 30m or 2h (default 1h)"),
+					"type":      stringProp("Normal or Warning"),
+					"reason":    stringProp("Comma-separated event reasons"),
+					"object":    stringProp("Name of the involved object, e.g. a pod name"),
+					"limit":     intProp("Maximum number of most recent events to return (default 50)"),
+				}),
+			},
+			Run: c.listEvents,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "list_uavs",
+				Description: "List UAVs attached to edge nodes with battery percentage, flight mode, armed state, GPS position, system status and last heartbeat, sorted by battery ascending.",
+				Parameters:  schema(map[string]interface{}{}),
+			},
+			Run: c.listUAVs,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "get_uav_state",
+				Description: "Get the full latest state of one UAV: battery (voltage, current, remaining), GPS, attitude, flight mode, armed state, health and mission progress.",
+				Parameters: schema(map[string]interface{}{
+					"node":   stringProp("Edge node the UAV is attached to"),
+					"uav_id": stringProp("UAV ID, used when node is not given"),
+				}),
+			},
+			Run: c.getUAVState,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "list_network_tests",
+				Description: "List the latest pod-to-pod network test results with connectivity, RTT (ms), packet loss (%) and quality.",
+				Parameters: schema(map[string]interface{}{
+					"pod": stringProp("Only tests where this pod (namespace/name) is the source or target"),
+				}),
+			},
+			Run: c.listNetworkTests,
+		},
+		{
+			Tool: llm.Tool{
+				Name:        "test_connectivity",
+				Description: "Check connectivity between two pods now: pod status, network policies, services, DNS and a ping/HTTP RTT test run inside the pods (only the commands allowed by k8s.exec, never from opted-out pods; no debug container is added to pods whose image lacks the command). Takes several seconds.",
+				Parameters: schema(map[string]interface{}{
+					"source": stringProp("Source pod as namespace/name"),
+					"target": stringProp("Target pod as namespace/name"),
+				}),
+			},
+			Run: c.testConnectivity,
+		},
+	}
+	if sources.History != nil {
+		tools = append(tools, Tool{
+			Tool: llm.Tool{
+				Name:        "search_history",
+				Description: "Search summaries of past cluster snapshots, incidents and UAV telemetry similar to a description, e.g. \"OOMKilled pods on node-1\" or \"uav-1 low battery\". Use this to find whether a problem happened before and how it was explained.",
+				Parameters: schema(map[string]interface{}{
+					"query": stringProp("Description of the situation to search for"),
+					"limit": intProp("Maximum number of results (default from analysis.rag.top_k)"),
+				}),
+			},
+			Run: c.searchHistory,
+		})
+	}
+	return tools
+}
+
+// clampLimit 规范化条目数量
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultToolLimit
+	}
+	if limit > maxToolLimit {
+		return maxToolLimit
+	}
+	return limit
+}
+
+func (c clusterTools) clusterSummary(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+	return c.Metrics.GetClusterMetrics(), nil
+}
+
+func (c clusterTools) listNodes(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+	snapshot := c.Metrics.ViewSnapshot()
+	if snapshot == nil {
+		return []analysis.NodeEvidence{}, nil
+	}
+	return analysis.SummarizeNodes(snapshot.NodeMetrics, maxToolLimit), nil
+}
+
+func (c clusterTools) listPods(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Namespace    string `json:"namespace"`
+		OnlyProblems bool   `json:"only_problems"`
+		SortBy       string `json:"sort_by"`
+		Limit        int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if c.Metrics == nil {
+		return c.listPodsFromK8s(params.Namespace, clampLimit(params.Limit))
+	}
+
+	pods := make([]analysis.PodEvidence, 0)
+	snapshot := c.Metrics.ViewSnapshot()
+	if snapshot == nil {
+		return map[string]interface{}{"pods": pods, "total": 0}, nil
+	}
+	for key, pod := range snapshot.PodMetrics {
+		if params.Namespace != "" && pod.Namespace != params.Namespace {
+			continue
+		}
+		if params.OnlyProblems && !analysis.PodSuspicious(pod) {
+			continue
+		}
+		pods = append(pods, analysis.SummarizePod(key, pod))
+	}
+
+	less := func(a, b analysis.PodEvidence) bool { return a.Restarts > b.Restarts }
+	switch params.SortBy {
+	case "cpu":
+		less = func(a, b analysis.PodEvidence) bool { return a.CPUUsageRate > b.CPUUsageRate }
+	case "memory":
+		less = func(a, b analysis.PodEvidence) bool { return a.MemoryUsageRate > b.MemoryUsageRate }
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		if less(pods[i], pods[j]) != less(pods[j], pods[i]) {
+			return less(pods[i], pods[j])
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	total := len(pods)
+	if limit := clampLimit(params.Limit); len(pods) > limit {
+		pods = pods[:limit]
+	}
+	return map[string]interface{}{"pods": pods, "total": total}, nil
+}
+
+// listPodsFromK8s 指标采集不可用时直接列出Pod（没有资源使用数据）
+func (c clusterTools) listPodsFromK8s(namespace string, limit int) (interface{}, error) {
+	if c.K8s == nil {
+		return nil, errNoMetrics
+	}
+	pods, err := c.K8s.GetPods(namespace)
+	if err != nil {
+		return nil, err
+	}
+	type podStatus struct {
+		Name   string `json:"name"`
+		Node   string `json:"node,omitempty"`
+		Status string `json:"status"`
+	}
+	result := make([]podStatus, 0, len(pods))
+	for _, pod := range pods {
+		result = append(result, podStatus{Name: pod.Namespace + "/" + pod.Name, Node: pod.NodeName, Status: pod.Status})
+	}
+	total := len(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return map[string]interface{}{"pods": result, "total": total}, nil
+}
+
+func (c clusterTools) listEvents(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Namespace string `json:"namespace"`
+		Since     string `json:"since"`
+		Type      string `json:"type"`
+		Reason    string `json:"reason"`
+		Object    string `json:"object"`
+		Limit     int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	window := time.Hour
+	if params.Since != "" {
+		parsed, err := time.ParseDuration(params.Since)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid since %q, expected a duration such as 30m", params.Since)
+		}
+		window = parsed
+	}
+
+	query := events.Query{
+		From:      time.Now().Add(-window),
+		Namespace: params.Namespace,
+		Type:      params.Type,
+		Object:    params.Object,
+		Limit:     clampLimit(params.Limit),
+	}
+	for _, reason := range strings.Split(params.Reason, ",") {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			query.Reasons = append(query.Reasons, reason)
+		}
+	}
+
+	if c.Events != nil {
+		return c.Events.Query(ctx, query)
+	}
+	if c.K8s == nil {
+		return nil, errors.New("event source is not available")
+	}
+
+	// 没有事件历史时直接读取K8s中仍保留的事件
+	list, err := c.K8s.GetEvents(query.Namespace, int64(maxToolLimit*4))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*models.EventInfo, 0)
+	for _, event := range list {
+		if event.Timestamp.Before(query.From) || !query.Matches(event) {
+			continue
+		}
+		result = append(result, event)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	if len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result, nil
+}
+
+// uavStatus 交给模型的UAV状态摘要
+type uavStatus struct {
+	Node           string    `json:"node"`
+	UAVID          string    `json:"uav_id,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	BatteryPercent float64   `json:"battery_percent"`
+	FlightMode     string    `json:"flight_mode,omitempty"`
+	Armed          bool      `json:"armed"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Altitude       float64   `json:"altitude"`
+	SystemStatus   string    `json:"system_status,omitempty"`
+	LastHeartbeat  time.Time `json:"last_heartbeat"`
+}
+
+func (c clusterTools) listUAVs(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+
+	result := make([]uavStatus, 0)
+	for node, entry := range c.Metrics.GetUAVMetrics() {
+		status := uavStatus{Node: node, UAVID: entry.UAVID, Status: entry.Status, LastHeartbeat: entry.LastHeartbeat}
+		if state := entry.State; state != nil {
+			status.BatteryPercent = state.Battery.RemainingPercent
+			status.FlightMode = state.Flight.Mode
+			status.Armed = state.Flight.Armed
+			status.Latitude = state.GPS.Latitude
+			status.Longitude = state.GPS.Longitude
+			status.Altitude = state.GPS.RelativeAltitude
+			status.SystemStatus = state.Health.SystemStatus
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BatteryPercent < result[j].BatteryPercent })
+	return result, nil
+}
+
+func (c clusterTools) listNetworkTests(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Pod string `json:"pod"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+
+	type networkTest struct {
+		Source     string  `json:"source"`
+		Target     string  `json:"target"`
+		Connected  bool    `json:"connected"`
+		RTT        float64 `json:"rtt_ms"`
+		PacketLoss float64 `json:"packet_loss"`
+		Quality    string  `json:"quality"`
+		Error      string  `json:"error,omitempty"`
+	}
+	result := make([]networkTest, 0)
+	for _, test := range c.Metrics.GetNetworkMetrics() {
+		if params.Pod != "" && test.SourcePod != params.Pod && test.TargetPod != params.Pod {
+			continue
+		}
+		result = append(result, networkTest{
+			Source:     test.SourcePod,
+			Target:     test.TargetPod,
+			Connected:  test.Connected,
+			RTT:        test.RTT,
+			PacketLoss: test.PacketLoss,
+			Quality:    test.GetQuality(),
+			Error:      test.Error,
+		})
+	}
+	return result, nil
+}
+
+func (c clusterTools) searchHistory(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	if params.Limit > defaultToolLimit {
+		params.Limit = defaultToolLimit
+	}
+	return c.History.Similar(ctx, params.Query, time.Time{}, params.Limit)
+}
+
+func (c clusterTools) getNodeMetrics(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Node string `json:"node"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if params.Node == "" {
+		return nil, errors.New("node is required")
+	}
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+	return c.Metrics.GetNodeMetrics(params.Node)
+}
+
+// splitPod 解析 namespace/name，省略命名空间时为 default
+func splitPod(pod string) (namespace, name string, err error) {
+	pod = strings.TrimSpace(pod)
+	if pod == "" {
+		return "", "", errors.New("pod is required")
+	}
+	namespace, name, found := strings.Cut(pod, "/")
+	if !found {
+		return "default", pod, nil
+	}
+	if namespace == "" || name == "" {
+		return "", "", fmt.Errorf("invalid pod %q, expected namespace/name", pod)
+	}
+	return namespace, name, nil
+}
+
+func (c clusterTools) getPodLogs(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Pod       string `json:"pod"`
+		Container string `json:"container"`
+		TailLines int    `json:"tail_lines"`
+		Previous  bool   `json:"previous"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	namespace, name, err := splitPod(params.Pod)
+	if err != nil {
+		return nil, err
+	}
+	if c.K8s == nil {
+		return nil, errNoK8s
+	}
+	lines := params.TailLines
+	if lines <= 0 {
+		lines = defaultLogLines
+	}
+	if lines > maxLogLines {
+		lines = maxLogLines
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, logsTimeout)
+	defer cancel()
+	logs, err := c.K8s.GetPodLogsWithOptions(ctx, namespace, name, k8s.LogOptions{
+		Container:  params.Container,
+		TailLines:  int64(lines),
+		Previous:   params.Previous,
+		LimitBytes: maxToolResult,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"pod":       namespace + "/" + name,
+		"container": params.Container,
+		"previous":  params.Previous,
+		"logs":      logs,
+	}, nil
+}
+
+func (c clusterTools) getUAVState(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Node  string `json:"node"`
+		UAVID string `json:"uav_id"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if params.Node == "" && params.UAVID == "" {
+		return nil, errors.New("node or uav_id is required")
+	}
+	if c.Metrics == nil {
+		return nil, errNoMetrics
+	}
+
+	for node, entry := range c.Metrics.GetUAVMetrics() {
+		if (params.Node != "" && node != params.Node) || (params.Node == "" && entry.UAVID != params.UAVID) {
+			continue
+		}
+		return map[string]interface{}{
+			"node":           node,
+			"uav_id":         entry.UAVID,
+			"status":         entry.Status,
+			"last_heartbeat": entry.LastHeartbeat,
+			"state":          entry.State,
+		}, nil
+	}
+	if params.Node != "" {
+		return nil, fmt.Errorf("no UAV reported from node %s", params.Node)
+	}
+	return nil, fmt.Errorf("UAV %s not found", params.UAVID)
+}
+
+func (c clusterTools) testConnectivity(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	sourceNamespace, sourceName, err := splitPod(params.Source)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	targetNamespace, targetName, err := splitPod(params.Target)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	if c.K8s == nil {
+		return nil, errNoK8s
+	}
+
+	// 工具只读：不向Pod添加临时调试容器
+	ctx, cancel := context.WithTimeout(k8s.WithoutDebugContainer(ctx), connectivityTimeout)
+	defer cancel()
+	return k8s.NewNetworkAnalyzer(c.K8s).AnalyzePodCommunication(ctx, sourceNamespace+"/"+sourceName, targetNamespace+"/"+targetName)
+}
@@ -0,0 +1,222 @@
+package assistant
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// sessionBucket 会话在存储中的桶名
+const sessionBucket = "chat_sessions"
+
+// TypeChat 对话在 llm.routing 中使用的分析类型
+const TypeChat = "chat"
+
+// 会话限制
+const (
+	maxHistoryTurns = 10               // 每轮交给模型的历史轮数，更早的对话不再发送
+	maxTitleLength  = 80               // 会话标题（默认取第一个问题）的最大长度
+	contextWindow   = 15 * time.Minute // 每轮注入的集群现状中事件的时间窗口
+)
+
+// ErrSessionNotFound 会话不存在
+var ErrSessionNotFound = errors.New("chat session not found")
+
+// chatPrompt 多轮对话在问答提示词之外的说明
+const chatPrompt = `
+This is a multi-turn conversation. Each user message starts with a fresh snapshot of the cluster state taken when the message was sent; prefer it over data from earlier turns, and use tools for details it does not contain.`
+
+// Session 多轮对话会话；Messages 为保存的对话（不含每轮注入的集群现状）
+type Session struct {
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	Profile   string        `json:"profile,omitempty"`
+	Turns     int           `json:"turns"`
+	Usage     llm.Usage     `json:"usage"`
+	Messages  []llm.Message `json:"messages"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Sessions 对话会话管理：保存历史，每轮注入最新的集群现状
+type Sessions struct {
+	store   storage.Store
+	service *llm.Service
+	tools   *Toolset
+	sources analysis.Sources
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // 同一会话的消息依次处理
+}
+
+// NewSessions 创建会话管理
+func NewSessions(store storage.Store, service *llm.Service, tools *Toolset, sources analysis.Sources) *Sessions {
+	return &Sessions{
+		store:   store,
+		service: service,
+		tools:   tools,
+		sources: sources,
+		locks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// Create 创建会话；profile 为该会话默认使用的LLM配置，可为空
+func (s *Sessions) Create(ctx context.Context, title, profile string) (*Session, error) {
+	now := time.Now().UTC()
+	session := &Session{
+		ID:        newSessionID(),
+		Title:     truncate(strings.TrimSpace(title), maxTitleLength),
+		Profile:   profile,
+		Messages:  []llm.Message{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.save(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Get 读取会话
+func (s *Sessions) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := s.store.Get(ctx, sessionBucket, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode chat session %s: %w", id, err)
+	}
+	return &session, nil
+}
+
+// Send 发送一条消息并返回回答；profile 为空时使用会话的配置
+func (s *Sessions) Send(ctx context.Context, id, message, profile string, observer Observer) (*Answer, error) {
+	lock := s.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if profile == "" {
+		profile = session.Profile
+	}
+	client, err := s.service.Client(TypeChat, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	snapshot, err := s.clusterContext(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发给模型的用户消息带有集群现状，保存的历史只记录原始问题
+	messages := []llm.Message{llm.SystemMessage(SystemPrompt + chatPrompt + "\nCurrent time: " + now.Format(time.RFC3339))}
+	messages = append(messages, recentTurns(session.Messages, maxHistoryTurns)...)
+	sent := len(messages)
+	messages = append(messages, llm.UserMessage(snapshot+"\n\n"+message))
+
+	answer, messages, err := AskStream(WithAnalysis(ctx, "chat/"+id), client, s.tools, messages, observer)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Messages = append(session.Messages, llm.UserMessage(message))
+	session.Messages = append(session.Messages, messages[sent+1:]...)
+	session.Turns++
+	session.Usage.Add(answer.Usage)
+	session.UpdatedAt = time.Now().UTC()
+	if session.Title == "" {
+		session.Title = truncate(message, maxTitleLength)
+	}
+	if err := s.save(ctx, session); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// clusterContext 每轮注入的集群现状：最新指标快照摘要和最近的事件
+func (s *Sessions) clusterContext(ctx context.Context, now time.Time) (string, error) {
+	evidence := s.sources.Collect(ctx, analysis.Scope{Since: contextWindow})
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cluster context: %w", err)
+	}
+
+	taken := "no metrics snapshot"
+	if !evidence.SnapshotTime.IsZero() {
+		taken = "metrics snapshot at " + evidence.SnapshotTime.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("[Cluster state at %s (%s), events from the last %s]\n%s",
+		now.Format(time.RFC3339), taken, contextWindow, data), nil
+}
+
+// save 保存会话
+func (s *Sessions) save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat session: %w", err)
+	}
+	if err := s.store.Put(ctx, sessionBucket, session.ID, data); err != nil {
+		return fmt.Errorf("failed to store chat session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// lock 返回会话的互斥锁
+func (s *Sessions) lock(id string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[id] = lock
+	}
+	return lock
+}
+
+// recentTurns 保留最近 turns 轮对话（每轮从用户消息开始，包含工具调用和回答）
+func recentTurns(messages []llm.Message, turns int) []llm.Message {
+	start := len(messages)
+	for i := len(messages) - 1; i >= 0 && turns > 0; i-- {
+		if messages[i].Role == llm.RoleUser {
+			start = i
+			turns--
+		}
+	}
+	return messages[start:]
+}
+
+// truncate 按字符截断
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func newSessionID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("chat-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("chat-%s-%s", time.Now().UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
@@ -0,0 +1,139 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+)
+
+// maxToolResult 工具结果交给模型的最大长度（字节），超出部分截断
+const maxToolResult = 16 << 10
+
+// ToolFunc 执行工具，args为模型给出的JSON参数
+type ToolFunc func(ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// Tool 模型可以调用的只读工具
+type Tool struct {
+	llm.Tool
+	Run ToolFunc
+}
+
+// Toolset 工具集合，按名称查找；模型只能调用集合中的工具
+type Toolset struct {
+	tools map[string]Tool
+	audit *audit.Logger
+}
+
+// NewToolset 创建工具集合
+func NewToolset(tools ...Tool) *Toolset {
+	set := &Toolset{tools: make(map[string]Tool, len(tools))}
+	for _, tool := range tools {
+		set.tools[tool.Name] = tool
+	}
+	return set
+}
+
+// SetAudit 将每次工具调用记录到审计日志（action 为 llm.tool_call，resource 为问答的 run_id）
+func (s *Toolset) SetAudit(log *audit.Logger) {
+	s.audit = log
+}
+
+// Specs 交给模型的工具定义（按名称排序）
+func (s *Toolset) Specs() []llm.Tool {
+	specs := make([]llm.Tool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		specs = append(specs, tool.Tool)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+// Call 执行一次工具调用，返回交给模型的JSON文本
+func (s *Toolset) Call(ctx context.Context, call llm.ToolCall) (string, error) {
+	start := time.Now()
+	content, err := s.call(ctx, call)
+	s.record(ctx, call, len(content), time.Since(start), err)
+	return content, err
+}
+
+// record 记录工具调用的审计日志：操作者为发起问答的请求方，resource 为问答的 run_id
+func (s *Toolset) record(ctx context.Context, call llm.ToolCall, size int, duration time.Duration, err error) {
+	if s.audit == nil {
+		return
+	}
+	run := runFrom(ctx)
+	if run.id == "" {
+		run.id = "tool/" + call.Name
+	}
+	details := map[string]interface{}{
+		"tool":         call.Name,
+		"arguments":    string(call.Arguments),
+		"duration_ms":  duration.Milliseconds(),
+		"result_bytes": size,
+	}
+	if run.analysis != "" {
+		details["analysis"] = run.analysis
+	}
+	actor, remoteAddr := audit.FromContext(ctx)
+	s.audit.Log(ctx, actor, remoteAddr, audit.ActionToolCall, run.id, details, err)
+}
+
+func (s *Toolset) call(ctx context.Context, call llm.ToolCall) (string, error) {
+	tool, ok := s.tools[call.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", call.Name)
+	}
+
+	result, err := tool.Run(ctx, call.Arguments)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s result: %w", call.Name, err)
+	}
+	if len(data) > maxToolResult {
+		return string(data[:maxToolResult]) + "...(truncated)", nil
+	}
+	return string(data), nil
+}
+
+// decodeArgs 解析工具参数，空参数视为 {}
+func decodeArgs(args json.RawMessage, v interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// schema 构造工具参数的JSON Schema
+func schema(properties map[string]interface{}) json.RawMessage {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	})
+	return data
+}
+
+// stringProp JSON Schema 字符串属性
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+// intProp JSON Schema 整数属性
+func intProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description}
+}
+
+// boolProp JSON Schema 布尔属性
+func boolProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
@@ -0,0 +1,299 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// Collection 审计日志在存储中的集合名
+const Collection = "audit"
+
+// 审计动作
+const (
+	ActionUAVCommand     = "uav.command"     // 向UAV发送控制命令
+	ActionUAVReport      = "uav.report"      // UAV状态上报（仅记录被拒绝的上报）
+	ActionCRDUpsert      = "crd.upsert"      // 创建或更新CRD资源
+	ActionRemediation    = "remediation"     // 执行自动修复
+	ActionConfigChange   = "config.change"   // 配置变更
+	ActionRestore        = "storage.restore" // 从备份恢复数据
+	ActionFederationPeer = "federation.peer" // 注册或删除联邦对端
+	ActionToolCall       = "llm.tool_call"   // LLM在分析中调用只读工具
+)
+
+// 操作结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// 系统内部发起操作时使用的身份
+const (
+	ActorSystem    = "system"
+	ActorAnonymous = "anonymous"
+)
+
+// Entry 一条审计记录
+type Entry struct {
+	ID         string                 `json:"id"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Actor      string                 `json:"actor"`
+	RemoteAddr string                 `json:"remote_addr,omitempty"`
+	Action     string                 `json:"action"`
+	Resource   string                 `json:"resource"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// Filter 审计记录查询条件
+type Filter struct {
+	Action   string
+	Resource string
+	Actor    string
+	Outcome  string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// Logger 审计日志记录器（写入存储集合，并可同时追加到本地JSONL文件）
+type Logger struct {
+	store  storage.Store
+	logger *logrus.Entry
+
+	file   *os.File
+	fileMu sync.Mutex
+}
+
+// NewLogger 创建审计日志记录器，path为空时只写入存储
+func NewLogger(store storage.Store, path string) (*Logger, error) {
+	l := &Logger{store: store, logger: logging.For("audit")}
+
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+		}
+		l.file = file
+	}
+
+	return l, nil
+}
+
+// Record 记录一条审计日志；记录器为nil时忽略
+func (l *Logger) Record(ctx context.Context, entry *Entry) error {
+	if l == nil || entry == nil {
+		return nil
+	}
+
+	if entry.ID == "" {
+		entry.ID = newID()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.Actor == "" {
+		entry.Actor = ActorAnonymous
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	l.logger.WithFields(logrus.Fields{
+		"audit":    true,
+		"actor":    entry.Actor,
+		"action":   entry.Action,
+		"resource": entry.Resource,
+		"outcome":  entry.Outcome,
+	}).Info("audit")
+
+	var errs []string
+	if l.file != nil {
+		l.fileMu.Lock()
+		_, err := l.file.Write(append(data, '\n'))
+		l.fileMu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("file: %v", err))
+		}
+	}
+
+	if l.store != nil {
+		if err := l.store.Append(ctx, Collection, &storage.Record{
+			ID:        entry.ID,
+			Key:       entry.Action,
+			Timestamp: entry.Timestamp,
+			Data:      data,
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("store: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to record audit entry %s: %s", entry.ID, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Log 记录一次操作，err不为nil时结果记为失败
+func (l *Logger) Log(ctx context.Context, actor, remoteAddr, action, resource string, details map[string]interface{}, err error) {
+	if l == nil {
+		return
+	}
+
+	entry := &Entry{
+		Actor:      actor,
+		RemoteAddr: remoteAddr,
+		Action:     action,
+		Resource:   resource,
+		Outcome:    OutcomeSuccess,
+		Details:    details,
+	}
+	if err != nil {
+		entry.Outcome = OutcomeFailure
+		entry.Error = err.Error()
+	}
+
+	if recordErr := l.Record(ctx, entry); recordErr != nil {
+		l.logger.Warnf("Failed to write audit log: %v", recordErr)
+	}
+}
+
+// LogRequest 记录由HTTP请求触发的操作，身份从请求中解析
+func (l *Logger) LogRequest(r *http.Request, action, resource string, details map[string]interface{}, err error) {
+	if l == nil {
+		return
+	}
+	l.Log(r.Context(), ActorFromRequest(r), ClientAddr(r), action, resource, details, err)
+}
+
+// Query 查询审计记录（按时间升序）
+func (l *Logger) Query(ctx context.Context, filter Filter) ([]*Entry, error) {
+	if l == nil || l.store == nil {
+		return []*Entry{}, nil
+	}
+
+	records, err := l.store.List(ctx, Collection, storage.Query{
+		Key:  filter.Action,
+		From: filter.From,
+		To:   filter.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	result := make([]*Entry, 0, len(records))
+	for _, rec := range records {
+		var entry Entry
+		if err := json.Unmarshal(rec.Data, &entry); err != nil {
+			continue
+		}
+		if filter.Resource != "" && entry.Resource != filter.Resource {
+			continue
+		}
+		if filter.Actor != "" && !strings.EqualFold(entry.Actor, filter.Actor) {
+			continue
+		}
+		if filter.Outcome != "" && !strings.EqualFold(entry.Outcome, filter.Outcome) {
+			continue
+		}
+		result = append(result, &entry)
+	}
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result, nil
+}
+
+// Close 关闭审计日志文件
+func (l *Logger) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	l.fileMu.Lock()
+	defer l.fileMu.Unlock()
+	return l.file.Close()
+}
+
+// ActorFromRequest 从请求中解析操作者身份：
+// 优先使用认证代理传入的用户头，其次是Bearer Token的哈希摘要（不记录Token本身），最后为匿名
+func ActorFromRequest(r *http.Request) string {
+	for _, header := range []string{"X-Remote-User", "X-Forwarded-User", "X-Actor"} {
+		if user := strings.TrimSpace(r.Header.Get(header)); user != "" {
+			return user
+		}
+	}
+
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		sum := sha256.Sum256([]byte(strings.TrimPrefix(auth, "Bearer ")))
+		return "token:" + hex.EncodeToString(sum[:4])
+	}
+
+	return ActorAnonymous
+}
+
+// originKey 上下文中操作来源的键
+type originKey struct{}
+
+// origin 发起操作的身份和地址
+type origin struct {
+	actor      string
+	remoteAddr string
+}
+
+// WithRequest 将请求的操作者身份和来源地址放入上下文，供后续在请求处理之外记录审计日志（如LLM的工具调用）
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, originKey{}, origin{actor: ActorFromRequest(r), remoteAddr: ClientAddr(r)})
+}
+
+// FromContext 返回上下文中的操作者身份和来源地址，没有时为系统
+func FromContext(ctx context.Context) (actor, remoteAddr string) {
+	if o, ok := ctx.Value(originKey{}).(origin); ok {
+		return o.actor, o.remoteAddr
+	}
+	return ActorSystem, ""
+}
+
+// ClientAddr 解析请求来源地址
+func ClientAddr(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// newID 生成审计记录ID
+func newID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("aud-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("aud-%s-%s", time.Now().UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
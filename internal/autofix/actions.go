@@ -0,0 +1,310 @@
+package autofix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 修复动作类型
+const (
+	ActionRestartPod      = "restart_pod"
+	ActionScaleDeployment = "scale_deployment"
+	ActionCordonNode      = "cordon_node"
+)
+
+// 动作来源
+const (
+	SourceRemediation = "remediation" // 分析结果中给出的kubectl命令
+	SourceRules       = "rules"       // 按分析证据中的Pod和节点状态生成
+)
+
+// restartThreshold 未就绪且重启次数达到该值的Pod建议重启
+const restartThreshold = 3
+
+// maxReplicas 扩缩容允许的最大副本数
+const maxReplicas = 50
+
+// Action 修复动作（等价于一条kubectl命令）
+type Action struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Replicas  *int32 `json:"replicas,omitempty"` // scale_deployment 的目标副本数
+	Command   string `json:"command"`            // 等价的kubectl命令
+	Reason    string `json:"reason"`
+	Source    string `json:"source"`
+	Risk      string `json:"risk"` // low、medium、high
+
+	CurrentReplicas *int32        `json:"current_replicas,omitempty"` // 预演时的副本数
+	DryRun          string        `json:"dry_run"`                    // 预演结果
+	DryRunError     string        `json:"dry_run_error,omitempty"`
+	Result          *ActionResult `json:"result,omitempty"` // 确认执行后的结果
+}
+
+// Skipped 分析结果中无法转换为修复动作的命令
+type Skipped struct {
+	Command string `json:"command"`
+	Reason  string `json:"reason"`
+}
+
+func (a *Action) key() string {
+	return a.Type + "/" + a.Namespace + "/" + a.Name
+}
+
+// resource 审计日志中的资源名
+func (a *Action) resource() string {
+	switch a.Type {
+	case ActionRestartPod:
+		return "pod/" + a.Namespace + "/" + a.Name
+	case ActionScaleDeployment:
+		return "deployment/" + a.Namespace + "/" + a.Name
+	default:
+		return "node/" + a.Name
+	}
+}
+
+func restartPod(namespace, name string) *Action {
+	return &Action{
+		Type:      ActionRestartPod,
+		Namespace: namespace,
+		Name:      name,
+		Command:   fmt.Sprintf("kubectl delete pod %s -n %s", name, namespace),
+		Risk:      "low",
+	}
+}
+
+func scaleDeployment(namespace, name string, replicas int32) *Action {
+	risk := "medium"
+	if replicas == 0 {
+		risk = "high"
+	}
+	return &Action{
+		Type:      ActionScaleDeployment,
+		Namespace: namespace,
+		Name:      name,
+		Replicas:  &replicas,
+		Command:   fmt.Sprintf("kubectl scale deployment %s --replicas=%d -n %s", name, replicas, namespace),
+		Risk:      risk,
+	}
+}
+
+func cordonNode(name string) *Action {
+	return &Action{
+		Type:    ActionCordonNode,
+		Name:    name,
+		Command: "kubectl cordon " + name,
+		Risk:    "high",
+	}
+}
+
+// planner 收集动作并去重
+type planner struct {
+	actions []*Action
+	skipped []Skipped
+	seen    map[string]bool
+}
+
+func (p *planner) add(action *Action, reason, source string) {
+	if p.seen == nil {
+		p.seen = make(map[string]bool)
+	}
+	if p.seen[action.key()] {
+		return
+	}
+	p.seen[action.key()] = true
+	action.Reason = reason
+	action.Source = source
+	p.actions = append(p.actions, action)
+}
+
+func (p *planner) skip(command, reason string) {
+	p.skipped = append(p.skipped, Skipped{Command: command, Reason: reason})
+}
+
+// commands 从一段修复建议中拆出kubectl命令，其他命令记为跳过
+func (p *planner) commands(text, reason, risk string) {
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		for _, command := range strings.Split(line, "&&") {
+			command = strings.Trim(strings.TrimSpace(command), "`$ ")
+			if command == "" {
+				continue
+			}
+			if !strings.HasPrefix(command, "kubectl ") {
+				p.skip(command, "not a kubectl command")
+				continue
+			}
+			action, err := parseKubectl(command)
+			if err != nil {
+				p.skip(command, err.Error())
+				continue
+			}
+			if level := strings.ToLower(risk); level == "low" || level == "medium" || level == "high" {
+				action.Risk = level
+			}
+			p.add(action, reason, SourceRemediation)
+		}
+	}
+}
+
+// fromRootCause 根因分析：解析修复建议中的命令，并为反复重启的Pod和异常节点生成动作
+func (p *planner) fromRootCause(result *analysis.RootCauseResult) {
+	for _, step := range result.Remediation {
+		if step.Command != "" {
+			p.commands(step.Command, step.Action, step.Risk)
+		}
+	}
+	if result.Evidence == nil {
+		return
+	}
+	for _, pod := range result.Evidence.Pods {
+		if pod.Ready || pod.Restarts < restartThreshold {
+			continue
+		}
+		namespace, name := splitPod(pod.Name)
+		p.add(restartPod(namespace, name), fmt.Sprintf("pod %s is not ready after %d restarts", pod.Name, pod.Restarts), SourceRules)
+	}
+	for _, node := range result.Evidence.Nodes {
+		if node.Healthy {
+			continue
+		}
+		reason := fmt.Sprintf("node %s is unhealthy", node.Name)
+		if len(node.Conditions) > 0 {
+			reason += ": " + strings.Join(node.Conditions, ", ")
+		}
+		p.add(cordonNode(node.Name), reason, SourceRules)
+	}
+}
+
+// fromCommunication Pod通信分析：解析诊断和建议中的命令，并为容器未就绪或已失败的Pod生成重启动作
+func (p *planner) fromCommunication(result *models.CommunicationAnalysis) {
+	for _, diagnosis := range result.Diagnoses {
+		if diagnosis.Fix != "" && strings.Contains(diagnosis.Fix, "kubectl ") {
+			p.commands(diagnosis.Fix, diagnosis.Cause, "")
+		}
+	}
+	for _, solution := range result.Solutions {
+		if strings.Contains(solution, "kubectl ") {
+			p.commands(solution[strings.Index(solution, "kubectl "):], solution, "")
+		}
+	}
+	if result.Findings == nil {
+		return
+	}
+	for _, pod := range []*models.PodInfo{result.Findings.PodA, result.Findings.PodB} {
+		if pod == nil {
+			continue
+		}
+		switch {
+		case pod.Status == "Failed":
+			p.add(restartPod(pod.Namespace, pod.Name), fmt.Sprintf("pod %s/%s has failed", pod.Namespace, pod.Name), SourceRules)
+		case pod.Status == "Running":
+			for _, container := range pod.Containers {
+				if !container.Ready {
+					p.add(restartPod(pod.Namespace, pod.Name), fmt.Sprintf("container %s of pod %s/%s is not ready", container.Name, pod.Namespace, pod.Name), SourceRules)
+					break
+				}
+			}
+		}
+	}
+}
+
+// parseKubectl 解析支持的kubectl命令：delete pod、scale deployment、cordon
+func parseKubectl(command string) (*Action, error) {
+	var args []string
+	namespace := "default"
+	var replicas *int32
+
+	fields := strings.Fields(command)[1:]
+	for i := 0; i < len(fields); i++ {
+		field := strings.Trim(fields[i], `"'`)
+		value := func() (string, error) {
+			if eq := strings.Index(field, "="); eq >= 0 {
+				return field[eq+1:], nil
+			}
+			if i+1 >= len(fields) {
+				return "", fmt.Errorf("flag %s requires a value", field)
+			}
+			i++
+			return strings.Trim(fields[i], `"'`), nil
+		}
+		switch {
+		case field == "-n" || field == "--namespace" || strings.HasPrefix(field, "--namespace="):
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			namespace = v
+		case field == "--replicas" || strings.HasPrefix(field, "--replicas="):
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > maxReplicas {
+				return nil, fmt.Errorf("replicas must be between 0 and %d", maxReplicas)
+			}
+			count := int32(n)
+			replicas = &count
+		case strings.HasPrefix(field, "-"):
+			return nil, fmt.Errorf("unsupported flag %s", field)
+		default:
+			args = append(args, field)
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty kubectl command")
+	}
+
+	verb, rest := args[0], args[1:]
+	// "pod/name" 与 "pod name" 两种写法
+	if len(rest) == 1 && strings.Contains(rest[0], "/") {
+		rest = strings.SplitN(rest[0], "/", 2)
+	}
+	switch verb {
+	case "delete":
+		if len(rest) != 2 || !isKind(rest[0], "pod", "pods", "po") {
+			return nil, fmt.Errorf("only deleting a single pod is supported")
+		}
+		return restartPod(namespace, rest[1]), nil
+	case "scale":
+		if len(rest) != 2 || !isKind(rest[0], "deployment", "deployments", "deploy") {
+			return nil, fmt.Errorf("only scaling a single deployment is supported")
+		}
+		if replicas == nil {
+			return nil, fmt.Errorf("--replicas is required")
+		}
+		return scaleDeployment(namespace, rest[1], *replicas), nil
+	case "cordon":
+		if len(rest) == 2 && isKind(rest[0], "node", "nodes", "no") {
+			rest = rest[1:]
+		}
+		if len(rest) != 1 {
+			return nil, fmt.Errorf("only cordoning a single node is supported")
+		}
+		return cordonNode(rest[0]), nil
+	default:
+		return nil, fmt.Errorf("unsupported kubectl command %q (supported: delete pod, scale deployment, cordon)", verb)
+	}
+}
+
+func isKind(kind string, names ...string) bool {
+	kind = strings.ToLower(kind)
+	for _, name := range names {
+		if kind == name || strings.HasPrefix(kind, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPod 拆分 namespace/name，未指定namespace时为default
+func splitPod(ref string) (string, string) {
+	if i := strings.Index(ref, "/"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return "default", ref
+}
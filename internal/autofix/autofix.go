@@ -0,0 +1,366 @@
+package autofix
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// Collection 修复计划在存储中的集合名
+const Collection = "autofix_plans"
+
+// 计划状态
+const (
+	StatusProposed = "proposed" // 等待确认
+	StatusExecuted = "executed" // 所有动作执行成功
+	StatusFailed   = "failed"   // 有动作执行失败
+	StatusExpired  = "expired"  // 超过有效期未确认
+)
+
+// 动作执行结果
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+	ResultSkipped   = "skipped"
+)
+
+// 预演结果
+const (
+	DryRunPassed  = "passed"
+	DryRunFailed  = "failed"
+	DryRunSkipped = "skipped" // 没有K8s连接
+)
+
+// planTTL 计划的有效期，过期后需要重新生成（集群状态可能已经变化）
+const planTTL = 15 * time.Minute
+
+// actionTimeout 单个动作（预演或执行）的超时时间
+const actionTimeout = 15 * time.Second
+
+var (
+	// ErrNotFound 计划不存在
+	ErrNotFound = errors.New("autofix plan not found")
+	// ErrUnsupported 分析类型不支持生成修复计划
+	ErrUnsupported = errors.New("analysis type does not support autofix")
+	// ErrNotPending 计划已执行或已过期
+	ErrNotPending = errors.New("autofix plan is not pending confirmation")
+	// ErrNoActions 计划中没有预演通过的动作
+	ErrNoActions = errors.New("autofix plan has no actions")
+	// ErrNoK8s 没有K8s连接，无法执行
+	ErrNoK8s = errors.New("autofix requires a K8s connection")
+)
+
+// ActionResult 动作执行结果
+type ActionResult struct {
+	Status           string    `json:"status"`
+	Error            string    `json:"error,omitempty"`
+	PreviousReplicas *int32    `json:"previous_replicas,omitempty"`
+	ExecutedAt       time.Time `json:"executed_at"`
+}
+
+// Plan 根据一次分析结果生成的修复计划；生成时只预演（dry-run），确认后才执行
+type Plan struct {
+	ID           string     `json:"id"`
+	AnalysisID   string     `json:"analysis_id"`
+	AnalysisType string     `json:"analysis_type"`
+	Status       string     `json:"status"`
+	Actions      []*Action  `json:"actions"`
+	Skipped      []Skipped  `json:"skipped,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	ExecutedAt   *time.Time `json:"executed_at,omitempty"`
+	ExecutedBy   string     `json:"executed_by,omitempty"`
+}
+
+// Filter 计划查询条件
+type Filter struct {
+	Status     string
+	AnalysisID string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// Engine 生成并执行修复计划
+type Engine struct {
+	analyses *analysis.Store
+	k8s      *k8s.Client
+	store    storage.Store
+	audit    *audit.Logger
+	logger   *logrus.Entry
+
+	mu sync.Mutex // 同一时间只执行一个计划
+}
+
+// NewEngine 创建修复引擎；k8sClient 为空时只能生成计划（不预演），不能执行
+func NewEngine(analyses *analysis.Store, k8sClient *k8s.Client, store storage.Store, auditLog *audit.Logger) *Engine {
+	return &Engine{
+		analyses: analyses,
+		k8s:      k8sClient,
+		store:    store,
+		audit:    auditLog,
+		logger:   logging.For("autofix"),
+	}
+}
+
+// Propose 为分析结果生成修复计划，并逐个预演动作
+func (e *Engine) Propose(ctx context.Context, analysisID string) (*Plan, error) {
+	record, err := e.analyses.Get(ctx, analysisID)
+	if err != nil {
+		return nil, err
+	}
+
+	var p planner
+	switch record.Type {
+	case analysis.TypeRootCause:
+		var result analysis.RootCauseResult
+		if err := json.Unmarshal(record.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode analysis %s: %w", record.ID, err)
+		}
+		p.fromRootCause(&result)
+	case analysis.TypePodCommunication:
+		var result models.CommunicationAnalysis
+		if err := json.Unmarshal(record.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode analysis %s: %w", record.ID, err)
+		}
+		p.fromCommunication(&result)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, record.Type)
+	}
+
+	now := time.Now().UTC()
+	plan := &Plan{
+		ID:           newID(now),
+		AnalysisID:   record.ID,
+		AnalysisType: record.Type,
+		Status:       StatusProposed,
+		Actions:      make([]*Action, 0, len(p.actions)),
+		Skipped:      p.skipped,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(planTTL),
+	}
+	for _, action := range p.actions {
+		if action.Namespace != "" && e.k8s != nil && !e.k8s.WatchesNamespace(action.Namespace) {
+			plan.Skipped = append(plan.Skipped, Skipped{Command: action.Command, Reason: "namespace " + action.Namespace + " is not monitored"})
+			continue
+		}
+		e.dryRun(ctx, action)
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	if err := e.save(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// dryRun 由API Server预演动作
+func (e *Engine) dryRun(ctx context.Context, action *Action) {
+	if e.k8s == nil {
+		action.DryRun = DryRunSkipped
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	current, err := e.apply(ctx, action, true)
+	if action.Type == ActionScaleDeployment && (err == nil || current != 0) {
+		action.CurrentReplicas = &current
+	}
+	if err != nil {
+		action.DryRun = DryRunFailed
+		action.DryRunError = err.Error()
+		return
+	}
+	action.DryRun = DryRunPassed
+}
+
+// apply 执行（或预演）一个动作；scale_deployment 返回修改前的副本数
+func (e *Engine) apply(ctx context.Context, action *Action, dryRun bool) (int32, error) {
+	switch action.Type {
+	case ActionRestartPod:
+		return 0, e.k8s.DeletePod(ctx, action.Namespace, action.Name, dryRun)
+	case ActionScaleDeployment:
+		return e.k8s.ScaleDeployment(ctx, action.Namespace, action.Name, *action.Replicas, dryRun)
+	case ActionCordonNode:
+		return 0, e.k8s.CordonNode(ctx, action.Name, dryRun)
+	default:
+		return 0, fmt.Errorf("unknown action type %q", action.Type)
+	}
+}
+
+// Execute 按顺序执行计划中预演通过的动作，某个动作失败后不再执行后续动作。
+// 每个动作记录到审计日志，操作者取自 ctx（audit.WithRequest）
+func (e *Engine) Execute(ctx context.Context, id string) (*Plan, error) {
+	if e.k8s == nil {
+		return nil, ErrNoK8s
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	plan, err := e.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != StatusProposed {
+		return nil, fmt.Errorf("%w: status is %s", ErrNotPending, plan.Status)
+	}
+	executable := 0
+	for _, action := range plan.Actions {
+		if action.DryRun != DryRunFailed {
+			executable++
+		}
+	}
+	if executable == 0 {
+		return nil, ErrNoActions
+	}
+
+	actor, remoteAddr := audit.FromContext(ctx)
+	plan.Status = StatusExecuted
+	failed := false
+	for _, action := range plan.Actions {
+		result := &ActionResult{ExecutedAt: time.Now().UTC()}
+		action.Result = result
+		switch {
+		case failed:
+			result.Status = ResultSkipped
+			result.Error = "a previous action failed"
+			continue
+		case action.DryRun == DryRunFailed:
+			result.Status = ResultSkipped
+			result.Error = "dry run failed: " + action.DryRunError
+			continue
+		}
+
+		actionCtx, cancel := context.WithTimeout(ctx, actionTimeout)
+		previous, err := e.apply(actionCtx, action, false)
+		cancel()
+
+		details := map[string]interface{}{
+			"plan":     plan.ID,
+			"analysis": plan.AnalysisID,
+			"action":   action.Type,
+			"command":  action.Command,
+		}
+		if action.Type == ActionScaleDeployment {
+			result.PreviousReplicas = &previous
+			details["replicas"], details["previous_replicas"] = *action.Replicas, previous
+		}
+		e.audit.Log(ctx, actor, remoteAddr, audit.ActionRemediation, action.resource(), details, err)
+
+		if err != nil {
+			result.Status = ResultFailed
+			result.Error = err.Error()
+			plan.Status = StatusFailed
+			failed = true
+			e.logger.Warnf("Autofix action %q of plan %s failed: %v", action.Command, plan.ID, err)
+			continue
+		}
+		result.Status = ResultSucceeded
+		e.logger.Infof("Autofix action %q of plan %s executed by %s", action.Command, plan.ID, actor)
+	}
+
+	executedAt := time.Now().UTC()
+	plan.ExecutedAt = &executedAt
+	plan.ExecutedBy = actor
+	if err := e.save(ctx, plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// Get 根据ID获取计划
+func (e *Engine) Get(ctx context.Context, id string) (*Plan, error) {
+	data, err := e.store.Get(ctx, Collection, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to decode autofix plan %s: %w", id, err)
+	}
+	plan.expire(time.Now())
+	return &plan, nil
+}
+
+// List 查询计划（按生成时间降序）
+func (e *Engine) List(ctx context.Context, filter Filter) ([]*Plan, error) {
+	records, err := e.store.List(ctx, Collection, storage.Query{Key: filter.AnalysisID, From: filter.From, To: filter.To})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list autofix plans: %w", err)
+	}
+
+	// 计划执行后会追加新记录，以最后一条为准
+	latest := make(map[string]*Plan)
+	for _, record := range records {
+		var plan Plan
+		if err := json.Unmarshal(record.Data, &plan); err != nil {
+			continue
+		}
+		latest[plan.ID] = &plan
+	}
+
+	now := time.Now()
+	result := make([]*Plan, 0, len(latest))
+	for _, plan := range latest {
+		plan.expire(now)
+		if filter.Status != "" && plan.Status != filter.Status {
+			continue
+		}
+		result = append(result, plan)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// expire 未确认且超过有效期的计划标记为过期
+func (p *Plan) expire(now time.Time) {
+	if p.Status == StatusProposed && now.After(p.ExpiresAt) {
+		p.Status = StatusExpired
+	}
+}
+
+// save 保存计划；再次保存时追加新记录，查询时以最后一条为准
+func (e *Engine) save(ctx context.Context, plan *Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal autofix plan: %w", err)
+	}
+	if err := e.store.Put(ctx, Collection, plan.ID, data); err != nil {
+		return fmt.Errorf("failed to store autofix plan %s: %w", plan.ID, err)
+	}
+	return e.store.Append(ctx, Collection, &storage.Record{
+		ID:        plan.ID,
+		Key:       plan.AnalysisID,
+		Timestamp: plan.CreatedAt,
+		Data:      data,
+	})
+}
+
+func newID(at time.Time) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("fix-%d", at.UnixNano())
+	}
+	return fmt.Sprintf("fix-%s-%s", at.Format("20060102150405"), hex.EncodeToString(buf))
+}
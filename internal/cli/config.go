@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// ConfigOptions 配置来源参数（配置文件或ConfigMap）
+type ConfigOptions struct {
+	Path      string
+	ConfigMap config.ConfigMapSource
+}
+
+// AddFlags 注册配置来源参数
+func (o *ConfigOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Path, "config", "./configs/config.yaml", "config file path")
+	flags.StringVar(&o.ConfigMap.Name, "configmap", os.Getenv("CONFIG_CONFIGMAP"), "load config from this ConfigMap instead of a file (in-cluster, env CONFIG_CONFIGMAP)")
+	flags.StringVar(&o.ConfigMap.Namespace, "configmap-namespace", "", "namespace of the config ConfigMap (defaults to the pod namespace)")
+	flags.StringVar(&o.ConfigMap.Key, "configmap-key", "config.yaml", "data key holding the config in the ConfigMap")
+}
+
+// UseConfigMap 是否从ConfigMap加载配置
+func (o *ConfigOptions) UseConfigMap() bool {
+	return o.ConfigMap.Name != ""
+}
+
+// Load 按参数加载配置（命令行覆盖参数优先级最高）
+func (o *ConfigOptions) Load(ctx context.Context) (*config.Config, error) {
+	if o.UseConfigMap() {
+		return config.LoadFromConfigMap(ctx, &o.ConfigMap)
+	}
+	return config.Load(o.Path)
+}
+
+// Source 配置来源描述
+func (o *ConfigOptions) Source() string {
+	if o.UseConfigMap() {
+		return fmt.Sprintf("configmap/%s/%s", o.ConfigMap.Namespace, o.ConfigMap.Name)
+	}
+	return o.Path
+}
+
+// BindConfigFlags 为每个配置项注册同名覆盖参数（如 --server.port、--metrics.collect_interval），
+// 并绑定到viper，优先级高于配置文件和环境变量
+func BindConfigFlags(flags *pflag.FlagSet) error {
+	defaults := viper.New()
+	config.SetDefaults(defaults)
+
+	var bindErr error
+	walkConfig("", reflect.TypeOf(config.Config{}), func(key string, kind reflect.Type) {
+		if flags.Lookup(key) != nil {
+			return
+		}
+
+		usage := "override config " + key
+		def := defaults.Get(key)
+		switch kind.Kind() {
+		case reflect.String:
+			flags.String(key, cast.ToString(def), usage)
+		case reflect.Int:
+			flags.Int(key, cast.ToInt(def), usage)
+		case reflect.Bool:
+			flags.Bool(key, cast.ToBool(def), usage)
+		case reflect.Float64:
+			flags.Float64(key, cast.ToFloat64(def), usage)
+		case reflect.Slice:
+			if kind.Elem().Kind() != reflect.String {
+				return
+			}
+			flags.StringSlice(key, cast.ToStringSlice(def), usage)
+		default:
+			return
+		}
+
+		if err := viper.BindPFlag(key, flags.Lookup(key)); err != nil && bindErr == nil {
+			bindErr = fmt.Errorf("failed to bind flag %s: %w", key, err)
+		}
+	})
+	return bindErr
+}
+
+// walkConfig 遍历配置结构的叶子字段（跳过map和结构体切片等无法用单个参数表达的字段）
+func walkConfig(prefix string, t reflect.Type, fn func(key string, kind reflect.Type)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			walkConfig(key, field.Type, fn)
+			continue
+		}
+		fn(key, field.Type)
+	}
+}
+
+// BindEnvFlags 将参数绑定到viper并允许通过环境变量提供（参数 > 环境变量 > 参数默认值），
+// 调用方通过 viper.Get* 读取参数值
+func BindEnvFlags(flags *pflag.FlagSet, envs map[string]string) error {
+	for name, env := range envs {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown flag: %s", name)
+		}
+		if err := viper.BindPFlag(name, flag); err != nil {
+			return fmt.Errorf("failed to bind flag %s: %w", name, err)
+		}
+		if err := viper.BindEnv(name, env); err != nil {
+			return fmt.Errorf("failed to bind env %s: %w", env, err)
+		}
+		flag.Usage = fmt.Sprintf("%s (env %s)", flag.Usage, env)
+	}
+	return nil
+}
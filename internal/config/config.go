@@ -18,13 +18,24 @@ type Config struct {
 	Metrics    MetricsConfig    `mapstructure:"metrics"` // 新增指标采集配置
 	Analysis   AnalysisConfig   `mapstructure:"analysis"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
+	Audit      AuditConfig      `mapstructure:"audit"`
+	TLS        TLSConfig        `mapstructure:"tls"`
+	UAV        UAVConfig        `mapstructure:"uav"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
+	Federation FederationConfig `mapstructure:"federation"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host  string `mapstructure:"host"`
-	Port  int    `mapstructure:"port"`
-	Debug bool   `mapstructure:"debug"`
+	Host      string `mapstructure:"host"`
+	Port      int    `mapstructure:"port"`
+	Debug     bool   `mapstructure:"debug"`
+	DebugAddr string `mapstructure:"debug_addr"` // debug开启时pprof/expvar的监听地址
+
+	AdminToken   string `mapstructure:"admin_token"`    // 管理接口Token，为空时管理接口关闭（返回403）
+	MaxBodyBytes int64  `mapstructure:"max_body_bytes"` // JSON请求体大小上限（字节）
+
+	AllowedOrigins []string `mapstructure:"allowed_origins"` // 允许跨域连接WebSocket的Origin，同源请求始终允许
 }
 
 // K8sConfig K8s配置
@@ -32,9 +43,29 @@ type K8sConfig struct {
 	Kubeconfig      string `mapstructure:"kubeconfig"`
 	Namespace       string `mapstructure:"namespace"`
 	WatchNamespaces string `mapstructure:"watch_namespaces"`
+	ClusterDomain   string `mapstructure:"cluster_domain"` // 集群DNS域名，通信分析中解析Service的FQDN
+	DNSTestName     string `mapstructure:"dns_test_name"`  // 通信分析中额外解析的外部域名，为空时不测试
+
+	Exec ExecConfig `mapstructure:"exec"`
+}
+
+// ExecConfig 网络测试在Pod内执行命令的安全策略
+type ExecConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`          // 关闭后不再exec进入Pod执行任何测试
+	AllowedCommands []string `mapstructure:"allowed_commands"` // 允许执行的命令
+
+	DebugContainer DebugContainerConfig `mapstructure:"debug_container"`
+}
+
+// DebugContainerConfig 应用容器中没有测试命令（如distroless镜像）时，
+// 向源Pod添加临时调试容器（kubectl debug 方式）执行测试
+type DebugContainerConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Image   string `mapstructure:"image"` // 调试容器镜像，需要包含允许执行的命令
+	TTL     int    `mapstructure:"ttl"`   // 调试容器保留时间（秒），之内的测试复用同一个容器
 }
 
-// LLMConfig LLM配置
+// LLMConfig LLM配置（顶层字段为默认配置，profiles中的命名配置未设置的字段继承默认值）
 type LLMConfig struct {
 	Provider    string  `mapstructure:"provider"`
 	APIKey      string  `mapstructure:"api_key"`
@@ -43,20 +74,143 @@ type LLMConfig struct {
 	MaxTokens   int     `mapstructure:"max_tokens"`
 	Temperature float64 `mapstructure:"temperature"`
 	Timeout     int     `mapstructure:"timeout"`
+
+	Profiles       map[string]LLMProfile `mapstructure:"profiles"`        // 命名LLM配置
+	DefaultProfile string                `mapstructure:"default_profile"` // 未指定时使用的配置，为空表示顶层配置
+	Routing        map[string]string     `mapstructure:"routing"`         // 分析类型 -> 配置名
+	Fallbacks      []string              `mapstructure:"fallbacks"`       // 主配置限流或故障时依次尝试的配置名
+	Breaker        LLMBreakerConfig      `mapstructure:"breaker"`
+	Cache          LLMCacheConfig        `mapstructure:"cache"`
+	Redaction      LLMRedactionConfig    `mapstructure:"redaction"`
+
+	DailyTokenBudget int                 `mapstructure:"daily_token_budget"` // 所有配置合计的每日token预算，0表示不限制
+	BudgetAction     string              `mapstructure:"budget_action"`      // 超出预算时: reject（返回429）或 degrade（有规则分析的接口退回规则结果）
+	Pricing          map[string]LLMPrice `mapstructure:"pricing"`            // 模型名 -> 单价，用于估算费用
+}
+
+// LLMPrice 模型单价（美元/百万token）
+type LLMPrice struct {
+	Prompt     float64 `mapstructure:"prompt" json:"prompt"`
+	Completion float64 `mapstructure:"completion" json:"completion"`
+}
+
+// LLMBreakerConfig LLM提供商熔断器配置（每个配置独立计数）
+type LLMBreakerConfig struct {
+	FailureThreshold int `mapstructure:"failure_threshold"` // 连续失败次数阈值
+	Cooldown         int `mapstructure:"cooldown"`          // 熔断后探测间隔（秒）
+}
+
+// LLMCacheConfig LLM回答缓存：相同提示词且集群状态（指标快照）未变化时复用回答
+type LLMCacheConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	TTL        int  `mapstructure:"ttl"`         // 缓存有效期（秒）
+	MaxEntries int  `mapstructure:"max_entries"` // 最多缓存的回答数，超出时淘汰最早过期的
+}
+
+// LLMRedactionConfig 发送给外部LLM提供商前的脱敏（本地配置不脱敏）
+type LLMRedactionConfig struct {
+	Enabled            bool     `mapstructure:"enabled"`
+	Patterns           []string `mapstructure:"patterns"`            // 额外脱敏的正则，整个匹配替换为 [REDACTED]
+	AnnotationPatterns []string `mapstructure:"annotation_patterns"` // 注解等字段的键匹配这些正则时脱敏其值
+	LocalProfiles      []string `mapstructure:"local_profiles"`      // 视为本地模型的配置名（ollama 配置始终视为本地）
+	Strict             bool     `mapstructure:"strict"`              // 禁止调用外部提供商，只使用本地配置
+}
+
+// LLMProfile 命名LLM配置
+type LLMProfile struct {
+	Name             string   `mapstructure:"-" json:"name"`
+	Provider         string   `mapstructure:"provider" json:"provider"`
+	APIKey           string   `mapstructure:"api_key" json:"api_key,omitempty"`
+	BaseURL          string   `mapstructure:"base_url" json:"base_url,omitempty"`
+	Model            string   `mapstructure:"model" json:"model"`
+	MaxTokens        int      `mapstructure:"max_tokens" json:"max_tokens"`
+	Temperature      *float64 `mapstructure:"temperature" json:"temperature"` // 为空时继承默认值（0是合法温度）
+	Timeout          int      `mapstructure:"timeout" json:"timeout"`
+	DailyTokenBudget int      `mapstructure:"daily_token_budget" json:"daily_token_budget"` // 每日token预算，0表示不限制
+	Fallbacks        []string `mapstructure:"fallbacks" json:"fallbacks,omitempty"`         // 为空时继承顶层 fallbacks
 }
 
 // StorageConfig 存储配置
 type StorageConfig struct {
-	Type     string         `mapstructure:"type"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Postgres PostgresConfig `mapstructure:"postgres"`
+	Type             string           `mapstructure:"type"`              // memory, file, redis
+	Path             string           `mapstructure:"path"`              // file存储的数据目录
+	SnapshotInterval int              `mapstructure:"snapshot_interval"` // 快照持久化间隔（秒）
+	UAVHistoryStep   int              `mapstructure:"uav_history_step"`  // UAV遥测降采样间隔（秒，0表示保存每次上报）
+	Redis            RedisConfig      `mapstructure:"redis"`
+	Postgres         PostgresConfig   `mapstructure:"postgres"`
+	Archive          ArchiveConfig    `mapstructure:"archive"`
+	Buffer           BufferConfig     `mapstructure:"buffer"`
+	Retention        RetentionConfig  `mapstructure:"retention"`
+	Breaker          BreakerConfig    `mapstructure:"breaker"`
+	Encryption       EncryptionConfig `mapstructure:"encryption"`
+}
+
+// EncryptionConfig 存储静态加密配置（UAV轨迹、分析结果等敏感数据）
+type EncryptionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Key          string   `mapstructure:"key"`           // 32字节密钥（base64或hex），支持 secret:// 与 file:// 引用
+	PreviousKeys []string `mapstructure:"previous_keys"` // 轮换前的旧密钥，只用于解密
+	Collections  []string `mapstructure:"collections"`   // 需要加密的记录集合
+	Buckets      []string `mapstructure:"buckets"`       // 需要加密的键值bucket
+	Strict       bool     `mapstructure:"strict"`        // 拒绝读取未加密的数据（历史明文迁移完成后开启）
+}
+
+// BreakerConfig 存储熔断器配置
+type BreakerConfig struct {
+	Enabled          bool `mapstructure:"enabled"`
+	FailureThreshold int  `mapstructure:"failure_threshold"` // 连续失败次数阈值
+	Cooldown         int  `mapstructure:"cooldown"`          // 熔断后探测间隔（秒）
+	SlowThreshold    int  `mapstructure:"slow_threshold"`    // 慢操作阈值（毫秒）
+	OpTimeout        int  `mapstructure:"op_timeout"`        // 单次操作超时（秒）
 }
 
-// RedisConfig Redis配置
+// RetentionConfig 数据保留与压缩任务配置
+type RetentionConfig struct {
+	Enabled  bool                    `mapstructure:"enabled"`
+	Interval int                     `mapstructure:"interval"` // 任务执行间隔（秒）
+	Policies []RetentionPolicyConfig `mapstructure:"policies"`
+}
+
+// RetentionPolicyConfig 单个集合的保留策略
+type RetentionPolicyConfig struct {
+	Collection      string `mapstructure:"collection"`
+	MaxAge          int    `mapstructure:"max_age"`          // 保留时长（小时，0表示不删除）
+	DownsampleAfter int    `mapstructure:"downsample_after"` // 超过该时长开始降采样（小时，0表示不降采样）
+	DownsampleStep  int    `mapstructure:"downsample_step"`  // 降采样窗口（秒）
+}
+
+// BufferConfig 存储写缓冲配置（后端短暂不可用时暂存写入）
+type BufferConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	MaxEntries    int    `mapstructure:"max_entries"`    // 缓冲最大条目数
+	Path          string `mapstructure:"path"`           // 落盘文件路径，为空时仅缓存在内存
+	DrainInterval int    `mapstructure:"drain_interval"` // 恢复检测间隔（秒）
+}
+
+// ArchiveConfig 归档导出配置（S3兼容对象存储）
+type ArchiveConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Endpoint     string   `mapstructure:"endpoint"` // 例如 https://s3.amazonaws.com 或 http://minio:9000
+	Region       string   `mapstructure:"region"`
+	Bucket       string   `mapstructure:"bucket"`
+	Prefix       string   `mapstructure:"prefix"`
+	AccessKey    string   `mapstructure:"access_key"`
+	SecretKey    string   `mapstructure:"secret_key"`
+	PathStyle    bool     `mapstructure:"path_style"`    // MinIO需要开启
+	Interval     int      `mapstructure:"interval"`      // 导出检查间隔（秒）
+	LookbackDays int      `mapstructure:"lookback_days"` // 首次运行回溯导出的天数
+	Collections  []string `mapstructure:"collections"`   // 需要归档的集合
+}
+
+// RedisConfig Redis配置（storage.type 为 redis 时使用）
 type RedisConfig struct {
-	Addr     string `mapstructure:"addr"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	Addr         string `mapstructure:"addr"`
+	Password     string `mapstructure:"password"`
+	DB           int    `mapstructure:"db"`
+	Prefix       string `mapstructure:"prefix"`        // 键前缀
+	Timeout      int    `mapstructure:"timeout"`       // 连接和命令超时（秒）
+	ShareState   bool   `mapstructure:"share_state"`   // 多副本共享最新指标快照和UAV状态
+	SyncInterval int    `mapstructure:"sync_interval"` // 读取其他副本状态的间隔（秒）
 }
 
 // PostgresConfig PostgreSQL配置
@@ -70,21 +224,248 @@ type PostgresConfig struct {
 
 // MonitoringConfig 监控配置
 type MonitoringConfig struct {
-	MetricsInterval int `mapstructure:"metrics_interval"`
-	EventRetention  int `mapstructure:"event_retention"`
-	LogRetention    int `mapstructure:"log_retention"`
+	MetricsInterval int               `mapstructure:"metrics_interval"`
+	EventRetention  int               `mapstructure:"event_retention"`
+	LogRetention    int               `mapstructure:"log_retention"`
+	EventFilter     EventFilterConfig `mapstructure:"event_filter"`
+}
+
+// EventFilterConfig K8s事件分类：记录前将事件标记为 noise、informational 或 actionable
+type EventFilterConfig struct {
+	Enabled       bool              `mapstructure:"enabled"`
+	LLM           bool              `mapstructure:"llm"`            // 规则无法判断的事件交给LLM分类
+	Profile       string            `mapstructure:"profile"`        // 为空时按 llm.routing.event_classification 选择
+	BatchSize     int               `mapstructure:"batch_size"`     // 每次LLM调用最多分类的事件模式数
+	FlushInterval int               `mapstructure:"flush_interval"` // 待分类事件最长等待时间（秒）
+	DropNoise     bool              `mapstructure:"drop_noise"`     // 不记录也不转发 noise 事件
+	Overrides     map[string]string `mapstructure:"overrides"`      // 事件原因 -> 分类，优先于规则和LLM
 }
 
 // MetricsConfig 指标采集配置
 type MetricsConfig struct {
-	Enabled         bool     `mapstructure:"enabled"`           // 是否启用指标采集
-	CollectInterval int      `mapstructure:"collect_interval"`  // 采集间隔（秒）
-	Namespaces      []string `mapstructure:"namespaces"`        // 要监控的命名空间列表
-	EnableNode      bool     `mapstructure:"enable_node"`       // 启用节点指标
-	EnablePod       bool     `mapstructure:"enable_pod"`        // 启用Pod指标
-	EnableNetwork   bool     `mapstructure:"enable_network"`    // 启用网络指标
-	EnableCustom    bool     `mapstructure:"enable_custom"`     // 启用自定义CRD指标
-	CacheRetention  int      `mapstructure:"cache_retention"`   // 缓存保留时间（秒）
+	Enabled         bool     `mapstructure:"enabled"`          // 是否启用指标采集
+	CollectInterval int      `mapstructure:"collect_interval"` // 采集间隔（秒）
+	Namespaces      []string `mapstructure:"namespaces"`       // 要监控的命名空间列表
+	EnableNode      bool     `mapstructure:"enable_node"`      // 启用节点指标
+	EnablePod       bool     `mapstructure:"enable_pod"`       // 启用Pod指标
+	EnableNetwork   bool     `mapstructure:"enable_network"`   // 启用网络指标
+	EnableCustom    bool     `mapstructure:"enable_custom"`    // 启用自定义CRD指标
+	EnableSummary   bool     `mapstructure:"enable_summary"`   // 从kubelet Summary API读取实际磁盘用量、网卡流量和Pod临时存储
+	CacheRetention  int      `mapstructure:"cache_retention"`  // 内存历史保留时间（秒），为0时不保留
+
+	Intervals       map[string]int         `mapstructure:"intervals"`        // 各采集器单独的采集间隔（秒），键为 node/pod/network/uav/custom/gpu/summary/node_latency/external
+	Jitter          float64                `mapstructure:"jitter"`           // 每次调度额外等待的随机比例（0-1），避免各采集器同时触发
+	PodFilter       PodFilterConfig        `mapstructure:"pod_filter"`       // 在命名空间内进一步限定采集的Pod
+	CustomResources []CustomResourceConfig `mapstructure:"custom_resources"` // enable_custom 时读取的自定义资源
+	GPU             GPUMetricsConfig       `mapstructure:"gpu"`
+	Anomalies       AnomaliesConfig        `mapstructure:"anomalies"`
+	RemoteWrite     RemoteWriteConfig      `mapstructure:"remote_write"`
+	Rollup          RollupConfig           `mapstructure:"rollup"`
+	SLOs            []SLOConfig            `mapstructure:"slos"` // 服务等级目标，按滚动窗口计算达标率和错误预算
+	UAVStaleness    UAVStalenessConfig     `mapstructure:"uav_staleness"`
+	Bandwidth       BandwidthConfig        `mapstructure:"bandwidth"`
+	ProbeAgents     ProbeAgentsConfig      `mapstructure:"probe_agents"`
+	NodeLatency     NodeLatencyConfig      `mapstructure:"node_latency"`
+	Network         NetworkTestsConfig     `mapstructure:"network"` // enable_network 时自动选择Pod对测试的方式
+
+	ConnectivityProbes ConnectivityProbesConfig `mapstructure:"connectivity_probes"`
+	ExternalTargets    ExternalTargetsConfig    `mapstructure:"external_targets"`
+}
+
+// ProbeAgentsConfig 由每个节点上的探测Agent（DaemonSet）进行网络测试，不再exec进入应用Pod；
+// 源Pod所在节点没有就绪的Agent时回退到exec测试
+type ProbeAgentsConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Manage    bool   `mapstructure:"manage"`    // 启动时由服务端创建或更新Agent DaemonSet
+	Namespace string `mapstructure:"namespace"` // Agent所在命名空间，默认为服务端所在命名空间
+	Image     string `mapstructure:"image"`     // manage 时DaemonSet使用的镜像
+	Port      int    `mapstructure:"port"`
+	Token     string `mapstructure:"token"`    // 访问Agent的Bearer Token（支持 secret:// 和 file:// 引用），为空时不鉴权
+	DNSName   string `mapstructure:"dns_name"` // Agent测量DNS解析耗时使用的域名，为空时不测量
+}
+
+// Validate 检查Agent端口与镜像
+func (c *ProbeAgentsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("metrics.probe_agents.port must be between 1 and 65535, got %d", c.Port)
+	}
+	if c.Manage && c.Image == "" {
+		return fmt.Errorf("metrics.probe_agents.image is required when manage is enabled")
+	}
+	return nil
+}
+
+// NodeLatencyConfig 由各节点的探测Agent互相ping，定期测量节点间时延矩阵（需要启用 probe_agents）
+type NodeLatencyConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	Interval    int  `mapstructure:"interval"`    // 测量间隔（秒），metrics.intervals.node_latency 优先
+	Count       int  `mapstructure:"count"`       // 每对节点的ping次数
+	Concurrency int  `mapstructure:"concurrency"` // 同时测量的节点对数
+}
+
+// Validate 检查是否启用了探测Agent
+func (c *NodeLatencyConfig) Validate(agents ProbeAgentsConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if !agents.Enabled {
+		return fmt.Errorf("metrics.node_latency requires metrics.probe_agents.enabled")
+	}
+	if c.Interval < 1 {
+		return fmt.Errorf("metrics.node_latency.interval must be at least 1, got %d", c.Interval)
+	}
+	return nil
+}
+
+// NetworkTestsConfig 自动网络测试的Pod对选择
+type NetworkTestsConfig struct {
+	MaxPairs int    `mapstructure:"max_pairs"` // 每轮最多测试的Pod对数
+	Strategy string `mapstructure:"strategy"`  // node_pair、service_pair、namespace_pair、random、first
+	Seed     int64  `mapstructure:"seed"`      // 代表Pod和随机抽样的随机种子，为0时每次启动不同
+
+	Concurrency int `mapstructure:"concurrency"`  // 同时测试的Pod对数
+	CycleBudget int `mapstructure:"cycle_budget"` // 每轮测试的时间预算（秒），超出时取消剩余的测试，为0时不限制
+
+	QualityWindow        int     `mapstructure:"quality_window"`        // 计算抖动和当前链路质量的最近测试次数
+	DegradationThreshold float64 `mapstructure:"degradation_threshold"` // 链路质量评分较24小时基线下降的百分比阈值
+
+	ExportCRD       bool   `mapstructure:"export_crd"`       // 将每个Pod对的结果写入NetworkProbeResult自定义资源
+	ExportNamespace string `mapstructure:"export_namespace"` // NetworkProbeResult所在命名空间，为空时使用 k8s.namespace
+	ExportTTL       int    `mapstructure:"export_ttl"`       // 超过该时长（秒）没有更新的NetworkProbeResult被删除
+}
+
+// Validate 检查Pod对数、并发与预算、策略、链路质量和导出参数
+func (c *NetworkTestsConfig) Validate() error {
+	if c.MaxPairs < 1 {
+		return fmt.Errorf("metrics.network.max_pairs must be at least 1, got %d", c.MaxPairs)
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("metrics.network.concurrency must be at least 1, got %d", c.Concurrency)
+	}
+	if c.CycleBudget < 0 {
+		return fmt.Errorf("metrics.network.cycle_budget must not be negative, got %d", c.CycleBudget)
+	}
+	if c.QualityWindow < 2 {
+		return fmt.Errorf("metrics.network.quality_window must be at least 2, got %d", c.QualityWindow)
+	}
+	if c.DegradationThreshold <= 0 || c.DegradationThreshold > 100 {
+		return fmt.Errorf("metrics.network.degradation_threshold must be between 0 and 100, got %v", c.DegradationThreshold)
+	}
+	if c.ExportCRD && c.ExportTTL < 60 {
+		return fmt.Errorf("metrics.network.export_ttl must be at least 60, got %d", c.ExportTTL)
+	}
+	switch c.Strategy {
+	case "node_pair", "service_pair", "namespace_pair", "random", "first":
+		return nil
+	default:
+		return fmt.Errorf("metrics.network.strategy must be node_pair, service_pair, namespace_pair, random or first, got %q", c.Strategy)
+	}
+}
+
+// BandwidthConfig 网络测试中对连通的Pod对额外运行iperf3测量吞吐量，
+// 需要在 k8s.exec.allowed_commands 中加入 iperf3
+type BandwidthConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Server   string `mapstructure:"server"`   // target：在目标Pod内启动服务端；pod：在目标Pod所在节点上创建临时服务端Pod
+	Image    string `mapstructure:"image"`    // server 为 pod 时服务端Pod的镜像
+	Port     int    `mapstructure:"port"`     // 服务端端口
+	Duration int    `mapstructure:"duration"` // 每次测试的发送时长（秒）
+}
+
+// Validate 检查服务端运行方式
+func (c *BandwidthConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Server {
+	case "target", "pod":
+	default:
+		return fmt.Errorf("metrics.bandwidth.server must be target or pod, got %q", c.Server)
+	}
+	if c.Duration < 1 {
+		return fmt.Errorf("metrics.bandwidth.duration must be at least 1, got %d", c.Duration)
+	}
+	return nil
+}
+
+// UAVStalenessConfig UAV心跳超时检测：错过 stale_after 个心跳间隔后标记为stale，错过 lost_after 个后标记为lost
+type UAVStalenessConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	StaleAfter    int  `mapstructure:"stale_after"`    // 错过多少个心跳间隔后标记为stale
+	LostAfter     int  `mapstructure:"lost_after"`     // 错过多少个心跳间隔后标记为lost，需大于 stale_after
+	CheckInterval int  `mapstructure:"check_interval"` // 检查间隔（秒）
+}
+
+// Validate 检查超时阈值
+func (c *UAVStalenessConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.StaleAfter < 1 {
+		return fmt.Errorf("metrics.uav_staleness.stale_after must be at least 1, got %d", c.StaleAfter)
+	}
+	if c.LostAfter <= c.StaleAfter {
+		return fmt.Errorf("metrics.uav_staleness.lost_after (%d) must be greater than stale_after (%d)", c.LostAfter, c.StaleAfter)
+	}
+	return nil
+}
+
+// RollupConfig 指标样本降采样：将原始样本聚合为1分钟和10分钟窗口，
+// 各层的保留时长由 storage.retention 中对应集合的策略控制
+type RollupConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	Interval int  `mapstructure:"interval"` // 任务执行间隔（秒）
+}
+
+// RemoteWriteConfig 将采集到的指标推送到Prometheus remote_write接口（Prometheus、Mimir、VictoriaMetrics），
+// 用于无法被抓取的边缘/UAV集群
+type RemoteWriteConfig struct {
+	Enabled        bool              `mapstructure:"enabled"`
+	URL            string            `mapstructure:"url"`
+	Headers        map[string]string `mapstructure:"headers"` // 额外的请求头，例如 X-Scope-OrgID
+	BearerToken    string            `mapstructure:"bearer_token"`
+	Username       string            `mapstructure:"username"`
+	Password       string            `mapstructure:"password"`
+	ExternalLabels map[string]string `mapstructure:"external_labels"` // 附加到每个序列的标签，例如 cluster: edge-1
+	BatchSize      int               `mapstructure:"batch_size"`      // 每个请求最多包含的样本数
+	QueueSize      int               `mapstructure:"queue_size"`      // 等待发送的样本上限，超出时丢弃最旧的样本
+	FlushInterval  int               `mapstructure:"flush_interval"`  // 发送间隔（秒）
+	Timeout        int               `mapstructure:"timeout"`         // 单个请求超时（秒）
+	MaxBackoff     int               `mapstructure:"max_backoff"`     // 失败重试的最长等待（秒）
+}
+
+// GPUMetricsConfig 从DCGM Exporter采集每个GPU的使用率和显存（GPU数量始终来自节点的 nvidia.com/gpu 容量）
+type GPUMetricsConfig struct {
+	Enabled   bool              `mapstructure:"enabled"`
+	Namespace string            `mapstructure:"namespace"` // DCGM Exporter所在的命名空间
+	Selector  string            `mapstructure:"selector"`  // DCGM Exporter Pod的label selector
+	Port      int               `mapstructure:"port"`
+	Endpoints map[string]string `mapstructure:"endpoints"` // 节点名 -> 指标地址，配置后不再发现Exporter Pod
+	Timeout   int               `mapstructure:"timeout"`   // 单次拉取超时（秒）
+}
+
+// CustomResourceConfig 提供节点指标的自定义资源，通过动态客户端读取
+type CustomResourceConfig struct {
+	Group       string            `mapstructure:"group"`
+	Version     string            `mapstructure:"version"`
+	Resource    string            `mapstructure:"resource"`     // 复数资源名，例如 gpumetrics
+	Namespace   string            `mapstructure:"namespace"`    // 为空时读取所有命名空间（或集群范围资源）
+	NodeField   string            `mapstructure:"node_field"`   // 节点名所在字段，默认 spec.nodeName
+	ValuesField string            `mapstructure:"values_field"` // 未配置 fields 时读取该字段下的所有标量值，默认 status
+	Fields      map[string]string `mapstructure:"fields"`       // 指标名 -> 字段路径
+}
+
+// AnomaliesConfig 指标异常检测：对CPU、内存、RTT和UAV电量序列计算EWMA和z分数
+type AnomaliesConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	ZThreshold   float64 `mapstructure:"z_threshold"`   // 偏离EWMA均值的标准差倍数达到该值视为异常
+	Alpha        float64 `mapstructure:"alpha"`         // EWMA平滑系数（0-1），越大越偏重最近的样本
+	MinSamples   int     `mapstructure:"min_samples"`   // 序列样本数达到该值后才开始检测
+	ResolveAfter int     `mapstructure:"resolve_after"` // 连续多少个正常样本后异常恢复
+	Triage       bool    `mapstructure:"triage"`        // 由LLM对新检测到的异常分级
 }
 
 // AnalysisConfig 分析配置
@@ -92,6 +473,56 @@ type AnalysisConfig struct {
 	EnablePrediction bool `mapstructure:"enable_prediction"`
 	EnableAutoFix    bool `mapstructure:"enable_auto_fix"`
 	MaxContextEvents int  `mapstructure:"max_context_events"`
+
+	Prompts   PromptsConfig   `mapstructure:"prompts"`
+	Incidents IncidentsConfig `mapstructure:"incidents"`
+	RAG       RAGConfig       `mapstructure:"rag"`
+	Reports   ReportsConfig   `mapstructure:"reports"`
+}
+
+// Validate 开启自动修复时要求配置管理Token：执行修复计划会修改集群资源，只能由持有Token的管理员确认
+func (c *AnalysisConfig) Validate(server ServerConfig) error {
+	if c.EnableAutoFix && server.AdminToken == "" {
+		return fmt.Errorf("analysis.enable_auto_fix requires server.admin_token to be set")
+	}
+	return nil
+}
+
+// PromptsConfig 提示词模板配置
+type PromptsConfig struct {
+	Dir      string         `mapstructure:"dir"`      // 模板目录（*.yaml），覆盖或新增内置模板
+	Versions map[string]int `mapstructure:"versions"` // 模板名 -> 固定使用的版本，未设置时使用最高版本
+}
+
+// IncidentsConfig 事件关联与故障分组配置
+type IncidentsConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	Interval       int      `mapstructure:"interval"`        // 关联间隔（秒）
+	Window         int      `mapstructure:"window"`          // 相关事件的最大间隔（秒），超过后故障视为已恢复
+	Reasons        []string `mapstructure:"reasons"`         // 参与关联的Warning事件原因
+	SpikeThreshold float64  `mapstructure:"spike_threshold"` // CPU/内存使用率（%）达到该值视为突增
+	Describe       bool     `mapstructure:"describe"`        // 由LLM为新故障命名和描述
+}
+
+// ReportsConfig 每日集群健康报告
+type ReportsConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Schedule string `mapstructure:"schedule"` // cron表达式（分 时 日 月 周，UTC），每次生成上一个完整UTC日的报告
+	Profile  string `mapstructure:"profile"`  // 生成摘要使用的LLM配置，为空时按 llm.routing.report 选择
+}
+
+// RAGConfig 历史检索：为指标快照、故障和UAV遥测生成摘要并向量化，分析时检索相似的历史情况
+type RAGConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	Interval     int     `mapstructure:"interval"`      // 生成摘要的间隔（秒）
+	Store        string  `mapstructure:"store"`         // 向量存储: memory（向量保存在 storage 中，启动时加载）或 pgvector
+	Embedder     string  `mapstructure:"embedder"`      // hash（本地特征哈希，无需模型）或 llm（调用LLM配置的embedding接口）
+	Profile      string  `mapstructure:"profile"`       // embedder 为 llm 时使用的LLM配置，为空时按 llm.routing.embedding 选择
+	Model        string  `mapstructure:"model"`         // embedding模型（如 text-embedding-3-small、nomic-embed-text）
+	Dimensions   int     `mapstructure:"dimensions"`    // hash 向量维数
+	TopK         int     `mapstructure:"top_k"`         // 分析时检索的历史条数
+	MinScore     float64 `mapstructure:"min_score"`     // 相似度（余弦）低于该值的历史不返回
+	MaxDocuments int     `mapstructure:"max_documents"` // 最多保留的摘要数，超出时淘汰最早的
 }
 
 // LoggingConfig 日志配置
@@ -101,20 +532,87 @@ type LoggingConfig struct {
 	Output string `mapstructure:"output"`
 }
 
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"` // 追加写入的JSONL文件，为空时只写入存储
+}
+
+// TLSConfig 组件间mTLS配置（证书可来自文件或cert-manager签发的Secret）
+type TLSConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	CertFile   string   `mapstructure:"cert_file"`
+	KeyFile    string   `mapstructure:"key_file"`
+	CAFile     string   `mapstructure:"ca_file"`
+	ClientAuth string   `mapstructure:"client_auth"` // verify_if_given（默认，仍允许浏览器访问）或 require
+	AgentSANs  []string `mapstructure:"agent_sans"`  // UAV Agent允许的证书SAN（上报和命令路径），支持通配符
+	// AgentNodeSANs 标识Agent所在节点的SAN模板（如 "{node}.uav-agent.default.svc"），
+	// 配置后上报中的 node_name 必须与证书中的节点身份一致；为空时Agent共用证书，不校验节点
+	AgentNodeSANs []string `mapstructure:"agent_node_sans"`
+}
+
+// UAVConfig UAV接入配置
+type UAVConfig struct {
+	ReportSigning ReportSigningConfig `mapstructure:"report_signing"`
+}
+
+// ReportSigningConfig UAV上报HMAC签名校验配置
+type ReportSigningConfig struct {
+	Enabled            bool              `mapstructure:"enabled"`
+	Required           bool              `mapstructure:"required"`             // 拒绝未签名的上报（关闭时只拒绝签名错误的上报）
+	MasterKey          string            `mapstructure:"master_key"`           // 节点密钥 = hex(HMAC-SHA256(master_key, 节点名))
+	PreviousMasterKeys []string          `mapstructure:"previous_master_keys"` // 轮换前的主密钥，轮换期间仍接受其派生密钥的签名
+	Keys               map[string]string `mapstructure:"keys"`                 // 显式指定的节点密钥，优先于派生密钥
+	MaxSkew            int               `mapstructure:"max_skew"`             // 允许的时间偏差（秒）
+}
+
+// TracingConfig OpenTelemetry链路追踪配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP/HTTP地址，如 otel-collector:4318；为空时使用 OTEL_EXPORTER_OTLP_* 环境变量
+	Insecure    bool    `mapstructure:"insecure"`     // 不使用TLS连接Collector
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例（0-1]
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// FederationConfig 多集群联邦配置
+type FederationConfig struct {
+	Enabled     bool                      `mapstructure:"enabled"`
+	ClusterName string                    `mapstructure:"cluster_name"` // 本集群在联邦中的名称
+	Timeout     int                       `mapstructure:"timeout"`      // 请求对端的超时（秒）
+	Peers       map[string]FederationPeer `mapstructure:"peers"`        // 静态配置的对端，key为集群名
+}
+
+// FederationPeer 对端监控实例
+type FederationPeer struct {
+	URL   string `mapstructure:"url"`   // 对端API地址，如 https://monitor.site-b.example.com
+	Token string `mapstructure:"token"` // 访问对端时携带的Bearer Token（支持 secret:// 和 file:// 引用）
+}
+
 // Load 加载配置文件
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 
+	return load(func() error {
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		return nil
+	})
+}
+
+// load 设置默认值和环境变量后通过read读取配置内容并解析
+func load(read func() error) (*Config, error) {
 	// 设置默认值
-	setDefaults()
+	SetDefaults(viper.GetViper())
 
 	// 读取环境变量
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	// 读取配置内容
+	if err := read(); err != nil {
+		return nil, err
 	}
 
 	// 解析环境变量
@@ -125,47 +623,255 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// 解析 secret:// 与 file:// 引用
+	if err := resolveSecrets(&config); err != nil {
+		return nil, err
+	}
+
+	if err := config.LLM.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Analysis.Validate(config.Server); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.PodFilter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateSLOs(config.Metrics.SLOs); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.UAVStaleness.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.Bandwidth.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.ProbeAgents.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.NodeLatency.Validate(config.Metrics.ProbeAgents); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.ConnectivityProbes.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.ExternalTargets.Validate(config.Metrics.ProbeAgents); err != nil {
+		return nil, err
+	}
+	if err := config.Metrics.Network.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Metrics.ProbeAgents.Namespace == "" {
+		config.Metrics.ProbeAgents.Namespace = podNamespace()
+	}
+
 	return &config, nil
 }
 
-// setDefaults 设置默认值
-func setDefaults() {
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.debug", false)
+// SetDefaults 设置默认值
+func SetDefaults(v *viper.Viper) {
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.debug", false)
+	v.SetDefault("server.debug_addr", "127.0.0.1:6060")
+	v.SetDefault("server.max_body_bytes", 1<<20)
+
+	v.SetDefault("k8s.kubeconfig", "")
+	v.SetDefault("k8s.namespace", "default")
+	v.SetDefault("k8s.watch_namespaces", "default")
+	v.SetDefault("k8s.exec.enabled", true)
+	v.SetDefault("k8s.cluster_domain", "cluster.local")
+	v.SetDefault("k8s.dns_test_name", "kubernetes.io")
+	v.SetDefault("k8s.exec.allowed_commands", []string{"ping", "curl", "nc", "nslookup"})
+	v.SetDefault("k8s.exec.debug_container.enabled", false)
+	v.SetDefault("k8s.exec.debug_container.image", "nicolaka/netshoot")
+	v.SetDefault("k8s.exec.debug_container.ttl", 3600)
+
+	v.SetDefault("llm.provider", "openai")
+	v.SetDefault("llm.model", "gpt-4")
+	v.SetDefault("llm.max_tokens", 2000)
+	v.SetDefault("llm.temperature", 0.1)
+	v.SetDefault("llm.timeout", 30)
+	v.SetDefault("llm.breaker.failure_threshold", 3)
+	v.SetDefault("llm.breaker.cooldown", 60)
+	v.SetDefault("llm.cache.enabled", true)
+	v.SetDefault("llm.cache.ttl", 300)
+	v.SetDefault("llm.cache.max_entries", 500)
+	v.SetDefault("llm.redaction.enabled", true)
+	v.SetDefault("llm.daily_token_budget", 0)
+	v.SetDefault("llm.budget_action", "reject")
+
+	v.SetDefault("storage.type", "memory")
+	v.SetDefault("storage.path", "./data")
+	v.SetDefault("storage.snapshot_interval", 60)
+	v.SetDefault("storage.uav_history_step", 5)
+	v.SetDefault("storage.redis.addr", "localhost:6379")
+	v.SetDefault("storage.redis.prefix", "k8s-llm-monitor")
+	v.SetDefault("storage.redis.timeout", 5)
+	v.SetDefault("storage.redis.share_state", true)
+	v.SetDefault("storage.redis.sync_interval", 5)
+	v.SetDefault("storage.buffer.enabled", true)
+	v.SetDefault("storage.buffer.max_entries", 10000)
+	v.SetDefault("storage.buffer.drain_interval", 5)
+	v.SetDefault("storage.breaker.enabled", true)
+	v.SetDefault("storage.breaker.failure_threshold", 5)
+	v.SetDefault("storage.breaker.cooldown", 30)
+	v.SetDefault("storage.breaker.slow_threshold", 2000)
+	v.SetDefault("storage.breaker.op_timeout", 5)
+	v.SetDefault("storage.encryption.enabled", false)
+	v.SetDefault("storage.encryption.key", "")
+	v.SetDefault("storage.encryption.previous_keys", []string{})
+	v.SetDefault("storage.encryption.collections", []string{"uav_telemetry", "analyses"})
+	v.SetDefault("storage.encryption.buckets", []string{"state", "federation"})
+	v.SetDefault("storage.encryption.strict", false)
+	v.SetDefault("storage.retention.enabled", true)
+	v.SetDefault("storage.retention.interval", 3600)
+	v.SetDefault("storage.retention.policies", []map[string]interface{}{
+		{"collection": "uav_telemetry", "max_age": 720, "downsample_after": 24, "downsample_step": 60},
+		{"collection": "metric_samples", "max_age": 1},
+		{"collection": "metric_samples_1m", "max_age": 24},
+		{"collection": "metric_samples_10m", "max_age": 336},
+		{"collection": "analyses", "max_age": 2160},
+		{"collection": "rag_documents", "max_age": 2160},
+		{"collection": "anomalies", "max_age": 720},
+		{"collection": "node_conditions", "max_age": 720},
+		{"collection": "autofix_plans", "max_age": 2160},
+		{"collection": "reports", "max_age": 8760},
+	})
+	v.SetDefault("storage.archive.enabled", false)
+	v.SetDefault("storage.archive.region", "us-east-1")
+	v.SetDefault("storage.archive.prefix", "k8s-llm-monitor")
+	v.SetDefault("storage.archive.interval", 3600)
+	v.SetDefault("storage.archive.lookback_days", 7)
+	v.SetDefault("storage.archive.collections", []string{"events", "analyses", "uav_telemetry"})
+
+	v.SetDefault("monitoring.metrics_interval", 30)
+	v.SetDefault("monitoring.event_retention", 168)
+	v.SetDefault("monitoring.log_retention", 24)
+	v.SetDefault("monitoring.event_filter.enabled", true)
+	v.SetDefault("monitoring.event_filter.llm", true)
+	v.SetDefault("monitoring.event_filter.profile", "")
+	v.SetDefault("monitoring.event_filter.batch_size", 20)
+	v.SetDefault("monitoring.event_filter.flush_interval", 10)
+	v.SetDefault("monitoring.event_filter.drop_noise", false)
 
-	viper.SetDefault("k8s.kubeconfig", "")
-	viper.SetDefault("k8s.namespace", "default")
-	viper.SetDefault("k8s.watch_namespaces", "default")
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.collect_interval", 30)
+	v.SetDefault("metrics.namespaces", []string{"default"})
+	v.SetDefault("metrics.enable_node", true)
+	v.SetDefault("metrics.enable_pod", true)
+	v.SetDefault("metrics.enable_network", false)
+	v.SetDefault("metrics.enable_custom", false)
+	v.SetDefault("metrics.enable_summary", true)
+	v.SetDefault("metrics.gpu.enabled", false)
+	v.SetDefault("metrics.gpu.namespace", "gpu-operator")
+	v.SetDefault("metrics.gpu.selector", "app=nvidia-dcgm-exporter")
+	v.SetDefault("metrics.gpu.port", 9400)
+	v.SetDefault("metrics.gpu.timeout", 5)
+	v.SetDefault("metrics.anomalies.enabled", true)
+	v.SetDefault("metrics.anomalies.z_threshold", 3.0)
+	v.SetDefault("metrics.anomalies.alpha", 0.1)
+	v.SetDefault("metrics.anomalies.min_samples", 10)
+	v.SetDefault("metrics.anomalies.resolve_after", 3)
+	v.SetDefault("metrics.anomalies.triage", false)
+	v.SetDefault("metrics.cache_retention", 300)
+	v.SetDefault("metrics.remote_write.enabled", false)
+	v.SetDefault("metrics.remote_write.batch_size", 2000)
+	v.SetDefault("metrics.remote_write.queue_size", 100000)
+	v.SetDefault("metrics.remote_write.flush_interval", 5)
+	v.SetDefault("metrics.remote_write.timeout", 10)
+	v.SetDefault("metrics.remote_write.max_backoff", 30)
+	v.SetDefault("metrics.rollup.enabled", true)
+	v.SetDefault("metrics.rollup.interval", 60)
+	v.SetDefault("metrics.uav_staleness.enabled", true)
+	v.SetDefault("metrics.uav_staleness.stale_after", 3)
+	v.SetDefault("metrics.uav_staleness.lost_after", 10)
+	v.SetDefault("metrics.uav_staleness.check_interval", 5)
+	v.SetDefault("metrics.bandwidth.enabled", false)
+	v.SetDefault("metrics.bandwidth.server", "target")
+	v.SetDefault("metrics.bandwidth.image", "networkstatic/iperf3")
+	v.SetDefault("metrics.bandwidth.port", 5201)
+	v.SetDefault("metrics.bandwidth.duration", 5)
+	v.SetDefault("metrics.probe_agents.enabled", false)
+	v.SetDefault("metrics.probe_agents.manage", false)
+	v.SetDefault("metrics.probe_agents.image", "k8s-llm-monitor-probe:dev")
+	v.SetDefault("metrics.probe_agents.port", 9091)
+	v.SetDefault("metrics.probe_agents.dns_name", "kubernetes.default.svc.cluster.local")
+	v.SetDefault("metrics.node_latency.enabled", false)
+	v.SetDefault("metrics.node_latency.interval", 300)
+	v.SetDefault("metrics.node_latency.count", 3)
+	v.SetDefault("metrics.node_latency.concurrency", 5)
+	v.SetDefault("metrics.network.max_pairs", 5)
+	v.SetDefault("metrics.network.strategy", "node_pair")
+	v.SetDefault("metrics.network.seed", 0)
+	v.SetDefault("metrics.network.concurrency", 3)
+	v.SetDefault("metrics.network.cycle_budget", 120)
+	v.SetDefault("metrics.network.quality_window", 5)
+	v.SetDefault("metrics.network.degradation_threshold", 30)
+	v.SetDefault("metrics.network.export_crd", false)
+	v.SetDefault("metrics.network.export_ttl", 3600)
+	v.SetDefault("metrics.connectivity_probes.history", 120)
+	v.SetDefault("metrics.connectivity_probes.flap_window", 10)
+	v.SetDefault("metrics.connectivity_probes.flap_threshold", 3)
+	v.SetDefault("metrics.external_targets.interval", 300)
+	v.SetDefault("metrics.external_targets.timeout", 10)
+	v.SetDefault("metrics.external_targets.concurrency", 5)
 
-	viper.SetDefault("llm.provider", "openai")
-	viper.SetDefault("llm.model", "gpt-4")
-	viper.SetDefault("llm.max_tokens", 2000)
-	viper.SetDefault("llm.temperature", 0.1)
-	viper.SetDefault("llm.timeout", 30)
+	v.SetDefault("analysis.enable_prediction", true)
+	v.SetDefault("analysis.enable_auto_fix", false)
+	v.SetDefault("analysis.max_context_events", 100)
+	v.SetDefault("analysis.prompts.dir", "./configs/prompts")
+	v.SetDefault("analysis.incidents.enabled", true)
+	v.SetDefault("analysis.incidents.interval", 60)
+	v.SetDefault("analysis.incidents.window", 600)
+	v.SetDefault("analysis.incidents.reasons", []string{
+		"BackOff", "CrashLoopBackOff", "FailedScheduling", "OOMKilling", "OOMKilled",
+		"Evicted", "Unhealthy", "FailedMount", "NodeNotReady",
+	})
+	v.SetDefault("analysis.incidents.spike_threshold", 90.0)
+	v.SetDefault("analysis.incidents.describe", false)
+	v.SetDefault("analysis.reports.enabled", true)
+	v.SetDefault("analysis.reports.schedule", "10 0 * * *")
+	v.SetDefault("analysis.reports.profile", "")
+	v.SetDefault("analysis.rag.enabled", true)
+	v.SetDefault("analysis.rag.interval", 300)
+	v.SetDefault("analysis.rag.store", "memory")
+	v.SetDefault("analysis.rag.embedder", "hash")
+	v.SetDefault("analysis.rag.dimensions", 512)
+	v.SetDefault("analysis.rag.top_k", 3)
+	v.SetDefault("analysis.rag.min_score", 0.3)
+	v.SetDefault("analysis.rag.max_documents", 20000)
 
-	viper.SetDefault("storage.type", "memory")
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.output", "stdout")
 
-	viper.SetDefault("monitoring.metrics_interval", 30)
-	viper.SetDefault("monitoring.event_retention", 168)
-	viper.SetDefault("monitoring.log_retention", 24)
+	v.SetDefault("tls.enabled", false)
+	v.SetDefault("tls.cert_file", "/etc/k8s-llm-monitor/tls/tls.crt")
+	v.SetDefault("tls.key_file", "/etc/k8s-llm-monitor/tls/tls.key")
+	v.SetDefault("tls.ca_file", "/etc/k8s-llm-monitor/tls/ca.crt")
+	v.SetDefault("tls.client_auth", "verify_if_given")
+	v.SetDefault("tls.agent_sans", []string{"uav-agent.*.svc", "uav-agent.*.svc.cluster.local"})
+	v.SetDefault("tls.agent_node_sans", []string{})
 
-	viper.SetDefault("metrics.enabled", true)
-	viper.SetDefault("metrics.collect_interval", 30)
-	viper.SetDefault("metrics.namespaces", []string{"default"})
-	viper.SetDefault("metrics.enable_node", true)
-	viper.SetDefault("metrics.enable_pod", true)
-	viper.SetDefault("metrics.enable_network", false)
-	viper.SetDefault("metrics.enable_custom", false)
-	viper.SetDefault("metrics.cache_retention", 300)
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.path", "")
 
-	viper.SetDefault("analysis.enable_prediction", true)
-	viper.SetDefault("analysis.enable_auto_fix", false)
-	viper.SetDefault("analysis.max_context_events", 100)
+	v.SetDefault("uav.report_signing.enabled", false)
+	v.SetDefault("uav.report_signing.required", true)
+	v.SetDefault("uav.report_signing.master_key", "")
+	v.SetDefault("uav.report_signing.previous_master_keys", []string{})
+	v.SetDefault("uav.report_signing.max_skew", 300)
 
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "json")
-	viper.SetDefault("logging.output", "stdout")
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.service_name", "k8s-llm-monitor")
+
+	v.SetDefault("federation.enabled", false)
+	v.SetDefault("federation.cluster_name", "local")
+	v.SetDefault("federation.timeout", 5)
 }
 
 // processEnvVars 处理环境变量
@@ -179,4 +885,32 @@ func processEnvVars() {
 	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
 		viper.Set("llm.base_url", baseURL)
 	}
+
+	// 使用Anthropic时从 ANTHROPIC_API_KEY 读取Key
+	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" && viper.GetString("llm.provider") == "anthropic" {
+		viper.Set("llm.api_key", apiKey)
+	}
+
+	// 本地Ollama服务地址
+	if host := os.Getenv("OLLAMA_HOST"); host != "" && viper.GetString("llm.provider") == "ollama" {
+		viper.Set("llm.base_url", host)
+	}
+
+	// 处理管理接口Token
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		viper.Set("server.admin_token", token)
+	}
+
+	// 处理UAV上报签名主密钥
+	if key := os.Getenv("UAV_REPORT_SIGNING_KEY"); key != "" {
+		viper.Set("uav.report_signing.master_key", key)
+	}
+
+	// 处理归档对象存储凭证
+	if accessKey := os.Getenv("AWS_ACCESS_KEY_ID"); accessKey != "" {
+		viper.Set("storage.archive.access_key", accessKey)
+	}
+	if secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY"); secretKey != "" {
+		viper.Set("storage.archive.secret_key", secretKey)
+	}
 }
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// serviceAccountNamespaceFile 集群内运行时Pod所在命名空间
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// ConfigMapSource 存放配置的ConfigMap
+type ConfigMapSource struct {
+	Namespace string // 为空时使用Pod所在命名空间
+	Name      string
+	Key       string // ConfigMap中的数据键，默认 config.yaml
+}
+
+// LoadFromConfigMap 从ConfigMap加载配置（集群内运行时使用ServiceAccount凭证）
+func LoadFromConfigMap(ctx context.Context, source *ConfigMapSource) (*Config, error) {
+	source.defaults()
+
+	clientset, err := kubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	configMap, err := clientset.CoreV1().ConfigMaps(source.Namespace).Get(ctx, source.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", source.Namespace, source.Name, err)
+	}
+
+	viper.SetConfigType(configType(source.Key))
+	return load(func() error {
+		return readConfigMap(configMap, source.Key)
+	})
+}
+
+// WatchConfigMap 监听ConfigMap变更并热更新配置（替代文件监听）
+func (w *Watcher) WatchConfigMap(ctx context.Context, source *ConfigMapSource) error {
+	source.defaults()
+
+	clientset, err := kubeClient()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		backoff := time.Second
+		for {
+			err := w.watchConfigMapOnce(ctx, clientset, source)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("ConfigMap watch for %s/%s failed: %v", source.Namespace, source.Name, err)
+				if backoff < time.Minute {
+					backoff *= 2
+				}
+			} else {
+				backoff = time.Second
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}()
+	return nil
+}
+
+// watchConfigMapOnce 建立一次watch，直到连接关闭
+func (w *Watcher) watchConfigMapOnce(ctx context.Context, clientset kubernetes.Interface, source *ConfigMapSource) error {
+	watcher, err := clientset.CoreV1().ConfigMaps(source.Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", source.Name).String(),
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	for event := range watcher.ResultChan() {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			configMap, ok := event.Object.(*corev1.ConfigMap)
+			if !ok {
+				continue
+			}
+			if err := readConfigMap(configMap, source.Key); err != nil {
+				log.Printf("Ignoring invalid config in configmap %s/%s: %v", source.Namespace, source.Name, err)
+				continue
+			}
+			if err := w.reload(); err != nil {
+				log.Printf("Failed to reload config from configmap %s/%s: %v", source.Namespace, source.Name, err)
+			}
+		case watch.Deleted:
+			log.Printf("ConfigMap %s/%s deleted, keeping current config", source.Namespace, source.Name)
+		case watch.Error:
+			return fmt.Errorf("watch error: %v", event.Object)
+		}
+	}
+	return nil
+}
+
+// readConfigMap 将ConfigMap中的配置内容读入viper
+func readConfigMap(configMap *corev1.ConfigMap, key string) error {
+	data, ok := configMap.Data[key]
+	if !ok {
+		return fmt.Errorf("key %q not found in configmap %s/%s", key, configMap.Namespace, configMap.Name)
+	}
+	if err := viper.ReadConfig(bytes.NewBufferString(data)); err != nil {
+		return fmt.Errorf("failed to parse configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+	return nil
+}
+
+// defaults 补全命名空间和数据键
+func (s *ConfigMapSource) defaults() {
+	if s.Key == "" {
+		s.Key = "config.yaml"
+	}
+	if s.Namespace == "" {
+		s.Namespace = podNamespace()
+	}
+}
+
+// podNamespace 当前Pod所在命名空间（集群外运行时为default）
+func podNamespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+		if namespace := strings.TrimSpace(string(data)); namespace != "" {
+			return namespace
+		}
+	}
+	return "default"
+}
+
+// configType 根据数据键的扩展名推断配置格式
+func configType(key string) string {
+	if idx := strings.LastIndex(key, "."); idx >= 0 && idx < len(key)-1 {
+		return key[idx+1:]
+	}
+	return "yaml"
+}
+
+// kubeClient 创建读取ConfigMap/Secret的客户端（优先使用集群内配置）
+func kubeClient() (*kubernetes.Clientset, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		// 集群外运行时回退到kubeconfig（便于本地调试）
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		restConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes config: %w", err)
+		}
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return clientset, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ConnectivityProbesConfig 持久的连通性探测定义：按固定的源/目标定期探测，记录每个探测的历史并检测抖动的链路
+type ConnectivityProbesConfig struct {
+	History       int                       `mapstructure:"history"`        // 每个探测保留的结果数
+	FlapWindow    int                       `mapstructure:"flap_window"`    // 检测抖动时查看的最近结果数
+	FlapThreshold int                       `mapstructure:"flap_threshold"` // 窗口内成功/失败切换达到该次数时判定为抖动
+	Probes        []ConnectivityProbeConfig `mapstructure:"probes"`
+}
+
+// ConnectivityProbeConfig 一个连通性探测：从源选择器匹配的Pod访问目标选择器匹配的Pod，或访问URL
+type ConnectivityProbeConfig struct {
+	Name            string `mapstructure:"name"`
+	SourceNamespace string `mapstructure:"source_namespace"`
+	SourceSelector  string `mapstructure:"source_selector"`  // 标签选择器，例如 app=api
+	TargetNamespace string `mapstructure:"target_namespace"` // 为空时与源相同
+	TargetSelector  string `mapstructure:"target_selector"`
+	URL             string `mapstructure:"url"`            // 与 target_selector 二选一，从源Pod执行HTTP请求
+	Method          string `mapstructure:"method"`         // ping（默认）、tcp、udp、http，URL目标固定为http；udp需要探测Agent
+	Port            int    `mapstructure:"port"`           // tcp/udp/http端口
+	Interval        int    `mapstructure:"interval"`       // 探测间隔（秒）
+	AllowNoReply    bool   `mapstructure:"allow_no_reply"` // udp：目标不做echo时，没有回复也算成功
+}
+
+// 连通性探测支持的方式
+var connectivityProbeMethods = []string{"ping", "tcp", "udp", "http"}
+
+// Validate 检查探测定义：名称唯一，源选择器有效，目标为选择器或http(s) URL之一
+func (c *ConnectivityProbesConfig) Validate() error {
+	if c.FlapWindow < 0 || c.FlapThreshold < 0 || c.History < 0 {
+		return fmt.Errorf("metrics.connectivity_probes: history, flap_window and flap_threshold must not be negative")
+	}
+	names := make(map[string]bool, len(c.Probes))
+	for i, p := range c.Probes {
+		if p.Name == "" {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d]: name is required", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d]: duplicate name %q", i, p.Name)
+		}
+		names[p.Name] = true
+
+		if p.SourceNamespace == "" || p.SourceSelector == "" {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): source_namespace and source_selector are required", i, p.Name)
+		}
+		if _, err := labels.Parse(p.SourceSelector); err != nil {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): invalid source_selector: %w", i, p.Name, err)
+		}
+		if (p.TargetSelector == "") == (p.URL == "") {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): exactly one of target_selector and url is required", i, p.Name)
+		}
+		if p.TargetSelector != "" {
+			if _, err := labels.Parse(p.TargetSelector); err != nil {
+				return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): invalid target_selector: %w", i, p.Name, err)
+			}
+			if p.Method != "" && !contains(connectivityProbeMethods, p.Method) {
+				return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): invalid method %q (supported: ping, tcp, udp, http)", i, p.Name, p.Method)
+			}
+			if (p.Method == "tcp" || p.Method == "udp") && (p.Port <= 0 || p.Port > 65535) {
+				return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): port must be between 1 and 65535 for %s probes", i, p.Name, p.Method)
+			}
+		} else if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): url must be an absolute http(s) URL, got %q", i, p.Name, p.URL)
+		}
+		if p.Interval < 1 {
+			return fmt.Errorf("metrics.connectivity_probes.probes[%d] (%s): interval must be at least 1, got %d", i, p.Name, p.Interval)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ExternalTargetsConfig 从集群内部定期访问的外部目标（LLM服务API、对象存储、GCS等），
+// 由每个节点上的探测Agent测量DNS解析、TCP建连、TLS握手和HTTP请求的耗时（需要启用 probe_agents）
+type ExternalTargetsConfig struct {
+	Interval    int                    `mapstructure:"interval"`    // 探测间隔（秒），metrics.intervals.external 优先
+	Timeout     int                    `mapstructure:"timeout"`     // 单次请求超时（秒）
+	Concurrency int                    `mapstructure:"concurrency"` // 同时进行的请求数
+	Targets     []ExternalTargetConfig `mapstructure:"targets"`
+}
+
+// ExternalTargetConfig 一个外部目标
+type ExternalTargetConfig struct {
+	Name         string `mapstructure:"name"`
+	URL          string `mapstructure:"url"`           // http(s) URL，例如 https://api.openai.com/v1/models
+	ExpectStatus int    `mapstructure:"expect_status"` // 期望的HTTP状态码，为0时收到任何响应都算可达（未带凭据访问API通常返回401）
+}
+
+// Validate 检查目标定义：名称唯一、URL为http(s)，且已启用探测Agent
+func (c *ExternalTargetsConfig) Validate(agents ProbeAgentsConfig) error {
+	if len(c.Targets) == 0 {
+		return nil
+	}
+	if !agents.Enabled {
+		return fmt.Errorf("metrics.external_targets requires metrics.probe_agents.enabled")
+	}
+	if c.Interval < 1 {
+		return fmt.Errorf("metrics.external_targets.interval must be at least 1, got %d", c.Interval)
+	}
+	if c.Timeout < 1 || c.Timeout > 30 {
+		return fmt.Errorf("metrics.external_targets.timeout must be between 1 and 30, got %d", c.Timeout)
+	}
+	names := make(map[string]bool, len(c.Targets))
+	for i, t := range c.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("metrics.external_targets.targets[%d]: name is required", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("metrics.external_targets.targets[%d]: duplicate name %q", i, t.Name)
+		}
+		names[t.Name] = true
+		if u, err := url.Parse(t.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("metrics.external_targets.targets[%d] (%s): url must be an absolute http(s) URL, got %q", i, t.Name, t.URL)
+		}
+		if t.ExpectStatus != 0 && (t.ExpectStatus < 100 || t.ExpectStatus > 599) {
+			return fmt.Errorf("metrics.external_targets.targets[%d] (%s): expect_status must be between 100 and 599, got %d", i, t.Name, t.ExpectStatus)
+		}
+	}
+	return nil
+}
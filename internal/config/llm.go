@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileName 顶层LLM配置对应的配置名
+const DefaultProfileName = "default"
+
+// ErrUnknownProfile 请求的LLM配置不存在
+var ErrUnknownProfile = errors.New("unknown llm profile")
+
+// 超出每日token预算时的处理方式
+const (
+	BudgetReject  = "reject"
+	BudgetDegrade = "degrade"
+)
+
+// LLMProviders 支持的LLM提供商
+var LLMProviders = []string{"openai", "openai-compatible", "anthropic", "ollama"}
+
+// Profile 返回指定名称的LLM配置，未设置的字段继承顶层配置；name为空或"default"时返回顶层配置
+func (c *LLMConfig) Profile(name string) (LLMProfile, error) {
+	temperature := c.Temperature
+	base := LLMProfile{
+		Name:        DefaultProfileName,
+		Provider:    c.Provider,
+		APIKey:      c.APIKey,
+		BaseURL:     c.BaseURL,
+		Model:       c.Model,
+		MaxTokens:   c.MaxTokens,
+		Temperature: &temperature,
+		Timeout:     c.Timeout,
+		Fallbacks:   c.Fallbacks,
+	}
+	if name == "" || name == DefaultProfileName {
+		if profile, ok := c.Profiles[DefaultProfileName]; ok {
+			return mergeProfile(base, profile, DefaultProfileName), nil
+		}
+		return base, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return LLMProfile{}, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	return mergeProfile(base, profile, name), nil
+}
+
+// ProfileFor 选择LLM配置：请求指定 > 分析类型路由 > default_profile > 顶层配置
+func (c *LLMConfig) ProfileFor(analysisType, requested string) (LLMProfile, error) {
+	if requested != "" {
+		return c.Profile(requested)
+	}
+	if name, ok := c.Routing[analysisType]; ok && name != "" {
+		return c.Profile(name)
+	}
+	return c.Profile(c.DefaultProfile)
+}
+
+// Chain 返回请求使用的配置及其后备配置（按顺序，去掉重复和自身）
+func (c *LLMConfig) Chain(analysisType, requested string) ([]LLMProfile, error) {
+	primary, err := c.ProfileFor(analysisType, requested)
+	if err != nil {
+		return nil, err
+	}
+	chain := []LLMProfile{primary}
+	seen := map[string]bool{primary.Name: true}
+	for _, name := range primary.Fallbacks {
+		if name == "" {
+			name = DefaultProfileName
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		profile, err := c.Profile(name)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, profile)
+	}
+	return chain, nil
+}
+
+// IsLocal 配置是否为本地模型：ollama 或列在 llm.redaction.local_profiles 中
+func (c *LLMConfig) IsLocal(profile LLMProfile) bool {
+	if profile.Provider == "ollama" {
+		return true
+	}
+	for _, name := range c.Redaction.LocalProfiles {
+		if name == profile.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalChain 严格模式下使用的配置：去掉请求链中的外部配置，都不是本地配置时按配置名顺序改用所有本地配置
+func (c *LLMConfig) LocalChain(chain []LLMProfile) []LLMProfile {
+	var local []LLMProfile
+	for _, profile := range chain {
+		if c.IsLocal(profile) {
+			local = append(local, profile)
+		}
+	}
+	if len(local) > 0 {
+		return local
+	}
+	for _, name := range c.ProfileNames() {
+		if profile, err := c.Profile(name); err == nil && c.IsLocal(profile) {
+			local = append(local, profile)
+		}
+	}
+	return local
+}
+
+// ProfileNames 返回所有可用的配置名（含default）
+func (c *LLMConfig) ProfileNames() []string {
+	names := []string{DefaultProfileName}
+	for name := range c.Profiles {
+		if name != DefaultProfileName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// Validate 检查提供商是否支持，以及路由和默认配置引用的配置名是否存在
+func (c *LLMConfig) Validate() error {
+	for _, name := range c.ProfileNames() {
+		profile, err := c.Profile(name)
+		if err != nil {
+			return err
+		}
+		field := "llm"
+		if name != DefaultProfileName {
+			field = "llm.profiles." + name
+		}
+		if err := validateProvider(profile); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		for _, fallback := range profile.Fallbacks {
+			if _, err := c.Profile(fallback); err != nil {
+				return fmt.Errorf("%s.fallbacks: %w", field, err)
+			}
+		}
+	}
+	if _, err := c.Profile(c.DefaultProfile); err != nil {
+		return fmt.Errorf("llm.default_profile: %w", err)
+	}
+	switch c.BudgetAction {
+	case "", BudgetReject, BudgetDegrade:
+	default:
+		return fmt.Errorf("llm.budget_action: must be %q or %q", BudgetReject, BudgetDegrade)
+	}
+	if c.Cache.Enabled && c.Cache.TTL <= 0 {
+		return fmt.Errorf("llm.cache.ttl: must be positive")
+	}
+	for analysisType, name := range c.Routing {
+		if _, err := c.Profile(name); err != nil {
+			return fmt.Errorf("llm.routing.%s: %w", analysisType, err)
+		}
+	}
+	return c.Redaction.validate(c)
+}
+
+// validate 检查脱敏正则和本地配置名
+func (r *LLMRedactionConfig) validate(c *LLMConfig) error {
+	for _, pattern := range r.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("llm.redaction.patterns: %w", err)
+		}
+	}
+	for _, pattern := range r.AnnotationPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("llm.redaction.annotation_patterns: %w", err)
+		}
+	}
+	for _, name := range r.LocalProfiles {
+		if _, err := c.Profile(name); err != nil {
+			return fmt.Errorf("llm.redaction.local_profiles: %w", err)
+		}
+	}
+	return nil
+}
+
+// mergeProfile 用命名配置覆盖顶层配置中已设置的字段
+func mergeProfile(base, profile LLMProfile, name string) LLMProfile {
+	merged := base
+	merged.Name = name
+	if profile.Provider != "" && profile.Provider != base.Provider {
+		// 切换提供商时不继承另一提供商的凭证和地址
+		merged.Provider = profile.Provider
+		merged.APIKey = ""
+		merged.BaseURL = ""
+	}
+	if profile.APIKey != "" {
+		merged.APIKey = profile.APIKey
+	}
+	if profile.BaseURL != "" {
+		merged.BaseURL = profile.BaseURL
+	}
+	if profile.Model != "" {
+		merged.Model = profile.Model
+	}
+	if profile.MaxTokens > 0 {
+		merged.MaxTokens = profile.MaxTokens
+	}
+	if profile.Temperature != nil {
+		temperature := *profile.Temperature
+		merged.Temperature = &temperature
+	}
+	if profile.Timeout > 0 {
+		merged.Timeout = profile.Timeout
+	}
+	merged.DailyTokenBudget = profile.DailyTokenBudget
+	if len(profile.Fallbacks) > 0 {
+		merged.Fallbacks = profile.Fallbacks
+	}
+	return merged
+}
+
+// validateProvider 检查提供商名称及其必需的字段
+func validateProvider(profile LLMProfile) error {
+	if profile.Provider == "" {
+		return nil
+	}
+	for _, provider := range LLMProviders {
+		if profile.Provider == provider {
+			if provider == "openai-compatible" && profile.BaseURL == "" {
+				return fmt.Errorf("provider %q requires base_url", provider)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported provider %q (supported: %s)", profile.Provider, strings.Join(LLMProviders, ", "))
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PodFilterConfig 限定采集哪些Pod的指标，大集群中避免每个周期采集大量无关Pod
+type PodFilterConfig struct {
+	LabelSelector string   `mapstructure:"label_selector"` // 例如 app=llm-inference，在API Server端过滤
+	FieldSelector string   `mapstructure:"field_selector"` // 例如 status.phase=Running，在API Server端过滤
+	Exclude       []string `mapstructure:"exclude"`        // 排除的 namespace 或 namespace/pod（支持glob，例如 default/debug-*）
+}
+
+// Validate 检查selector和排除规则的语法
+func (c *PodFilterConfig) Validate() error {
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("metrics.pod_filter.label_selector: %w", err)
+	}
+	if _, err := fields.ParseSelector(c.FieldSelector); err != nil {
+		return fmt.Errorf("metrics.pod_filter.field_selector: %w", err)
+	}
+	for _, pattern := range c.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("metrics.pod_filter.exclude: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 敏感配置值的引用前缀：
+//
+//	secret://[namespace/]name/key  从Kubernetes Secret读取
+//	file:///path/to/file           从文件读取（例如挂载的Secret卷）
+const (
+	secretRefPrefix = "secret://"
+	fileRefPrefix   = "file://"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "******"
+
+// resolveSecrets 解析配置中所有字符串字段的Secret/文件引用
+func resolveSecrets(cfg *Config) error {
+	var clientset kubernetes.Interface
+	return walkStrings("", reflect.ValueOf(cfg).Elem(), func(key string, field reflect.Value) error {
+		value := field.String()
+		switch {
+		case strings.HasPrefix(value, fileRefPrefix):
+			path := strings.TrimPrefix(value, fileRefPrefix)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s from file %s: %w", key, path, err)
+			}
+			field.SetString(strings.TrimSpace(string(data)))
+
+		case strings.HasPrefix(value, secretRefPrefix):
+			if clientset == nil {
+				client, err := kubeClient()
+				if err != nil {
+					return fmt.Errorf("failed to resolve %s: %w", key, err)
+				}
+				clientset = client
+			}
+			resolved, err := readSecretRef(clientset, strings.TrimPrefix(value, secretRefPrefix))
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", key, err)
+			}
+			field.SetString(resolved)
+		}
+		return nil
+	})
+}
+
+// readSecretRef 读取 [namespace/]name/key 形式的Secret引用
+func readSecretRef(clientset kubernetes.Interface, ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	var namespace, name, key string
+	switch len(parts) {
+	case 2:
+		namespace, name, key = podNamespace(), parts[0], parts[1]
+	case 3:
+		namespace, name, key = parts[0], parts[1], parts[2]
+	default:
+		return "", fmt.Errorf("invalid secret reference %q (expected secret://[namespace/]name/key)", ref)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in secret %s/%s", key, namespace, name)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Redacted 返回敏感字段已脱敏的配置副本，用于日志和配置导出
+func (c *Config) Redacted() Config {
+	clone := *c
+	walkStrings("", reflect.ValueOf(&clone).Elem(), func(key string, field reflect.Value) error {
+		if isSensitiveKey(key) && field.String() != "" {
+			field.SetString(redactedValue)
+		}
+		return nil
+	})
+	return clone
+}
+
+// walkStrings 遍历结构体中的字符串字段（key为mapstructure路径）
+func walkStrings(prefix string, value reflect.Value, fn func(key string, field reflect.Value) error) error {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if err := walkStrings(joinKey(prefix, value.Type().Field(i)), value.Field(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map元素不可寻址，写入新map后整体替换（Redacted的浅拷贝因此不会修改原配置）
+		elemKind := value.Type().Elem().Kind()
+		if value.IsNil() || !value.CanSet() || (elemKind != reflect.Struct && elemKind != reflect.String) {
+			return nil
+		}
+		replaced := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			item := reflect.New(value.Type().Elem()).Elem()
+			item.Set(iter.Value())
+			// 字符串map（如节点密钥）沿用map本身的配置路径
+			itemKey := prefix
+			if elemKind == reflect.Struct {
+				itemKey = fmt.Sprintf("%s.%v", prefix, iter.Key().Interface())
+			}
+			if err := walkStrings(itemKey, item, fn); err != nil {
+				return err
+			}
+			replaced.SetMapIndex(iter.Key(), item)
+		}
+		value.Set(replaced)
+	case reflect.Slice:
+		// 同样写入新切片后整体替换，元素沿用切片本身的配置路径
+		if value.IsNil() || !value.CanSet() || value.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		replaced := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(replaced, value)
+		for i := 0; i < replaced.Len(); i++ {
+			if err := walkStrings(prefix, replaced.Index(i), fn); err != nil {
+				return err
+			}
+		}
+		value.Set(replaced)
+	case reflect.String:
+		if value.CanSet() {
+			return fn(prefix, value)
+		}
+	}
+	return nil
+}
+
+// joinKey 拼接配置路径
+func joinKey(prefix string, field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isSensitiveKey 判断配置项是否为凭证（按路径最后一段判断，避免误伤 max_tokens 等字段）
+func isSensitiveKey(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	for _, marker := range []string{"password", "api_key", "access_key", "secret"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	switch {
+	case name == "token", name == "key", name == "keys":
+		return true
+	case strings.HasSuffix(name, "_token"), strings.HasSuffix(name, "_key"), strings.HasSuffix(name, "_keys"):
+		return true
+	}
+	return false
+}
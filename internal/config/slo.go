@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// SLOConfig 基于采集数据计算的服务等级目标，例如“99%的Pod对RTT低于50ms”
+type SLOConfig struct {
+	Name        string  `mapstructure:"name"`
+	Description string  `mapstructure:"description"`
+	Target      string  `mapstructure:"target"`    // node、pod、pair、uav
+	Match       string  `mapstructure:"match"`     // 对象名称的glob：节点名、namespace/pod、source|dest、UAV节点名，为空时匹配全部
+	Metric      string  `mapstructure:"metric"`    // 样本指标名，例如 rtt_ms、healthy、ready
+	Operator    string  `mapstructure:"operator"`  // 样本满足 metric <operator> threshold 时计为达标：<、<=、>、>=、==、!=
+	Threshold   float64 `mapstructure:"threshold"` // 阈值
+	Objective   float64 `mapstructure:"objective"` // 目标达标比例 (0-100)，例如 99.9
+	Window      int     `mapstructure:"window"`    // 滚动窗口（小时），默认720（30天）
+}
+
+// SLO支持的对象类型和比较运算符
+var (
+	sloTargets   = []string{"node", "pod", "pair", "uav"}
+	sloOperators = []string{"<", "<=", ">", ">=", "==", "!="}
+)
+
+// ValidateSLOs 检查SLO定义：名称唯一，对象类型、运算符、目标比例和匹配规则有效
+func ValidateSLOs(slos []SLOConfig) error {
+	names := make(map[string]bool, len(slos))
+	for i, slo := range slos {
+		if slo.Name == "" {
+			return fmt.Errorf("metrics.slos[%d]: name is required", i)
+		}
+		if names[slo.Name] {
+			return fmt.Errorf("metrics.slos[%d]: duplicate name %q", i, slo.Name)
+		}
+		names[slo.Name] = true
+
+		if !contains(sloTargets, slo.Target) {
+			return fmt.Errorf("metrics.slos[%d] (%s): invalid target %q (supported: node, pod, pair, uav)", i, slo.Name, slo.Target)
+		}
+		if slo.Metric == "" {
+			return fmt.Errorf("metrics.slos[%d] (%s): metric is required", i, slo.Name)
+		}
+		if !contains(sloOperators, slo.Operator) {
+			return fmt.Errorf("metrics.slos[%d] (%s): invalid operator %q (supported: <, <=, >, >=, ==, !=)", i, slo.Name, slo.Operator)
+		}
+		if slo.Objective <= 0 || slo.Objective >= 100 {
+			return fmt.Errorf("metrics.slos[%d] (%s): objective must be between 0 and 100 (exclusive), got %v", i, slo.Name, slo.Objective)
+		}
+		if slo.Window < 0 {
+			return fmt.Errorf("metrics.slos[%d] (%s): window must not be negative", i, slo.Name)
+		}
+		if _, err := path.Match(slo.Match, ""); err != nil {
+			return fmt.Errorf("metrics.slos[%d] (%s): invalid match pattern %q: %w", i, slo.Name, slo.Match, err)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
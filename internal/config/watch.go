@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// hotReloadKeys 可在运行时安全修改的配置项（前缀匹配）
+var hotReloadKeys = []string{
+	"logging.level",
+	"metrics.collect_interval",
+	"metrics.intervals",
+	"metrics.jitter",
+	"metrics.namespaces",
+	"metrics.pod_filter",
+	"metrics.cache_retention",
+	"metrics.anomalies.z_threshold",
+	"metrics.anomalies.alpha",
+	"metrics.anomalies.min_samples",
+	"metrics.anomalies.resolve_after",
+	"monitoring.event_retention",
+	"monitoring.event_filter.llm",
+	"monitoring.event_filter.profile",
+	"monitoring.event_filter.drop_noise",
+	"monitoring.event_filter.overrides",
+	"llm",
+	"analysis.prompts",
+}
+
+// Change 一项配置变更
+type Change struct {
+	Key       string      `json:"key"`
+	Old       interface{} `json:"old"`
+	New       interface{} `json:"new"`
+	HotReload bool        `json:"hot_reload"` // false表示需要重启才能生效
+}
+
+// ChangeHandler 配置变更回调
+type ChangeHandler func(old, current *Config, changes []Change)
+
+// Watcher 配置文件监听器
+type Watcher struct {
+	current  *Config
+	handlers []ChangeHandler
+	mu       sync.Mutex
+}
+
+// NewWatcher 创建配置监听器，current为当前生效的配置
+func NewWatcher(current *Config) *Watcher {
+	return &Watcher{current: current}
+}
+
+// OnChange 注册配置变更回调
+func (w *Watcher) OnChange(handler ChangeHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Current 返回当前配置
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start 开始监听配置文件（需在 Load 之后调用）
+func (w *Watcher) Start() {
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		if err := w.reload(); err != nil {
+			log.Printf("Failed to reload config %s: %v", event.Name, err)
+		}
+	})
+	viper.WatchConfig()
+}
+
+// reload 重新解析配置并通知变更
+func (w *Watcher) reload() error {
+	processEnvVars()
+
+	var next Config
+	if err := viper.Unmarshal(&next); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := resolveSecrets(&next); err != nil {
+		return err
+	}
+	if err := next.LLM.Validate(); err != nil {
+		return err
+	}
+	if err := next.Metrics.PodFilter.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateSLOs(next.Metrics.SLOs); err != nil {
+		return err
+	}
+	if err := next.Metrics.UAVStaleness.Validate(); err != nil {
+		return err
+	}
+	if err := next.Metrics.Bandwidth.Validate(); err != nil {
+		return err
+	}
+	if err := next.Metrics.ProbeAgents.Validate(); err != nil {
+		return err
+	}
+	if err := next.Metrics.NodeLatency.Validate(next.Metrics.ProbeAgents); err != nil {
+		return err
+	}
+	if err := next.Metrics.ConnectivityProbes.Validate(); err != nil {
+		return err
+	}
+	if err := next.Metrics.ExternalTargets.Validate(next.Metrics.ProbeAgents); err != nil {
+		return err
+	}
+	if err := next.Metrics.Network.Validate(); err != nil {
+		return err
+	}
+	if next.Metrics.ProbeAgents.Namespace == "" {
+		next.Metrics.ProbeAgents.Namespace = podNamespace()
+	}
+
+	w.mu.Lock()
+	old := w.current
+	changes := Diff(old, &next)
+	if len(changes) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	w.current = &next
+	handlers := append([]ChangeHandler(nil), w.handlers...)
+	w.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, &next, changes)
+	}
+	return nil
+}
+
+// Diff 比较两份配置，返回发生变化的叶子配置项
+func Diff(old, current *Config) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(*old), reflect.ValueOf(*current), &changes)
+	return changes
+}
+
+func diffValue(prefix string, old, current reflect.Value, changes *[]Change) {
+	if old.Kind() == reflect.Struct {
+		for i := 0; i < old.NumField(); i++ {
+			field := old.Type().Field(i)
+			diffValue(joinKey(prefix, field), old.Field(i), current.Field(i), changes)
+		}
+		return
+	}
+
+	// 结构体map（如 llm.profiles）逐项比较，保证其中的凭证字段同样脱敏
+	if old.Kind() == reflect.Map && old.Type().Elem().Kind() == reflect.Struct {
+		zero := reflect.Zero(old.Type().Elem())
+		seen := make(map[string]bool)
+		for _, key := range append(old.MapKeys(), current.MapKeys()...) {
+			name := fmt.Sprint(key.Interface())
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			oldItem, currentItem := old.MapIndex(key), current.MapIndex(key)
+			if !oldItem.IsValid() {
+				oldItem = zero
+			}
+			if !currentItem.IsValid() {
+				currentItem = zero
+			}
+			diffValue(prefix+"."+name, oldItem, currentItem, changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(old.Interface(), current.Interface()) {
+		return
+	}
+
+	change := Change{
+		Key:       prefix,
+		Old:       old.Interface(),
+		New:       current.Interface(),
+		HotReload: IsHotReloadable(prefix),
+	}
+	if isSensitiveKey(prefix) {
+		change.Old, change.New = redactedValue, redactedValue
+	}
+	*changes = append(*changes, change)
+}
+
+// IsHotReloadable 判断配置项是否支持热更新
+func IsHotReloadable(key string) bool {
+	for _, safe := range hotReloadKeys {
+		if key == safe || strings.HasPrefix(key, safe+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestIsHotReloadable(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"logging.level", true},
+		{"metrics.anomalies.z_threshold", true},
+		{"metrics.anomalies.resolve_after", true},
+		{"metrics.anomalies.enabled", false},
+		{"metrics.anomalies.triage", false},
+		{"metrics.intervals", true},
+		{"metrics.jitter", true},
+		{"metrics.node_latency.interval", false},
+		{"llm.profiles.fast.model", true},
+		{"llmx", false},
+		{"storage.type", false},
+	}
+	for _, tt := range tests {
+		if got := IsHotReloadable(tt.key); got != tt.want {
+			t.Errorf("IsHotReloadable(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDiffMarksHotReload(t *testing.T) {
+	old := &Config{}
+	current := &Config{}
+	current.Metrics.Anomalies.ZThreshold = 4
+	current.Metrics.Anomalies.Triage = true
+
+	changes := Diff(old, current)
+	got := make(map[string]bool)
+	for _, change := range changes {
+		got[change.Key] = change.HotReload
+	}
+	if len(got) != 2 || !got["metrics.anomalies.z_threshold"] || got["metrics.anomalies.triage"] {
+		t.Errorf("Diff() = %+v, want z_threshold hot-reloadable and triage requiring restart", changes)
+	}
+}
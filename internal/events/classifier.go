@@ -0,0 +1,386 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 事件分类
+const (
+	ClassNoise         = "noise"
+	ClassInformational = "informational"
+	ClassActionable    = "actionable"
+)
+
+// 分类来源
+const (
+	ByOverride = "override"
+	ByRules    = "rules"
+	ByLLM      = "llm"
+	ByDefault  = "default"
+)
+
+// TypeClassification 事件分类在 llm.routing 中使用的分析类型
+const TypeClassification = "event_classification"
+
+const (
+	maxPending    = 1000 // 等待LLM分类的事件上限，超出时直接按默认分类
+	maxVerdicts   = 5000 // 缓存的LLM分类结果（按事件模式）
+	classifyLimit = time.Minute
+)
+
+// ClassifierStats 分类统计
+type ClassifierStats struct {
+	Classes        map[string]int64 `json:"classes"`
+	ClassifiedBy   map[string]int64 `json:"classified_by"`
+	Dropped        int64            `json:"dropped"` // drop_noise 丢弃的事件数
+	Pending        int              `json:"pending"`
+	CachedPatterns int              `json:"cached_patterns"`
+	CacheHits      int64            `json:"cache_hits"`
+	LLMCalls       int64            `json:"llm_calls"`
+	LLMErrors      int64            `json:"llm_errors"`
+	LastError      string           `json:"last_error,omitempty"`
+}
+
+// Classifier 在事件记录和转发前分类（实现 k8s.EventHandler 接口）：
+// 先按配置的覆盖和内置规则判断，无法判断的事件按模式去重后批量交给LLM，结果按模式缓存
+type Classifier struct {
+	next      k8s.EventHandler
+	service   *llm.Service
+	templates *prompts.Registry
+	logger    *logrus.Entry
+	pending   chan *models.EventInfo
+
+	mu        sync.Mutex
+	cfg       config.EventFilterConfig
+	overrides map[string]string // 小写的事件原因 -> 分类
+	verdicts  map[string]string // 事件模式 -> LLM分类
+	stats     ClassifierStats
+}
+
+// NewClassifier 创建事件分类器，分类后的事件转发给 next；启用LLM时需要调用 Start
+func NewClassifier(cfg config.EventFilterConfig, next k8s.EventHandler, service *llm.Service, templates *prompts.Registry) *Classifier {
+	c := &Classifier{
+		next:      next,
+		service:   service,
+		templates: templates,
+		logger:    logging.For("events"),
+		pending:   make(chan *models.EventInfo, maxPending),
+		verdicts:  make(map[string]string),
+		stats: ClassifierStats{
+			Classes:      make(map[string]int64),
+			ClassifiedBy: make(map[string]int64),
+		},
+	}
+	c.Update(cfg)
+	return c
+}
+
+// Update 更新配置（热更新），无效的覆盖项被忽略
+func (c *Classifier) Update(cfg config.EventFilterConfig) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10
+	}
+	overrides := make(map[string]string, len(cfg.Overrides))
+	for reason, class := range cfg.Overrides {
+		class = strings.ToLower(strings.TrimSpace(class))
+		switch class {
+		case ClassNoise, ClassInformational, ClassActionable:
+			overrides[strings.ToLower(reason)] = class
+		default:
+			c.logger.Warnf("Ignoring event filter override %s: unknown class %q", reason, class)
+		}
+	}
+
+	c.mu.Lock()
+	c.cfg = cfg
+	c.overrides = overrides
+	c.mu.Unlock()
+}
+
+// Stats 返回分类统计
+func (c *Classifier) Stats() ClassifierStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Classes = make(map[string]int64, len(c.stats.Classes))
+	for k, v := range c.stats.Classes {
+		stats.Classes[k] = v
+	}
+	stats.ClassifiedBy = make(map[string]int64, len(c.stats.ClassifiedBy))
+	for k, v := range c.stats.ClassifiedBy {
+		stats.ClassifiedBy[k] = v
+	}
+	stats.Pending = len(c.pending)
+	stats.CachedPatterns = len(c.verdicts)
+	return stats
+}
+
+// OnEvent 分类后转发；需要LLM判断的事件在批量分类完成后才转发
+func (c *Classifier) OnEvent(event *models.EventInfo) {
+	if event == nil {
+		return
+	}
+
+	c.mu.Lock()
+	useLLM := c.cfg.LLM
+	class, by := c.classifyLocked(event)
+	if class == "" {
+		if verdict, ok := c.verdicts[patternKey(event)]; ok {
+			class, by = verdict, ByLLM
+			c.stats.CacheHits++
+		}
+	}
+	c.mu.Unlock()
+
+	if class == "" && useLLM {
+		select {
+		case c.pending <- event:
+			return
+		default:
+			c.logger.Debugf("Event classification queue full, using default class for %s", event.Reason)
+		}
+	}
+	if class == "" {
+		class, by = defaultClass(event), ByDefault
+	}
+	c.forward(event, class, by)
+}
+
+// classifyLocked 按覆盖和内置规则分类，无法判断时返回空字符串
+func (c *Classifier) classifyLocked(event *models.EventInfo) (string, string) {
+	if class, ok := c.overrides[strings.ToLower(event.Reason)]; ok {
+		return class, ByOverride
+	}
+	if class := classifyByRules(event); class != "" {
+		return class, ByRules
+	}
+	return "", ""
+}
+
+func (c *Classifier) forward(event *models.EventInfo, class, by string) {
+	event.Class, event.ClassifiedBy = class, by
+
+	c.mu.Lock()
+	c.stats.Classes[class]++
+	c.stats.ClassifiedBy[by]++
+	drop := class == ClassNoise && c.cfg.DropNoise
+	if drop {
+		c.stats.Dropped++
+	}
+	c.mu.Unlock()
+
+	if !drop {
+		c.next.OnEvent(event)
+	}
+}
+
+// Start 批量分类等待中的事件：凑满 batch_size 个不同的事件模式或等待 flush_interval 后调用一次LLM
+func (c *Classifier) Start(ctx context.Context) {
+	for {
+		var first *models.EventInfo
+		select {
+		case <-ctx.Done():
+			c.flush()
+			return
+		case first = <-c.pending:
+		}
+
+		c.mu.Lock()
+		batchSize, interval := c.cfg.BatchSize, time.Duration(c.cfg.FlushInterval)*time.Second
+		c.mu.Unlock()
+
+		batch := []*models.EventInfo{first}
+		patterns := map[string]bool{patternKey(first): true}
+		timer := time.NewTimer(interval)
+	collect:
+		for len(patterns) < batchSize {
+			select {
+			case <-ctx.Done():
+				break collect
+			case <-timer.C:
+				break collect
+			case event := <-c.pending:
+				batch = append(batch, event)
+				patterns[patternKey(event)] = true
+			}
+		}
+		timer.Stop()
+
+		c.resolve(ctx, batch)
+	}
+}
+
+// flush 停止时按默认分类转发剩余的事件
+func (c *Classifier) flush() {
+	for {
+		select {
+		case event := <-c.pending:
+			c.forward(event, defaultClass(event), ByDefault)
+		default:
+			return
+		}
+	}
+}
+
+// classifyItem 交给LLM的一个事件模式
+type classifyItem struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Kind    string `json:"object_kind,omitempty"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// resolve 为一批事件调用LLM，失败时按默认分类，然后按原顺序转发
+func (c *Classifier) resolve(ctx context.Context, batch []*models.EventInfo) {
+	keys := make([]string, len(batch))
+	var items []*classifyItem
+	byKey := make(map[string]*classifyItem)
+
+	c.mu.Lock()
+	profile, useLLM := c.cfg.Profile, c.cfg.LLM
+	for i, event := range batch {
+		key, message := pattern(event)
+		keys[i] = key
+		if _, ok := c.verdicts[key]; ok {
+			continue
+		}
+		if item, ok := byKey[key]; ok {
+			item.Count++
+			continue
+		}
+		item := &classifyItem{
+			ID:      fmt.Sprintf("e%d", len(items)+1),
+			Type:    event.Type,
+			Kind:    event.ObjectKind,
+			Reason:  event.Reason,
+			Message: message,
+			Count:   1,
+		}
+		byKey[key] = item
+		items = append(items, item)
+	}
+	c.mu.Unlock()
+
+	var classes map[string]string
+	if len(items) > 0 && useLLM {
+		callCtx, cancel := context.WithTimeout(ctx, classifyLimit)
+		result, err := c.classifyWithLLM(callCtx, items, profile)
+		cancel()
+
+		c.mu.Lock()
+		c.stats.LLMCalls++
+		if err != nil {
+			c.stats.LLMErrors++
+			c.stats.LastError = err.Error()
+		}
+		c.mu.Unlock()
+
+		if err != nil {
+			if errors.Is(err, llm.ErrNotConfigured) || errors.Is(err, llm.ErrBudgetExceeded) {
+				c.logger.Debugf("Skipping LLM event classification: %v", err)
+			} else {
+				c.logger.Warnf("Failed to classify %d event patterns: %v", len(items), err)
+			}
+		}
+		classes = result
+	}
+
+	c.mu.Lock()
+	for key, item := range byKey {
+		if class, ok := classes[item.ID]; ok {
+			if len(c.verdicts) >= maxVerdicts {
+				// 缓存满时清空，避免长时间运行后无限增长
+				c.verdicts = make(map[string]string)
+			}
+			c.verdicts[key] = class
+		}
+	}
+	verdicts := make([]string, len(batch))
+	for i, key := range keys {
+		verdicts[i] = c.verdicts[key]
+	}
+	c.mu.Unlock()
+
+	for i, event := range batch {
+		if verdicts[i] != "" {
+			c.forward(event, verdicts[i], ByLLM)
+		} else {
+			c.forward(event, defaultClass(event), ByDefault)
+		}
+	}
+}
+
+// classifyWithLLM 返回 模式ID -> 分类，模型未给出或给出无效分类的模式不在结果中
+func (c *Classifier) classifyWithLLM(ctx context.Context, items []*classifyItem, profile string) (map[string]string, error) {
+	client, err := c.service.Client(TypeClassification, profile)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := c.templates.Render(prompts.EventClassification, map[string]interface{}{"events": items})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Chat(ctx, llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Events []struct {
+			ID    string `json:"id"`
+			Class string `json:"class"`
+		} `json:"events"`
+	}
+	if err := llm.DecodeJSON(resp.Content, &output); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(output.Events))
+	for _, e := range output.Events {
+		class := strings.ToLower(strings.TrimSpace(e.Class))
+		switch class {
+		case ClassNoise, ClassInformational, ClassActionable:
+			result[e.ID] = class
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("model returned no classifications for %d event patterns", len(items))
+	}
+	return result, nil
+}
+
+// OnPodUpdate 直接转发
+func (c *Classifier) OnPodUpdate(pod *models.PodInfo) { c.next.OnPodUpdate(pod) }
+
+// OnServiceUpdate 直接转发
+func (c *Classifier) OnServiceUpdate(service *models.ServiceInfo) { c.next.OnServiceUpdate(service) }
+
+// OnCRDEvent 直接转发
+func (c *Classifier) OnCRDEvent(event *models.CRDEvent) { c.next.OnCRDEvent(event) }
+
+func patternKey(event *models.EventInfo) string {
+	key, _ := pattern(event)
+	return key
+}
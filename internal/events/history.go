@@ -0,0 +1,220 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// Collection 事件在存储中的集合名
+const Collection = "events"
+
+// History 事件历史记录器（实现 k8s.EventHandler 接口）
+type History struct {
+	store     storage.Store
+	retention atomic.Int64 // 保留时长（纳秒），支持运行时调整
+	logger    *logrus.Entry
+
+	// 已记录的事件（watch重连时会重放已有事件，用于去重）
+	seen   map[string]time.Time
+	seenMu sync.Mutex
+}
+
+// Query 事件查询条件
+type Query struct {
+	From      time.Time
+	To        time.Time
+	Namespace string
+	Reasons   []string // 匹配任意一个即可
+	Type      string   // Normal, Warning
+	Object    string   // 关联对象名称
+	Class     string   // noise, informational, actionable
+	Limit     int
+}
+
+// NewHistory 创建事件历史记录器，retention为事件保留时长
+func NewHistory(store storage.Store, retention time.Duration) *History {
+	logger := logging.For("events")
+
+	if retention <= 0 {
+		retention = 168 * time.Hour
+	}
+
+	h := &History{
+		store:  store,
+		logger: logger,
+		seen:   make(map[string]time.Time),
+	}
+	h.retention.Store(int64(retention))
+	return h
+}
+
+// SetRetention 调整事件保留时长（配置热更新）
+func (h *History) SetRetention(retention time.Duration) {
+	if retention > 0 {
+		h.retention.Store(int64(retention))
+	}
+}
+
+// Retention 返回当前事件保留时长
+func (h *History) Retention() time.Duration {
+	return time.Duration(h.retention.Load())
+}
+
+// Start 启动过期事件清理循环
+func (h *History) Start(ctx context.Context) {
+	h.logger.Infof("Event history started (retention: %s)", h.Retention())
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := h.Prune(ctx); err != nil {
+			h.logger.Warnf("Failed to prune event history: %v", err)
+		} else if deleted > 0 {
+			h.logger.Infof("Pruned %d expired events", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune 删除超过保留时长的事件
+func (h *History) Prune(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-h.Retention())
+
+	h.seenMu.Lock()
+	for id, ts := range h.seen {
+		if ts.Before(cutoff) {
+			delete(h.seen, id)
+		}
+	}
+	h.seenMu.Unlock()
+
+	return h.store.DeleteBefore(ctx, Collection, cutoff)
+}
+
+// Record 保存一条事件
+func (h *History) Record(ctx context.Context, event *models.EventInfo) error {
+	if event == nil {
+		return nil
+	}
+
+	timestamp := event.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	if timestamp.Before(time.Now().Add(-h.Retention())) {
+		return nil
+	}
+
+	id := fmt.Sprintf("%s/%d", event.UID, event.Count)
+	if event.UID != "" {
+		h.seenMu.Lock()
+		if _, exists := h.seen[id]; exists {
+			h.seenMu.Unlock()
+			return nil
+		}
+		h.seen[id] = timestamp
+		h.seenMu.Unlock()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return h.store.Append(ctx, Collection, &storage.Record{
+		ID:        id,
+		Key:       event.Namespace,
+		Timestamp: timestamp,
+		Data:      data,
+	})
+}
+
+// Query 查询事件历史（按时间升序）
+func (h *History) Query(ctx context.Context, query Query) ([]*models.EventInfo, error) {
+	records, err := h.store.List(ctx, Collection, storage.Query{
+		Key:  query.Namespace,
+		From: query.From,
+		To:   query.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	result := make([]*models.EventInfo, 0, len(records))
+	for _, record := range records {
+		var event models.EventInfo
+		if err := json.Unmarshal(record.Data, &event); err != nil {
+			continue
+		}
+		if !query.Matches(&event) {
+			continue
+		}
+		result = append(result, &event)
+	}
+
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result, nil
+}
+
+// Matches 判断事件是否满足非时间类过滤条件
+func (q Query) Matches(event *models.EventInfo) bool {
+	if q.Type != "" && !strings.EqualFold(event.Type, q.Type) {
+		return false
+	}
+	if q.Object != "" && event.ObjectName != q.Object {
+		return false
+	}
+	if q.Class != "" && event.Class != q.Class {
+		return false
+	}
+	if len(q.Reasons) > 0 {
+		matched := false
+		for _, reason := range q.Reasons {
+			if strings.EqualFold(event.Reason, reason) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// OnEvent 处理K8s事件
+func (h *History) OnEvent(event *models.EventInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Record(ctx, event); err != nil {
+		h.logger.Warnf("Failed to record event %s: %v", event.Reason, err)
+	}
+}
+
+// OnPodUpdate 事件历史不关心Pod变化
+func (h *History) OnPodUpdate(pod *models.PodInfo) {}
+
+// OnServiceUpdate 事件历史不关心Service变化
+func (h *History) OnServiceUpdate(service *models.ServiceInfo) {}
+
+// OnCRDEvent 事件历史不关心CRD变化
+func (h *History) OnCRDEvent(event *models.CRDEvent) {}
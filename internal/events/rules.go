@@ -0,0 +1,90 @@
+package events
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// noiseReasons 例行的生命周期事件（仅 Normal 类型）
+var noiseReasons = reasonSet(
+	"Pulling", "Pulled", "Created", "Started", "Scheduled",
+	"SuccessfulCreate", "SuccessfulDelete", "SuccessfulAttachVolume", "SuccessfulMountVolume",
+	"Sync", "LeaderElection", "Starting", "NodeAllocatableEnforced",
+	"NodeHasSufficientMemory", "NodeHasNoDiskPressure", "NodeHasSufficientPID",
+	"ExternalProvisioning", "Provisioning", "ProvisioningSucceeded",
+	"EnsuringLoadBalancer", "EnsuredLoadBalancer", "UpdatedLoadBalancer", "NoPods",
+)
+
+// informationalReasons 值得保留作为上下文的状态变化
+var informationalReasons = reasonSet(
+	"ScalingReplicaSet", "SuccessfulRescale", "Killing", "Completed", "SawCompletedJob",
+	"NodeReady", "NodeSchedulable", "NodeNotSchedulable", "RegisteredNode", "RemovingNode", "DeletingNode",
+)
+
+// actionableReasons 故障、降级或配置错误
+var actionableReasons = reasonSet(
+	"BackOff", "CrashLoopBackOff", "Failed", "FailedScheduling", "FailedCreate", "FailedKillPod",
+	"FailedMount", "FailedAttachVolume", "FailedCreatePodSandBox", "FailedPostStartHook", "FailedPreStopHook",
+	"OOMKilling", "OOMKilled", "SystemOOM", "Evicted", "EvictionThresholdMet",
+	"NodeNotReady", "NodeHasDiskPressure", "NodeHasInsufficientMemory", "NodeHasInsufficientPID", "Rebooted",
+	"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "InspectFailed",
+	"NetworkNotReady", "FreeDiskSpaceFailed", "ImageGCFailed", "ContainerGCFailed",
+	"ProvisioningFailed", "FailedBinding", "FailedDaemonPod", "FailedToUpdateEndpoint",
+)
+
+// unhealthyThreshold 探针失败达到该次数才认为需要处理（启动期间偶尔失败很常见）
+const unhealthyThreshold = 3
+
+func reasonSet(reasons ...string) map[string]bool {
+	set := make(map[string]bool, len(reasons))
+	for _, reason := range reasons {
+		set[strings.ToLower(reason)] = true
+	}
+	return set
+}
+
+// classifyByRules 按事件原因和类型分类，无法判断时返回空字符串
+func classifyByRules(event *models.EventInfo) string {
+	reason := strings.ToLower(event.Reason)
+	switch {
+	case reason == "unhealthy":
+		if event.Count >= unhealthyThreshold {
+			return ClassActionable
+		}
+		return ""
+	case actionableReasons[reason]:
+		return ClassActionable
+	case informationalReasons[reason]:
+		return ClassInformational
+	case noiseReasons[reason] && strings.EqualFold(event.Type, "Normal"):
+		return ClassNoise
+	default:
+		return ""
+	}
+}
+
+// defaultClass 规则和LLM都无法判断时：Warning 视为需要处理，其余视为一般信息
+func defaultClass(event *models.EventInfo) string {
+	if strings.EqualFold(event.Type, "Warning") {
+		return ClassActionable
+	}
+	return ClassInformational
+}
+
+// volatileToken 消息中含数字的片段（Pod名后缀、IP、端口、耗时等）
+var volatileToken = regexp.MustCompile(`[A-Za-z_-]*\d[\w.:/-]*`)
+
+// maxPatternMessage 事件模式中消息的最大长度
+const maxPatternMessage = 300
+
+// pattern 同类事件的归并键，去掉消息中易变的部分
+func pattern(event *models.EventInfo) (string, string) {
+	message := volatileToken.ReplaceAllString(event.Message, "*")
+	if len(message) > maxPatternMessage {
+		message = message[:maxPatternMessage]
+	}
+	key := strings.Join([]string{strings.ToLower(event.Type), event.ObjectKind, strings.ToLower(event.Reason), message}, "|")
+	return key, message
+}
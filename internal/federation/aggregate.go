@@ -0,0 +1,104 @@
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// healthRank 健康状态的严重程度，汇总时取最严重的状态
+var healthRank = map[string]int{"healthy": 0, "warning": 1, "critical": 2}
+
+// AggregateClusterMetrics 汇总多个集群的整体指标，使用率按汇总后的总量重新计算；
+// 问题列表以 "[集群名] " 为前缀
+func AggregateClusterMetrics(clusters map[string]*metricstypes.ClusterMetrics) *metricstypes.ClusterMetrics {
+	total := &metricstypes.ClusterMetrics{Timestamp: time.Now().UTC(), HealthStatus: "healthy"}
+
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cluster := clusters[name]
+		if cluster == nil {
+			continue
+		}
+		total.TotalNodes += cluster.TotalNodes
+		total.HealthyNodes += cluster.HealthyNodes
+		total.TotalPods += cluster.TotalPods
+		total.RunningPods += cluster.RunningPods
+		total.TotalCPU += cluster.TotalCPU
+		total.UsedCPU += cluster.UsedCPU
+		total.TotalMemory += cluster.TotalMemory
+		total.UsedMemory += cluster.UsedMemory
+		total.TotalGPUs += cluster.TotalGPUs
+		total.AvailableGPUs += cluster.AvailableGPUs
+
+		if healthRank[cluster.HealthStatus] > healthRank[total.HealthStatus] {
+			total.HealthStatus = cluster.HealthStatus
+		}
+		for _, issue := range cluster.Issues {
+			total.Issues = append(total.Issues, fmt.Sprintf("[%s] %s", name, issue))
+		}
+	}
+
+	if total.TotalCPU > 0 {
+		total.CPUUsageRate = float64(total.UsedCPU) / float64(total.TotalCPU) * 100
+	}
+	if total.TotalMemory > 0 {
+		total.MemoryUsageRate = float64(total.UsedMemory) / float64(total.TotalMemory) * 100
+	}
+	return total
+}
+
+// DecodeClusterMetrics 解析对端返回的集群指标
+func DecodeClusterMetrics(result ClusterResult) (*metricstypes.ClusterMetrics, error) {
+	if result.Error != "" {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	var cluster metricstypes.ClusterMetrics
+	if err := json.Unmarshal(result.Data, &cluster); err != nil {
+		return nil, fmt.Errorf("invalid cluster metrics from %s: %w", result.Cluster, err)
+	}
+	return &cluster, nil
+}
+
+// ClusterUAV 带所属集群的UAV条目
+type ClusterUAV struct {
+	Cluster string `json:"cluster"`
+	*models.UAVMetricsEntry
+}
+
+// MergeUAVs 合并多个集群的UAV列表（key见 models.UAVKey），每个条目增加 cluster 字段，
+// 按集群名、节点名和UAV序号排序
+func MergeUAVs(clusters map[string]map[string]*models.UAVMetricsEntry) []ClusterUAV {
+	merged := make([]ClusterUAV, 0)
+	for cluster, uavs := range clusters {
+		for nodeName, entry := range uavs {
+			if entry == nil {
+				continue
+			}
+			if entry.NodeName == "" {
+				entry.NodeName = nodeName
+			}
+			merged = append(merged, ClusterUAV{Cluster: cluster, UAVMetricsEntry: entry})
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Cluster != merged[j].Cluster {
+			return merged[i].Cluster < merged[j].Cluster
+		}
+		if merged[i].NodeName != merged[j].NodeName {
+			return merged[i].NodeName < merged[j].NodeName
+		}
+		return merged[i].Index < merged[j].Index
+	})
+	return merged
+}
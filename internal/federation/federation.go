@@ -0,0 +1,392 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+)
+
+// 通过API注册的对端保存在存储中（包含Token，默认在 storage.encryption.buckets 中）
+const (
+	Bucket   = "federation"
+	peersKey = "peers"
+)
+
+// 对端来源
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+// maxResponseBytes 对端响应大小上限
+const maxResponseBytes = 32 << 20
+
+// 注册错误
+var (
+	ErrNotFound = errors.New("federation: cluster not found")
+	ErrConflict = errors.New("federation: cluster name already in use")
+	ErrReadOnly = errors.New("federation: cluster is defined in configuration")
+	ErrInvalid  = errors.New("federation: invalid cluster")
+)
+
+// clusterNameRe 集群名称需为DNS标签
+var clusterNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Peer 对端监控实例
+type Peer struct {
+	Name         string    `json:"name"`
+	URL          string    `json:"url"`
+	Token        string    `json:"token,omitempty"`
+	Source       string    `json:"source"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// ClusterStatus 集群状态（不包含Token）
+type ClusterStatus struct {
+	Name         string     `json:"name"`
+	URL          string     `json:"url,omitempty"`
+	Local        bool       `json:"local"`
+	Source       string     `json:"source,omitempty"`
+	RegisteredAt *time.Time `json:"registered_at,omitempty"`
+	Reachable    bool       `json:"reachable"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
+	LatencyMs    float64    `json:"latency_ms,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+// ClusterResult 从单个集群获取的数据
+type ClusterResult struct {
+	Cluster string          `json:"cluster"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// peerState 对端最近一次请求的结果
+type peerState struct {
+	lastSeen  time.Time
+	latency   time.Duration
+	lastError string
+	checked   bool
+}
+
+// Registry 对端注册表，负责向对端并发请求数据
+type Registry struct {
+	local   string
+	timeout time.Duration
+	store   storage.Store
+	client  *http.Client
+	logger  *logrus.Entry
+
+	mu     sync.RWMutex
+	peers  map[string]*Peer
+	states map[string]*peerState
+}
+
+// NewRegistry 创建注册表：加载配置中的对端和此前通过API注册的对端；client为nil时使用默认客户端
+func NewRegistry(ctx context.Context, cfg config.FederationConfig, store storage.Store, client *http.Client) (*Registry, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	local := cfg.ClusterName
+	if local == "" {
+		local = "local"
+	}
+
+	r := &Registry{
+		local:   local,
+		timeout: timeout,
+		store:   store,
+		client:  &http.Client{Transport: tracing.Transport(client.Transport)},
+		logger:  logging.For("federation"),
+		peers:   make(map[string]*Peer),
+		states:  make(map[string]*peerState),
+	}
+
+	for name, peerCfg := range cfg.Peers {
+		peer := &Peer{Name: name, URL: peerCfg.URL, Token: peerCfg.Token, Source: SourceConfig}
+		if err := r.validate(peer); err != nil {
+			return nil, fmt.Errorf("invalid federation peer %s: %w", name, err)
+		}
+		r.peers[name] = peer
+	}
+
+	if err := r.loadStored(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LocalName 返回本集群名称
+func (r *Registry) LocalName() string {
+	return r.local
+}
+
+// loadStored 加载通过API注册的对端
+func (r *Registry) loadStored(ctx context.Context) error {
+	if r.store == nil {
+		return nil
+	}
+	data, err := r.store.Get(ctx, Bucket, peersKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load federation peers: %w", err)
+	}
+
+	var stored []*Peer
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to decode federation peers: %w", err)
+	}
+	for _, peer := range stored {
+		if _, exists := r.peers[peer.Name]; exists {
+			r.logger.Warnf("Ignoring stored federation peer %s: name is defined in configuration", peer.Name)
+			continue
+		}
+		peer.Source = SourceAPI
+		r.peers[peer.Name] = peer
+	}
+	return nil
+}
+
+// saveLocked 保存通过API注册的对端（调用方持有写锁）
+func (r *Registry) saveLocked(ctx context.Context) error {
+	if r.store == nil {
+		return nil
+	}
+	stored := make([]*Peer, 0, len(r.peers))
+	for _, peer := range r.peers {
+		if peer.Source == SourceAPI {
+			stored = append(stored, peer)
+		}
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Name < stored[j].Name })
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	if err := r.store.Put(ctx, Bucket, peersKey, data); err != nil {
+		return fmt.Errorf("failed to save federation peers: %w", err)
+	}
+	return nil
+}
+
+// validate 校验对端名称和地址，并规范化URL
+func (r *Registry) validate(peer *Peer) error {
+	if !clusterNameRe.MatchString(peer.Name) {
+		return fmt.Errorf("%w: name %q must be a lowercase DNS label", ErrInvalid, peer.Name)
+	}
+	if peer.Name == r.local {
+		return fmt.Errorf("%w: %q is the local cluster name", ErrConflict, peer.Name)
+	}
+	parsed, err := url.Parse(strings.TrimSpace(peer.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url %q must be an absolute http(s) URL", ErrInvalid, peer.URL)
+	}
+	peer.URL = strings.TrimRight(parsed.String(), "/")
+	return nil
+}
+
+// Register 注册（或更新）对端；配置文件中定义的对端不能通过API修改
+func (r *Registry) Register(ctx context.Context, peer Peer) (*ClusterStatus, error) {
+	peer.Source = SourceAPI
+	if err := r.validate(&peer); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.peers[peer.Name]; ok {
+		if existing.Source == SourceConfig {
+			return nil, fmt.Errorf("%w: %s", ErrReadOnly, peer.Name)
+		}
+		if peer.Token == "" {
+			peer.Token = existing.Token
+		}
+	}
+	peer.RegisteredAt = time.Now().UTC()
+
+	previous := r.peers[peer.Name]
+	r.peers[peer.Name] = &peer
+	delete(r.states, peer.Name)
+	if err := r.saveLocked(ctx); err != nil {
+		if previous != nil {
+			r.peers[peer.Name] = previous
+		} else {
+			delete(r.peers, peer.Name)
+		}
+		return nil, err
+	}
+
+	r.logger.Infof("Registered federation peer %s (%s)", peer.Name, peer.URL)
+	status := r.statusLocked(&peer)
+	return &status, nil
+}
+
+// Remove 删除通过API注册的对端
+func (r *Registry) Remove(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peer, ok := r.peers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if peer.Source == SourceConfig {
+		return fmt.Errorf("%w: %s", ErrReadOnly, name)
+	}
+
+	delete(r.peers, name)
+	if err := r.saveLocked(ctx); err != nil {
+		r.peers[name] = peer
+		return err
+	}
+	delete(r.states, name)
+	r.logger.Infof("Removed federation peer %s", name)
+	return nil
+}
+
+// Clusters 返回本集群和所有对端的状态（按名称排序，本集群在前）
+func (r *Registry) Clusters() []ClusterStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clusters := make([]ClusterStatus, 0, len(r.peers)+1)
+	clusters = append(clusters, ClusterStatus{Name: r.local, Local: true, Reachable: true})
+	for _, peer := range r.sortedPeersLocked() {
+		clusters = append(clusters, r.statusLocked(peer))
+	}
+	return clusters
+}
+
+// statusLocked 生成对端状态（调用方持有锁）
+func (r *Registry) statusLocked(peer *Peer) ClusterStatus {
+	status := ClusterStatus{Name: peer.Name, URL: peer.URL, Source: peer.Source}
+	if !peer.RegisteredAt.IsZero() {
+		registeredAt := peer.RegisteredAt
+		status.RegisteredAt = &registeredAt
+	}
+	if state, ok := r.states[peer.Name]; ok && state.checked {
+		status.Reachable = state.lastError == ""
+		status.LastError = state.lastError
+		status.LatencyMs = float64(state.latency.Microseconds()) / 1000
+		if !state.lastSeen.IsZero() {
+			lastSeen := state.lastSeen
+			status.LastSeen = &lastSeen
+		}
+	}
+	return status
+}
+
+// sortedPeersLocked 按名称排序的对端列表（调用方持有锁）
+func (r *Registry) sortedPeersLocked() []*Peer {
+	peers := make([]*Peer, 0, len(r.peers))
+	for _, peer := range r.peers {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	return peers
+}
+
+// Fetch 并发请求所有对端的同一个API路径，返回各对端响应中的 data 字段；
+// 单个对端失败不影响其他对端，错误记录在对应结果中
+func (r *Registry) Fetch(ctx context.Context, path string) []ClusterResult {
+	r.mu.RLock()
+	peers := r.sortedPeersLocked()
+	r.mu.RUnlock()
+
+	ctx, span := tracing.Start(ctx, "federation.Fetch")
+	defer span.End()
+
+	results, _ := workpool.Map(ctx, workpool.Options{Concurrency: len(peers), TaskTimeout: r.timeout}, peers,
+		func(peer *Peer) string { return peer.Name },
+		func(ctx context.Context, peer *Peer) (ClusterResult, error) {
+			start := time.Now()
+			data, err := r.get(ctx, peer, path)
+			r.observe(peer.Name, time.Since(start), err)
+
+			result := ClusterResult{Cluster: peer.Name, Data: data}
+			if err != nil {
+				r.logger.Warnf("Federation request to %s%s failed: %v", peer.Name, path, err)
+				result.Error = err.Error()
+			}
+			return result, nil
+		})
+	return results
+}
+
+// get 请求对端API并解析 {"status": ..., "data": ...} 格式的响应
+func (r *Registry) get(ctx context.Context, peer *Peer, path string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if peer.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+peer.Token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return envelope.Data, nil
+}
+
+// observe 记录对端最近一次请求的结果
+func (r *Registry) observe(name string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.peers[name]; !ok {
+		return
+	}
+	state, ok := r.states[name]
+	if !ok {
+		state = &peerState{}
+		r.states[name] = state
+	}
+	state.checked = true
+	state.latency = latency
+	if err != nil {
+		state.lastError = err.Error()
+		return
+	}
+	state.lastError = ""
+	state.lastSeen = time.Now().UTC()
+}
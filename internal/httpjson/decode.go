@@ -0,0 +1,124 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxBodyBytes 请求体默认大小上限
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// 错误码
+const (
+	CodeInvalidJSON          = "invalid_json"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeInvalidRequest       = "invalid_request"
+)
+
+// Error 请求体校验错误，包含应返回的HTTP状态码
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// BadRequest 创建400错误（用于业务字段校验）
+func BadRequest(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// Decode 严格解析JSON请求体：要求 Content-Type 为 application/json，限制请求体大小，
+// 拒绝未知字段和多余内容；返回的错误均为 *Error
+func Decode(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) error {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !strings.EqualFold(mediaType, "application/json") {
+		return &Error{
+			Status:  http.StatusUnsupportedMediaType,
+			Code:    CodeUnsupportedMediaType,
+			Message: fmt.Sprintf("Content-Type must be application/json, got %q", contentType),
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err, maxBytes)
+	}
+
+	// 请求体只能包含一个JSON值
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return decodeError(err, maxBytes)
+		}
+		return &Error{Status: http.StatusBadRequest, Code: CodeInvalidJSON, Message: "request body must contain a single JSON value"}
+	}
+	return nil
+}
+
+// decodeError 将解析错误转换为带状态码的错误
+func decodeError(err error, maxBytes int64) *Error {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		tooLargeErr *http.MaxBytesError
+	)
+	invalidError := func(msg string) *Error {
+		return &Error{Status: http.StatusBadRequest, Code: CodeInvalidJSON, Message: msg}
+	}
+
+	switch {
+	case errors.As(err, &tooLargeErr):
+		return &Error{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    CodeBodyTooLarge,
+			Message: fmt.Sprintf("request body must not exceed %d bytes", maxBytes),
+		}
+	case errors.As(err, &syntaxErr):
+		return invalidError(fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return invalidError("malformed JSON")
+	case errors.As(err, &typeErr):
+		return invalidError(fmt.Sprintf("invalid value for field %q (expected %s)", typeErr.Field, typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return invalidError(fmt.Sprintf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field ")))
+	case errors.Is(err, io.EOF):
+		return invalidError("request body must not be empty")
+	default:
+		return invalidError(err.Error())
+	}
+}
+
+// WriteError 以统一的JSON格式输出错误；非 *Error 的错误按500处理
+func WriteError(w http.ResponseWriter, err error) {
+	var reqErr *Error
+	if !errors.As(err, &reqErr) {
+		reqErr = &Error{Status: http.StatusInternalServerError, Code: "internal_error", Message: err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reqErr.Status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "error",
+		"error":     reqErr,
+		"timestamp": time.Now().UTC(),
+	})
+}
@@ -0,0 +1,135 @@
+package httpjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// streamBufferSize 流式写出的缓冲区大小
+const streamBufferSize = 32 << 10
+
+// Field 流式JSON对象的一个字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// streamer 可以分段写出自身的值（大map不必先整体编码到内存）
+type streamer interface {
+	streamJSON(enc *streamEncoder) error
+}
+
+// Object 按给定顺序写出字段的JSON对象，可作为其他字段的值嵌套使用
+func Object(fields ...Field) interface{} {
+	return streamObject(fields)
+}
+
+type streamObject []Field
+
+func (o streamObject) streamJSON(enc *streamEncoder) error {
+	enc.out.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			enc.out.WriteByte(',')
+		}
+		if err := enc.value(field.Key); err != nil {
+			return err
+		}
+		enc.out.WriteByte(':')
+		if err := enc.value(field.Value); err != nil {
+			return err
+		}
+	}
+	enc.out.WriteByte('}')
+	return nil
+}
+
+// Map 包装大map，逐个条目编码写出（键排序与 encoding/json 一致），
+// 输出内容与直接编码map相同，但峰值内存只与单个条目大小相关
+func Map[T any](m map[string]T) interface{} {
+	return streamMap[T](m)
+}
+
+type streamMap[T any] map[string]T
+
+func (m streamMap[T]) streamJSON(enc *streamEncoder) error {
+	if m == nil {
+		_, err := enc.out.WriteString("null")
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	enc.out.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			enc.out.WriteByte(',')
+		}
+		if err := enc.value(key); err != nil {
+			return err
+		}
+		enc.out.WriteByte(':')
+		if err := enc.value(m[key]); err != nil {
+			return err
+		}
+	}
+	enc.out.WriteByte('}')
+	return nil
+}
+
+// streamEncoder 复用的编码状态：单个值先编码到 scratch，再写入带缓冲的输出
+type streamEncoder struct {
+	out     *bufio.Writer
+	scratch bytes.Buffer
+	encoder *json.Encoder
+}
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		enc := &streamEncoder{out: bufio.NewWriterSize(nil, streamBufferSize)}
+		enc.encoder = json.NewEncoder(&enc.scratch)
+		return enc
+	},
+}
+
+// value 写出单个值
+func (enc *streamEncoder) value(v interface{}) error {
+	if s, ok := v.(streamer); ok {
+		return s.streamJSON(enc)
+	}
+	enc.scratch.Reset()
+	if err := enc.encoder.Encode(v); err != nil {
+		return err
+	}
+	// 去掉 Encode 追加的换行
+	_, err := enc.out.Write(bytes.TrimSuffix(enc.scratch.Bytes(), []byte("\n")))
+	return err
+}
+
+// WriteObject 流式写出JSON对象（末尾换行，与 json.Encoder 一致）。
+// 字段值可以是任意可编码的值，或 Object / Map 返回的流式值
+func WriteObject(w io.Writer, fields ...Field) error {
+	enc := encoderPool.Get().(*streamEncoder)
+	enc.out.Reset(w)
+	defer func() {
+		enc.out.Reset(nil)
+		// 避免个别超大条目的缓冲区长期占用内存
+		if enc.scratch.Cap() > 1<<20 {
+			enc.scratch = bytes.Buffer{}
+		}
+		encoderPool.Put(enc)
+	}()
+
+	if err := streamObject(fields).streamJSON(enc); err != nil {
+		return err
+	}
+	enc.out.WriteByte('\n')
+	return enc.out.Flush()
+}
@@ -0,0 +1,161 @@
+package httpjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type testPod struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	CPUUsage  int64             `json:"cpu_usage"`
+	Rate      float64           `json:"rate"`
+	Ready     bool              `json:"ready"`
+	Started   time.Time         `json:"started"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Owner     *string           `json:"owner"`
+}
+
+// testPods 生成 count 个条目的map，用于基准测试
+func testPods(count int) map[string]*testPod {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	pods := make(map[string]*testPod, count)
+	for i := 0; i < count; i++ {
+		namespace := fmt.Sprintf("ns-%02d", i%20)
+		name := fmt.Sprintf("app-%05d", i)
+		pods[namespace+"/"+name] = &testPod{
+			Name:      name,
+			Namespace: namespace,
+			CPUUsage:  int64(100 + i%400),
+			Rate:      float64(i%1000) / 7,
+			Ready:     i%10 != 0,
+			Started:   started.Add(time.Duration(i) * time.Second),
+			Labels:    map[string]string{"app": name, "tier": "web"},
+		}
+	}
+	return pods
+}
+
+// encodeJSON 用 encoding/json 编码，作为流式编码的参照
+func encodeJSON(t testing.TB, v interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatalf("json.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeObject(t testing.TB, fields ...Field) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteObject(&buf, fields...); err != nil {
+		t.Fatalf("WriteObject() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteObjectMatchesEncodingJSON(t *testing.T) {
+	owner := "deploy/<web>&co"
+	pods := testPods(50)
+	pods["default/web- \"quoted\""] = &testPod{Name: "<script>", Owner: &owner, Rate: 1e21}
+	pods["ns-00/nil"] = nil
+	now := time.Now()
+
+	type response struct {
+		Status    string              `json:"status"`
+		Data      map[string]*testPod `json:"data"`
+		Count     int                 `json:"count"`
+		Timestamp time.Time           `json:"timestamp"`
+	}
+	type nested struct {
+		Status string `json:"status"`
+		Data   struct {
+			Pods   map[string]*testPod `json:"pods"`
+			Counts map[string]int      `json:"counts"`
+			Empty  map[string]string   `json:"empty"`
+			Nil    map[string]string   `json:"nil"`
+		} `json:"data"`
+	}
+	var want nested
+	want.Status = "success"
+	want.Data.Pods = pods
+	want.Data.Counts = map[string]int{"b": 2, "a": 1, "é": 3, "A": 0}
+	want.Data.Empty = map[string]string{}
+
+	tests := []struct {
+		name   string
+		want   interface{}
+		fields []Field
+	}{
+		{
+			name: "map",
+			want: response{Status: "success", Data: pods, Count: len(pods), Timestamp: now},
+			fields: []Field{
+				{Key: "status", Value: "success"},
+				{Key: "data", Value: Map(pods)},
+				{Key: "count", Value: len(pods)},
+				{Key: "timestamp", Value: now},
+			},
+		},
+		{
+			name: "nested objects and empty maps",
+			want: want,
+			fields: []Field{
+				{Key: "status", Value: "success"},
+				{Key: "data", Value: Object(
+					Field{Key: "pods", Value: Map(pods)},
+					Field{Key: "counts", Value: Map(want.Data.Counts)},
+					Field{Key: "empty", Value: Map(map[string]string{})},
+					Field{Key: "nil", Value: Map(map[string]string(nil))},
+				)},
+			},
+		},
+		{
+			name:   "empty object",
+			want:   struct{}{},
+			fields: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := encodeJSON(t, tt.want)
+			if got := writeObject(t, tt.fields...); !bytes.Equal(got, want) {
+				t.Errorf("WriteObject() output differs from encoding/json\n got: %.300s\nwant: %.300s", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteObjectReturnsEncodeError(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteObject(&buf, Field{Key: "data", Value: Map(map[string]interface{}{"bad": func() {}})})
+	if err == nil {
+		t.Fatal("WriteObject() with an unsupported value should fail")
+	}
+}
+
+// 5000个条目的map：流式编码与 encoding/json 整体编码对比
+func BenchmarkWriteObjectMap(b *testing.B) {
+	pods := testPods(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := WriteObject(io.Discard, Field{Key: "status", Value: "success"}, Field{Key: "data", Value: Map(pods)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeMap(b *testing.B) {
+	pods := testPods(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := json.NewEncoder(io.Discard).Encode(map[string]interface{}{"status": "success", "data": pods}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package incidents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+const (
+	maxIncidentEvents = 50 // 每个故障保留的事件数
+	keepFirstEvents   = 10 // 超出时保留最早的事件，其余保留最近的
+	maxSignalTargets  = 10 // 每个故障检查指标的Pod/节点数
+	baselineWindow    = 30 * time.Minute
+	spikeDelta        = 20.0 // 使用率比故障前平均值高出的百分点
+)
+
+// correlate 将新事件归入进行中的故障：事件涉及的Pod、工作负载或节点与故障相同，
+// 且与故障最近一次事件的间隔不超过窗口；同时匹配多个故障时将它们合并。返回新归入的事件数
+func (e *Engine) correlate(list []*models.EventInfo, podNodes map[string]string) int {
+	added := 0
+	for _, event := range list {
+		id := eventID(event)
+		if _, ok := e.seen[id]; ok {
+			continue
+		}
+		e.seen[id] = event.Timestamp
+
+		keys, lookup := eventKeys(event, podNodes)
+		var matched []*tracked
+		for _, t := range e.open {
+			if event.Timestamp.Sub(t.incident.LastSeen) > e.window || t.incident.StartedAt.Sub(event.Timestamp) > e.window {
+				continue
+			}
+			for _, key := range lookup {
+				if t.keys[key] {
+					matched = append(matched, t)
+					break
+				}
+			}
+		}
+
+		var target *tracked
+		if len(matched) == 0 {
+			target = &tracked{incident: newIncident(event), keys: make(map[string]bool)}
+			e.open[target.incident.ID] = target
+		} else {
+			sort.Slice(matched, func(i, j int) bool { return matched[i].incident.StartedAt.Before(matched[j].incident.StartedAt) })
+			target = matched[0]
+			for _, other := range matched[1:] {
+				target.merge(other)
+				delete(e.open, other.incident.ID)
+			}
+		}
+
+		for _, key := range keys {
+			target.keys[key] = true
+		}
+		target.add(event, podNodes)
+		target.changed = true
+		added++
+	}
+	return added
+}
+
+// resolve 将超过窗口未再出现新事件的故障标记为已恢复，返回这些故障
+func (e *Engine) resolve(now time.Time) []*Incident {
+	var resolved []*Incident
+	for id, t := range e.open {
+		if now.Sub(t.incident.LastSeen) <= e.window {
+			continue
+		}
+		resolvedAt := now
+		t.incident.Status = StatusResolved
+		t.incident.ResolvedAt = &resolvedAt
+		resolved = append(resolved, t.incident)
+		delete(e.open, id)
+	}
+	return resolved
+}
+
+// newIncident 以一条事件开始新的故障
+func newIncident(event *models.EventInfo) *Incident {
+	return &Incident{
+		ID:         newID(event.Timestamp),
+		Status:     StatusOpen,
+		Categories: make(map[string]int),
+		Namespaces: []string{},
+		Workloads:  []string{},
+		Pods:       []string{},
+		Nodes:      []string{},
+		Events:     []*models.EventInfo{},
+		Metrics:    []MetricSignal{},
+		StartedAt:  event.Timestamp,
+		LastSeen:   event.Timestamp,
+	}
+}
+
+// add 将事件加入故障并更新涉及的对象
+func (t *tracked) add(event *models.EventInfo, podNodes map[string]string) {
+	incident := t.incident
+	incident.EventCount++
+	incident.Categories[category(event)]++
+	if event.Timestamp.Before(incident.StartedAt) {
+		incident.StartedAt = event.Timestamp
+	}
+	if event.Timestamp.After(incident.LastSeen) {
+		incident.LastSeen = event.Timestamp
+	}
+
+	if event.Namespace != "" {
+		incident.Namespaces = appendUnique(incident.Namespaces, event.Namespace)
+	}
+	switch event.ObjectKind {
+	case "Pod":
+		pod := event.Namespace + "/" + event.ObjectName
+		incident.Pods = appendUnique(incident.Pods, pod)
+		incident.Workloads = appendUnique(incident.Workloads, event.Namespace+"/"+workloadOf(event.ObjectName))
+		if node := podNodes[pod]; node != "" {
+			incident.Nodes = appendUnique(incident.Nodes, node)
+		}
+	case "Node":
+		incident.Nodes = appendUnique(incident.Nodes, event.ObjectName)
+	default:
+		if event.ObjectName != "" {
+			incident.Workloads = appendUnique(incident.Workloads, event.Namespace+"/"+workloadOf(event.ObjectName))
+		}
+	}
+
+	if len(incident.Events) >= maxIncidentEvents {
+		incident.Events = append(incident.Events[:keepFirstEvents], incident.Events[keepFirstEvents+1:]...)
+	}
+	incident.Events = append(incident.Events, event)
+	sort.SliceStable(incident.Events, func(i, j int) bool { return incident.Events[i].Timestamp.Before(incident.Events[j].Timestamp) })
+	incident.Title = ruleTitle(incident)
+	incident.Severity = severity(incident)
+}
+
+// merge 将另一个故障并入当前故障
+func (t *tracked) merge(other *tracked) {
+	incident, o := t.incident, other.incident
+	for key := range other.keys {
+		t.keys[key] = true
+	}
+	for c, n := range o.Categories {
+		incident.Categories[c] += n
+	}
+	for _, ns := range o.Namespaces {
+		incident.Namespaces = appendUnique(incident.Namespaces, ns)
+	}
+	for _, w := range o.Workloads {
+		incident.Workloads = appendUnique(incident.Workloads, w)
+	}
+	for _, pod := range o.Pods {
+		incident.Pods = appendUnique(incident.Pods, pod)
+	}
+	for _, node := range o.Nodes {
+		incident.Nodes = appendUnique(incident.Nodes, node)
+	}
+	incident.EventCount += o.EventCount
+	if o.StartedAt.Before(incident.StartedAt) {
+		incident.StartedAt = o.StartedAt
+	}
+	if o.LastSeen.After(incident.LastSeen) {
+		incident.LastSeen = o.LastSeen
+	}
+
+	merged := append(incident.Events, o.Events...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+	if len(merged) > maxIncidentEvents {
+		merged = append(merged[:keepFirstEvents], merged[len(merged)-(maxIncidentEvents-keepFirstEvents):]...)
+	}
+	incident.Events = merged
+	// 合并后的范围变化，原有描述不再适用
+	incident.Description = nil
+	incident.Title = ruleTitle(incident)
+	incident.Severity = severity(incident)
+}
+
+// eventKeys 事件的关联键和匹配时使用的键。
+// Pod事件关联到Pod和工作负载，并可与所在节点的节点级事件匹配；节点事件关联到节点
+func eventKeys(event *models.EventInfo, podNodes map[string]string) (keys, lookup []string) {
+	switch event.ObjectKind {
+	case "Pod":
+		pod := event.Namespace + "/" + event.ObjectName
+		keys = []string{"pod:" + pod, "workload:" + event.Namespace + "/" + workloadOf(event.ObjectName)}
+		lookup = keys
+		if node := podNodes[pod]; node != "" {
+			lookup = append(lookup, "node:"+node)
+		}
+	case "Node":
+		keys = []string{"node:" + event.ObjectName}
+		lookup = keys
+	default:
+		if event.ObjectName != "" {
+			keys = []string{"workload:" + event.Namespace + "/" + workloadOf(event.ObjectName)}
+			lookup = keys
+		}
+	}
+	return keys, lookup
+}
+
+// workloadOf 由Pod名推断所属工作负载：去掉随机后缀、ReplicaSet哈希或StatefulSet序号
+func workloadOf(name string) string {
+	parts := strings.Split(name, "-")
+	if len(parts) < 2 {
+		return name
+	}
+	last := parts[len(parts)-1]
+	switch {
+	case isDigits(last):
+		// StatefulSet: web-0
+		return strings.Join(parts[:len(parts)-1], "-")
+	case len(last) == 5 && isAlnum(last):
+		// Deployment/DaemonSet/Job: api-7d9f8c6b5-x2x4z, fluentd-x7k2p
+		parts = parts[:len(parts)-1]
+		if n := len(parts); n > 1 && len(parts[n-1]) >= 8 && len(parts[n-1]) <= 10 && isAlnum(parts[n-1]) && !isLetters(parts[n-1]) {
+			parts = parts[:n-1]
+		}
+		return strings.Join(parts, "-")
+	case len(last) >= 8 && len(last) <= 10 && isAlnum(last) && !isLetters(last):
+		// ReplicaSet: api-7d9f8c6b5
+		return strings.Join(parts[:len(parts)-1], "-")
+	}
+	return name
+}
+
+// category 事件分类，BackOff 按消息区分容器重启和镜像拉取
+func category(event *models.EventInfo) string {
+	switch event.Reason {
+	case "BackOff":
+		if strings.Contains(strings.ToLower(event.Message), "pulling image") {
+			return "ImagePullBackOff"
+		}
+		return "CrashLoopBackOff"
+	case "OOMKilling", "OOMKilled":
+		return "OOMKilled"
+	}
+	return event.Reason
+}
+
+// ruleTitle 按事件分类和涉及的对象生成故障名称，已有LLM描述时使用其名称
+func ruleTitle(incident *Incident) string {
+	if incident.Description != nil && incident.Description.Title != "" {
+		return incident.Description.Title
+	}
+
+	categories := make([]string, 0, len(incident.Categories))
+	for c := range incident.Categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if incident.Categories[categories[i]] != incident.Categories[categories[j]] {
+			return incident.Categories[categories[i]] > incident.Categories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	if len(categories) > 2 {
+		categories = categories[:2]
+	}
+
+	subject := ""
+	switch {
+	case len(incident.Workloads) > 0:
+		subject = incident.Workloads[0]
+		if len(incident.Workloads) > 1 {
+			subject += fmt.Sprintf(" and %d more", len(incident.Workloads)-1)
+		}
+	case len(incident.Nodes) > 0:
+		subject = "node " + incident.Nodes[0]
+	}
+
+	title := strings.Join(categories, " + ")
+	if subject != "" {
+		title += " in " + subject
+	}
+	if len(incident.Pods) > 1 {
+		title += fmt.Sprintf(" (%d pods)", len(incident.Pods))
+	}
+	return title
+}
+
+// severity 节点级故障、OOM、驱逐、影响多个Pod或伴随节点资源突增时为 critical
+func severity(incident *Incident) string {
+	for _, c := range []string{"OOMKilled", "NodeNotReady", "Evicted"} {
+		if incident.Categories[c] > 0 {
+			return "critical"
+		}
+	}
+	if len(incident.Pods) >= 5 {
+		return "critical"
+	}
+	for _, signal := range incident.Metrics {
+		if signal.Target == metrics.TargetNode {
+			return "critical"
+		}
+	}
+	return "warning"
+}
+
+// metricSignals 查询故障期间相关Pod和节点的CPU/内存使用率，与故障前的平均值比较；Pod同时检查重启次数的增加
+func (e *Engine) metricSignals(ctx context.Context, incident *Incident) []MetricSignal {
+	signals := []MetricSignal{}
+	if e.store == nil {
+		return signals
+	}
+
+	from := incident.StartedAt.Add(-2 * time.Minute)
+	to := incident.LastSeen.Add(time.Minute)
+
+	check := func(target, key, sampleKey string) {
+		for _, metric := range []string{"cpu_usage_rate", "memory_usage_rate"} {
+			during, err := metrics.Aggregate(ctx, e.store, metrics.AggregateQuery{
+				Target: target, Key: sampleKey, Metric: metric, Aggregations: []string{"max"}, From: from, To: to,
+			})
+			if err != nil || during.Samples == 0 {
+				continue
+			}
+			before, err := metrics.Aggregate(ctx, e.store, metrics.AggregateQuery{
+				Target: target, Key: sampleKey, Metric: metric, Aggregations: []string{"avg"}, From: from.Add(-baselineWindow), To: from,
+			})
+			baseline := 0.0
+			if err == nil && before.Samples > 0 {
+				baseline = before.Summary["avg"]
+			}
+			max := during.Summary["max"]
+			if max >= e.cfg.SpikeThreshold || (before != nil && before.Samples > 0 && max-baseline >= spikeDelta) {
+				signals = append(signals, MetricSignal{Target: target, Key: key, Metric: metric, Max: max, Baseline: baseline})
+			}
+		}
+
+		if target != metrics.TargetPod {
+			return
+		}
+		restarts, err := metrics.Aggregate(ctx, e.store, metrics.AggregateQuery{
+			Target: target, Key: sampleKey, Metric: "restarts", Aggregations: []string{"min", "max"}, From: from, To: to,
+		})
+		if err == nil && restarts.Samples > 0 && restarts.Summary["max"] > restarts.Summary["min"] {
+			signals = append(signals, MetricSignal{Target: target, Key: key, Metric: "restarts", Max: restarts.Summary["max"], Baseline: restarts.Summary["min"]})
+		}
+	}
+
+	for i, pod := range incident.Pods {
+		if i == maxSignalTargets {
+			break
+		}
+		namespace, name, _ := strings.Cut(pod, "/")
+		check(metrics.TargetPod, pod, metrics.SampleKey(metrics.TargetPod, namespace, name))
+	}
+	for i, node := range incident.Nodes {
+		if i == maxSignalTargets {
+			break
+		}
+		check(metrics.TargetNode, node, metrics.SampleKey(metrics.TargetNode, node))
+	}
+	return signals
+}
+
+// eventID 事件去重标识，与事件历史一致
+func eventID(event *models.EventInfo) string {
+	if event.UID == "" {
+		return fmt.Sprintf("%s/%s/%s/%d", event.Namespace, event.ObjectName, event.Reason, event.Timestamp.UnixNano())
+	}
+	return fmt.Sprintf("%s/%d", event.UID, event.Count)
+}
+
+// newID 生成故障ID
+func newID(start time.Time) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("inc-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("inc-%s-%s", start.UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
+
+func appendUnique(list []string, value string) []string {
+	if contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
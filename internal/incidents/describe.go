@@ -0,0 +1,86 @@
+package incidents
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+)
+
+// TypeIncident 故障描述在 llm.routing 中使用的分析类型
+const TypeIncident = "incident"
+
+// describeEvents 交给LLM的事件数（最近的）
+const describeEvents = 20
+
+// needsDescription 尚未描述，或描述后事件数已翻倍
+func needsDescription(incident *Incident) bool {
+	return incident.Description == nil || incident.EventCount >= 2*incident.Description.EventCount
+}
+
+// describe 由LLM为故障生成名称和描述；失败时记录在 DescribeError 中，保留规则生成的名称
+func (e *Engine) describe(ctx context.Context, incident *Incident, profile string) error {
+	client, err := e.service.Client(TypeIncident, profile)
+	if err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	view := incident.clone()
+	e.mu.RUnlock()
+	if len(view.Events) > describeEvents {
+		view.Events = view.Events[len(view.Events)-describeEvents:]
+	}
+	view.Description = nil
+	view.DescribeError = ""
+
+	prompt, err := e.templates.Render(prompts.IncidentSummary, map[string]interface{}{"incident": view})
+	if err != nil {
+		return err
+	}
+
+	var output struct {
+		Title         string   `json:"title"`
+		Summary       string   `json:"summary"`
+		ProbableCause string   `json:"probable_cause"`
+		Severity      string   `json:"severity"`
+		NextSteps     []string `json:"next_steps"`
+	}
+	resp, err := client.Chat(ctx, llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	})
+	if err == nil {
+		err = llm.DecodeJSON(resp.Content, &output)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		incident.DescribeError = err.Error()
+		return err
+	}
+
+	severity := strings.ToLower(strings.TrimSpace(output.Severity))
+	switch severity {
+	case "critical", "warning", "info":
+	default:
+		severity = ""
+	}
+	incident.Description = &Description{
+		Title:         strings.TrimSpace(output.Title),
+		Summary:       output.Summary,
+		ProbableCause: output.ProbableCause,
+		Severity:      severity,
+		NextSteps:     output.NextSteps,
+		EventCount:    view.EventCount,
+		Model:         resp.Model,
+		Prompt:        prompt.Template,
+		GeneratedAt:   time.Now().UTC(),
+	}
+	incident.DescribeError = ""
+	incident.Title = ruleTitle(incident)
+	return nil
+}
@@ -0,0 +1,537 @@
+package incidents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// Collection 已恢复的故障在存储中的集合名（同时按ID保存，便于读取单个故障）
+const Collection = "incidents"
+
+// 故障状态
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+)
+
+const (
+	stateBucket = "state"
+	stateKey    = "incidents"
+)
+
+// ErrNotFound 故障不存在
+var ErrNotFound = errors.New("incident not found")
+
+// MetricSignal 故障期间相关Pod或节点的指标异常
+type MetricSignal struct {
+	Target   string  `json:"target"` // node, pod
+	Key      string  `json:"key"`    // 节点名或 namespace/name
+	Metric   string  `json:"metric"` // cpu_usage_rate, memory_usage_rate, restarts
+	Max      float64 `json:"max"`
+	Baseline float64 `json:"baseline"` // 故障前的平均值（restarts 为故障期间的最小值）
+}
+
+// Description LLM给出的故障名称和描述
+type Description struct {
+	Title         string    `json:"title"`
+	Summary       string    `json:"summary"`
+	ProbableCause string    `json:"probable_cause"`
+	Severity      string    `json:"severity,omitempty"`
+	NextSteps     []string  `json:"next_steps,omitempty"`
+	EventCount    int       `json:"event_count"` // 生成描述时的事件数
+	Model         string    `json:"model"`
+	Prompt        string    `json:"prompt"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// Incident 一组时间相近、涉及相同Pod/工作负载/节点的Warning事件
+type Incident struct {
+	ID         string              `json:"id"`
+	Status     string              `json:"status"`
+	Title      string              `json:"title"`
+	Severity   string              `json:"severity"`   // critical, warning
+	Categories map[string]int      `json:"categories"` // CrashLoopBackOff、FailedScheduling、OOMKilled 等及其事件数
+	Namespaces []string            `json:"namespaces"`
+	Workloads  []string            `json:"workloads"` // namespace/name，由Pod名推断
+	Pods       []string            `json:"pods"`
+	Nodes      []string            `json:"nodes"`
+	EventCount int                 `json:"event_count"`
+	Events     []*models.EventInfo `json:"events"` // 最早和最近的部分事件
+	Metrics    []MetricSignal      `json:"metrics"`
+	StartedAt  time.Time           `json:"started_at"`
+	LastSeen   time.Time           `json:"last_seen"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty"`
+
+	Description   *Description `json:"description,omitempty"`
+	DescribeError string       `json:"describe_error,omitempty"`
+}
+
+// Filter 故障查询条件
+type Filter struct {
+	Status    string
+	Namespace string
+	Pod       string // namespace/name
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// Matches 判断故障是否满足非时间类过滤条件
+func (f Filter) Matches(incident *Incident) bool {
+	if f.Status != "" && !strings.EqualFold(incident.Status, f.Status) {
+		return false
+	}
+	if f.Namespace != "" && !contains(incident.Namespaces, f.Namespace) {
+		return false
+	}
+	if f.Pod != "" && !contains(incident.Pods, f.Pod) {
+		return false
+	}
+	return true
+}
+
+// Engine 定期从事件历史中关联相关事件，生成和更新故障
+type Engine struct {
+	cfg       config.IncidentsConfig
+	history   *events.History
+	store     storage.Store
+	metrics   *metrics.Manager // 可为空，用于查找Pod所在节点
+	service   *llm.Service
+	templates *prompts.Registry
+	window    time.Duration
+	interval  time.Duration
+	logger    *logrus.Entry
+
+	runMu sync.Mutex // 串行执行关联周期和描述生成
+
+	mu     sync.RWMutex
+	open   map[string]*tracked
+	seen   map[string]time.Time // 已处理的事件（查询窗口有重叠）
+	cursor time.Time
+}
+
+// tracked 进行中的故障及其关联键
+type tracked struct {
+	incident *Incident
+	keys     map[string]bool
+	changed  bool
+}
+
+// persistedState 持久化的进行中故障
+type persistedState struct {
+	Cursor    time.Time            `json:"cursor"`
+	Seen      map[string]time.Time `json:"seen"`
+	Incidents []persistedIncident  `json:"incidents"`
+}
+
+type persistedIncident struct {
+	Incident *Incident `json:"incident"`
+	Keys     []string  `json:"keys"`
+}
+
+// NewEngine 创建故障关联引擎
+func NewEngine(cfg config.IncidentsConfig, history *events.History, store storage.Store, metricsManager *metrics.Manager, service *llm.Service, templates *prompts.Registry) *Engine {
+	window := time.Duration(cfg.Window) * time.Second
+	if window <= 0 {
+		window = 10 * time.Minute
+	}
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if cfg.SpikeThreshold <= 0 {
+		cfg.SpikeThreshold = 90
+	}
+
+	return &Engine{
+		cfg:       cfg,
+		history:   history,
+		store:     store,
+		metrics:   metricsManager,
+		service:   service,
+		templates: templates,
+		window:    window,
+		interval:  interval,
+		logger:    logging.For("incidents"),
+		open:      make(map[string]*tracked),
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Start 恢复进行中的故障并启动关联循环
+func (e *Engine) Start(ctx context.Context) {
+	if err := e.restore(ctx); err != nil {
+		e.logger.Warnf("Failed to restore open incidents: %v", err)
+	}
+	e.logger.Infof("Incident engine started (interval: %s, window: %s, reasons: %v)", e.interval, e.window, e.cfg.Reasons)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			e.logger.Warnf("Incident correlation failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一次关联：归并新事件，标记超过窗口未再出现的故障为已恢复
+func (e *Engine) RunOnce(ctx context.Context) error {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	now := time.Now().UTC()
+	e.mu.RLock()
+	from := e.cursor.Add(-e.interval)
+	e.mu.RUnlock()
+	if from.IsZero() || from.Before(now.Add(-e.window)) {
+		from = now.Add(-e.window)
+	}
+
+	list, err := e.history.Query(ctx, events.Query{From: from, Type: "Warning", Reasons: e.cfg.Reasons})
+	if err != nil {
+		return err
+	}
+	// 被分类为噪声的事件（如通过 monitoring.event_filter.overrides）不参与关联
+	relevant := list[:0]
+	for _, event := range list {
+		if event.Class != events.ClassNoise {
+			relevant = append(relevant, event)
+		}
+	}
+	list = relevant
+	podNodes := e.podNodes()
+
+	e.mu.Lock()
+	added := e.correlate(list, podNodes)
+	resolved := e.resolve(now)
+	e.cursor = now
+	for id, ts := range e.seen {
+		if ts.Before(now.Add(-e.window - e.interval)) {
+			delete(e.seen, id)
+		}
+	}
+	var changed []*Incident
+	for _, t := range e.open {
+		if t.changed {
+			changed = append(changed, t.incident)
+		}
+	}
+	for _, incident := range resolved {
+		changed = append(changed, incident)
+	}
+	e.mu.Unlock()
+
+	// 指标和描述在锁外查询，结果写回时再加锁
+	for _, incident := range changed {
+		signals := e.metricSignals(ctx, incident)
+		e.mu.Lock()
+		incident.Metrics = signals
+		incident.Severity = severity(incident)
+		e.mu.Unlock()
+	}
+	if e.cfg.Describe {
+		for _, incident := range changed {
+			if needsDescription(incident) {
+				if err := e.describe(ctx, incident, ""); err != nil && !errors.Is(err, llm.ErrNotConfigured) && !errors.Is(err, llm.ErrBudgetExceeded) {
+					e.logger.Warnf("Failed to describe incident %s: %v", incident.ID, err)
+				}
+			}
+		}
+	}
+
+	var errs []error
+	for _, incident := range resolved {
+		if err := e.save(ctx, incident); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(changed) > 0 {
+		if err := e.persist(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	e.mu.Lock()
+	for _, t := range e.open {
+		t.changed = false
+	}
+	e.mu.Unlock()
+
+	if added > 0 || len(resolved) > 0 {
+		e.logger.Infof("Correlated %d events: %d open incidents, %d resolved", added, e.OpenCount(), len(resolved))
+	}
+	return errors.Join(errs...)
+}
+
+// OpenCount 进行中的故障数
+func (e *Engine) OpenCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.open)
+}
+
+// List 查询故障（按开始时间降序）：进行中的故障来自内存，已恢复的来自存储
+func (e *Engine) List(ctx context.Context, filter Filter) ([]*Incident, error) {
+	result := make([]*Incident, 0)
+
+	if filter.Status == "" || strings.EqualFold(filter.Status, StatusOpen) {
+		e.mu.RLock()
+		for _, t := range e.open {
+			if filter.Matches(t.incident) && inRange(t.incident, filter.From, filter.To) {
+				result = append(result, t.incident.clone())
+			}
+		}
+		e.mu.RUnlock()
+	}
+
+	if filter.Status == "" || strings.EqualFold(filter.Status, StatusResolved) {
+		records, err := e.store.List(ctx, Collection, storage.Query{To: filter.To})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list incidents: %w", err)
+		}
+		latest := make(map[string]*Incident, len(records))
+		for _, record := range records {
+			var incident Incident
+			if err := json.Unmarshal(record.Data, &incident); err != nil {
+				continue
+			}
+			latest[incident.ID] = &incident
+		}
+		for _, incident := range latest {
+			if filter.Matches(incident) && inRange(incident, filter.From, filter.To) {
+				result = append(result, incident)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.After(result[j].StartedAt) })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// Get 根据ID获取故障
+func (e *Engine) Get(ctx context.Context, id string) (*Incident, error) {
+	e.mu.RLock()
+	if t, ok := e.open[id]; ok {
+		incident := t.incident.clone()
+		e.mu.RUnlock()
+		return incident, nil
+	}
+	e.mu.RUnlock()
+
+	data, err := e.store.Get(ctx, Collection, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var incident Incident
+	if err := json.Unmarshal(data, &incident); err != nil {
+		return nil, fmt.Errorf("failed to decode incident %s: %w", id, err)
+	}
+	return &incident, nil
+}
+
+// Describe 由LLM为故障生成名称和描述，profile 为空时按 llm.routing.incident 选择配置
+func (e *Engine) Describe(ctx context.Context, id, profile string) (*Incident, error) {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	e.mu.RLock()
+	t, open := e.open[id]
+	e.mu.RUnlock()
+
+	if open {
+		if err := e.describe(ctx, t.incident, profile); err != nil {
+			return nil, err
+		}
+		if err := e.persist(ctx); err != nil {
+			e.logger.Warnf("Failed to persist open incidents: %v", err)
+		}
+		return e.Get(ctx, id)
+	}
+
+	incident, err := e.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.describe(ctx, incident, profile); err != nil {
+		return nil, err
+	}
+	if err := e.save(ctx, incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// save 保存已恢复的故障；再次保存时追加新记录，查询时以最后一条为准
+func (e *Engine) save(ctx context.Context, incident *Incident) error {
+	e.mu.RLock()
+	data, err := json.Marshal(incident)
+	e.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident: %w", err)
+	}
+
+	if err := e.store.Put(ctx, Collection, incident.ID, data); err != nil {
+		return fmt.Errorf("failed to store incident %s: %w", incident.ID, err)
+	}
+	key := ""
+	if len(incident.Namespaces) > 0 {
+		key = incident.Namespaces[0]
+	}
+	if err := e.store.Append(ctx, Collection, &storage.Record{
+		ID:        incident.ID,
+		Key:       key,
+		Timestamp: incident.StartedAt,
+		Data:      data,
+	}); err != nil {
+		return fmt.Errorf("failed to index incident %s: %w", incident.ID, err)
+	}
+	return nil
+}
+
+// persist 保存进行中的故障，重启后继续关联
+func (e *Engine) persist(ctx context.Context) error {
+	e.mu.RLock()
+	state := persistedState{Cursor: e.cursor, Seen: e.seen, Incidents: make([]persistedIncident, 0, len(e.open))}
+	for _, t := range e.open {
+		keys := make([]string, 0, len(t.keys))
+		for key := range t.keys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		state.Incidents = append(state.Incidents, persistedIncident{Incident: t.incident, Keys: keys})
+	}
+	data, err := json.Marshal(state)
+	e.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal open incidents: %w", err)
+	}
+
+	if err := e.store.Put(ctx, stateBucket, stateKey, data); err != nil {
+		return fmt.Errorf("failed to persist open incidents: %w", err)
+	}
+	return nil
+}
+
+// restore 加载上次保存的进行中故障
+func (e *Engine) restore(ctx context.Context) error {
+	data, err := e.store.Get(ctx, stateBucket, stateKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode open incidents: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cursor = state.Cursor
+	if state.Seen != nil {
+		e.seen = state.Seen
+	}
+	for _, p := range state.Incidents {
+		if p.Incident == nil {
+			continue
+		}
+		keys := make(map[string]bool, len(p.Keys))
+		for _, key := range p.Keys {
+			keys[key] = true
+		}
+		e.open[p.Incident.ID] = &tracked{incident: p.Incident, keys: keys}
+	}
+	if len(e.open) > 0 {
+		e.logger.Infof("Restored %d open incidents", len(e.open))
+	}
+	return nil
+}
+
+// podNodes Pod（namespace/name）所在的节点，来自最新的指标快照
+func (e *Engine) podNodes() map[string]string {
+	result := make(map[string]string)
+	if e.metrics == nil {
+		return result
+	}
+	snapshot := e.metrics.ViewSnapshot()
+	if snapshot == nil {
+		return result
+	}
+	for _, pod := range snapshot.PodMetrics {
+		if pod.NodeName != "" {
+			result[pod.Namespace+"/"+pod.PodName] = pod.NodeName
+		}
+	}
+	return result
+}
+
+// clone 复制故障，避免调用方读取时与关联循环并发修改
+func (i *Incident) clone() *Incident {
+	c := *i
+	c.Categories = make(map[string]int, len(i.Categories))
+	for k, v := range i.Categories {
+		c.Categories[k] = v
+	}
+	c.Namespaces = append([]string{}, i.Namespaces...)
+	c.Workloads = append([]string{}, i.Workloads...)
+	c.Pods = append([]string{}, i.Pods...)
+	c.Nodes = append([]string{}, i.Nodes...)
+	c.Events = append([]*models.EventInfo{}, i.Events...)
+	c.Metrics = append([]MetricSignal{}, i.Metrics...)
+	if i.Description != nil {
+		d := *i.Description
+		c.Description = &d
+	}
+	return &c
+}
+
+// inRange 故障的持续时间是否与查询范围重叠
+func inRange(incident *Incident, from, to time.Time) bool {
+	if !from.IsZero() && incident.LastSeen.Before(from) {
+		return false
+	}
+	if !to.IsZero() && incident.StartedAt.After(to) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
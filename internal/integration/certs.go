@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/mtls"
+)
+
+// CA 测试用的自签名CA，签发组件间mTLS证书
+type CA struct {
+	File string // PEM格式的CA证书，作为 tls.ca_file
+
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	t    testing.TB
+}
+
+// NewCA 创建自签名CA
+func NewCA(t testing.TB) *CA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "integration-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	ca := &CA{File: filepath.Join(t.TempDir(), "ca.crt"), cert: cert, key: key, t: t}
+	ca.write(ca.File, "CERTIFICATE", der)
+	return ca
+}
+
+// Issue 签发带给定DNS SAN（以及 127.0.0.1）的证书，可用于服务端和客户端认证；返回证书和私钥文件
+func (ca *CA) Issue(dnsNames ...string) (certFile, keyFile string) {
+	ca.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		ca.t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatalf("failed to marshal key: %v", err)
+	}
+	dir := ca.t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.write(certFile, "CERTIFICATE", der)
+	ca.write(keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+// Client 使用新签发的客户端证书访问HTTPS服务的HTTP客户端
+func (ca *CA) Client(dnsNames ...string) *http.Client {
+	ca.t.Helper()
+	certFile, keyFile := ca.Issue(dnsNames...)
+	client, err := mtls.HTTPClient(mtls.Options{CertFile: certFile, KeyFile: keyFile, CAFile: ca.File}, 10*time.Second)
+	if err != nil {
+		ca.t.Fatalf("failed to create mTLS client: %v", err)
+	}
+	return client
+}
+
+func (ca *CA) write(file, blockType string, der []byte) {
+	ca.t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		ca.t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/scheduler"
+	"github.com/yourusername/k8s-llm-monitor/internal/server"
+	"github.com/yourusername/k8s-llm-monitor/internal/uavagent"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Server 在进程内运行的API服务器
+type Server struct {
+	*server.Server
+	URL string
+
+	t testing.TB
+}
+
+// StartServer 按配置启动API服务器（不监听配置文件变更），启用mTLS时以HTTPS提供服务，测试结束时关闭
+func (e *Environment) StartServer(cfg *config.Config) *Server {
+	e.t.Helper()
+	srv, err := server.New(cfg, server.Options{DisableConfigWatch: true})
+	if err != nil {
+		e.t.Fatalf("failed to create server: %v", err)
+	}
+
+	var url string
+	var closeHTTP func()
+	if tlsConfig := srv.TLSConfig(); tlsConfig != nil {
+		// httptest 的TLS服务会用自带的证书代替服务器的证书，这里直接监听
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			e.t.Fatalf("failed to listen: %v", err)
+		}
+		httpServer := &http.Server{Handler: srv.Handler(), TLSConfig: tlsConfig}
+		go httpServer.ServeTLS(listener, "", "")
+		url = "https://" + listener.Addr().String()
+		closeHTTP = func() { httpServer.Close() }
+	} else {
+		ts := httptest.NewServer(srv.Handler())
+		url = ts.URL
+		closeHTTP = func() {
+			ts.CloseClientConnections()
+			ts.Close()
+		}
+	}
+	e.t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			e.t.Logf("server shutdown: %v", err)
+		}
+		closeHTTP()
+	})
+	return &Server{Server: srv, URL: url, t: e.t}
+}
+
+// Do 发送请求，body 不为nil时编码为JSON；返回状态码，成功时把响应解码到 out（可为nil）
+func (s *Server) Do(method, path string, body, out interface{}) int {
+	s.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("failed to marshal %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	if err != nil {
+		s.t.Fatalf("failed to create %s %s request: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 300 && out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			s.t.Fatalf("failed to decode %s %s response %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// StartScheduler 以与 cmd/scheduler 相同的方式（通过配置中的kubeconfig）运行调度控制器，测试结束时停止
+func (e *Environment) StartScheduler(cfg *config.Config, interval time.Duration) {
+	e.t.Helper()
+	k8sClient, err := k8s.NewClient(&cfg.K8s)
+	if err != nil {
+		e.t.Fatalf("failed to create k8s client: %v", err)
+	}
+	restConfig, err := k8sClient.RESTConfig()
+	if err != nil {
+		e.t.Fatalf("failed to get REST config: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		e.t.Fatalf("failed to create kube clientset: %v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		e.t.Fatalf("failed to create dynamic client: %v", err)
+	}
+
+	controller := scheduler.NewController(dynamicClient, kubeClient, k8sClient, scheduler.Config{Interval: interval})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		controller.Run(ctx)
+	}()
+	e.t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// AgentOptions 模拟UAV Agent的参数
+type AgentOptions struct {
+	NodeName string
+	NodeIP   string        // 默认 127.0.0.1
+	Count    int           // UAV数量，默认1
+	Interval time.Duration // 上报间隔，默认1秒
+}
+
+// Agent 模拟的UAV Agent：每架UAV运行MAVLink模拟器，并通过与 uav-agent 相同的上报循环上报到服务器
+type Agent struct {
+	UAVs []*uav.MAVLinkSimulator
+}
+
+// StartAgent 启动上报到该服务器的UAV Agent，测试结束时停止
+func (s *Server) StartAgent(opts AgentOptions) *Agent {
+	s.t.Helper()
+	if opts.NodeIP == "" {
+		opts.NodeIP = "127.0.0.1"
+	}
+	if opts.Count <= 0 {
+		opts.Count = 1
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	agent := &Agent{}
+	done := make(chan struct{}, opts.Count)
+	for i := 0; i < opts.Count; i++ {
+		uavID := fmt.Sprintf("UAV-%s", opts.NodeName)
+		if i > 0 {
+			uavID = fmt.Sprintf("%s-%d", uavID, i)
+		}
+		simulator := uav.NewMAVLinkSimulator(uavID, opts.NodeName)
+		simulator.Start()
+		agent.UAVs = append(agent.UAVs, simulator)
+
+		go func(index int) {
+			defer func() { done <- struct{}{} }()
+			uavagent.RunReportLoop(ctx, uavagent.ReportOptions{
+				MasterURL: s.URL,
+				Interval:  opts.Interval,
+				NodeName:  opts.NodeName,
+				NodeIP:    opts.NodeIP,
+				Index:     index,
+			}, simulator)
+		}(i)
+	}
+	s.t.Cleanup(func() {
+		cancel()
+		for range agent.UAVs {
+			<-done
+		}
+		for _, simulator := range agent.UAVs {
+			simulator.Stop()
+		}
+	})
+	return agent
+}
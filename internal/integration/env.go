@@ -0,0 +1,239 @@
+// Package integration 端到端测试环境：envtest启动真实的API Server和etcd并安装仓库中的CRD，
+// 配合伪造的Metrics Server和LLM提供商，在同一进程内运行API服务器、调度控制器和模拟UAV Agent。
+//
+// 需要 kube-apiserver 和 etcd 二进制，通过 KUBEBUILDER_ASSETS 指定所在目录
+// （例如 setup-envtest use -p path），未找到时测试跳过。
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// defaultAssetsDir envtest 未设置 KUBEBUILDER_ASSETS 时查找二进制的目录
+const defaultAssetsDir = "/usr/local/kubebuilder/bin"
+
+// crdFiles 安装到测试集群的CRD（相对仓库根目录）
+var crdFiles = []string{
+	"deployments/uav-metrics-crd.yaml",
+	"deployments/scheduling-crd.yaml",
+	"deployments/network-probe-result-crd.yaml",
+}
+
+// Environment 一套独立的测试集群及伪造的外部依赖
+type Environment struct {
+	RESTConfig *rest.Config // 直连API Server（管理员权限）
+	Kubeconfig string       // 组件使用的kubeconfig，指向在API Server前提供 metrics.k8s.io 的代理
+	Kube       *kubernetes.Clientset
+	Dynamic    dynamic.Interface
+	Metrics    *FakeMetricsServer
+	LLM        *FakeLLM
+
+	t testing.TB
+}
+
+// Start 启动测试集群，测试结束时自动停止。未找到envtest二进制时跳过测试
+func Start(t testing.TB) *Environment {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped in short mode")
+	}
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if _, err := os.Stat(filepath.Join(defaultAssetsDir, "kube-apiserver")); err != nil {
+			t.Skip("envtest binaries not found: set KUBEBUILDER_ASSETS to a directory with kube-apiserver and etcd")
+		}
+	}
+
+	root := repoRoot()
+	paths := make([]string, len(crdFiles))
+	for i, file := range crdFiles {
+		paths[i] = filepath.Join(root, file)
+	}
+	testEnv := &envtest.Environment{
+		CRDInstallOptions:     envtest.CRDInstallOptions{Paths: paths},
+		ErrorIfCRDPathMissing: true,
+	}
+	restConfig, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("failed to start envtest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Logf("failed to stop envtest: %v", err)
+		}
+	})
+
+	e := &Environment{RESTConfig: restConfig, Metrics: NewFakeMetricsServer(), t: t}
+	if e.Kube, err = kubernetes.NewForConfig(restConfig); err != nil {
+		t.Fatalf("failed to create clientset: %v", err)
+	}
+	if e.Dynamic, err = dynamic.NewForConfig(restConfig); err != nil {
+		t.Fatalf("failed to create dynamic client: %v", err)
+	}
+
+	proxy := e.startProxy()
+	e.Kubeconfig = writeKubeconfig(t, proxy.URL)
+	e.LLM = NewFakeLLM(t)
+	return e
+}
+
+// startProxy 启动API Server前的代理：metrics.k8s.io 由伪造的Metrics Server响应，其余请求以管理员身份转发
+func (e *Environment) startProxy() *httptest.Server {
+	target, err := url.Parse(e.RESTConfig.Host)
+	if err != nil {
+		e.t.Fatalf("invalid API server address %q: %v", e.RESTConfig.Host, err)
+	}
+	transport, err := rest.TransportFor(e.RESTConfig)
+	if err != nil {
+		e.t.Fatalf("failed to create API server transport: %v", err)
+	}
+	forward := httputil.NewSingleHostReverseProxy(target)
+	forward.Transport = transport
+	forward.FlushInterval = -1 // watch 响应逐条转发
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, metricsAPIPrefix) {
+			e.Metrics.ServeHTTP(w, r)
+			return
+		}
+		forward.ServeHTTP(w, r)
+	}))
+	e.t.Cleanup(func() {
+		// watch 请求不会自行结束，先断开连接再关闭
+		proxy.CloseClientConnections()
+		proxy.Close()
+	})
+	return proxy
+}
+
+// writeKubeconfig 写出指向代理的kubeconfig（代理已携带凭据，无需认证）
+func writeKubeconfig(t testing.TB, server string) string {
+	t.Helper()
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["envtest"] = &clientcmdapi.Cluster{Server: server}
+	kubeconfig.AuthInfos["envtest"] = &clientcmdapi.AuthInfo{}
+	kubeconfig.Contexts["envtest"] = &clientcmdapi.Context{Cluster: "envtest", AuthInfo: "envtest", Namespace: metav1.NamespaceDefault}
+	kubeconfig.CurrentContext = "envtest"
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*kubeconfig, path); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+// Config 连接测试集群和伪造LLM的服务器配置：内存存储，每秒采集一次节点和Pod指标，
+// 不读取kubelet Summary API、不做网络测试。调用方可以在启动组件前继续修改
+func (e *Environment) Config() *config.Config {
+	e.t.Helper()
+	content := fmt.Sprintf(`server:
+  host: 127.0.0.1
+  port: 0
+k8s:
+  kubeconfig: %q
+  namespace: default
+llm:
+  provider: openai-compatible
+  base_url: %q
+  model: fake-model
+  timeout: 10
+  cache:
+    enabled: false
+storage:
+  type: memory
+metrics:
+  enabled: true
+  collect_interval: 1
+  namespaces: [default]
+  enable_node: true
+  enable_pod: true
+  enable_network: false
+  enable_custom: false
+  enable_summary: false
+`, e.Kubeconfig, e.LLM.URL)
+
+	path := filepath.Join(e.t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		e.t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		e.t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+// CreateNode 创建一个Ready的节点，容量为给定的CPU和内存（如 "4"、"8Gi"）
+func (e *Environment) CreateNode(name, cpu, memory string) *corev1.Node {
+	e.t.Helper()
+	ctx := context.Background()
+	node, err := e.Kube.CoreV1().Nodes().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}, metav1.CreateOptions{})
+	if err != nil {
+		e.t.Fatalf("failed to create node %s: %v", name, err)
+	}
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	node.Status = corev1.NodeStatus{
+		Capacity:    capacity,
+		Allocatable: capacity,
+		Conditions: []corev1.NodeCondition{{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionTrue,
+			Reason:             "KubeletReady",
+			LastHeartbeatTime:  metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+		}},
+		Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "127.0.0.1"}},
+	}
+	if node, err = e.Kube.CoreV1().Nodes().UpdateStatus(ctx, node, metav1.UpdateOptions{}); err != nil {
+		e.t.Fatalf("failed to update node %s status: %v", name, err)
+	}
+	return node
+}
+
+// WaitFor 每隔100ms检查一次条件，超时后以最后一次的错误结束测试
+func WaitFor(t testing.TB, timeout time.Duration, condition func() error) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		err := condition()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s: %v", timeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// repoRoot 仓库根目录（本文件位于 internal/integration）
+func repoRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
@@ -0,0 +1,276 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/reportsig"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var schedulingRequestGVR = schema.GroupVersionResource{Group: "scheduler.io", Version: "v1", Resource: "schedulingrequests"}
+
+// nodeMetricsResponse GET /api/v1/metrics/nodes 中用到的字段
+type nodeMetricsResponse struct {
+	Data map[string]struct {
+		CPUUsage     int64   `json:"cpu_usage"`
+		CPUUsageRate float64 `json:"cpu_usage_rate"`
+		MemoryUsage  int64   `json:"memory_usage"`
+	} `json:"data"`
+}
+
+// waitForNodeUsage 等待服务器采集到节点的给定用量（毫核、字节）
+func waitForNodeUsage(t *testing.T, srv *Server, node string, cpu, memory int64) {
+	t.Helper()
+	WaitFor(t, 20*time.Second, func() error {
+		var resp nodeMetricsResponse
+		if code := srv.Do("GET", "/api/v1/metrics/nodes", nil, &resp); code != 200 {
+			return fmt.Errorf("status %d", code)
+		}
+		got, ok := resp.Data[node]
+		if !ok {
+			return fmt.Errorf("node %s not collected yet", node)
+		}
+		if got.CPUUsage != cpu || got.MemoryUsage != memory {
+			return fmt.Errorf("node %s usage = %dm / %d bytes, want %dm / %d bytes", node, got.CPUUsage, got.MemoryUsage, cpu, memory)
+		}
+		return nil
+	})
+}
+
+// Metrics Server中的用量经指标采集出现在API中，用量变化后下一轮采集更新
+func TestNodeMetricsFromMetricsServer(t *testing.T) {
+	env := Start(t)
+	env.CreateNode("node-1", "4", "8Gi")
+	env.Metrics.SetNodeUsage("node-1", "1", "2Gi")
+	srv := env.StartServer(env.Config())
+
+	waitForNodeUsage(t, srv, "node-1", 1000, 2<<30)
+
+	env.Metrics.SetNodeUsage("node-1", "3", "4Gi")
+	waitForNodeUsage(t, srv, "node-1", 3000, 4<<30)
+
+	var resp nodeMetricsResponse
+	srv.Do("GET", "/api/v1/metrics/nodes", nil, &resp)
+	if rate := resp.Data["node-1"].CPUUsageRate; rate != 75 {
+		t.Errorf("cpu_usage_rate = %v, want 75", rate)
+	}
+}
+
+// Agent上报的UAV状态写入UAVMetric CRD，调度控制器据此为SchedulingRequest选择节点
+func TestUAVReportsDriveScheduling(t *testing.T) {
+	env := Start(t)
+	cfg := env.Config()
+	srv := env.StartServer(cfg)
+	srv.StartAgent(AgentOptions{NodeName: "edge-a"})
+	srv.StartAgent(AgentOptions{NodeName: "edge-b", Count: 2})
+
+	WaitFor(t, 20*time.Second, func() error {
+		var resp struct {
+			Count int `json:"count"`
+		}
+		if code := srv.Do("GET", "/api/v1/crd/uav", nil, &resp); code != 200 {
+			return fmt.Errorf("status %d", code)
+		}
+		if resp.Count != 3 {
+			return fmt.Errorf("%d UAVMetric resources, want 3", resp.Count)
+		}
+		return nil
+	})
+
+	env.StartScheduler(cfg, 200*time.Millisecond)
+
+	ctx := context.Background()
+	requests := env.Dynamic.Resource(schedulingRequestGVR).Namespace("default")
+	create := func(name string, spec map[string]interface{}) {
+		t.Helper()
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "scheduler.io/v1",
+			"kind":       "SchedulingRequest",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+		if _, err := requests.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create SchedulingRequest %s: %v", name, err)
+		}
+	}
+	workload := map[string]interface{}{"name": "survey", "namespace": "default", "type": "inspection"}
+	create("prefer-a", map[string]interface{}{"workload": workload, "minBatteryPercent": int64(50), "preferredNodes": []interface{}{"edge-a"}})
+	create("prefer-b", map[string]interface{}{"workload": workload, "preferredNodes": []interface{}{"EDGE-B"}})
+
+	tests := []struct {
+		name     string
+		wantNode string
+		wantUAVs []string
+	}{
+		{name: "prefer-a", wantNode: "edge-a", wantUAVs: []string{"UAV-edge-a"}},
+		{name: "prefer-b", wantNode: "edge-b", wantUAVs: []string{"UAV-edge-b", "UAV-edge-b-1"}},
+	}
+	for _, tt := range tests {
+		var status map[string]interface{}
+		WaitFor(t, 20*time.Second, func() error {
+			obj, err := requests.Get(ctx, tt.name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			status, _, _ = unstructured.NestedMap(obj.Object, "status")
+			if phase := status["phase"]; phase != "Assigned" {
+				return fmt.Errorf("%s phase = %v (%v)", tt.name, phase, status["message"])
+			}
+			return nil
+		})
+		if status["assignedNode"] != tt.wantNode {
+			t.Errorf("%s assignedNode = %v, want %s", tt.name, status["assignedNode"], tt.wantNode)
+		}
+		uavID, _ := status["assignedUAV"].(string)
+		found := false
+		for _, want := range tt.wantUAVs {
+			found = found || uavID == want
+		}
+		if !found {
+			t.Errorf("%s assignedUAV = %q, want one of %v", tt.name, uavID, tt.wantUAVs)
+		}
+	}
+}
+
+// 自然语言查询：伪造LLM请求调用工具，服务器从采集的指标中返回结果，模型据此回答
+func TestQueryCallsToolsThroughFakeLLM(t *testing.T) {
+	env := Start(t)
+	env.CreateNode("node-1", "4", "8Gi")
+	env.Metrics.SetNodeUsage("node-1", "1", "2Gi")
+	srv := env.StartServer(env.Config())
+	waitForNodeUsage(t, srv, "node-1", 1000, 2<<30)
+
+	env.LLM.Script(
+		LLMReply{ToolCalls: []LLMToolCall{{Name: "get_node_metrics", Arguments: `{"node":"node-1"}`}}},
+		LLMReply{Content: "node-1 is at 25% CPU"},
+	)
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			Answer     string `json:"answer"`
+			Iterations int    `json:"iterations"`
+			Model      string `json:"model"`
+		} `json:"data"`
+	}
+	if code := srv.Do("POST", "/api/v1/query", map[string]string{"question": "How busy is node-1?"}, &resp); code != 200 {
+		t.Fatalf("POST /api/v1/query status = %d", code)
+	}
+	if resp.Data.Answer != "node-1 is at 25% CPU" || resp.Data.Model != "fake-model" {
+		t.Errorf("answer = %+v", resp.Data)
+	}
+
+	requests := env.LLM.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("fake LLM got %d requests, want 2", len(requests))
+	}
+	if !strings.Contains(strings.Join(requests[0].Tools, ","), "get_node_metrics") {
+		t.Errorf("first request tools = %v, want get_node_metrics", requests[0].Tools)
+	}
+	var toolResult string
+	for _, m := range requests[1].Messages {
+		if m.Role == "tool" {
+			toolResult = m.Content
+		}
+	}
+	if !strings.Contains(toolResult, `"node_name":"node-1"`) || !strings.Contains(toolResult, `"cpu_usage":1000`) {
+		t.Errorf("tool result sent to the LLM = %s", toolResult)
+	}
+}
+
+// postReport 通过 client 发送UAV上报，signNode 非空时用该节点的派生密钥签名
+func postReport(t *testing.T, client *http.Client, srv *Server, master, signNode string, report map[string]interface{}) int {
+	t.Helper()
+	body, _ := json.Marshal(report)
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/uav/report", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create report request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signNode != "" {
+		reportsig.Sign(req, reportsig.DeriveKey(master, signNode), signNode, body)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("POST /api/v1/uav/report failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// 开启上报签名后，签名节点必须与上报中的 node_name 一致，未签名的上报被拒绝
+func TestSignedReportsMustMatchNode(t *testing.T) {
+	env := Start(t)
+	cfg := env.Config()
+	cfg.UAV.ReportSigning.Enabled = true
+	cfg.UAV.ReportSigning.Required = true
+	cfg.UAV.ReportSigning.MasterKey = "integration-master"
+	srv := env.StartServer(cfg)
+
+	report := func(node string) map[string]interface{} {
+		return map[string]interface{}{"node_name": node, "uav_id": "UAV-" + node, "status": "active"}
+	}
+	tests := []struct {
+		name     string
+		signNode string
+		report   map[string]interface{}
+		want     int
+	}{
+		{name: "matching node", signNode: "edge-a", report: report("edge-a"), want: http.StatusOK},
+		{name: "other node's key", signNode: "edge-a", report: report("edge-b"), want: http.StatusForbidden},
+		{name: "unsigned", report: report("edge-a"), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postReport(t, http.DefaultClient, srv, cfg.UAV.ReportSigning.MasterKey, tt.signNode, tt.report); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+// 配置 tls.agent_node_sans 后，Agent证书中的节点身份必须与上报中的 node_name 一致
+func TestReportCertificateMustMatchNode(t *testing.T) {
+	env := Start(t)
+	ca := NewCA(t)
+	cfg := env.Config()
+	cfg.TLS.Enabled = true
+	cfg.TLS.CertFile, cfg.TLS.KeyFile = ca.Issue("k8s-llm-monitor.default.svc")
+	cfg.TLS.CAFile = ca.File
+	cfg.TLS.AgentSANs = []string{"uav-agent.*.svc"}
+	cfg.TLS.AgentNodeSANs = []string{"{node}.uav-agent.default.svc"}
+	srv := env.StartServer(cfg)
+
+	edgeA := ca.Client("uav-agent.default.svc", "edge-a.uav-agent.default.svc")
+	shared := ca.Client("uav-agent.default.svc")
+	report := func(node string) map[string]interface{} {
+		return map[string]interface{}{"node_name": node, "uav_id": "UAV-" + node, "status": "active"}
+	}
+	tests := []struct {
+		name   string
+		client *http.Client
+		node   string
+		want   int
+	}{
+		{name: "matching node", client: edgeA, node: "edge-a", want: http.StatusOK},
+		{name: "other node", client: edgeA, node: "edge-b", want: http.StatusForbidden},
+		{name: "certificate without node identity", client: shared, node: "edge-a", want: http.StatusForbidden},
+		{name: "agent identity not allowed", client: ca.Client("edge-a.other.default.svc"), node: "edge-a", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := postReport(t, tt.client, srv, "", "", report(tt.node)); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// DefaultLLMReply 没有预设回答时伪造LLM返回的内容
+const DefaultLLMReply = "fake-llm: the cluster looks healthy"
+
+// LLMRequest 伪造LLM收到的一次 Chat Completions 请求
+type LLMRequest struct {
+	Model    string
+	Messages []LLMMessage
+	Tools    []string // 提供给模型的工具名
+	Stream   bool
+}
+
+// LLMMessage 请求中的一条消息
+type LLMMessage struct {
+	Role       string
+	Content    string
+	ToolCallID string
+}
+
+// LLMReply 伪造LLM的一次回答，有工具调用时模型请求调用工具
+type LLMReply struct {
+	Content   string
+	ToolCalls []LLMToolCall
+}
+
+// LLMToolCall 模型请求的工具调用，Arguments 为JSON对象
+type LLMToolCall struct {
+	Name      string
+	Arguments string
+}
+
+// FakeLLM 伪造的OpenAI兼容接口（provider: openai-compatible），按预设顺序回答并记录收到的请求
+type FakeLLM struct {
+	URL string // 作为 llm.base_url
+
+	mu       sync.Mutex
+	requests []LLMRequest
+	replies  []LLMReply
+	calls    int
+}
+
+// NewFakeLLM 启动伪造的LLM服务，测试结束时关闭
+func NewFakeLLM(t testing.TB) *FakeLLM {
+	f := &FakeLLM{}
+	server := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(server.Close)
+	f.URL = server.URL + "/v1"
+	return f
+}
+
+// Script 追加预设回答，依次用于之后的请求，用完后返回 DefaultLLMReply
+func (f *FakeLLM) Script(replies ...LLMReply) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, replies...)
+}
+
+// Requests 已收到的请求
+func (f *FakeLLM) Requests() []LLMRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]LLMRequest(nil), f.requests...)
+}
+
+// chatRequest Chat Completions 请求中用到的字段
+type chatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role       string  `json:"role"`
+		Content    *string `json:"content"`
+		ToolCallID string  `json:"tool_call_id"`
+	} `json:"messages"`
+	Tools []struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	} `json:"tools"`
+	Stream bool `json:"stream"`
+}
+
+func (f *FakeLLM) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+		http.Error(w, `{"error":{"message":"not found","type":"invalid_request_error"}}`, http.StatusNotFound)
+		return
+	}
+	var body chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":{"message":%q,"type":"invalid_request_error"}}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	request := LLMRequest{Model: body.Model, Stream: body.Stream}
+	for _, m := range body.Messages {
+		message := LLMMessage{Role: m.Role, ToolCallID: m.ToolCallID}
+		if m.Content != nil {
+			message.Content = *m.Content
+		}
+		request.Messages = append(request.Messages, message)
+	}
+	for _, tool := range body.Tools {
+		request.Tools = append(request.Tools, tool.Function.Name)
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	reply := LLMReply{Content: DefaultLLMReply}
+	if len(f.replies) > 0 {
+		reply, f.replies = f.replies[0], f.replies[1:]
+	}
+	f.calls++
+	callID := f.calls
+	f.mu.Unlock()
+
+	message := map[string]interface{}{"role": "assistant", "content": reply.Content}
+	finishReason := "stop"
+	if len(reply.ToolCalls) > 0 {
+		calls := make([]map[string]interface{}, len(reply.ToolCalls))
+		for i, call := range reply.ToolCalls {
+			calls[i] = map[string]interface{}{
+				"index":    i,
+				"id":       fmt.Sprintf("call_%d_%d", callID, i),
+				"type":     "function",
+				"function": map[string]string{"name": call.Name, "arguments": call.Arguments},
+			}
+		}
+		message["tool_calls"] = calls
+		finishReason = "tool_calls"
+	}
+	usage := map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
+
+	if !body.Stream {
+		writeJSON(w, map[string]interface{}{
+			"model":   body.Model,
+			"choices": []interface{}{map[string]interface{}{"message": message, "finish_reason": finishReason}},
+			"usage":   usage,
+		})
+		return
+	}
+
+	// 流式响应：内容和工具调用放在一个片段中，随后是结束原因和用量
+	w.Header().Set("Content-Type", "text/event-stream")
+	send := func(chunk interface{}) {
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	send(map[string]interface{}{"model": body.Model, "choices": []interface{}{map[string]interface{}{"delta": message}}})
+	send(map[string]interface{}{"model": body.Model, "choices": []interface{}{map[string]interface{}{"delta": map[string]string{}, "finish_reason": finishReason}}})
+	send(map[string]interface{}{"model": body.Model, "choices": []interface{}{}, "usage": usage})
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
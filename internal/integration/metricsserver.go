@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// metricsAPIPrefix 伪造Metrics Server响应的路径前缀
+const metricsAPIPrefix = "/apis/metrics.k8s.io/"
+
+// metricsAPIVersion metrics.k8s.io 的组版本
+const metricsAPIVersion = "metrics.k8s.io/v1beta1"
+
+// FakeMetricsServer 伪造的 metrics.k8s.io/v1beta1 API，返回测试设置的节点和Pod用量
+type FakeMetricsServer struct {
+	mu       sync.Mutex
+	nodes    map[string]corev1.ResourceList
+	pods     map[string][]metricsv1beta1.ContainerMetrics // namespace/name -> 容器用量
+	requests int
+}
+
+// NewFakeMetricsServer 创建没有任何用量数据的Metrics Server
+func NewFakeMetricsServer() *FakeMetricsServer {
+	return &FakeMetricsServer{
+		nodes: make(map[string]corev1.ResourceList),
+		pods:  make(map[string][]metricsv1beta1.ContainerMetrics),
+	}
+}
+
+// SetNodeUsage 设置节点用量（如 "1500m"、"2Gi"）
+func (f *FakeMetricsServer) SetNodeUsage(node, cpu, memory string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes[node] = usage(cpu, memory)
+}
+
+// SetPodUsage 设置Pod中一个容器的用量，同名容器覆盖
+func (f *FakeMetricsServer) SetPodUsage(namespace, pod, container, cpu, memory string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := namespace + "/" + pod
+	containers := f.pods[key]
+	for i := range containers {
+		if containers[i].Name == container {
+			containers[i].Usage = usage(cpu, memory)
+			return
+		}
+	}
+	f.pods[key] = append(containers, metricsv1beta1.ContainerMetrics{Name: container, Usage: usage(cpu, memory)})
+}
+
+// Requests 已处理的请求数
+func (f *FakeMetricsServer) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+// ServeHTTP 处理 nodes、nodes/{name}、pods、namespaces/{ns}/pods 和 namespaces/{ns}/pods/{name}
+func (f *FakeMetricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	if r.Method != http.MethodGet {
+		writeStatus(w, http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed, "only GET is supported")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, metricsAPIPrefix+"v1beta1")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "nodes":
+		list := &metricsv1beta1.NodeMetricsList{TypeMeta: metav1.TypeMeta{APIVersion: metricsAPIVersion, Kind: "NodeMetricsList"}}
+		for _, name := range sortedKeys(f.nodes) {
+			list.Items = append(list.Items, f.nodeMetrics(name))
+		}
+		writeJSON(w, list)
+	case len(parts) == 2 && parts[0] == "nodes":
+		if _, ok := f.nodes[parts[1]]; !ok {
+			writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "nodemetrics "+parts[1]+" not found")
+			return
+		}
+		writeJSON(w, f.nodeMetrics(parts[1]))
+	case len(parts) == 1 && parts[0] == "pods":
+		writeJSON(w, f.podMetricsList(""))
+	case len(parts) == 3 && parts[0] == "namespaces" && parts[2] == "pods":
+		writeJSON(w, f.podMetricsList(parts[1]))
+	case len(parts) == 4 && parts[0] == "namespaces" && parts[2] == "pods":
+		key := parts[1] + "/" + parts[3]
+		if _, ok := f.pods[key]; !ok {
+			writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "podmetrics "+key+" not found")
+			return
+		}
+		writeJSON(w, f.podMetrics(key))
+	default:
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, "the server could not find the requested resource")
+	}
+}
+
+func (f *FakeMetricsServer) nodeMetrics(name string) metricsv1beta1.NodeMetrics {
+	return metricsv1beta1.NodeMetrics{
+		TypeMeta:   metav1.TypeMeta{APIVersion: metricsAPIVersion, Kind: "NodeMetrics"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Timestamp:  metav1.Now(),
+		Window:     metav1.Duration{Duration: 30 * time.Second},
+		Usage:      f.nodes[name].DeepCopy(),
+	}
+}
+
+func (f *FakeMetricsServer) podMetrics(key string) metricsv1beta1.PodMetrics {
+	namespace, name, _ := strings.Cut(key, "/")
+	containers := make([]metricsv1beta1.ContainerMetrics, len(f.pods[key]))
+	for i, c := range f.pods[key] {
+		containers[i] = *c.DeepCopy()
+	}
+	return metricsv1beta1.PodMetrics{
+		TypeMeta:   metav1.TypeMeta{APIVersion: metricsAPIVersion, Kind: "PodMetrics"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Timestamp:  metav1.Now(),
+		Window:     metav1.Duration{Duration: 30 * time.Second},
+		Containers: containers,
+	}
+}
+
+// podMetricsList namespace为空时返回所有命名空间
+func (f *FakeMetricsServer) podMetricsList(namespace string) *metricsv1beta1.PodMetricsList {
+	list := &metricsv1beta1.PodMetricsList{TypeMeta: metav1.TypeMeta{APIVersion: metricsAPIVersion, Kind: "PodMetricsList"}}
+	for _, key := range sortedKeys(f.pods) {
+		if namespace == "" || strings.HasPrefix(key, namespace+"/") {
+			list.Items = append(list.Items, f.podMetrics(key))
+		}
+	}
+	return list
+}
+
+func usage(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeStatus 以 metav1.Status 返回错误，客户端据此生成 apierrors
+func writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   metav1.StatusFailure,
+		Code:     int32(code),
+		Reason:   reason,
+		Message:  message,
+	})
+}
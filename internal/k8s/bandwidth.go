@@ -0,0 +1,253 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// iperf3服务端的运行方式
+const (
+	BandwidthServerTarget = "target" // 在目标Pod内启动一次性的服务端（目标Pod的镜像需要包含iperf3）
+	BandwidthServerPod    = "pod"    // 在目标Pod所在节点上创建临时的服务端Pod，测试结束后删除
+)
+
+// BandwidthServerLabel 临时iperf3服务端Pod的标签，网络测试选择Pod对时跳过这些Pod
+const BandwidthServerLabel = "k8s-llm-monitor.io/iperf3-server"
+
+// iperf3客户端连接服务端的重试次数和间隔（服务端以后台方式启动，可能还没有开始监听）
+const (
+	iperfClientAttempts = 3
+	iperfRetryDelay     = time.Second
+)
+
+// BandwidthOptions iperf3带宽测试选项
+type BandwidthOptions struct {
+	Server   string        // target 或 pod（默认 target）
+	Image    string        // pod 方式下服务端Pod的镜像（默认 networkstatic/iperf3）
+	Port     int           // 服务端端口（默认5201）
+	Duration time.Duration // 每次测试的发送时长（默认5秒）
+}
+
+func (o *BandwidthOptions) applyDefaults() {
+	if o.Server == "" {
+		o.Server = BandwidthServerTarget
+	}
+	if o.Image == "" {
+		o.Image = "networkstatic/iperf3"
+	}
+	if o.Port <= 0 {
+		o.Port = 5201
+	}
+	if o.Duration < time.Second {
+		o.Duration = 5 * time.Second
+	}
+}
+
+// Timeout 一次带宽测试（包括启动服务端Pod）的最长时间
+func (o BandwidthOptions) Timeout() time.Duration {
+	o.applyDefaults()
+	timeout := o.Duration + 15*time.Second
+	if o.Server == BandwidthServerPod {
+		timeout += 45 * time.Second // 拉取镜像和启动Pod
+	}
+	return timeout
+}
+
+// TestBandwidth 从podA向podB运行iperf3，测量TCP吞吐量。客户端在podA内执行，服务端按 opts.Server
+// 在podB内启动或作为podB所在节点上的临时Pod运行；在Pod内执行的命令需要 k8s.exec.allowed_commands 包含 iperf3
+func (rt *RTTTester) TestBandwidth(ctx context.Context, podA, podB string, opts BandwidthOptions) (*models.BandwidthResult, error) {
+	policy := rt.client.ExecPolicy()
+	if !policy.Enabled() {
+		return nil, ErrExecDisabled
+	}
+	opts.applyDefaults()
+
+	namespaceA, nameA := parsePodName(podA)
+	namespaceB, nameB := parsePodName(podB)
+	source, err := rt.client.clientset.CoreV1().Pods(namespaceA).Get(ctx, nameA, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod A info: %w", err)
+	}
+	if err := policy.CanProbeFrom(source); err != nil {
+		return nil, err
+	}
+	target, err := rt.client.clientset.CoreV1().Pods(namespaceB).Get(ctx, nameB, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod B info: %w", err)
+	}
+
+	var serverIP string
+	switch opts.Server {
+	case BandwidthServerPod:
+		server, err := rt.startIperfServerPod(ctx, target, opts)
+		if server != nil {
+			defer rt.deleteIperfServerPod(server)
+		}
+		if err != nil {
+			return nil, err
+		}
+		serverIP = server.Status.PodIP
+	case BandwidthServerTarget:
+		if target.Status.PodIP == "" {
+			return nil, fmt.Errorf("pod %s has no IP", podB)
+		}
+		// -1：服务一个客户端后退出；-D：后台运行，exec立即返回
+		argv := []string{"iperf3", "-s", "-1", "-D", "-p", strconv.Itoa(opts.Port)}
+		if _, err := rt.executeCommandInPod(ctx, namespaceB, nameB, argv); err != nil {
+			return nil, fmt.Errorf("failed to start iperf3 server in %s: %w", podB, err)
+		}
+		serverIP = target.Status.PodIP
+	default:
+		return nil, fmt.Errorf("unknown iperf3 server mode %q (supported: %s, %s)", opts.Server, BandwidthServerTarget, BandwidthServerPod)
+	}
+
+	rt.logger.Infof("执行iperf3带宽测试: %s -> %s (%s)", podA, podB, serverIP)
+	startTime := time.Now()
+	argv := []string{"iperf3", "-c", serverIP, "-p", strconv.Itoa(opts.Port),
+		"-t", strconv.Itoa(int(opts.Duration / time.Second)), "-J"}
+	var output string
+	for attempt := 1; ; attempt++ {
+		output, err = rt.executeCommandInPod(ctx, namespaceA, nameA, argv)
+		if err == nil || attempt == iperfClientAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("iperf3 client failed: %w", err)
+		case <-time.After(iperfRetryDelay):
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("iperf3 client failed: %w", err)
+	}
+
+	result, err := parseIperfOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	result.PodA, result.PodB = podA, podB
+	result.Server = opts.Server
+	result.Timestamp = startTime
+
+	rt.logger.Infof("iperf3 %s -> %s: %.1f Mbps, 重传=%d", podA, podB, result.Bandwidth, result.Retransmits)
+	return result, nil
+}
+
+// parseIperfOutput 解析 iperf3 -J 的输出，带宽取接收端的统计
+func parseIperfOutput(output string) (*models.BandwidthResult, error) {
+	var report struct {
+		End struct {
+			SumSent struct {
+				BitsPerSecond float64 `json:"bits_per_second"`
+				Retransmits   int     `json:"retransmits"`
+			} `json:"sum_sent"`
+			SumReceived struct {
+				BitsPerSecond float64 `json:"bits_per_second"`
+				Seconds       float64 `json:"seconds"`
+			} `json:"sum_received"`
+		} `json:"end"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse iperf3 output: %w", err)
+	}
+	if report.Error != "" {
+		return nil, fmt.Errorf("iperf3: %s", report.Error)
+	}
+
+	bitsPerSecond := report.End.SumReceived.BitsPerSecond
+	if bitsPerSecond == 0 {
+		bitsPerSecond = report.End.SumSent.BitsPerSecond
+	}
+	if bitsPerSecond <= 0 {
+		return nil, fmt.Errorf("iperf3 reported no throughput")
+	}
+	return &models.BandwidthResult{
+		Bandwidth:   bitsPerSecond / 1e6,
+		Retransmits: report.End.SumSent.Retransmits,
+		Duration:    report.End.SumReceived.Seconds,
+	}, nil
+}
+
+// startIperfServerPod 在目标Pod所在节点上创建iperf3服务端Pod并等待其运行，创建成功后即使等待失败也返回Pod以便清理
+func (rt *RTTTester) startIperfServerPod(ctx context.Context, target *corev1.Pod, opts BandwidthOptions) (*corev1.Pod, error) {
+	if target.Spec.NodeName == "" {
+		return nil, fmt.Errorf("pod %s/%s is not scheduled", target.Namespace, target.Name)
+	}
+
+	// 清理失败时Pod也会在截止时间后自行结束
+	deadline := int64(opts.Timeout() / time.Second)
+	port := strconv.Itoa(opts.Port)
+	spec := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "k8s-llm-monitor-iperf3-",
+			Namespace:    target.Namespace,
+			Labels:       map[string]string{BandwidthServerLabel: "true"},
+			Annotations:  map[string]string{ProbeOptOutAnnotation: "true"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              target.Spec.NodeName,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &deadline,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    "iperf3",
+				Image:   opts.Image,
+				Command: []string{"iperf3"},
+				Args:    []string{"-s", "-1", "-p", port},
+				Ports:   []corev1.ContainerPort{{Name: "iperf3", ContainerPort: int32(opts.Port)}},
+			}},
+		},
+	}
+
+	pods := rt.client.clientset.CoreV1().Pods(target.Namespace)
+	server, err := pods.Create(ctx, spec, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create iperf3 server pod: %w", err)
+	}
+	rt.logger.Debugf("Created iperf3 server pod %s/%s on node %s", server.Namespace, server.Name, server.Spec.NodeName)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		current, err := pods.Get(ctx, server.Name, metav1.GetOptions{})
+		if err != nil {
+			return server, fmt.Errorf("failed to get iperf3 server pod: %w", err)
+		}
+		switch current.Status.Phase {
+		case corev1.PodRunning:
+			if current.Status.PodIP != "" {
+				return current, nil
+			}
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return server, fmt.Errorf("iperf3 server pod %s exited (%s)", server.Name, current.Status.Phase)
+		}
+
+		select {
+		case <-ctx.Done():
+			return server, fmt.Errorf("iperf3 server pod %s not running: %w", server.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteIperfServerPod 删除临时服务端Pod（测试的context可能已经超时，使用独立的超时）
+func (rt *RTTTester) deleteIperfServerPod(server *corev1.Pod) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	grace := int64(0)
+	err := rt.client.clientset.CoreV1().Pods(server.Namespace).Delete(ctx, server.Name, metav1.DeleteOptions{GracePeriodSeconds: &grace})
+	if err != nil {
+		rt.logger.Warnf("Failed to delete iperf3 server pod %s/%s: %v", server.Namespace, server.Name, err)
+	}
+}
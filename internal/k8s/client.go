@@ -2,13 +2,19 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
 	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -21,14 +27,25 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// uavMetricGVR UAVMetric自定义资源
+var uavMetricGVR = schema.GroupVersionResource{
+	Group:    "monitoring.io",
+	Version:  "v1",
+	Resource: "uavmetrics",
+}
+
 // Client K8s客户端封装
 type Client struct {
 	clientset  *kubernetes.Clientset
 	dynamic    dynamic.Interface
 	config     *config.K8sConfig
 	restConfig *rest.Config
-	logger     *logrus.Logger
+	logger     *logrus.Entry
 	namespaces []string
+	execPolicy *ExecPolicy
+
+	probeAgents    *probe.Client // 探测Agent客户端，未启用时为nil
+	probeNamespace string
 }
 
 // NewClient 创建新的K8s客户端
@@ -48,6 +65,9 @@ func NewClient(cfg *config.K8sConfig) (*Client, error) {
 		return nil, fmt.Errorf("failed to create k8s config: %w", err)
 	}
 
+	// API Server调用记录为子span（未启用追踪时为no-op）
+	restConfig.Wrap(tracing.Transport)
+
 	// 创建clientset
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -63,8 +83,7 @@ func NewClient(cfg *config.K8sConfig) (*Client, error) {
 	// 解析要监控的namespace
 	namespaces := parseNamespaces(cfg.WatchNamespaces)
 
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("k8s")
 
 	return &Client{
 		clientset:  clientset,
@@ -73,6 +92,7 @@ func NewClient(cfg *config.K8sConfig) (*Client, error) {
 		restConfig: restConfig,
 		logger:     logger,
 		namespaces: namespaces,
+		execPolicy: NewExecPolicy(cfg.Exec),
 	}, nil
 }
 
@@ -213,29 +233,41 @@ func (c *Client) GetPodLogs(namespace, podName string, lines int64) (string, err
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	req := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
-		TailLines: &lines,
-	})
+	return c.GetPodLogsWithOptions(ctx, namespace, podName, LogOptions{TailLines: lines})
+}
 
-	logs, err := req.Stream(ctx)
+// LogOptions 读取Pod日志的选项
+type LogOptions struct {
+	Container  string // 为空时使用Pod的默认容器（单容器Pod）
+	TailLines  int64  // 最后N行，0表示全部
+	Previous   bool   // 读取上一次（崩溃前）容器实例的日志
+	LimitBytes int64  // 最多读取的字节数，0表示不限制
+}
+
+// GetPodLogsWithOptions 按选项获取Pod日志，Pod或容器不存在时返回的错误可用 apierrors.IsNotFound 判断
+func (c *Client) GetPodLogsWithOptions(ctx context.Context, namespace, podName string, opts LogOptions) (string, error) {
+	logOptions := &corev1.PodLogOptions{
+		Container: opts.Container,
+		Previous:  opts.Previous,
+	}
+	if opts.TailLines > 0 {
+		logOptions.TailLines = &opts.TailLines
+	}
+	if opts.LimitBytes > 0 {
+		logOptions.LimitBytes = &opts.LimitBytes
+	}
+
+	logs, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, logOptions).Stream(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod logs: %w", err)
 	}
 	defer logs.Close()
 
-	buf := make([]byte, 1024)
-	var result string
-	for {
-		n, err := logs.Read(buf)
-		if n > 0 {
-			result += string(buf[:n])
-		}
-		if err != nil {
-			break
-		}
+	data, err := io.ReadAll(logs)
+	if err != nil && len(data) == 0 {
+		return "", fmt.Errorf("failed to read pod logs: %w", err)
 	}
-
-	return result, nil
+	return string(data), nil
 }
 
 // Namespaces 返回监控的namespace列表
@@ -243,6 +275,11 @@ func (c *Client) Namespaces() []string {
 	return c.namespaces
 }
 
+// ExecPolicy 返回在Pod内执行命令的安全策略
+func (c *Client) ExecPolicy() *ExecPolicy {
+	return c.execPolicy
+}
+
 // RESTConfig 返回底层的 REST 配置
 func (c *Client) RESTConfig() (*rest.Config, error) {
 	if c.restConfig == nil {
@@ -257,13 +294,7 @@ func (c *Client) ListUAVMetricsCRD(ctx context.Context, namespace string) ([]*mo
 		return nil, fmt.Errorf("dynamic client not initialized")
 	}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "monitoring.io",
-		Version:  "v1",
-		Resource: "uavmetrics",
-	}
-
-	resource := c.dynamic.Resource(gvr)
+	resource := c.dynamic.Resource(uavMetricGVR)
 
 	var (
 		list *unstructured.UnstructuredList
@@ -312,14 +343,14 @@ func convertUnstructuredToModel(obj *unstructured.Unstructured, group, kind stri
 	}
 }
 
-// UpsertUAVMetric 创建或更新UAVMetric自定义资源
-func (c *Client) UpsertUAVMetric(ctx context.Context, namespace string, report *models.UAVReport) error {
+// UpsertUAVMetric 用UAV条目创建或更新UAVMetric自定义资源
+func (c *Client) UpsertUAVMetric(ctx context.Context, namespace string, entry *models.UAVMetricsEntry) error {
 	if c.dynamic == nil {
 		return fmt.Errorf("dynamic client not initialized")
 	}
 
-	if report == nil {
-		return fmt.Errorf("uav report is nil")
+	if entry == nil {
+		return fmt.Errorf("uav entry is nil")
 	}
 
 	if namespace == "" {
@@ -329,36 +360,33 @@ func (c *Client) UpsertUAVMetric(ctx context.Context, namespace string, report *
 		}
 	}
 
-	resourceName := fmt.Sprintf("uavmetric-%s", sanitizeResourceName(report.NodeName))
-	if report.NodeName == "" {
-		return fmt.Errorf("uav report missing node name")
+	resourceName := uavMetricName(entry.NodeName, entry.Index)
+	if entry.NodeName == "" {
+		return fmt.Errorf("uav entry missing node name")
 	}
 
-	reportTime := report.Timestamp
-	if reportTime.IsZero() {
-		reportTime = time.Now().UTC()
+	lastUpdate := entry.LastHeartbeat
+	if lastUpdate.IsZero() {
+		lastUpdate = time.Now().UTC()
 	}
 
-	status := report.Status
+	status := entry.Status
 	if status == "" {
 		status = "active"
 	}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "monitoring.io",
-		Version:  "v1",
-		Resource: "uavmetrics",
-	}
-
-	resource := c.dynamic.Resource(gvr).Namespace(namespace)
+	resource := c.dynamic.Resource(uavMetricGVR).Namespace(namespace)
 
 	spec := map[string]interface{}{
-		"node_name": report.NodeName,
-		"uav_id":    report.UAVID,
+		"node_name": entry.NodeName,
+		"uav_id":    entry.UAVID,
+	}
+	if entry.Index > 0 {
+		spec["index"] = int64(entry.Index)
 	}
 
-	if report.State != nil {
-		state := report.State
+	if entry.State != nil {
+		state := entry.State
 		spec["gps"] = map[string]interface{}{
 			"latitude":          state.GPS.Latitude,
 			"longitude":         state.GPS.Longitude,
@@ -387,21 +415,24 @@ func (c *Client) UpsertUAVMetric(ctx context.Context, namespace string, report *
 	}
 
 	statusPayload := map[string]interface{}{
-		"last_update":       reportTime.UTC().Format(time.RFC3339),
+		"last_update":       lastUpdate.UTC().Format(time.RFC3339),
 		"collection_status": status,
 	}
+	if entry.State != nil && entry.State.Geofence.Enabled {
+		statusPayload["geofence"] = uavGeofenceStatus(entry.State.Geofence)
+	}
 
 	labels := map[string]interface{}{
 		"app":                     "uav-agent",
 		"monitoring.io/component": "uav-metrics",
-		"monitoring.io/node":      sanitizeResourceName(report.NodeName),
+		"monitoring.io/node":      sanitizeResourceName(entry.NodeName),
 	}
-	if report.UAVID != "" {
-		labels["monitoring.io/uav-id"] = sanitizeResourceName(report.UAVID)
+	if entry.UAVID != "" {
+		labels["monitoring.io/uav-id"] = sanitizeResourceName(entry.UAVID)
 	}
 
-	if report.NodeIP != "" {
-		labels["monitoring.io/node-ip"] = report.NodeIP
+	if entry.NodeIP != "" {
+		labels["monitoring.io/node-ip"] = entry.NodeIP
 	}
 
 	obj := &unstructured.Unstructured{
@@ -449,6 +480,224 @@ func (c *Client) UpsertUAVMetric(ctx context.Context, namespace string, report *
 	return nil
 }
 
+// RecordUAVStatusChange 将UAV心跳状态的变化写入UAVMetric的 status.collection_status，
+// 并在该资源上记录K8s事件（超时为Warning，恢复为Normal）。资源不存在时（未收到过Agent上报）只记录事件
+func (c *Client) RecordUAVStatusChange(ctx context.Context, namespace string, change *models.UAVStatusChange) error {
+	if c.dynamic == nil {
+		return fmt.Errorf("dynamic client not initialized")
+	}
+	if namespace == "" {
+		namespace = c.config.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+
+	resourceName := uavMetricName(change.NodeName, change.Index)
+	involved := corev1.ObjectReference{
+		APIVersion: "monitoring.io/v1",
+		Kind:       "UAVMetric",
+		Name:       resourceName,
+		Namespace:  namespace,
+	}
+
+	resource := c.dynamic.Resource(uavMetricGVR).Namespace(namespace)
+	existing, err := resource.Get(ctx, resourceName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		involved.UID = existing.GetUID()
+		status, _, _ := unstructured.NestedMap(existing.Object, "status")
+		if status == nil {
+			status = map[string]interface{}{}
+		}
+		status["collection_status"] = change.To
+		status["last_transition"] = change.Timestamp.UTC().Format(time.RFC3339)
+		existing.Object["status"] = status
+		if _, err := resource.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update UAVMetric %s status: %w", resourceName, err)
+		}
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get UAVMetric %s: %w", resourceName, err)
+	}
+
+	uavID := change.UAVID
+	if uavID == "" {
+		uavID = "UAV"
+	}
+	eventType, reason := corev1.EventTypeWarning, "UAVStale"
+	message := fmt.Sprintf("%s on node %s missed %d heartbeats (last heartbeat %s)",
+		uavID, change.NodeName, change.MissedHeartbeats, change.LastHeartbeat.UTC().Format(time.RFC3339))
+	switch {
+	case change.Recovered():
+		eventType, reason = corev1.EventTypeNormal, "UAVRecovered"
+		message = fmt.Sprintf("%s on node %s is reporting again (was %s)", uavID, change.NodeName, change.From)
+	case change.To == models.UAVStatusLost:
+		reason = "UAVLost"
+	}
+
+	timestamp := metav1.NewTime(change.Timestamp)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: resourceName + ".",
+			Namespace:    namespace,
+		},
+		InvolvedObject: involved,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "k8s-llm-monitor"},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+	}
+	if _, err := c.clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to record event for UAVMetric %s: %w", resourceName, err)
+	}
+	return nil
+}
+
+// RecordUAVGeofenceChange 将UAV越出或回到地理围栏写入UAVMetric的 status.geofence，
+// 并在该资源上记录K8s事件（越界为Warning的 UAVGeofenceBreach，恢复为Normal的 UAVGeofenceCleared）
+func (c *Client) RecordUAVGeofenceChange(ctx context.Context, namespace string, change *models.UAVGeofenceChange) error {
+	if c.dynamic == nil {
+		return fmt.Errorf("dynamic client not initialized")
+	}
+	if namespace == "" {
+		namespace = c.config.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+
+	resourceName := uavMetricName(change.NodeName, change.Index)
+	involved := corev1.ObjectReference{
+		APIVersion: "monitoring.io/v1",
+		Kind:       "UAVMetric",
+		Name:       resourceName,
+		Namespace:  namespace,
+	}
+
+	resource := c.dynamic.Resource(uavMetricGVR).Namespace(namespace)
+	existing, err := resource.Get(ctx, resourceName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		involved.UID = existing.GetUID()
+		status, _, _ := unstructured.NestedMap(existing.Object, "status")
+		if status == nil {
+			status = map[string]interface{}{}
+		}
+		geofence, _, _ := unstructured.NestedMap(status, "geofence")
+		if geofence == nil {
+			geofence = map[string]interface{}{}
+		}
+		geofence["breached"] = change.Breached
+		geofence["breach_count"] = int64(change.BreachCount)
+		if change.Breached {
+			geofence["last_breach"] = change.Timestamp.UTC().Format(time.RFC3339)
+		}
+		status["geofence"] = geofence
+		existing.Object["status"] = status
+		if _, err := resource.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update UAVMetric %s status: %w", resourceName, err)
+		}
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get UAVMetric %s: %w", resourceName, err)
+	}
+
+	uavID := change.UAVID
+	if uavID == "" {
+		uavID = "UAV"
+	}
+	eventType, reason := corev1.EventTypeWarning, "UAVGeofenceBreach"
+	message := fmt.Sprintf("%s on node %s left its geofence at %.6f,%.6f (%.1fm above home)",
+		uavID, change.NodeName, change.Latitude, change.Longitude, change.RelativeAltitude)
+	if change.Action != "" {
+		message += ", action: " + change.Action
+	}
+	if !change.Breached {
+		eventType, reason = corev1.EventTypeNormal, "UAVGeofenceCleared"
+		message = fmt.Sprintf("%s on node %s is back inside its geofence", uavID, change.NodeName)
+	}
+
+	timestamp := metav1.NewTime(change.Timestamp)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: resourceName + ".",
+			Namespace:    namespace,
+		},
+		InvolvedObject: involved,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "k8s-llm-monitor"},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+	}
+	if _, err := c.clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to record event for UAVMetric %s: %w", resourceName, err)
+	}
+	return nil
+}
+
+// uavGeofenceStatus UAVMetric的 status.geofence
+func uavGeofenceStatus(fence uav.GeofenceStatus) map[string]interface{} {
+	status := map[string]interface{}{
+		"breached":     fence.Breached,
+		"inside":       fence.Inside,
+		"breach_count": int64(fence.BreachCount),
+	}
+	if fence.LastBreach != nil {
+		status["last_breach"] = fence.LastBreach.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// uavMetricName UAV对应的UAVMetric资源名：Agent的第一架UAV为 uavmetric-<节点名>，
+// 其他UAV为 uavmetric-<节点名>.<序号>（节点名中的点已替换，不会与序号混淆）
+func uavMetricName(nodeName string, index int) string {
+	name := fmt.Sprintf("uavmetric-%s", sanitizeResourceName(nodeName))
+	if index > 0 {
+		name += fmt.Sprintf(".%d", index)
+	}
+	return name
+}
+
+// UAVMetricsEntryFromCRD 将UAVMetric自定义资源转换回UAV条目，state 只包含CRD中保存的GPS、电池、飞行和健康字段
+func UAVMetricsEntryFromCRD(obj *unstructured.Unstructured) (*models.UAVMetricsEntry, error) {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	entry := &models.UAVMetricsEntry{NodeIP: obj.GetLabels()["monitoring.io/node-ip"]}
+	entry.NodeName, _, _ = unstructured.NestedString(spec, "node_name")
+	entry.UAVID, _, _ = unstructured.NestedString(spec, "uav_id")
+	if index, ok, _ := unstructured.NestedInt64(spec, "index"); ok {
+		entry.Index = int(index)
+	}
+	entry.Status, _, _ = unstructured.NestedString(obj.Object, "status", "collection_status")
+	if lastUpdate, _, _ := unstructured.NestedString(obj.Object, "status", "last_update"); lastUpdate != "" {
+		if parsed, err := time.Parse(time.RFC3339, lastUpdate); err == nil {
+			entry.Timestamp, entry.LastHeartbeat = parsed, parsed
+		}
+	}
+
+	for _, field := range []string{"gps", "battery", "flight", "health"} {
+		if _, ok := spec[field]; ok {
+			entry.State = &uav.UAVState{}
+		}
+	}
+	if entry.State == nil {
+		return entry, nil
+	}
+	// spec中的字段名与UAVState的JSON字段一致
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode UAVMetric %s spec: %w", obj.GetName(), err)
+	}
+	if err := json.Unmarshal(data, entry.State); err != nil {
+		return nil, fmt.Errorf("invalid UAVMetric %s spec: %w", obj.GetName(), err)
+	}
+	return entry, nil
+}
+
 func sanitizeResourceName(name string) string {
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, "_", "-")
@@ -0,0 +1,265 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 识别出的CNI
+const (
+	CNICalico     = "calico"
+	CNICilium     = "cilium"
+	CNIFlannel    = "flannel"
+	CNICanal      = "canal" // flannel 转发 + calico 策略
+	CNIWeave      = "weave"
+	CNIAntrea     = "antrea"
+	CNIKubeRouter = "kube-router"
+	CNIUnknown    = "unknown"
+)
+
+// kube-proxy 模式（ebpf 表示由CNI替代kube-proxy转发Service流量）
+const (
+	ProxyModeIPTables = "iptables"
+	ProxyModeIPVS     = "ipvs"
+	ProxyModeNFTables = "nftables"
+	ProxyModeEBPF     = "ebpf"
+	ProxyModeUnknown  = "unknown"
+)
+
+// cniNamespaces CNI组件通常所在的命名空间（operator方式安装的calico和新版flannel不在kube-system）
+var cniNamespaces = []string{"kube-system", "calico-system", "kube-flannel"}
+
+// cniContainers CNI的节点Agent容器名，按识别优先级排列
+var cniContainers = []struct {
+	cni       string
+	container string
+}{
+	{CNICilium, "cilium-agent"},
+	{CNICalico, "calico-node"},
+	{CNIFlannel, "kube-flannel"},
+	{CNIWeave, "weave"},
+	{CNIAntrea, "antrea-agent"},
+	{CNIKubeRouter, "kube-router"},
+}
+
+// cniNodeAnnotations CNI写在节点上的注解前缀
+var cniNodeAnnotations = []struct {
+	cni    string
+	prefix string
+}{
+	{CNICilium, "network.cilium.io/"},
+	{CNICilium, "io.cilium.network."},
+	{CNICalico, "projectcalico.org/"},
+	{CNIFlannel, "flannel.alpha.coreos.com/"},
+	{CNIKubeRouter, "kube-router.io/"},
+}
+
+// kube-system 中描述网络环境的ConfigMap
+const (
+	kubeProxyConfigMap = "kube-proxy"
+	ciliumConfigMap    = "cilium-config"
+)
+
+// DetectNetworkEnvironment 通过kube-system等命名空间中的CNI和kube-proxy Pod、节点注解以及
+// kube-proxy/cilium的ConfigMap识别集群的CNI和kube-proxy模式。ConfigMap不可读时按Pod参数判断
+func (c *Client) DetectNetworkEnvironment(ctx context.Context) (*models.NetworkEnvironment, error) {
+	var pods []corev1.Pod
+	for _, namespace := range cniNamespaces {
+		list, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if namespace == "kube-system" {
+				return nil, fmt.Errorf("failed to list pods in kube-system: %w", err)
+			}
+			continue
+		}
+		pods = append(pods, list.Items...)
+	}
+
+	// 节点注解只是补充证据，取一个节点即可
+	var nodes []corev1.Node
+	if list, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1}); err == nil {
+		nodes = list.Items
+	}
+
+	configs := make(map[string]map[string]string)
+	for _, name := range []string{kubeProxyConfigMap, ciliumConfigMap} {
+		cm, err := c.clientset.CoreV1().ConfigMaps("kube-system").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				c.logger.Debugf("Failed to read ConfigMap kube-system/%s: %v", name, err)
+			}
+			continue
+		}
+		configs[name] = cm.Data
+	}
+
+	return detectNetworkEnvironment(pods, nodes, configs), nil
+}
+
+// detectNetworkEnvironment 根据收集到的Pod、节点和ConfigMap数据识别网络环境
+func detectNetworkEnvironment(pods []corev1.Pod, nodes []corev1.Node, configs map[string]map[string]string) *models.NetworkEnvironment {
+	env := &models.NetworkEnvironment{CNI: CNIUnknown, KubeProxyMode: ProxyModeUnknown}
+
+	found := make(map[string]*corev1.Container)
+	var kubeProxy *corev1.Container
+	for i := range pods {
+		for j := range pods[i].Spec.Containers {
+			container := &pods[i].Spec.Containers[j]
+			if container.Name == "kube-proxy" && kubeProxy == nil {
+				kubeProxy = container
+			}
+			for _, known := range cniContainers {
+				if container.Name == known.container && found[known.cni] == nil {
+					found[known.cni] = container
+					env.Evidence = append(env.Evidence, fmt.Sprintf("container %s in pod %s/%s", container.Name, pods[i].Namespace, pods[i].Name))
+				}
+			}
+		}
+	}
+
+	switch {
+	case found[CNICalico] != nil && found[CNIFlannel] != nil:
+		env.CNI = CNICanal
+		env.CNIVersion = imageTag(found[CNICalico].Image)
+	default:
+		for _, known := range cniContainers {
+			if container := found[known.cni]; container != nil {
+				env.CNI = known.cni
+				env.CNIVersion = imageTag(container.Image)
+				break
+			}
+		}
+	}
+
+	// 没有找到CNI的Pod（例如托管集群隐藏了系统组件）时按节点注解判断
+	for _, node := range nodes {
+		for _, known := range cniNodeAnnotations {
+			if annotation := annotationWithPrefix(node.Annotations, known.prefix); annotation != "" {
+				env.Evidence = append(env.Evidence, fmt.Sprintf("annotation %s on node %s", annotation, node.Name))
+				if env.CNI == CNIUnknown {
+					env.CNI = known.cni
+				}
+				break
+			}
+		}
+	}
+
+	cilium := configs[ciliumConfigMap]
+	switch env.CNI {
+	case CNIFlannel:
+		env.EnforcesNetworkPolicy = boolPtr(false)
+	case CNICilium:
+		env.PolicyMode = cilium["enable-policy"]
+		if env.PolicyMode == "" {
+			env.PolicyMode = "default"
+		}
+		env.EnforcesNetworkPolicy = boolPtr(env.PolicyMode != "never")
+	case CNICalico, CNICanal, CNIWeave, CNIAntrea, CNIKubeRouter:
+		env.EnforcesNetworkPolicy = boolPtr(true)
+	}
+
+	env.KubeProxyMode = detectProxyMode(kubeProxy, configs[kubeProxyConfigMap], env.CNI, cilium, found[CNICalico])
+	env.Notes = environmentNotes(env)
+	return env
+}
+
+// detectProxyMode kube-proxy 存在时取 --proxy-mode 参数或ConfigMap中的 mode（为空即iptables）；
+// 不存在时若cilium开启了kube-proxy替代或calico使用eBPF数据面则为ebpf
+func detectProxyMode(kubeProxy *corev1.Container, config map[string]string, cni string, cilium map[string]string, calico *corev1.Container) string {
+	if kubeProxy != nil {
+		for _, arg := range append(append([]string{}, kubeProxy.Command...), kubeProxy.Args...) {
+			if mode, ok := strings.CutPrefix(arg, "--proxy-mode="); ok && mode != "" {
+				return mode
+			}
+		}
+		if conf, ok := config["config.conf"]; ok {
+			for _, line := range strings.Split(conf, "\n") {
+				if value, ok := strings.CutPrefix(strings.TrimSpace(line), "mode:"); ok {
+					if mode := strings.Trim(strings.TrimSpace(value), `"'`); mode != "" {
+						return mode
+					}
+					return ProxyModeIPTables
+				}
+			}
+		}
+		return ProxyModeIPTables
+	}
+
+	if cni == CNICilium {
+		switch cilium["kube-proxy-replacement"] {
+		case "true", "strict":
+			return ProxyModeEBPF
+		}
+	}
+	if calico != nil {
+		for _, env := range calico.Env {
+			if env.Name == "FELIX_BPFENABLED" && strings.EqualFold(env.Value, "true") {
+				return ProxyModeEBPF
+			}
+		}
+	}
+	return ProxyModeUnknown
+}
+
+// environmentNotes 与CNI和kube-proxy模式相关的排查提示
+func environmentNotes(env *models.NetworkEnvironment) []string {
+	var notes []string
+	switch env.CNI {
+	case CNICilium:
+		if env.PolicyMode != "never" {
+			notes = append(notes, fmt.Sprintf("Cilium in policy-enforcement mode (%s) detected; check CiliumNetworkPolicies and CiliumClusterwideNetworkPolicies too", env.PolicyMode))
+		}
+	case CNICalico, CNICanal:
+		notes = append(notes, "Calico detected; check Calico NetworkPolicies and GlobalNetworkPolicies too")
+	case CNIFlannel:
+		notes = append(notes, "Flannel does not enforce NetworkPolicies; policies selecting these pods have no effect")
+	case CNIAntrea:
+		notes = append(notes, "Antrea detected; check Antrea NetworkPolicies and ClusterNetworkPolicies too")
+	}
+
+	switch env.KubeProxyMode {
+	case ProxyModeEBPF:
+		notes = append(notes, "Service traffic is handled by eBPF instead of kube-proxy; inspect the CNI's service map rather than iptables rules")
+	case ProxyModeIPVS:
+		notes = append(notes, "kube-proxy runs in ipvs mode; inspect the virtual servers with ipvsadm -Ln on the source node")
+	}
+	return notes
+}
+
+// annotationWithPrefix 返回第一个（按名称排序）以 prefix 开头的注解名
+func annotationWithPrefix(annotations map[string]string, prefix string) string {
+	var matches []string
+	for key := range annotations {
+		if strings.HasPrefix(key, prefix) {
+			matches = append(matches, key)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[0]
+}
+
+// imageTag 返回镜像的tag（去掉digest），没有tag时为空
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	slash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if colon <= slash {
+		return ""
+	}
+	return image[colon+1:]
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
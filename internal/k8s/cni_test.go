@@ -0,0 +1,145 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// systemPod 只有一个容器的kube-system Pod
+func systemPod(name string, container corev1.Container) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: name},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+	}
+}
+
+func TestDetectNetworkEnvironment(t *testing.T) {
+	kubeProxy := systemPod("kube-proxy-abcde", corev1.Container{Name: "kube-proxy", Command: []string{"/usr/local/bin/kube-proxy", "--config=/var/lib/kube-proxy/config.conf"}})
+	calico := systemPod("calico-node-x1", corev1.Container{Name: "calico-node", Image: "docker.io/calico/node:v3.27.0"})
+	flannel := systemPod("kube-flannel-ds-1", corev1.Container{Name: "kube-flannel", Image: "flannel/flannel:v0.24.2"})
+	cilium := systemPod("cilium-7xk2p", corev1.Container{Name: "cilium-agent", Image: "quay.io/cilium/cilium:v1.15.1@sha256:abc"})
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		nodes    []corev1.Node
+		configs  map[string]map[string]string
+		cni      string
+		version  string
+		mode     string
+		enforces *bool
+	}{
+		{
+			name:     "calico with iptables kube-proxy",
+			pods:     []corev1.Pod{kubeProxy, calico},
+			configs:  map[string]map[string]string{kubeProxyConfigMap: {"config.conf": "kind: KubeProxyConfiguration\nmode: \"\"\n"}},
+			cni:      CNICalico,
+			version:  "v3.27.0",
+			mode:     ProxyModeIPTables,
+			enforces: boolPtr(true),
+		},
+		{
+			name:     "flannel with ipvs",
+			pods:     []corev1.Pod{kubeProxy, flannel},
+			configs:  map[string]map[string]string{kubeProxyConfigMap: {"config.conf": "mode: ipvs\n"}},
+			cni:      CNIFlannel,
+			version:  "v0.24.2",
+			mode:     ProxyModeIPVS,
+			enforces: boolPtr(false),
+		},
+		{
+			name:     "canal",
+			pods:     []corev1.Pod{kubeProxy, calico, flannel},
+			cni:      CNICanal,
+			version:  "v3.27.0",
+			mode:     ProxyModeIPTables,
+			enforces: boolPtr(true),
+		},
+		{
+			name:     "cilium replacing kube-proxy",
+			pods:     []corev1.Pod{cilium},
+			configs:  map[string]map[string]string{ciliumConfigMap: {"kube-proxy-replacement": "true"}},
+			cni:      CNICilium,
+			version:  "v1.15.1",
+			mode:     ProxyModeEBPF,
+			enforces: boolPtr(true),
+		},
+		{
+			name:     "cilium without policy enforcement",
+			pods:     []corev1.Pod{kubeProxy, cilium},
+			configs:  map[string]map[string]string{ciliumConfigMap: {"enable-policy": "never"}},
+			cni:      CNICilium,
+			version:  "v1.15.1",
+			mode:     ProxyModeIPTables,
+			enforces: boolPtr(false),
+		},
+		{
+			name: "proxy mode from arguments",
+			pods: []corev1.Pod{
+				systemPod("kube-proxy-1", corev1.Container{Name: "kube-proxy", Args: []string{"--proxy-mode=nftables"}}),
+				calico,
+			},
+			cni:      CNICalico,
+			version:  "v3.27.0",
+			mode:     ProxyModeNFTables,
+			enforces: boolPtr(true),
+		},
+		{
+			name: "calico ebpf dataplane",
+			pods: []corev1.Pod{systemPod("calico-node-2", corev1.Container{
+				Name:  "calico-node",
+				Image: "calico/node:v3.27.0",
+				Env:   []corev1.EnvVar{{Name: "FELIX_BPFENABLED", Value: "true"}},
+			})},
+			cni:      CNICalico,
+			version:  "v3.27.0",
+			mode:     ProxyModeEBPF,
+			enforces: boolPtr(true),
+		},
+		{
+			name: "node annotations only",
+			nodes: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{
+				"flannel.alpha.coreos.com/backend-type": "vxlan",
+			}}}},
+			cni:      CNIFlannel,
+			mode:     ProxyModeUnknown,
+			enforces: boolPtr(false),
+		},
+		{
+			name: "nothing recognised",
+			cni:  CNIUnknown,
+			mode: ProxyModeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := detectNetworkEnvironment(tt.pods, tt.nodes, tt.configs)
+			if env.CNI != tt.cni || env.CNIVersion != tt.version || env.KubeProxyMode != tt.mode {
+				t.Errorf("cni = %q %q, mode = %q, want %q %q, %q", env.CNI, env.CNIVersion, env.KubeProxyMode, tt.cni, tt.version, tt.mode)
+			}
+			switch {
+			case tt.enforces == nil && env.EnforcesNetworkPolicy != nil:
+				t.Errorf("enforces network policy = %v, want unknown", *env.EnforcesNetworkPolicy)
+			case tt.enforces != nil && (env.EnforcesNetworkPolicy == nil || *env.EnforcesNetworkPolicy != *tt.enforces):
+				t.Errorf("enforces network policy = %v, want %v", env.EnforcesNetworkPolicy, *tt.enforces)
+			}
+			if tt.cni != CNIUnknown && len(env.Evidence) == 0 {
+				t.Error("expected evidence for the detected CNI")
+			}
+		})
+	}
+}
+
+func TestCiliumPolicyNote(t *testing.T) {
+	env := detectNetworkEnvironment([]corev1.Pod{systemPod("cilium-1", corev1.Container{Name: "cilium-agent"})}, nil, nil)
+	if env.PolicyMode != "default" || len(env.Notes) == 0 {
+		t.Fatalf("policy mode = %q, notes = %v", env.PolicyMode, env.Notes)
+	}
+	want := "Cilium in policy-enforcement mode (default) detected; check CiliumNetworkPolicies and CiliumClusterwideNetworkPolicies too"
+	if env.Notes[0] != want {
+		t.Errorf("note = %q, want %q", env.Notes[0], want)
+	}
+}
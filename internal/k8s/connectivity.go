@@ -0,0 +1,166 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrNoMatchingPod 选择器没有匹配到可用的Pod（运行中、就绪、有IP）
+var ErrNoMatchingPod = errors.New("no ready pod matches selector")
+
+// ConnectivityCheck 一次连通性探测：从源选择器匹配的Pod访问目标选择器匹配的Pod，或访问URL。
+// 选择器匹配多个Pod时按名称取第一个，使同一探测的历史尽量对应同一条路径
+type ConnectivityCheck struct {
+	SourceNamespace string
+	SourceSelector  string
+	TargetNamespace string // 为空时与源相同
+	TargetSelector  string
+	URL             string // 与 TargetSelector 二选一
+	Method          string // probe.MethodPing（默认）、MethodTCP、MethodUDP、MethodHTTP，URL固定为http
+	Port            int
+	AllowNoReply    bool // udp：目标不回复也算成功
+}
+
+// ConnectivityResult 连通性探测结果
+type ConnectivityResult struct {
+	Source     string  `json:"source"` // namespace/name
+	Target     string  `json:"target"` // namespace/name 或 URL
+	TargetIP   string  `json:"target_ip,omitempty"`
+	Method     string  `json:"method"`
+	Via        string  `json:"via"` // agent（源节点上的探测Agent）或 exec
+	Success    bool    `json:"success"`
+	RTT        float64 `json:"rtt_ms"`
+	PacketLoss float64 `json:"packet_loss"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// CheckConnectivity 执行一次连通性探测。Pod目标优先由源Pod所在节点的探测Agent执行，
+// 没有Agent时以及URL目标在源Pod中exec执行。无法选出源或目标Pod时返回错误（包装 ErrNoMatchingPod）
+func (c *Client) CheckConnectivity(ctx context.Context, check ConnectivityCheck) (*ConnectivityResult, error) {
+	method := check.Method
+	if check.URL != "" {
+		method = probe.MethodHTTP
+	} else if method == "" {
+		method = probe.MethodPing
+	}
+
+	source, err := c.selectPod(ctx, check.SourceNamespace, check.SourceSelector, true)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	result := &ConnectivityResult{Source: source.Namespace + "/" + source.Name, Method: method}
+
+	if check.URL != "" {
+		result.Target = check.URL
+		result.Via = "exec"
+		c.execConnectivity(ctx, source, []string{"curl", "-s", "-o", "/dev/null", "-w", "%{time_total}", "-m", "5", check.URL}, result)
+		return result, nil
+	}
+
+	targetNamespace := check.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = check.SourceNamespace
+	}
+	target, err := c.selectPod(ctx, targetNamespace, check.TargetSelector, false)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	result.Target = target.Namespace + "/" + target.Name
+	result.TargetIP = target.Status.PodIP
+
+	if agent := c.probeAgentFor(ctx, source.Spec.NodeName); agent != "" {
+		result.Via = "agent"
+		probed, err := c.probeAgents.Probe(ctx, agent, probe.Request{Method: method, Target: target.Status.PodIP, Port: check.Port, AllowNoReply: check.AllowNoReply})
+		if err != nil {
+			result.Error = err.Error()
+			return result, nil
+		}
+		result.Success, result.RTT, result.PacketLoss, result.Error = probed.Success, probed.RTT, probed.PacketLoss, probed.Error
+		return result, nil
+	}
+
+	result.Via = "exec"
+	if method == probe.MethodUDP {
+		result.Error = fmt.Sprintf("udp probes require a probe agent on node %s", source.Spec.NodeName)
+		return result, nil
+	}
+	var argv []string
+	switch method {
+	case probe.MethodTCP:
+		argv = []string{"nc", "-z", "-w", "5", target.Status.PodIP, strconv.Itoa(check.Port)}
+	case probe.MethodHTTP:
+		port := check.Port
+		if port == 0 {
+			port = 80
+		}
+		argv = []string{"curl", "-s", "-o", "/dev/null", "-w", "%{time_total}", "-m", "5", fmt.Sprintf("http://%s:%d", target.Status.PodIP, port)}
+	default:
+		argv = []string{"ping", "-c", "3", "-W", "5", target.Status.PodIP}
+	}
+	c.execConnectivity(ctx, source, argv, result)
+	return result, nil
+}
+
+// execConnectivity 在源Pod中执行探测命令并解析耗时（nc 没有耗时输出，按命令执行时间计算）
+func (c *Client) execConnectivity(ctx context.Context, source *corev1.Pod, argv []string, result *ConnectivityResult) {
+	tester := NewRTTTester(c)
+	start := time.Now()
+	output, err := tester.executeCommandInPod(ctx, source.Namespace, source.Name, argv)
+	elapsed := time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		if result.Method == probe.MethodPing {
+			result.PacketLoss = 100
+		}
+		return
+	}
+
+	var rtt models.RTTResult
+	switch argv[0] {
+	case "ping":
+		tester.parsePingOutput(output, &rtt)
+	case "curl":
+		tester.parseHTTPOutput(output, &rtt)
+	default:
+		rtt.Success = true
+		rtt.RTT = float64(elapsed.Microseconds()) / 1000
+	}
+	result.Success, result.RTT, result.PacketLoss = rtt.Success, rtt.RTT, rtt.PacketLoss
+	if !result.Success {
+		result.Error = fmt.Sprintf("%s returned no usable result", argv[0])
+	}
+}
+
+// selectPod 按名称顺序选出选择器匹配的第一个运行中且就绪的Pod（跳过探测Agent；作为源时跳过退出测试的Pod）
+func (c *Client) selectPod(ctx context.Context, namespace, selector string, asSource bool) (*corev1.Pod, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+		FieldSelector: "status.phase=Running",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", namespace, err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.PodIP == "" || !podReady(pod) || IsProbeAgent(pod) {
+			continue
+		}
+		if asSource && ProbeOptedOut(pod) {
+			continue
+		}
+		return pod, nil
+	}
+	return nil, fmt.Errorf("%w %q in namespace %s", ErrNoMatchingPod, selector, namespace)
+}
@@ -71,13 +71,26 @@ func (c *Client) convertServiceToModel(svc *corev1.Service) *models.ServiceInfo
 
 // convertEventToModel 将K8s Event对象转换为模型
 func (c *Client) convertEventToModel(event *corev1.Event) *models.EventInfo {
+	// 新版事件可能只设置EventTime
+	timestamp := event.LastTimestamp.Time
+	if timestamp.IsZero() {
+		timestamp = event.EventTime.Time
+	}
+	if timestamp.IsZero() {
+		timestamp = event.CreationTimestamp.Time
+	}
+
 	return &models.EventInfo{
-		Type:      event.Type,
-		Reason:    event.Reason,
-		Message:   event.Message,
-		Source:    event.Source.Component,
-		Timestamp: event.LastTimestamp.Time,
-		Count:     event.Count,
+		UID:        string(event.UID),
+		Namespace:  event.Namespace,
+		ObjectKind: event.InvolvedObject.Kind,
+		ObjectName: event.InvolvedObject.Name,
+		Type:       event.Type,
+		Reason:     event.Reason,
+		Message:    event.Message,
+		Source:     event.Source.Component,
+		Timestamp:  timestamp,
+		Count:      event.Count,
 	}
 }
 
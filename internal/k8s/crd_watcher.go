@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -22,7 +22,7 @@ type CRDWatcher struct {
 	client          *Client
 	dynamicClient   dynamic.Interface
 	crdClient       *apiextensionsv1client.Clientset
-	logger          *logrus.Logger
+	logger          *logrus.Entry
 	crdWatchers     map[schema.GroupVersionResource]watch.Interface
 	customResources map[string][]*models.CustomResourceInfo
 	eventHandler    EventHandler
@@ -128,7 +128,7 @@ func (cw *CRDWatcher) doWatchCRDs(ctx context.Context) {
 						Version:   "v1",
 						Name:      crd.Name,
 						Namespace: "",
-						Object:    map[string]interface{}{
+						Object: map[string]interface{}{
 							"crd": crdInfo,
 						},
 						Timestamp: time.Now(),
@@ -163,7 +163,7 @@ func (cw *CRDWatcher) doWatchCRDs(ctx context.Context) {
 						Version:   "v1",
 						Name:      crd.Name,
 						Namespace: "",
-						Object:    map[string]interface{}{
+						Object: map[string]interface{}{
 							"crd": crd.Name,
 						},
 						Timestamp: time.Now(),
@@ -348,7 +348,6 @@ func (cw *CRDWatcher) getStatusFromObject(obj map[string]interface{}) map[string
 	return make(map[string]interface{})
 }
 
-
 // updateCustomResourceCache 更新自定义资源缓存
 func (cw *CRDWatcher) updateCustomResourceCache(crd *models.CRDInfo, resource *models.CustomResourceInfo, eventType string) {
 	key := fmt.Sprintf("%s/%s/%s", crd.Group, crd.Kind, resource.Namespace)
@@ -405,4 +404,4 @@ func (cw *CRDWatcher) GetCustomResources(group, kind, namespace string) ([]*mode
 		return resources, nil
 	}
 	return []*models.CustomResourceInfo{}, nil
-}
\ No newline at end of file
+}
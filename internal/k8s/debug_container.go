@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// DebugContainerPrefix 临时调试容器的名称前缀，后接序号
+const DebugContainerPrefix = "k8s-llm-monitor-debug"
+
+// debugContainerStartTimeout 等待调试容器运行的最长时间（与调用方的超时无关）
+const debugContainerStartTimeout = 60 * time.Second
+
+// exitCommandNotFound shell约定的"命令不存在"退出码
+const exitCommandNotFound = 127
+
+type noDebugContainerKey struct{}
+
+// WithoutDebugContainer 禁止在该ctx下的exec中添加临时调试容器（只读的调用方使用，如LLM工具）：
+// 镜像中没有测试命令时直接返回错误，只复用已在运行的调试容器，不修改Pod
+func WithoutDebugContainer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDebugContainerKey{}, true)
+}
+
+// debugContainerAllowed ctx是否允许添加临时调试容器
+func debugContainerAllowed(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noDebugContainerKey{}).(bool)
+	return !disabled
+}
+
+// 临时容器添加后无法删除，调试容器以 sleep 保持运行，存活期内的测试复用同一个容器，
+// 过期退出后再添加新序号的容器
+
+// ensureDebugContainer 返回Pod中运行中的调试容器，没有时添加一个并等待其运行
+func (rt *RTTTester) ensureDebugContainer(ctx context.Context, namespace, podName string) (string, error) {
+	image, ttl, ok := rt.client.ExecPolicy().DebugContainer()
+	if !ok {
+		return "", fmt.Errorf("debug containers are disabled")
+	}
+	pods := rt.client.clientset.CoreV1().Pods(namespace)
+
+	for attempt := 0; ; attempt++ {
+		pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get pod info: %w", err)
+		}
+		name, pending := findDebugContainer(pod)
+		if name != "" && !pending {
+			return name, nil
+		}
+		if name == "" {
+			// 同一镜像拉取失败过时不再添加新容器（临时容器无法删除，重试只会在Pod中累积无法启动的容器）
+			if reason := debugImagePullFailure(pod, image); reason != "" {
+				return "", fmt.Errorf("debug container image %s cannot be pulled: %s", image, reason)
+			}
+			name = fmt.Sprintf("%s-%d", DebugContainerPrefix, countDebugContainers(pod)+1)
+			pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:                     name,
+					Image:                    image,
+					Command:                  []string{"sleep", strconv.Itoa(int(ttl / time.Second))},
+					TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+					SecurityContext: &corev1.SecurityContext{
+						// ping 需要发送ICMP
+						Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_RAW"}},
+					},
+				},
+			})
+			_, err = pods.UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{})
+			// 并发的测试可能同时为该Pod添加调试容器，冲突时重新读取
+			if apierrors.IsConflict(err) && attempt < 3 {
+				continue
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to add debug container to pod %s/%s: %w", namespace, podName, err)
+			}
+			rt.logger.Infof("Added debug container %s (%s) to pod %s/%s", name, image, namespace, podName)
+		}
+		return name, rt.waitDebugContainer(ctx, namespace, podName, name)
+	}
+}
+
+// waitDebugContainer 等待调试容器进入运行状态，最多等待 debugContainerStartTimeout；
+// 镜像拉取失败时立即返回错误
+func (rt *RTTTester) waitDebugContainer(ctx context.Context, namespace, podName, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, debugContainerStartTimeout)
+	defer cancel()
+
+	pods := rt.client.clientset.CoreV1().Pods(namespace)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod info: %w", err)
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != name {
+				continue
+			}
+			if status.State.Running != nil {
+				return nil
+			}
+			if terminated := status.State.Terminated; terminated != nil {
+				return fmt.Errorf("debug container %s exited: %s", name, terminated.Reason)
+			}
+			if waiting := status.State.Waiting; waiting != nil && imagePullFailed(waiting) {
+				return fmt.Errorf("debug container %s cannot start: %s: %s", name, waiting.Reason, waiting.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("debug container %s not running: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// findDebugContainer 查找Pod中可用的调试容器：优先返回运行中的容器，
+// 其次是尚未启动的容器（pending 为true），已退出和镜像拉取失败的容器不再使用
+func findDebugContainer(pod *corev1.Pod) (name string, pending bool) {
+	statuses := make(map[string]corev1.ContainerState, len(pod.Status.EphemeralContainerStatuses))
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		statuses[status.Name] = status.State
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if !strings.HasPrefix(container.Name, DebugContainerPrefix) {
+			continue
+		}
+		state, ok := statuses[container.Name]
+		switch {
+		case ok && state.Running != nil:
+			return container.Name, false
+		case !ok || (state.Waiting != nil && !imagePullFailed(state.Waiting)):
+			name, pending = container.Name, true
+		}
+	}
+	return name, pending
+}
+
+// imagePullFailed 判断等待中的容器是否因镜像拉取失败而无法启动
+func imagePullFailed(waiting *corev1.ContainerStateWaiting) bool {
+	switch waiting.Reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+		return true
+	}
+	return false
+}
+
+// debugImagePullFailure 返回使用指定镜像的调试容器拉取镜像失败的原因，没有时返回空字符串
+func debugImagePullFailure(pod *corev1.Pod, image string) string {
+	images := make(map[string]string, len(pod.Spec.EphemeralContainers))
+	for _, container := range pod.Spec.EphemeralContainers {
+		images[container.Name] = container.Image
+	}
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if !strings.HasPrefix(status.Name, DebugContainerPrefix) || images[status.Name] != image {
+			continue
+		}
+		if waiting := status.State.Waiting; waiting != nil && imagePullFailed(waiting) {
+			return waiting.Reason
+		}
+	}
+	return ""
+}
+
+// countDebugContainers 统计Pod中已添加过的调试容器数量（用于生成新容器的序号）
+func countDebugContainers(pod *corev1.Pod) int {
+	count := 0
+	for _, container := range pod.Spec.EphemeralContainers {
+		if strings.HasPrefix(container.Name, DebugContainerPrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// commandNotFound 判断exec失败是否因为容器镜像中没有该命令：
+// 退出码为127，或容器运行时报告 "executable file not found"
+func commandNotFound(err error) bool {
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitStatus() == exitCommandNotFound {
+		return true
+	}
+	return strings.Contains(err.Error(), "executable file not found")
+}
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func debugPod(statuses ...corev1.ContainerStatus) *corev1.Pod {
+	pod := &corev1.Pod{}
+	for _, status := range statuses {
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: status.Name, Image: "busybox:1.36"},
+		})
+		if status.State != (corev1.ContainerState{}) {
+			pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses, status)
+		}
+	}
+	return pod
+}
+
+func waitingStatus(name, reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+}
+
+func TestFindDebugContainer(t *testing.T) {
+	running := corev1.ContainerStatus{Name: DebugContainerPrefix + "-2", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+	exited := corev1.ContainerStatus{Name: DebugContainerPrefix + "-1", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}}
+
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		wantName    string
+		wantPending bool
+	}{
+		{name: "none", pod: debugPod()},
+		{name: "running preferred over exited", pod: debugPod(exited, running), wantName: running.Name},
+		{name: "exited", pod: debugPod(exited)},
+		{name: "no status yet", pod: debugPod(corev1.ContainerStatus{Name: DebugContainerPrefix + "-1"}), wantName: DebugContainerPrefix + "-1", wantPending: true},
+		{name: "creating", pod: debugPod(waitingStatus(DebugContainerPrefix+"-1", "ContainerCreating")), wantName: DebugContainerPrefix + "-1", wantPending: true},
+		{name: "image pull backoff", pod: debugPod(waitingStatus(DebugContainerPrefix+"-1", "ImagePullBackOff"))},
+		{name: "image pull error", pod: debugPod(waitingStatus(DebugContainerPrefix+"-1", "ErrImagePull"))},
+		{name: "other ephemeral container", pod: debugPod(corev1.ContainerStatus{Name: "debugger", State: running.State})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, pending := findDebugContainer(tt.pod)
+			if name != tt.wantName || pending != tt.wantPending {
+				t.Errorf("findDebugContainer() = %q, %v, want %q, %v", name, pending, tt.wantName, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestDebugImagePullFailure(t *testing.T) {
+	pod := debugPod(waitingStatus(DebugContainerPrefix+"-1", "ImagePullBackOff"))
+	if got := debugImagePullFailure(pod, "busybox:1.36"); got != "ImagePullBackOff" {
+		t.Errorf("debugImagePullFailure() = %q, want ImagePullBackOff", got)
+	}
+	// 修改镜像配置后允许用新镜像添加容器
+	if got := debugImagePullFailure(pod, "nicolaka/netshoot"); got != "" {
+		t.Errorf("debugImagePullFailure() with another image = %q, want empty", got)
+	}
+}
+
+func TestCommandNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "exit code 127", err: fmt.Errorf("command execution failed: %w, stderr: sh: ping: not found", utilexec.CodeExitError{Err: errors.New("command terminated with exit code 127"), Code: 127}), want: true},
+		{name: "runtime exec error", err: errors.New(`OCI runtime exec failed: exec failed: unable to start container process: exec: "ping": executable file not found in $PATH: unknown`), want: true},
+		{name: "exit code 1", err: utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1}},
+		{name: "missing file argument", err: fmt.Errorf("command execution failed: %w, stderr: cat: /etc/resolv.conf: no such file or directory", utilexec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1})},
+		{name: "connection error", err: errors.New("error dialing backend: dial tcp 10.0.0.1:10250: connect: connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandNotFound(tt.err); got != tt.want {
+				t.Errorf("commandNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutDebugContainer(t *testing.T) {
+	ctx := context.Background()
+	if !debugContainerAllowed(ctx) {
+		t.Error("debug containers should be allowed by default")
+	}
+	if debugContainerAllowed(WithoutDebugContainer(ctx)) {
+		t.Error("WithoutDebugContainer should disable debug containers")
+	}
+}
@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// DNS解析测试的限制
+const (
+	maxDNSServiceLookups = 3                      // 最多解析的目标Service数
+	slowDNSThreshold     = 100 * time.Millisecond // 超过该耗时视为解析缓慢（仅Agent测量的耗时）
+)
+
+// checkDNSResolution 从Pod A所在节点的探测Agent（没有时exec进入Pod A执行nslookup）解析目标Service的FQDN
+// （没有Service时解析 kubernetes.default）和外部域名，记录耗时以及NXDOMAIN/SERVFAIL等失败原因
+func (na *NetworkAnalyzer) checkDNSResolution(ctx context.Context, podA *models.PodInfo, analysis *models.CommunicationAnalysis) {
+	domain := "cluster.local"
+	var external string
+	if cfg := na.client.config; cfg != nil {
+		if cfg.ClusterDomain != "" {
+			domain = strings.Trim(cfg.ClusterDomain, ".")
+		}
+		external = cfg.DNSTestName
+	}
+
+	type lookup struct{ name, kind string }
+	var lookups []lookup
+	for _, svc := range analysis.Findings.TargetServices {
+		if len(lookups) == maxDNSServiceLookups {
+			break
+		}
+		namespace, name := parsePodName(svc)
+		lookups = append(lookups, lookup{fmt.Sprintf("%s.%s.svc.%s", name, namespace, domain), "service"})
+	}
+	if len(lookups) == 0 {
+		lookups = append(lookups, lookup{"kubernetes.default.svc." + domain, "cluster"})
+	}
+	if external != "" {
+		lookups = append(lookups, lookup{external, "external"})
+	}
+
+	agentIP := na.client.probeAgentFor(ctx, podA.NodeName)
+	for _, l := range lookups {
+		var result models.DNSLookup
+		if agentIP != "" {
+			result = na.lookupWithAgent(ctx, agentIP, l.name)
+		} else {
+			result = na.lookupWithExec(ctx, podA, l.name)
+		}
+		result.Kind = l.kind
+		analysis.Findings.DNSLookups = append(analysis.Findings.DNSLookups, result)
+	}
+
+	na.analyzeDNSLookups(analysis)
+}
+
+// lookupWithAgent 由探测Agent解析域名
+func (na *NetworkAnalyzer) lookupWithAgent(ctx context.Context, agentIP, name string) models.DNSLookup {
+	lookup := models.DNSLookup{Name: name, Source: "agent"}
+	result, err := na.client.probeAgents.Probe(ctx, agentIP, probe.Request{Method: probe.MethodDNS, Target: name, Count: 1})
+	if err != nil {
+		lookup.Error = err.Error()
+		return lookup
+	}
+	lookup.Success = result.Success
+	lookup.Latency = result.RTT
+	lookup.Addresses = result.Addresses
+	lookup.Rcode = result.Rcode
+	lookup.Error = result.Error
+	return lookup
+}
+
+// lookupWithExec 在Pod A内执行nslookup解析域名，耗时包含exec的开销
+func (na *NetworkAnalyzer) lookupWithExec(ctx context.Context, pod *models.PodInfo, name string) models.DNSLookup {
+	lookup := models.DNSLookup{Name: name, Source: "exec"}
+	start := time.Now()
+	output, err := na.rttTester.executeCommandInPod(ctx, pod.Namespace, pod.Name, []string{"nslookup", name})
+	lookup.Latency = float64(time.Since(start)) / float64(time.Millisecond)
+
+	lookup.Addresses, lookup.Rcode = parseNslookupOutput(output)
+	if lookup.Rcode == "" && err != nil {
+		lookup.Rcode = nslookupRcode(err.Error())
+	}
+	lookup.Success = err == nil && len(lookup.Addresses) > 0
+	if !lookup.Success {
+		if err != nil {
+			lookup.Error = err.Error()
+		} else {
+			lookup.Error = "no addresses returned"
+		}
+	}
+	return lookup
+}
+
+// analyzeDNSLookups 根据解析测试结果给出问题和建议
+func (na *NetworkAnalyzer) analyzeDNSLookups(analysis *models.CommunicationAnalysis) {
+	clusterOK := false
+	for _, lookup := range analysis.Findings.DNSLookups {
+		if lookup.Kind != "external" && lookup.Success {
+			clusterOK = true
+		}
+	}
+
+	for _, lookup := range analysis.Findings.DNSLookups {
+		if lookup.Success {
+			if lookup.Source == "agent" && lookup.Latency > float64(slowDNSThreshold/time.Millisecond) {
+				analysis.Issues = append(analysis.Issues,
+					fmt.Sprintf("DNS lookup of %s is slow (%.0fms)", lookup.Name, lookup.Latency))
+				analysis.Solutions = append(analysis.Solutions,
+					"Check CoreDNS load and replicas, and consider NodeLocal DNSCache")
+			}
+			continue
+		}
+		if lookup.Rcode == "" && (strings.Contains(lookup.Error, ErrCommandNotAllowed.Error()) ||
+			strings.Contains(lookup.Error, ErrExecDisabled.Error()) || strings.Contains(lookup.Error, ErrProbeOptOut.Error())) {
+			// 按exec策略无法测试，不视为DNS问题
+			analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("dns lookup %s: %s", lookup.Name, lookup.Error))
+			continue
+		}
+
+		reason := lookup.Rcode
+		if reason == "" {
+			reason = lookup.Error
+		}
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("DNS lookup of %s failed: %s", lookup.Name, reason))
+		switch {
+		case lookup.Kind == "service" && lookup.Rcode == probe.RcodeNXDomain:
+			analysis.Solutions = append(analysis.Solutions,
+				fmt.Sprintf("Service name %s does not resolve; check the Service exists and the cluster domain is correct", lookup.Name))
+		case lookup.Rcode == probe.RcodeServFail:
+			analysis.Solutions = append(analysis.Solutions, "CoreDNS returned SERVFAIL; check CoreDNS logs and its upstream/forward configuration")
+		case lookup.Kind == "external" && clusterOK:
+			analysis.Solutions = append(analysis.Solutions,
+				"Cluster names resolve but external names do not; check the CoreDNS forward plugin and egress to upstream DNS")
+		default:
+			analysis.Solutions = append(analysis.Solutions,
+				"Check that Pod A can reach the cluster DNS Service (kube-dns) and that no NetworkPolicy blocks UDP/TCP 53")
+		}
+	}
+}
+
+// parseNslookupOutput 解析nslookup的输出，返回解析到的地址以及失败原因（"Name:" 之前的 Address 为DNS服务器）
+func parseNslookupOutput(output string) ([]string, string) {
+	var addresses []string
+	answer := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			answer = true
+		case answer && strings.HasPrefix(line, "Address"):
+			// "Address: 10.96.0.1" 或 busybox 的 "Address 1: 10.96.0.1 kubernetes.default..."
+			if idx := strings.Index(line, ":"); idx >= 0 {
+				if fields := strings.Fields(line[idx+1:]); len(fields) > 0 {
+					addresses = append(addresses, fields[0])
+				}
+			}
+		}
+	}
+	return addresses, nslookupRcode(output)
+}
+
+// nslookupRcode 从nslookup的输出或错误信息中提取失败原因
+func nslookupRcode(text string) string {
+	switch {
+	case strings.Contains(text, "NXDOMAIN"):
+		return probe.RcodeNXDomain
+	case strings.Contains(text, "SERVFAIL"):
+		return probe.RcodeServFail
+	case strings.Contains(text, "timed out"):
+		return probe.RcodeTimeout
+	}
+	return ""
+}
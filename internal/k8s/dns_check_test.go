@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+)
+
+func TestParseNslookupOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		addresses []string
+		rcode     string
+	}{
+		{
+			name: "busybox success",
+			output: `Server:    10.96.0.10
+Address 1: 10.96.0.10 kube-dns.kube-system.svc.cluster.local
+
+Name:      kubernetes.default.svc.cluster.local
+Address 1: 10.96.0.1 kubernetes.default.svc.cluster.local
+`,
+			addresses: []string{"10.96.0.1"},
+		},
+		{
+			name: "busybox nxdomain",
+			output: `Server:		10.96.0.10
+Address:	10.96.0.10:53
+
+** server can't find missing.default.svc.cluster.local: NXDOMAIN
+`,
+			rcode: probe.RcodeNXDomain,
+		},
+		{
+			name: "bind success with multiple addresses",
+			output: `Server:		10.96.0.10
+Address:	10.96.0.10#53
+
+Non-authoritative answer:
+Name:	kubernetes.io
+Address: 15.197.167.90
+Name:	kubernetes.io
+Address: 3.33.186.135
+`,
+			addresses: []string{"15.197.167.90", "3.33.186.135"},
+		},
+		{
+			name: "bind servfail",
+			output: `Server:		10.96.0.10
+Address:	10.96.0.10#53
+
+** server can't find example.com: SERVFAIL
+`,
+			rcode: probe.RcodeServFail,
+		},
+		{
+			name:   "bind timeout",
+			output: ";; connection timed out; no servers could be reached\n",
+			rcode:  probe.RcodeTimeout,
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses, rcode := parseNslookupOutput(tt.output)
+			if !reflect.DeepEqual(addresses, tt.addresses) {
+				t.Errorf("addresses = %v, want %v", addresses, tt.addresses)
+			}
+			if rcode != tt.rcode {
+				t.Errorf("rcode = %q, want %q", rcode, tt.rcode)
+			}
+		})
+	}
+}
+
+func TestNslookupRcode(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"command terminated with exit code 1: ** server can't find foo: NXDOMAIN", probe.RcodeNXDomain},
+		{"** server can't find foo: SERVFAIL", probe.RcodeServFail},
+		{";; connection timed out; no servers could be reached", probe.RcodeTimeout},
+		{"command not allowed by exec policy: nslookup", ""},
+	}
+	for _, tt := range tests {
+		if got := nslookupRcode(tt.text); got != tt.want {
+			t.Errorf("nslookupRcode(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
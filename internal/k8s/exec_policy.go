@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ProbeOptOutAnnotation Pod注解，值为 "true" 时该Pod不会被用作网络测试的发起方；
+// 加在Namespace上时该命名空间的Pod不参与自动网络测试（既不作为发起方也不作为目标）
+const ProbeOptOutAnnotation = "k8s-llm-monitor.io/probe-opt-out"
+
+var (
+	// ErrExecDisabled 已全局关闭基于exec的测试
+	ErrExecDisabled = errors.New("exec-based testing is disabled")
+	// ErrCommandNotAllowed 命令不在允许列表中
+	ErrCommandNotAllowed = errors.New("command not allowed by exec policy")
+	// ErrProbeOptOut Pod通过注解退出了网络测试
+	ErrProbeOptOut = errors.New("pod opted out of probing")
+)
+
+// ExecPolicy 在Pod内执行命令的安全策略
+type ExecPolicy struct {
+	enabled bool
+	allowed map[string]bool
+
+	debugImage string        // 非空时应用容器缺少命令后改在临时调试容器中执行
+	debugTTL   time.Duration // 调试容器的存活时间
+}
+
+// NewExecPolicy 根据配置创建执行策略
+func NewExecPolicy(cfg config.ExecConfig) *ExecPolicy {
+	policy := &ExecPolicy{
+		enabled: cfg.Enabled,
+		allowed: make(map[string]bool, len(cfg.AllowedCommands)),
+	}
+	for _, command := range cfg.AllowedCommands {
+		if command = strings.TrimSpace(command); command != "" {
+			policy.allowed[command] = true
+		}
+	}
+	if debug := cfg.DebugContainer; debug.Enabled && debug.Image != "" {
+		policy.debugImage = debug.Image
+		policy.debugTTL = time.Duration(debug.TTL) * time.Second
+		if policy.debugTTL <= 0 {
+			policy.debugTTL = time.Hour
+		}
+	}
+	return policy
+}
+
+// DebugContainer 返回临时调试容器的镜像和存活时间，未启用时 ok 为false
+func (p *ExecPolicy) DebugContainer() (image string, ttl time.Duration, ok bool) {
+	if !p.Enabled() || p.debugImage == "" {
+		return "", 0, false
+	}
+	return p.debugImage, p.debugTTL, true
+}
+
+// Enabled 是否允许exec测试
+func (p *ExecPolicy) Enabled() bool {
+	return p != nil && p.enabled
+}
+
+// Check 检查命令是否允许执行（按可执行文件名匹配允许列表）
+func (p *ExecPolicy) Check(argv []string) error {
+	if !p.Enabled() {
+		return ErrExecDisabled
+	}
+	if len(argv) == 0 {
+		return fmt.Errorf("%w: empty command", ErrCommandNotAllowed)
+	}
+	if name := path.Base(argv[0]); !p.allowed[name] {
+		return fmt.Errorf("%w: %s", ErrCommandNotAllowed, name)
+	}
+	return nil
+}
+
+// CanProbeFrom 检查Pod是否可以作为网络测试的发起方
+func (p *ExecPolicy) CanProbeFrom(pod *corev1.Pod) error {
+	if !p.Enabled() {
+		return ErrExecDisabled
+	}
+	if ProbeOptedOut(pod) {
+		return fmt.Errorf("%w: %s/%s", ErrProbeOptOut, pod.Namespace, pod.Name)
+	}
+	return nil
+}
+
+// ProbeOptedOut 判断Pod是否通过注解退出了网络测试
+func ProbeOptedOut(pod *corev1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(pod.Annotations[ProbeOptOutAnnotation]), "true")
+}
+
+// NamespaceProbeOptedOut 判断命名空间是否通过注解退出了自动网络测试
+func NamespaceProbeOptedOut(namespace *corev1.Namespace) bool {
+	if namespace == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(namespace.Annotations[ProbeOptOutAnnotation]), "true")
+}
@@ -0,0 +1,527 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// 外部流量经由Ingress到后端Pod的链路上可能断开的环节，按流量经过的顺序
+const (
+	IngressHopAddress   = "address"   // Ingress或LoadBalancer没有分配外部地址
+	IngressHopRule      = "rule"      // 没有规则匹配host/path，也没有默认后端
+	IngressHopTLS       = "tls"       // TLS Secret不存在、证书过期或不覆盖host
+	IngressHopService   = "service"   // 后端Service或其端口不存在
+	IngressHopEndpoints = "endpoints" // 后端Service没有就绪的Endpoints
+	IngressHopExternal  = "external"  // 集群内检查都通过，但外部HTTP请求失败
+)
+
+// 外部HTTP探测的超时
+const externalProbeTimeout = 10 * time.Second
+
+// IngressCheck Ingress链路分析的参数，Ingress 与 Service 二选一
+type IngressCheck struct {
+	Namespace string
+	Ingress   string // Ingress名称
+	Service   string // LoadBalancer Service名称
+	Host      string // 为空时取第一条规则的host
+	Path      string // 默认 /
+	Probe     bool   // 向Ingress/LoadBalancer的外部地址发送HTTP请求（Host头和SNI为host）
+}
+
+// AnalyzeIngress 沿Ingress的host/path（或LoadBalancer Service）追踪到后端Service、Endpoints和Pod，
+// 检查外部地址、TLS Secret和后端健康状况，可选地从监控服务向外部地址发HTTP请求，报告第一个断开的环节。
+// Ingress或Service不存在时返回NotFound错误
+func (c *Client) AnalyzeIngress(ctx context.Context, check IngressCheck) (*models.IngressAnalysis, error) {
+	if check.Path == "" {
+		check.Path = "/"
+	}
+	result := &models.IngressAnalysis{
+		Host:      check.Host,
+		Path:      check.Path,
+		Addresses: []string{},
+		Issues:    []string{},
+		Solutions: []string{},
+	}
+
+	if check.Ingress != "" {
+		ing, err := c.clientset.NetworkingV1().Ingresses(check.Namespace).Get(ctx, check.Ingress, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if !c.traceIngress(ctx, ing, result) {
+			finishIngressAnalysis(result)
+			return result, nil
+		}
+	} else {
+		svc, err := c.clientset.CoreV1().Services(check.Namespace).Get(ctx, check.Service, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		result.Service = svc.Namespace + "/" + svc.Name
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			ingressBroken(result, IngressHopAddress,
+				fmt.Sprintf("service %s is of type %s, not LoadBalancer", result.Service, svc.Spec.Type),
+				fmt.Sprintf("Set the type of Service %s to LoadBalancer or expose it through an Ingress", result.Service))
+		}
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			result.Addresses = append(result.Addresses, firstNonEmpty(lb.IP, lb.Hostname))
+		}
+		if len(result.Addresses) == 0 && svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			ingressBroken(result, IngressHopAddress,
+				fmt.Sprintf("load balancer of service %s has no external address", result.Service),
+				"Check the cloud controller manager (or MetalLB) events on the Service; the load balancer was not provisioned")
+		}
+	}
+
+	svcPort := c.checkIngressBackend(ctx, result)
+
+	if check.Probe {
+		probeIngress(ctx, result, svcPort)
+	}
+	finishIngressAnalysis(result)
+	return result, nil
+}
+
+// traceIngress 检查Ingress的地址、匹配的规则和TLS，返回是否找到Service后端
+func (c *Client) traceIngress(ctx context.Context, ing *networkingv1.Ingress, result *models.IngressAnalysis) bool {
+	result.Ingress = ing.Namespace + "/" + ing.Name
+	if ing.Spec.IngressClassName != nil {
+		result.Class = *ing.Spec.IngressClassName
+	}
+
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		result.Addresses = append(result.Addresses, firstNonEmpty(lb.IP, lb.Hostname))
+	}
+	if len(result.Addresses) == 0 {
+		solution := "Check that an ingress controller is running and handles this Ingress"
+		if result.Class != "" {
+			solution = fmt.Sprintf("Check that the ingress controller for class %q is running and has published its address", result.Class)
+		}
+		ingressBroken(result, IngressHopAddress, fmt.Sprintf("ingress %s has no address", result.Ingress), solution)
+	}
+
+	if result.Host == "" {
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				result.Host = rule.Host
+				break
+			}
+		}
+	}
+
+	backend, rule := matchIngressRule(ing, result.Host, result.Path)
+	if backend == nil {
+		ingressBroken(result, IngressHopRule,
+			fmt.Sprintf("no rule of ingress %s matches %s%s and it has no default backend", result.Ingress, result.Host, result.Path),
+			fmt.Sprintf("Add a rule for host %q and path %q to Ingress %s", result.Host, result.Path, result.Ingress))
+		return false
+	}
+	result.Rule = rule
+
+	c.checkIngressTLS(ctx, ing, result)
+
+	if backend.Service == nil {
+		ingressBroken(result, IngressHopService,
+			fmt.Sprintf("rule %s routes to a resource backend, not a Service", rule),
+			"Resource backends are served by the ingress controller itself; check its documentation")
+		return false
+	}
+	result.Service = ing.Namespace + "/" + backend.Service.Name
+	if backend.Service.Port.Name != "" {
+		result.Port = backend.Service.Port.Name
+	} else {
+		result.Port = strconv.Itoa(int(backend.Service.Port.Number))
+	}
+	return true
+}
+
+// matchIngressRule 按Ingress的匹配规则查找后端：精确host优先于通配符host，再按路径最长匹配（长度相同时Exact优先），
+// 都不匹配时使用默认后端。返回后端和匹配规则的描述
+func matchIngressRule(ing *networkingv1.Ingress, host, path string) (*networkingv1.IngressBackend, string) {
+	var best *networkingv1.IngressBackend
+	var bestRule string
+	bestScore := [3]int{-1, -1, -1}
+
+	for _, rule := range ing.Spec.Rules {
+		hostScore := ingressHostScore(rule.Host, host)
+		if hostScore < 0 || rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			p := &rule.HTTP.Paths[i]
+			pathType := networkingv1.PathTypeImplementationSpecific
+			if p.PathType != nil {
+				pathType = *p.PathType
+			}
+			if !ingressPathMatches(pathType, p.Path, path) {
+				continue
+			}
+			exact := 0
+			if pathType == networkingv1.PathTypeExact {
+				exact = 1
+			}
+			score := [3]int{hostScore, len(p.Path), exact}
+			if compareScores(score, bestScore) > 0 {
+				best, bestScore = &p.Backend, score
+				bestRule = fmt.Sprintf("%s%s (%s)", rule.Host, p.Path, pathType)
+			}
+		}
+	}
+	if best == nil && ing.Spec.DefaultBackend != nil {
+		return ing.Spec.DefaultBackend, "default backend"
+	}
+	return best, bestRule
+}
+
+// ingressHostScore 规则host与请求host的匹配程度：精确匹配2，通配符1，规则没有host为0，不匹配为-1
+func ingressHostScore(ruleHost, host string) int {
+	switch {
+	case ruleHost == "":
+		return 0
+	case strings.EqualFold(ruleHost, host):
+		return 2
+	case strings.HasPrefix(ruleHost, "*."):
+		// 通配符只匹配一级标签：*.example.com 匹配 a.example.com，不匹配 a.b.example.com
+		suffix := ruleHost[1:]
+		if label, ok := strings.CutSuffix(strings.ToLower(host), strings.ToLower(suffix)); ok && label != "" && !strings.Contains(label, ".") {
+			return 1
+		}
+	}
+	return -1
+}
+
+// ingressPathMatches 按路径类型判断请求路径是否匹配。Prefix按 / 分隔的路径元素匹配，
+// ImplementationSpecific 按字符串前缀处理（与大多数控制器一致）
+func ingressPathMatches(pathType networkingv1.PathType, rulePath, path string) bool {
+	if rulePath == "" {
+		rulePath = "/"
+	}
+	switch pathType {
+	case networkingv1.PathTypeExact:
+		return path == rulePath
+	case networkingv1.PathTypePrefix:
+		prefix := strings.TrimSuffix(rulePath, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	default:
+		return strings.HasPrefix(path, rulePath)
+	}
+}
+
+func compareScores(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// checkIngressTLS 检查覆盖host的TLS配置：Secret是否存在、证书是否过期、SAN是否包含host。
+// 没有读取Secret的权限时只记录为未能完成的检查
+func (c *Client) checkIngressTLS(ctx context.Context, ing *networkingv1.Ingress, result *models.IngressAnalysis) {
+	var secretName string
+	found := false
+	for _, entry := range ing.Spec.TLS {
+		for _, host := range entry.Hosts {
+			if ingressHostScore(host, result.Host) > 0 {
+				secretName, found = entry.SecretName, true
+			}
+		}
+	}
+	// 没有secretName时由控制器使用默认证书
+	if !found || secretName == "" {
+		return
+	}
+
+	result.TLS = &models.IngressTLS{Secret: ing.Namespace + "/" + secretName}
+	secret, err := c.clientset.CoreV1().Secrets(ing.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		ingressBroken(result, IngressHopTLS,
+			fmt.Sprintf("TLS secret %s does not exist", result.TLS.Secret),
+			fmt.Sprintf("Create the TLS secret %s (if it is issued by cert-manager, check the Certificate and its CertificateRequest)", result.TLS.Secret))
+		return
+	case err != nil:
+		result.TLS.Unverifiable = true
+		result.CheckErrors = append(result.CheckErrors, fmt.Sprintf("tls secret %s: %v", result.TLS.Secret, err))
+		return
+	}
+	result.TLS.Present = true
+
+	certificate, err := parseTLSCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		result.TLS.Error = err.Error()
+		ingressBroken(result, IngressHopTLS,
+			fmt.Sprintf("TLS secret %s has no valid certificate: %v", result.TLS.Secret, err),
+			fmt.Sprintf("Store a PEM certificate under %s in secret %s", corev1.TLSCertKey, result.TLS.Secret))
+		return
+	}
+	notAfter := certificate.NotAfter.UTC()
+	result.TLS.NotAfter = &notAfter
+	result.TLS.DNSNames = certificate.DNSNames
+	result.TLS.CoversHost = result.Host == "" || certificate.VerifyHostname(result.Host) == nil
+
+	switch {
+	case time.Now().After(certificate.NotAfter):
+		ingressBroken(result, IngressHopTLS,
+			fmt.Sprintf("certificate in %s expired at %s", result.TLS.Secret, notAfter.Format(time.RFC3339)),
+			fmt.Sprintf("Renew the certificate in secret %s", result.TLS.Secret))
+	case !result.TLS.CoversHost:
+		ingressBroken(result, IngressHopTLS,
+			fmt.Sprintf("certificate in %s does not cover host %s (covers %s)", result.TLS.Secret, result.Host, strings.Join(certificate.DNSNames, ", ")),
+			fmt.Sprintf("Issue a certificate for %s or use a secret whose certificate covers it", result.Host))
+	}
+}
+
+// parseTLSCertificate 解析PEM中的第一个证书（叶子证书）
+func parseTLSCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// checkIngressBackend 检查后端Service、端口、Endpoints和选中的Pod，返回外部探测使用的Service端口
+func (c *Client) checkIngressBackend(ctx context.Context, result *models.IngressAnalysis) *corev1.ServicePort {
+	namespace, name := parsePodName(result.Service)
+	svc, err := c.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ingressBroken(result, IngressHopService,
+				fmt.Sprintf("backend service %s does not exist", result.Service),
+				fmt.Sprintf("Create Service %s or point the Ingress at an existing Service", result.Service))
+		} else {
+			result.CheckErrors = append(result.CheckErrors, fmt.Sprintf("service %s: %v", result.Service, err))
+		}
+		return nil
+	}
+
+	var port *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		p := &svc.Spec.Ports[i]
+		if result.Port == "" || p.Name == result.Port || strconv.Itoa(int(p.Port)) == result.Port {
+			port = p
+			break
+		}
+	}
+	if port == nil {
+		ingressBroken(result, IngressHopService,
+			fmt.Sprintf("service %s has no port %s", result.Service, result.Port),
+			fmt.Sprintf("Use one of the ports of Service %s in the Ingress backend", result.Service))
+		return nil
+	}
+	if result.Port == "" {
+		result.Port = strconv.Itoa(int(port.Port))
+	}
+
+	backends := &models.IngressBackend{TargetPort: port.TargetPort.String()}
+	result.Backends = backends
+	if err := c.countServiceEndpoints(ctx, svc, backends); err != nil {
+		result.CheckErrors = append(result.CheckErrors, fmt.Sprintf("endpoints %s: %v", result.Service, err))
+		return port
+	}
+
+	if len(svc.Spec.Selector) > 0 {
+		pods, err := c.clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+		})
+		if err != nil {
+			result.CheckErrors = append(result.CheckErrors, fmt.Sprintf("pods of %s: %v", result.Service, err))
+		} else {
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				var restarts int32
+				for _, status := range pod.Status.ContainerStatuses {
+					restarts += status.RestartCount
+				}
+				backends.Pods = append(backends.Pods, models.IngressBackendPod{
+					Name:     pod.Name,
+					Node:     pod.Spec.NodeName,
+					Phase:    string(pod.Status.Phase),
+					Ready:    podReady(pod),
+					Restarts: restarts,
+				})
+			}
+		}
+	}
+
+	if backends.ReadyEndpoints > 0 {
+		return port
+	}
+	switch {
+	case len(svc.Spec.Selector) > 0 && len(backends.Pods) == 0:
+		ingressBroken(result, IngressHopEndpoints,
+			fmt.Sprintf("no pods match the selector of service %s (%s)", result.Service, labels.SelectorFromSet(svc.Spec.Selector)),
+			fmt.Sprintf("Check that the workload behind Service %s is deployed and its pod labels match the selector", result.Service))
+	case len(backends.Pods) > 0:
+		ingressBroken(result, IngressHopEndpoints,
+			fmt.Sprintf("service %s has no ready endpoints (%d pods selected, none ready)", result.Service, len(backends.Pods)),
+			fmt.Sprintf("Check the readiness probes and logs of the pods behind Service %s", result.Service))
+	default:
+		ingressBroken(result, IngressHopEndpoints,
+			fmt.Sprintf("service %s has no ready endpoints", result.Service),
+			fmt.Sprintf("Service %s has no selector; check its manually managed Endpoints", result.Service))
+	}
+	return port
+}
+
+// countServiceEndpoints 统计Service就绪和未就绪的Endpoints（EndpointSlice列出失败时回退到Endpoints）
+func (c *Client) countServiceEndpoints(ctx context.Context, svc *corev1.Service, backends *models.IngressBackend) error {
+	slices, err := c.clientset.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: serviceNameLabel + "=" + svc.Name,
+	})
+	if err == nil {
+		for _, slice := range slices.Items {
+			for _, endpoint := range slice.Endpoints {
+				if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+					backends.ReadyEndpoints++
+				} else {
+					backends.NotReadyEndpoints++
+				}
+			}
+		}
+		return nil
+	}
+
+	endpoints, epErr := c.clientset.CoreV1().Endpoints(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+	if epErr != nil {
+		return fmt.Errorf("endpointslices: %v; endpoints: %v", err, epErr)
+	}
+	for _, subset := range endpoints.Subsets {
+		backends.ReadyEndpoints += len(subset.Addresses)
+		backends.NotReadyEndpoints += len(subset.NotReadyAddresses)
+	}
+	return nil
+}
+
+// probeIngress 向第一个外部地址发送HTTP GET（不跟随重定向），Host头和SNI使用分析的host。
+// 404表示请求没有匹配到规则（控制器的默认后端），5xx表示后端不可用，都视为断开
+func probeIngress(ctx context.Context, result *models.IngressAnalysis, svcPort *corev1.ServicePort) {
+	if len(result.Addresses) == 0 {
+		return
+	}
+
+	scheme, port := "http", ""
+	switch {
+	case result.Ingress != "":
+		if result.TLS != nil {
+			scheme = "https"
+		}
+	case svcPort != nil:
+		port = strconv.Itoa(int(svcPort.Port))
+		if svcPort.Port == 443 {
+			scheme = "https"
+		}
+	}
+	address := result.Addresses[0]
+	if port != "" {
+		address = net.JoinHostPort(address, port)
+	} else if strings.Contains(address, ":") {
+		address = "[" + address + "]"
+	}
+	probe := &models.ExternalProbe{URL: scheme + "://" + address + result.Path, Host: result.Host}
+	result.External = probe
+
+	ctx, cancel := context.WithTimeout(ctx, externalProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+	if err != nil {
+		probe.Error = err.Error()
+		ingressBroken(result, IngressHopExternal, fmt.Sprintf("invalid probe URL: %v", err), "")
+		return
+	}
+	if result.Host != "" {
+		req.Host = result.Host
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{ServerName: result.Host},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	defer client.CloseIdleConnections()
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	probe.Latency = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		probe.Error = err.Error()
+		ingressBroken(result, IngressHopExternal,
+			fmt.Sprintf("request to %s (host %s) failed: %v", probe.URL, result.Host, err),
+			"Check the load balancer, firewall rules and the ingress controller's service")
+		return
+	}
+	resp.Body.Close()
+	probe.StatusCode = resp.StatusCode
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		probe.Error = resp.Status
+		ingressBroken(result, IngressHopExternal,
+			fmt.Sprintf("%s returned 404; the request did not match an ingress rule", probe.URL),
+			"Check the ingress class and that the controller has loaded this Ingress")
+	case resp.StatusCode >= 500:
+		probe.Error = resp.Status
+		ingressBroken(result, IngressHopExternal,
+			fmt.Sprintf("%s returned %s", probe.URL, resp.Status),
+			fmt.Sprintf("Check the logs of the ingress controller and of the pods behind Service %s", result.Service))
+	default:
+		probe.Success = true
+	}
+}
+
+// ingressBroken 记录问题和建议，并在还没有断开的环节时记为第一个断开的环节
+func ingressBroken(result *models.IngressAnalysis, hop, detail, solution string) {
+	result.Issues = append(result.Issues, detail)
+	if solution != "" {
+		result.Solutions = append(result.Solutions, solution)
+	}
+	if result.BrokenHop == "" {
+		result.BrokenHop = hop
+		result.Detail = detail
+	}
+}
+
+// finishIngressAnalysis 确定链路状态：有断开的环节为 broken，外部请求成功为 reachable，否则为 configured
+func finishIngressAnalysis(result *models.IngressAnalysis) {
+	switch {
+	case result.BrokenHop != "":
+		result.Status = "broken"
+	case result.External != nil && result.External.Success:
+		result.Status = "reachable"
+	default:
+		result.Status = "configured"
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
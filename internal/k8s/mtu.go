@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrNoProbeAgent 源节点上没有就绪的探测Agent（或未启用探测Agent）
+var ErrNoProbeAgent = errors.New("no ready probe agent")
+
+// mtuProbeTimeout 每个长度的ping等待回复的时间，二分查找最多约14次
+const mtuProbeTimeout = time.Second
+
+// 各CNI默认封装方式的开销（字节）：VXLAN/Geneve 50，Calico默认IPIP 20，WireGuard 60
+var cniEncapOverhead = map[string]int{
+	CNICalico:  20,
+	CNICanal:   50,
+	CNIFlannel: 50,
+	CNICilium:  50,
+	CNIWeave:   50,
+	CNIAntrea:  50,
+}
+
+// MTUCheck 一次MTU诊断：源和目标同为节点（SourceNode/TargetNode）或同为Pod（namespace/name）
+type MTUCheck struct {
+	SourceNode string
+	TargetNode string
+	SourcePod  string
+	TargetPod  string
+	MaxMTU     int // 查找上限，默认1500
+}
+
+// DiagnoseMTU 由源节点上的探测Agent分别测量到目标（Pod IP，节点对时为目标节点上的Agent）的Pod网络路径
+// 和到目标节点 InternalIP 的路径MTU，超过路径MTU的包被静默丢弃时给出与CNI对应的解决建议
+func (c *Client) DiagnoseMTU(ctx context.Context, check MTUCheck) (*models.MTUDiagnosis, error) {
+	if check.MaxMTU == 0 {
+		check.MaxMTU = probe.DefaultMaxMTU
+	}
+	diagnosis := &models.MTUDiagnosis{Kind: "node", Source: check.SourceNode, Target: check.TargetNode, MaxMTU: check.MaxMTU, Timestamp: time.Now().UTC()}
+
+	var overlayTarget string
+	if check.SourcePod != "" {
+		diagnosis.Kind, diagnosis.Source, diagnosis.Target = "pod", check.SourcePod, check.TargetPod
+		source, err := c.getPodRef(ctx, check.SourcePod)
+		if err != nil {
+			return nil, fmt.Errorf("source: %w", err)
+		}
+		target, err := c.getPodRef(ctx, check.TargetPod)
+		if err != nil {
+			return nil, fmt.Errorf("target: %w", err)
+		}
+		if target.Status.PodIP == "" {
+			return nil, fmt.Errorf("target pod %s has no IP", check.TargetPod)
+		}
+		check.SourceNode, check.TargetNode, overlayTarget = source.Spec.NodeName, target.Spec.NodeName, target.Status.PodIP
+	}
+	diagnosis.SourceNode, diagnosis.TargetNode = check.SourceNode, check.TargetNode
+
+	agent := c.probeAgentFor(ctx, check.SourceNode)
+	if agent == "" {
+		return nil, fmt.Errorf("%w on node %s", ErrNoProbeAgent, check.SourceNode)
+	}
+	if overlayTarget == "" {
+		overlayTarget = c.probeAgentFor(ctx, check.TargetNode)
+	}
+
+	node, err := c.clientset.CoreV1().Nodes().Get(ctx, check.TargetNode, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("target node: %w", err)
+	}
+	underlayTarget := nodeInternalIP(node)
+
+	if overlayTarget != "" {
+		diagnosis.Overlay = c.probeMTU(ctx, agent, overlayTarget, check.MaxMTU)
+	}
+	if underlayTarget != "" {
+		diagnosis.Underlay = c.probeMTU(ctx, agent, underlayTarget, check.MaxMTU)
+	}
+	if env, err := c.DetectNetworkEnvironment(ctx); err == nil {
+		diagnosis.Environment = env
+	} else {
+		c.logger.Debugf("Failed to detect network environment for MTU diagnosis: %v", err)
+	}
+
+	diagnoseMTU(diagnosis)
+	return diagnosis, nil
+}
+
+// getPodRef 按 namespace/name 读取Pod
+func (c *Client) getPodRef(ctx context.Context, ref string) (*corev1.Pod, error) {
+	namespace, name := parsePodName(ref)
+	return c.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// probeMTU 让Agent测量到 target 的路径MTU
+func (c *Client) probeMTU(ctx context.Context, agent, target string, maxMTU int) *models.MTUPath {
+	path := &models.MTUPath{Target: target}
+	result, err := c.probeAgents.Probe(ctx, agent, probe.Request{Method: probe.MethodMTU, Target: target, MaxMTU: maxMTU, TimeoutMs: int(mtuProbeTimeout / time.Millisecond)})
+	if err != nil {
+		path.Error = err.Error()
+		return path
+	}
+	path.PathMTU, path.InterfaceMTU, path.ReportedMTU, path.Error = result.PathMTU, result.InterfaceMTU, result.ReportedMTU, result.Error
+	if !result.Success && path.Error == "" {
+		path.Error = "no reply"
+	}
+	return path
+}
+
+// nodeInternalIP 节点的 InternalIP，没有时为空
+func nodeInternalIP(node *corev1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// diagnoseMTU 根据两条路径的测量结果判断是否存在MTU黑洞，并给出解决建议
+func diagnoseMTU(d *models.MTUDiagnosis) {
+	d.Issues, d.Solutions = []string{}, []string{}
+	for _, path := range []*models.MTUPath{d.Overlay, d.Underlay} {
+		if path == nil || path.PathMTU == 0 {
+			continue
+		}
+		// 路径MTU小于网卡MTU却没有收到报告该MTU的ICMP需要分片消息：大包被静默丢弃
+		path.Blackhole = path.PathMTU < mtuLimit(path, d.MaxMTU) && path.ReportedMTU != path.PathMTU
+	}
+	if d.Overlay != nil && d.Underlay != nil && d.Overlay.PathMTU > 0 && d.Underlay.PathMTU > d.Overlay.PathMTU {
+		d.Overhead = d.Underlay.PathMTU - d.Overlay.PathMTU
+	}
+
+	overlayOK := d.Overlay != nil && d.Overlay.PathMTU > 0
+	underlayOK := d.Underlay != nil && d.Underlay.PathMTU > 0
+	switch {
+	case !overlayOK && !underlayOK:
+		d.Status = models.MTUUnreachable
+		d.Issues = append(d.Issues, fmt.Sprintf("%s is not reachable with a default-size ping from node %s", d.Target, d.SourceNode))
+		d.Solutions = append(d.Solutions, "Check basic connectivity (network policies, routes, firewalls) before diagnosing MTU")
+		return
+	case overlayOK && d.Overlay.Blackhole:
+		d.Status = models.MTUMismatch
+		d.Issues = append(d.Issues, fmt.Sprintf("Packets larger than %d bytes on the pod network from %s to %s are silently dropped although the interface sends %d (path MTU blackhole)",
+			d.Overlay.PathMTU, d.Source, d.Target, mtuLimit(d.Overlay, d.MaxMTU)))
+		d.Solutions = append(d.Solutions, overlayMTUSolution(d))
+		d.Solutions = append(d.Solutions, "Until the MTU is fixed, clamp TCP MSS to the path MTU (iptables -t mangle -A FORWARD -p tcp --tcp-flags SYN,RST SYN -j TCPMSS --clamp-mss-to-pmtu) and allow ICMP fragmentation-needed (type 3 code 4) between nodes")
+	case underlayOK && d.Underlay.Blackhole:
+		d.Status = models.MTUMismatch
+		d.Issues = append(d.Issues, fmt.Sprintf("Packets larger than %d bytes between nodes %s and %s are silently dropped although the interface sends %d (path MTU blackhole)",
+			d.Underlay.PathMTU, d.SourceNode, d.TargetNode, mtuLimit(d.Underlay, d.MaxMTU)))
+		d.Solutions = append(d.Solutions, fmt.Sprintf("Align the MTU of node NICs, switches and any VPN/tunnel between the nodes, or lower the node MTU to %d; make sure ICMP fragmentation-needed messages are not filtered", d.Underlay.PathMTU))
+	case (overlayOK && d.Overlay.PathMTU < d.MaxMTU) || (underlayOK && d.Underlay.PathMTU < d.MaxMTU):
+		d.Status = models.MTUReduced
+	default:
+		d.Status = models.MTUOK
+	}
+}
+
+// mtuLimit 路径上应能通过的最大包长：网卡MTU与查找上限中较小的一个
+func mtuLimit(path *models.MTUPath, maxMTU int) int {
+	if path.InterfaceMTU > 0 && path.InterfaceMTU < maxMTU {
+		return path.InterfaceMTU
+	}
+	return maxMTU
+}
+
+// overlayMTUSolution 按CNI给出调整Pod网络MTU的方法，建议值为测得的Pod网络路径MTU
+func overlayMTUSolution(d *models.MTUDiagnosis) string {
+	mtu := d.Overlay.PathMTU
+	cni := CNIUnknown
+	if d.Environment != nil {
+		cni = d.Environment.CNI
+	}
+	overhead, ok := cniEncapOverhead[cni]
+	if !ok {
+		overhead = 50
+	}
+	hint := ""
+	if d.Underlay != nil && d.Underlay.PathMTU > 0 {
+		hint = fmt.Sprintf(" (node path MTU %d minus about %d bytes of encapsulation overhead is %d)", d.Underlay.PathMTU, overhead, d.Underlay.PathMTU-overhead)
+	}
+
+	switch cni {
+	case CNICalico, CNICanal:
+		return fmt.Sprintf("Set the Calico MTU to %d%s: veth_mtu in the calico-config ConfigMap, or spec.calicoNetwork.mtu in the operator Installation, then restart calico-node", mtu, hint)
+	case CNICilium:
+		return fmt.Sprintf("Set mtu: \"%d\"%s in the cilium-config ConfigMap (Helm value MTU) and restart the Cilium agents", mtu, hint)
+	case CNIFlannel:
+		return fmt.Sprintf("Flannel derives the pod MTU from the node interface; the pod network only carries %d bytes%s. Check the node MTU and the FLANNEL_MTU in /run/flannel/subnet.env, then restart kube-flannel", mtu, hint)
+	case CNIWeave:
+		return fmt.Sprintf("Set WEAVE_MTU=%d%s on the weave-net DaemonSet", mtu, hint)
+	case CNIAntrea:
+		return fmt.Sprintf("Set defaultMTU: %d%s in antrea-agent.conf and restart antrea-agent", mtu, hint)
+	default:
+		return fmt.Sprintf("Lower the pod network MTU in the CNI configuration to %d%s and restart the CNI agents", mtu, hint)
+	}
+}
@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+func TestDiagnoseMTU(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlay  *models.MTUPath
+		underlay *models.MTUPath
+		cni      string
+		status   string
+		solution string
+	}{
+		{
+			name:     "overlay blackhole",
+			overlay:  &models.MTUPath{PathMTU: 1450, InterfaceMTU: 1500},
+			underlay: &models.MTUPath{PathMTU: 1500, InterfaceMTU: 1500},
+			cni:      CNICalico,
+			status:   models.MTUMismatch,
+			solution: "Set the Calico MTU to 1450",
+		},
+		{
+			name:     "interface sized for the overlay",
+			overlay:  &models.MTUPath{PathMTU: 1450, InterfaceMTU: 1450, ReportedMTU: 1450},
+			underlay: &models.MTUPath{PathMTU: 1450, InterfaceMTU: 1450, ReportedMTU: 1450},
+			cni:      CNIFlannel,
+			status:   models.MTUReduced,
+		},
+		{
+			name:     "icmp fragmentation needed",
+			overlay:  &models.MTUPath{PathMTU: 1400, InterfaceMTU: 1500, ReportedMTU: 1400},
+			underlay: &models.MTUPath{PathMTU: 1500, InterfaceMTU: 1500},
+			status:   models.MTUReduced,
+		},
+		{
+			name:     "underlay blackhole",
+			overlay:  &models.MTUPath{Error: "no reply"},
+			underlay: &models.MTUPath{PathMTU: 1400, InterfaceMTU: 1500},
+			status:   models.MTUMismatch,
+			solution: "Align the MTU of node NICs",
+		},
+		{
+			name:     "full size",
+			overlay:  &models.MTUPath{PathMTU: 1500, InterfaceMTU: 1500},
+			underlay: &models.MTUPath{PathMTU: 1500, InterfaceMTU: 9001},
+			status:   models.MTUOK,
+		},
+		{
+			name:     "unreachable",
+			overlay:  &models.MTUPath{Error: "no reply"},
+			status:   models.MTUUnreachable,
+			solution: "Check basic connectivity",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &models.MTUDiagnosis{Source: "default/a", Target: "default/b", SourceNode: "n1", TargetNode: "n2", MaxMTU: 1500, Overlay: tt.overlay, Underlay: tt.underlay}
+			if tt.cni != "" {
+				d.Environment = &models.NetworkEnvironment{CNI: tt.cni}
+			}
+			diagnoseMTU(d)
+			if d.Status != tt.status {
+				t.Fatalf("status = %s, want %s (issues %v)", d.Status, tt.status, d.Issues)
+			}
+			if tt.solution == "" {
+				if len(d.Issues) != 0 {
+					t.Errorf("unexpected issues %v", d.Issues)
+				}
+				return
+			}
+			if len(d.Solutions) == 0 || !strings.HasPrefix(d.Solutions[0], tt.solution) {
+				t.Errorf("solutions = %v, want one starting with %q", d.Solutions, tt.solution)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -15,7 +17,7 @@ import (
 // NetworkAnalyzer 网络分析器
 type NetworkAnalyzer struct {
 	client    *Client
-	logger    *logrus.Logger
+	logger    *logrus.Entry
 	rttTester *RTTTester
 	enableRTT bool
 }
@@ -55,12 +57,22 @@ func (na *NetworkAnalyzer) AnalyzePodCommunication(ctx context.Context, podA, po
 		Issues:     []string{},
 		Solutions:  []string{},
 		Confidence: 0.0,
+		Method:     "rules",
+		Findings: &models.CommunicationFindings{
+			PodA:            podAInfo,
+			PodB:            podBInfo,
+			NetworkPolicies: []*models.NetworkPolicyInfo{},
+			TargetServices:  []string{},
+		},
 	}
 
 	// 检查Pod状态
 	na.checkPodStatus(podAInfo, analysis)
 	na.checkPodStatus(podBInfo, analysis)
 
+	// 识别CNI和kube-proxy模式，网络策略检查需要知道CNI是否执行策略
+	na.checkNetworkEnvironment(ctx, analysis)
+
 	// 检查网络策略
 	na.checkNetworkPolicies(ctx, podAInfo, podBInfo, analysis)
 
@@ -69,12 +81,16 @@ func (na *NetworkAnalyzer) AnalyzePodCommunication(ctx context.Context, podA, po
 
 	// 检查DNS配置
 	na.checkDNSConnectivity(ctx, podAInfo, podBInfo, analysis)
+	na.checkDNSResolution(ctx, podAInfo, analysis)
 
 	// 执行RTT测试
 	if na.enableRTT {
 		na.checkRTTConnectivity(ctx, podA, podB, analysis)
 	}
 
+	// 有问题时补充与网络环境相关的排查建议
+	reportNetworkEnvironment(analysis)
+
 	// 确定最终状态
 	na.determineFinalStatus(analysis)
 
@@ -110,23 +126,46 @@ func (na *NetworkAnalyzer) checkPodStatus(pod *models.PodInfo, analysis *models.
 	}
 }
 
+// checkNetworkEnvironment 识别集群的CNI和kube-proxy模式
+func (na *NetworkAnalyzer) checkNetworkEnvironment(ctx context.Context, analysis *models.CommunicationAnalysis) {
+	env, err := na.client.DetectNetworkEnvironment(ctx)
+	if err != nil {
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("network environment: %v", err))
+		return
+	}
+	analysis.Findings.Environment = env
+}
+
+// reportNetworkEnvironment 存在问题时将网络环境的排查提示加入建议
+func reportNetworkEnvironment(analysis *models.CommunicationAnalysis) {
+	if env := analysis.Findings.Environment; env != nil && len(analysis.Issues) > 0 {
+		analysis.Solutions = append(analysis.Solutions, env.Notes...)
+	}
+}
+
 // checkNetworkPolicies 检查网络策略
 func (na *NetworkAnalyzer) checkNetworkPolicies(ctx context.Context, podA, podB *models.PodInfo, analysis *models.CommunicationAnalysis) {
 	// 获取两个Pod所在namespace的网络策略
 	policiesA, err := na.getNetworkPolicies(ctx, podA.Namespace)
 	if err != nil {
 		na.logger.Warnf("Failed to get network policies for namespace %s: %v", podA.Namespace, err)
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("network policies: %v", err))
 		return
 	}
 
-	policiesB, err := na.getNetworkPolicies(ctx, podB.Namespace)
-	if err != nil {
-		na.logger.Warnf("Failed to get network policies for namespace %s: %v", podB.Namespace, err)
-		return
+	policiesB := policiesA
+	if podB.Namespace != podA.Namespace {
+		policiesB, err = na.getNetworkPolicies(ctx, podB.Namespace)
+		if err != nil {
+			na.logger.Warnf("Failed to get network policies for namespace %s: %v", podB.Namespace, err)
+			analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("network policies: %v", err))
+			return
+		}
+		policiesB = append(policiesA, policiesB...)
 	}
 
 	// 检查网络策略是否阻止通信
-	na.analyzeNetworkPolicies(podA, podB, append(policiesA, policiesB...), analysis)
+	na.analyzeNetworkPolicies(podA, podB, policiesB, analysis)
 }
 
 // getNetworkPolicies 获取网络策略
@@ -152,41 +191,88 @@ func (na *NetworkAnalyzer) convertNetworkPolicyToModel(policy *networkingv1.Netw
 		Namespace:   policy.Namespace,
 		PodSelector: policy.Spec.PodSelector.MatchLabels,
 	}
+	for _, policyType := range policy.Spec.PolicyTypes {
+		policyInfo.PolicyTypes = append(policyInfo.PolicyTypes, string(policyType))
+	}
 
 	// 转换Ingress规则
 	for _, ingress := range policy.Spec.Ingress {
-		ingressRule := models.NetworkPolicyRule{}
-		for _, port := range ingress.Ports {
-			ingressRule.Ports = append(ingressRule.Ports, models.PortRule{
-				Protocol: string(*port.Protocol),
-				Port:     port.Port.IntVal,
-			})
-		}
-		policyInfo.Ingress = append(policyInfo.Ingress, ingressRule)
+		policyInfo.Ingress = append(policyInfo.Ingress, models.NetworkPolicyRule{
+			Ports: convertPolicyPorts(ingress.Ports),
+			From:  convertPolicyPeers(ingress.From),
+		})
 	}
 
 	// 转换Egress规则
 	for _, egress := range policy.Spec.Egress {
-		egressRule := models.NetworkPolicyRule{}
-		for _, port := range egress.Ports {
-			egressRule.Ports = append(egressRule.Ports, models.PortRule{
-				Protocol: string(*port.Protocol),
-				Port:     port.Port.IntVal,
-			})
-		}
-		policyInfo.Egress = append(policyInfo.Egress, egressRule)
+		policyInfo.Egress = append(policyInfo.Egress, models.NetworkPolicyRule{
+			Ports: convertPolicyPorts(egress.Ports),
+			To:    convertPolicyPeers(egress.To),
+		})
 	}
 
 	return policyInfo
 }
 
+// convertPolicyPorts 转换策略端口，未指定协议时为TCP，命名端口无法表示时忽略
+func convertPolicyPorts(ports []networkingv1.NetworkPolicyPort) []models.PortRule {
+	var rules []models.PortRule
+	for _, port := range ports {
+		rule := models.PortRule{Protocol: "TCP"}
+		if port.Protocol != nil {
+			rule.Protocol = string(*port.Protocol)
+		}
+		if port.Port != nil {
+			rule.Port = port.Port.IntVal
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// convertPolicyPeers 转换策略对等体
+func convertPolicyPeers(peers []networkingv1.NetworkPolicyPeer) []models.PeerRule {
+	var rules []models.PeerRule
+	for _, peer := range peers {
+		rule := models.PeerRule{}
+		if peer.PodSelector != nil {
+			rule.PodSelector = peer.PodSelector.MatchLabels
+			if rule.PodSelector == nil {
+				rule.PodSelector = map[string]string{}
+			}
+		}
+		if peer.NamespaceSelector != nil {
+			rule.NamespaceSelector = peer.NamespaceSelector.MatchLabels
+			if rule.NamespaceSelector == nil {
+				rule.NamespaceSelector = map[string]string{}
+			}
+		}
+		if peer.IPBlock != nil {
+			rule.IPBlock = peer.IPBlock.CIDR
+			if len(peer.IPBlock.Except) > 0 {
+				rule.IPBlock += " except " + strings.Join(peer.IPBlock.Except, ", ")
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
 // analyzeNetworkPolicies 分析网络策略
 func (na *NetworkAnalyzer) analyzeNetworkPolicies(podA, podB *models.PodInfo, policies []*models.NetworkPolicyInfo, analysis *models.CommunicationAnalysis) {
 	// 简化的网络策略检查
 	// 实际实现需要更复杂的逻辑来检查策略是否阻止通信
 
+	// CNI不执行NetworkPolicy（例如flannel）时策略不会阻止通信，只记录
+	env := analysis.Findings.Environment
+	enforced := env == nil || env.EnforcesNetworkPolicy == nil || *env.EnforcesNetworkPolicy
+
 	for _, policy := range policies {
 		if na.doesPolicyAffectPod(policy, podA) || na.doesPolicyAffectPod(policy, podB) {
+			analysis.Findings.NetworkPolicies = append(analysis.Findings.NetworkPolicies, policy)
+			if !enforced {
+				continue
+			}
 			analysis.Issues = append(analysis.Issues,
 				fmt.Sprintf("Network policy %s/%s may affect communication", policy.Namespace, policy.Name))
 			analysis.Solutions = append(analysis.Solutions,
@@ -213,34 +299,50 @@ func (na *NetworkAnalyzer) checkServiceConnectivity(ctx context.Context, podA, p
 	services, err := na.client.GetServices(podB.Namespace)
 	if err != nil {
 		na.logger.Warnf("Failed to get services for namespace %s: %v", podB.Namespace, err)
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("services: %v", err))
 		return
 	}
 
 	// 查找是否有Service指向Pod B
-	var targetService *models.ServiceInfo
 	for _, svc := range services {
 		if na.doesServiceTargetPod(svc, podB) {
-			targetService = svc
-			break
+			analysis.Findings.TargetServices = append(analysis.Findings.TargetServices, svc.Namespace+"/"+svc.Name)
 		}
 	}
 
-	if targetService == nil {
+	if len(analysis.Findings.TargetServices) == 0 {
+		// 选择器只差部分标签的Service很可能就是本应指向Pod B的Service
+		if mismatches := selectorMismatches(services, podB); len(mismatches) > 0 {
+			analysis.Findings.ServicePaths = append(analysis.Findings.ServicePaths, mismatches...)
+			for _, path := range mismatches {
+				analysis.Issues = append(analysis.Issues,
+					fmt.Sprintf("Service %s does not select Pod %s/%s: %s", path.Service, podB.Namespace, podB.Name, path.Detail))
+				analysis.Solutions = append(analysis.Solutions,
+					fmt.Sprintf("Align the selector of Service %s with the labels of Pod %s/%s", path.Service, podB.Namespace, podB.Name))
+			}
+			return
+		}
 		analysis.Issues = append(analysis.Issues,
 			fmt.Sprintf("No service found targeting Pod %s/%s", podB.Namespace, podB.Name))
 		analysis.Solutions = append(analysis.Solutions,
 			fmt.Sprintf("Create a service to expose Pod %s/%s", podB.Namespace, podB.Name))
+		return
 	}
+
+	na.checkServicePaths(ctx, podA, podB, analysis)
 }
 
-// doesServiceTargetPod 检查Service是否指向Pod
+// doesServiceTargetPod 检查Service是否指向Pod（选择器的所有标签都匹配）
 func (na *NetworkAnalyzer) doesServiceTargetPod(svc *models.ServiceInfo, pod *models.PodInfo) bool {
+	if len(svc.Selector) == 0 {
+		return false
+	}
 	for key, value := range svc.Selector {
-		if podValue, exists := pod.Labels[key]; exists && podValue == value {
-			return true
+		if podValue, exists := pod.Labels[key]; !exists || podValue != value {
+			return false
 		}
 	}
-	return false
+	return true
 }
 
 // checkDNSConnectivity 检查DNS连通性
@@ -249,6 +351,7 @@ func (na *NetworkAnalyzer) checkDNSConnectivity(ctx context.Context, podA, podB
 	coreDNSPods, err := na.client.GetPods("kube-system")
 	if err != nil {
 		na.logger.Warnf("Failed to get CoreDNS pods: %v", err)
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("coredns: %v", err))
 		return
 	}
 
@@ -260,6 +363,7 @@ func (na *NetworkAnalyzer) checkDNSConnectivity(ctx context.Context, podA, podB
 		}
 	}
 
+	analysis.Findings.CoreDNSRunning = &coreDNSRunning
 	if !coreDNSRunning {
 		analysis.Issues = append(analysis.Issues, "CoreDNS is not running properly")
 		analysis.Solutions = append(analysis.Solutions, "Check CoreDNS pods in kube-system namespace")
@@ -270,12 +374,21 @@ func (na *NetworkAnalyzer) checkDNSConnectivity(ctx context.Context, podA, podB
 func (na *NetworkAnalyzer) checkRTTConnectivity(ctx context.Context, podA, podB string, analysis *models.CommunicationAnalysis) {
 	// 执行RTT测试
 	result, err := na.rttTester.TestPodConnectivity(ctx, podA, podB)
+	if errors.Is(err, ErrExecDisabled) || errors.Is(err, ErrProbeOptOut) {
+		// 按安全策略跳过，不视为网络问题
+		na.logger.Infof("跳过RTT测试 %s -> %s: %v", podA, podB, err)
+		analysis.Findings.RTTSkipped = err.Error()
+		return
+	}
 	if err != nil {
+		analysis.Findings.RTTError = err.Error()
 		analysis.Issues = append(analysis.Issues, fmt.Sprintf("RTT测试失败: %v", err))
 		analysis.Solutions = append(analysis.Solutions, "检查Pod是否支持网络命令执行")
 		return
 	}
 
+	analysis.Findings.RTT = result
+
 	// 分析测试结果
 	if result.SuccessRate < 50 {
 		analysis.Issues = append(analysis.Issues, fmt.Sprintf("网络连通性差，成功率仅为%.1f%%", result.SuccessRate))
@@ -302,14 +415,26 @@ func (na *NetworkAnalyzer) checkRTTConnectivity(ctx context.Context, podA, podB
 		podA, podB, result.SuccessRate, result.AverageRTT, result.Latency)
 }
 
-// determineFinalStatus 确定最终状态
+// determineFinalStatus 确定最终状态。置信度取决于证据：RTT测试是直接证据，
+// 与规则结论一致时置信度高，不一致或缺少RTT测试时较低；未能完成的检查会降低置信度
 func (na *NetworkAnalyzer) determineFinalStatus(analysis *models.CommunicationAnalysis) {
 	if len(analysis.Issues) == 0 {
 		analysis.Status = "connected"
-		analysis.Confidence = 0.9
 		analysis.Solutions = append(analysis.Solutions, "No obvious issues detected")
 	} else {
 		analysis.Status = "disconnected"
-		analysis.Confidence = 0.7
 	}
+
+	findings := analysis.Findings
+	confidence := 0.5
+	if rtt := findings.RTT; rtt != nil {
+		reachable := rtt.SuccessRate >= 50
+		if reachable == (analysis.Status == "connected") {
+			confidence = 0.9
+		} else {
+			confidence = 0.4
+		}
+	}
+	confidence -= 0.1 * float64(len(findings.CheckErrors))
+	analysis.Confidence = math.Max(confidence, 0.1)
 }
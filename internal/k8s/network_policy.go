@@ -0,0 +1,177 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrWorkloadNotFound 找不到指定的工作负载
+var ErrWorkloadNotFound = errors.New("workload not found")
+
+// WorkloadKinds 支持的工作负载类型（未指定类型时按此顺序查找）
+var WorkloadKinds = []string{"deployment", "statefulset", "daemonset", "pod"}
+
+// ParseWorkloadRef 解析 [kind/][namespace/]name 形式的工作负载引用，kind 为空表示按 WorkloadKinds 顺序查找
+func ParseWorkloadRef(ref string) (kind, namespace, name string, err error) {
+	parts := strings.Split(strings.TrimSpace(ref), "/")
+	switch len(parts) {
+	case 1:
+		namespace, name = "default", parts[0]
+	case 2:
+		namespace, name = parts[0], parts[1]
+	case 3:
+		kind, namespace, name = strings.ToLower(parts[0]), parts[1], parts[2]
+		known := false
+		for _, k := range WorkloadKinds {
+			known = known || k == kind
+		}
+		if !known {
+			return "", "", "", fmt.Errorf("unsupported workload kind %q (supported: %s)", parts[0], strings.Join(WorkloadKinds, ", "))
+		}
+	default:
+		return "", "", "", fmt.Errorf("workload must be in the form [kind/][namespace/]name")
+	}
+	if namespace == "" || name == "" {
+		return "", "", "", fmt.Errorf("workload must be in the form [kind/][namespace/]name")
+	}
+	return kind, namespace, name, nil
+}
+
+// PolicyContext 读取两个工作负载的Pod标签、端口以及所在命名空间的网络策略，
+// 并找出已经隔离源出站流量和目标入站流量的策略
+func (na *NetworkAnalyzer) PolicyContext(ctx context.Context, source, destination string) (*models.NetworkPolicyContext, error) {
+	src, err := na.getWorkload(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	dst, err := na.getWorkload(ctx, destination)
+	if err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+
+	result := &models.NetworkPolicyContext{
+		Source:                     src,
+		Destination:                dst,
+		Policies:                   []*models.NetworkPolicyInfo{},
+		SourceEgressPolicies:       []string{},
+		DestinationIngressPolicies: []string{},
+	}
+	namespaces := []string{src.Namespace}
+	if dst.Namespace != src.Namespace {
+		namespaces = append(namespaces, dst.Namespace)
+	}
+	for _, namespace := range namespaces {
+		policies, err := na.client.clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network policies in %s: %w", namespace, err)
+		}
+		for i := range policies.Items {
+			policy := &policies.Items[i]
+			result.Policies = append(result.Policies, na.convertNetworkPolicyToModel(policy))
+			ref := policy.Namespace + "/" + policy.Name
+			if policyIsolates(policy, src, networkingv1.PolicyTypeEgress) {
+				result.SourceEgressPolicies = append(result.SourceEgressPolicies, ref)
+			}
+			if policyIsolates(policy, dst, networkingv1.PolicyTypeIngress) {
+				result.DestinationIngressPolicies = append(result.DestinationIngressPolicies, ref)
+			}
+		}
+	}
+	return result, nil
+}
+
+// policyIsolates 策略是否选中工作负载的Pod并限制指定方向的流量
+func policyIsolates(policy *networkingv1.NetworkPolicy, workload *models.WorkloadInfo, direction networkingv1.PolicyType) bool {
+	return policySelects(policy, policyEndpoint{namespace: workload.Namespace, labels: workload.PodLabels}, direction)
+}
+
+// getWorkload 按引用查找工作负载，读取其Pod模板的标签和端口以及命名空间标签
+func (na *NetworkAnalyzer) getWorkload(ctx context.Context, ref string) (*models.WorkloadInfo, error) {
+	kind, namespace, name, err := ParseWorkloadRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	kinds := WorkloadKinds
+	if kind != "" {
+		kinds = []string{kind}
+	}
+
+	var workload *models.WorkloadInfo
+	for _, k := range kinds {
+		workload, err = na.lookupWorkload(ctx, k, namespace, name)
+		if err == nil {
+			break
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get %s %s/%s: %w", k, namespace, name, err)
+		}
+	}
+	if workload == nil {
+		return nil, fmt.Errorf("%w: %s", ErrWorkloadNotFound, ref)
+	}
+
+	if ns, err := na.client.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+		workload.NamespaceLabels = ns.Labels
+	} else {
+		na.logger.Debugf("Failed to get labels of namespace %s: %v", namespace, err)
+	}
+	return workload, nil
+}
+
+// lookupWorkload 读取指定类型的工作负载
+func (na *NetworkAnalyzer) lookupWorkload(ctx context.Context, kind, namespace, name string) (*models.WorkloadInfo, error) {
+	apps := na.client.clientset.AppsV1()
+	var template corev1.PodTemplateSpec
+	switch kind {
+	case "deployment":
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		kind, template = "Deployment", deployment.Spec.Template
+	case "statefulset":
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		kind, template = "StatefulSet", statefulSet.Spec.Template
+	case "daemonset":
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		kind, template = "DaemonSet", daemonSet.Spec.Template
+	default:
+		pod, err := na.client.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		kind, template = "Pod", corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec}
+	}
+
+	workload := &models.WorkloadInfo{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		PodLabels: template.Labels,
+	}
+	for _, container := range template.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := string(port.Protocol)
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			workload.Ports = append(workload.Ports, models.PortRule{Protocol: protocol, Port: port.ContainerPort})
+		}
+	}
+	return workload, nil
+}
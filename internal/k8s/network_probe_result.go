@@ -0,0 +1,191 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// networkProbeResultGVR NetworkProbeResult自定义资源
+var networkProbeResultGVR = schema.GroupVersionResource{
+	Group:    "monitoring.io",
+	Version:  "v1",
+	Resource: "networkproberesults",
+}
+
+// NetworkProbeResultSelector 服务端导出的NetworkProbeResult的标签选择器，其他控制器按此列出
+const NetworkProbeResultSelector = "monitoring.io/component=network-probe"
+
+// maxNetworkProbeResultName 资源名的最大长度（DNS子域名）
+const maxNetworkProbeResultName = 253
+
+// UpsertNetworkProbeResult 用Pod对的网络测试结果创建或更新NetworkProbeResult自定义资源，
+// 每个Pod对（有方向）一个资源，供调度器等其他控制器读取连通性数据
+func (c *Client) UpsertNetworkProbeResult(ctx context.Context, namespace string, metric *metricstypes.NetworkMetrics) error {
+	if c.dynamic == nil {
+		return fmt.Errorf("dynamic client not initialized")
+	}
+	if metric == nil || metric.SourcePod == "" || metric.TargetPod == "" {
+		return fmt.Errorf("network metric missing source or target pod")
+	}
+	if namespace == "" {
+		namespace = c.config.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+
+	obj := networkProbeResultObject(namespace, metric, time.Now())
+	resourceName := obj.GetName()
+	resource := c.dynamic.Resource(networkProbeResultGVR).Namespace(namespace)
+
+	existing, err := resource.Get(ctx, resourceName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if _, createErr := resource.Create(ctx, obj, metav1.CreateOptions{}); createErr != nil {
+				return fmt.Errorf("failed to create NetworkProbeResult %s: %w", resourceName, createErr)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to get NetworkProbeResult %s: %w", resourceName, err)
+	}
+
+	existing.Object["spec"] = obj.Object["spec"]
+	existing.Object["status"] = obj.Object["status"]
+	labels := existing.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for key, value := range obj.GetLabels() {
+		labels[key] = value
+	}
+	existing.SetLabels(labels)
+
+	if _, err = resource.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update NetworkProbeResult %s: %w", resourceName, err)
+	}
+	return nil
+}
+
+// PruneNetworkProbeResults 删除 before 之前没有更新过的NetworkProbeResult（Pod对不再被测试或Pod已删除），返回删除的数量
+func (c *Client) PruneNetworkProbeResults(ctx context.Context, namespace string, before time.Time) (int, error) {
+	if c.dynamic == nil {
+		return 0, fmt.Errorf("dynamic client not initialized")
+	}
+	if namespace == "" {
+		namespace = c.config.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+
+	resource := c.dynamic.Resource(networkProbeResultGVR).Namespace(namespace)
+	list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: NetworkProbeResultSelector})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list NetworkProbeResults: %w", err)
+	}
+
+	deleted := 0
+	for i := range list.Items {
+		item := &list.Items[i]
+		if !networkProbeResultExpired(item, before) {
+			continue
+		}
+		if err := resource.Delete(ctx, item.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return deleted, fmt.Errorf("failed to delete NetworkProbeResult %s: %w", item.GetName(), err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// networkProbeResultObject 由网络测试结果构建NetworkProbeResult资源
+func networkProbeResultObject(namespace string, metric *metricstypes.NetworkMetrics, now time.Time) *unstructured.Unstructured {
+	tested := metric.Timestamp
+	if tested.IsZero() {
+		tested = now
+	}
+
+	status := map[string]interface{}{
+		"connected":     metric.Connected,
+		"rtt_ms":        metric.RTT,
+		"packet_loss":   metric.PacketLoss,
+		"jitter_ms":     metric.Jitter,
+		"quality_score": metric.QualityScore,
+		"test_method":   metric.TestMethod,
+		"last_tested":   tested.UTC().Format(time.RFC3339),
+		"last_update":   now.UTC().Format(time.RFC3339),
+	}
+	if metric.Bandwidth > 0 {
+		status["bandwidth_mbps"] = metric.Bandwidth
+	}
+	if metric.DNSLatency > 0 {
+		status["dns_latency_ms"] = metric.DNSLatency
+	}
+	if metric.ProbeSource != "" {
+		status["probe_source"] = metric.ProbeSource
+	}
+	if metric.Error != "" {
+		status["error"] = metric.Error
+	}
+
+	sourceNamespace, _ := parsePodName(metric.SourcePod)
+	targetNamespace, _ := parsePodName(metric.TargetPod)
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "monitoring.io/v1",
+			"kind":       "NetworkProbeResult",
+			"metadata": map[string]interface{}{
+				"name":      networkProbeResultName(metric.SourcePod, metric.TargetPod),
+				"namespace": namespace,
+				"labels": map[string]interface{}{
+					"monitoring.io/component":        "network-probe",
+					"monitoring.io/source-namespace": sourceNamespace,
+					"monitoring.io/target-namespace": targetNamespace,
+				},
+			},
+			"spec": map[string]interface{}{
+				"source_pod": metric.SourcePod,
+				"target_pod": metric.TargetPod,
+			},
+			"status": status,
+		},
+	}
+}
+
+// networkProbeResultName Pod对的资源名：可读的 npr-<源>--<目标> 加上Pod对的哈希（不同Pod对替换字符后可能相同），
+// 过长时截断可读部分
+func networkProbeResultName(sourcePod, targetPod string) string {
+	readable := func(ref string) string {
+		return sanitizeResourceName(strings.ReplaceAll(ref, "/", "-"))
+	}
+	sum := sha256.Sum256([]byte(sourcePod + "|" + targetPod))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+
+	name := "npr-" + readable(sourcePod) + "--" + readable(targetPod)
+	if len(name)+len(suffix) > maxNetworkProbeResultName {
+		name = strings.TrimRight(name[:maxNetworkProbeResultName-len(suffix)], "-")
+	}
+	return name + suffix
+}
+
+// networkProbeResultExpired 资源的 status.last_update 早于 before（没有或无法解析时按创建时间）
+func networkProbeResultExpired(obj *unstructured.Unstructured, before time.Time) bool {
+	updated := obj.GetCreationTimestamp().Time
+	if value, ok, _ := unstructured.NestedString(obj.Object, "status", "last_update"); ok {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			updated = parsed
+		}
+	}
+	return updated.Before(before)
+}
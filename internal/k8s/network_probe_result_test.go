@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestNetworkProbeResultName(t *testing.T) {
+	name := networkProbeResultName("shop/web-1", "payments/api-0")
+	if !strings.HasPrefix(name, "npr-shop-web-1--payments-api-0-") {
+		t.Errorf("name = %q, want a readable prefix", name)
+	}
+	if name != networkProbeResultName("shop/web-1", "payments/api-0") {
+		t.Error("name is not deterministic")
+	}
+	// 替换 / 后可读部分相同的Pod对，以及反方向的Pod对都需要不同的资源
+	for _, other := range []string{networkProbeResultName("shop-web/1", "payments/api-0"), networkProbeResultName("payments/api-0", "shop/web-1")} {
+		if other == name {
+			t.Errorf("different pod pairs share the name %q", name)
+		}
+	}
+
+	long := networkProbeResultName("ns/"+strings.Repeat("a", 200), "ns/"+strings.Repeat("b", 200))
+	if len(long) > maxNetworkProbeResultName {
+		t.Errorf("name length = %d", len(long))
+	}
+	for _, n := range []string{name, long} {
+		if errs := validation.IsDNS1123Subdomain(n); len(errs) > 0 {
+			t.Errorf("%q is not a valid resource name: %v", n, errs)
+		}
+	}
+}
+
+func TestNetworkProbeResultObject(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	metric := &metricstypes.NetworkMetrics{
+		SourcePod: "shop/web-1", TargetPod: "payments/api-0", Timestamp: now.Add(-time.Minute),
+		Connected: true, RTT: 1.5, Jitter: 0.2, QualityScore: 98.3, TestMethod: "ping", ProbeSource: "agent",
+	}
+
+	obj := networkProbeResultObject("monitoring", metric, now)
+	if obj.GetNamespace() != "monitoring" || obj.GetKind() != "NetworkProbeResult" {
+		t.Errorf("namespace = %q, kind = %q", obj.GetNamespace(), obj.GetKind())
+	}
+	labels := obj.GetLabels()
+	if labels["monitoring.io/source-namespace"] != "shop" || labels["monitoring.io/target-namespace"] != "payments" {
+		t.Errorf("labels = %v", labels)
+	}
+	if connected, _, _ := unstructured.NestedBool(obj.Object, "status", "connected"); !connected {
+		t.Error("status.connected = false")
+	}
+	if tested, _, _ := unstructured.NestedString(obj.Object, "status", "last_tested"); tested != "2026-10-16T11:59:00Z" {
+		t.Errorf("status.last_tested = %q", tested)
+	}
+	if _, ok, _ := unstructured.NestedFieldNoCopy(obj.Object, "status", "bandwidth_mbps"); ok {
+		t.Error("bandwidth should be omitted when not measured")
+	}
+
+	if networkProbeResultExpired(obj, now.Add(-time.Hour)) {
+		t.Error("a just-updated result must not expire")
+	}
+	if !networkProbeResultExpired(obj, now.Add(time.Second)) {
+		t.Error("a result updated before the cutoff must expire")
+	}
+	// 没有 last_update 时按创建时间判断
+	created := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	created.SetCreationTimestamp(metav1.NewTime(now))
+	if networkProbeResultExpired(created, now.Add(-time.Minute)) {
+		t.Error("result without last_update should fall back to the creation time")
+	}
+}
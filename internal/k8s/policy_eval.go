@@ -0,0 +1,194 @@
+package k8s
+
+import (
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// policyEndpoint 网络策略评估中的一端（Pod）
+type policyEndpoint struct {
+	namespace       string
+	namespaceLabels map[string]string
+	labels          map[string]string
+	ip              string
+	ports           map[string]int32 // 容器声明的命名端口，用于匹配策略中的命名端口
+}
+
+// policyVerdict 一次评估的结论，Policies 为拦截流量的隔离策略（namespace/name）
+type policyVerdict struct {
+	Allowed  bool
+	Policies []string
+}
+
+// evaluatePolicies 按NetworkPolicy语义判断从 src 到 dst 的流量是否放行：源的出站和目标的入站都需要放行，
+// 某一方向没有选中该Pod的策略时不隔离。port 为0时任一端口放行即视为放行
+func evaluatePolicies(policies []networkingv1.NetworkPolicy, src, dst policyEndpoint, port int32, protocol corev1.Protocol) policyVerdict {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	egressAllowed, egressPolicies := evaluateDirection(policies, src, dst, networkingv1.PolicyTypeEgress, port, protocol)
+	ingressAllowed, ingressPolicies := evaluateDirection(policies, dst, src, networkingv1.PolicyTypeIngress, port, protocol)
+
+	verdict := policyVerdict{Allowed: egressAllowed && ingressAllowed}
+	if !egressAllowed {
+		verdict.Policies = append(verdict.Policies, egressPolicies...)
+	}
+	if !ingressAllowed {
+		verdict.Policies = append(verdict.Policies, ingressPolicies...)
+	}
+	return verdict
+}
+
+// evaluateDirection 评估 subject 一个方向的隔离：返回是否放行与 peer 之间的流量，以及隔离 subject 的策略
+func evaluateDirection(policies []networkingv1.NetworkPolicy, subject, peer policyEndpoint, direction networkingv1.PolicyType, port int32, protocol corev1.Protocol) (bool, []string) {
+	var isolating []string
+	for i := range policies {
+		policy := &policies[i]
+		if !policySelects(policy, subject, direction) {
+			continue
+		}
+		isolating = append(isolating, policy.Namespace+"/"+policy.Name)
+
+		// 端口总是目标Pod的端口：入站时为 subject，出站时为对端
+		if direction == networkingv1.PolicyTypeIngress {
+			for _, rule := range policy.Spec.Ingress {
+				if peersMatch(rule.From, policy.Namespace, peer) && portsMatch(rule.Ports, subject, port, protocol) {
+					return true, nil
+				}
+			}
+		} else {
+			for _, rule := range policy.Spec.Egress {
+				if peersMatch(rule.To, policy.Namespace, peer) && portsMatch(rule.Ports, peer, port, protocol) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return len(isolating) == 0, isolating
+}
+
+// policySelects 策略是否选中该Pod并隔离指定方向
+func policySelects(policy *networkingv1.NetworkPolicy, endpoint policyEndpoint, direction networkingv1.PolicyType) bool {
+	if policy.Namespace != endpoint.namespace {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil || !selector.Matches(labels.Set(endpoint.labels)) {
+		return false
+	}
+	for _, t := range policyTypes(policy) {
+		if t == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// policyTypes 策略隔离的方向：未设置时总是隔离入站，有出站规则时隔离出站
+func policyTypes(policy *networkingv1.NetworkPolicy) []networkingv1.PolicyType {
+	if len(policy.Spec.PolicyTypes) > 0 {
+		return policy.Spec.PolicyTypes
+	}
+	types := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(policy.Spec.Egress) > 0 {
+		types = append(types, networkingv1.PolicyTypeEgress)
+	}
+	return types
+}
+
+// peersMatch 规则的对等体是否包含该Pod，列表为空时匹配所有来源/目的
+func peersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, endpoint policyEndpoint) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peerMatches(peer, policyNamespace, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerMatches 单个对等体：只有podSelector时限于策略所在命名空间，只有namespaceSelector时为匹配命名空间中的所有Pod，
+// 两者都有时为匹配命名空间中匹配的Pod；ipBlock按Pod IP匹配
+func peerMatches(peer networkingv1.NetworkPolicyPeer, policyNamespace string, endpoint policyEndpoint) bool {
+	if peer.IPBlock != nil {
+		return ipBlockMatches(peer.IPBlock, endpoint.ip)
+	}
+	if peer.PodSelector == nil && peer.NamespaceSelector == nil {
+		return false
+	}
+
+	if peer.NamespaceSelector == nil {
+		if endpoint.namespace != policyNamespace {
+			return false
+		}
+	} else {
+		selector, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+		if err != nil || !selector.Matches(labels.Set(endpoint.namespaceLabels)) {
+			return false
+		}
+	}
+	if peer.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(peer.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(endpoint.labels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ipBlockMatches IP是否在CIDR内且不在except中
+func ipBlockMatches(block *networkingv1.IPBlock, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(addr) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, excluded, err := net.ParseCIDR(except); err == nil && excluded.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch 规则的端口是否包含该端口，列表为空或 port 为0时匹配
+func portsMatch(ports []networkingv1.NetworkPolicyPort, target policyEndpoint, port int32, protocol corev1.Protocol) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, rule := range ports {
+		ruleProtocol := corev1.ProtocolTCP
+		if rule.Protocol != nil {
+			ruleProtocol = *rule.Protocol
+		}
+		if port != 0 && ruleProtocol != protocol {
+			continue
+		}
+		if port == 0 || rule.Port == nil {
+			return true
+		}
+		if rule.Port.StrVal != "" {
+			if number, ok := target.ports[rule.Port.StrVal]; ok && number == port {
+				return true
+			}
+			continue
+		}
+		end := rule.Port.IntVal
+		if rule.EndPort != nil {
+			end = *rule.EndPort
+		}
+		if port >= rule.Port.IntVal && port <= end {
+			return true
+		}
+	}
+	return false
+}
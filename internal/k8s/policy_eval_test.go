@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func netpol(namespace, name string, selector map[string]string, spec networkingv1.NetworkPolicySpec) networkingv1.NetworkPolicy {
+	spec.PodSelector = metav1.LabelSelector{MatchLabels: selector}
+	return networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Spec: spec}
+}
+
+func tcpPort(port intstr.IntOrString) networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	web := policyEndpoint{namespace: "shop", namespaceLabels: map[string]string{"team": "shop"}, labels: map[string]string{"app": "web"}, ip: "10.0.1.5"}
+	api := policyEndpoint{namespace: "shop", namespaceLabels: map[string]string{"team": "shop"}, labels: map[string]string{"app": "api"}, ip: "10.0.1.6", ports: map[string]int32{"http": 8080}}
+	batch := policyEndpoint{namespace: "jobs", namespaceLabels: map[string]string{"team": "data"}, labels: map[string]string{"app": "batch"}, ip: "10.0.2.7"}
+
+	denyAll := netpol("shop", "default-deny", nil, networkingv1.NetworkPolicySpec{})
+	allowWeb := netpol("shop", "api-from-web", map[string]string{"app": "api"}, networkingv1.NetworkPolicySpec{
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}},
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(intstr.FromString("http"))},
+		}},
+	})
+	allowData := netpol("shop", "api-from-data", map[string]string{"app": "api"}, networkingv1.NetworkPolicySpec{
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}}}},
+		}},
+	})
+	egressDeny := netpol("jobs", "egress-dns-only", nil, networkingv1.NetworkPolicySpec{
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		Egress: []networkingv1.NetworkPolicyEgressRule{{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.1.0/24"}}}},
+		}},
+	})
+
+	tests := []struct {
+		name     string
+		policies []networkingv1.NetworkPolicy
+		src, dst policyEndpoint
+		port     int32
+		allowed  bool
+		blocking []string
+	}{
+		{"no policies", nil, web, api, 0, true, nil},
+		{"default deny", []networkingv1.NetworkPolicy{denyAll}, web, api, 0, false, []string{"shop/default-deny"}},
+		{"named port allowed", []networkingv1.NetworkPolicy{denyAll, allowWeb}, web, api, 8080, true, nil},
+		{"other port blocked", []networkingv1.NetworkPolicy{denyAll, allowWeb}, web, api, 9090, false, []string{"shop/default-deny", "shop/api-from-web"}},
+		{"pod selector is namespace local", []networkingv1.NetworkPolicy{allowWeb}, policyEndpoint{namespace: "other", labels: map[string]string{"app": "web"}}, api, 0, false, []string{"shop/api-from-web"}},
+		{"namespace selector", []networkingv1.NetworkPolicy{allowWeb, allowData}, batch, api, 0, true, nil},
+		{"egress ip block except", []networkingv1.NetworkPolicy{egressDeny}, batch, api, 0, false, []string{"jobs/egress-dns-only"}},
+		{"egress ip block allowed", []networkingv1.NetworkPolicy{egressDeny}, batch, policyEndpoint{namespace: "shop", ip: "10.0.3.1"}, 0, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := evaluatePolicies(tt.policies, tt.src, tt.dst, tt.port, "")
+			if verdict.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v", verdict.Allowed, tt.allowed)
+			}
+			if !reflect.DeepEqual(verdict.Policies, tt.blocking) {
+				t.Errorf("blocking policies = %v, want %v", verdict.Policies, tt.blocking)
+			}
+		})
+	}
+}
+
+func TestBuildReachabilityMatrix(t *testing.T) {
+	pod := func(namespace, name string, labels map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+	}
+	pods := []corev1.Pod{
+		pod("shop", "web-1", map[string]string{"app": "web"}),
+		pod("shop", "web-2", map[string]string{"app": "web"}), // 与web-1标签相同，去重
+		pod("shop", "api-1", map[string]string{"app": "api"}),
+		pod("jobs", "batch-1", map[string]string{"app": "batch"}),
+	}
+	pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "node-agent"}, Spec: corev1.PodSpec{HostNetwork: true}})
+	policies := []networkingv1.NetworkPolicy{
+		// shop只允许命名空间内的入站
+		netpol("shop", "same-namespace", nil, networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}}},
+		}),
+	}
+
+	matrix := buildReachabilityMatrix([]string{"shop", "jobs", "empty"}, nil, pods, policies, 0, "")
+
+	want := [][]string{
+		{models.ReachabilityAllowed, models.ReachabilityAllowed, models.ReachabilityEmpty},
+		{models.ReachabilityBlocked, models.ReachabilityAllowed, models.ReachabilityEmpty},
+		{models.ReachabilityEmpty, models.ReachabilityEmpty, models.ReachabilityEmpty},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if got := matrix.Cells[i][j].Status; got != want[i][j] {
+				t.Errorf("%s -> %s = %s, want %s", matrix.Namespaces[i], matrix.Namespaces[j], got, want[i][j])
+			}
+		}
+	}
+	if cell := matrix.Cells[0][0]; cell.Allowed != 4 {
+		t.Errorf("shop -> shop evaluated %d pairs, want 4 after deduplicating web pods", cell.Allowed)
+	}
+	if cell := matrix.Cells[1][0]; cell.Blocked != 2 || !reflect.DeepEqual(cell.Policies, []string{"shop/same-namespace"}) {
+		t.Errorf("jobs -> shop = %+v, want 2 blocked pairs by shop/same-namespace", cell)
+	}
+}
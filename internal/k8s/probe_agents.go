@@ -0,0 +1,191 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// 探测Agent DaemonSet的名称和标签
+const (
+	ProbeAgentName     = "k8s-llm-monitor-probe"
+	ProbeAgentLabel    = "app.kubernetes.io/name"
+	ProbeAgentSelector = ProbeAgentLabel + "=" + ProbeAgentName
+	probeTokenKey      = "token"
+)
+
+// ProbeAgentSpec 由服务端管理的探测Agent DaemonSet
+type ProbeAgentSpec struct {
+	Namespace string
+	Image     string
+	Port      int
+	Token     string // 非空时写入同名Secret，Agent通过环境变量读取
+}
+
+// EnsureProbeAgents 创建或更新探测Agent的DaemonSet（以及保存token的Secret）
+func (c *Client) EnsureProbeAgents(ctx context.Context, spec ProbeAgentSpec) error {
+	if spec.Port <= 0 {
+		spec.Port = probe.DefaultPort
+	}
+	labels := map[string]string{ProbeAgentLabel: ProbeAgentName}
+
+	env := []corev1.EnvVar{
+		{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		{Name: "PROBE_PORT", Value: strconv.Itoa(spec.Port)},
+	}
+	if spec.Token != "" {
+		if err := c.ensureProbeTokenSecret(ctx, spec.Namespace, spec.Token, labels); err != nil {
+			return err
+		}
+		env = append(env, corev1.EnvVar{Name: "PROBE_TOKEN", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ProbeAgentName},
+				Key:                  probeTokenKey,
+			},
+		}})
+	}
+
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: ProbeAgentName, Namespace: spec.Namespace, Labels: labels},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					// Agent本身不作为网络测试的对象
+					Annotations: map[string]string{ProbeOptOutAnnotation: "true"},
+				},
+				Spec: corev1.PodSpec{
+					Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:  "probe-agent",
+						Image: spec.Image,
+						Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: int32(spec.Port)}},
+						Env:   env,
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+								Path: "/health",
+								Port: intstr.FromString("http"),
+							}},
+							PeriodSeconds: 10,
+						},
+						SecurityContext: &corev1.SecurityContext{
+							// Agent镜像中的ping需要发送ICMP
+							Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_RAW"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	daemonSets := c.clientset.AppsV1().DaemonSets(spec.Namespace)
+	existing, err := daemonSets.Get(ctx, ProbeAgentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := daemonSets.Create(ctx, daemonSet, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create probe agent daemonset: %w", err)
+		}
+		c.logger.Infof("Created probe agent daemonset %s/%s", spec.Namespace, ProbeAgentName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get probe agent daemonset: %w", err)
+	}
+
+	existing.Labels = daemonSet.Labels
+	existing.Spec.Template = daemonSet.Spec.Template
+	if _, err := daemonSets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update probe agent daemonset: %w", err)
+	}
+	c.logger.Infof("Updated probe agent daemonset %s/%s", spec.Namespace, ProbeAgentName)
+	return nil
+}
+
+// ensureProbeTokenSecret 创建或更新保存Agent token的Secret
+func (c *Client) ensureProbeTokenSecret(ctx context.Context, namespace, token string, labels map[string]string) error {
+	secrets := c.clientset.CoreV1().Secrets(namespace)
+	existing, err := secrets.Get(ctx, ProbeAgentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ProbeAgentName, Namespace: namespace, Labels: labels},
+			StringData: map[string]string{probeTokenKey: token},
+		}
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create probe agent secret: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get probe agent secret: %w", err)
+	}
+	if string(existing.Data[probeTokenKey]) == token {
+		return nil
+	}
+	existing.StringData = map[string]string{probeTokenKey: token}
+	if _, err := secrets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update probe agent secret: %w", err)
+	}
+	return nil
+}
+
+// ProbeAgents 列出就绪的探测Agent，返回 节点名 -> Agent Pod IP
+func (c *Client) ProbeAgents(ctx context.Context, namespace string) (map[string]string, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: ProbeAgentSelector,
+		FieldSelector: "status.phase=Running",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list probe agents: %w", err)
+	}
+
+	agents := make(map[string]string, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.PodIP == "" || !podReady(pod) {
+			continue
+		}
+		agents[pod.Spec.NodeName] = pod.Status.PodIP
+	}
+	return agents, nil
+}
+
+// SetProbeAgents 设置访问探测Agent的客户端，通信分析的DNS测试优先由源Pod所在节点的Agent进行
+func (c *Client) SetProbeAgents(namespace string, agents *probe.Client) {
+	c.probeNamespace = namespace
+	c.probeAgents = agents
+}
+
+// probeAgentFor 返回节点上就绪的探测Agent IP，未配置或没有时为空
+func (c *Client) probeAgentFor(ctx context.Context, node string) string {
+	if c.probeAgents == nil || node == "" {
+		return ""
+	}
+	agents, err := c.ProbeAgents(ctx, c.probeNamespace)
+	if err != nil {
+		c.logger.Warnf("Failed to list probe agents: %v", err)
+		return ""
+	}
+	return agents[node]
+}
+
+// IsProbeAgent 判断Pod是否为探测Agent（网络测试选择Pod对时跳过）
+func IsProbeAgent(pod *corev1.Pod) bool {
+	return pod != nil && pod.Labels[ProbeAgentLabel] == ProbeAgentName
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxCellPolicies 矩阵每格最多列出的拦截策略数
+const maxCellPolicies = 10
+
+// PolicyReachability 读取各命名空间的标签、Pod和NetworkPolicy，按策略计算命名空间间的N×N可达性矩阵，
+// 不发送实际流量。port 为0时任一端口放行即视为可达
+func (c *Client) PolicyReachability(ctx context.Context, namespaces []string, port int32, protocol string) (*models.ReachabilityMatrix, error) {
+	namespaceLabels := make(map[string]map[string]string, len(namespaces))
+	var pods []corev1.Pod
+	var policies []networkingv1.NetworkPolicy
+	for _, namespace := range namespaces {
+		ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+		namespaceLabels[namespace] = ns.Labels
+
+		podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods in %s: %w", namespace, err)
+		}
+		pods = append(pods, podList.Items...)
+
+		policyList, err := c.clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network policies in %s: %w", namespace, err)
+		}
+		policies = append(policies, policyList.Items...)
+	}
+
+	matrix := buildReachabilityMatrix(namespaces, namespaceLabels, pods, policies, port, corev1.Protocol(protocol))
+
+	// CNI不执行NetworkPolicy时矩阵只反映配置，附上网络环境供判断
+	if env, err := c.DetectNetworkEnvironment(ctx); err == nil {
+		matrix.Environment = env
+	} else {
+		c.logger.Debugf("Failed to detect network environment for reachability matrix: %v", err)
+	}
+	return matrix, nil
+}
+
+// buildReachabilityMatrix 将每个命名空间中的Pod按标签和命名端口去重，两两评估策略并汇总到命名空间对
+func buildReachabilityMatrix(namespaces []string, namespaceLabels map[string]map[string]string, pods []corev1.Pod, policies []networkingv1.NetworkPolicy, port int32, protocol corev1.Protocol) *models.ReachabilityMatrix {
+	groups := reachabilityEndpoints(pods, namespaceLabels)
+
+	matrix := &models.ReachabilityMatrix{
+		Namespaces: namespaces,
+		Port:       port,
+		Cells:      make([][]models.ReachabilityCell, len(namespaces)),
+		Timestamp:  time.Now().UTC(),
+	}
+	if port != 0 {
+		matrix.Protocol = string(protocol)
+		if matrix.Protocol == "" {
+			matrix.Protocol = string(corev1.ProtocolTCP)
+		}
+	}
+
+	for i, from := range namespaces {
+		matrix.Cells[i] = make([]models.ReachabilityCell, len(namespaces))
+		for j, to := range namespaces {
+			cell := &matrix.Cells[i][j]
+			blocking := make(map[string]bool)
+			for _, src := range groups[from] {
+				for _, dst := range groups[to] {
+					verdict := evaluatePolicies(policies, src, dst, port, protocol)
+					if verdict.Allowed {
+						cell.Allowed++
+						continue
+					}
+					cell.Blocked++
+					for _, policy := range verdict.Policies {
+						blocking[policy] = true
+					}
+				}
+			}
+			cell.Status = reachabilityStatus(cell.Allowed, cell.Blocked)
+			for policy := range blocking {
+				cell.Policies = append(cell.Policies, policy)
+			}
+			sort.Strings(cell.Policies)
+			if len(cell.Policies) > maxCellPolicies {
+				cell.Policies = cell.Policies[:maxCellPolicies]
+			}
+		}
+	}
+	return matrix
+}
+
+// reachabilityEndpoints 按命名空间列出去重后的Pod（标签和命名端口相同的Pod评估结果相同），
+// 跳过已结束的Pod和hostNetwork Pod（NetworkPolicy不作用于hostNetwork Pod）
+func reachabilityEndpoints(pods []corev1.Pod, namespaceLabels map[string]map[string]string) map[string][]policyEndpoint {
+	result := make(map[string][]policyEndpoint)
+	seen := make(map[string]bool)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.HostNetwork || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		endpoint := policyEndpoint{
+			namespace:       pod.Namespace,
+			namespaceLabels: namespaceLabels[pod.Namespace],
+			labels:          pod.Labels,
+			ip:              pod.Status.PodIP,
+			ports:           make(map[string]int32),
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name != "" {
+					endpoint.ports[port.Name] = port.ContainerPort
+				}
+			}
+		}
+
+		key := endpointKey(endpoint)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result[pod.Namespace] = append(result[pod.Namespace], endpoint)
+	}
+	return result
+}
+
+// endpointKey 命名空间、标签和命名端口组成的去重键（ipBlock按IP匹配的策略不常用于集群内Pod，去重时不考虑IP）
+func endpointKey(endpoint policyEndpoint) string {
+	parts := []string{endpoint.namespace}
+	for key, value := range endpoint.labels {
+		parts = append(parts, "l:"+key+"="+value)
+	}
+	for name, number := range endpoint.ports {
+		parts = append(parts, fmt.Sprintf("p:%s=%d", name, number))
+	}
+	sort.Strings(parts[1:])
+	return strings.Join(parts, ",")
+}
+
+// reachabilityStatus 汇总一对命名空间的结论
+func reachabilityStatus(allowed, blocked int) string {
+	switch {
+	case allowed == 0 && blocked == 0:
+		return models.ReachabilityEmpty
+	case blocked == 0:
+		return models.ReachabilityAllowed
+	case allowed == 0:
+		return models.ReachabilityBlocked
+	default:
+		return models.ReachabilityPartial
+	}
+}
@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// dryRunOptions dryRun 为 true 时由API Server校验请求但不实际修改资源
+func dryRunOptions(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// WatchesNamespace 判断namespace是否在监控范围内
+func (c *Client) WatchesNamespace(namespace string) bool {
+	for _, ns := range c.namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletePod 删除Pod（由控制器重建，相当于重启）
+func (c *Client) DeletePod(ctx context.Context, namespace, name string, dryRun bool) error {
+	err := c.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunOptions(dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to delete pod %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// ScaleDeployment 修改Deployment副本数，返回修改前的副本数
+func (c *Client) ScaleDeployment(ctx context.Context, namespace, name string, replicas int32, dryRun bool) (int32, error) {
+	deployments := c.clientset.AppsV1().Deployments(namespace)
+	scale, err := deployments.GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get scale of deployment %s/%s: %w", namespace, name, err)
+	}
+	previous := scale.Spec.Replicas
+
+	update := &autoscalingv1.Scale{
+		ObjectMeta: scale.ObjectMeta,
+		Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+	}
+	if _, err := deployments.UpdateScale(ctx, name, update, metav1.UpdateOptions{DryRun: dryRunOptions(dryRun)}); err != nil {
+		return previous, fmt.Errorf("failed to scale deployment %s/%s: %w", namespace, name, err)
+	}
+	return previous, nil
+}
+
+// CordonNode 将节点标记为不可调度
+func (c *Client) CordonNode(ctx context.Context, name string, dryRun bool) error {
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	_, err := c.clientset.CoreV1().Nodes().Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOptions(dryRun)})
+	if err != nil {
+		return fmt.Errorf("failed to cordon node %s: %w", name, err)
+	}
+	return nil
+}
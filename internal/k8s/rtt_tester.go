@@ -1,7 +1,6 @@
 package k8s
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"strconv"
@@ -9,6 +8,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
 	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,7 +28,7 @@ func NewNetworkTestResult(podA, podB string) *models.NetworkTestResult {
 // RTTTester RTT测试器
 type RTTTester struct {
 	client *Client
-	logger *logrus.Logger
+	logger *logrus.Entry
 }
 
 // NewRTTTester 创建新的RTT测试器
@@ -39,29 +39,65 @@ func NewRTTTester(client *Client) *RTTTester {
 	}
 }
 
+// RTTOptions 指定Pod对测试的方法和次数，零值为默认测试（双向ping，Pod B像HTTP服务时加HTTP测试）
+type RTTOptions struct {
+	Methods  []string // ping、tcp、http，ping只从Pod A发起
+	Count    int      // ping次数，默认3
+	Port     int      // tcp的目标端口
+	HTTPPort int      // http的目标端口，默认80
+}
+
 // TestPodConnectivity 测试Pod间连通性和RTT
+// 只从未通过注解退出测试的Pod发起命令；两个Pod都退出时返回 ErrProbeOptOut
 func (rt *RTTTester) TestPodConnectivity(ctx context.Context, podA, podB string) (*models.NetworkTestResult, error) {
+	return rt.TestPodConnectivityWithOptions(ctx, podA, podB, RTTOptions{})
+}
+
+// TestPodConnectivityWithOptions 按指定的方法测试Pod间连通性。指定了方法时只从Pod A发起，Pod A退出测试时返回 ErrProbeOptOut
+func (rt *RTTTester) TestPodConnectivityWithOptions(ctx context.Context, podA, podB string, opts RTTOptions) (*models.NetworkTestResult, error) {
+	policy := rt.client.ExecPolicy()
+	if !policy.Enabled() {
+		return nil, ErrExecDisabled
+	}
+
 	// 解析Pod名称
 	podANamespace, podAName := parsePodName(podA)
 	podBNamespace, podBName := parsePodName(podB)
 
 	// 获取Pod信息
-	podAInfo, err := rt.getPodInfo(ctx, podANamespace, podAName)
+	rawA, err := rt.client.clientset.CoreV1().Pods(podANamespace).Get(ctx, podAName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod A info: %w", err)
 	}
 
-	podBInfo, err := rt.getPodInfo(ctx, podBNamespace, podBName)
+	rawB, err := rt.client.clientset.CoreV1().Pods(podBNamespace).Get(ctx, podBName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod B info: %w", err)
 	}
 
+	probeA := policy.CanProbeFrom(rawA) == nil
+	probeB := policy.CanProbeFrom(rawB) == nil
+	if !probeA && (!probeB || len(opts.Methods) > 0) {
+		return nil, fmt.Errorf("%w: %s and %s", ErrProbeOptOut, podA, podB)
+	}
+
+	podAInfo := rt.client.convertPodToModel(rawA)
+	podBInfo := rt.client.convertPodToModel(rawB)
+
 	// 初始化测试结果
 	result := NewNetworkTestResult(podA, podB)
 
+	if len(opts.Methods) > 0 {
+		rt.executeMethods(ctx, podAInfo, podBInfo, opts, result)
+		rt.calculateStats(result)
+		return result, nil
+	}
+
 	// 执行多种测试
-	rt.executePingTest(ctx, podAInfo, podBInfo, result)
-	rt.executeHTTPTest(ctx, podAInfo, podBInfo, result)
+	rt.executePingTest(ctx, podAInfo, podBInfo, probeA, probeB, result)
+	if probeA {
+		rt.executeHTTPTest(ctx, podAInfo, podBInfo, result)
+	}
 
 	// 计算统计信息
 	rt.calculateStats(result)
@@ -69,21 +105,48 @@ func (rt *RTTTester) TestPodConnectivity(ctx context.Context, podA, podB string)
 	return result, nil
 }
 
-// executePingTest 执行ping测试
-func (rt *RTTTester) executePingTest(ctx context.Context, podA, podB *models.PodInfo, result *models.NetworkTestResult) {
+// executeMethods 从Pod A依次执行指定的测试
+func (rt *RTTTester) executeMethods(ctx context.Context, podA, podB *models.PodInfo, opts RTTOptions, result *models.NetworkTestResult) {
+	if podB.IP == "" {
+		return
+	}
+	for _, method := range opts.Methods {
+		var rttResult models.RTTResult
+		switch method {
+		case probe.MethodPing:
+			rttResult = rt.pingFromPod(ctx, podA, podB.IP, opts.Count)
+		case probe.MethodHTTP:
+			port := opts.HTTPPort
+			if port == 0 {
+				port = 80
+			}
+			rttResult = rt.httpFromPod(ctx, podA, podB.IP, port)
+		case probe.MethodTCP:
+			rttResult = rt.tcpFromPod(ctx, podA, podB.IP, opts.Port)
+		default:
+			continue
+		}
+		rttResult.Method = method
+		result.RTTResults = append(result.RTTResults, rttResult)
+		result.TestCount++
+	}
+}
+
+// executePingTest 执行ping测试（fromA/fromB表示是否允许从对应Pod发起）
+func (rt *RTTTester) executePingTest(ctx context.Context, podA, podB *models.PodInfo, fromA, fromB bool, result *models.NetworkTestResult) {
 	rt.logger.Infof("执行ping测试: %s -> %s", podA.Name, podB.Name)
 
 	// 从Pod A ping Pod B的IP
-	if podB.IP != "" {
-		rttResult := rt.pingFromPod(ctx, podA, podB.IP)
+	if fromA && podB.IP != "" {
+		rttResult := rt.pingFromPod(ctx, podA, podB.IP, 0)
 		rttResult.Method = "ping"
 		result.RTTResults = append(result.RTTResults, rttResult)
 		result.TestCount++
 	}
 
 	// 反向测试：从Pod B ping Pod A的IP
-	if podA.IP != "" {
-		rttResult := rt.pingFromPod(ctx, podB, podA.IP)
+	if fromB && podA.IP != "" {
+		rttResult := rt.pingFromPod(ctx, podB, podA.IP, 0)
 		rttResult.Method = "ping_reverse"
 		result.RTTResults = append(result.RTTResults, rttResult)
 		result.TestCount++
@@ -104,12 +167,15 @@ func (rt *RTTTester) executeHTTPTest(ctx context.Context, podA, podB *models.Pod
 	}
 }
 
-// pingFromPod 从指定Pod执行ping命令
-func (rt *RTTTester) pingFromPod(ctx context.Context, pod *models.PodInfo, targetIP string) models.RTTResult {
+// pingFromPod 从指定Pod执行ping命令（count<=0时ping 3次）
+func (rt *RTTTester) pingFromPod(ctx context.Context, pod *models.PodInfo, targetIP string, count int) models.RTTResult {
 	startTime := time.Now()
+	if count <= 0 {
+		count = probe.DefaultCount
+	}
 
 	// 构建ping命令
-	cmd := fmt.Sprintf("ping -c 3 -W 5 %s", targetIP)
+	cmd := []string{"ping", "-c", strconv.Itoa(count), "-W", "5", targetIP}
 
 	// 在Pod中执行命令
 	output, err := rt.executeCommandInPod(ctx, pod.Namespace, pod.Name, cmd)
@@ -140,7 +206,7 @@ func (rt *RTTTester) httpFromPod(ctx context.Context, pod *models.PodInfo, targe
 	startTime := time.Now()
 
 	// 构建curl命令
-	cmd := fmt.Sprintf("curl -s -o /dev/null -w %%{time_total} -m 5 http://%s:%d", targetIP, port)
+	cmd := []string{"curl", "-s", "-o", "/dev/null", "-w", "%{time_total}", "-m", "5", fmt.Sprintf("http://%s:%d", targetIP, port)}
 
 	// 在Pod中执行命令
 	output, err := rt.executeCommandInPod(ctx, pod.Namespace, pod.Name, cmd)
@@ -166,31 +232,87 @@ func (rt *RTTTester) httpFromPod(ctx context.Context, pod *models.PodInfo, targe
 	return result
 }
 
-// executeCommandInPod 在Pod中执行命令
-func (rt *RTTTester) executeCommandInPod(ctx context.Context, namespace, podName, command string) (string, error) {
+// tcpFromPod 从指定Pod用 nc -z 测试TCP建连，耗时按命令执行时间计算
+func (rt *RTTTester) tcpFromPod(ctx context.Context, pod *models.PodInfo, targetIP string, port int) models.RTTResult {
+	startTime := time.Now()
+	result := models.RTTResult{
+		Timestamp: startTime,
+		Success:   false,
+		Method:    "tcp",
+	}
+	if port <= 0 {
+		result.ErrorMessage = "tcp test requires a target port"
+		return result
+	}
+
+	cmd := []string{"nc", "-z", "-w", "5", targetIP, strconv.Itoa(port)}
+	if _, err := rt.executeCommandInPod(ctx, pod.Namespace, pod.Name, cmd); err != nil {
+		result.ErrorMessage = fmt.Sprintf("TCP连接失败: %v", err)
+		rt.logger.Warnf("TCP from pod %s to %s:%d failed: %v", pod.Name, targetIP, port, err)
+		return result
+	}
+
+	result.Success = true
+	result.RTT = float64(time.Since(startTime).Microseconds()) / 1000
+	rt.logger.Infof("TCP %s -> %s:%d: %.2fms", pod.Name, targetIP, port, result.RTT)
+	return result
+}
+
+// executeCommandInPod 在Pod中执行命令（直接执行，不经过shell；命令需通过执行策略检查）
+func (rt *RTTTester) executeCommandInPod(ctx context.Context, namespace, podName string, argv []string) (string, error) {
+	policy := rt.client.ExecPolicy()
+	if err := policy.Check(argv); err != nil {
+		return "", err
+	}
+
 	// 获取Pod信息以获取容器名称
 	pod, err := rt.client.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod info: %w", err)
 	}
+	if err := policy.CanProbeFrom(pod); err != nil {
+		return "", err
+	}
+
+	// 已有运行中的调试容器时直接使用
+	_, _, debugEnabled := policy.DebugContainer()
+	if debugEnabled {
+		if name, pending := findDebugContainer(pod); name != "" && !pending {
+			return rt.execInContainer(ctx, namespace, podName, name, argv)
+		}
+	}
 
 	// 使用第一个容器的名称
 	if len(pod.Spec.Containers) == 0 {
 		return "", fmt.Errorf("no containers found in pod %s", podName)
 	}
-	containerName := pod.Spec.Containers[0].Name
+	output, err := rt.execInContainer(ctx, namespace, podName, pod.Spec.Containers[0].Name, argv)
+	if err == nil || !debugEnabled || !commandNotFound(err) || !debugContainerAllowed(ctx) {
+		return output, err
+	}
+
+	// 应用镜像中没有该命令（如distroless），改在临时调试容器中执行
+	rt.logger.Debugf("%s not found in pod %s/%s, falling back to debug container", argv[0], namespace, podName)
+	name, debugErr := rt.ensureDebugContainer(ctx, namespace, podName)
+	if debugErr != nil {
+		return "", fmt.Errorf("%v; debug container fallback failed: %w", err, debugErr)
+	}
+	return rt.execInContainer(ctx, namespace, podName, name, argv)
+}
 
+// execInContainer 在Pod的指定容器中执行命令并返回标准输出（命令以非0退出时同样返回已有的输出）
+func (rt *RTTTester) execInContainer(ctx context.Context, namespace, podName, containerName string, argv []string) (string, error) {
 	// 构建执行请求
 	req := rt.client.clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Namespace(namespace).
 		Name(podName).
 		SubResource("exec").
-		Param("container", containerName).
-		Param("command", "sh").
-		Param("command", "-c").
-		Param("command", command).
-		Param("stdout", "true").
+		Param("container", containerName)
+	for _, arg := range argv {
+		req = req.Param("command", arg)
+	}
+	req = req.Param("stdout", "true").
 		Param("stderr", "true")
 
 	// 创建执行器
@@ -209,7 +331,7 @@ func (rt *RTTTester) executeCommandInPod(ctx context.Context, namespace, podName
 	})
 
 	if err != nil {
-		return "", fmt.Errorf("command execution failed: %v, stderr: %s", err, stderr.String())
+		return stdout.String(), fmt.Errorf("command execution failed: %w, stderr: %s", err, stderr.String())
 	}
 
 	return stdout.String(), nil
@@ -217,36 +339,7 @@ func (rt *RTTTester) executeCommandInPod(ctx context.Context, namespace, podName
 
 // parsePingOutput 解析ping命令输出
 func (rt *RTTTester) parsePingOutput(output string, result *models.RTTResult) {
-	scanner := bufio.NewScanner(strings.NewReader(output))
-
-	var rttSum float64
-	var rttCount int
-	var packetLoss float64
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// 解析RTT信息：例如 "64 bytes from 10.244.0.4: icmp_seq=1 ttl=64 time=0.123 ms"
-		if strings.Contains(line, "time=") && strings.Contains(line, "ms") {
-			rtt := rt.extractRTTFromPingLine(line)
-			if rtt > 0 {
-				rttSum += rtt
-				rttCount++
-			}
-		}
-
-		// 解析丢包信息：例如 "3 packets transmitted, 3 received, 0% packet loss"
-		if strings.Contains(line, "packet loss") {
-			packetLoss = rt.extractPacketLossFromPingLine(line)
-		}
-	}
-
-	if rttCount > 0 {
-		result.RTT = rttSum / float64(rttCount)
-		result.Success = true
-	}
-
-	result.PacketLoss = packetLoss
+	result.RTT, result.PacketLoss, result.Success = probe.ParsePingOutput(output)
 }
 
 // parseHTTPOutput 解析curl输出
@@ -263,39 +356,6 @@ func (rt *RTTTester) parseHTTPOutput(output string, result *models.RTTResult) {
 	}
 }
 
-// extractRTTFromPingLine 从ping输出行中提取RTT
-func (rt *RTTTester) extractRTTFromPingLine(line string) float64 {
-	parts := strings.Split(line, "time=")
-	if len(parts) < 2 {
-		return 0
-	}
-
-	timePart := strings.Split(parts[1], " ")[0]
-	timePart = strings.TrimSuffix(timePart, "ms")
-
-	rtt, err := strconv.ParseFloat(timePart, 64)
-	if err != nil {
-		return 0
-	}
-
-	return rtt
-}
-
-// extractPacketLossFromPingLine 从ping输出行中提取丢包率
-func (rt *RTTTester) extractPacketLossFromPingLine(line string) float64 {
-	parts := strings.Split(line, " ")
-	for _, part := range parts {
-		if strings.Contains(part, "%") {
-			lossStr := strings.TrimSuffix(part, "%")
-			loss, err := strconv.ParseFloat(lossStr, 64)
-			if err == nil {
-				return loss
-			}
-		}
-	}
-	return 0
-}
-
 // isHTTPService 判断Pod是否可能是HTTP服务
 func (rt *RTTTester) isHTTPService(pod *models.PodInfo) bool {
 	// 检查标签
@@ -367,13 +427,3 @@ func (rt *RTTTester) assessLatency(rtt float64) string {
 		return "very_poor"
 	}
 }
-
-// getPodInfo 获取Pod信息（添加到client.go的公共方法）
-func (rt *RTTTester) getPodInfo(ctx context.Context, namespace, name string) (*models.PodInfo, error) {
-	pod, err := rt.client.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	return rt.client.convertPodToModel(pod), nil
-}
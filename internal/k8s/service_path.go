@@ -0,0 +1,301 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Service转发路径上可能断开的环节，按流量经过的顺序
+const (
+	HopSelector   = "selector"    // Service的选择器没有选中Pod B
+	HopEndpoints  = "endpoints"   // Pod B不在Service的就绪Endpoints中
+	HopTargetPort = "target_port" // targetPort没有对应Pod B的容器端口
+	HopKubeProxy  = "kube_proxy"  // Pod A所在节点上没有运行kube-proxy（或替代实现）
+)
+
+// kube-proxy 以及可以替代它转发Service流量的组件（kube-system中的Pod标签）
+const (
+	kubeProxySelector       = "k8s-app=kube-proxy"
+	kubeProxyReplacementSel = "k8s-app=cilium"
+	serviceNameLabel        = discoveryv1.LabelServiceName
+)
+
+// checkServicePaths 检查Pod A经由每个目标Service到Pod B的转发路径：Endpoints中是否有就绪的Pod B、
+// targetPort是否对应容器端口、Pod A所在节点上kube-proxy是否在运行，报告第一个断开的环节
+func (na *NetworkAnalyzer) checkServicePaths(ctx context.Context, podA, podB *models.PodInfo, analysis *models.CommunicationAnalysis) {
+	if len(analysis.Findings.TargetServices) == 0 {
+		return
+	}
+
+	pod, err := na.client.clientset.CoreV1().Pods(podB.Namespace).Get(ctx, podB.Name, metav1.GetOptions{})
+	if err != nil {
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("service path: %v", err))
+		return
+	}
+
+	proxyChecked := false
+	var proxyHop string
+	for _, ref := range analysis.Findings.TargetServices {
+		namespace, name := parsePodName(ref)
+		svc, err := na.client.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("service %s: %v", ref, err))
+			continue
+		}
+
+		path := models.ServicePath{
+			Service:   ref,
+			Type:      string(svc.Spec.Type),
+			ClusterIP: svc.Spec.ClusterIP,
+		}
+		if err := na.checkServiceEndpoints(ctx, svc, pod, &path); err != nil {
+			analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("endpoints %s: %v", ref, err))
+		}
+		checkServiceTargetPorts(svc, pod, &path)
+
+		// 无头Service直接解析到Pod IP，不经过kube-proxy
+		if path.BrokenHop == "" && svc.Spec.ClusterIP != corev1.ClusterIPNone {
+			if !proxyChecked {
+				proxyHop = na.checkKubeProxy(ctx, podA.NodeName, analysis)
+				proxyChecked = true
+			}
+			if proxyHop != "" {
+				path.BrokenHop = HopKubeProxy
+				path.Detail = proxyHop
+			}
+		}
+
+		analysis.Findings.ServicePaths = append(analysis.Findings.ServicePaths, path)
+		reportServicePath(path, podB, analysis)
+	}
+}
+
+// checkServiceEndpoints 从EndpointSlice（列出失败时回退到Endpoints）中查找Pod B
+func (na *NetworkAnalyzer) checkServiceEndpoints(ctx context.Context, svc *corev1.Service, pod *corev1.Pod, path *models.ServicePath) error {
+	path.PodBEndpoint = models.EndpointMissing
+
+	slices, err := na.client.clientset.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: serviceNameLabel + "=" + svc.Name,
+	})
+	if err == nil {
+		for _, slice := range slices.Items {
+			for _, endpoint := range slice.Endpoints {
+				ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				if ready {
+					path.ReadyEndpoints++
+				}
+				if isPodEndpoint(endpoint.TargetRef, endpoint.Addresses, pod) {
+					path.PodBEndpoint = endpointState(ready)
+				}
+			}
+		}
+	} else {
+		endpoints, epErr := na.client.clientset.CoreV1().Endpoints(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+		if epErr != nil {
+			path.PodBEndpoint = ""
+			return fmt.Errorf("endpointslices: %v; endpoints: %v", err, epErr)
+		}
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				path.ReadyEndpoints++
+				if isPodEndpoint(address.TargetRef, []string{address.IP}, pod) {
+					path.PodBEndpoint = models.EndpointReady
+				}
+			}
+			for _, address := range subset.NotReadyAddresses {
+				if isPodEndpoint(address.TargetRef, []string{address.IP}, pod) {
+					path.PodBEndpoint = models.EndpointNotReady
+				}
+			}
+		}
+	}
+
+	switch path.PodBEndpoint {
+	case models.EndpointNotReady:
+		path.BrokenHop = HopEndpoints
+		path.Detail = "pod B is listed as a not-ready endpoint; its readiness probe is failing"
+	case models.EndpointMissing:
+		path.BrokenHop = HopEndpoints
+		if len(svc.Spec.Selector) == 0 {
+			path.Detail = "service has no selector and its manually managed endpoints do not include pod B"
+		} else {
+			path.Detail = fmt.Sprintf("pod B (%s) is not in the service endpoints (%d ready)", pod.Status.PodIP, path.ReadyEndpoints)
+		}
+	}
+	return nil
+}
+
+// isPodEndpoint 判断Endpoint是否指向Pod（优先比较targetRef，没有时比较IP）
+func isPodEndpoint(ref *corev1.ObjectReference, addresses []string, pod *corev1.Pod) bool {
+	if ref != nil && ref.Kind == "Pod" {
+		return ref.Namespace == pod.Namespace && ref.Name == pod.Name
+	}
+	for _, address := range addresses {
+		if address != "" && address == pod.Status.PodIP {
+			return true
+		}
+	}
+	return false
+}
+
+func endpointState(ready bool) string {
+	if ready {
+		return models.EndpointReady
+	}
+	return models.EndpointNotReady
+}
+
+// checkServiceTargetPorts 检查每个Service端口的targetPort能否对应Pod B的容器端口。
+// 数字targetPort在Pod未声明该端口时仍可能可用（容器可以监听未声明的端口），命名端口则必须存在
+func checkServiceTargetPorts(svc *corev1.Service, pod *corev1.Pod, path *models.ServicePath) {
+	var declared []corev1.ContainerPort
+	for _, container := range pod.Spec.Containers {
+		declared = append(declared, container.Ports...)
+	}
+
+	for _, port := range svc.Spec.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		check := models.ServicePortPath{
+			Port:       port.Port,
+			Protocol:   string(protocol),
+			TargetPort: port.TargetPort.String(),
+		}
+
+		switch {
+		case port.TargetPort.StrVal != "":
+			for _, cp := range declared {
+				if cp.Name == port.TargetPort.StrVal && containerProtocol(cp) == protocol {
+					check.ContainerPort = cp.ContainerPort
+				}
+			}
+			check.OK = check.ContainerPort != 0
+			if !check.OK {
+				check.Detail = fmt.Sprintf("pod B declares no %s container port named %q", protocol, port.TargetPort.StrVal)
+			}
+		default:
+			target := port.TargetPort.IntVal
+			if target == 0 {
+				target = port.Port
+				check.TargetPort = fmt.Sprint(target)
+			}
+			check.ContainerPort = target
+			check.OK = len(declared) == 0
+			for _, cp := range declared {
+				if cp.ContainerPort == target && containerProtocol(cp) == protocol {
+					check.OK = true
+				}
+			}
+			if len(declared) == 0 {
+				check.Detail = "pod B declares no container ports; cannot verify the target port"
+			} else if !check.OK {
+				check.Detail = fmt.Sprintf("pod B does not declare %s port %d", protocol, target)
+			}
+		}
+
+		if !check.OK && path.BrokenHop == "" {
+			path.BrokenHop = HopTargetPort
+			path.Detail = fmt.Sprintf("port %d -> %s: %s", port.Port, check.TargetPort, check.Detail)
+		}
+		path.Ports = append(path.Ports, check)
+	}
+}
+
+func containerProtocol(port corev1.ContainerPort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
+
+// checkKubeProxy 检查节点上是否有运行中的kube-proxy；集群中没有kube-proxy时查找替代实现（Cilium），
+// 都没有时无法判断，不视为断开。返回断开的原因，正常时为空
+func (na *NetworkAnalyzer) checkKubeProxy(ctx context.Context, node string, analysis *models.CommunicationAnalysis) string {
+	pods, err := na.client.clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: kubeProxySelector})
+	if err != nil {
+		analysis.Findings.CheckErrors = append(analysis.Findings.CheckErrors, fmt.Sprintf("kube-proxy: %v", err))
+		return ""
+	}
+	if len(pods.Items) == 0 {
+		replacements, err := na.client.clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: kubeProxyReplacementSel})
+		if err == nil && len(replacements.Items) == 0 {
+			na.logger.Debugf("No kube-proxy or known replacement found in kube-system; skipping proxy check")
+		}
+		return ""
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != node {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning && podReady(&pod) {
+			return ""
+		}
+		return fmt.Sprintf("kube-proxy pod %s on node %s is not ready (phase %s)", pod.Name, node, pod.Status.Phase)
+	}
+	return fmt.Sprintf("no kube-proxy pod is running on node %s", node)
+}
+
+// reportServicePath 将断开的环节写入问题和建议
+func reportServicePath(path models.ServicePath, podB *models.PodInfo, analysis *models.CommunicationAnalysis) {
+	if path.BrokenHop == "" {
+		return
+	}
+	analysis.Issues = append(analysis.Issues,
+		fmt.Sprintf("Service %s -> Pod %s/%s is broken at %s: %s", path.Service, podB.Namespace, podB.Name, path.BrokenHop, path.Detail))
+
+	var solution string
+	switch path.BrokenHop {
+	case HopEndpoints:
+		if path.PodBEndpoint == models.EndpointNotReady {
+			solution = fmt.Sprintf("Fix the readiness probe of Pod %s/%s so it is added to the ready endpoints", podB.Namespace, podB.Name)
+		} else {
+			solution = fmt.Sprintf("Check the EndpointSlices of Service %s and whether the endpoint controller is updating them", path.Service)
+		}
+	case HopTargetPort:
+		solution = fmt.Sprintf("Set the targetPort of Service %s to a port the container of Pod %s/%s listens on", path.Service, podB.Namespace, podB.Name)
+	case HopKubeProxy:
+		solution = "Check the kube-proxy DaemonSet in kube-system on the source node"
+	}
+	analysis.Solutions = append(analysis.Solutions, solution)
+}
+
+// selectorMismatches 列出选择器部分匹配Pod的Service及不匹配的标签，用于解释为什么没有Service指向Pod
+func selectorMismatches(services []*models.ServiceInfo, pod *models.PodInfo) []models.ServicePath {
+	var paths []models.ServicePath
+	for _, svc := range services {
+		var matched int
+		var mismatched []string
+		for key, value := range svc.Selector {
+			if actual, ok := pod.Labels[key]; ok && actual == value {
+				matched++
+			} else if ok {
+				mismatched = append(mismatched, fmt.Sprintf("%s=%s (pod has %q)", key, value, actual))
+			} else {
+				mismatched = append(mismatched, fmt.Sprintf("%s=%s (pod has no such label)", key, value))
+			}
+		}
+		if matched == 0 || len(mismatched) == 0 {
+			continue
+		}
+		sort.Strings(mismatched)
+		paths = append(paths, models.ServicePath{
+			Service:   svc.Namespace + "/" + svc.Name,
+			Type:      svc.Type,
+			ClusterIP: svc.ClusterIP,
+			BrokenHop: HopSelector,
+			Detail:    "selector does not match pod B: " + strings.Join(mismatched, ", "),
+		})
+	}
+	return paths
+}
@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCheckServiceTargetPorts(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Ports: []corev1.ContainerPort{
+			{Name: "http", ContainerPort: 8080},
+			{Name: "mavlink", ContainerPort: 14550, Protocol: corev1.ProtocolUDP},
+		},
+	}}}}
+
+	tests := []struct {
+		name   string
+		port   corev1.ServicePort
+		broken bool
+		want   int32
+	}{
+		{"named port", corev1.ServicePort{Port: 80, TargetPort: intstr.FromString("http")}, false, 8080},
+		{"numeric port", corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080)}, false, 8080},
+		{"defaults to port", corev1.ServicePort{Port: 8080}, false, 8080},
+		{"udp named port", corev1.ServicePort{Port: 14550, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromString("mavlink")}, false, 14550},
+		{"missing named port", corev1.ServicePort{Port: 80, TargetPort: intstr.FromString("web")}, true, 0},
+		{"wrong protocol", corev1.ServicePort{Port: 80, TargetPort: intstr.FromString("mavlink")}, true, 0},
+		{"undeclared number", corev1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(9090)}, true, 9090},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{tt.port}}}
+			var path models.ServicePath
+			checkServiceTargetPorts(svc, pod, &path)
+
+			if got := path.BrokenHop == HopTargetPort; got != tt.broken {
+				t.Fatalf("broken = %v, want %v (%s)", got, tt.broken, path.Detail)
+			}
+			if got := path.Ports[0].ContainerPort; got != tt.want {
+				t.Errorf("container port = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckServiceTargetPortsWithoutDeclaredPorts(t *testing.T) {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}}}}
+	var path models.ServicePath
+	checkServiceTargetPorts(svc, &corev1.Pod{}, &path)
+
+	if path.BrokenHop != "" {
+		t.Fatalf("numeric target port on a pod without declared ports should not be broken: %s", path.Detail)
+	}
+	if path.Ports[0].Detail == "" {
+		t.Error("expected a note that the port could not be verified")
+	}
+}
+
+func TestSelectorMismatches(t *testing.T) {
+	pod := &models.PodInfo{Labels: map[string]string{"app": "api", "tier": "backend"}}
+	services := []*models.ServiceInfo{
+		{Namespace: "default", Name: "exact", Selector: map[string]string{"app": "api"}},
+		{Namespace: "default", Name: "near", Selector: map[string]string{"app": "api", "tier": "frontend", "track": "stable"}},
+		{Namespace: "default", Name: "unrelated", Selector: map[string]string{"app": "db"}},
+	}
+
+	paths := selectorMismatches(services, pod)
+	if len(paths) != 1 || paths[0].Service != "default/near" {
+		t.Fatalf("paths = %+v, want only default/near", paths)
+	}
+	if paths[0].BrokenHop != HopSelector {
+		t.Errorf("broken hop = %q, want %q", paths[0].BrokenHop, HopSelector)
+	}
+	for _, want := range []string{`tier=frontend (pod has "backend")`, "track=stable (pod has no such label)"} {
+		if !strings.Contains(paths[0].Detail, want) {
+			t.Errorf("detail %q does not mention %q", paths[0].Detail, want)
+		}
+	}
+}
+
+func TestIsPodEndpoint(t *testing.T) {
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name, pod.Status.PodIP = "default", "api-0", "10.0.0.5"
+
+	if !isPodEndpoint(&corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api-0"}, nil, pod) {
+		t.Error("target ref should match")
+	}
+	if isPodEndpoint(&corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api-1"}, []string{"10.0.0.5"}, pod) {
+		t.Error("a target ref to another pod should win over a reused IP")
+	}
+	if !isPodEndpoint(nil, []string{"10.0.0.5"}, pod) {
+		t.Error("address should match when there is no target ref")
+	}
+}
@@ -0,0 +1,22 @@
+package k8s
+
+import "testing"
+
+func TestUAVMetricName(t *testing.T) {
+	tests := []struct {
+		node  string
+		index int
+		want  string
+	}{
+		{"edge-1", 0, "uavmetric-edge-1"},
+		{"edge-1", 2, "uavmetric-edge-1.2"},
+		{"x", 1, "uavmetric-x.1"},
+		{"x-uav1", 0, "uavmetric-x-uav1"},
+		{"edge.example.com", 1, "uavmetric-edge-example-com.1"},
+	}
+	for _, tt := range tests {
+		if got := uavMetricName(tt.node, tt.index); got != tt.want {
+			t.Errorf("uavMetricName(%q, %d) = %q, want %q", tt.node, tt.index, got, tt.want)
+		}
+	}
+}
@@ -24,7 +24,7 @@ type EventHandler interface {
 type Watcher struct {
 	client  *Client
 	handler EventHandler
-	logger  *logrus.Logger
+	logger  *logrus.Entry
 	stopCh  chan struct{}
 }
 
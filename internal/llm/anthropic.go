@@ -0,0 +1,382 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// ProviderAnthropic Anthropic Messages API
+const ProviderAnthropic = "anthropic"
+
+// Anthropic 接口的默认值
+const (
+	defaultAnthropicBaseURL   = "https://api.anthropic.com"
+	defaultAnthropicMaxTokens = 1024 // Messages API 要求必须设置 max_tokens
+	anthropicVersion          = "2023-06-01"
+)
+
+// jsonModeInstruction Anthropic 没有 response_format，JSONMode 时追加到系统提示词
+const jsonModeInstruction = "Respond with a single valid JSON object only, without markdown code fences or any other text."
+
+// AnthropicClient Anthropic Messages API 客户端
+type AnthropicClient struct {
+	apiKey      string
+	baseURL     string
+	model       string
+	maxTokens   int
+	temperature float64
+	httpClient  *http.Client
+}
+
+// NewAnthropicClient 根据LLM配置创建Anthropic客户端
+func NewAnthropicClient(profile config.LLMProfile) (*AnthropicClient, error) {
+	if profile.APIKey == "" {
+		return nil, fmt.Errorf("%w: %s profile %q has no api_key", ErrNotConfigured, ProviderAnthropic, profile.Name)
+	}
+	baseURL := strings.TrimSuffix(strings.TrimRight(profile.BaseURL, "/"), "/v1")
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	maxTokens := profile.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+
+	return &AnthropicClient{
+		apiKey:      profile.APIKey,
+		baseURL:     baseURL,
+		model:       profile.Model,
+		maxTokens:   maxTokens,
+		temperature: profileTemperature(profile),
+		httpClient:  newHTTPClient(profile),
+	}, nil
+}
+
+// Provider 提供商名称
+func (c *AnthropicClient) Provider() string {
+	return ProviderAnthropic
+}
+
+// Model 模型名称
+func (c *AnthropicClient) Model() string {
+	return c.model
+}
+
+// Complete 单轮补全
+func (c *AnthropicClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// anthropicBlock 消息内容块（text、tool_use、tool_result）
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+// buildRequest 转换为 Messages API 请求：system 单独传递，连续的工具结果合并到同一条 user 消息中
+func (c *AnthropicClient) buildRequest(req Request, stream bool) anthropicRequest {
+	body := anthropicRequest{
+		Model:       c.model,
+		Messages:    make([]anthropicMessage, 0, len(req.Messages)),
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		Stream:      stream,
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		body.Temperature = *req.Temperature
+	}
+
+	var system []string
+	for _, message := range req.Messages {
+		switch message.Role {
+		case RoleSystem:
+			system = append(system, message.Content)
+		case RoleTool:
+			block := anthropicBlock{Type: "tool_result", ToolUseID: message.ToolCallID, Content: message.Content}
+			if last := len(body.Messages) - 1; last >= 0 && body.Messages[last].Role == RoleUser &&
+				body.Messages[last].Content[0].Type == "tool_result" {
+				body.Messages[last].Content = append(body.Messages[last].Content, block)
+				continue
+			}
+			body.Messages = append(body.Messages, anthropicMessage{Role: RoleUser, Content: []anthropicBlock{block}})
+		default:
+			converted := anthropicMessage{Role: message.Role}
+			if message.Content != "" {
+				converted.Content = append(converted.Content, anthropicBlock{Type: "text", Text: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				converted.Content = append(converted.Content, anthropicBlock{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Name,
+					Input: toolArguments(string(call.Arguments)),
+				})
+			}
+			// 空消息会被接口拒绝
+			if len(converted.Content) == 0 {
+				continue
+			}
+			body.Messages = append(body.Messages, converted)
+		}
+	}
+	if req.JSONMode {
+		system = append(system, jsonModeInstruction)
+	}
+	body.System = strings.Join(system, "\n\n")
+
+	for _, tool := range req.Tools {
+		schema := tool.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		body.Tools = append(body.Tools, anthropicTool{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	return body
+}
+
+// post 发送请求，非2xx响应转换为 *APIError
+func (c *AnthropicClient) post(ctx context.Context, body anthropicRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", ProviderAnthropic, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if body.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", ProviderAnthropic, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	// 错误格式 {"type": "error", "error": {"type": ..., "message": ...}}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{
+		Provider:   ProviderAnthropic,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(data)),
+		RetryAfter: retryAfter(resp),
+	}
+	var payloadErr struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &payloadErr) == nil && payloadErr.Error.Message != "" {
+		apiErr.Message = payloadErr.Error.Message
+		apiErr.Type = payloadErr.Error.Type
+	}
+	return nil, apiErr
+}
+
+// Chat 多轮对话
+func (c *AnthropicClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", ProviderAnthropic, err)
+	}
+
+	result := &Response{
+		FinishReason: payload.StopReason,
+		Provider:     ProviderAnthropic,
+		Model:        payload.Model,
+		Usage:        anthropicTotal(payload.Usage),
+		Latency:      time.Since(start),
+	}
+	var content strings.Builder
+	for _, block := range payload.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			result.ToolCalls = append(result.ToolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: toolArguments(string(block.Input))})
+		}
+	}
+	result.Content = content.String()
+	return result, nil
+}
+
+// anthropicStreamEvent 流式事件（message_start、content_block_start、content_block_delta、message_delta ...）
+type anthropicStreamEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message"`
+	ContentBlock *anthropicBlock    `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Stream 流式对话（SSE），工具调用的参数按内容块拼接
+func (c *AnthropicClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Provider: ProviderAnthropic, Model: c.model}
+	var content strings.Builder
+	var usage anthropicUsage
+	blocks := make(map[int]*ToolCall)
+	var order []int
+	arguments := make(map[int]*strings.Builder)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode %s stream event: %w", ProviderAnthropic, err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				if event.Message.Model != "" {
+					result.Model = event.Message.Model
+				}
+				usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				blocks[event.Index] = &ToolCall{ID: event.ContentBlock.ID, Name: event.ContentBlock.Name}
+				arguments[event.Index] = &strings.Builder{}
+				order = append(order, event.Index)
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				content.WriteString(event.Delta.Text)
+				if handler != nil && event.Delta.Text != "" {
+					if err := handler(event.Delta.Text); err != nil {
+						return nil, err
+					}
+				}
+			case "input_json_delta":
+				if builder, ok := arguments[event.Index]; ok {
+					builder.WriteString(event.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				result.FinishReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			apiErr := &APIError{Provider: ProviderAnthropic, StatusCode: http.StatusServiceUnavailable, Message: "stream error"}
+			if event.Error != nil {
+				apiErr.Type, apiErr.Message = event.Error.Type, event.Error.Message
+			}
+			return nil, apiErr
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s stream interrupted: %w", ProviderAnthropic, err)
+	}
+
+	result.Content = content.String()
+	for _, index := range order {
+		call := blocks[index]
+		call.Arguments = toolArguments(arguments[index].String())
+		result.ToolCalls = append(result.ToolCalls, *call)
+	}
+	result.Usage = anthropicTotal(usage)
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// anthropicTotal 转换为统一的用量结构
+func anthropicTotal(usage anthropicUsage) Usage {
+	return Usage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+}
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 提供商熔断中，暂不发送请求
+var ErrCircuitOpen = errors.New("llm: circuit breaker open")
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ProviderStatus 单个LLM配置的健康状态
+type ProviderStatus struct {
+	Profile             string     `json:"profile"`
+	Provider            string     `json:"provider"`
+	Model               string     `json:"model"`
+	Local               bool       `json:"local"`             // 本地模型，发送前不脱敏
+	Blocked             bool       `json:"blocked,omitempty"` // 严格模式下不会被调用的外部配置
+	Configured          bool       `json:"configured"`        // 是否具备必要配置（如API Key）
+	ConfigError         string     `json:"config_error,omitempty"`
+	State               string     `json:"state"`
+	Healthy             bool       `json:"healthy"`
+	Requests            int64      `json:"requests"`
+	Failures            int64      `json:"failures"`
+	Fallbacks           int64      `json:"fallbacks"` // 本配置失败后转到后备配置的次数
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	AvgLatencyMs        float64    `json:"avg_latency_ms"` // 指数加权平均
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// breaker 单个LLM配置的熔断器：连续失败达到阈值后打开，冷却后放行一个探测请求
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	state     string
+	probing   bool
+	openUntil time.Time
+	status    ProviderStatus
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if threshold <= 0 {
+		threshold = 3
+	}
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	return &breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+// configure 更新阈值（配置热更新），保留当前状态
+func (b *breaker) configure(threshold int, cooldown time.Duration) {
+	fresh := newBreaker(threshold, cooldown)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold, b.cooldown = fresh.threshold, fresh.cooldown
+}
+
+// allow 判断是否允许发送请求，返回是否为半开状态下的探测请求
+func (b *breaker) allow() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false, ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true, nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, ErrCircuitOpen
+		}
+		b.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record 记录请求结果；failed 只统计提供商故障（限流、5xx、网络错误），不含请求本身的错误
+func (b *breaker) record(latency time.Duration, failed bool, err error, probe bool) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	const alpha = 0.2
+	latencyMs := float64(latency.Microseconds()) / 1000
+	if b.status.Requests == 0 {
+		b.status.AvgLatencyMs = latencyMs
+	} else {
+		b.status.AvgLatencyMs = alpha*latencyMs + (1-alpha)*b.status.AvgLatencyMs
+	}
+	b.status.Requests++
+	now := time.Now().UTC()
+	if probe {
+		b.probing = false
+	}
+
+	if !failed {
+		b.status.ConsecutiveFailures = 0
+		b.status.LastSuccessAt = &now
+		b.state = BreakerClosed
+		return false
+	}
+
+	b.status.Failures++
+	b.status.ConsecutiveFailures++
+	b.status.LastErrorAt = &now
+	if err != nil {
+		b.status.LastError = err.Error()
+	}
+
+	if b.state == BreakerHalfOpen || b.status.ConsecutiveFailures >= b.threshold {
+		// 提供商给出 Retry-After 时至少等待该时长
+		wait := b.cooldown
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		b.state = BreakerOpen
+		openUntil := now.Add(wait)
+		b.openUntil = openUntil
+		b.status.OpenedAt = &now
+		b.status.OpenUntil = &openUntil
+		return true
+	}
+	return false
+}
+
+// fellBack 记录一次转到后备配置
+func (b *breaker) fellBack() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status.Fallbacks++
+}
+
+// snapshot 返回当前状态
+func (b *breaker) snapshot() ProviderStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := b.status
+	status.State = b.state
+	status.Healthy = b.state == BreakerClosed
+	if b.state == BreakerClosed {
+		status.OpenedAt, status.OpenUntil = nil, nil
+	}
+	return status
+}
+
+// providerFailure 判断错误是否为提供商故障（应计入熔断并尝试后备配置）。
+// 调用方取消、配置缺失和请求本身的错误（4xx，429除外）不计入
+func providerFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrNotConfigured) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
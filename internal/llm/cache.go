@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// fingerprintKey 在上下文中传递集群状态指纹
+type fingerprintKey struct{}
+
+// WithFingerprint 为调用附加集群状态指纹（如指标快照时间）。
+// 只有带指纹的调用才使用回答缓存：提示词和指纹都相同时在TTL内复用上次的回答
+func WithFingerprint(ctx context.Context, fingerprint string) context.Context {
+	return context.WithValue(ctx, fingerprintKey{}, fingerprint)
+}
+
+// fingerprint 上下文中的集群状态指纹
+func fingerprint(ctx context.Context) string {
+	value, _ := ctx.Value(fingerprintKey{}).(string)
+	return value
+}
+
+// CacheStats 回答缓存状态
+type CacheStats struct {
+	Enabled    bool  `json:"enabled"`
+	TTLSeconds int   `json:"ttl_seconds"`
+	MaxEntries int   `json:"max_entries"`
+	Entries    int   `json:"entries"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	// TokensSaved 缓存命中节省的token数（按缓存回答的原始用量计算）
+	TokensSaved int64 `json:"tokens_saved"`
+}
+
+// cacheEntry 缓存的回答
+type cacheEntry struct {
+	resp    Response
+	expires time.Time
+}
+
+// responseCache 按请求内容和集群状态指纹缓存模型回答
+type responseCache struct {
+	mu          sync.Mutex
+	enabled     bool
+	ttl         time.Duration
+	maxEntries  int
+	entries     map[string]*cacheEntry
+	hits        int64
+	misses      int64
+	tokensSaved int64
+}
+
+func newResponseCache(cfg config.LLMCacheConfig) *responseCache {
+	c := &responseCache{entries: make(map[string]*cacheEntry)}
+	c.configure(cfg)
+	return c
+}
+
+// configure 应用新的缓存配置（配置热更新），关闭缓存时清空已有回答
+func (c *responseCache) configure(cfg config.LLMCacheConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = cfg.Enabled && cfg.TTL > 0
+	c.ttl = time.Duration(cfg.TTL) * time.Second
+	c.maxEntries = cfg.MaxEntries
+	if !c.enabled {
+		c.entries = make(map[string]*cacheEntry)
+	}
+}
+
+// key 缓存键：配置、模型、请求内容和指纹的哈希；未启用缓存或没有指纹时返回空
+func (c *responseCache) key(ctx context.Context, scope string, req Request) string {
+	fp := fingerprint(ctx)
+	c.mu.Lock()
+	enabled := c.enabled
+	c.mu.Unlock()
+	if !enabled || fp == "" {
+		return ""
+	}
+
+	data, err := json.Marshal(struct {
+		Scope       string
+		Fingerprint string
+		Request     Request
+	}{scope, fp, req})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get 返回未过期的缓存回答
+func (c *responseCache) get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.tokensSaved += int64(entry.resp.Usage.TotalTokens)
+
+	resp := entry.resp
+	resp.ToolCalls = append([]ToolCall(nil), entry.resp.ToolCalls...)
+	resp.Usage = Usage{}
+	resp.Latency = 0
+	resp.Cached = true
+	return &resp, true
+}
+
+// put 保存回答；超出 max_entries 时先清理过期的回答，仍然超出则淘汰最早过期的
+func (c *responseCache) put(key string, resp *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled {
+		return
+	}
+
+	now := time.Now()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for len(c.entries) >= c.maxEntries {
+			oldest := ""
+			for k, entry := range c.entries {
+				if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = &cacheEntry{resp: *resp, expires: now.Add(c.ttl)}
+}
+
+// invalidate 清空缓存，返回清除的回答数
+func (c *responseCache) invalidate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.entries)
+	c.entries = make(map[string]*cacheEntry)
+	return n
+}
+
+// stats 缓存状态
+func (c *responseCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Enabled:     c.enabled,
+		TTLSeconds:  int(c.ttl / time.Second),
+		MaxEntries:  c.maxEntries,
+		Entries:     len(c.entries),
+		Hits:        c.hits,
+		Misses:      c.misses,
+		TokensSaved: c.tokensSaved,
+	}
+}
+
+// cachedClient 在后备链之前查询回答缓存；scope 为主配置名，不同配置的回答不共用
+type cachedClient struct {
+	Client
+	scope string
+	cache *responseCache
+}
+
+// Complete 单轮补全
+func (c *cachedClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// Chat 多轮对话
+func (c *cachedClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	key := c.cache.key(ctx, c.scope, req)
+	if key != "" {
+		if resp, ok := c.cache.get(key); ok {
+			return resp, nil
+		}
+	}
+	resp, err := c.Client.Chat(ctx, req)
+	if err == nil && key != "" {
+		c.cache.put(key, resp)
+	}
+	return resp, err
+}
+
+// Stream 流式对话；命中缓存时一次性输出缓存的回答
+func (c *cachedClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	key := c.cache.key(ctx, c.scope, req)
+	if key != "" {
+		if resp, ok := c.cache.get(key); ok {
+			if handler != nil && resp.Content != "" {
+				if err := handler(resp.Content); err != nil {
+					return nil, err
+				}
+			}
+			return resp, nil
+		}
+	}
+	resp, err := c.Client.Stream(ctx, req, handler)
+	if err == nil && key != "" {
+		c.cache.put(key, resp)
+	}
+	return resp, err
+}
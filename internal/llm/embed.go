@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TypeEmbedding 文本向量化在 llm.routing 和用量统计中使用的类型
+const TypeEmbedding = "embedding"
+
+// ErrEmbeddingsUnsupported 提供商不支持文本向量化（如 anthropic）
+var ErrEmbeddingsUnsupported = errors.New("llm: provider does not support embeddings")
+
+// Embedding 向量化结果，Vectors 与输入文本一一对应
+type Embedding struct {
+	Vectors [][]float32
+	Model   string
+	Usage   Usage
+}
+
+// Embedder 文本向量化
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) (*Embedding, error)
+}
+
+// Embed 调用 /embeddings 接口（模型为配置中的 model）
+func (c *OpenAIClient) Embed(ctx context.Context, texts []string) (*Embedding, error) {
+	resp, err := c.send(ctx, "/embeddings", map[string]interface{}{
+		"model": c.model,
+		"input": texts,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Model string `json:"model"`
+		Data  []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage *Usage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s embeddings: %w", c.provider, err)
+	}
+
+	result := &Embedding{Vectors: make([][]float32, len(texts)), Model: payload.Model}
+	for _, item := range payload.Data {
+		if item.Index >= 0 && item.Index < len(texts) {
+			result.Vectors[item.Index] = item.Embedding
+		}
+	}
+	for i, vector := range result.Vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("%s embeddings response has no vector for input %d", c.provider, i)
+		}
+	}
+	if payload.Usage != nil {
+		result.Usage = *payload.Usage
+	}
+	return result, nil
+}
+
+// Embed 调用 Ollama /api/embed 接口
+func (c *OllamaClient) Embed(ctx context.Context, texts []string) (*Embedding, error) {
+	resp, err := c.send(ctx, "/api/embed", map[string]interface{}{
+		"model": c.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Model           string      `json:"model"`
+		Embeddings      [][]float32 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s embeddings: %w", ProviderOllama, err)
+	}
+	if len(payload.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d inputs", ProviderOllama, len(payload.Embeddings), len(texts))
+	}
+	return &Embedding{
+		Vectors: payload.Embeddings,
+		Model:   payload.Model,
+		Usage:   Usage{PromptTokens: payload.PromptEvalCount, TotalTokens: payload.PromptEvalCount},
+	}, nil
+}
+
+// Embedder 返回文本向量化客户端：指定的配置 > llm.routing.embedding > 默认配置；model 非空时覆盖配置中的模型。
+// 向量化调用计入每日用量和预算
+func (s *Service) Embedder(profileName, model string) (Embedder, error) {
+	s.mu.Lock()
+	profile, err := s.cfg.ProfileFor(TypeEmbedding, profileName)
+	local, strict := s.cfg.IsLocal(profile), s.cfg.Redaction.Strict
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if strict && !local {
+		s.redactor.blocked()
+		return nil, fmt.Errorf("%w, embedding profile %s is not local", ErrExternalBlocked, profile.Name)
+	}
+	if model != "" {
+		profile.Model = model
+	}
+
+	client, err := New(profile)
+	if err != nil {
+		return nil, err
+	}
+	embedder, ok := client.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmbeddingsUnsupported, client.Provider())
+	}
+	if !local {
+		embedder = &redactingEmbedder{Embedder: embedder, redactor: s.redactor}
+	}
+	return &trackedEmbedder{
+		Embedder: embedder,
+		profile:  profile.Name,
+		provider: client.Provider(),
+		model:    profile.Model,
+		budget:   profile.DailyTokenBudget,
+		usage:    s.usage,
+	}, nil
+}
+
+// trackedEmbedder 检查预算并记录向量化用量
+type trackedEmbedder struct {
+	Embedder
+	profile  string
+	provider string
+	model    string
+	budget   int
+	usage    *usageTracker
+}
+
+// Embed 文本向量化
+func (e *trackedEmbedder) Embed(ctx context.Context, texts []string) (*Embedding, error) {
+	if err := e.usage.allow(e.profile, e.budget); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := e.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if result.Model == "" {
+		result.Model = e.model
+	}
+	result.Usage.EstimatedCost = e.usage.cost(result.Model, result.Usage)
+	e.usage.record(UsageRecord{
+		Timestamp: time.Now().UTC(),
+		Profile:   e.profile,
+		Provider:  e.provider,
+		Model:     result.Model,
+		Type:      TypeEmbedding,
+		Usage:     result.Usage,
+		LatencyMs: time.Since(start).Milliseconds(),
+	})
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// member 后备链中的一个配置
+type member struct {
+	profile string
+	client  Client
+	breaker *breaker
+	budget  int // 配置的每日token预算
+}
+
+// fallbackClient 按顺序尝试主配置和后备配置：提供商限流、5xx或网络错误时转到下一个，
+// 熔断中或超出每日预算的配置直接跳过
+type fallbackClient struct {
+	members []member
+	usage   *usageTracker
+	logger  *logrus.Entry
+}
+
+// Provider 主配置的提供商
+func (c *fallbackClient) Provider() string {
+	return c.members[0].client.Provider()
+}
+
+// Model 主配置的模型
+func (c *fallbackClient) Model() string {
+	return c.members[0].client.Model()
+}
+
+// Complete 单轮补全
+func (c *fallbackClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// Chat 多轮对话
+func (c *fallbackClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	return c.try(ctx, func(client Client) (*Response, error) {
+		return client.Chat(ctx, req)
+	}, nil)
+}
+
+// streamState 流式调用的输出状态
+type streamState struct {
+	started    bool  // 已向调用方输出内容
+	handlerErr error // 调用方处理失败（例如客户端断开），不属于提供商故障
+}
+
+// Stream 流式对话；已经输出部分内容后失败时不再切换（避免调用方收到两份不同的回答）
+func (c *fallbackClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	state := &streamState{}
+	tracked := func(delta string) error {
+		state.started = true
+		if handler == nil {
+			return nil
+		}
+		if err := handler(delta); err != nil {
+			state.handlerErr = err
+			return err
+		}
+		return nil
+	}
+	return c.try(ctx, func(client Client) (*Response, error) {
+		return client.Stream(ctx, req, tracked)
+	}, state)
+}
+
+// try 依次调用后备链，stream 为空表示非流式调用
+func (c *fallbackClient) try(ctx context.Context, call func(Client) (*Response, error), stream *streamState) (*Response, error) {
+	var lastErr error
+	for i, m := range c.members {
+		if err := c.usage.allow(m.profile, m.budget); err != nil {
+			lastErr = err
+			continue
+		}
+		probe, err := m.breaker.allow()
+		if err != nil {
+			lastErr = fmt.Errorf("%w: profile %s", err, m.profile)
+			continue
+		}
+
+		start := time.Now()
+		resp, err := call(m.client)
+		failed := providerFailure(ctx, err) && (stream == nil || stream.handlerErr == nil)
+		if m.breaker.record(time.Since(start), failed, err, probe) {
+			c.logger.Errorf("LLM profile %s (%s) unhealthy, circuit breaker opened: %v", m.profile, m.client.Provider(), err)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		if !failed || (stream != nil && stream.started) {
+			return nil, err
+		}
+
+		lastErr = err
+		if i < len(c.members)-1 {
+			m.breaker.fellBack()
+			c.logger.Warnf("LLM profile %s failed, falling back to %s: %v", m.profile, c.members[i+1].profile, err)
+		}
+	}
+	return nil, lastErr
+}
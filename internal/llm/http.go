@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+)
+
+// 未配置时的默认值
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultTemperature = 0.1
+)
+
+// newHTTPClient 按配置的超时创建带链路追踪的HTTP客户端
+func newHTTPClient(profile config.LLMProfile) *http.Client {
+	timeout := time.Duration(profile.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)}
+}
+
+// profileTemperature 配置的温度，未设置时使用默认值
+func profileTemperature(profile config.LLMProfile) float64 {
+	if profile.Temperature != nil {
+		return *profile.Temperature
+	}
+	return defaultTemperature
+}
+
+// retryAfter 解析 Retry-After 响应头（秒）
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DecodeJSON 解析模型输出的JSON，兼容包裹在 ```json 代码块中或前后带有说明文字的输出
+func DecodeJSON(content string, v interface{}) error {
+	text := strings.TrimSpace(content)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+
+	if err := json.Unmarshal([]byte(text), v); err == nil {
+		return nil
+	}
+
+	// 截取第一个 { 到最后一个 } 之间的内容再尝试一次
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end <= start {
+		return fmt.Errorf("model output is not a JSON object")
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), v); err != nil {
+		return fmt.Errorf("failed to decode model output: %w", err)
+	}
+	return nil
+}
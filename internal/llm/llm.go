@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 消息角色
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// ErrNotConfigured 提供商缺少必要配置（如API Key）
+var ErrNotConfigured = errors.New("llm: provider not configured")
+
+// Message 对话消息
+type Message struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant 消息中模型请求的工具调用
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool 消息对应的调用ID
+}
+
+// Tool 可供模型调用的工具（函数）
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"` // JSON Schema
+}
+
+// ToolCall 模型发起的工具调用
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Request 对话请求；MaxTokens、Temperature 为空时使用配置中的默认值
+type Request struct {
+	Messages    []Message
+	Tools       []Tool
+	MaxTokens   int
+	Temperature *float64
+	JSONMode    bool // 要求模型输出JSON对象
+}
+
+// Usage token用量
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost_usd"` // 按 llm.pricing 估算，未配置单价时为0
+}
+
+// Add 累加另一次调用的用量
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.EstimatedCost += other.EstimatedCost
+}
+
+// Response 模型响应
+type Response struct {
+	Content      string        `json:"content"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	FinishReason string        `json:"finish_reason"`
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	Usage        Usage         `json:"usage"`
+	Latency      time.Duration `json:"latency_ns"`
+	Cached       bool          `json:"cached,omitempty"` // 来自回答缓存，未调用模型（Usage为0）
+}
+
+// StreamHandler 接收流式输出的增量文本，返回错误时中止请求
+type StreamHandler func(delta string) error
+
+// Client 与提供商无关的LLM客户端
+type Client interface {
+	// Complete 单轮补全（prompt作为用户消息）
+	Complete(ctx context.Context, prompt string) (*Response, error)
+
+	// Chat 多轮对话，支持工具调用
+	Chat(ctx context.Context, req Request) (*Response, error)
+
+	// Stream 流式对话，增量文本交给handler，返回汇总后的完整响应
+	Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error)
+
+	// Provider 提供商名称
+	Provider() string
+
+	// Model 模型名称
+	Model() string
+}
+
+// APIError 提供商返回的错误
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Type       string
+	Message    string
+	RetryAfter time.Duration // 429 时提供商建议的重试间隔
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("%s API error (status %d, %s): %s", e.Provider, e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Retryable 是否为可重试的错误（限流或服务端错误）
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// IsRetryable 判断错误是否可以重试或切换到其他提供商
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return false
+}
+
+// UserMessage 创建用户消息
+func UserMessage(content string) Message {
+	return Message{Role: RoleUser, Content: content}
+}
+
+// SystemMessage 创建系统消息
+func SystemMessage(content string) Message {
+	return Message{Role: RoleSystem, Content: content}
+}
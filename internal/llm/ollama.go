@@ -0,0 +1,283 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// ProviderOllama 本地 Ollama 服务（原生 /api/chat 接口），用于离线集群
+const ProviderOllama = "ollama"
+
+// defaultOllamaBaseURL 未配置 base_url 时使用的地址
+const defaultOllamaBaseURL = "http://localhost:11434"
+
+// OllamaClient Ollama 客户端，不需要API Key
+type OllamaClient struct {
+	baseURL     string
+	model       string
+	maxTokens   int
+	temperature float64
+	httpClient  *http.Client
+}
+
+// NewOllamaClient 根据LLM配置创建Ollama客户端
+func NewOllamaClient(profile config.LLMProfile) (*OllamaClient, error) {
+	if profile.Model == "" {
+		return nil, fmt.Errorf("%w: %s profile %q has no model", ErrNotConfigured, ProviderOllama, profile.Name)
+	}
+	baseURL := strings.TrimRight(profile.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	} else if !strings.Contains(baseURL, "://") {
+		// OLLAMA_HOST 通常写成 host:port
+		baseURL = "http://" + baseURL
+	}
+
+	return &OllamaClient{
+		baseURL:     baseURL,
+		model:       profile.Model,
+		maxTokens:   profile.MaxTokens,
+		temperature: profileTemperature(profile),
+		httpClient:  newHTTPClient(profile),
+	}, nil
+}
+
+// Provider 提供商名称
+func (c *OllamaClient) Provider() string {
+	return ProviderOllama
+}
+
+// Model 模型名称
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// Complete 单轮补全
+func (c *OllamaClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"` // JSON对象（不是字符串）
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaMessage        `json:"messages"`
+	Tools    []openAITool           `json:"tools,omitempty"`
+	Format   string                 `json:"format,omitempty"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// buildRequest 转换为 /api/chat 请求
+func (c *OllamaClient) buildRequest(req Request, stream bool) ollamaRequest {
+	options := map[string]interface{}{"temperature": c.temperature}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if maxTokens := req.MaxTokens; maxTokens > 0 {
+		options["num_predict"] = maxTokens
+	} else if c.maxTokens > 0 {
+		options["num_predict"] = c.maxTokens
+	}
+
+	body := ollamaRequest{
+		Model:    c.model,
+		Messages: make([]ollamaMessage, 0, len(req.Messages)),
+		Stream:   stream,
+		Options:  options,
+	}
+	if req.JSONMode {
+		body.Format = "json"
+	}
+	for _, message := range req.Messages {
+		converted := ollamaMessage{Role: message.Role, Content: message.Content}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = toolArguments(string(call.Arguments))
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		body.Messages = append(body.Messages, converted)
+	}
+	for _, tool := range req.Tools {
+		if len(tool.Parameters) == 0 {
+			tool.Parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: tool})
+	}
+	return body
+}
+
+// post 发送对话请求，非2xx响应转换为 *APIError
+func (c *OllamaClient) post(ctx context.Context, body ollamaRequest) (*http.Response, error) {
+	return c.send(ctx, "/api/chat", body)
+}
+
+// send 向 path 发送JSON请求，非2xx响应转换为 *APIError
+func (c *OllamaClient) send(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", ProviderOllama, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", ProviderOllama, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	// 错误格式 {"error": "..."}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{Provider: ProviderOllama, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	var payloadErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &payloadErr) == nil && payloadErr.Error != "" {
+		apiErr.Message = payloadErr.Error
+	}
+	return nil, apiErr
+}
+
+// Chat 多轮对话
+func (c *OllamaClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", ProviderOllama, err)
+	}
+	result := c.response(&payload, start)
+	result.Content = payload.Message.Content
+	result.ToolCalls = ollamaToolCalls(payload.Message.ToolCalls)
+	return result, nil
+}
+
+// Stream 流式对话（每行一个JSON对象，最后一行 done=true 带有用量）
+func (c *OllamaClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var calls []ollamaToolCall
+	var final ollamaResponse
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode %s stream chunk: %w", ProviderOllama, err)
+		}
+		if chunk.Error != "" {
+			return nil, &APIError{Provider: ProviderOllama, StatusCode: http.StatusInternalServerError, Message: chunk.Error}
+		}
+
+		if delta := chunk.Message.Content; delta != "" {
+			content.WriteString(delta)
+			if handler != nil {
+				if err := handler(delta); err != nil {
+					return nil, err
+				}
+			}
+		}
+		// Ollama 的工具调用不是增量的，每次给出完整调用
+		calls = append(calls, chunk.Message.ToolCalls...)
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s stream interrupted: %w", ProviderOllama, err)
+	}
+
+	result := c.response(&final, start)
+	result.Content = content.String()
+	result.ToolCalls = ollamaToolCalls(calls)
+	return result, nil
+}
+
+// response 构造不含内容的响应（模型、用量和结束原因）
+func (c *OllamaClient) response(payload *ollamaResponse, start time.Time) *Response {
+	model := payload.Model
+	if model == "" {
+		model = c.model
+	}
+	return &Response{
+		FinishReason: payload.DoneReason,
+		Provider:     ProviderOllama,
+		Model:        model,
+		Usage: Usage{
+			PromptTokens:     payload.PromptEvalCount,
+			CompletionTokens: payload.EvalCount,
+			TotalTokens:      payload.PromptEvalCount + payload.EvalCount,
+		},
+		Latency: time.Since(start),
+	}
+}
+
+// ollamaToolCalls 转换工具调用；Ollama 不返回调用ID，按顺序生成
+func ollamaToolCalls(calls []ollamaToolCall) []ToolCall {
+	var result []ToolCall
+	for i, call := range calls {
+		result = append(result, ToolCall{
+			ID:        fmt.Sprintf("call_%d", i),
+			Name:      call.Function.Name,
+			Arguments: toolArguments(string(call.Function.Arguments)),
+		})
+	}
+	return result
+}
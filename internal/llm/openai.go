@@ -0,0 +1,360 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// 使用 Chat Completions 协议的提供商
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible" // 兼容OpenAI接口的服务（vLLM、LocalAI、Azure网关等），需要配置 base_url
+)
+
+// defaultOpenAIBaseURL 未配置 base_url 时使用的地址
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// maxErrorBody 读取错误响应的最大长度
+const maxErrorBody = 64 << 10
+
+// OpenAIClient OpenAI（及兼容接口）的客户端
+type OpenAIClient struct {
+	provider    string
+	apiKey      string
+	baseURL     string
+	model       string
+	maxTokens   int
+	temperature float64
+	httpClient  *http.Client
+}
+
+// NewOpenAIClient 根据LLM配置创建OpenAI客户端
+func NewOpenAIClient(profile config.LLMProfile) (*OpenAIClient, error) {
+	if profile.APIKey == "" {
+		return nil, fmt.Errorf("%w: %s profile %q has no api_key", ErrNotConfigured, ProviderOpenAI, profile.Name)
+	}
+	baseURL := strings.TrimRight(profile.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	return newOpenAIClient(ProviderOpenAI, baseURL, profile), nil
+}
+
+// NewOpenAICompatibleClient 创建兼容OpenAI接口的客户端，api_key可以为空（本地部署的模型服务）
+func NewOpenAICompatibleClient(profile config.LLMProfile) (*OpenAIClient, error) {
+	baseURL := strings.TrimRight(profile.BaseURL, "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("%w: %s profile %q has no base_url", ErrNotConfigured, ProviderOpenAICompatible, profile.Name)
+	}
+	return newOpenAIClient(ProviderOpenAICompatible, baseURL, profile), nil
+}
+
+func newOpenAIClient(provider, baseURL string, profile config.LLMProfile) *OpenAIClient {
+	return &OpenAIClient{
+		provider:    provider,
+		apiKey:      profile.APIKey,
+		baseURL:     baseURL,
+		model:       profile.Model,
+		maxTokens:   profile.MaxTokens,
+		temperature: profileTemperature(profile),
+		httpClient:  newHTTPClient(profile),
+	}
+}
+
+// Provider 提供商名称
+func (c *OpenAIClient) Provider() string {
+	return c.provider
+}
+
+// Model 模型名称
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// Complete 单轮补全
+func (c *OpenAIClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// openAIMessage Chat Completions 消息格式
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	Index    int    `json:"index,omitempty"` // 仅出现在流式响应中
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function Tool   `json:"function"`
+}
+
+type openAIRequest struct {
+	Model          string            `json:"model"`
+	Messages       []openAIMessage   `json:"messages"`
+	Tools          []openAITool      `json:"tools,omitempty"`
+	MaxTokens      int               `json:"max_tokens,omitempty"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+	Stream         bool              `json:"stream,omitempty"`
+	StreamOptions  map[string]bool   `json:"stream_options,omitempty"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		Delta        openAIMessage `json:"delta"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+// buildRequest 转换为 Chat Completions 请求
+func (c *OpenAIClient) buildRequest(req Request, stream bool) openAIRequest {
+	body := openAIRequest{
+		Model:       c.model,
+		Messages:    make([]openAIMessage, 0, len(req.Messages)),
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		Stream:      stream,
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		body.Temperature = *req.Temperature
+	}
+	if req.JSONMode {
+		body.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+	if stream {
+		body.StreamOptions = map[string]bool{"include_usage": true}
+	}
+
+	for _, message := range req.Messages {
+		content := message.Content
+		converted := openAIMessage{Role: message.Role, Content: &content, ToolCallID: message.ToolCallID}
+		for _, call := range message.ToolCalls {
+			toolCall := openAIToolCall{ID: call.ID, Type: "function"}
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = string(call.Arguments)
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		// 只包含工具调用的assistant消息 content 为 null
+		if message.Role == RoleAssistant && content == "" && len(converted.ToolCalls) > 0 {
+			converted.Content = nil
+		}
+		body.Messages = append(body.Messages, converted)
+	}
+
+	for _, tool := range req.Tools {
+		if len(tool.Parameters) == 0 {
+			tool.Parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: tool})
+	}
+	return body
+}
+
+// post 发送对话请求，非2xx响应转换为 *APIError
+func (c *OpenAIClient) post(ctx context.Context, body openAIRequest) (*http.Response, error) {
+	return c.send(ctx, "/chat/completions", body, body.Stream)
+}
+
+// send 向 path 发送JSON请求，非2xx响应转换为 *APIError
+func (c *OpenAIClient) send(ctx context.Context, path string, body interface{}, stream bool) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", c.provider, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", c.provider, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, c.apiError(resp)
+}
+
+// apiError 解析错误响应 {"error": {"message": ..., "type": ...}}
+func (c *OpenAIClient) apiError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{Provider: c.provider, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil && payload.Error.Message != "" {
+		apiErr.Message = payload.Error.Message
+		apiErr.Type = payload.Error.Type
+	}
+	apiErr.RetryAfter = retryAfter(resp)
+	return apiErr
+}
+
+// Chat 多轮对话
+func (c *OpenAIClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var payload openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", c.provider, err)
+	}
+	if len(payload.Choices) == 0 {
+		return nil, fmt.Errorf("%s response has no choices", c.provider)
+	}
+
+	choice := payload.Choices[0]
+	result := &Response{
+		FinishReason: choice.FinishReason,
+		Provider:     c.provider,
+		Model:        payload.Model,
+		Latency:      time.Since(start),
+	}
+	if choice.Message.Content != nil {
+		result.Content = *choice.Message.Content
+	}
+	for _, call := range choice.Message.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: toolArguments(call.Function.Arguments),
+		})
+	}
+	if payload.Usage != nil {
+		result.Usage = *payload.Usage
+	}
+	return result, nil
+}
+
+// Stream 流式对话（SSE），工具调用的参数按 index 拼接
+func (c *OpenAIClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, c.buildRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &Response{Provider: c.provider, Model: c.model}
+	var content strings.Builder
+	var calls []*openAIToolCall
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode %s stream chunk: %w", c.provider, err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			result.FinishReason = choice.FinishReason
+		}
+		if delta := choice.Delta.Content; delta != nil && *delta != "" {
+			content.WriteString(*delta)
+			if handler != nil {
+				if err := handler(*delta); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, call := range choice.Delta.ToolCalls {
+			for len(calls) <= call.Index {
+				calls = append(calls, &openAIToolCall{})
+			}
+			merged := calls[call.Index]
+			if call.ID != "" {
+				merged.ID = call.ID
+			}
+			if call.Function.Name != "" {
+				merged.Function.Name = call.Function.Name
+			}
+			merged.Function.Arguments += call.Function.Arguments
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s stream interrupted: %w", c.provider, err)
+	}
+
+	result.Content = content.String()
+	for _, call := range calls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: toolArguments(call.Function.Arguments),
+		})
+	}
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// toolArguments 工具调用参数，空字符串视为空对象
+func toolArguments(arguments string) json.RawMessage {
+	if strings.TrimSpace(arguments) == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
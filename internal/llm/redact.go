@@ -0,0 +1,321 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// ErrExternalBlocked 严格模式下禁止调用外部提供商
+var ErrExternalBlocked = fmt.Errorf("%w: external providers are blocked by llm.redaction.strict", ErrNotConfigured)
+
+// redactedValue 替换敏感内容的占位符
+const redactedValue = "[REDACTED]"
+
+// jsonString JSON字符串字面量
+const jsonString = `"(?:[^"\\]|\\.)*"`
+
+// jsonObject 嵌套不超过两层的JSON对象（容器环境变量的 valueFrom.secretKeyRef）
+var jsonObject = nestedObject(2)
+
+// nestedObject 嵌套不超过 depth 层的JSON对象，字符串中的括号不计入嵌套
+func nestedObject(depth int) string {
+	object := `\{(?:` + jsonString + `|[^{}"])*\}`
+	for i := 0; i < depth; i++ {
+		object = `\{(?:` + jsonString + `|[^{}"]|` + object + `)*\}`
+	}
+	return object
+}
+
+// credentialKeyword 名称中表示凭证的关键字
+const credentialKeyword = `password|passwd|secret|token|api[_-]?key|apikey|access[_-]?key|credentials?`
+
+// tokenValue 看起来像令牌的值：含数字或符号，或者是16个以上的字母（普通英文单词不算）
+const tokenValue = `(?:[^\s"',;&]*[0-9_/+=@#$%!*~^-][^\s"',;&]*|[A-Za-z]{16,})`
+
+var (
+	// jsonPair JSON中的字符串键值对
+	jsonPair = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)` + jsonString)
+	// envValue 环境变量列表项中的值
+	envValue = regexp.MustCompile(`("value"\s*:\s*)` + jsonString)
+	// secretKind 内容中包含Kubernetes Secret对象
+	secretKind = regexp.MustCompile(`"kind"\s*:\s*"Secret"`)
+)
+
+// redactRule 内置脱敏规则：replace 为空时用 template 替换匹配内容
+type redactRule struct {
+	name     string
+	re       *regexp.Regexp
+	template string
+	replace  func(string) string
+	when     *regexp.Regexp // 内容匹配时才应用
+}
+
+// builtinRules kubectl apply 保存的原始配置、私钥、令牌、凭证赋值、容器环境变量和Secret数据
+var builtinRules = []redactRule{
+	{
+		// 注解值是整个对象的JSON（包括环境变量和Secret数据），整体替换
+		name:     "last_applied",
+		re:       regexp.MustCompile(`("kubectl\.kubernetes\.io/last-applied-configuration"\s*:\s*)` + jsonString),
+		template: `${1}"` + redactedValue + `"`,
+	},
+	{
+		name:     "private_key",
+		re:       regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`),
+		template: "[REDACTED PRIVATE KEY]",
+	},
+	{
+		name:     "jwt",
+		re:       regexp.MustCompile(`\beyJ[\w-]{5,}\.eyJ[\w-]{5,}\.[\w-]{5,}`),
+		template: redactedValue,
+	},
+	{
+		name:     "bearer_token",
+		re:       regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]{8,}`),
+		template: "${1} " + redactedValue,
+	},
+	{
+		name:     "url_credentials",
+		re:       regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://[^\s:/@"]+:)[^\s@/"]+@`),
+		template: "${1}" + redactedValue + "@",
+	},
+	{
+		name:     "api_key",
+		re:       regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|\bsk-[A-Za-z0-9_-]{20,}|\bgh[pousr]_[A-Za-z0-9]{30,}|\bxox[abpr]-[A-Za-z0-9-]{10,}`),
+		template: redactedValue,
+	},
+	{
+		name:     "credential_field",
+		re:       regexp.MustCompile(`"([^"\\]*(?i:` + credentialKeyword + `|private[_-]?key)[^"\\]*)"(\s*:\s*)` + jsonString),
+		template: `"${1}"${2}"` + redactedValue + `"`,
+	},
+	{
+		name:     "credential_assignment",
+		re:       regexp.MustCompile(`(?i)\b([\w.-]*(?:` + credentialKeyword + `))(\s*[=:]\s*["']?)` + tokenValue),
+		template: "${1}${2}" + redactedValue,
+	},
+	{
+		name:    "env",
+		re:      regexp.MustCompile(`"env"\s*:\s*\{(?:\s*` + jsonString + `\s*:\s*` + jsonString + `\s*,?)*\s*\}`),
+		replace: redactValues,
+	},
+	{
+		// Kubernetes容器定义中的列表形式 [{"name": ..., "value": ...}]，保留名称和 valueFrom 引用
+		name:    "env_list",
+		re:      regexp.MustCompile(`"env"\s*:\s*\[\s*(?:` + jsonObject + `\s*,?\s*)*\]`),
+		replace: redactEnvValues,
+	},
+	{
+		name:    "secret_data",
+		re:      regexp.MustCompile(`"(?:data|stringData)"\s*:\s*\{(?:\s*` + jsonString + `\s*:\s*` + jsonString + `\s*,?)*\s*\}`),
+		replace: redactValues,
+		when:    secretKind,
+	},
+}
+
+// redactValues 脱敏JSON对象中所有字符串值，保留键名
+func redactValues(object string) string {
+	return jsonPair.ReplaceAllString(object, `"${1}"${2}"`+redactedValue+`"`)
+}
+
+// redactEnvValues 脱敏环境变量列表中的 value
+func redactEnvValues(list string) string {
+	return envValue.ReplaceAllString(list, `${1}"`+redactedValue+`"`)
+}
+
+// RedactionStats 脱敏统计
+type RedactionStats struct {
+	Enabled       bool             `json:"enabled"`
+	Strict        bool             `json:"strict"`
+	LocalProfiles []string         `json:"local_profiles"`
+	Requests      int64            `json:"requests"`          // 发往外部提供商的请求数
+	Redacted      int64            `json:"redacted_requests"` // 其中包含敏感内容的请求数
+	Redactions    map[string]int64 `json:"redactions"`        // 规则 -> 脱敏次数
+	Blocked       int64            `json:"blocked"`           // 严格模式下因没有本地配置而拒绝的请求数
+}
+
+// redactor 发往外部提供商的内容脱敏
+type redactor struct {
+	mu          sync.Mutex
+	enabled     bool
+	patterns    []*regexp.Regexp
+	annotations []*regexp.Regexp
+	stats       RedactionStats
+	logger      *logrus.Entry
+}
+
+func newRedactor(cfg config.LLMConfig, logger *logrus.Entry) *redactor {
+	r := &redactor{logger: logger, stats: RedactionStats{Redactions: make(map[string]int64)}}
+	r.configure(cfg)
+	return r
+}
+
+// configure 应用新的脱敏配置（配置热更新），无效的正则被忽略
+func (r *redactor) configure(cfg config.LLMConfig) {
+	patterns := r.compile("patterns", cfg.Redaction.Patterns)
+	annotations := r.compile("annotation_patterns", cfg.Redaction.AnnotationPatterns)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = cfg.Redaction.Enabled
+	r.patterns = patterns
+	r.annotations = annotations
+	r.stats.Enabled = cfg.Redaction.Enabled
+	r.stats.Strict = cfg.Redaction.Strict
+	r.stats.LocalProfiles = cfg.Redaction.LocalProfiles
+}
+
+func (r *redactor) compile(field string, patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			r.logger.Warnf("Ignoring llm.redaction.%s entry %q: %v", field, pattern, err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// snapshot 返回脱敏统计
+func (r *redactor) snapshot() RedactionStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.Redactions = make(map[string]int64, len(r.stats.Redactions))
+	for rule, n := range r.stats.Redactions {
+		stats.Redactions[rule] = n
+	}
+	if stats.LocalProfiles == nil {
+		stats.LocalProfiles = []string{}
+	}
+	return stats
+}
+
+// blocked 记录一次被严格模式拒绝的请求
+func (r *redactor) blocked() {
+	r.mu.Lock()
+	r.stats.Blocked++
+	r.mu.Unlock()
+}
+
+// redactTexts 脱敏一组文本，返回新的切片；未启用时原样返回
+func (r *redactor) redactTexts(texts []string) []string {
+	r.mu.Lock()
+	enabled, patterns, annotations := r.enabled, r.patterns, r.annotations
+	r.mu.Unlock()
+
+	counts := make(map[string]int64)
+	result := texts
+	if enabled {
+		result = make([]string, len(texts))
+		for i, text := range texts {
+			result[i] = redact(text, patterns, annotations, counts)
+		}
+	}
+
+	r.mu.Lock()
+	r.stats.Requests++
+	if len(counts) > 0 {
+		r.stats.Redacted++
+		for rule, n := range counts {
+			r.stats.Redactions[rule] += n
+		}
+	}
+	r.mu.Unlock()
+	return result
+}
+
+// redact 按内置规则、注解键和自定义正则依次脱敏，counts 累计各规则的替换次数
+func redact(text string, patterns, annotations []*regexp.Regexp, counts map[string]int64) string {
+	for _, rule := range builtinRules {
+		if rule.when != nil && !rule.when.MatchString(text) {
+			continue
+		}
+		n := len(rule.re.FindAllStringIndex(text, -1))
+		if n == 0 {
+			continue
+		}
+		if rule.replace != nil {
+			text = rule.re.ReplaceAllStringFunc(text, rule.replace)
+		} else {
+			text = rule.re.ReplaceAllString(text, rule.template)
+		}
+		counts[rule.name] += int64(n)
+	}
+
+	if len(annotations) > 0 {
+		text = jsonPair.ReplaceAllStringFunc(text, func(pair string) string {
+			match := jsonPair.FindStringSubmatch(pair)
+			for _, re := range annotations {
+				if re.MatchString(match[1]) {
+					counts["annotation"]++
+					return `"` + match[1] + `"` + match[2] + `"` + redactedValue + `"`
+				}
+			}
+			return pair
+		})
+	}
+
+	for _, re := range patterns {
+		if n := len(re.FindAllStringIndex(text, -1)); n > 0 {
+			text = re.ReplaceAllLiteralString(text, redactedValue)
+			counts["custom"] += int64(n)
+		}
+	}
+	return text
+}
+
+// redactingClient 发往外部提供商前脱敏所有消息内容
+type redactingClient struct {
+	Client
+	redactor *redactor
+}
+
+// Complete 单轮补全
+func (c *redactingClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// Chat 多轮对话
+func (c *redactingClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	return c.Client.Chat(ctx, c.redact(req))
+}
+
+// Stream 流式对话
+func (c *redactingClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	return c.Client.Stream(ctx, c.redact(req), handler)
+}
+
+// redact 返回消息内容脱敏后的请求副本（不修改调用方的消息，调用方可能保存对话历史）
+func (c *redactingClient) redact(req Request) Request {
+	contents := make([]string, len(req.Messages))
+	for i, message := range req.Messages {
+		contents[i] = message.Content
+	}
+	contents = c.redactor.redactTexts(contents)
+
+	messages := make([]Message, len(req.Messages))
+	for i, message := range req.Messages {
+		message.Content = contents[i]
+		messages[i] = message
+	}
+	req.Messages = messages
+	return req
+}
+
+// redactingEmbedder 向外部提供商发送向量化文本前脱敏
+type redactingEmbedder struct {
+	Embedder
+	redactor *redactor
+}
+
+// Embed 文本向量化
+func (e *redactingEmbedder) Embed(ctx context.Context, texts []string) (*Embedding, error) {
+	return e.Embedder.Embed(ctx, e.redactor.redactTexts(texts))
+}
@@ -0,0 +1,287 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New 根据LLM配置创建客户端
+func New(profile config.LLMProfile) (Client, error) {
+	switch profile.Provider {
+	case "", ProviderOpenAI:
+		return NewOpenAIClient(profile)
+	case ProviderOpenAICompatible:
+		return NewOpenAICompatibleClient(profile)
+	case ProviderAnthropic:
+		return NewAnthropicClient(profile)
+	case ProviderOllama:
+		return NewOllamaClient(profile)
+	default:
+		return nil, fmt.Errorf("%w: unsupported provider %q", ErrNotConfigured, profile.Provider)
+	}
+}
+
+// Service 按分析类型和命名配置选择LLM客户端，配置变更时重建客户端
+type Service struct {
+	mu       sync.Mutex
+	cfg      config.LLMConfig
+	clients  map[string]Client
+	breakers map[string]*breaker // 按配置名，配置热更新后保留
+	usage    *usageTracker
+	cache    *responseCache
+	redactor *redactor
+	logger   *logrus.Entry
+}
+
+// NewService 创建LLM服务
+func NewService(cfg config.LLMConfig) *Service {
+	logger := logging.For("llm")
+	return &Service{
+		cfg:      cfg,
+		clients:  make(map[string]Client),
+		breakers: make(map[string]*breaker),
+		usage:    newUsageTracker(cfg, logger),
+		cache:    newResponseCache(cfg.Cache),
+		redactor: newRedactor(cfg, logger),
+		logger:   logger,
+	}
+}
+
+// SetStore 持久化每日用量，并加载当天已有的用量（重启后预算继续累计）
+func (s *Service) SetStore(ctx context.Context, store storage.Store) error {
+	return s.usage.setStore(ctx, store)
+}
+
+// Update 应用新的LLM配置（配置热更新），已创建的客户端在下次使用时重建
+func (s *Service) Update(cfg config.LLMConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.clients = make(map[string]Client)
+	for _, b := range s.breakers {
+		b.configure(cfg.Breaker.FailureThreshold, time.Duration(cfg.Breaker.Cooldown)*time.Second)
+	}
+	s.usage.configure(cfg)
+	s.cache.configure(cfg.Cache)
+	s.redactor.configure(cfg)
+}
+
+// Client 返回分析类型对应的客户端：请求指定的配置 > 分析类型路由 > 默认配置。
+// 配置了 fallbacks 时返回的客户端在提供商限流或故障时依次尝试后备配置；
+// 发往外部提供商的消息先脱敏，llm.redaction.strict 时只使用本地配置
+func (s *Service) Client(analysisType, profileName string) (Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chain, err := s.cfg.Chain(analysisType, profileName)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.Redaction.Strict {
+		if chain = s.cfg.LocalChain(chain); len(chain) == 0 {
+			s.redactor.blocked()
+			return nil, fmt.Errorf("%w and no local profile is configured", ErrExternalBlocked)
+		}
+	}
+	if client, ok := s.clients[chain[0].Name]; ok {
+		return &typedClient{Client: client, analysisType: analysisType}, nil
+	}
+
+	fallback := &fallbackClient{usage: s.usage, logger: s.logger}
+	cached := &cachedClient{Client: fallback, scope: chain[0].Name, cache: s.cache}
+	for i, profile := range chain {
+		client, err := New(profile)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			// 后备配置不可用（例如缺少API Key）时跳过，不影响主配置
+			s.logger.Warnf("LLM fallback profile %s for %s skipped: %v", profile.Name, chain[0].Name, err)
+			continue
+		}
+		if !s.cfg.IsLocal(profile) {
+			client = &redactingClient{Client: client, redactor: s.redactor}
+		}
+		fallback.members = append(fallback.members, member{
+			profile: profile.Name,
+			client:  &tracedClient{Client: client, profile: profile.Name, usage: s.usage, logger: s.logger},
+			breaker: s.breaker(profile.Name),
+			budget:  profile.DailyTokenBudget,
+		})
+	}
+	s.clients[chain[0].Name] = cached
+	return &typedClient{Client: cached, analysisType: analysisType}, nil
+}
+
+// breaker 返回配置对应的熔断器，调用方需持有锁
+func (s *Service) breaker(profile string) *breaker {
+	b, ok := s.breakers[profile]
+	if !ok {
+		b = newBreaker(s.cfg.Breaker.FailureThreshold, time.Duration(s.cfg.Breaker.Cooldown)*time.Second)
+		s.breakers[profile] = b
+	}
+	return b
+}
+
+// Status 返回所有配置的健康状态（按配置名排序，default在前）
+func (s *Service) Status() []ProviderStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := s.cfg.ProfileNames()
+	statuses := make([]ProviderStatus, 0, len(names))
+	for _, name := range names {
+		profile, err := s.cfg.Profile(name)
+		if err != nil {
+			continue
+		}
+		status := s.breaker(profile.Name).snapshot()
+		status.Profile = profile.Name
+		status.Provider = profile.Provider
+		if status.Provider == "" {
+			status.Provider = ProviderOpenAI
+		}
+		status.Model = profile.Model
+		status.Local = s.cfg.IsLocal(profile)
+		status.Blocked = s.cfg.Redaction.Strict && !status.Local
+		status.Configured = true
+		if _, err := New(profile); err != nil {
+			status.Configured = false
+			status.ConfigError = err.Error()
+			status.Healthy = false
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Usage 返回今天和之前 days-1 天的用量、预算使用情况和最近的调用
+func (s *Service) Usage(ctx context.Context, days int) UsageReport {
+	s.mu.Lock()
+	budgets := make(map[string]int)
+	for _, name := range s.cfg.ProfileNames() {
+		if profile, err := s.cfg.Profile(name); err == nil && profile.DailyTokenBudget > 0 {
+			budgets[name] = profile.DailyTokenBudget
+		}
+	}
+	action := s.cfg.BudgetAction
+	s.mu.Unlock()
+
+	if action == "" {
+		action = config.BudgetReject
+	}
+	return s.usage.report(ctx, days, budgets, action)
+}
+
+// CacheStats 回答缓存状态
+func (s *Service) CacheStats() CacheStats {
+	return s.cache.stats()
+}
+
+// InvalidateCache 清空回答缓存（例如集群发生了指标快照无法反映的变更），返回清除的回答数
+func (s *Service) InvalidateCache() int {
+	n := s.cache.invalidate()
+	s.logger.Infof("LLM response cache invalidated, %d entries removed", n)
+	return n
+}
+
+// RedactionStats 脱敏和严格模式统计
+func (s *Service) RedactionStats() RedactionStats {
+	return s.redactor.snapshot()
+}
+
+// DegradeOnBudget 超出预算时是否退回规则分析（llm.budget_action: degrade）
+func (s *Service) DegradeOnBudget() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg.BudgetAction == config.BudgetDegrade
+}
+
+// Available 默认配置是否可用（例如已配置API Key）
+func (s *Service) Available() bool {
+	if s == nil {
+		return false
+	}
+	_, err := s.Client("", "")
+	return err == nil
+}
+
+// tracedClient 为每次调用记录span和用量日志
+type tracedClient struct {
+	Client
+	profile string
+	usage   *usageTracker
+	logger  *logrus.Entry
+}
+
+// Complete 单轮补全
+func (c *tracedClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// Chat 多轮对话
+func (c *tracedClient) Chat(ctx context.Context, req Request) (resp *Response, err error) {
+	ctx, span := c.start(ctx, "llm.Chat", req)
+	defer func() { c.finish(ctx, span, resp, err) }()
+	return c.Client.Chat(ctx, req)
+}
+
+// Stream 流式对话
+func (c *tracedClient) Stream(ctx context.Context, req Request, handler StreamHandler) (resp *Response, err error) {
+	ctx, span := c.start(ctx, "llm.Stream", req)
+	defer func() { c.finish(ctx, span, resp, err) }()
+	return c.Client.Stream(ctx, req, handler)
+}
+
+func (c *tracedClient) start(ctx context.Context, name string, req Request) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, name,
+		attribute.String("llm.provider", c.Provider()),
+		attribute.String("llm.model", c.Model()),
+		attribute.String("llm.profile", c.profile),
+		attribute.Int("llm.messages", len(req.Messages)),
+		attribute.Int("llm.tools", len(req.Tools)),
+	)
+	return ctx, span
+}
+
+func (c *tracedClient) finish(ctx context.Context, span trace.Span, resp *Response, err error) {
+	if resp != nil {
+		resp.Usage.EstimatedCost = c.usage.cost(resp.Model, resp.Usage)
+		c.usage.record(UsageRecord{
+			Timestamp: time.Now().UTC(),
+			Profile:   c.profile,
+			Provider:  resp.Provider,
+			Model:     resp.Model,
+			Type:      analysisType(ctx),
+			Usage:     resp.Usage,
+			LatencyMs: resp.Latency.Milliseconds(),
+		})
+		span.SetAttributes(
+			attribute.Int("llm.prompt_tokens", resp.Usage.PromptTokens),
+			attribute.Int("llm.completion_tokens", resp.Usage.CompletionTokens),
+			attribute.String("llm.finish_reason", resp.FinishReason),
+		)
+		c.logger.WithFields(logrus.Fields{
+			"provider":          resp.Provider,
+			"model":             resp.Model,
+			"profile":           c.profile,
+			"prompt_tokens":     resp.Usage.PromptTokens,
+			"completion_tokens": resp.Usage.CompletionTokens,
+			"latency_ms":        resp.Latency.Milliseconds(),
+		}).Debug("LLM call completed")
+	}
+	if err != nil {
+		c.logger.Warnf("LLM call to %s (%s) failed: %v", c.Provider(), c.Model(), err)
+	}
+	tracing.End(span, err)
+}
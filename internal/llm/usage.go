@@ -0,0 +1,381 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// ErrBudgetExceeded 超出每日token预算
+var ErrBudgetExceeded = errors.New("llm: daily token budget exceeded")
+
+const (
+	usageBucket     = "llm_usage" // 按日期（UTC）保存每日用量
+	maxUsageRecords = 100         // 保留的最近调用记录数
+	dateLayout      = "2006-01-02"
+)
+
+// UsageTotals 一组调用的token和费用合计
+type UsageTotals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost_usd"`
+}
+
+func (t *UsageTotals) add(u Usage) {
+	t.Requests++
+	t.PromptTokens += u.PromptTokens
+	t.CompletionTokens += u.CompletionTokens
+	t.TotalTokens += u.TotalTokens
+	t.EstimatedCost += u.EstimatedCost
+}
+
+func (t *UsageTotals) merge(other UsageTotals) {
+	t.Requests += other.Requests
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+	t.TotalTokens += other.TotalTokens
+	t.EstimatedCost += other.EstimatedCost
+}
+
+// DailyUsage 一天（UTC）的用量，按配置和分析类型细分
+type DailyUsage struct {
+	Date string `json:"date"`
+	UsageTotals
+	Profiles map[string]*UsageTotals `json:"profiles"`
+	Types    map[string]*UsageTotals `json:"types"` // llm.routing 中的分析类型，未指定类型的调用记为 "default"
+}
+
+// UsageRecord 一次调用的用量
+type UsageRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Profile   string    `json:"profile"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Type      string    `json:"type"`
+	Usage
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+// BudgetStatus 每日预算的使用情况
+type BudgetStatus struct {
+	Scope       string `json:"scope"` // "total" 或配置名
+	DailyTokens int    `json:"daily_tokens"`
+	Used        int    `json:"used"`
+	Remaining   int    `json:"remaining"`
+	Exceeded    bool   `json:"exceeded"`
+}
+
+// UsageReport 用量报告
+type UsageReport struct {
+	Today        DailyUsage     `json:"today"`
+	Budgets      []BudgetStatus `json:"budgets"`
+	BudgetAction string         `json:"budget_action"`
+	History      []DailyUsage   `json:"history"` // 之前各天的用量，日期降序
+	Recent       []UsageRecord  `json:"recent"`  // 最近的调用，最新的在前
+}
+
+// usageTracker 统计每次调用的token和估算费用，并检查每日预算
+type usageTracker struct {
+	mu      sync.Mutex
+	today   *DailyUsage
+	recent  []UsageRecord
+	budget  int
+	pricing map[string]config.LLMPrice
+	store   storage.Store
+	logger  *logrus.Entry
+}
+
+func newUsageTracker(cfg config.LLMConfig, logger *logrus.Entry) *usageTracker {
+	return &usageTracker{
+		today:   newDailyUsage(time.Now()),
+		budget:  cfg.DailyTokenBudget,
+		pricing: pricingTable(cfg.Pricing),
+		logger:  logger,
+	}
+}
+
+func newDailyUsage(now time.Time) *DailyUsage {
+	return &DailyUsage{
+		Date:     now.UTC().Format(dateLayout),
+		Profiles: make(map[string]*UsageTotals),
+		Types:    make(map[string]*UsageTotals),
+	}
+}
+
+// configure 应用新的预算和单价（配置热更新）
+func (t *usageTracker) configure(cfg config.LLMConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget = cfg.DailyTokenBudget
+	t.pricing = pricingTable(cfg.Pricing)
+}
+
+// pricingTable 模型名统一为小写（viper 读取的键已是小写）
+func pricingTable(pricing map[string]config.LLMPrice) map[string]config.LLMPrice {
+	table := make(map[string]config.LLMPrice, len(pricing))
+	for name, price := range pricing {
+		table[strings.ToLower(name)] = price
+	}
+	return table
+}
+
+// rollover 跨天时重新开始计数，调用方需持有锁
+func (t *usageTracker) rollover(now time.Time) {
+	if date := now.UTC().Format(dateLayout); t.today.Date != date {
+		t.today = newDailyUsage(now)
+	}
+}
+
+// allow 检查总预算和配置的预算是否已用完
+func (t *usageTracker) allow(profile string, profileBudget int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(time.Now())
+
+	if t.budget > 0 && t.today.TotalTokens >= t.budget {
+		return fmt.Errorf("%w: %d of %d tokens used today", ErrBudgetExceeded, t.today.TotalTokens, t.budget)
+	}
+	if used := t.today.Profiles[profile]; profileBudget > 0 && used != nil && used.TotalTokens >= profileBudget {
+		return fmt.Errorf("%w: profile %s used %d of %d tokens today", ErrBudgetExceeded, profile, used.TotalTokens, profileBudget)
+	}
+	return nil
+}
+
+// cost 按模型单价估算费用；没有完全匹配时使用最长的前缀匹配（如 gpt-4o 匹配 gpt-4o-2024-08-06）
+func (t *usageTracker) cost(model string, usage Usage) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	model = strings.ToLower(model)
+	price, ok := t.pricing[model]
+	if !ok {
+		matched := ""
+		for name, p := range t.pricing {
+			if strings.HasPrefix(model, name) && len(name) > len(matched) {
+				matched, price, ok = name, p, true
+			}
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+}
+
+// record 记录一次调用，并保存当天的用量
+func (t *usageTracker) record(record UsageRecord) {
+	if record.Type == "" {
+		record.Type = "default"
+	}
+
+	t.mu.Lock()
+	t.rollover(record.Timestamp)
+	t.today.UsageTotals.add(record.Usage)
+	for _, entry := range []struct {
+		totals map[string]*UsageTotals
+		key    string
+	}{{t.today.Profiles, record.Profile}, {t.today.Types, record.Type}} {
+		totals, ok := entry.totals[entry.key]
+		if !ok {
+			totals = &UsageTotals{}
+			entry.totals[entry.key] = totals
+		}
+		totals.add(record.Usage)
+	}
+	t.recent = append(t.recent, record)
+	if len(t.recent) > maxUsageRecords {
+		t.recent = t.recent[len(t.recent)-maxUsageRecords:]
+	}
+
+	store, date := t.store, t.today.Date
+	var data []byte
+	var err error
+	if store != nil {
+		data, err = json.Marshal(t.today)
+	}
+	t.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = store.Put(ctx, usageBucket, date, data)
+	}
+	if err != nil {
+		t.logger.Warnf("Failed to persist LLM usage: %v", err)
+	}
+}
+
+// setStore 设置持久化存储并加载当天已有的用量（重启后预算继续累计）
+func (t *usageTracker) setStore(ctx context.Context, store storage.Store) error {
+	now := time.Now()
+	data, err := store.Get(ctx, usageBucket, now.UTC().Format(dateLayout))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("failed to load llm usage: %w", err)
+	}
+
+	var saved *DailyUsage
+	if err == nil {
+		saved = &DailyUsage{}
+		if err := json.Unmarshal(data, saved); err != nil {
+			return fmt.Errorf("failed to decode llm usage: %w", err)
+		}
+		if saved.Profiles == nil {
+			saved.Profiles = make(map[string]*UsageTotals)
+		}
+		if saved.Types == nil {
+			saved.Types = make(map[string]*UsageTotals)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+	if saved != nil && saved.Date == t.today.Date {
+		// 加载前已有的调用（通常没有）合并到保存的用量中
+		saved.merge(t.today)
+		t.today = saved
+	}
+	return nil
+}
+
+// report 生成用量报告，days 为包括今天在内的天数
+func (t *usageTracker) report(ctx context.Context, days int, profileBudgets map[string]int, action string) UsageReport {
+	t.mu.Lock()
+	t.rollover(time.Now())
+	today := t.today.clone()
+	budget := t.budget
+	store := t.store
+	recent := make([]UsageRecord, 0, len(t.recent))
+	for i := len(t.recent) - 1; i >= 0; i-- {
+		recent = append(recent, t.recent[i])
+	}
+	t.mu.Unlock()
+
+	report := UsageReport{
+		Today:        *today,
+		Budgets:      []BudgetStatus{},
+		BudgetAction: action,
+		History:      []DailyUsage{},
+		Recent:       recent,
+	}
+	if budget > 0 {
+		report.Budgets = append(report.Budgets, budgetStatus("total", budget, today.TotalTokens))
+	}
+	for profile, limit := range profileBudgets {
+		used := 0
+		if totals := today.Profiles[profile]; totals != nil {
+			used = totals.TotalTokens
+		}
+		report.Budgets = append(report.Budgets, budgetStatus(profile, limit, used))
+	}
+	sort.SliceStable(report.Budgets, func(i, j int) bool {
+		// total 在前，其余按配置名排序
+		if (report.Budgets[i].Scope == "total") != (report.Budgets[j].Scope == "total") {
+			return report.Budgets[i].Scope == "total"
+		}
+		return report.Budgets[i].Scope < report.Budgets[j].Scope
+	})
+
+	if store != nil {
+		now := time.Now().UTC()
+		for i := 1; i < days; i++ {
+			date := now.AddDate(0, 0, -i).Format(dateLayout)
+			data, err := store.Get(ctx, usageBucket, date)
+			if err != nil {
+				if !errors.Is(err, storage.ErrNotFound) {
+					t.logger.Warnf("Failed to load LLM usage for %s: %v", date, err)
+				}
+				continue
+			}
+			var day DailyUsage
+			if err := json.Unmarshal(data, &day); err == nil {
+				report.History = append(report.History, day)
+			}
+		}
+	}
+	return report
+}
+
+func budgetStatus(scope string, limit, used int) BudgetStatus {
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return BudgetStatus{Scope: scope, DailyTokens: limit, Used: used, Remaining: remaining, Exceeded: used >= limit}
+}
+
+// merge 合并同一天的另一份用量
+func (d *DailyUsage) merge(other *DailyUsage) {
+	d.UsageTotals.merge(other.UsageTotals)
+	for _, m := range []struct{ dst, src map[string]*UsageTotals }{{d.Profiles, other.Profiles}, {d.Types, other.Types}} {
+		for key, totals := range m.src {
+			if existing, ok := m.dst[key]; ok {
+				existing.merge(*totals)
+			} else {
+				copied := *totals
+				m.dst[key] = &copied
+			}
+		}
+	}
+}
+
+// clone 复制当天用量，调用方需持有锁
+func (d *DailyUsage) clone() *DailyUsage {
+	c := *d
+	c.Profiles = make(map[string]*UsageTotals, len(d.Profiles))
+	for k, v := range d.Profiles {
+		totals := *v
+		c.Profiles[k] = &totals
+	}
+	c.Types = make(map[string]*UsageTotals, len(d.Types))
+	for k, v := range d.Types {
+		totals := *v
+		c.Types[k] = &totals
+	}
+	return &c
+}
+
+// analysisTypeKey 在上下文中传递分析类型，用于按类型统计用量
+type analysisTypeKey struct{}
+
+// typedClient 为调用附加分析类型
+type typedClient struct {
+	Client
+	analysisType string
+}
+
+// Complete 单轮补全
+func (c *typedClient) Complete(ctx context.Context, prompt string) (*Response, error) {
+	return c.Chat(ctx, Request{Messages: []Message{UserMessage(prompt)}})
+}
+
+// Chat 多轮对话
+func (c *typedClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	return c.Client.Chat(context.WithValue(ctx, analysisTypeKey{}, c.analysisType), req)
+}
+
+// Stream 流式对话
+func (c *typedClient) Stream(ctx context.Context, req Request, handler StreamHandler) (*Response, error) {
+	return c.Client.Stream(context.WithValue(ctx, analysisTypeKey{}, c.analysisType), req, handler)
+}
+
+// analysisType 上下文中的分析类型
+func analysisType(ctx context.Context) string {
+	value, _ := ctx.Value(analysisTypeKey{}).(string)
+	return value
+}
@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// 日志格式
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+var (
+	base    = newBase()
+	outMu   sync.Mutex
+	outFile *os.File
+)
+
+// newBase 创建默认的基础日志器（JSON输出到stdout，info级别），在 Configure 之前使用
+func newBase() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(os.Stdout)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+	return logger
+}
+
+// Configure 按配置设置级别、格式和输出，并将标准库log重定向到同一输出。
+// component 为调用方（进程）名称，用于标准库log输出的 component 字段
+func Configure(cfg config.LoggingConfig, component string) error {
+	level := logrus.InfoLevel
+	if cfg.Level != "" {
+		parsed, err := logrus.ParseLevel(cfg.Level)
+		if err != nil {
+			return fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+		}
+		level = parsed
+	}
+
+	var formatter logrus.Formatter
+	switch strings.ToLower(strings.TrimSpace(cfg.Format)) {
+	case "", FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	case FormatText:
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	default:
+		return fmt.Errorf("unsupported log format %q (expected json or text)", cfg.Format)
+	}
+
+	out, file, err := openOutput(cfg.Output)
+	if err != nil {
+		return err
+	}
+
+	outMu.Lock()
+	previous := outFile
+	outFile = file
+	outMu.Unlock()
+
+	base.SetOutput(out)
+	base.SetFormatter(formatter)
+	base.SetLevel(level)
+	if previous != nil {
+		previous.Close()
+	}
+
+	redirectStdLog(component)
+	return nil
+}
+
+// openOutput 解析输出目标：stdout、stderr 或文件路径
+func openOutput(output string) (io.Writer, *os.File, error) {
+	switch strings.ToLower(strings.TrimSpace(output)) {
+	case "", "stdout":
+		return os.Stdout, nil, nil
+	case "stderr":
+		return os.Stderr, nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file %s: %w", output, err)
+	}
+	return file, file, nil
+}
+
+// redirectStdLog 将标准库log的输出转为结构化日志
+func redirectStdLog(component string) {
+	if component == "" {
+		component = "main"
+	}
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{entry: For(component)})
+}
+
+// stdLogWriter 同步写入的适配器（log.Fatal 紧接着退出进程，不能异步写入）
+type stdLogWriter struct {
+	entry *logrus.Entry
+}
+
+// Write 每次调用对应一条标准库日志；以 Warning/Failed 开头的消息提升级别
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	switch {
+	case strings.HasPrefix(message, "Warning"):
+		w.entry.Warn(message)
+	case strings.HasPrefix(message, "Failed"), strings.HasPrefix(message, "Error"):
+		w.entry.Error(message)
+	default:
+		w.entry.Info(message)
+	}
+	return len(p), nil
+}
+
+// For 返回带 component 字段的日志入口，所有组件共享同一个基础日志器的级别、格式和输出
+func For(component string) *logrus.Entry {
+	return base.WithField("component", component)
+}
+
+// SetLevel 调整全局日志级别（配置热更新）
+func SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	base.SetLevel(parsed)
+	return nil
+}
+
+// Level 返回当前日志级别
+func Level() logrus.Level {
+	return base.GetLevel()
+}
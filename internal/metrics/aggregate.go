@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/telemetry"
+)
+
+// AggregateQuery 聚合查询条件
+type AggregateQuery struct {
+	Target       string   // node, pod, pair, uav
+	Key          string   // 实体标识（uav为节点名，其余见 SampleKey）
+	Metric       string   // 指标字段名，例如 cpu_usage_rate、rtt_ms、battery_percent
+	Aggregations []string // avg, min, max, sum, count, last, pXX
+	From         time.Time
+	To           time.Time
+	Step         time.Duration // >0 时按窗口分桶返回序列
+	Tier         string        // raw、1m、10m，为空时按时间范围选择（uav 只有原始样本）
+}
+
+// AggregatePoint 一个时间窗口的聚合结果
+type AggregatePoint struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// AggregateResult 聚合查询结果
+type AggregateResult struct {
+	Target  string             `json:"target"`
+	Key     string             `json:"key"`
+	Metric  string             `json:"metric"`
+	From    time.Time          `json:"from,omitempty"`
+	To      time.Time          `json:"to,omitempty"`
+	Step    string             `json:"step,omitempty"`
+	Tier    string             `json:"tier"`    // 读取的降采样层
+	Points  int                `json:"points"`  // 读取的记录数（聚合层为窗口数）
+	Samples int                `json:"samples"` // 包含的原始样本数
+	Summary map[string]float64 `json:"summary"`
+	Series  []AggregatePoint   `json:"series,omitempty"`
+}
+
+// sample 一条记录中的指标值，原始样本只包含一个值
+type sample struct {
+	timestamp time.Time
+	value     RollupValue
+}
+
+// ValidateAggregation 校验聚合函数名
+func ValidateAggregation(name string) error {
+	switch name {
+	case "avg", "min", "max", "sum", "count", "last":
+		return nil
+	}
+	if _, err := parsePercentile(name); err == nil {
+		return nil
+	}
+	return fmt.Errorf("unsupported aggregation: %s", name)
+}
+
+// Aggregate 在存储的历史数据上计算聚合
+func Aggregate(ctx context.Context, store storage.Store, query AggregateQuery) (*AggregateResult, error) {
+	if store == nil {
+		return nil, fmt.Errorf("storage not configured")
+	}
+	if query.Metric == "" {
+		return nil, fmt.Errorf("metric is required")
+	}
+	if len(query.Aggregations) == 0 {
+		query.Aggregations = []string{"avg", "min", "max"}
+	}
+	for _, agg := range query.Aggregations {
+		if err := ValidateAggregation(agg); err != nil {
+			return nil, err
+		}
+	}
+
+	var points []HistoryPoint
+	var tier HistoryTier
+	var err error
+	if query.Target == TargetUAV {
+		if query.Tier != "" && query.Tier != TierRaw {
+			return nil, fmt.Errorf("uav history has no %s tier", query.Tier)
+		}
+		tier = HistoryTier{Name: TierRaw, Collection: telemetry.UAVCollection}
+		points, err = readTier(ctx, store, tier, storage.Query{Key: query.Key, From: query.From, To: query.To}, query.Metric)
+	} else {
+		points, tier, err = ReadHistory(ctx, store, query.Tier, query.Key, query.Metric, query.From, query.To)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]sample, 0, len(points))
+	count := 0
+	for _, point := range points {
+		value := point.Values[query.Metric]
+		samples = append(samples, sample{timestamp: point.Timestamp, value: value})
+		count += value.Count
+	}
+
+	result := &AggregateResult{
+		Target:  query.Target,
+		Key:     query.Key,
+		Metric:  query.Metric,
+		From:    query.From,
+		To:      query.To,
+		Tier:    tier.Name,
+		Points:  len(samples),
+		Samples: count,
+		Summary: computeAggregations(samples, query.Aggregations),
+	}
+
+	if query.Step > 0 {
+		result.Step = query.Step.String()
+		result.Series = []AggregatePoint{}
+
+		var bucket []sample
+		var bucketStart time.Time
+		for _, s := range samples {
+			start := s.timestamp.Truncate(query.Step)
+			if len(bucket) > 0 && !start.Equal(bucketStart) {
+				result.Series = append(result.Series, AggregatePoint{
+					Timestamp: bucketStart,
+					Values:    computeAggregations(bucket, query.Aggregations),
+				})
+				bucket = bucket[:0]
+			}
+			bucketStart = start
+			bucket = append(bucket, s)
+		}
+		if len(bucket) > 0 {
+			result.Series = append(result.Series, AggregatePoint{
+				Timestamp: bucketStart,
+				Values:    computeAggregations(bucket, query.Aggregations),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// computeAggregations 计算一组样本的聚合值：avg、sum、count 按原始样本计算，
+// 聚合层的百分位按各窗口的平均值计算
+func computeAggregations(samples []sample, aggregations []string) map[string]float64 {
+	result := make(map[string]float64, len(aggregations))
+	if len(samples) == 0 {
+		for _, agg := range aggregations {
+			if agg == "count" || agg == "sum" {
+				result[agg] = 0
+			}
+		}
+		return result
+	}
+
+	var sorted []float64
+	sum, count, min, max := 0.0, 0, math.Inf(1), math.Inf(-1)
+	for _, s := range samples {
+		sum += s.value.Sum
+		count += s.value.Count
+		min = math.Min(min, s.value.Min)
+		max = math.Max(max, s.value.Max)
+	}
+
+	for _, agg := range aggregations {
+		switch agg {
+		case "avg":
+			result[agg] = sum / float64(count)
+		case "min":
+			result[agg] = min
+		case "max":
+			result[agg] = max
+		case "sum":
+			result[agg] = sum
+		case "count":
+			result[agg] = float64(count)
+		case "last":
+			result[agg] = samples[len(samples)-1].value.Last
+		default:
+			p, err := parsePercentile(agg)
+			if err != nil {
+				continue
+			}
+			if sorted == nil {
+				sorted = make([]float64, len(samples))
+				for i, s := range samples {
+					sorted[i] = s.value.Avg()
+				}
+				sort.Float64s(sorted)
+			}
+			result[agg] = percentile(sorted, p)
+		}
+	}
+	return result
+}
+
+// parsePercentile 解析 p50、p95、p99.9 等百分位名称
+func parsePercentile(name string) (float64, error) {
+	if !strings.HasPrefix(name, "p") {
+		return 0, fmt.Errorf("not a percentile: %s", name)
+	}
+	p, err := strconv.ParseFloat(name[1:], 64)
+	if err != nil || p <= 0 || p > 100 {
+		return 0, fmt.Errorf("invalid percentile: %s", name)
+	}
+	return p, nil
+}
+
+// percentile 线性插值计算百分位（sorted须已升序）
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,640 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+)
+
+// AnomalyCollection 异常在存储中的集合名（同时按ID保存，便于读取单个异常）
+const AnomalyCollection = "anomalies"
+
+// 异常状态
+const (
+	AnomalyOpen     = "open"
+	AnomalyResolved = "resolved"
+)
+
+// 偏离方向
+const (
+	DirectionHigh = "high"
+	DirectionLow  = "low"
+)
+
+const (
+	// staleSeries 序列超过该时间没有新样本时丢弃（Pod已删除、UAV离线），进行中的异常随之恢复
+	staleSeries = 15 * time.Minute
+	// maxTriageBatch 单次交给分级回调的最大异常数
+	maxTriageBatch = 20
+)
+
+// ErrAnomalyNotFound 异常不存在
+var ErrAnomalyNotFound = errors.New("anomaly not found")
+
+// AnomalyConfig 异常检测配置
+type AnomalyConfig struct {
+	ZThreshold   float64 // 偏离EWMA均值的标准差倍数
+	Alpha        float64 // EWMA平滑系数
+	MinSamples   int     // 开始检测前需要的样本数
+	ResolveAfter int     // 连续正常样本数达到该值后恢复
+}
+
+// AnomalyTriage LLM对异常的分级结果
+type AnomalyTriage struct {
+	Severity    string    `json:"severity"` // critical, warning, info
+	LikelyCause string    `json:"likely_cause"`
+	NextSteps   []string  `json:"next_steps,omitempty"`
+	Summary     string    `json:"summary,omitempty"` // 同一批异常的总体结论
+	Model       string    `json:"model"`
+	Prompt      string    `json:"prompt"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Anomaly 一个指标序列偏离其EWMA基线的时间段
+type Anomaly struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Target     string     `json:"target"` // node, pod, pair, uav
+	Key        string     `json:"key"`    // 节点名、namespace/name、源Pod|目标Pod 或 UAV所在节点
+	Metric     string     `json:"metric"` // cpu_usage_rate, memory_usage_rate, rtt_ms, battery_percent
+	Direction  string     `json:"direction"`
+	Value      float64    `json:"value"`    // 最近的异常值
+	Peak       float64    `json:"peak"`     // 偏离最大的值
+	Baseline   float64    `json:"baseline"` // 检测到异常时的EWMA均值
+	StdDev     float64    `json:"stddev"`
+	ZScore     float64    `json:"z_score"` // 最大z分数
+	Samples    int        `json:"samples"` // 异常样本数
+	DetectedAt time.Time  `json:"detected_at"`
+	LastSeen   time.Time  `json:"last_seen"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	Triage      *AnomalyTriage `json:"triage,omitempty"`
+	TriageError string         `json:"triage_error,omitempty"`
+}
+
+func (a *Anomaly) clone() *Anomaly {
+	copied := *a
+	if a.ResolvedAt != nil {
+		resolved := *a.ResolvedAt
+		copied.ResolvedAt = &resolved
+	}
+	if a.Triage != nil {
+		triage := *a.Triage
+		triage.NextSteps = append([]string(nil), a.Triage.NextSteps...)
+		copied.Triage = &triage
+	}
+	return &copied
+}
+
+// AnomalyFilter 异常查询条件
+type AnomalyFilter struct {
+	Status string
+	Target string
+	Key    string
+	Metric string
+	From   time.Time // 检测时间
+	To     time.Time
+	Limit  int
+}
+
+// Matches 判断异常是否满足状态、对象和指标条件
+func (f AnomalyFilter) Matches(a *Anomaly) bool {
+	if f.Status != "" && !strings.EqualFold(a.Status, f.Status) {
+		return false
+	}
+	if f.Target != "" && a.Target != f.Target {
+		return false
+	}
+	if f.Key != "" && a.Key != f.Key {
+		return false
+	}
+	if f.Metric != "" && a.Metric != f.Metric {
+		return false
+	}
+	if !f.From.IsZero() && a.DetectedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && a.DetectedAt.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// seriesSpec 一个被检测的指标序列
+type seriesSpec struct {
+	target    string
+	key       string
+	metric    string
+	direction string
+	// minDeviation 偏离均值的最小绝对值，避免平稳序列的微小波动（标准差接近0）被判为异常
+	minDeviation float64
+}
+
+func (s seriesSpec) id() string {
+	return s.target + "/" + s.key + "/" + s.metric
+}
+
+// ewma 序列的指数加权均值和方差
+type ewma struct {
+	mean     float64
+	variance float64
+	samples  int
+	normal   int // 异常进行中时连续的正常样本数
+	lastSeen time.Time
+}
+
+// AnomalyDetector 对CPU/内存使用率、Pod间RTT和UAV电量序列计算EWMA和z分数，
+// 偏离超过阈值时记录异常，恢复正常后关闭
+type AnomalyDetector struct {
+	cfg    AnomalyConfig
+	store  storage.Store
+	logger *logrus.Entry
+
+	mu     sync.Mutex
+	series map[string]*ewma
+	open   map[string]*Anomaly // 序列ID -> 进行中的异常
+
+	triageMu sync.Mutex
+	onDetect func(ctx context.Context, anomalies []*Anomaly)
+	pending  []*Anomaly
+	running  bool
+}
+
+// withDefaults 补全未设置或超出范围的参数
+func (cfg AnomalyConfig) withDefaults() AnomalyConfig {
+	if cfg.ZThreshold <= 0 {
+		cfg.ZThreshold = 3
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha >= 1 {
+		cfg.Alpha = 0.1
+	}
+	if cfg.MinSamples < 2 {
+		cfg.MinSamples = 2
+	}
+	if cfg.ResolveAfter <= 0 {
+		cfg.ResolveAfter = 3
+	}
+	return cfg
+}
+
+// NewAnomalyDetector 创建异常检测器；store 为空时不持久化
+func NewAnomalyDetector(cfg AnomalyConfig, store storage.Store) *AnomalyDetector {
+	d := &AnomalyDetector{
+		cfg:    cfg.withDefaults(),
+		store:  store,
+		logger: logging.For("metrics.anomaly"),
+		series: make(map[string]*ewma),
+		open:   make(map[string]*Anomaly),
+	}
+	if err := d.restore(context.Background()); err != nil {
+		d.logger.Warnf("Failed to restore open anomalies: %v", err)
+	}
+	return d
+}
+
+// SetConfig 更新检测参数（配置热更新）；已有序列的基线保留，进行中的异常从下一个样本起按新阈值判断
+func (d *AnomalyDetector) SetConfig(cfg AnomalyConfig) {
+	cfg = cfg.withDefaults()
+	d.mu.Lock()
+	d.cfg = cfg
+	d.mu.Unlock()
+}
+
+// OnDetect 设置新异常的回调（如LLM分级），在后台按批次调用，同一时间只有一个回调在执行
+func (d *AnomalyDetector) OnDetect(fn func(ctx context.Context, anomalies []*Anomaly)) {
+	d.triageMu.Lock()
+	d.onDetect = fn
+	d.triageMu.Unlock()
+}
+
+// ObserveSnapshot 检测一次采集结果中的节点、Pod、网络和UAV指标
+func (d *AnomalyDetector) ObserveSnapshot(ctx context.Context, snapshot *metricstypes.MetricsSnapshot, uavs map[string]*models.UAVMetricsEntry) {
+	now := snapshot.Timestamp
+	var fresh []*Anomaly
+	observe := func(spec seriesSpec, value float64, at time.Time) {
+		if at.IsZero() {
+			at = now
+		}
+		if a := d.observe(ctx, spec, value, at); a != nil {
+			fresh = append(fresh, a)
+		}
+	}
+
+	for name, node := range snapshot.NodeMetrics {
+		observe(seriesSpec{TargetNode, name, "cpu_usage_rate", DirectionHigh, 15}, node.CPUUsageRate, node.Timestamp)
+		observe(seriesSpec{TargetNode, name, "memory_usage_rate", DirectionHigh, 15}, node.MemoryUsageRate, node.Timestamp)
+	}
+	for key, pod := range snapshot.PodMetrics {
+		observe(seriesSpec{TargetPod, key, "cpu_usage_rate", DirectionHigh, 15}, pod.CPUUsageRate, pod.Timestamp)
+		observe(seriesSpec{TargetPod, key, "memory_usage_rate", DirectionHigh, 15}, pod.MemoryUsageRate, pod.Timestamp)
+	}
+	for _, pair := range snapshot.NetworkMetrics {
+		if !pair.Connected {
+			continue
+		}
+		observe(seriesSpec{TargetPair, pair.SourcePod + "|" + pair.TargetPod, "rtt_ms", DirectionHigh, 10}, pair.RTT, pair.Timestamp)
+	}
+	for node, entry := range uavs {
+		if entry.State == nil {
+			continue
+		}
+		observe(uavBatterySpec(node), entry.State.Battery.RemainingPercent, now)
+	}
+
+	d.sweep(ctx, now)
+	d.notify(fresh)
+}
+
+// ObserveUAV 检测一次UAV上报中的电量
+func (d *AnomalyDetector) ObserveUAV(ctx context.Context, node string, state *uav.UAVState, at time.Time) {
+	if state == nil {
+		return
+	}
+	if a := d.observe(ctx, uavBatterySpec(node), state.Battery.RemainingPercent, at); a != nil {
+		d.notify([]*Anomaly{a})
+	}
+}
+
+// uavBatterySpec 电量只检测突然下降
+func uavBatterySpec(node string) seriesSpec {
+	return seriesSpec{TargetUAV, node, "battery_percent", DirectionLow, 10}
+}
+
+// observe 用一个样本更新序列；返回新检测到的异常
+func (d *AnomalyDetector) observe(ctx context.Context, spec seriesSpec, value float64, at time.Time) *Anomaly {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+
+	d.mu.Lock()
+	id := spec.id()
+	s, ok := d.series[id]
+	if !ok {
+		s = &ewma{mean: value}
+		d.series[id] = s
+	}
+	s.lastSeen = at
+
+	var created, changed *Anomaly
+	if s.samples >= d.cfg.MinSamples {
+		deviation := value - s.mean
+		if spec.direction == DirectionLow {
+			deviation = -deviation
+		}
+		std := math.Sqrt(s.variance)
+		z := deviation / math.Max(std, spec.minDeviation/10)
+
+		current := d.open[id]
+		switch {
+		case z >= d.cfg.ZThreshold && deviation >= spec.minDeviation:
+			if current == nil {
+				current = &Anomaly{
+					ID:         newAnomalyID(at),
+					Status:     AnomalyOpen,
+					Target:     spec.target,
+					Key:        spec.key,
+					Metric:     spec.metric,
+					Direction:  spec.direction,
+					Peak:       value,
+					Baseline:   round(s.mean),
+					StdDev:     round(std),
+					DetectedAt: at.UTC(),
+				}
+				d.open[id] = current
+				created = current
+			}
+			current.Value = value
+			current.LastSeen = at.UTC()
+			current.Samples++
+			if z > current.ZScore {
+				current.ZScore = round(z)
+			}
+			if (spec.direction == DirectionLow && value < current.Peak) || (spec.direction != DirectionLow && value > current.Peak) {
+				current.Peak = value
+			}
+			s.normal = 0
+		case current != nil:
+			s.normal++
+			if s.normal >= d.cfg.ResolveAfter {
+				d.resolveLocked(id, at)
+				changed = current
+			}
+		}
+	}
+
+	// Welford式的指数加权更新
+	diff := value - s.mean
+	increment := d.cfg.Alpha * diff
+	s.mean += increment
+	s.variance = (1 - d.cfg.Alpha) * (s.variance + diff*increment)
+	s.samples++
+
+	var toSave *Anomaly
+	switch {
+	case created != nil:
+		toSave = created.clone()
+		d.logger.WithFields(logrus.Fields{
+			"target": spec.target, "key": spec.key, "metric": spec.metric,
+		}).Infof("Anomaly detected: value %.1f, baseline %.1f, z %.1f", value, created.Baseline, created.ZScore)
+	case changed != nil:
+		toSave = changed.clone()
+	}
+	d.mu.Unlock()
+
+	if toSave != nil {
+		if err := d.save(ctx, toSave); err != nil {
+			d.logger.Warnf("Failed to store anomaly %s: %v", toSave.ID, err)
+		}
+	}
+	if created != nil {
+		return toSave
+	}
+	return nil
+}
+
+// resolveLocked 关闭进行中的异常，调用方需持有锁
+func (d *AnomalyDetector) resolveLocked(id string, at time.Time) {
+	a, ok := d.open[id]
+	if !ok {
+		return
+	}
+	resolved := at.UTC()
+	a.Status = AnomalyResolved
+	a.ResolvedAt = &resolved
+	delete(d.open, id)
+	if s, ok := d.series[id]; ok {
+		s.normal = 0
+	}
+}
+
+// sweep 丢弃长时间没有样本的序列，并关闭其上进行中的异常
+func (d *AnomalyDetector) sweep(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	var resolved []*Anomaly
+	for id, s := range d.series {
+		if now.Sub(s.lastSeen) < staleSeries {
+			continue
+		}
+		if a, ok := d.open[id]; ok {
+			d.resolveLocked(id, now)
+			resolved = append(resolved, a.clone())
+		}
+		delete(d.series, id)
+	}
+	// 重启后恢复的异常在序列重新出现前没有样本
+	for id, a := range d.open {
+		if _, ok := d.series[id]; !ok && now.Sub(a.LastSeen) >= staleSeries {
+			d.resolveLocked(id, now)
+			resolved = append(resolved, a.clone())
+		}
+	}
+	d.mu.Unlock()
+
+	for _, a := range resolved {
+		if err := d.save(ctx, a); err != nil {
+			d.logger.Warnf("Failed to store anomaly %s: %v", a.ID, err)
+		}
+	}
+}
+
+// notify 将新异常交给回调，已有回调在执行时排队等待下一批
+func (d *AnomalyDetector) notify(fresh []*Anomaly) {
+	if len(fresh) == 0 {
+		return
+	}
+	d.triageMu.Lock()
+	defer d.triageMu.Unlock()
+	if d.onDetect == nil {
+		return
+	}
+	d.pending = append(d.pending, fresh...)
+	if d.running {
+		return
+	}
+	d.running = true
+	go d.drain()
+}
+
+func (d *AnomalyDetector) drain() {
+	for {
+		d.triageMu.Lock()
+		if len(d.pending) == 0 {
+			d.running = false
+			d.triageMu.Unlock()
+			return
+		}
+		n := len(d.pending)
+		if n > maxTriageBatch {
+			n = maxTriageBatch
+		}
+		batch := d.pending[:n]
+		d.pending = d.pending[n:]
+		fn := d.onDetect
+		d.triageMu.Unlock()
+
+		fn(context.Background(), batch)
+	}
+}
+
+// SetTriage 保存异常的分级结果；err 不为空时记录分级失败
+func (d *AnomalyDetector) SetTriage(ctx context.Context, id string, triage *AnomalyTriage, err error) error {
+	d.mu.Lock()
+	var target *Anomaly
+	for _, a := range d.open {
+		if a.ID == id {
+			target = a
+			break
+		}
+	}
+	if target != nil {
+		applyTriage(target, triage, err)
+		target = target.clone()
+	}
+	d.mu.Unlock()
+
+	if target == nil {
+		stored, getErr := d.Get(ctx, id)
+		if getErr != nil {
+			return getErr
+		}
+		applyTriage(stored, triage, err)
+		target = stored
+	}
+	return d.save(ctx, target)
+}
+
+func applyTriage(a *Anomaly, triage *AnomalyTriage, err error) {
+	if err != nil {
+		a.TriageError = err.Error()
+		return
+	}
+	a.Triage = triage
+	a.TriageError = ""
+}
+
+// OpenCount 进行中的异常数
+func (d *AnomalyDetector) OpenCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.open)
+}
+
+// List 查询异常（按检测时间降序）：进行中的来自内存，其余来自存储
+func (d *AnomalyDetector) List(ctx context.Context, filter AnomalyFilter) ([]*Anomaly, error) {
+	latest := make(map[string]*Anomaly)
+	if d.store != nil && !strings.EqualFold(filter.Status, AnomalyOpen) {
+		records, err := d.store.List(ctx, AnomalyCollection, storage.Query{To: filter.To})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list anomalies: %w", err)
+		}
+		for _, record := range records {
+			var a Anomaly
+			if err := json.Unmarshal(record.Data, &a); err != nil {
+				continue
+			}
+			latest[a.ID] = &a
+		}
+	}
+
+	d.mu.Lock()
+	for _, a := range d.open {
+		latest[a.ID] = a.clone()
+	}
+	d.mu.Unlock()
+
+	result := make([]*Anomaly, 0)
+	for _, a := range latest {
+		// 存储中状态为open但已不在内存中的异常只会出现在重启前，视为已恢复的旧记录
+		if a.Status == AnomalyOpen && !d.isOpen(a.ID) {
+			a.Status = AnomalyResolved
+		}
+		if filter.Matches(a) {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DetectedAt.After(result[j].DetectedAt) })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (d *AnomalyDetector) isOpen(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, a := range d.open {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Get 根据ID获取异常
+func (d *AnomalyDetector) Get(ctx context.Context, id string) (*Anomaly, error) {
+	d.mu.Lock()
+	for _, a := range d.open {
+		if a.ID == id {
+			copied := a.clone()
+			d.mu.Unlock()
+			return copied, nil
+		}
+	}
+	d.mu.Unlock()
+
+	if d.store == nil {
+		return nil, ErrAnomalyNotFound
+	}
+	data, err := d.store.Get(ctx, AnomalyCollection, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrAnomalyNotFound
+		}
+		return nil, err
+	}
+	var a Anomaly
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode anomaly %s: %w", id, err)
+	}
+	if a.Status == AnomalyOpen && !d.isOpen(a.ID) {
+		a.Status = AnomalyResolved
+	}
+	return &a, nil
+}
+
+// save 保存异常；再次保存时追加新记录，查询时以最后一条为准
+func (d *AnomalyDetector) save(ctx context.Context, a *Anomaly) error {
+	if d.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal anomaly: %w", err)
+	}
+	if err := d.store.Put(ctx, AnomalyCollection, a.ID, data); err != nil {
+		return err
+	}
+	return d.store.Append(ctx, AnomalyCollection, &storage.Record{
+		ID:        a.ID,
+		Key:       a.Target,
+		Timestamp: a.DetectedAt,
+		Data:      data,
+	})
+}
+
+// restore 重启后恢复最近仍在进行的异常，序列重新出现并恢复正常后关闭
+func (d *AnomalyDetector) restore(ctx context.Context) error {
+	if d.store == nil {
+		return nil
+	}
+	records, err := d.store.List(ctx, AnomalyCollection, storage.Query{From: time.Now().Add(-24 * time.Hour)})
+	if err != nil {
+		return err
+	}
+	latest := make(map[string]*Anomaly)
+	for _, record := range records {
+		var a Anomaly
+		if err := json.Unmarshal(record.Data, &a); err != nil {
+			continue
+		}
+		latest[a.ID] = &a
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, a := range latest {
+		if a.Status != AnomalyOpen {
+			continue
+		}
+		d.open[seriesSpec{target: a.Target, key: a.Key, metric: a.Metric}.id()] = a
+	}
+	return nil
+}
+
+func newAnomalyID(at time.Time) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("anom-%d", at.UnixNano())
+	}
+	return fmt.Sprintf("anom-%s-%s", at.UTC().Format("20060102150405"), hex.EncodeToString(buf))
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
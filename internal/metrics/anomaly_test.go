@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// observeBaseline 为节点CPU序列写入在50%附近小幅波动的样本，返回下一个样本的时间
+func observeBaseline(d *AnomalyDetector, node string, start time.Time, samples int) time.Time {
+	at := start
+	for i := 0; i < samples; i++ {
+		d.observe(context.Background(), cpuSpec(node), 50+float64(i%2)*2, at)
+		at = at.Add(time.Second)
+	}
+	return at
+}
+
+func cpuSpec(node string) seriesSpec {
+	return seriesSpec{TargetNode, node, "cpu_usage_rate", DirectionHigh, 15}
+}
+
+// 热更新后的阈值从下一个样本起生效，已有序列的基线保留
+func TestAnomalyDetectorSetConfig(t *testing.T) {
+	ctx := context.Background()
+	d := NewAnomalyDetector(AnomalyConfig{ZThreshold: 1000, MinSamples: 5, ResolveAfter: 10}, nil)
+	start := time.Now()
+
+	at := observeBaseline(d, "node-a", start, 10)
+	if a := d.observe(ctx, cpuSpec("node-a"), 90, at); a != nil {
+		t.Fatalf("spike detected with z_threshold 1000: %+v", a)
+	}
+
+	d.SetConfig(AnomalyConfig{ZThreshold: 3, MinSamples: 5, ResolveAfter: 1})
+	at = observeBaseline(d, "node-b", start, 10)
+	a := d.observe(ctx, cpuSpec("node-b"), 90, at)
+	if a == nil {
+		t.Fatal("spike not detected after lowering z_threshold to 3")
+	}
+	if a.Baseline < 50 || a.Baseline > 52 {
+		t.Errorf("baseline = %v, want about 51", a.Baseline)
+	}
+
+	// resolve_after 同样按新值生效
+	d.observe(ctx, cpuSpec("node-b"), 51, at.Add(time.Second))
+	if open := d.OpenCount(); open != 0 {
+		t.Errorf("%d open anomalies after a normal sample with resolve_after 1, want 0", open)
+	}
+}
+
+func TestAnomalyConfigDefaults(t *testing.T) {
+	got := AnomalyConfig{Alpha: 1.5, MinSamples: 1}.withDefaults()
+	want := AnomalyConfig{ZThreshold: 3, Alpha: 0.1, MinSamples: 2, ResolveAfter: 3}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
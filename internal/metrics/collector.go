@@ -4,7 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
 	mt "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
 )
 
 // Collector 指标采集器接口
@@ -46,7 +49,19 @@ type NetworkMetricsSource interface {
 	CollectNetworkMetrics(ctx context.Context) ([]*mt.NetworkMetrics, error)
 
 	// TestPodConnectivity 测试两个Pod之间的连通性
-	TestPodConnectivity(ctx context.Context, sourcePod, targetPod string) (*mt.NetworkMetrics, error)
+	TestPodConnectivity(ctx context.Context, sourcePod, targetPod string, opts sources.PodTestOptions) (*mt.NetworkMetrics, error)
+}
+
+// NodeLatencySource 节点间时延数据源接口（探测Agent）
+type NodeLatencySource interface {
+	// CollectNodeLatency 测量所有节点对之间的往返时延
+	CollectNodeLatency(ctx context.Context) (*mt.NodeLatencyMatrix, error)
+}
+
+// ExternalTargetsSource 外部目标可达性数据源接口（探测Agent）
+type ExternalTargetsSource interface {
+	// CollectExternalTargets 由各节点访问所有外部目标
+	CollectExternalTargets(ctx context.Context) (*mt.ExternalReachability, error)
 }
 
 // CustomMetricsSource 自定义指标数据源接口（从CRD获取）
@@ -55,11 +70,26 @@ type CustomMetricsSource interface {
 	CollectCustomMetrics(ctx context.Context) (map[string]interface{}, error)
 }
 
+// GPUMetricsSource GPU指标数据源接口（DCGM Exporter）
+type GPUMetricsSource interface {
+	// CollectGPUMetrics 采集各节点每个GPU的指标
+	CollectGPUMetrics(ctx context.Context) (map[string][]mt.GPUDevice, error)
+}
+
+// SummaryMetricsSource kubelet Summary API 数据源接口
+type SummaryMetricsSource interface {
+	// CollectSummaryMetrics 采集各节点的文件系统、网卡流量和Pod临时存储统计
+	CollectSummaryMetrics(ctx context.Context) (map[string]*mt.KubeletSummary, error)
+}
+
 // UAVMetricsSource UAV指标数据源接口
 type UAVMetricsSource interface {
-	// CollectUAVMetrics 采集所有UAV指标
-	CollectUAVMetrics(ctx context.Context) (map[string]interface{}, error)
+	// CollectUAVMetrics 采集所有Agent模拟的所有UAV的指标
+	CollectUAVMetrics(ctx context.Context) (map[models.UAVRef]*uav.UAVState, error)
 
 	// CollectSingleUAVMetrics 采集单个UAV指标
-	CollectSingleUAVMetrics(ctx context.Context, nodeName string) (interface{}, error)
+	CollectSingleUAVMetrics(ctx context.Context, nodeName string) (*uav.UAVState, error)
+
+	// SendCommandToUAV 通过节点上的UAV Agent向第 index 架UAV发送控制命令，返回Agent的响应消息
+	SendCommandToUAV(ctx context.Context, nodeName string, index int, command string, payload interface{}) (string, error)
 }
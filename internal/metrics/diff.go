@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+)
+
+// DefaultDiffThreshold 指标变化超过该百分比时列入差异
+const DefaultDiffThreshold = 20.0
+
+// ErrNoSnapshotData 指定时间没有可用的采集数据（早于内存历史且未配置存储或存储中没有样本）
+var ErrNoSnapshotData = errors.New("no metrics data")
+
+// 比较的数据来源
+const (
+	DiffSourceCurrent = "current" // 最新快照
+	DiffSourceMemory  = "memory"  // 内存历史（metrics.cache_retention）
+	DiffSourceStorage = "storage" // 存储中的指标样本
+)
+
+// DiffEndpoint 比较的一端实际使用的数据
+type DiffEndpoint struct {
+	Requested time.Time `json:"requested"`
+	Timestamp time.Time `json:"timestamp"` // 实际使用的采集时间（聚合层为窗口的开始时间）
+	Source    string    `json:"source"`
+	Tier      string    `json:"tier,omitempty"` // 来源为存储时读取的层
+	Nodes     int       `json:"nodes"`
+	Pods      int       `json:"pods"`
+	Pairs     int       `json:"pairs"`
+}
+
+// MetricChange 两个时间点之间变化超过阈值的指标
+type MetricChange struct {
+	Target  string   `json:"target"` // node/<name>、pod/<namespace>/<name> 或 pair/<source>|<dest>
+	Metric  string   `json:"metric"`
+	From    float64  `json:"from"`
+	To      float64  `json:"to"`
+	Change  float64  `json:"change"`
+	Percent *float64 `json:"percent,omitempty"` // 相对 from 的变化百分比，from 为0时为空
+}
+
+// SnapshotDiff 两个时间点之间集群状态的变化
+type SnapshotDiff struct {
+	From      DiffEndpoint `json:"from"`
+	To        DiffEndpoint `json:"to"`
+	Threshold float64      `json:"threshold"`
+
+	NodesAdded     []string `json:"nodes_added"`
+	NodesRemoved   []string `json:"nodes_removed"`
+	NodesUnhealthy []string `json:"nodes_unhealthy"` // 由健康变为不健康
+	NodesRecovered []string `json:"nodes_recovered"` // 由不健康恢复
+	PodsAdded      []string `json:"pods_added"`      // namespace/name
+	PodsRemoved    []string `json:"pods_removed"`
+	PodsNotReady   []string `json:"pods_not_ready"` // 由就绪变为未就绪
+
+	Changes []MetricChange `json:"changes"` // 按变化幅度从大到小
+}
+
+// diffState 一个时间点各对象的指标（样本键 -> 指标 -> 值）
+type diffState struct {
+	endpoint DiffEndpoint
+	samples  map[string]map[string]float64
+}
+
+// Diff 比较两个时间点的集群状态：新增和消失的节点、Pod，健康和就绪状态的变化，
+// 以及变化超过 threshold 百分比的指标。to 为零值时使用最新快照；
+// 较早的时间点优先从内存历史读取，超出内存历史时读取存储中的指标样本
+func (m *Manager) Diff(ctx context.Context, from, to time.Time, threshold float64) (*SnapshotDiff, error) {
+	if threshold <= 0 {
+		threshold = DefaultDiffThreshold
+	}
+
+	before, err := m.stateAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := m.stateAt(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SnapshotDiff{
+		From:           before.endpoint,
+		To:             after.endpoint,
+		Threshold:      threshold,
+		NodesAdded:     []string{},
+		NodesRemoved:   []string{},
+		NodesUnhealthy: []string{},
+		NodesRecovered: []string{},
+		PodsAdded:      []string{},
+		PodsRemoved:    []string{},
+		PodsNotReady:   []string{},
+		Changes:        []MetricChange{},
+	}
+
+	for key := range after.samples {
+		if _, ok := before.samples[key]; ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(key, TargetNode+"/"); ok {
+			diff.NodesAdded = append(diff.NodesAdded, name)
+		} else if name, ok := strings.CutPrefix(key, TargetPod+"/"); ok {
+			diff.PodsAdded = append(diff.PodsAdded, name)
+		}
+	}
+
+	for key, old := range before.samples {
+		current, ok := after.samples[key]
+		nodeName, isNode := strings.CutPrefix(key, TargetNode+"/")
+		podName, isPod := strings.CutPrefix(key, TargetPod+"/")
+		if !ok {
+			if isNode {
+				diff.NodesRemoved = append(diff.NodesRemoved, nodeName)
+			} else if isPod {
+				diff.PodsRemoved = append(diff.PodsRemoved, podName)
+			}
+			continue
+		}
+
+		switch {
+		case isNode && old["healthy"] == 1 && hasValue(current, "healthy", 0):
+			diff.NodesUnhealthy = append(diff.NodesUnhealthy, nodeName)
+		case isNode && hasValue(old, "healthy", 0) && current["healthy"] == 1:
+			diff.NodesRecovered = append(diff.NodesRecovered, nodeName)
+		case isPod && old["ready"] == 1 && hasValue(current, "ready", 0):
+			diff.PodsNotReady = append(diff.PodsNotReady, podName)
+		}
+
+		for metric, from := range old {
+			to, ok := current[metric]
+			if !ok || !diffMetric(metric) || from == to {
+				continue
+			}
+			change := MetricChange{Target: key, Metric: metric, From: from, To: to, Change: round(to - from)}
+			if from != 0 {
+				percent := round((to - from) / math.Abs(from) * 100)
+				if math.Abs(percent) < threshold {
+					continue
+				}
+				change.Percent = &percent
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	for _, list := range [][]string{diff.NodesAdded, diff.NodesRemoved, diff.NodesUnhealthy, diff.NodesRecovered, diff.PodsAdded, diff.PodsRemoved, diff.PodsNotReady} {
+		sort.Strings(list)
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		// from 为0的变化视为幅度最大
+		if (a.Percent == nil) != (b.Percent == nil) {
+			return a.Percent == nil
+		}
+		if a.Percent != nil && math.Abs(*a.Percent) != math.Abs(*b.Percent) {
+			return math.Abs(*a.Percent) > math.Abs(*b.Percent)
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Metric < b.Metric
+	})
+	return diff, nil
+}
+
+// hasValue 指标存在且等于 value（缺少健康或就绪字段的旧样本不参与比较）
+func hasValue(values map[string]float64, metric string, value float64) bool {
+	v, ok := values[metric]
+	return ok && v == value
+}
+
+// diffMetric 是否比较该指标的变化幅度：健康和就绪状态单独列出，变化速率本身的百分比没有意义
+func diffMetric(metric string) bool {
+	return metric != "healthy" && metric != "ready" && !strings.HasSuffix(metric, "_trend")
+}
+
+// stateAt 读取某一时间点各节点、Pod和Pod对的指标
+func (m *Manager) stateAt(ctx context.Context, at time.Time) (*diffState, error) {
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	snapshot := m.ViewSnapshot()
+	if !at.Before(snapshot.Timestamp) {
+		state := &diffState{
+			endpoint: DiffEndpoint{Requested: at, Timestamp: snapshot.Timestamp, Source: DiffSourceCurrent},
+			samples:  make(map[string]map[string]float64),
+		}
+		for _, s := range snapshotSamples(snapshot) {
+			state.samples[s.key] = s.values
+		}
+		return state.count(), nil
+	}
+
+	if entry, ok := m.history.at(at); ok {
+		state := &diffState{
+			endpoint: DiffEndpoint{Requested: at, Timestamp: entry.timestamp, Source: DiffSourceMemory},
+			samples:  make(map[string]map[string]float64),
+		}
+		for key, values := range entry.samples {
+			if !strings.Contains(key, "/") {
+				continue // UAV
+			}
+			state.samples[key] = values
+		}
+		return state.count(), nil
+	}
+
+	if m.store == nil {
+		return nil, fmt.Errorf("%w at %s: older than the in-memory history and no storage is configured", ErrNoSnapshotData, at.Format(time.RFC3339))
+	}
+
+	// 每个对象取时间点之前最近的样本，范围覆盖各数据源的采集间隔
+	var lookback time.Duration
+	for _, name := range []string{sources.CollectorNode, sources.CollectorPod, sources.CollectorNetwork} {
+		if interval, _, ok := m.scheduler.lookup(name); ok && 2*interval > lookback {
+			lookback = 2 * interval
+		}
+	}
+	tier := SelectTier(at, time.Now())
+	if 2*tier.Resolution > lookback {
+		lookback = 2 * tier.Resolution
+	}
+	points, tier, err := ReadHistory(ctx, m.store, "", "", "", at.Add(-lookback), at)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%w at %s", ErrNoSnapshotData, at.Format(time.RFC3339))
+	}
+
+	state := &diffState{
+		endpoint: DiffEndpoint{Requested: at, Source: DiffSourceStorage, Tier: tier.Name},
+		samples:  make(map[string]map[string]float64),
+	}
+	for _, point := range points {
+		values := make(map[string]float64, len(point.Values))
+		for metric, value := range point.Values {
+			values[metric] = value.Last
+		}
+		// 记录按时间升序，后面的覆盖前面的
+		state.samples[point.Key] = values
+		if point.Timestamp.After(state.endpoint.Timestamp) {
+			state.endpoint.Timestamp = point.Timestamp
+		}
+	}
+	return state.count(), nil
+}
+
+// count 统计各类对象的数量
+func (s *diffState) count() *diffState {
+	for key := range s.samples {
+		switch {
+		case strings.HasPrefix(key, TargetNode+"/"):
+			s.endpoint.Nodes++
+		case strings.HasPrefix(key, TargetPod+"/"):
+			s.endpoint.Pods++
+		case strings.HasPrefix(key, TargetPair+"/"):
+			s.endpoint.Pairs++
+		}
+	}
+	return s
+}
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// 资源申请的评估结果
+const (
+	EfficiencyOK               = "ok"
+	EfficiencyOverProvisioned  = "over_provisioned"  // 用量远低于请求，可以调低请求
+	EfficiencyUnderProvisioned = "under_provisioned" // 用量超过请求，可能被驱逐或被限流
+	EfficiencyNoRequest        = "no_request"        // 没有设置请求
+)
+
+// 效率评估的默认参数
+const (
+	defaultEfficiencyWindow     = 24 * time.Hour
+	defaultEfficiencyPercentile = 95
+	efficiencyHeadroom          = 0.15 // 建议请求在用量基础上增加的余量
+	overProvisionedRatio        = 0.5  // 用量低于请求的该比例视为过度申请
+	memoryRoundTo               = 1 << 20
+	cpuRoundTo                  = 5 // 毫核
+)
+
+// EfficiencyOptions 资源效率报告的参数
+type EfficiencyOptions struct {
+	Namespace  string    // 为空时包含所有命名空间
+	From       time.Time // 为零值时为 To 之前24小时
+	To         time.Time // 为零值时为当前时间
+	Percentile float64   // CPU用量取该百分位（默认95），内存取最大值
+}
+
+// ResourceEfficiency 一种资源的请求与实际用量
+type ResourceEfficiency struct {
+	Request     int64   `json:"request"`
+	Limit       int64   `json:"limit"`
+	Average     float64 `json:"average"`
+	Peak        float64 `json:"peak"`                  // CPU为百分位用量，内存为最大用量
+	Utilization float64 `json:"utilization,omitempty"` // 峰值用量/请求 (%)，没有请求时为0
+	Suggested   int64   `json:"suggested_request"`     // 峰值用量加余量
+	Status      string  `json:"status"`
+}
+
+// PodEfficiency 一个Pod的资源效率
+type PodEfficiency struct {
+	Namespace string             `json:"namespace"`
+	Pod       string             `json:"pod"`
+	Samples   int                `json:"samples"`
+	CPU       ResourceEfficiency `json:"cpu"`    // 毫核
+	Memory    ResourceEfficiency `json:"memory"` // bytes
+}
+
+// NamespaceEfficiency 一个命名空间的资源效率汇总
+type NamespaceEfficiency struct {
+	Namespace        string          `json:"namespace"`
+	Pods             int             `json:"pods"`
+	CPURequest       int64           `json:"cpu_request"`
+	CPUPeak          int64           `json:"cpu_peak"`
+	CPUSuggested     int64           `json:"cpu_suggested"`
+	MemoryRequest    int64           `json:"memory_request"`
+	MemoryPeak       int64           `json:"memory_peak"`
+	MemorySuggested  int64           `json:"memory_suggested"`
+	OverProvisioned  []PodEfficiency `json:"over_provisioned"`  // 按可释放的CPU请求从多到少排序
+	UnderProvisioned []PodEfficiency `json:"under_provisioned"` // 按超出请求的CPU用量从多到少排序
+}
+
+// EfficiencyReport 资源效率报告
+type EfficiencyReport struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	Source     string                `json:"source"` // storage 或 memory（内存历史）
+	Percentile float64               `json:"percentile"`
+	Skipped    int                   `json:"skipped"` // 时间范围内没有样本的Pod
+	Namespaces []NamespaceEfficiency `json:"namespaces"`
+}
+
+// Efficiency 比较当前Pod的请求/限制与时间范围内的实际用量，按命名空间生成过度申请和申请不足的报告。
+// 配置了存储时读取样本历史，否则使用内存历史（范围受 metrics.cache_retention 限制）
+func (m *Manager) Efficiency(ctx context.Context, opts EfficiencyOptions) (*EfficiencyReport, error) {
+	if opts.To.IsZero() {
+		opts.To = time.Now()
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.Add(-defaultEfficiencyWindow)
+	}
+	if opts.Percentile <= 0 {
+		opts.Percentile = defaultEfficiencyPercentile
+	}
+	if opts.Percentile > 100 {
+		return nil, fmt.Errorf("invalid percentile: %v", opts.Percentile)
+	}
+
+	report := &EfficiencyReport{From: opts.From, To: opts.To, Source: "memory", Percentile: opts.Percentile, Namespaces: []NamespaceEfficiency{}}
+	if m.store != nil {
+		report.Source = "storage"
+	}
+
+	namespaces := make(map[string]*NamespaceEfficiency)
+	for _, pod := range m.ViewSnapshot().PodMetrics {
+		if opts.Namespace != "" && pod.Namespace != opts.Namespace {
+			continue
+		}
+		cpu, memory, err := m.podUsage(ctx, SampleKey(TargetPod, pod.Namespace, pod.PodName), opts.From, opts.To)
+		if err != nil {
+			return nil, err
+		}
+		if len(cpu) == 0 && len(memory) == 0 {
+			report.Skipped++
+			continue
+		}
+
+		efficiency := PodEfficiency{
+			Namespace: pod.Namespace,
+			Pod:       pod.PodName,
+			Samples:   len(cpu),
+			CPU:       resourceEfficiency(cpu, opts.Percentile, pod.CPURequest, pod.CPULimit, cpuRoundTo),
+			Memory:    resourceEfficiency(memory, 100, pod.MemoryRequest, pod.MemoryLimit, memoryRoundTo),
+		}
+
+		ns := namespaces[pod.Namespace]
+		if ns == nil {
+			ns = &NamespaceEfficiency{Namespace: pod.Namespace, OverProvisioned: []PodEfficiency{}, UnderProvisioned: []PodEfficiency{}}
+			namespaces[pod.Namespace] = ns
+		}
+		ns.Pods++
+		ns.CPURequest += efficiency.CPU.Request
+		ns.CPUPeak += int64(math.Ceil(efficiency.CPU.Peak))
+		ns.CPUSuggested += efficiency.CPU.Suggested
+		ns.MemoryRequest += efficiency.Memory.Request
+		ns.MemoryPeak += int64(math.Ceil(efficiency.Memory.Peak))
+		ns.MemorySuggested += efficiency.Memory.Suggested
+
+		switch {
+		case efficiency.CPU.Status == EfficiencyUnderProvisioned || efficiency.Memory.Status == EfficiencyUnderProvisioned:
+			ns.UnderProvisioned = append(ns.UnderProvisioned, efficiency)
+		case efficiency.CPU.Status == EfficiencyOverProvisioned || efficiency.Memory.Status == EfficiencyOverProvisioned:
+			ns.OverProvisioned = append(ns.OverProvisioned, efficiency)
+		}
+	}
+
+	for _, ns := range namespaces {
+		sort.Slice(ns.OverProvisioned, func(i, j int) bool {
+			a, b := ns.OverProvisioned[i].CPU, ns.OverProvisioned[j].CPU
+			return a.Request-a.Suggested > b.Request-b.Suggested
+		})
+		sort.Slice(ns.UnderProvisioned, func(i, j int) bool {
+			a, b := ns.UnderProvisioned[i].CPU, ns.UnderProvisioned[j].CPU
+			return a.Peak-float64(a.Request) > b.Peak-float64(b.Request)
+		})
+		report.Namespaces = append(report.Namespaces, *ns)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool { return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace })
+	return report, nil
+}
+
+// podUsage 读取Pod在时间范围内的CPU和内存用量样本
+func (m *Manager) podUsage(ctx context.Context, key string, from, to time.Time) ([]float64, []float64, error) {
+	var cpu, memory []float64
+	if m.store == nil {
+		for _, point := range m.history.series(key, "cpu_usage", from, to) {
+			cpu = append(cpu, point.Value)
+		}
+		for _, point := range m.history.series(key, "memory_usage", from, to) {
+			memory = append(memory, point.Value)
+		}
+		return cpu, memory, nil
+	}
+
+	// 聚合层中CPU取各窗口的平均值，内存取窗口内的最大值
+	points, _, err := ReadHistory(ctx, m.store, "", key, "", from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, point := range points {
+		if value, ok := point.Values["cpu_usage"]; ok {
+			cpu = append(cpu, value.Avg())
+		}
+		if value, ok := point.Values["memory_usage"]; ok {
+			memory = append(memory, value.Max)
+		}
+	}
+	return cpu, memory, nil
+}
+
+// resourceEfficiency 按百分位用量评估请求，建议值为用量加余量并按 roundTo 向上取整
+func resourceEfficiency(values []float64, p float64, request, limit, roundTo int64) ResourceEfficiency {
+	result := ResourceEfficiency{Request: request, Limit: limit}
+	if len(values) == 0 {
+		result.Status = EfficiencyOK
+		return result
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+	result.Average = sum / float64(len(sorted))
+	result.Peak = percentile(sorted, p)
+
+	suggested := int64(math.Ceil(result.Peak * (1 + efficiencyHeadroom)))
+	if remainder := suggested % roundTo; remainder != 0 {
+		suggested += roundTo - remainder
+	}
+	if suggested < roundTo {
+		suggested = roundTo
+	}
+	result.Suggested = suggested
+
+	switch {
+	case request <= 0:
+		result.Status = EfficiencyNoRequest
+	case result.Peak > float64(request):
+		result.Status = EfficiencyUnderProvisioned
+	case result.Peak < float64(request)*overProvisionedRatio:
+		result.Status = EfficiencyOverProvisioned
+	default:
+		result.Status = EfficiencyOK
+	}
+	if request > 0 {
+		result.Utilization = result.Peak / float64(request) * 100
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// SnapshotJSON 返回快照的流式JSON表示，输出与直接编码 MetricsSnapshot 相同，
+// 但Node/Pod指标逐个编码写出，大集群下不需要在内存中生成完整响应
+func SnapshotJSON(snapshot *metricstypes.MetricsSnapshot) interface{} {
+	if snapshot == nil {
+		return nil
+	}
+	fields := []httpjson.Field{
+		{Key: "timestamp", Value: snapshot.Timestamp},
+		{Key: "node_metrics", Value: httpjson.Map(snapshot.NodeMetrics)},
+		{Key: "pod_metrics", Value: httpjson.Map(snapshot.PodMetrics)},
+		{Key: "network_metrics", Value: snapshot.NetworkMetrics},
+		{Key: "cluster_metrics", Value: snapshot.ClusterMetrics},
+	}
+	if snapshot.CollectionStatus != nil {
+		fields = append(fields, httpjson.Field{Key: "collection_status", Value: snapshot.CollectionStatus})
+	}
+	return httpjson.Object(fields...)
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// 基准测试使用的快照规模
+const (
+	benchNodes = 100
+	benchPods  = 5000
+)
+
+func TestSnapshotJSONMatchesEncodingJSON(t *testing.T) {
+	snapshot := buildSnapshot(10, 200)
+	snapshot.NetworkMetrics = []*metricstypes.NetworkMetrics{{SourcePod: "ns-00/app-00000", TargetPod: "ns-01/app-00001", Connected: true, RTT: 0.25}}
+	snapshot.NodeMetrics["node-000"].CustomMetrics = map[string]interface{}{"temperature": 61.5, "<zone>": "edge&a"}
+	withStatus := buildSnapshot(2, 5)
+	withStatus.CollectionStatus = &metricstypes.CollectionStatus{Degraded: true, Sources: map[string]*metricstypes.SourceStatus{
+		"node": {Status: metricstypes.SourceOK},
+		"pod":  {Status: metricstypes.SourceFailed, Error: "metrics server unavailable"},
+	}}
+	noSources := buildSnapshot(1, 1)
+	noSources.CollectionStatus = &metricstypes.CollectionStatus{}
+
+	for name, snapshot := range map[string]*metricstypes.MetricsSnapshot{
+		"snapshot":          snapshot,
+		"collection status": withStatus,
+		"no sources":        noSources,
+		"empty":             {},
+		"nil":               nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var want, got bytes.Buffer
+			if err := legacySnapshot(&want, snapshot); err != nil {
+				t.Fatalf("encoding/json error = %v", err)
+			}
+			if err := streamSnapshot(&got, snapshot); err != nil {
+				t.Fatalf("WriteObject() error = %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Errorf("SnapshotJSON output differs from encoding/json\n got: %.300s\nwant: %.300s", got.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+func BenchmarkPodsLegacy(b *testing.B) {
+	benchmarkEncode(b, legacyPods)
+}
+
+func BenchmarkPodsStream(b *testing.B) {
+	benchmarkEncode(b, streamPods)
+}
+
+func BenchmarkSnapshotLegacy(b *testing.B) {
+	benchmarkEncode(b, legacySnapshot)
+}
+
+func BenchmarkSnapshotStream(b *testing.B) {
+	benchmarkEncode(b, streamSnapshot)
+}
+
+func BenchmarkSnapshotClone(b *testing.B) {
+	snapshot := buildSnapshot(benchNodes, benchPods)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		snapshot.Clone()
+	}
+}
+
+func benchmarkEncode(b *testing.B, encode func(io.Writer, *metricstypes.MetricsSnapshot) error) {
+	snapshot := buildSnapshot(benchNodes, benchPods)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := encode(io.Discard, snapshot); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// legacyPods 拷贝快照后整体编码 /api/v1/metrics/pods 的响应（流式编码之前的实现）
+func legacyPods(w io.Writer, snapshot *metricstypes.MetricsSnapshot) error {
+	snapshot = snapshot.Clone()
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      snapshot.PodMetrics,
+		"count":     len(snapshot.PodMetrics),
+		"timestamp": snapshot.Timestamp,
+	})
+}
+
+// streamPods 当前 /api/v1/metrics/pods 的实现：只读快照 + 流式编码
+func streamPods(w io.Writer, snapshot *metricstypes.MetricsSnapshot) error {
+	return httpjson.WriteObject(w,
+		httpjson.Field{Key: "status", Value: "success"},
+		httpjson.Field{Key: "data", Value: httpjson.Map(snapshot.PodMetrics)},
+		httpjson.Field{Key: "count", Value: len(snapshot.PodMetrics)},
+		httpjson.Field{Key: "timestamp", Value: snapshot.Timestamp},
+	)
+}
+
+// legacySnapshot 拷贝快照后整体编码 /api/v1/metrics/snapshot 的响应
+func legacySnapshot(w io.Writer, snapshot *metricstypes.MetricsSnapshot) error {
+	return json.NewEncoder(w).Encode(struct {
+		Status string                        `json:"status"`
+		Data   *metricstypes.MetricsSnapshot `json:"data"`
+	}{"success", snapshot.Clone()})
+}
+
+// streamSnapshot 当前 /api/v1/metrics/snapshot 的实现
+func streamSnapshot(w io.Writer, snapshot *metricstypes.MetricsSnapshot) error {
+	return httpjson.WriteObject(w,
+		httpjson.Field{Key: "status", Value: "success"},
+		httpjson.Field{Key: "data", Value: SnapshotJSON(snapshot)},
+	)
+}
+
+// buildSnapshot 生成模拟快照（nodeCount 个节点、podCount 个Pod）
+func buildSnapshot(nodeCount, podCount int) *metricstypes.MetricsSnapshot {
+	now := time.Now()
+	snapshot := &metricstypes.MetricsSnapshot{
+		Timestamp:      now,
+		NodeMetrics:    make(map[string]*metricstypes.NodeMetrics, nodeCount),
+		PodMetrics:     make(map[string]*metricstypes.PodMetrics, podCount),
+		NetworkMetrics: []*metricstypes.NetworkMetrics{},
+		ClusterMetrics: &metricstypes.ClusterMetrics{Timestamp: now, TotalNodes: nodeCount, TotalPods: podCount, HealthStatus: "healthy"},
+	}
+
+	for i := 0; i < nodeCount; i++ {
+		name := fmt.Sprintf("node-%03d", i)
+		snapshot.NodeMetrics[name] = &metricstypes.NodeMetrics{
+			NodeName:        name,
+			Timestamp:       now,
+			CPUCapacity:     16000,
+			CPUUsage:        int64(4000 + i*10),
+			CPUUsageRate:    25,
+			MemoryCapacity:  64 << 30,
+			MemoryUsage:     16 << 30,
+			MemoryUsageRate: 25,
+			Healthy:         true,
+			Conditions:      []string{},
+			Labels:          map[string]string{"kubernetes.io/hostname": name, "topology.kubernetes.io/zone": "edge-a"},
+		}
+	}
+
+	for i := 0; i < podCount; i++ {
+		namespace := fmt.Sprintf("ns-%02d", i%20)
+		name := fmt.Sprintf("app-%05d", i)
+		snapshot.PodMetrics[namespace+"/"+name] = &metricstypes.PodMetrics{
+			PodName:       name,
+			Namespace:     namespace,
+			NodeName:      fmt.Sprintf("node-%03d", i%max(nodeCount, 1)),
+			Timestamp:     now,
+			CPUUsage:      int64(100 + i%400),
+			MemoryUsage:   int64(128<<20 + i),
+			CPURequest:    250,
+			CPULimit:      500,
+			MemoryRequest: 256 << 20,
+			MemoryLimit:   512 << 20,
+			Containers: []metricstypes.ContainerMetrics{
+				{Name: "app", CPUUsage: int64(100 + i%400), MemoryUsage: int64(128<<20 + i), CPURequest: 250, CPULimit: 500},
+			},
+			Phase:     "Running",
+			Ready:     true,
+			StartTime: now.Add(-time.Hour),
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/tabular"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// SnapshotTables 快照可导出的表，每个对象一行
+var SnapshotTables = []string{"nodes", "pods", "network", "uavs"}
+
+// SnapshotTable 将快照中的一类对象导出为表格（CSV/Parquet），uavs 为 GetUAVMetrics 的结果
+func SnapshotTable(snapshot *metricstypes.MetricsSnapshot, uavs map[string]*models.UAVMetricsEntry, name string) (*tabular.Table, error) {
+	switch name {
+	case "nodes":
+		return nodesTable(snapshot), nil
+	case "pods":
+		return podsTable(snapshot), nil
+	case "network":
+		return networkTable(snapshot), nil
+	case "uavs":
+		return uavsTable(uavs), nil
+	}
+	return nil, fmt.Errorf("unknown table %q (supported: %s)", name, strings.Join(SnapshotTables, ", "))
+}
+
+func nodesTable(snapshot *metricstypes.MetricsSnapshot) *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "node_name", Type: tabular.String},
+		tabular.Column{Name: "timestamp", Type: tabular.Time},
+		tabular.Column{Name: "healthy", Type: tabular.Bool},
+		tabular.Column{Name: "conditions", Type: tabular.String},
+		tabular.Column{Name: "cpu_capacity", Type: tabular.Int},
+		tabular.Column{Name: "cpu_usage", Type: tabular.Int},
+		tabular.Column{Name: "cpu_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "cpu_usage_trend", Type: tabular.Float},
+		tabular.Column{Name: "memory_capacity", Type: tabular.Int},
+		tabular.Column{Name: "memory_usage", Type: tabular.Int},
+		tabular.Column{Name: "memory_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "disk_capacity", Type: tabular.Int},
+		tabular.Column{Name: "disk_usage", Type: tabular.Int},
+		tabular.Column{Name: "disk_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "network_latency", Type: tabular.Float},
+		tabular.Column{Name: "network_rx_rate", Type: tabular.Float},
+		tabular.Column{Name: "network_tx_rate", Type: tabular.Float},
+		tabular.Column{Name: "gpu_count", Type: tabular.Int},
+	)
+	for _, name := range sortedKeys(snapshot.NodeMetrics) {
+		node := snapshot.NodeMetrics[name]
+		table.Append(name, node.Timestamp, node.Healthy, strings.Join(node.Conditions, ";"),
+			node.CPUCapacity, node.CPUUsage, node.CPUUsageRate, node.CPUUsageTrend,
+			node.MemoryCapacity, node.MemoryUsage, node.MemoryUsageRate,
+			node.DiskCapacity, node.DiskUsage, node.DiskUsageRate,
+			node.NetworkLatency, node.NetworkRxRate, node.NetworkTxRate, int64(node.GPUCount))
+	}
+	return table
+}
+
+func podsTable(snapshot *metricstypes.MetricsSnapshot) *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "namespace", Type: tabular.String},
+		tabular.Column{Name: "pod_name", Type: tabular.String},
+		tabular.Column{Name: "node_name", Type: tabular.String},
+		tabular.Column{Name: "timestamp", Type: tabular.Time},
+		tabular.Column{Name: "phase", Type: tabular.String},
+		tabular.Column{Name: "ready", Type: tabular.Bool},
+		tabular.Column{Name: "restarts", Type: tabular.Int},
+		tabular.Column{Name: "restart_rate", Type: tabular.Float},
+		tabular.Column{Name: "cpu_usage", Type: tabular.Int},
+		tabular.Column{Name: "cpu_request", Type: tabular.Int},
+		tabular.Column{Name: "cpu_limit", Type: tabular.Int},
+		tabular.Column{Name: "cpu_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "cpu_usage_trend", Type: tabular.Float},
+		tabular.Column{Name: "memory_usage", Type: tabular.Int},
+		tabular.Column{Name: "memory_request", Type: tabular.Int},
+		tabular.Column{Name: "memory_limit", Type: tabular.Int},
+		tabular.Column{Name: "memory_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "ephemeral_storage_usage", Type: tabular.Int},
+		tabular.Column{Name: "gpu_request", Type: tabular.Int},
+		tabular.Column{Name: "gpu_usage", Type: tabular.Float},
+		tabular.Column{Name: "gpu_usage_rate", Type: tabular.Float},
+		tabular.Column{Name: "gpu_memory_used", Type: tabular.Int},
+	)
+	for _, key := range sortedKeys(snapshot.PodMetrics) {
+		pod := snapshot.PodMetrics[key]
+		table.Append(pod.Namespace, pod.PodName, pod.NodeName, pod.Timestamp, pod.Phase, pod.Ready,
+			int64(pod.Restarts), pod.RestartRate,
+			pod.CPUUsage, pod.CPURequest, pod.CPULimit, pod.CPUUsageRate, pod.CPUUsageTrend,
+			pod.MemoryUsage, pod.MemoryRequest, pod.MemoryLimit, pod.MemoryUsageRate,
+			pod.EphemeralStorageUsage, pod.GPURequest, pod.GPUUsage, pod.GPUUsageRate, pod.GPUMemoryUsed)
+	}
+	return table
+}
+
+func networkTable(snapshot *metricstypes.MetricsSnapshot) *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "source_pod", Type: tabular.String},
+		tabular.Column{Name: "target_pod", Type: tabular.String},
+		tabular.Column{Name: "timestamp", Type: tabular.Time},
+		tabular.Column{Name: "connected", Type: tabular.Bool},
+		tabular.Column{Name: "rtt_ms", Type: tabular.Float},
+		tabular.Column{Name: "packet_loss", Type: tabular.Float},
+		tabular.Column{Name: "bandwidth_mbps", Type: tabular.Float},
+		tabular.Column{Name: "dns_latency_ms", Type: tabular.Float},
+		tabular.Column{Name: "test_method", Type: tabular.String},
+		tabular.Column{Name: "probe_source", Type: tabular.String},
+		tabular.Column{Name: "error", Type: tabular.String},
+		tabular.Column{Name: "bandwidth_error", Type: tabular.String},
+	)
+	for _, pair := range snapshot.NetworkMetrics {
+		table.Append(pair.SourcePod, pair.TargetPod, pair.Timestamp, pair.Connected,
+			pair.RTT, pair.PacketLoss, pair.Bandwidth, pair.DNSLatency, pair.TestMethod, pair.ProbeSource, pair.Error, pair.BandwidthError)
+	}
+	return table
+}
+
+// uavsTable 每个UAV节点一行，没有遥测状态的UAV只有状态列
+func uavsTable(uavs map[string]*models.UAVMetricsEntry) *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "node_name", Type: tabular.String},
+		tabular.Column{Name: "uav_id", Type: tabular.String},
+		tabular.Column{Name: "status", Type: tabular.String},
+		tabular.Column{Name: "source", Type: tabular.String},
+		tabular.Column{Name: "last_heartbeat", Type: tabular.Time},
+		tabular.Column{Name: "flight_mode", Type: tabular.String},
+		tabular.Column{Name: "armed", Type: tabular.Bool},
+		tabular.Column{Name: "latitude", Type: tabular.Float},
+		tabular.Column{Name: "longitude", Type: tabular.Float},
+		tabular.Column{Name: "altitude", Type: tabular.Float},
+		tabular.Column{Name: "relative_altitude", Type: tabular.Float},
+		tabular.Column{Name: "ground_speed", Type: tabular.Float},
+		tabular.Column{Name: "battery_percent", Type: tabular.Float},
+		tabular.Column{Name: "battery_voltage", Type: tabular.Float},
+		tabular.Column{Name: "system_status", Type: tabular.String},
+	)
+	for _, node := range sortedKeys(uavs) {
+		entry := uavs[node]
+		row := []interface{}{node, entry.UAVID, entry.Status, entry.Source, entry.LastHeartbeat}
+		if state := entry.State; state != nil {
+			if entry.UAVID == "" {
+				row[1] = state.UAVID
+			}
+			row = append(row, state.Flight.Mode, state.Flight.Armed,
+				state.GPS.Latitude, state.GPS.Longitude, state.GPS.Altitude, state.GPS.RelativeAltitude,
+				state.GPS.GroundSpeed, state.Battery.RemainingPercent, state.Battery.Voltage, state.Health.SystemStatus)
+		} else {
+			row = append(row, make([]interface{}, 10)...)
+		}
+		table.Append(row...)
+	}
+	return table
+}
+
+// Table 将序列导出为表格，每个点一行
+func (s *Series) Table() *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "target", Type: tabular.String},
+		tabular.Column{Name: "metric", Type: tabular.String},
+		tabular.Column{Name: "timestamp", Type: tabular.Time},
+		tabular.Column{Name: "value", Type: tabular.Float},
+	)
+	for _, p := range s.Points {
+		table.Append(s.Target, s.Metric, p.Timestamp, p.Value)
+	}
+	return table
+}
+
+// sortedKeys 按名称排序的键，导出的行顺序固定
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Table 将聚合结果导出为表格：有 Series 时每个窗口一行，否则为整个时间范围一行；
+// aggregations 决定聚合列的顺序，为空时按名称排序
+func (r *AggregateResult) Table(aggregations []string) *tabular.Table {
+	if len(aggregations) == 0 {
+		aggregations = sortedKeys(r.Summary)
+	}
+	columns := []tabular.Column{
+		{Name: "key", Type: tabular.String},
+		{Name: "metric", Type: tabular.String},
+		{Name: "timestamp", Type: tabular.Time},
+	}
+	for _, agg := range aggregations {
+		columns = append(columns, tabular.Column{Name: agg, Type: tabular.Float})
+	}
+	table := tabular.New(columns...)
+
+	appendRow := func(timestamp time.Time, values map[string]float64) {
+		row := []interface{}{r.Key, r.Metric, timestamp}
+		for _, agg := range aggregations {
+			if value, ok := values[agg]; ok {
+				row = append(row, value)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		table.Append(row...)
+	}
+	if len(r.Series) == 0 {
+		appendRow(r.From, r.Summary)
+		return table
+	}
+	for _, point := range r.Series {
+		appendRow(point.Timestamp, point.Values)
+	}
+	return table
+}
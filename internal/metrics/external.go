@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// collectExternalTargets 从各节点访问外部目标并发布新的结果；部分失败时仍发布已有的结果
+func (m *Manager) collectExternalTargets(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "metrics.collect.external")
+	reachability, err := m.externalSource.CollectExternalTargets(ctx)
+	tracing.End(span, err)
+	if err != nil && !sources.IsPartial(err) {
+		m.logger.Errorf("Failed to check external targets: %v", err)
+		return err
+	}
+
+	m.snapshotMutex.Lock()
+	m.external = reachability
+	m.snapshotMutex.Unlock()
+	return err
+}
+
+// GetExternalReachability 返回最近一轮外部目标的探测结果，未配置或尚未探测时为nil。
+// 结果发布后不再修改，调用方只能读取
+func (m *Manager) GetExternalReachability() *metricstypes.ExternalReachability {
+	m.snapshotMutex.RLock()
+	defer m.snapshotMutex.RUnlock()
+	return m.external
+}
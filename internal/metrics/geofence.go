@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// geofenceState 一个UAV上一次的地理围栏状态
+type geofenceState struct {
+	breached    bool
+	breachCount int
+}
+
+// OnGeofenceChange 设置UAV越出或回到地理围栏时的回调（如记录K8s事件、更新UAVMetric状态），
+// 在处理上报或采集时同步调用
+func (m *Manager) OnGeofenceChange(fn func(ctx context.Context, changes []*models.UAVGeofenceChange)) {
+	m.snapshotMutex.Lock()
+	m.onGeofenceChange = fn
+	m.snapshotMutex.Unlock()
+}
+
+// checkGeofences 按Agent报告的围栏状态检测越界和恢复：越界中的UAV再次报告越界、
+// 但越界次数增加（两次上报之间回到围栏内又越出）时同样记为一次越界
+func (m *Manager) checkGeofences(ctx context.Context, entries map[string]*models.UAVMetricsEntry) {
+	var changes []*models.UAVGeofenceChange
+
+	m.snapshotMutex.Lock()
+	if m.geofences == nil {
+		m.geofences = make(map[string]geofenceState)
+	}
+	for key, entry := range entries {
+		if entry == nil || entry.State == nil || !entry.State.Geofence.Enabled {
+			continue
+		}
+		fence := entry.State.Geofence
+		previous := m.geofences[key]
+		m.geofences[key] = geofenceState{breached: fence.Breached, breachCount: fence.BreachCount}
+
+		breach := fence.Breached && (!previous.breached || fence.BreachCount > previous.breachCount)
+		cleared := !fence.Breached && previous.breached
+		if !breach && !cleared {
+			continue
+		}
+		timestamp := entry.LastHeartbeat
+		if fence.Breached && fence.LastBreach != nil {
+			timestamp = *fence.LastBreach
+		}
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		nodeName := entry.NodeName
+		if nodeName == "" {
+			nodeName = key
+		}
+		changes = append(changes, &models.UAVGeofenceChange{
+			NodeName:         nodeName,
+			UAVID:            entry.UAVID,
+			Index:            entry.Index,
+			Breached:         fence.Breached,
+			BreachCount:      fence.BreachCount,
+			Latitude:         entry.State.GPS.Latitude,
+			Longitude:        entry.State.GPS.Longitude,
+			RelativeAltitude: entry.State.GPS.RelativeAltitude,
+			Action:           fence.Action,
+			Timestamp:        timestamp.UTC(),
+		})
+	}
+	onChange := m.onGeofenceChange
+	m.snapshotMutex.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	for _, change := range changes {
+		if change.Breached {
+			m.logger.Warnf("UAV %s on node %s breached its geofence at %.6f,%.6f (%.1fm), action: %s",
+				change.UAVID, change.NodeName, change.Latitude, change.Longitude, change.RelativeAltitude, change.Action)
+		} else {
+			m.logger.Infof("UAV %s on node %s is back inside its geofence", change.UAVID, change.NodeName)
+		}
+	}
+	if onChange != nil {
+		onChange(ctx, changes)
+	}
+}
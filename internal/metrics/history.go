@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidTarget 无法识别的指标对象
+	ErrInvalidTarget = errors.New("invalid target")
+	// ErrUnknownMetric 对象类型不支持该指标
+	ErrUnknownMetric = errors.New("unknown metric")
+)
+
+// SeriesMetrics 各类对象在内存历史中可查询的指标
+var SeriesMetrics = map[string][]string{
+	TargetNode: {"cpu_usage", "cpu_usage_rate", "memory_usage", "memory_usage_rate", "disk_usage_rate", "network_latency", "cpu_usage_trend", "network_rx_rate", "network_tx_rate", "healthy"},
+	TargetPod:  {"cpu_usage", "cpu_usage_rate", "memory_usage", "memory_usage_rate", "restarts", "restart_rate", "cpu_usage_trend", "ready", "gpu_usage", "gpu_usage_rate", "gpu_memory_used"},
+	TargetPair: {"rtt_ms", "packet_loss", "bandwidth_mbps", "jitter_ms", "quality_score", "connected"},
+	TargetUAV:  {"battery_percent", "battery_voltage", "latitude", "longitude", "altitude", "relative_altitude", "ground_speed", "armed"},
+}
+
+// knownSeriesMetric 指标是否在该类对象的样本中
+func knownSeriesMetric(kind, metric string) bool {
+	for _, name := range SeriesMetrics[kind] {
+		if name == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTarget 将 node/worker-1 形式的对象拆分为类型和样本键（uav的样本键为节点名）
+func ParseTarget(target string) (string, string, error) {
+	kind, name, ok := strings.Cut(strings.TrimSpace(target), "/")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("%w %q: expected node/<name>, pod/<namespace>/<name>, pair/<source>|<dest> or uav/<node>", ErrInvalidTarget, target)
+	}
+	switch kind = strings.ToLower(kind); kind {
+	case TargetNode:
+		return kind, SampleKey(kind, name), nil
+	case TargetPod:
+		namespace, pod, ok := strings.Cut(name, "/")
+		if !ok {
+			namespace, pod = "default", name
+		}
+		return kind, SampleKey(kind, namespace, pod), nil
+	case TargetPair:
+		source, dest, ok := strings.Cut(name, "|")
+		if !ok || source == "" || dest == "" {
+			return "", "", fmt.Errorf("%w %q: pair targets are pair/<source>|<dest>", ErrInvalidTarget, target)
+		}
+		return kind, SampleKey(kind, source, dest), nil
+	case TargetUAV:
+		return kind, name, nil
+	default:
+		return "", "", fmt.Errorf("%w %q: kind must be one of node, pod, pair, uav", ErrInvalidTarget, target)
+	}
+}
+
+// SeriesPoint 序列中的一个点
+type SeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Series 内存历史中一个对象的指标序列
+type Series struct {
+	Target string        `json:"target"`
+	Metric string        `json:"metric"`
+	From   time.Time     `json:"from,omitempty"`
+	To     time.Time     `json:"to,omitempty"`
+	Step   string        `json:"step,omitempty"`
+	Points []SeriesPoint `json:"points"`
+}
+
+// historyEntry 一次采集中各对象的数值指标
+type historyEntry struct {
+	timestamp time.Time
+	samples   map[string]map[string]float64 // 样本键 -> 指标 -> 值
+}
+
+// snapshotHistory 最近若干次采集结果的环形缓冲区，容量为 保留时长/采集间隔
+type snapshotHistory struct {
+	mu        sync.RWMutex
+	entries   []historyEntry
+	next      int // 下一次写入的位置
+	size      int
+	retention time.Duration
+	interval  time.Duration
+}
+
+func newSnapshotHistory(retention, interval time.Duration) *snapshotHistory {
+	h := &snapshotHistory{}
+	h.resize(retention, interval)
+	return h
+}
+
+// setRetention 调整保留时长
+func (h *snapshotHistory) setRetention(retention time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resize(retention, h.interval)
+}
+
+// setInterval 采集间隔变化时调整容量
+func (h *snapshotHistory) setInterval(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resize(h.retention, interval)
+}
+
+// capacityFor 保留时长内最多的采集次数
+func capacityFor(retention, interval time.Duration) int {
+	if retention <= 0 {
+		return 0
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return int((retention+interval-1)/interval) + 1
+}
+
+// resize 按保留时长和采集间隔重新分配缓冲区，保留最新的条目（调用方持有锁）
+func (h *snapshotHistory) resize(retention, interval time.Duration) {
+	capacity := capacityFor(retention, interval)
+	if capacity == len(h.entries) {
+		h.retention, h.interval = retention, interval
+		return
+	}
+	entries := make([]historyEntry, capacity)
+	kept := h.size
+	if kept > capacity {
+		kept = capacity
+	}
+	for i := 0; i < kept; i++ {
+		// 从旧缓冲区中按时间顺序取最新的 kept 个条目
+		entries[i] = h.entries[(h.next-kept+i+len(h.entries))%len(h.entries)]
+	}
+	h.entries, h.size, h.retention, h.interval = entries, kept, retention, interval
+	h.next = 0
+	if capacity > 0 {
+		h.next = kept % capacity
+	}
+}
+
+// add 写入一次采集结果，缓冲区满时覆盖最旧的条目
+func (h *snapshotHistory) add(entry historyEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return
+	}
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.size < len(h.entries) {
+		h.size++
+	}
+}
+
+// series 按时间顺序返回范围内某对象某指标的样本
+func (h *snapshotHistory) series(key, metric string, from, to time.Time) []SeriesPoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	points := []SeriesPoint{}
+	if h.size == 0 {
+		return points
+	}
+	cutoff := time.Now().Add(-h.retention)
+	start := (h.next - h.size + len(h.entries)) % len(h.entries)
+	for i := 0; i < h.size; i++ {
+		entry := h.entries[(start+i)%len(h.entries)]
+		if entry.timestamp.Before(cutoff) || (!from.IsZero() && entry.timestamp.Before(from)) || (!to.IsZero() && entry.timestamp.After(to)) {
+			continue
+		}
+		if value, ok := entry.samples[key][metric]; ok {
+			points = append(points, SeriesPoint{Timestamp: entry.timestamp, Value: value})
+		}
+	}
+	// 采集时间单调递增，保险起见仍按时间排序
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	return points
+}
+
+// at 返回不晚于 t 的最近一次采集结果，t 早于内存中最旧的条目时返回false
+func (h *snapshotHistory) at(t time.Time) (historyEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var found historyEntry
+	ok := false
+	if h.size == 0 {
+		return found, false
+	}
+	cutoff := time.Now().Add(-h.retention)
+	start := (h.next - h.size + len(h.entries)) % len(h.entries)
+	for i := 0; i < h.size; i++ {
+		entry := h.entries[(start+i)%len(h.entries)]
+		if entry.timestamp.Before(cutoff) || entry.timestamp.After(t) {
+			continue
+		}
+		if !ok || entry.timestamp.After(found.timestamp) {
+			found, ok = entry, true
+		}
+	}
+	return found, ok
+}
+
+// SetHistoryRetention 调整内存历史的保留时长（配置热更新），为0时不保留历史
+func (m *Manager) SetHistoryRetention(retention time.Duration) {
+	m.history.setRetention(retention)
+	m.logger.Infof("Metrics history retention changed to %v", retention)
+}
+
+// GetSeries 从内存历史中读取对象的指标序列。target 为 node/<name>、pod/<namespace>/<name>、
+// pair/<source>|<dest> 或 uav/<node>；step>0 时按窗口取平均值
+func (m *Manager) GetSeries(metric, target string, from, to time.Time, step time.Duration) (*Series, error) {
+	kind, key, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if !knownSeriesMetric(kind, metric) {
+		return nil, fmt.Errorf("%w %q for %s targets (supported: %s)", ErrUnknownMetric, metric, kind, strings.Join(SeriesMetrics[kind], ", "))
+	}
+
+	result := &Series{
+		Target: target,
+		Metric: metric,
+		From:   from,
+		To:     to,
+		Points: m.history.series(key, metric, from, to),
+	}
+	if step <= 0 || len(result.Points) == 0 {
+		return result, nil
+	}
+
+	result.Step = step.String()
+	averaged := []SeriesPoint{}
+	var bucketStart time.Time
+	sum, count := 0.0, 0
+	for _, p := range result.Points {
+		start := p.Timestamp.Truncate(step)
+		if count > 0 && !start.Equal(bucketStart) {
+			averaged = append(averaged, SeriesPoint{Timestamp: bucketStart, Value: sum / float64(count)})
+			sum, count = 0, 0
+		}
+		bucketStart = start
+		sum += p.Value
+		count++
+	}
+	averaged = append(averaged, SeriesPoint{Timestamp: bucketStart, Value: sum / float64(count)})
+	result.Points = averaged
+	return result, nil
+}
@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// 链路质量跟踪参数
+const (
+	linkBaselineWindow    = 24 * time.Hour // 基线使用的历史时长，同时为测试记录的保留时长
+	maxLinkSamples        = 2880           // 每个Pod对最多保留的测试记录（30秒一次时为24小时）
+	minLinkBaselineSample = 10             // 基线至少需要的测试记录数
+	defaultQualityWindow  = 5              // 计算当前抖动和质量的最近测试次数
+	defaultDegradation    = 30             // 质量评分较基线下降该百分比视为劣化
+)
+
+// LinkStats 一段时间内Pod对的测试统计
+type LinkStats struct {
+	Samples      int     `json:"samples"`
+	RTT          float64 `json:"rtt_ms"`      // 连通测试的平均往返时延
+	PacketLoss   float64 `json:"packet_loss"` // 平均丢包率，不连通的测试计为100
+	Jitter       float64 `json:"jitter_ms"`   // 相邻两次连通测试RTT之差的平均值
+	QualityScore float64 `json:"quality_score"`
+}
+
+// LinkTrend 一个Pod对最近的链路质量与24小时基线的比较
+type LinkTrend struct {
+	SourcePod   string     `json:"source_pod"`
+	TargetPod   string     `json:"target_pod"`
+	LastTested  time.Time  `json:"last_tested"`
+	Current     LinkStats  `json:"current"`            // 最近 quality_window 次测试
+	Baseline    *LinkStats `json:"baseline,omitempty"` // 24小时内更早的测试，记录不足时为空
+	Degradation float64    `json:"degradation"`        // 质量评分较基线下降的百分比（上升为负数）
+	Degraded    bool       `json:"degraded"`
+}
+
+// linkSample 一次测试的结果
+type linkSample struct {
+	at         time.Time
+	connected  bool
+	rtt        float64
+	packetLoss float64
+}
+
+// linkHistory 一个Pod对的测试记录（按时间升序）
+type linkHistory struct {
+	source, target string
+	samples        []linkSample
+}
+
+// linkTracker 保存各Pod对24小时内的RTT和丢包记录，计算抖动、质量评分和相对基线的劣化
+type linkTracker struct {
+	mu        sync.RWMutex
+	window    int     // 计算当前统计的最近测试次数
+	threshold float64 // 劣化阈值（百分比）
+	links     map[string]*linkHistory
+}
+
+// newLinkTracker 创建链路质量跟踪器，参数为0时使用默认值
+func newLinkTracker(window int, threshold float64) *linkTracker {
+	if window <= 0 {
+		window = defaultQualityWindow
+	}
+	if threshold <= 0 {
+		threshold = defaultDegradation
+	}
+	return &linkTracker{window: window, threshold: threshold, links: make(map[string]*linkHistory)}
+}
+
+// observe 记录本轮新测试的结果，并填写各网络指标的抖动和质量评分
+func (t *linkTracker) observe(network []*metricstypes.NetworkMetrics, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, metric := range network {
+		if metric == nil {
+			continue
+		}
+		key := metric.SourcePod + "|" + metric.TargetPod
+		history := t.links[key]
+		if history == nil {
+			history = &linkHistory{source: metric.SourcePod, target: metric.TargetPod}
+			t.links[key] = history
+		}
+		at := metric.Timestamp
+		if at.IsZero() {
+			at = now
+		}
+		history.samples = append(history.samples, linkSample{at: at, connected: metric.Connected, rtt: metric.RTT, packetLoss: metric.PacketLoss})
+		if len(history.samples) > maxLinkSamples {
+			history.samples = history.samples[len(history.samples)-maxLinkSamples:]
+		}
+
+		recent := history.samples[max(0, len(history.samples)-t.window):]
+		metric.Jitter = linkJitter(recent)
+		metric.QualityScore = metricstypes.LinkQualityScore(metric.Connected, metric.RTT, metric.PacketLoss, metric.Jitter)
+	}
+
+	// 丢弃超过保留时长的记录和不再测试的Pod对
+	for key, history := range t.links {
+		keep := sort.Search(len(history.samples), func(i int) bool { return now.Sub(history.samples[i].at) <= linkBaselineWindow })
+		history.samples = history.samples[keep:]
+		if len(history.samples) == 0 {
+			delete(t.links, key)
+		}
+	}
+}
+
+// trends 各Pod对的链路质量趋势（劣化幅度从大到小），degradedOnly 时只返回劣化的链路
+func (t *linkTracker) trends(degradedOnly bool, now time.Time) []LinkTrend {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := []LinkTrend{}
+	for _, history := range t.links {
+		trend, ok := t.trend(history, now)
+		if !ok || (degradedOnly && !trend.Degraded) {
+			continue
+		}
+		result = append(result, trend)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Degradation != result[j].Degradation {
+			return result[i].Degradation > result[j].Degradation
+		}
+		return result[i].SourcePod+"|"+result[i].TargetPod < result[j].SourcePod+"|"+result[j].TargetPod
+	})
+	return result
+}
+
+// trend 比较最近 window 次测试与24小时内更早的测试（调用方持有锁）
+func (t *linkTracker) trend(history *linkHistory, now time.Time) (LinkTrend, bool) {
+	keep := sort.Search(len(history.samples), func(i int) bool { return now.Sub(history.samples[i].at) <= linkBaselineWindow })
+	samples := history.samples[keep:]
+	if len(samples) == 0 {
+		return LinkTrend{}, false
+	}
+
+	split := max(0, len(samples)-t.window)
+	trend := LinkTrend{
+		SourcePod:  history.source,
+		TargetPod:  history.target,
+		LastTested: samples[len(samples)-1].at,
+		Current:    linkStats(samples[split:]),
+	}
+	if split >= minLinkBaselineSample {
+		baseline := linkStats(samples[:split])
+		trend.Baseline = &baseline
+		if baseline.QualityScore > 0 {
+			trend.Degradation = round((baseline.QualityScore - trend.Current.QualityScore) / baseline.QualityScore * 100)
+			trend.Degraded = trend.Degradation >= t.threshold
+		}
+	}
+	return trend, true
+}
+
+// issues 质量较基线明显劣化的链路，写入 ClusterMetrics.Issues
+func (t *linkTracker) issues(now time.Time) []string {
+	var issues []string
+	for _, trend := range t.trends(true, now) {
+		issues = append(issues, fmt.Sprintf("Link %s -> %s quality degraded %.0f%% vs 24h baseline (score %.1f vs %.1f, rtt %.1fms, loss %.1f%%, jitter %.1fms)",
+			trend.SourcePod, trend.TargetPod, trend.Degradation, trend.Current.QualityScore, trend.Baseline.QualityScore,
+			trend.Current.RTT, trend.Current.PacketLoss, trend.Current.Jitter))
+	}
+	return issues
+}
+
+// linkStats 统计一组测试：RTT只计连通的测试，不连通的测试丢包计为100
+func linkStats(samples []linkSample) LinkStats {
+	stats := LinkStats{Samples: len(samples)}
+	connected := 0
+	for _, sample := range samples {
+		if sample.connected {
+			connected++
+			stats.RTT += sample.rtt
+			stats.PacketLoss += sample.packetLoss
+		} else {
+			stats.PacketLoss += 100
+		}
+	}
+	if connected > 0 {
+		stats.RTT = round(stats.RTT / float64(connected))
+	}
+	if len(samples) > 0 {
+		stats.PacketLoss = round(stats.PacketLoss / float64(len(samples)))
+	}
+	stats.Jitter = linkJitter(samples)
+	stats.QualityScore = metricstypes.LinkQualityScore(connected > 0, stats.RTT, stats.PacketLoss, stats.Jitter)
+	return stats
+}
+
+// linkJitter 相邻两次连通测试RTT之差的平均值 (ms)
+func linkJitter(samples []linkSample) float64 {
+	var sum float64
+	var count int
+	previous := -1.0
+	for _, sample := range samples {
+		if !sample.connected {
+			continue
+		}
+		if previous >= 0 {
+			diff := sample.rtt - previous
+			if diff < 0 {
+				diff = -diff
+			}
+			sum += diff
+			count++
+		}
+		previous = sample.rtt
+	}
+	if count == 0 {
+		return 0
+	}
+	return round(sum / float64(count))
+}
+
+// GetLinkTrends 返回各Pod对最近的链路质量与24小时基线的比较，degradedOnly 时只返回劣化的链路
+func (m *Manager) GetLinkTrends(degradedOnly bool) []LinkTrend {
+	return m.links.trends(degradedOnly, time.Now())
+}
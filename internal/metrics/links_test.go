@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// observeLink 记录一次 a -> b 的测试结果
+func observeLink(tracker *linkTracker, at time.Time, connected bool, rtt, loss float64) *metricstypes.NetworkMetrics {
+	metric := &metricstypes.NetworkMetrics{SourcePod: "default/a", TargetPod: "default/b", Timestamp: at, Connected: connected, RTT: rtt, PacketLoss: loss}
+	tracker.observe([]*metricstypes.NetworkMetrics{metric}, at)
+	return metric
+}
+
+func TestLinkTrackerJitterAndScore(t *testing.T) {
+	tracker := newLinkTracker(3, 30)
+	start := time.Now()
+
+	observeLink(tracker, start, true, 10, 0)
+	observeLink(tracker, start.Add(time.Minute), true, 14, 0)
+	observeLink(tracker, start.Add(2*time.Minute), false, 0, 100)
+	metric := observeLink(tracker, start.Add(3*time.Minute), true, 12, 0)
+
+	// 最近3次中不连通的测试不计入抖动：|12-14| = 2
+	if metric.Jitter != 2 {
+		t.Errorf("jitter = %v, want 2", metric.Jitter)
+	}
+	if want := metricstypes.LinkQualityScore(true, 12, 0, 2); metric.QualityScore != want {
+		t.Errorf("quality score = %v, want %v", metric.QualityScore, want)
+	}
+
+	trends := tracker.trends(false, start.Add(3*time.Minute))
+	if len(trends) != 1 {
+		t.Fatalf("got %d trends, want 1", len(trends))
+	}
+	if trends[0].Baseline != nil {
+		t.Errorf("baseline should need %d samples, got %+v", minLinkBaselineSample, trends[0].Baseline)
+	}
+	if current := trends[0].Current; current.Samples != 3 || current.RTT != 13 || current.PacketLoss != round(100.0/3) {
+		t.Errorf("current = %+v, want 3 samples, rtt 13 and a third lost", current)
+	}
+}
+
+func TestLinkTrackerFlagsDegradation(t *testing.T) {
+	tracker := newLinkTracker(3, 30)
+	start := time.Now().Add(-time.Hour)
+
+	now := start
+	for i := 0; i < 20; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		observeLink(tracker, now, true, 2, 0)
+	}
+	if issues := tracker.issues(now); len(issues) != 0 {
+		t.Fatalf("stable link reported as degraded: %v", issues)
+	}
+
+	for i := 20; i < 23; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		observeLink(tracker, now, true, 2+float64(i-20)*40, 10)
+	}
+	trends := tracker.trends(true, now)
+	if len(trends) != 1 || trends[0].Baseline == nil || trends[0].Baseline.Samples != 20 {
+		t.Fatalf("trends = %+v, want one degraded link with a 20-sample baseline", trends)
+	}
+	if trends[0].Degradation < 30 {
+		t.Errorf("degradation = %v, want at least 30", trends[0].Degradation)
+	}
+	issues := tracker.issues(now)
+	if len(issues) != 1 || !strings.Contains(issues[0], "Link default/a -> default/b quality degraded") {
+		t.Errorf("issues = %v", issues)
+	}
+}
+
+func TestLinkTrackerExpiresOldSamples(t *testing.T) {
+	tracker := newLinkTracker(3, 30)
+	start := time.Now()
+	observeLink(tracker, start, true, 1, 0)
+
+	// 其他Pod对的测试触发清理
+	later := start.Add(linkBaselineWindow + time.Minute)
+	tracker.observe([]*metricstypes.NetworkMetrics{{SourcePod: "default/c", TargetPod: "default/d", Timestamp: later, Connected: true, RTT: 1}}, later)
+
+	trends := tracker.trends(false, later)
+	if len(trends) != 1 || trends[0].SourcePod != "default/c" {
+		t.Errorf("trends = %+v, want only the recently tested pair", trends)
+	}
+}
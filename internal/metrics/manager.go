@@ -2,15 +2,24 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
 	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/remotewrite"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
 	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
 	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
@@ -23,17 +32,84 @@ type Manager struct {
 	podSource     PodMetricsSource
 	networkSource NetworkMetricsSource
 	customSource  CustomMetricsSource
+	gpuSource     GPUMetricsSource
+	summarySource SummaryMetricsSource
 	uavSource     UAVMetricsSource
 
+	// 节点间时延（探测Agent），单独按自己的间隔测量
+	nodeLatencySource NodeLatencySource
+	nodeLatency       *metricstypes.NodeLatencyMatrix // snapshotMutex 保护，发布后不再修改
+
+	// 从集群内部访问外部目标的结果（探测Agent），单独按自己的间隔测量
+	externalSource ExternalTargetsSource
+	external       *metricstypes.ExternalReachability // snapshotMutex 保护，发布后不再修改
+
 	// 缓存
-	snapshot         *metricstypes.MetricsSnapshot
-	uavSnapshot      map[string]interface{} // UAV状态快照
-	uavLastHeartbeat map[string]time.Time   // UAV最后心跳时间
-	snapshotMutex    sync.RWMutex
+	snapshot      *metricstypes.MetricsSnapshot
+	uavSnapshot   map[string]*models.UAVMetricsEntry // UAV状态快照
+	snapshotMutex sync.RWMutex
+
+	// 最近采集结果的内存历史（metrics.cache_retention）
+	history *snapshotHistory
+
+	// 各Pod的容器重启历史
+	restarts *restartTracker
+
+	// 各Pod对的RTT和丢包记录，用于计算抖动和链路质量趋势
+	links *linkTracker
+
+	// 节点条件的上一次状态（未配置存储时也保存条件变化）
+	conditions *conditionTracker
+
+	// 各SLO的达标计数（metrics.slos）
+	slos *sloTracker
+
+	// UAV心跳超时检测（为空表示未启用）
+	staleness *uavStaleness
+
+	// 每轮新测得网络指标后的回调（如导出为NetworkProbeResult CRD），snapshotMutex 保护
+	onNetworkMetrics func(ctx context.Context, metrics []*metricstypes.NetworkMetrics)
+
+	// 各UAV上一次的地理围栏状态和状态变化的回调，snapshotMutex 保护
+	geofences        map[string]geofenceState
+	onGeofenceChange func(ctx context.Context, changes []*models.UAVGeofenceChange)
+
+	// 权限不足时的降级状态
+	permissions *sources.PermissionTracker
+
+	// 异常检测（为空表示未启用）
+	anomalies *AnomalyDetector
+
+	// 推送到Prometheus remote_write（为空表示未启用）
+	remoteWrite *remotewrite.Client
+
+	// 实时更新的订阅者（/api/v1/metrics/stream）
+	stream streamHub
+
+	// 样本降采样（为空表示未启用）
+	rollups        *rollupTracker
+	rollupInterval time.Duration
+
+	// 持久化
+	store           storage.Store
+	persistInterval time.Duration
+
+	// 多副本共享快照和UAV状态（共享存储）
+	shareState   bool
+	syncInterval time.Duration
 
 	// 配置
-	interval time.Duration
-	logger   *logrus.Logger
+	interval     time.Duration
+	rescheduleCh chan struct{} // 运行时调整采集间隔后重新计算下次采集时间
+	scheduler    *collectorScheduler
+	logger       *logrus.Entry
+
+	// 未到采集时间或采集失败的数据源沿用上次的结果（collectMutex 保护）
+	collectMutex  sync.Mutex
+	lastCustom    map[string]interface{}
+	lastGPU       map[string][]metricstypes.GPUDevice
+	lastSummaries map[string]*metricstypes.KubeletSummary
+	sourceStatus  map[string]*metricstypes.SourceStatus
 
 	// 控制
 	stopChan chan struct{}
@@ -44,17 +120,76 @@ type Manager struct {
 // ManagerConfig 管理器配置
 type ManagerConfig struct {
 	Namespaces      []string      // 要监控的命名空间
-	CollectInterval time.Duration // 采集间隔
+	CollectInterval time.Duration // 采集间隔（未单独配置间隔的数据源使用）
 	EnableNode      bool          // 是否启用节点指标采集
 	EnablePod       bool          // 是否启用Pod指标采集
 	EnableNetwork   bool          // 是否启用网络指标采集
 	EnableCustom    bool          // 是否启用自定义指标采集
 	EnableUAV       bool          // 是否启用UAV指标采集
+	EnableGPU       bool          // 是否从DCGM Exporter采集GPU使用率和显存
+	EnableSummary   bool          // 是否从kubelet Summary API采集磁盘、网卡流量和Pod临时存储
+
+	// 各数据源的采集间隔（键为采集器名称，如 node、network、uav），未配置的使用 CollectInterval；
+	// Jitter 为每次调度额外等待的随机比例（0-1），避免各数据源同时触发
+	CollectorIntervals map[string]time.Duration
+	Jitter             float64
+
+	// Pod过滤条件（在 Namespaces 内进一步限定）
+	PodFilter sources.PodFilter
+
+	// 自定义指标配置
+	CustomResources []sources.CustomResource // 提供节点指标的自定义资源
+
+	// GPU指标配置
+	GPU sources.GPUCollectorConfig
 
 	// 网络指标配置
-	NetworkMaxPairs    int           // 网络测试最大Pod对数
-	NetworkTestTimeout time.Duration // 网络测试超时时间
-	K8sClient          interface{}   // K8s client（用于网络测试）
+	NetworkMaxPairs      int                       // 网络测试最大Pod对数
+	NetworkStrategy      string                    // Pod对的选择策略（sources.PairStrategy*）
+	NetworkSeed          int64                     // Pod对选择的随机种子，为0时随机
+	NetworkQualityWindow int                       // 计算抖动和当前链路质量的最近测试次数
+	NetworkDegradation   float64                   // 链路质量评分较24小时基线下降该百分比时列入集群问题
+	NetworkTestTimeout   time.Duration             // 网络测试超时时间
+	NetworkConcurrency   int                       // 同时测试的Pod对数
+	NetworkBudget        time.Duration             // 每轮网络测试的时间预算，为0时不限制
+	NetworkBandwidth     *k8s.BandwidthOptions     // iperf3带宽测试选项，为nil时不测试带宽
+	NetworkAgents        *sources.ProbeAgentConfig // 由探测Agent进行网络测试，为nil时exec进入源Pod
+	K8sClient            interface{}               // K8s client（用于网络测试）
+
+	// 节点间时延矩阵配置（为空时不测量），需要探测Agent
+	NodeLatency *sources.NodeLatencyConfig
+
+	// 外部目标可达性配置（为空时不探测），需要探测Agent
+	ExternalTargets *sources.ExternalTargetsConfig
+
+	// UAV Agent访问配置
+	UAVHTTPClient *http.Client // 访问Agent使用的客户端（启用mTLS时使用HTTPS），为空时使用普通HTTP
+
+	// 持久化配置
+	Store           storage.Store // 快照持久化存储（为空时不持久化）
+	PersistInterval time.Duration // 持久化间隔
+
+	// 内存历史保留时长（为0时不保留）
+	HistoryRetention time.Duration
+
+	// 多副本共享：Store 为各副本共用的存储（如Redis）时开启
+	ShareState   bool
+	SyncInterval time.Duration // 读取其他副本状态的间隔
+
+	// 异常检测配置（为空时不检测）
+	Anomalies *AnomalyConfig
+
+	// remote_write推送配置（为空时不推送），用于无法被Prometheus抓取的边缘/UAV集群
+	RemoteWrite *remotewrite.Config
+
+	// 样本降采样任务间隔（为0或未配置 Store 时不降采样）
+	RollupInterval time.Duration
+
+	// 服务等级目标，按滚动窗口计算达标率和错误预算
+	SLOs []SLODefinition
+
+	// UAV心跳超时检测配置（为空时不检测）
+	UAVStaleness *UAVStalenessConfig
 }
 
 // NewManager 创建指标管理器
@@ -71,15 +206,24 @@ func NewManager(restConfig *rest.Config, config ManagerConfig) (*Manager, error)
 		return nil, fmt.Errorf("failed to create metrics client: %w", err)
 	}
 
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("metrics")
 
 	manager := &Manager{
-		interval:         config.CollectInterval,
-		logger:           logger,
-		stopChan:         make(chan struct{}),
-		uavSnapshot:      make(map[string]interface{}),
-		uavLastHeartbeat: make(map[string]time.Time),
+		interval:        config.CollectInterval,
+		rescheduleCh:    make(chan struct{}, 1),
+		store:           config.Store,
+		persistInterval: config.PersistInterval,
+		shareState:      config.ShareState && config.Store != nil,
+		syncInterval:    config.SyncInterval,
+		logger:          logger,
+		permissions:     sources.NewPermissionTracker(0, logger),
+		history:         newSnapshotHistory(config.HistoryRetention, config.CollectInterval),
+		restarts:        newRestartTracker(),
+		links:           newLinkTracker(config.NetworkQualityWindow, config.NetworkDegradation),
+		conditions:      newConditionTracker(),
+		slos:            newSLOTracker(config.SLOs),
+		stopChan:        make(chan struct{}),
+		uavSnapshot:     make(map[string]*models.UAVMetricsEntry),
 		snapshot: &metricstypes.MetricsSnapshot{
 			Timestamp:      time.Now(),
 			NodeMetrics:    make(map[string]*metricstypes.NodeMetrics),
@@ -96,7 +240,9 @@ func NewManager(restConfig *rest.Config, config ManagerConfig) (*Manager, error)
 	}
 
 	if config.EnablePod {
-		manager.podSource = sources.NewPodMetricsCollector(kubeClient, metricsClient, config.Namespaces)
+		podCollector := sources.NewPodMetricsCollector(kubeClient, metricsClient, config.Namespaces)
+		podCollector.SetFilter(config.PodFilter)
+		manager.podSource = podCollector
 		logger.Info("Pod metrics collector enabled")
 	}
 
@@ -107,8 +253,14 @@ func NewManager(restConfig *rest.Config, config ManagerConfig) (*Manager, error)
 			networkConfig := sources.NetworkCollectorConfig{
 				Namespaces:     config.Namespaces,
 				MaxPodPairs:    config.NetworkMaxPairs,
+				Strategy:       config.NetworkStrategy,
+				Seed:           config.NetworkSeed,
 				TestTimeout:    config.NetworkTestTimeout,
+				Budget:         config.NetworkBudget,
+				Concurrency:    config.NetworkConcurrency,
 				EnableAutoTest: true,
+				Bandwidth:      config.NetworkBandwidth,
+				Agents:         config.NetworkAgents,
 			}
 			manager.networkSource = sources.NewNetworkMetricsCollector(kubeClient, k8sClient, networkConfig)
 			logger.Info("Network metrics collector enabled")
@@ -117,18 +269,119 @@ func NewManager(restConfig *rest.Config, config ManagerConfig) (*Manager, error)
 		}
 	}
 
+	// 初始化节点间时延采集器（由各节点的探测Agent互相ping）
+	if config.NodeLatency != nil {
+		if k8sClient, ok := config.K8sClient.(*k8s.Client); ok {
+			manager.nodeLatencySource = sources.NewNodeLatencyCollector(k8sClient, *config.NodeLatency)
+			logger.Info("Node latency collector enabled")
+		} else {
+			logger.Warn("Node latency enabled but K8s client not available")
+		}
+	}
+
+	// 初始化外部目标探测采集器（由各节点的探测Agent访问集群外的URL）
+	if config.ExternalTargets != nil {
+		if k8sClient, ok := config.K8sClient.(*k8s.Client); ok {
+			manager.externalSource = sources.NewExternalTargetsCollector(k8sClient, *config.ExternalTargets)
+			logger.Infof("External target checks enabled (%d targets)", len(config.ExternalTargets.Targets))
+		} else {
+			logger.Warn("External targets configured but K8s client not available")
+		}
+	}
+
 	// 初始化UAV指标采集器
 	if config.EnableUAV {
 		uavConfig := sources.UAVCollectorConfig{
-			Namespace: config.Namespaces[0], // 使用第一个namespace
-			UAVLabel:  "app=uav-agent",
-			Timeout:   5 * time.Second,
+			Namespace:  config.Namespaces[0], // 使用第一个namespace
+			UAVLabel:   "app=uav-agent",
+			Timeout:    5 * time.Second,
+			HTTPClient: config.UAVHTTPClient,
 		}
 		manager.uavSource = sources.NewUAVMetricsCollector(kubeClient, uavConfig)
 		logger.Info("UAV metrics collector enabled")
 	}
 
-	// TODO: 自定义指标的初始化将在后续实现
+	// 初始化自定义指标采集器（读取CRD对象中的节点指标）
+	if config.EnableCustom {
+		dynamicClient, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		}
+		manager.customSource = sources.NewCustomMetricsCollector(dynamicClient, config.CustomResources)
+		logger.Infof("Custom metrics collector enabled (%d resources)", len(config.CustomResources))
+	}
+
+	// 初始化GPU指标采集器（DCGM Exporter）
+	if config.EnableGPU {
+		manager.gpuSource = sources.NewGPUMetricsCollector(kubeClient, config.GPU)
+		logger.Info("GPU metrics collector enabled")
+	}
+
+	// 初始化kubelet Summary API采集器（通过apiserver代理访问各节点）
+	if config.EnableSummary {
+		manager.summarySource = sources.NewSummaryMetricsCollector(kubeClient, sources.SummaryCollectorConfig{})
+		logger.Info("Kubelet summary collector enabled")
+	}
+
+	if config.Anomalies != nil {
+		manager.anomalies = NewAnomalyDetector(*config.Anomalies, config.Store)
+		logger.Info("Anomaly detection enabled")
+	}
+
+	if config.RemoteWrite != nil {
+		if manager.remoteWrite, err = remotewrite.New(*config.RemoteWrite); err != nil {
+			return nil, fmt.Errorf("failed to create remote write client: %w", err)
+		}
+		logger.Info("Remote write enabled")
+	}
+
+	for _, slo := range config.SLOs {
+		if !knownSeriesMetric(slo.Target, slo.Metric) {
+			logger.Warnf("SLO %q uses metric %q which is not collected for %s targets (supported: %s)",
+				slo.Name, slo.Metric, slo.Target, strings.Join(SeriesMetrics[slo.Target], ", "))
+		}
+	}
+
+	if config.UAVStaleness != nil {
+		manager.staleness = newUAVStaleness(*config.UAVStaleness)
+	}
+
+	if config.Store != nil && config.RollupInterval > 0 {
+		manager.rollups = newRollupTracker()
+		manager.rollupInterval = config.RollupInterval
+	}
+
+	// 按数据源调度采集，内存历史的容量按最短的采集间隔计算
+	var enabled []string
+	for _, c := range manager.collectors() {
+		if c.enabled {
+			enabled = append(enabled, c.name)
+		}
+	}
+	manager.scheduler = newCollectorScheduler(config.CollectInterval, config.Jitter, enabled, config.CollectorIntervals)
+	for name := range config.CollectorIntervals {
+		if _, _, ok := manager.scheduler.lookup(name); !ok {
+			logger.Warnf("Ignoring collection interval for %q: no such collector is enabled", name)
+		}
+	}
+	manager.history.setInterval(manager.scheduler.shortest())
+
+	// 各采集器共享权限跟踪器，ServiceAccount缺少权限时降级而不是反复报错
+	for _, source := range []interface{}{manager.nodeSource, manager.podSource, manager.networkSource, manager.uavSource, manager.customSource, manager.gpuSource, manager.summarySource} {
+		if setter, ok := source.(interface {
+			SetPermissionTracker(*sources.PermissionTracker)
+		}); ok {
+			setter.SetPermissionTracker(manager.permissions)
+		}
+	}
+
+	// 加载上次持久化的状态，重启后API可立即返回数据
+	if manager.persistInterval <= 0 {
+		manager.persistInterval = manager.interval
+	}
+	if err := manager.restoreState(context.Background()); err != nil {
+		logger.Warnf("Failed to restore persisted metrics state: %v", err)
+	}
 
 	return manager, nil
 }
@@ -145,14 +398,30 @@ func (m *Manager) Start(ctx context.Context) error {
 
 	m.logger.Infof("Starting metrics manager with interval: %v", m.interval)
 
+	if m.store != nil && m.persistInterval > 0 {
+		go m.persistLoop(ctx)
+	}
+	if m.shareState && m.syncInterval > 0 {
+		go m.sharedLoop(ctx)
+	}
+	if m.remoteWrite != nil {
+		go m.remoteWrite.Start(ctx)
+	}
+	if m.rollups != nil {
+		go m.rollupLoop(ctx)
+	}
+	if m.staleness != nil {
+		go m.stalenessLoop(ctx)
+	}
+
 	// 立即采集一次
-	if err := m.Collect(ctx); err != nil {
+	if err := m.collect(ctx, m.scheduler.due(time.Now())); err != nil {
 		m.logger.Errorf("Initial metrics collection failed: %v", err)
 	}
 
-	// 定期采集
-	ticker := time.NewTicker(m.interval)
-	defer ticker.Stop()
+	// 按各数据源的间隔定期采集，每轮只采集到期的数据源
+	timer := time.NewTimer(m.scheduler.untilNext(time.Now()))
+	defer timer.Stop()
 
 	for {
 		select {
@@ -170,14 +439,70 @@ func (m *Manager) Start(ctx context.Context) error {
 			m.runMutex.Unlock()
 			return nil
 
-		case <-ticker.C:
-			if err := m.Collect(ctx); err != nil {
+		case <-m.rescheduleCh:
+			timer.Reset(m.scheduler.untilNext(time.Now()))
+
+		case <-timer.C:
+			due := m.scheduler.due(time.Now())
+			if m.shareState && m.collectedElsewhere(ctx) {
+				m.logger.Debug("Skipping collection, another replica collected recently")
+			} else if err := m.collect(ctx, due); err != nil {
 				m.logger.Errorf("Failed to collect metrics: %v", err)
 			}
+			timer.Reset(m.scheduler.untilNext(time.Now()))
 		}
 	}
 }
 
+// SetInterval 调整采集间隔（配置热更新）
+func (m *Manager) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	m.runMutex.Lock()
+	m.interval = interval
+	m.runMutex.Unlock()
+	m.scheduler.setInterval(interval)
+	m.history.setInterval(m.scheduler.shortest())
+	m.reschedule()
+	m.logger.Infof("Metrics collection interval changed to %v", interval)
+}
+
+// SetCollectorIntervals 调整各数据源单独的采集间隔和抖动比例（配置热更新），
+// 没有配置的数据源改用全局间隔，未启用的数据源被忽略
+func (m *Manager) SetCollectorIntervals(intervals map[string]time.Duration, jitter float64) {
+	m.scheduler.setIntervals(intervals, jitter)
+	m.history.setInterval(m.scheduler.shortest())
+	m.reschedule()
+	m.logger.Infof("Collector intervals changed to %v (jitter %v)", intervals, jitter)
+}
+
+// reschedule 通知采集循环按新的调度重新计算等待时间
+func (m *Manager) reschedule() {
+	select {
+	case m.rescheduleCh <- struct{}{}:
+	default: // 已有尚未处理的通知
+	}
+}
+
+// SetNamespaces 更新Pod指标采集的命名空间（配置热更新）
+func (m *Manager) SetNamespaces(namespaces []string) {
+	if setter, ok := m.podSource.(interface{ SetNamespaces([]string) }); ok {
+		setter.SetNamespaces(namespaces)
+		m.logger.Infof("Pod metrics namespaces changed to %v", namespaces)
+	}
+}
+
+// SetPodFilter 更新Pod指标采集的过滤条件（配置热更新）
+func (m *Manager) SetPodFilter(filter sources.PodFilter) {
+	if setter, ok := m.podSource.(interface{ SetFilter(sources.PodFilter) }); ok {
+		setter.SetFilter(filter)
+		m.logger.Infof("Pod metrics filter changed (label selector %q, field selector %q, %d exclusions)",
+			filter.LabelSelector, filter.FieldSelector, len(filter.Exclude))
+	}
+}
+
 // Stop 停止采集
 func (m *Manager) Stop() error {
 	m.runMutex.Lock()
@@ -191,10 +516,23 @@ func (m *Manager) Stop() error {
 	return nil
 }
 
-// Collect 执行一次指标采集
+// Collect 执行一次指标采集（采集所有数据源）
 func (m *Manager) Collect(ctx context.Context) error {
+	return m.collect(ctx, nil)
+}
+
+// collect 采集 due 中的数据源（为nil时采集全部），其余数据源沿用上一次的结果
+func (m *Manager) collect(ctx context.Context, due map[string]bool) (err error) {
+	ctx, span := tracing.Start(ctx, "metrics.Collect")
+	defer func() { tracing.End(span, err) }()
+
+	m.collectMutex.Lock()
+	defer m.collectMutex.Unlock()
+
 	m.logger.Debug("Collecting metricstypes...")
 	startTime := time.Now()
+	run := func(name string) bool { return due == nil || due[name] }
+	previous := m.ViewSnapshot()
 
 	snapshot := &metricstypes.MetricsSnapshot{
 		Timestamp:      startTime,
@@ -205,16 +543,22 @@ func (m *Manager) Collect(ctx context.Context) error {
 	}
 
 	var wg sync.WaitGroup
-	var nodeErr, podErr, networkErr error
+	var nodeErr, podErr, networkErr, uavErr, customErr, gpuErr, summaryErr error
+
+	// 未到采集时间的节点、Pod和网络指标沿用上一次快照中的副本
+	m.carryForward(previous, snapshot, m.nodeSource != nil && !run(sources.CollectorNode),
+		m.podSource != nil && !run(sources.CollectorPod), m.networkSource != nil && !run(sources.CollectorNetwork))
 
 	// 并发采集各类指标
-	if m.nodeSource != nil {
+	if m.nodeSource != nil && run(sources.CollectorNode) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.node")
 			nodeMetrics, err := m.nodeSource.CollectNodeMetrics(ctx)
-			if err != nil {
-				nodeErr = err
+			tracing.End(span, err)
+			nodeErr = err
+			if err != nil && !sources.IsPartial(err) {
 				m.logger.Errorf("Failed to collect node metrics: %v", err)
 				return
 			}
@@ -222,13 +566,15 @@ func (m *Manager) Collect(ctx context.Context) error {
 		}()
 	}
 
-	if m.podSource != nil {
+	if m.podSource != nil && run(sources.CollectorPod) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.pod")
 			podMetrics, err := m.podSource.CollectPodMetrics(ctx)
-			if err != nil {
-				podErr = err
+			tracing.End(span, err)
+			podErr = err
+			if err != nil && !sources.IsPartial(err) {
 				m.logger.Errorf("Failed to collect pod metrics: %v", err)
 				return
 			}
@@ -237,13 +583,15 @@ func (m *Manager) Collect(ctx context.Context) error {
 	}
 
 	// 采集网络指标
-	if m.networkSource != nil {
+	if m.networkSource != nil && run(sources.CollectorNetwork) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.network")
 			networkMetrics, err := m.networkSource.CollectNetworkMetrics(ctx)
-			if err != nil {
-				networkErr = err
+			tracing.End(span, err)
+			networkErr = err
+			if err != nil && !sources.IsPartial(err) {
 				m.logger.Errorf("Failed to collect network metrics: %v", err)
 				return
 			}
@@ -252,80 +600,242 @@ func (m *Manager) Collect(ctx context.Context) error {
 	}
 
 	// 采集UAV指标
-	var uavMetrics map[string]interface{}
-	if m.uavSource != nil {
+	var uavMetrics map[string]*models.UAVMetricsEntry
+	if m.uavSource != nil && run(sources.CollectorUAV) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			rawMetrics, err := m.uavSource.CollectUAVMetrics(ctx)
-			if err != nil {
+			ctx, span := tracing.Start(ctx, "metrics.collect.uav")
+			states, err := m.uavSource.CollectUAVMetrics(ctx)
+			tracing.End(span, err)
+			uavErr = err
+			if err != nil && !sources.IsPartial(err) {
 				m.logger.Errorf("Failed to collect UAV metrics: %v", err)
 				return
 			}
 			now := time.Now().UTC()
-			metrics := make(map[string]interface{}, len(rawMetrics))
-			for nodeName, data := range rawMetrics {
-				metrics[nodeName] = map[string]interface{}{
-					"node_name":      nodeName,
-					"status":         "active",
-					"source":         "pull",
-					"timestamp":      now,
-					"last_heartbeat": now,
-					"state":          data,
+			metrics := make(map[string]*models.UAVMetricsEntry, len(states))
+			for ref, state := range states {
+				metrics[ref.Key()] = &models.UAVMetricsEntry{
+					NodeName:      ref.NodeName,
+					UAVID:         state.UAVID,
+					Index:         ref.Index,
+					Status:        "active",
+					Source:        "pull",
+					Timestamp:     now,
+					LastHeartbeat: now,
+					State:         state,
 				}
 			}
 			uavMetrics = metrics
 		}()
 	}
 
-	// TODO: 添加自定义指标采集
+	// 采集自定义指标（按节点合并到 NodeMetrics.CustomMetrics）
+	customMetrics, customRun := m.lastCustom, m.customSource != nil && run(sources.CollectorCustom)
+	if customRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.custom")
+			metrics, err := m.customSource.CollectCustomMetrics(ctx)
+			tracing.End(span, err)
+			customErr = err
+			if err != nil && !sources.IsPartial(err) {
+				m.logger.Errorf("Failed to collect custom metrics: %v", err)
+				return
+			}
+			customMetrics = metrics
+		}()
+	}
+
+	// 采集GPU使用率和显存（按节点合并到 NodeMetrics 的GPU字段）
+	gpuMetrics, gpuRun := m.lastGPU, m.gpuSource != nil && run(sources.CollectorGPU)
+	if gpuRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.gpu")
+			metrics, err := m.gpuSource.CollectGPUMetrics(ctx)
+			tracing.End(span, err)
+			gpuErr = err
+			if err != nil && !sources.IsPartial(err) {
+				m.logger.Errorf("Failed to collect GPU metrics: %v", err)
+				return
+			}
+			gpuMetrics = metrics
+		}()
+	}
+
+	// 采集kubelet Summary（实际磁盘用量、网卡流量和Pod临时存储）
+	summaries, summaryRun := m.lastSummaries, m.summarySource != nil && run(sources.CollectorSummary)
+	if summaryRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tracing.Start(ctx, "metrics.collect.summary")
+			metrics, err := m.summarySource.CollectSummaryMetrics(ctx)
+			tracing.End(span, err)
+			summaryErr = err
+			if err != nil && !sources.IsPartial(err) {
+				m.logger.Errorf("Failed to collect kubelet summary: %v", err)
+				return
+			}
+			summaries = metrics
+		}()
+	}
+
+	// 测量节点间时延矩阵（结果单独保存，不属于快照）
+	var latencyErr error
+	latencyRun := m.nodeLatencySource != nil && run(sources.CollectorNodeLatency)
+	if latencyRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latencyErr = m.collectNodeLatency(ctx)
+		}()
+	}
+
+	// 从各节点访问外部目标（结果单独保存，不属于快照）
+	var externalErr error
+	externalRun := m.externalSource != nil && run(sources.CollectorExternal)
+	if externalRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			externalErr = m.collectExternalTargets(ctx)
+		}()
+	}
 
 	wg.Wait()
 
+	// 记录各数据源的采集结果，失败的节点、Pod和网络指标沿用上一次快照中的数据
+	results := make(map[string]error)
+	for _, source := range []struct {
+		name string
+		run  bool
+		err  error
+	}{
+		{sources.CollectorNode, m.nodeSource != nil && run(sources.CollectorNode), nodeErr},
+		{sources.CollectorPod, m.podSource != nil && run(sources.CollectorPod), podErr},
+		{sources.CollectorNetwork, m.networkSource != nil && run(sources.CollectorNetwork), networkErr},
+		{sources.CollectorUAV, m.uavSource != nil && run(sources.CollectorUAV), uavErr},
+		{sources.CollectorCustom, customRun, customErr},
+		{sources.CollectorGPU, gpuRun, gpuErr},
+		{sources.CollectorSummary, summaryRun, summaryErr},
+		{sources.CollectorNodeLatency, latencyRun, latencyErr},
+		{sources.CollectorExternal, externalRun, externalErr},
+	} {
+		if source.run {
+			results[source.name] = source.err
+		}
+	}
+	failed := func(name string) bool {
+		err, ok := results[name]
+		return ok && err != nil && !sources.IsPartial(err)
+	}
+	collected := func(name string) bool {
+		_, ok := results[name]
+		return ok && !failed(name)
+	}
+	m.carryForward(previous, snapshot, failed(sources.CollectorNode), failed(sources.CollectorPod), failed(sources.CollectorNetwork))
+	snapshot.CollectionStatus = m.observeSources(startTime, results)
+	summaryRun = summaryRun && !failed(sources.CollectorSummary)
+
+	// 合并到节点的数据源按各自的间隔更新，每轮都合并最近一次的结果
+	if summaryRun {
+		deriveSummaryRates(m.lastSummaries, summaries)
+	}
+	m.lastCustom, m.lastGPU, m.lastSummaries = customMetrics, gpuMetrics, summaries
+	m.mergeCustomMetrics(snapshot, customMetrics)
+	m.mergeGPUMetrics(snapshot, gpuMetrics)
+	m.mergePodGPUMetrics(snapshot, gpuMetrics)
+	m.mergeSummaryMetrics(snapshot, summaries)
+
+	// 与上一次采集比较，计算变化速率并记录容器重启
+	deriveRates(previous, snapshot)
+	if collected(sources.CollectorPod) {
+		var previousPods map[string]*metricstypes.PodMetrics
+		if previous != nil {
+			previousPods = previous.PodMetrics
+		}
+		m.restarts.observe(previousPods, snapshot.PodMetrics, startTime)
+	}
+	if collected(sources.CollectorNetwork) {
+		m.links.observe(snapshot.NetworkMetrics, startTime)
+	}
+
+	// 本轮新采集的数据（沿用的结果不重复计入样本、SLO和异常检测）
+	fresh := &metricstypes.MetricsSnapshot{
+		Timestamp:      snapshot.Timestamp,
+		NodeMetrics:    make(map[string]*metricstypes.NodeMetrics),
+		PodMetrics:     make(map[string]*metricstypes.PodMetrics),
+		NetworkMetrics: []*metricstypes.NetworkMetrics{},
+		ClusterMetrics: snapshot.ClusterMetrics,
+	}
+	if collected(sources.CollectorNode) {
+		fresh.NodeMetrics = snapshot.NodeMetrics
+	}
+	if collected(sources.CollectorPod) {
+		fresh.PodMetrics = snapshot.PodMetrics
+	}
+	if collected(sources.CollectorNetwork) {
+		fresh.NetworkMetrics = snapshot.NetworkMetrics
+	}
+	m.observeSLOs(fresh, uavMetrics)
+
 	// 计算集群整体指标
 	m.calculateClusterMetrics(snapshot)
 
 	// 更新缓存
 	m.snapshotMutex.Lock()
 	m.snapshot = snapshot
-	if uavMetrics != nil {
-		m.uavSnapshot = uavMetrics
-		if m.uavLastHeartbeat == nil {
-			m.uavLastHeartbeat = make(map[string]time.Time)
-		}
-		for nodeName, raw := range uavMetrics {
-			if entry, ok := raw.(map[string]interface{}); ok {
-				switch ts := entry["last_heartbeat"].(type) {
-				case time.Time:
-					m.uavLastHeartbeat[nodeName] = ts
-				case string:
-					if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
-						m.uavLastHeartbeat[nodeName] = parsed
-					} else {
-						m.uavLastHeartbeat[nodeName] = time.Now()
-					}
-				default:
-					m.uavLastHeartbeat[nodeName] = time.Now()
-				}
-			} else {
-				m.uavLastHeartbeat[nodeName] = time.Now()
-			}
-		}
+	// 拉取结果按UAV标识覆盖（与Agent上报使用同一个标识），本轮没有返回的UAV保留，由心跳超时检测降级
+	for key, entry := range uavMetrics {
+		m.uavSnapshot[key] = entry
 	}
 	m.snapshotMutex.Unlock()
 
+	// 保存本轮新采集的指标样本用于历史聚合查询，并写入内存历史
+	m.recordSamples(ctx, fresh)
+	m.recordConditions(ctx, fresh.NodeMetrics, startTime)
+	m.pushRemoteWrite(snapshot.Timestamp)
+	m.streamSnapshot(previous, snapshot, fresh, collected(sources.CollectorNode), collected(sources.CollectorPod), collected(sources.CollectorNetwork), uavMetrics != nil)
+	m.recordHistory(snapshot, m.GetUAVMetrics())
+	if m.shareState {
+		m.publishSnapshot(ctx, snapshot)
+		for nodeName, entry := range uavMetrics {
+			m.publishUAV(nodeName, entry)
+		}
+	}
+
+	// 检测CPU、内存、RTT和UAV电量异常
+	if m.anomalies != nil {
+		m.anomalies.ObserveSnapshot(ctx, fresh, uavMetrics)
+	}
+
+	// 通知本轮新测得的网络指标
+	m.notifyNetworkMetrics(ctx, fresh.NetworkMetrics)
+	m.checkGeofences(ctx, uavMetrics)
+
 	duration := time.Since(startTime)
+	span.SetAttributes(
+		attribute.Int("metrics.nodes", len(snapshot.NodeMetrics)),
+		attribute.Int("metrics.pods", len(snapshot.PodMetrics)),
+		attribute.Int("metrics.network", len(snapshot.NetworkMetrics)),
+		attribute.Int("metrics.uavs", len(uavMetrics)),
+	)
 	m.logger.Infof("Metrics collection completed in %v (nodes: %d, pods: %d, network: %d, uavs: %d)",
 		duration, len(snapshot.NodeMetrics), len(snapshot.PodMetrics), len(snapshot.NetworkMetrics), len(uavMetrics))
 
-	// 如果有错误，返回第一个错误
-	if nodeErr != nil {
+	// 如果有错误，返回第一个错误（部分失败的结果仍然可用，只记录在采集状态中）
+	if failed(sources.CollectorNode) {
 		return nodeErr
 	}
-	if podErr != nil {
+	if failed(sources.CollectorPod) {
 		return podErr
 	}
-	if networkErr != nil {
+	if failed(sources.CollectorNetwork) {
 		// 网络指标错误只记录日志，不中断采集
 		m.logger.Warnf("Network metrics collection had errors: %v", networkErr)
 	}
@@ -333,8 +843,17 @@ func (m *Manager) Collect(ctx context.Context) error {
 	return nil
 }
 
-// GetLatestSnapshot 获取最新的指标快照
+// GetLatestSnapshot 获取最新指标快照的副本；
+// 新快照替换时调用方仍在读取（如序列化响应），因此不返回内部指针
 func (m *Manager) GetLatestSnapshot() *metricstypes.MetricsSnapshot {
+	m.snapshotMutex.RLock()
+	defer m.snapshotMutex.RUnlock()
+	return m.snapshot.Clone()
+}
+
+// ViewSnapshot 返回当前发布的快照本身（不拷贝）。快照发布后不再被修改，
+// 调用方只能读取；用于序列化大快照等热点路径，需要修改时使用 GetLatestSnapshot
+func (m *Manager) ViewSnapshot() *metricstypes.MetricsSnapshot {
 	m.snapshotMutex.RLock()
 	defer m.snapshotMutex.RUnlock()
 	return m.snapshot
@@ -346,7 +865,7 @@ func (m *Manager) GetNodeMetrics(nodeName string) (*metricstypes.NodeMetrics, er
 	defer m.snapshotMutex.RUnlock()
 
 	if metric, exists := m.snapshot.NodeMetrics[nodeName]; exists {
-		return metric, nil
+		return metric.Clone(), nil
 	}
 	return nil, fmt.Errorf("metrics not found for node: %s", nodeName)
 }
@@ -358,7 +877,7 @@ func (m *Manager) GetPodMetrics(namespace, podName string) (*metricstypes.PodMet
 
 	key := fmt.Sprintf("%s/%s", namespace, podName)
 	if metric, exists := m.snapshot.PodMetrics[key]; exists {
-		return metric, nil
+		return metric.Clone(), nil
 	}
 	return nil, fmt.Errorf("metrics not found for pod: %s/%s", namespace, podName)
 }
@@ -367,24 +886,57 @@ func (m *Manager) GetPodMetrics(namespace, podName string) (*metricstypes.PodMet
 func (m *Manager) GetClusterMetrics() *metricstypes.ClusterMetrics {
 	m.snapshotMutex.RLock()
 	defer m.snapshotMutex.RUnlock()
-	return m.snapshot.ClusterMetrics
+	return m.snapshot.ClusterMetrics.Clone()
 }
 
 // GetNetworkMetrics 获取网络指标
 func (m *Manager) GetNetworkMetrics() []*metricstypes.NetworkMetrics {
 	m.snapshotMutex.RLock()
 	defer m.snapshotMutex.RUnlock()
-	return m.snapshot.NetworkMetrics
+	if m.snapshot.NetworkMetrics == nil {
+		return nil
+	}
+	result := make([]*metricstypes.NetworkMetrics, len(m.snapshot.NetworkMetrics))
+	for i, metric := range m.snapshot.NetworkMetrics {
+		result[i] = metric.Clone()
+	}
+	return result
 }
 
-// TestPodCommunication 测试指定Pod对的网络连通性（按需测试）
-func (m *Manager) TestPodCommunication(ctx context.Context, sourcePod, targetPod string) (*metricstypes.NetworkMetrics, error) {
+// OnNetworkMetrics 设置每轮网络测试完成后的回调（如将各Pod对的结果导出为NetworkProbeResult CRD），
+// 在采集中同步调用，参数为本轮新测得结果的副本；沿用上一轮的结果不会通知
+func (m *Manager) OnNetworkMetrics(fn func(ctx context.Context, metrics []*metricstypes.NetworkMetrics)) {
+	m.snapshotMutex.Lock()
+	m.onNetworkMetrics = fn
+	m.snapshotMutex.Unlock()
+}
+
+// notifyNetworkMetrics 将本轮新测得的网络指标交给回调
+func (m *Manager) notifyNetworkMetrics(ctx context.Context, metrics []*metricstypes.NetworkMetrics) {
+	m.snapshotMutex.RLock()
+	fn := m.onNetworkMetrics
+	m.snapshotMutex.RUnlock()
+	if fn == nil || len(metrics) == 0 {
+		return
+	}
+	clones := make([]*metricstypes.NetworkMetrics, len(metrics))
+	for i, metric := range metrics {
+		clones[i] = metric.Clone()
+	}
+	fn(ctx, clones)
+}
+
+// ErrNetworkDisabled 未启用网络指标采集
+var ErrNetworkDisabled = errors.New("network metrics collector not enabled")
+
+// TestPodCommunication 测试指定Pod对的网络连通性（按需测试），opts 为零值时执行与自动采集相同的测试
+func (m *Manager) TestPodCommunication(ctx context.Context, sourcePod, targetPod string, opts sources.PodTestOptions) (*metricstypes.NetworkMetrics, error) {
 	if m.networkSource == nil {
-		return nil, fmt.Errorf("network metrics collector not enabled")
+		return nil, ErrNetworkDisabled
 	}
 
 	// 直接调用接口方法
-	return m.networkSource.TestPodConnectivity(ctx, sourcePod, targetPod)
+	return m.networkSource.TestPodConnectivity(ctx, sourcePod, targetPod, opts)
 }
 
 // UpdateUAVReport 接收来自Agent的UAV状态上报
@@ -393,100 +945,196 @@ func (m *Manager) UpdateUAVReport(report *models.UAVReport) {
 		return
 	}
 
-	reportTime := report.Timestamp
-	if reportTime.IsZero() {
-		reportTime = time.Now().UTC()
-	}
+	entry := models.NewUAVMetricsEntry(report)
+	key := report.Key()
 
-	status := report.Status
-	if status == "" {
-		status = "active"
-	}
-
-	source := report.Source
-	if source == "" {
-		source = "agent"
-	}
-
-	entry := map[string]interface{}{
-		"node_name":      report.NodeName,
-		"uav_id":         report.UAVID,
-		"status":         status,
-		"source":         source,
-		"timestamp":      reportTime,
-		"last_heartbeat": reportTime,
-	}
-
-	if report.NodeIP != "" {
-		entry["node_ip"] = report.NodeIP
-	}
-
-	if report.HeartbeatIntervalSeconds > 0 {
-		entry["heartbeat_interval_seconds"] = report.HeartbeatIntervalSeconds
+	m.snapshotMutex.Lock()
+	if m.uavSnapshot == nil {
+		m.uavSnapshot = make(map[string]*models.UAVMetricsEntry)
 	}
+	m.uavSnapshot[key] = entry
+	m.snapshotMutex.Unlock()
 
-	if len(report.Metadata) > 0 {
-		entry["metadata"] = report.Metadata
+	if m.shareState {
+		m.publishUAV(key, entry)
 	}
+	m.streamUAV(key)
+	m.checkGeofences(context.Background(), map[string]*models.UAVMetricsEntry{key: entry})
 
-	if report.State != nil {
-		stateCopy := *report.State
-		entry["state"] = stateCopy
+	if m.anomalies != nil {
+		m.anomalies.ObserveUAV(context.Background(), key, entry.State, entry.LastHeartbeat)
 	}
 
-	m.snapshotMutex.Lock()
-	if m.uavSnapshot == nil {
-		m.uavSnapshot = make(map[string]interface{})
-	}
-	m.uavSnapshot[report.NodeName] = entry
-	if m.uavLastHeartbeat == nil {
-		m.uavLastHeartbeat = make(map[string]time.Time)
-	}
-	m.uavLastHeartbeat[report.NodeName] = reportTime
-	m.snapshotMutex.Unlock()
+	m.logger.Debugf("UAV report ingested: node=%s uav=%s index=%d status=%s", report.NodeName, report.UAVID, report.Index, entry.Status)
+}
 
-	m.logger.Debugf("UAV report ingested: node=%s uav=%s status=%s", report.NodeName, report.UAVID, status)
+// Anomalies 返回异常检测器，未启用时为空
+func (m *Manager) Anomalies() *AnomalyDetector {
+	return m.anomalies
 }
 
 // GetUAVMetrics 获取所有UAV指标
-func (m *Manager) GetUAVMetrics() map[string]interface{} {
+func (m *Manager) GetUAVMetrics() map[string]*models.UAVMetricsEntry {
 	m.snapshotMutex.RLock()
 	defer m.snapshotMutex.RUnlock()
 
-	if len(m.uavSnapshot) == 0 {
-		return map[string]interface{}{}
-	}
-
-	result := make(map[string]interface{}, len(m.uavSnapshot))
-	for key, value := range m.uavSnapshot {
-		result[key] = value
+	// 每个条目单独拷贝，调用方修改返回值不会影响内部状态
+	result := make(map[string]*models.UAVMetricsEntry, len(m.uavSnapshot))
+	for key, entry := range m.uavSnapshot {
+		result[key] = entry.Clone()
 	}
 	return result
 }
 
-// GetSingleUAVMetrics 获取指定节点的UAV指标
-func (m *Manager) GetSingleUAVMetrics(nodeName string) (interface{}, bool) {
+// GetSingleUAVMetrics 获取指定UAV的指标，key为节点名（同一个Agent模拟的其他UAV见 models.UAVKey）
+func (m *Manager) GetSingleUAVMetrics(key string) (*models.UAVMetricsEntry, bool) {
 	m.snapshotMutex.RLock()
 	defer m.snapshotMutex.RUnlock()
 
-	if m.uavSnapshot == nil {
+	entry, exists := m.uavSnapshot[key]
+	if !exists {
 		return nil, false
 	}
+	return entry.Clone(), true
+}
 
-	metric, exists := m.uavSnapshot[nodeName]
-	if !exists {
-		return nil, false
+// ErrUAVCommandsUnavailable 未启用UAV采集，无法向UAV发送命令
+var ErrUAVCommandsUnavailable = errors.New("UAV collection is not enabled")
+
+// SendUAVCommand 通过节点上的UAV Agent向UAV发送控制命令，返回Agent的响应消息。
+// key为节点名或同一个Agent模拟的其他UAV的标识（见 models.UAVKey）
+func (m *Manager) SendUAVCommand(ctx context.Context, key, command string, payload interface{}) (string, error) {
+	if m.uavSource == nil {
+		return "", ErrUAVCommandsUnavailable
 	}
+	nodeName, index := models.ParseUAVKey(key)
+	return m.uavSource.SendCommandToUAV(ctx, nodeName, index, command, payload)
+}
 
-	if entry, ok := metric.(map[string]interface{}); ok {
-		clone := make(map[string]interface{}, len(entry))
-		for key, value := range entry {
-			clone[key] = value
+// carryForward 将上一次快照中未到采集时间（或采集失败）的节点、Pod和网络指标拷贝到新快照
+// （之后的合并会修改节点指标，已发布的快照不能直接复用）
+func (m *Manager) carryForward(previous, snapshot *metricstypes.MetricsSnapshot, nodes, pods, network bool) {
+	if previous == nil {
+		return
+	}
+	if nodes {
+		for name, node := range previous.NodeMetrics {
+			snapshot.NodeMetrics[name] = node.Clone()
+		}
+	}
+	if pods {
+		for key, pod := range previous.PodMetrics {
+			snapshot.PodMetrics[key] = pod.Clone()
 		}
-		return clone, true
 	}
+	if network {
+		for _, test := range previous.NetworkMetrics {
+			snapshot.NetworkMetrics = append(snapshot.NetworkMetrics, test.Clone())
+		}
+	}
+}
 
-	return metric, true
+// mergeCustomMetrics 将自定义指标合并到对应节点，没有节点指标的节点被忽略
+func (m *Manager) mergeCustomMetrics(snapshot *metricstypes.MetricsSnapshot, custom map[string]interface{}) {
+	for nodeName, raw := range custom {
+		values, ok := raw.(map[string]interface{})
+		node := snapshot.NodeMetrics[nodeName]
+		if !ok || node == nil {
+			m.logger.Debugf("Ignoring custom metrics for unknown node %s", nodeName)
+			continue
+		}
+		if node.CustomMetrics == nil {
+			node.CustomMetrics = make(map[string]interface{}, len(values))
+		}
+		for name, value := range values {
+			node.CustomMetrics[name] = value
+		}
+	}
+}
+
+// mergeGPUMetrics 用DCGM Exporter的数据填充节点的GPU字段。GPU数量取节点容量和Exporter报告数量的较大值，
+// Exporter没有报告型号时保留节点标签中的型号
+func (m *Manager) mergeGPUMetrics(snapshot *metricstypes.MetricsSnapshot, gpus map[string][]metricstypes.GPUDevice) {
+	for nodeName, devices := range gpus {
+		node := snapshot.NodeMetrics[nodeName]
+		if node == nil {
+			m.logger.Debugf("Ignoring GPU metrics for unknown node %s", nodeName)
+			continue
+		}
+		if len(devices) > node.GPUCount {
+			node.GPUCount = len(devices)
+		}
+		models := make([]string, len(devices))
+		usage := make([]float64, len(devices))
+		memoryTotal := make([]int64, len(devices))
+		memoryUsed := make([]int64, len(devices))
+		for i, device := range devices {
+			models[i] = device.Model
+			if models[i] == "" && i < len(node.GPUModels) {
+				models[i] = node.GPUModels[i]
+			}
+			usage[i] = device.Utilization
+			memoryTotal[i] = device.MemoryTotal
+			if memoryTotal[i] == 0 && i < len(node.GPUMemoryTotal) {
+				memoryTotal[i] = node.GPUMemoryTotal[i]
+			}
+			memoryUsed[i] = device.MemoryUsed
+		}
+		node.GPUModels, node.GPUUsage, node.GPUMemoryTotal, node.GPUMemoryUsed = models, usage, memoryTotal, memoryUsed
+	}
+}
+
+// mergePodGPUMetrics 按DCGM Exporter的pod标签把GPU使用率和显存归属到Pod，多个Pod共享的GPU按Pod数均分。
+// 沿用上一次快照的Pod先清除旧的归属结果，GPU数据每轮都使用最近一次的结果重新计算
+func (m *Manager) mergePodGPUMetrics(snapshot *metricstypes.MetricsSnapshot, gpus map[string][]metricstypes.GPUDevice) {
+	for _, pod := range snapshot.PodMetrics {
+		pod.GPUDevices, pod.GPUUsage, pod.GPUUsageRate, pod.GPUMemoryUsed = nil, 0, 0, 0
+	}
+	for nodeName, devices := range gpus {
+		for _, device := range devices {
+			for _, key := range device.Pods {
+				pod := snapshot.PodMetrics[key]
+				if pod == nil || pod.NodeName != nodeName {
+					continue
+				}
+				shared := float64(len(device.Pods))
+				pod.GPUDevices = append(pod.GPUDevices, device.Index)
+				pod.GPUUsage += device.Utilization / shared
+				pod.GPUMemoryUsed += int64(float64(device.MemoryUsed) / shared)
+			}
+		}
+	}
+	for _, pod := range snapshot.PodMetrics {
+		allocated := pod.GPURequest
+		if allocated == 0 {
+			allocated = int64(len(pod.GPUDevices))
+		}
+		if allocated > 0 {
+			pod.GPUUsageRate = pod.GPUUsage / float64(allocated)
+		}
+	}
+}
+
+// mergeSummaryMetrics 用kubelet Summary中的实际用量替换节点磁盘的估算值，并补充网卡流量和Pod临时存储用量
+func (m *Manager) mergeSummaryMetrics(snapshot *metricstypes.MetricsSnapshot, summaries map[string]*metricstypes.KubeletSummary) {
+	for nodeName, summary := range summaries {
+		if node := snapshot.NodeMetrics[nodeName]; node != nil {
+			if summary.FSCapacity > 0 {
+				node.DiskCapacity = summary.FSCapacity
+				node.DiskUsage = summary.FSUsed
+				node.DiskUsageRate = float64(summary.FSUsed) / float64(summary.FSCapacity) * 100.0
+			}
+			node.NetworkRxBytes = summary.NetworkRxBytes
+			node.NetworkTxBytes = summary.NetworkTxBytes
+			node.NetworkRxRate = summary.NetworkRxRate
+			node.NetworkTxRate = summary.NetworkTxRate
+		}
+		for key, used := range summary.PodEphemeralStorage {
+			if pod := snapshot.PodMetrics[key]; pod != nil {
+				pod.EphemeralStorageUsage = used
+			}
+		}
+	}
 }
 
 // calculateClusterMetrics 计算集群整体指标
@@ -553,6 +1201,9 @@ func (m *Manager) calculateClusterMetrics(snapshot *metricstypes.MetricsSnapshot
 	if cluster.MemoryUsageRate > 80 {
 		cluster.Issues = append(cluster.Issues, fmt.Sprintf("High memory usage: %.1f%%", cluster.MemoryUsageRate))
 	}
+	cluster.Issues = append(cluster.Issues, m.restarts.issues(snapshot.Timestamp)...)
+	cluster.Issues = append(cluster.Issues, m.links.issues(snapshot.Timestamp)...)
+	cluster.Issues = append(cluster.Issues, m.slos.issues(snapshot.Timestamp)...)
 
 	// 设置健康状态
 	if len(cluster.Issues) == 0 {
@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"k8s.io/client-go/rest"
+)
+
+// fakeNodeSource 每次采集返回新的节点指标
+type fakeNodeSource struct{}
+
+func (fakeNodeSource) CollectNodeMetrics(ctx context.Context) (map[string]*metricstypes.NodeMetrics, error) {
+	return map[string]*metricstypes.NodeMetrics{
+		"node-1": {
+			NodeName:    "node-1",
+			Timestamp:   time.Now(),
+			CPUCapacity: 4000,
+			CPUUsage:    1000,
+			Healthy:     true,
+			GPUCount:    1,
+			GPUModels:   []string{"A100"},
+			GPUUsage:    []float64{50},
+			Conditions:  []string{},
+			Labels:      map[string]string{"zone": "a"},
+		},
+	}, nil
+}
+
+func (s fakeNodeSource) CollectSingleNodeMetrics(ctx context.Context, nodeName string) (*metricstypes.NodeMetrics, error) {
+	nodes, _ := s.CollectNodeMetrics(ctx)
+	return nodes[nodeName], nil
+}
+
+// fakePodSource 每次采集返回新的Pod指标
+type fakePodSource struct{}
+
+func (fakePodSource) CollectPodMetrics(ctx context.Context) (map[string]*metricstypes.PodMetrics, error) {
+	return map[string]*metricstypes.PodMetrics{
+		"default/web-1": {
+			PodName:    "web-1",
+			Namespace:  "default",
+			NodeName:   "node-1",
+			Timestamp:  time.Now(),
+			CPUUsage:   200,
+			Phase:      "Running",
+			Ready:      true,
+			Containers: []metricstypes.ContainerMetrics{{Name: "web", CPUUsage: 200}},
+		},
+	}, nil
+}
+
+func (s fakePodSource) CollectNamespacePodMetrics(ctx context.Context, namespace string) (map[string]*metricstypes.PodMetrics, error) {
+	return s.CollectPodMetrics(ctx)
+}
+
+// newTestManager 创建使用假数据源的管理器（不访问API Server）
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager, err := NewManager(&rest.Config{Host: "http://127.0.0.1:1"}, ManagerConfig{CollectInterval: time.Second})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	manager.nodeSource = fakeNodeSource{}
+	manager.podSource = fakePodSource{}
+	return manager
+}
+
+func testUAVReport(index int) *models.UAVReport {
+	state := uav.NewMAVLinkSimulator(fmt.Sprintf("uav-%d", index), "node-1").GetState()
+	return &models.UAVReport{
+		NodeName:  "node-1",
+		UAVID:     state.UAVID,
+		Index:     index,
+		Status:    "active",
+		Timestamp: time.Now(),
+		State:     &state,
+		Metadata:  map[string]string{"agent": "test"},
+	}
+}
+
+// 采集、UAV上报与读取并发进行，调用方修改读取到的副本；需要以 -race 运行才能发现数据竞争
+func TestManagerConcurrentReadsReturnCopies(t *testing.T) {
+	manager := newTestManager(t)
+	ctx := context.Background()
+	if err := manager.Collect(ctx); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	manager.UpdateUAVReport(testUAVReport(0))
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			if err := manager.Collect(ctx); err != nil {
+				t.Errorf("Collect() error = %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			manager.UpdateUAVReport(testUAVReport(i % 3))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			snapshot := manager.GetLatestSnapshot()
+			for _, node := range snapshot.NodeMetrics {
+				node.Labels["zone"] = "mutated"
+				node.GPUModels[0] = "mutated"
+				node.Conditions = append(node.Conditions, "Mutated")
+			}
+			for key, pod := range snapshot.PodMetrics {
+				pod.Containers[0].CPUUsage = -1
+				pod.Phase = "Mutated"
+				delete(snapshot.PodMetrics, key)
+			}
+			snapshot.ClusterMetrics.Issues = append(snapshot.ClusterMetrics.Issues, "mutated")
+			snapshot.ClusterMetrics.TotalNodes = -1
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			for key, entry := range manager.GetUAVMetrics() {
+				entry.Status = "mutated"
+				entry.Metadata["agent"] = "mutated"
+				entry.State.Battery.RemainingPercent = -1
+				entry.State.Health.SensorsHealth["gps"] = false
+				entry.State.PreArm.Checks[0].Passed = false
+				if single, ok := manager.GetSingleUAVMetrics(key); ok {
+					single.State.Health.Messages = append(single.State.Health.Messages, "mutated")
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	snapshot := manager.GetLatestSnapshot()
+	node := snapshot.NodeMetrics["node-1"]
+	if node == nil || node.Labels["zone"] != "a" || node.GPUModels[0] != "A100" || len(node.Conditions) != 0 {
+		t.Errorf("node metrics were modified through a copy: %+v", node)
+	}
+	pod := snapshot.PodMetrics["default/web-1"]
+	if pod == nil || pod.Phase != "Running" || pod.Containers[0].CPUUsage != 200 {
+		t.Errorf("pod metrics were modified through a copy: %+v", pod)
+	}
+	if snapshot.ClusterMetrics.TotalNodes != 1 {
+		t.Errorf("cluster metrics were modified through a copy: total nodes = %d", snapshot.ClusterMetrics.TotalNodes)
+	}
+	for _, issue := range snapshot.ClusterMetrics.Issues {
+		if issue == "mutated" {
+			t.Errorf("cluster issues were modified through a copy: %v", snapshot.ClusterMetrics.Issues)
+		}
+	}
+
+	entries := manager.GetUAVMetrics()
+	if len(entries) != 3 {
+		t.Fatalf("got %d UAV entries, want 3", len(entries))
+	}
+	for key, entry := range entries {
+		if entry.Status != "active" || entry.Metadata["agent"] != "test" || entry.State.Battery.RemainingPercent < 0 ||
+			!entry.State.Health.SensorsHealth["gps"] || !entry.State.PreArm.Checks[0].Passed {
+			t.Errorf("UAV %s was modified through a copy: %+v", key, entry)
+		}
+		for _, message := range entry.State.Health.Messages {
+			if message == "mutated" {
+				t.Errorf("UAV %s messages were modified through a copy", key)
+			}
+		}
+	}
+}
+
+// fakeUAVSource 主动拉取返回的UAV，记录发送的命令
+type fakeUAVSource struct {
+	states   map[models.UAVRef]*uav.UAVState
+	commands []models.UAVRef
+}
+
+func (s *fakeUAVSource) CollectUAVMetrics(ctx context.Context) (map[models.UAVRef]*uav.UAVState, error) {
+	return s.states, nil
+}
+
+func (s *fakeUAVSource) CollectSingleUAVMetrics(ctx context.Context, nodeName string) (*uav.UAVState, error) {
+	return s.states[models.UAVRef{NodeName: nodeName}], nil
+}
+
+func (s *fakeUAVSource) SendCommandToUAV(ctx context.Context, nodeName string, index int, command string, payload interface{}) (string, error) {
+	s.commands = append(s.commands, models.UAVRef{NodeName: nodeName, Index: index})
+	return "ok", nil
+}
+
+// 拉取和上报使用同一个UAV标识：同一Agent的多架UAV都保留，节点名像其他UAV标识时也不会互相覆盖
+func TestManagerUAVKeys(t *testing.T) {
+	manager := newTestManager(t)
+	pulled := func(node string, index int) *uav.UAVState {
+		state := uav.NewMAVLinkSimulator(fmt.Sprintf("pull-%s-%d", node, index), node).GetState()
+		return &state
+	}
+	source := &fakeUAVSource{states: map[models.UAVRef]*uav.UAVState{
+		{NodeName: "x", Index: 0}:      pulled("x", 0),
+		{NodeName: "x", Index: 1}:      pulled("x", 1),
+		{NodeName: "x-uav1", Index: 0}: pulled("x-uav1", 0),
+	}}
+	manager.uavSource = source
+	if err := manager.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	uavs := manager.GetUAVMetrics()
+	if len(uavs) != 3 {
+		t.Fatalf("GetUAVMetrics() returned %d UAVs, want 3: %v", len(uavs), uavs)
+	}
+	for ref, state := range source.states {
+		entry, ok := uavs[ref.Key()]
+		if !ok || entry.UAVID != state.UAVID || entry.NodeName != ref.NodeName || entry.Index != ref.Index {
+			t.Errorf("uavs[%q] = %+v, want %s (node %s, index %d)", ref.Key(), entry, state.UAVID, ref.NodeName, ref.Index)
+		}
+	}
+
+	// 上报覆盖同一架UAV的拉取结果
+	report := testUAVReport(1)
+	report.NodeName = "x"
+	manager.UpdateUAVReport(report)
+	if entry, _ := manager.GetSingleUAVMetrics("x:1"); entry == nil || entry.UAVID != report.UAVID {
+		t.Errorf("GetSingleUAVMetrics(x:1) = %+v, want the reported UAV %s", entry, report.UAVID)
+	}
+	if entry, _ := manager.GetSingleUAVMetrics("x-uav1"); entry == nil || entry.UAVID != "pull-x-uav1-0" {
+		t.Errorf("GetSingleUAVMetrics(x-uav1) = %+v, want the pulled UAV of node x-uav1", entry)
+	}
+
+	// 未收到过上报的标识同样按节点名和序号发送命令
+	for _, key := range []string{"x:1", "x-uav1", "y:3"} {
+		manager.SendUAVCommand(context.Background(), key, "arm", nil)
+	}
+	want := []models.UAVRef{{NodeName: "x", Index: 1}, {NodeName: "x-uav1"}, {NodeName: "y", Index: 3}}
+	if fmt.Sprint(source.commands) != fmt.Sprint(want) {
+		t.Errorf("commands sent to %v, want %v", source.commands, want)
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// collectNodeLatency 测量节点间时延并发布新的矩阵；部分失败时仍发布已测得的结果
+func (m *Manager) collectNodeLatency(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "metrics.collect.node_latency")
+	matrix, err := m.nodeLatencySource.CollectNodeLatency(ctx)
+	tracing.End(span, err)
+	if err != nil && !sources.IsPartial(err) {
+		m.logger.Errorf("Failed to measure node latency: %v", err)
+		return err
+	}
+
+	m.snapshotMutex.Lock()
+	m.nodeLatency = matrix
+	m.snapshotMutex.Unlock()
+	return err
+}
+
+// GetNodeLatency 返回最近一次测得的节点间时延矩阵，未启用或尚未测量时为nil。
+// 矩阵发布后不再修改，调用方只能读取（调度器按时延选择节点时使用）
+func (m *Manager) GetNodeLatency() *metricstypes.NodeLatencyMatrix {
+	m.snapshotMutex.RLock()
+	defer m.snapshotMutex.RUnlock()
+	return m.nodeLatency
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+const (
+	stateBucket = "state"
+	stateKey    = "latest"
+)
+
+// persistedState 持久化的管理器状态
+type persistedState struct {
+	SavedAt     time.Time                          `json:"saved_at"`
+	Snapshot    *metricstypes.MetricsSnapshot      `json:"snapshot"`
+	UAVSnapshot map[string]*models.UAVMetricsEntry `json:"uav_snapshot"`
+	SLOs        map[string][]sloBucket             `json:"slos,omitempty"` // 各SLO的达标计数，重启后继续累计
+}
+
+// PersistState 将最新快照和UAV状态写入存储
+func (m *Manager) PersistState(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	m.snapshotMutex.RLock()
+	state := persistedState{
+		SavedAt:     time.Now().UTC(),
+		Snapshot:    m.snapshot,
+		UAVSnapshot: m.uavSnapshot,
+		SLOs:        m.slos.export(),
+	}
+	data, err := json.Marshal(state)
+	m.snapshotMutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal manager state: %w", err)
+	}
+
+	if err := m.store.Put(ctx, stateBucket, stateKey, data); err != nil {
+		return fmt.Errorf("failed to persist manager state: %w", err)
+	}
+
+	m.logger.Debugf("Manager state persisted (%d bytes)", len(data))
+	return nil
+}
+
+// restoreState 启动时从存储加载上次保存的快照和UAV状态
+func (m *Manager) restoreState(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	data, err := m.store.Get(ctx, stateBucket, stateKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load manager state: %w", err)
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode manager state: %w", err)
+	}
+
+	m.slos.restore(state.SLOs, time.Now())
+
+	m.snapshotMutex.Lock()
+	defer m.snapshotMutex.Unlock()
+
+	if state.Snapshot != nil {
+		normalizeSnapshot(state.Snapshot)
+		m.snapshot = state.Snapshot
+	}
+
+	for nodeName, entry := range state.UAVSnapshot {
+		if entry != nil {
+			m.uavSnapshot[nodeName] = entry
+		}
+	}
+
+	m.logger.Infof("Restored manager state saved at %s (nodes: %d, pods: %d, uavs: %d)",
+		state.SavedAt.Format(time.RFC3339), len(m.snapshot.NodeMetrics), len(m.snapshot.PodMetrics), len(state.UAVSnapshot))
+	return nil
+}
+
+// persistLoop 定期持久化状态
+func (m *Manager) persistLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			if err := m.PersistState(ctx); err != nil {
+				m.logger.Warnf("Failed to persist metrics state: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+)
+
+// PrometheusContentType Prometheus文本格式（0.0.4）
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusPrefix 所有指标名的前缀
+const prometheusPrefix = "k8s_llm_monitor_"
+
+// promSample 一个样本：标签按 名称、值 成对排列
+type promSample struct {
+	labels []string
+	value  float64
+}
+
+// promWriter 按指标族输出Prometheus文本格式，没有样本的指标族不输出
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+
+	// 不为nil时不输出文本，而是将每个样本（带前缀的指标名）交给 sample（remote_write）
+	sample func(name string, labels []string, value float64)
+}
+
+// family 输出一个指标族，collect 通过 add 添加样本
+func (p *promWriter) family(name, metricType, help string, collect func(add func(value float64, labels ...string))) {
+	var samples []promSample
+	collect(func(value float64, labels ...string) {
+		samples = append(samples, promSample{labels: labels, value: value})
+	})
+	if len(samples) == 0 || p.err != nil {
+		return
+	}
+
+	name = prometheusPrefix + name
+	if p.sample != nil {
+		for _, s := range samples {
+			p.sample(name, s.labels, s.value)
+		}
+		return
+	}
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	for _, s := range samples {
+		p.w.WriteString(name)
+		if len(s.labels) > 0 {
+			p.w.WriteByte('{')
+			for i := 0; i+1 < len(s.labels); i += 2 {
+				if i > 0 {
+					p.w.WriteByte(',')
+				}
+				fmt.Fprintf(p.w, `%s="%s"`, s.labels[i], escapeLabel(s.labels[i+1]))
+			}
+			p.w.WriteByte('}')
+		}
+		p.w.WriteByte(' ')
+		p.w.WriteString(formatValue(s.value))
+		_, p.err = p.w.WriteString("\n")
+	}
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(value string) string {
+	if !strings.ContainsAny(value, "\\\"\n") {
+		return value
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WritePrometheus 以Prometheus文本格式输出最新快照中的节点、Pod、Pod间网络、集群汇总和UAV指标。
+// 使用率为0-1的比例，CPU为核数，时延为秒
+func (m *Manager) WritePrometheus(w io.Writer) error {
+	p := &promWriter{w: bufio.NewWriter(w)}
+	m.writeFamilies(p)
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// writeFamilies 输出所有指标族
+func (m *Manager) writeFamilies(p *promWriter) {
+	snapshot := m.ViewSnapshot()
+	uavs := m.GetUAVMetrics()
+	status := m.Status()
+
+	p.family("collection_running", "gauge", "Whether periodic metrics collection is running.", func(add func(float64, ...string)) {
+		add(boolValue(status.Running))
+	})
+	p.family("collection_degraded", "gauge", "Whether a collector is skipping data because of missing RBAC permissions.", func(add func(float64, ...string)) {
+		for _, c := range status.Collectors {
+			if c.Enabled {
+				add(boolValue(c.Degraded), "collector", c.Name)
+			}
+		}
+	})
+	if rw := status.RemoteWrite; rw != nil {
+		p.family("remote_write_samples_sent_total", "counter", "Samples pushed to the remote_write endpoint.", func(add func(float64, ...string)) { add(float64(rw.Sent)) })
+		p.family("remote_write_samples_dropped_total", "counter", "Samples dropped because the queue was full or the endpoint rejected them.", func(add func(float64, ...string)) { add(float64(rw.Dropped)) })
+		p.family("remote_write_queued_samples", "gauge", "Samples waiting to be pushed to the remote_write endpoint.", func(add func(float64, ...string)) { add(float64(rw.Queued)) })
+		p.family("remote_write_failed_requests_total", "counter", "Failed remote_write requests, including ones retried later.", func(add func(float64, ...string)) { add(float64(rw.Failures)) })
+	}
+	if snapshot != nil && !snapshot.Timestamp.IsZero() {
+		p.family("snapshot_timestamp_seconds", "gauge", "Unix time of the latest metrics snapshot.", func(add func(float64, ...string)) {
+			add(unixSeconds(snapshot.Timestamp))
+		})
+		writeClusterMetrics(p, snapshot.ClusterMetrics)
+		writeNodeMetrics(p, snapshot.NodeMetrics)
+		writePodMetrics(p, snapshot.PodMetrics)
+		writeNetworkMetrics(p, snapshot.NetworkMetrics)
+	}
+	writeUAVMetrics(p, uavs)
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+func writeClusterMetrics(p *promWriter, cluster *metricstypes.ClusterMetrics) {
+	if cluster == nil {
+		return
+	}
+	gauges := []struct {
+		name, help string
+		value      float64
+	}{
+		{"cluster_nodes", "Number of nodes.", float64(cluster.TotalNodes)},
+		{"cluster_healthy_nodes", "Number of healthy nodes.", float64(cluster.HealthyNodes)},
+		{"cluster_pods", "Number of pods in the monitored namespaces.", float64(cluster.TotalPods)},
+		{"cluster_running_pods", "Number of running pods in the monitored namespaces.", float64(cluster.RunningPods)},
+		{"cluster_gpus", "Number of GPUs reported by nodes.", float64(cluster.TotalGPUs)},
+	}
+	for _, g := range gauges {
+		p.family(g.name, "gauge", g.help, func(add func(float64, ...string)) { add(g.value) })
+	}
+}
+
+func writeNodeMetrics(p *promWriter, nodes map[string]*metricstypes.NodeMetrics) {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	each := func(value func(n *metricstypes.NodeMetrics) (float64, bool)) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, name := range names {
+				if v, ok := value(nodes[name]); ok {
+					add(v, "node", name)
+				}
+			}
+		}
+	}
+	always := func(f func(n *metricstypes.NodeMetrics) float64) func(func(float64, ...string)) {
+		return each(func(n *metricstypes.NodeMetrics) (float64, bool) { return f(n), true })
+	}
+	positive := func(f func(n *metricstypes.NodeMetrics) float64) func(func(float64, ...string)) {
+		return each(func(n *metricstypes.NodeMetrics) (float64, bool) { v := f(n); return v, v > 0 })
+	}
+
+	p.family("node_healthy", "gauge", "Whether the node is healthy (Ready without pressure conditions).", always(func(n *metricstypes.NodeMetrics) float64 { return boolValue(n.Healthy) }))
+	p.family("node_cpu_capacity_cores", "gauge", "Allocatable CPU of the node in cores.", always(func(n *metricstypes.NodeMetrics) float64 { return float64(n.CPUCapacity) / 1000 }))
+	p.family("node_cpu_usage_cores", "gauge", "CPU used on the node in cores.", always(func(n *metricstypes.NodeMetrics) float64 { return float64(n.CPUUsage) / 1000 }))
+	p.family("node_cpu_usage_ratio", "gauge", "CPU usage of the node as a fraction of capacity.", always(func(n *metricstypes.NodeMetrics) float64 { return n.CPUUsageRate / 100 }))
+	p.family("node_memory_capacity_bytes", "gauge", "Allocatable memory of the node in bytes.", always(func(n *metricstypes.NodeMetrics) float64 { return float64(n.MemoryCapacity) }))
+	p.family("node_memory_usage_bytes", "gauge", "Memory used on the node in bytes.", always(func(n *metricstypes.NodeMetrics) float64 { return float64(n.MemoryUsage) }))
+	p.family("node_memory_usage_ratio", "gauge", "Memory usage of the node as a fraction of capacity.", always(func(n *metricstypes.NodeMetrics) float64 { return n.MemoryUsageRate / 100 }))
+	p.family("node_disk_capacity_bytes", "gauge", "Root filesystem capacity of the node in bytes.", positive(func(n *metricstypes.NodeMetrics) float64 { return float64(n.DiskCapacity) }))
+	p.family("node_disk_usage_bytes", "gauge", "Root filesystem usage of the node in bytes.", each(func(n *metricstypes.NodeMetrics) (float64, bool) { return float64(n.DiskUsage), n.DiskCapacity > 0 }))
+	p.family("node_disk_usage_ratio", "gauge", "Root filesystem usage of the node as a fraction of capacity.", each(func(n *metricstypes.NodeMetrics) (float64, bool) { return n.DiskUsageRate / 100, n.DiskCapacity > 0 }))
+	p.family("node_network_receive_bytes_total", "counter", "Bytes received on the default interface of the node (kubelet summary).", positive(func(n *metricstypes.NodeMetrics) float64 { return float64(n.NetworkRxBytes) }))
+	p.family("node_network_transmit_bytes_total", "counter", "Bytes transmitted on the default interface of the node (kubelet summary).", positive(func(n *metricstypes.NodeMetrics) float64 { return float64(n.NetworkTxBytes) }))
+	p.family("node_network_latency_seconds", "gauge", "Average network latency measured for the node in seconds.", positive(func(n *metricstypes.NodeMetrics) float64 { return n.NetworkLatency / 1000 }))
+	p.family("node_gpus", "gauge", "Number of GPUs on the node.", positive(func(n *metricstypes.NodeMetrics) float64 { return float64(n.GPUCount) }))
+
+	p.family("node_condition", "gauge", "Abnormal node conditions such as MemoryPressure or DiskPressure (always 1).", func(add func(float64, ...string)) {
+		for _, name := range names {
+			for _, condition := range nodes[name].Conditions {
+				add(1, "node", name, "condition", condition)
+			}
+		}
+	})
+
+	p.family("node_custom", "gauge", "Numeric values read from custom metrics resources (metrics.custom_resources).", func(add func(float64, ...string)) {
+		for _, name := range names {
+			custom := nodes[name].CustomMetrics
+			metrics := make([]string, 0, len(custom))
+			for metric := range custom {
+				metrics = append(metrics, metric)
+			}
+			sort.Strings(metrics)
+			for _, metric := range metrics {
+				switch value := custom[metric].(type) {
+				case float64:
+					add(value, "node", name, "metric", metric)
+				case bool:
+					add(boolValue(value), "node", name, "metric", metric)
+				}
+			}
+		}
+	})
+
+	gpus := func(value func(n *metricstypes.NodeMetrics, i int) (float64, bool)) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, name := range names {
+				n := nodes[name]
+				for i := 0; i < n.GPUCount; i++ {
+					v, ok := value(n, i)
+					if !ok {
+						continue
+					}
+					model := ""
+					if i < len(n.GPUModels) {
+						model = n.GPUModels[i]
+					}
+					add(v, "node", name, "gpu", strconv.Itoa(i), "model", model)
+				}
+			}
+		}
+	}
+	p.family("node_gpu_utilization_ratio", "gauge", "GPU utilization as a fraction.", gpus(func(n *metricstypes.NodeMetrics, i int) (float64, bool) {
+		if i >= len(n.GPUUsage) {
+			return 0, false
+		}
+		return n.GPUUsage[i] / 100, true
+	}))
+	p.family("node_gpu_memory_total_bytes", "gauge", "GPU memory capacity in bytes.", gpus(func(n *metricstypes.NodeMetrics, i int) (float64, bool) {
+		if i >= len(n.GPUMemoryTotal) {
+			return 0, false
+		}
+		return float64(n.GPUMemoryTotal[i]) * 1024 * 1024, true
+	}))
+	p.family("node_gpu_memory_used_bytes", "gauge", "GPU memory in use in bytes.", gpus(func(n *metricstypes.NodeMetrics, i int) (float64, bool) {
+		if i >= len(n.GPUMemoryUsed) {
+			return 0, false
+		}
+		return float64(n.GPUMemoryUsed[i]) * 1024 * 1024, true
+	}))
+}
+
+func writePodMetrics(p *promWriter, pods map[string]*metricstypes.PodMetrics) {
+	keys := make([]string, 0, len(pods))
+	for key := range pods {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	each := func(value func(pod *metricstypes.PodMetrics) (float64, bool)) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, key := range keys {
+				pod := pods[key]
+				if v, ok := value(pod); ok {
+					add(v, "namespace", pod.Namespace, "pod", pod.PodName, "node", pod.NodeName)
+				}
+			}
+		}
+	}
+	always := func(f func(pod *metricstypes.PodMetrics) float64) func(func(float64, ...string)) {
+		return each(func(pod *metricstypes.PodMetrics) (float64, bool) { return f(pod), true })
+	}
+	positive := func(f func(pod *metricstypes.PodMetrics) float64) func(func(float64, ...string)) {
+		return each(func(pod *metricstypes.PodMetrics) (float64, bool) { v := f(pod); return v, v > 0 })
+	}
+
+	p.family("pod_ready", "gauge", "Whether all containers of the pod are ready.", always(func(pod *metricstypes.PodMetrics) float64 { return boolValue(pod.Ready) }))
+	p.family("pod_phase", "gauge", "Current phase of the pod (always 1).", func(add func(float64, ...string)) {
+		for _, key := range keys {
+			pod := pods[key]
+			add(1, "namespace", pod.Namespace, "pod", pod.PodName, "node", pod.NodeName, "phase", pod.Phase)
+		}
+	})
+	p.family("pod_restarts_total", "counter", "Container restarts of the pod.", always(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.Restarts) }))
+	p.family("pod_cpu_usage_cores", "gauge", "CPU used by the pod in cores.", always(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.CPUUsage) / 1000 }))
+	p.family("pod_cpu_request_cores", "gauge", "CPU requested by the pod in cores.", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.CPURequest) / 1000 }))
+	p.family("pod_cpu_limit_cores", "gauge", "CPU limit of the pod in cores.", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.CPULimit) / 1000 }))
+	p.family("pod_cpu_usage_ratio", "gauge", "CPU usage of the pod as a fraction of its limit (or request).", always(func(pod *metricstypes.PodMetrics) float64 { return pod.CPUUsageRate / 100 }))
+	p.family("pod_memory_usage_bytes", "gauge", "Memory used by the pod in bytes.", always(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.MemoryUsage) }))
+	p.family("pod_memory_request_bytes", "gauge", "Memory requested by the pod in bytes.", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.MemoryRequest) }))
+	p.family("pod_memory_limit_bytes", "gauge", "Memory limit of the pod in bytes.", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.MemoryLimit) }))
+	p.family("pod_memory_usage_ratio", "gauge", "Memory usage of the pod as a fraction of its limit.", always(func(pod *metricstypes.PodMetrics) float64 { return pod.MemoryUsageRate / 100 }))
+	p.family("pod_ephemeral_storage_usage_bytes", "gauge", "Ephemeral storage used by the pod in bytes (kubelet summary).", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.EphemeralStorageUsage) }))
+	gpuPods := func(f func(pod *metricstypes.PodMetrics) float64) func(func(float64, ...string)) {
+		return each(func(pod *metricstypes.PodMetrics) (float64, bool) {
+			return f(pod), pod.GPURequest > 0 || len(pod.GPUDevices) > 0
+		})
+	}
+	p.family("pod_gpu_request", "gauge", "GPUs allocated to the pod by the device plugin.", positive(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.GPURequest) }))
+	p.family("pod_gpu_usage", "gauge", "GPU used by the pod in fully utilized GPUs (shared GPUs split evenly).", gpuPods(func(pod *metricstypes.PodMetrics) float64 { return pod.GPUUsage / 100 }))
+	p.family("pod_gpu_usage_ratio", "gauge", "GPU usage of the pod as a fraction of its allocated GPUs.", gpuPods(func(pod *metricstypes.PodMetrics) float64 { return pod.GPUUsageRate / 100 }))
+	p.family("pod_gpu_memory_used_bytes", "gauge", "GPU memory used by the pod in bytes.", gpuPods(func(pod *metricstypes.PodMetrics) float64 { return float64(pod.GPUMemoryUsed) * 1024 * 1024 }))
+
+	containers := func(value func(c metricstypes.ContainerMetrics) float64) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, key := range keys {
+				pod := pods[key]
+				for _, c := range pod.Containers {
+					add(value(c), "namespace", pod.Namespace, "pod", pod.PodName, "container", c.Name)
+				}
+			}
+		}
+	}
+	p.family("container_cpu_usage_cores", "gauge", "CPU used by the container in cores.", containers(func(c metricstypes.ContainerMetrics) float64 { return float64(c.CPUUsage) / 1000 }))
+	p.family("container_memory_usage_bytes", "gauge", "Memory used by the container in bytes.", containers(func(c metricstypes.ContainerMetrics) float64 { return float64(c.MemoryUsage) }))
+}
+
+func writeNetworkMetrics(p *promWriter, tests []*metricstypes.NetworkMetrics) {
+	each := func(value func(n *metricstypes.NetworkMetrics) (float64, bool)) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, n := range tests {
+				if v, ok := value(n); ok {
+					add(v, "source", n.SourcePod, "target", n.TargetPod, "method", n.TestMethod)
+				}
+			}
+		}
+	}
+
+	p.family("network_connected", "gauge", "Whether the last test between the two pods succeeded.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) { return boolValue(n.Connected), true }))
+	p.family("network_rtt_seconds", "gauge", "Round-trip time between the two pods in seconds.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) { return n.RTT / 1000, n.Connected }))
+	p.family("network_packet_loss_ratio", "gauge", "Packet loss between the two pods as a fraction.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) { return n.PacketLoss / 100, true }))
+	p.family("network_jitter_seconds", "gauge", "Mean RTT difference between consecutive recent tests of the two pods in seconds.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) { return n.Jitter / 1000, n.Connected }))
+	p.family("network_link_quality_score", "gauge", "Composite link quality score (0-100) from RTT, packet loss and jitter.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) { return n.QualityScore, true }))
+	p.family("network_bandwidth_bytes_per_second", "gauge", "Measured bandwidth between the two pods in bytes per second.", each(func(n *metricstypes.NetworkMetrics) (float64, bool) {
+		return n.Bandwidth * 1e6 / 8, n.Bandwidth > 0
+	}))
+}
+
+// uavSample 一个UAV条目的状态
+type uavSample struct {
+	node, id string
+	lastSeen time.Time
+	state    *uav.UAVState
+}
+
+func writeUAVMetrics(p *promWriter, entries map[string]*models.UAVMetricsEntry) {
+	var samples []uavSample
+	for key, entry := range entries {
+		s := uavSample{node: entry.NodeName, id: entry.UAVID, lastSeen: entry.LastHeartbeat, state: entry.State}
+		if s.node == "" {
+			s.node = key
+		}
+		if s.id == "" && s.state != nil {
+			s.id = s.state.UAVID
+		}
+		samples = append(samples, s)
+	}
+	// 同一个Agent模拟多架UAV时节点相同，按 uav_id 区分
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].node != samples[j].node {
+			return samples[i].node < samples[j].node
+		}
+		return samples[i].id < samples[j].id
+	})
+
+	each := func(value func(s *uav.UAVState) float64) func(func(float64, ...string)) {
+		return func(add func(float64, ...string)) {
+			for _, s := range samples {
+				if s.state != nil {
+					add(value(s.state), "node", s.node, "uav_id", s.id)
+				}
+			}
+		}
+	}
+
+	p.family("uav_last_report_timestamp_seconds", "gauge", "Unix time of the last report from the UAV agent.", func(add func(float64, ...string)) {
+		for _, s := range samples {
+			if !s.lastSeen.IsZero() {
+				add(unixSeconds(s.lastSeen), "node", s.node, "uav_id", s.id)
+			}
+		}
+	})
+	p.family("uav_battery_remaining_ratio", "gauge", "Remaining battery charge as a fraction.", each(func(s *uav.UAVState) float64 { return s.Battery.RemainingPercent / 100 }))
+	p.family("uav_battery_voltage_volts", "gauge", "Battery voltage in volts.", each(func(s *uav.UAVState) float64 { return s.Battery.Voltage }))
+	p.family("uav_battery_current_amperes", "gauge", "Battery current in amperes.", each(func(s *uav.UAVState) float64 { return s.Battery.Current }))
+	p.family("uav_battery_temperature_celsius", "gauge", "Battery temperature in degrees Celsius.", each(func(s *uav.UAVState) float64 { return s.Battery.Temperature }))
+	p.family("uav_battery_time_remaining_seconds", "gauge", "Estimated remaining flight time in seconds.", each(func(s *uav.UAVState) float64 { return float64(s.Battery.TimeRemaining) }))
+	p.family("uav_gps_latitude_degrees", "gauge", "GPS latitude in degrees.", each(func(s *uav.UAVState) float64 { return s.GPS.Latitude }))
+	p.family("uav_gps_longitude_degrees", "gauge", "GPS longitude in degrees.", each(func(s *uav.UAVState) float64 { return s.GPS.Longitude }))
+	p.family("uav_gps_altitude_meters", "gauge", "Altitude above mean sea level in meters.", each(func(s *uav.UAVState) float64 { return s.GPS.Altitude }))
+	p.family("uav_gps_relative_altitude_meters", "gauge", "Altitude above the takeoff point in meters.", each(func(s *uav.UAVState) float64 { return s.GPS.RelativeAltitude }))
+	p.family("uav_gps_satellites", "gauge", "Number of visible GPS satellites.", each(func(s *uav.UAVState) float64 { return float64(s.GPS.SatelliteCount) }))
+	p.family("uav_gps_fix_type", "gauge", "GPS fix type (0 none, 2 2D, 3 3D).", each(func(s *uav.UAVState) float64 { return float64(s.GPS.FixType) }))
+	p.family("uav_gps_hdop", "gauge", "Horizontal dilution of precision.", each(func(s *uav.UAVState) float64 { return s.GPS.HDOP }))
+	p.family("uav_ground_speed_meters_per_second", "gauge", "Ground speed in meters per second.", each(func(s *uav.UAVState) float64 { return s.GPS.GroundSpeed }))
+	p.family("uav_armed", "gauge", "Whether the UAV is armed.", each(func(s *uav.UAVState) float64 { return boolValue(s.Flight.Armed) }))
+	p.family("uav_flight_mode", "gauge", "Current flight mode of the UAV (always 1).", func(add func(float64, ...string)) {
+		for _, s := range samples {
+			if s.state != nil && s.state.Flight.Mode != "" {
+				add(1, "node", s.node, "uav_id", s.id, "mode", s.state.Flight.Mode)
+			}
+		}
+	})
+	p.family("uav_health_errors", "gauge", "Error count reported by the UAV health monitor.", each(func(s *uav.UAVState) float64 { return float64(s.Health.ErrorCount) }))
+	p.family("uav_health_warnings", "gauge", "Warning count reported by the UAV health monitor.", each(func(s *uav.UAVState) float64 { return float64(s.Health.WarningCount) }))
+}
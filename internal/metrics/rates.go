@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// deriveRates 与上一次采集的快照比较，计算CPU使用量趋势和Pod重启速率，
+// 使用方无需自己对比快照。计数器变小（Pod重建、kubelet重启）时视为重置，以当前值作为增量
+func deriveRates(previous, current *metricstypes.MetricsSnapshot) {
+	if previous == nil {
+		return
+	}
+
+	for name, node := range current.NodeMetrics {
+		prev := previous.NodeMetrics[name]
+		if prev == nil {
+			continue
+		}
+		seconds := elapsedSeconds(prev.Timestamp, node.Timestamp, previous.Timestamp, current.Timestamp)
+		if seconds <= 0 {
+			continue
+		}
+		node.CPUUsageTrend = float64(node.CPUUsage-prev.CPUUsage) / 1000 / seconds
+	}
+
+	for key, pod := range current.PodMetrics {
+		prev := previous.PodMetrics[key]
+		if prev == nil {
+			continue
+		}
+		seconds := elapsedSeconds(prev.Timestamp, pod.Timestamp, previous.Timestamp, current.Timestamp)
+		if seconds <= 0 {
+			continue
+		}
+		pod.CPUUsageTrend = float64(pod.CPUUsage-prev.CPUUsage) / 1000 / seconds
+		pod.RestartRate = float64(counterDelta(int64(prev.Restarts), int64(pod.Restarts))) / seconds * 3600
+	}
+}
+
+// deriveSummaryRates 与各节点上一次的kubelet Summary比较，计算网卡流量速率
+// （Summary按自己的间隔采集，不能与快照中的节点指标比较）
+func deriveSummaryRates(previous, current map[string]*metricstypes.KubeletSummary) {
+	for name, summary := range current {
+		prev := previous[name]
+		if prev == nil {
+			continue
+		}
+		seconds := summary.Timestamp.Sub(prev.Timestamp).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		// 任一次没有读到流量计数时不计算，避免把累计值当作增量
+		if prev.NetworkRxBytes > 0 && summary.NetworkRxBytes > 0 {
+			summary.NetworkRxRate = float64(counterDelta(prev.NetworkRxBytes, summary.NetworkRxBytes)) / seconds
+		}
+		if prev.NetworkTxBytes > 0 && summary.NetworkTxBytes > 0 {
+			summary.NetworkTxRate = float64(counterDelta(prev.NetworkTxBytes, summary.NetworkTxBytes)) / seconds
+		}
+	}
+}
+
+// elapsedSeconds 两次采集之间的秒数，对象没有时间戳时使用快照时间
+func elapsedSeconds(prev, cur, prevSnapshot, curSnapshot time.Time) float64 {
+	if prev.IsZero() || cur.IsZero() {
+		prev, cur = prevSnapshot, curSnapshot
+	}
+	return cur.Sub(prev).Seconds()
+}
+
+// counterDelta 计数器的增量，计数器重置时返回当前值
+func counterDelta(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/remotewrite"
+)
+
+// pushRemoteWrite 将最新快照中的指标（与 /metrics 相同的指标名和标签）以采集时间加入remote_write发送队列
+func (m *Manager) pushRemoteWrite(at time.Time) {
+	if m.remoteWrite == nil {
+		return
+	}
+
+	var samples []remotewrite.Sample
+	m.writeFamilies(&promWriter{sample: func(name string, labels []string, value float64) {
+		sample := remotewrite.Sample{Value: value, Timestamp: at}
+		sample.Labels = append(make([]remotewrite.Label, 0, len(labels)/2+1), remotewrite.Label{Name: "__name__", Value: name})
+		for i := 0; i+1 < len(labels); i += 2 {
+			// 空值标签在Prometheus中等同于没有该标签
+			if labels[i+1] != "" {
+				sample.Labels = append(sample.Labels, remotewrite.Label{Name: labels[i], Value: labels[i+1]})
+			}
+		}
+		samples = append(samples, sample)
+	}})
+	m.remoteWrite.Enqueue(samples)
+}
@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// 重启跟踪参数
+const (
+	chronicRestartWindow    = time.Hour      // 统计近期重启次数的时间窗口
+	chronicRestartThreshold = 3              // 窗口内重启达到该次数视为频繁重启
+	restartHistoryRetention = 24 * time.Hour // 重启记录保留时长
+	maxRestartEvents        = 100            // 每个Pod最多保留的重启记录
+	reasonOOMKilled         = "OOMKilled"
+)
+
+// RestartEvent 一次检测到的容器重启
+type RestartEvent struct {
+	Timestamp  time.Time `json:"timestamp"` // 检测到重启的采集时间
+	Container  string    `json:"container"`
+	Restarts   int32     `json:"restarts"`              // 与上一次采集相比增加的重启次数
+	Reason     string    `json:"reason,omitempty"`      // 上一次终止的原因，例如 OOMKilled、Error
+	ExitCode   int32     `json:"exit_code,omitempty"`   // 上一次终止的退出码
+	FinishedAt time.Time `json:"finished_at,omitempty"` // 上一次终止的时间
+}
+
+// PodRestarts 一个Pod的重启历史
+type PodRestarts struct {
+	Namespace      string         `json:"namespace"`
+	Pod            string         `json:"pod"`
+	Node           string         `json:"node"`
+	TotalRestarts  int32          `json:"total_restarts"`  // 当前各容器的重启次数之和
+	RecentRestarts int            `json:"recent_restarts"` // 最近一小时内的重启次数
+	RecentOOMKills int            `json:"recent_oom_kills"`
+	OOMKills       int            `json:"oom_kills"` // 保留的记录中因OOMKilled重启的次数
+	Chronic        bool           `json:"chronic"`   // 最近一小时内重启达到3次
+	LastRestart    time.Time      `json:"last_restart"`
+	Events         []RestartEvent `json:"events"` // 按时间从新到旧
+}
+
+// restartTracker 比较相邻两次采集中各容器的重启次数，记录每个Pod的重启历史
+type restartTracker struct {
+	mu       sync.RWMutex
+	pods     map[string]*PodRestarts // key: namespace/pod-name
+	lastSeen map[string]time.Time
+}
+
+// newRestartTracker 创建重启跟踪器
+func newRestartTracker() *restartTracker {
+	return &restartTracker{
+		pods:     make(map[string]*PodRestarts),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// observe 比较本轮采集到的Pod与上一次快照，记录重启次数的增量。
+// 首次出现的Pod只在最近一次终止发生在统计窗口内时记录一次
+func (t *restartTracker) observe(previous, current map[string]*metricstypes.PodMetrics, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, pod := range current {
+		t.lastSeen[key] = now
+		prev := previous[key]
+
+		prevRestarts := make(map[string]int32)
+		if prev != nil {
+			for _, container := range prev.ContainerRestarts {
+				prevRestarts[container.Name] = container.Restarts
+			}
+		}
+
+		for _, container := range pod.ContainerRestarts {
+			delta := container.Restarts - prevRestarts[container.Name]
+			if prev == nil {
+				delta = 0
+				if container.Restarts > 0 && !container.FinishedAt.IsZero() && now.Sub(container.FinishedAt) < chronicRestartWindow {
+					delta = 1
+				}
+			}
+			if delta <= 0 {
+				continue
+			}
+			t.record(key, pod, RestartEvent{
+				Timestamp:  now,
+				Container:  container.Name,
+				Restarts:   delta,
+				Reason:     container.Reason,
+				ExitCode:   container.ExitCode,
+				FinishedAt: container.FinishedAt,
+			})
+		}
+		if history := t.pods[key]; history != nil {
+			history.Node, history.TotalRestarts = pod.NodeName, pod.Restarts
+		}
+	}
+
+	// 清理已消失且超过保留时长的Pod
+	for key, seen := range t.lastSeen {
+		if now.Sub(seen) > restartHistoryRetention {
+			delete(t.lastSeen, key)
+			delete(t.pods, key)
+		}
+	}
+}
+
+// record 追加一条重启记录（调用方持有锁）
+func (t *restartTracker) record(key string, pod *metricstypes.PodMetrics, event RestartEvent) {
+	history := t.pods[key]
+	if history == nil {
+		history = &PodRestarts{Namespace: pod.Namespace, Pod: pod.PodName}
+		t.pods[key] = history
+	}
+	history.Events = append([]RestartEvent{event}, history.Events...)
+	if len(history.Events) > maxRestartEvents {
+		history.Events = history.Events[:maxRestartEvents]
+	}
+	history.LastRestart = event.Timestamp
+}
+
+// list 返回重启历史（近期重启次数从多到少），chronicOnly 时只返回频繁重启的Pod
+func (t *restartTracker) list(namespace string, chronicOnly bool, now time.Time) []PodRestarts {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := []PodRestarts{}
+	for _, history := range t.pods {
+		if namespace != "" && history.Namespace != namespace {
+			continue
+		}
+		summary := summarizeRestarts(history, now)
+		if chronicOnly && !summary.Chronic {
+			continue
+		}
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RecentRestarts != result[j].RecentRestarts {
+			return result[i].RecentRestarts > result[j].RecentRestarts
+		}
+		return result[i].Namespace+"/"+result[i].Pod < result[j].Namespace+"/"+result[j].Pod
+	})
+	return result
+}
+
+// summarizeRestarts 复制重启历史并统计窗口内的重启和OOMKill次数（丢弃超过保留时长的记录）
+func summarizeRestarts(history *PodRestarts, now time.Time) PodRestarts {
+	summary := *history
+	summary.Events = make([]RestartEvent, 0, len(history.Events))
+	summary.RecentRestarts, summary.RecentOOMKills, summary.OOMKills = 0, 0, 0
+	for _, event := range history.Events {
+		age := now.Sub(event.Timestamp)
+		if age > restartHistoryRetention {
+			continue
+		}
+		summary.Events = append(summary.Events, event)
+		oom := event.Reason == reasonOOMKilled
+		if oom {
+			summary.OOMKills += int(event.Restarts)
+		}
+		if age <= chronicRestartWindow {
+			summary.RecentRestarts += int(event.Restarts)
+			if oom {
+				summary.RecentOOMKills += int(event.Restarts)
+			}
+		}
+	}
+	summary.Chronic = summary.RecentRestarts >= chronicRestartThreshold
+	return summary
+}
+
+// onNode 节点上的Pod在时间范围内的重启记录（按时间升序）
+func (t *restartTracker) onNode(node string, from, to time.Time) []NodePodRestart {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := []NodePodRestart{}
+	for _, history := range t.pods {
+		if history.Node != node {
+			continue
+		}
+		for _, event := range history.Events {
+			if event.Timestamp.Before(from) || event.Timestamp.After(to) {
+				continue
+			}
+			result = append(result, NodePodRestart{Namespace: history.Namespace, Pod: history.Pod, RestartEvent: event})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result
+}
+
+// issues 频繁重启的Pod，写入 ClusterMetrics.Issues
+func (t *restartTracker) issues(now time.Time) []string {
+	var issues []string
+	for _, pod := range t.list("", true, now) {
+		issue := fmt.Sprintf("Pod %s/%s restarted %d times in the last hour", pod.Namespace, pod.Pod, pod.RecentRestarts)
+		if pod.RecentOOMKills > 0 {
+			issue += fmt.Sprintf(" (%d OOMKilled)", pod.RecentOOMKills)
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// GetRestarts 返回各Pod的重启历史（近期重启次数从多到少），chronicOnly 时只返回最近一小时内重启达到3次的Pod
+func (m *Manager) GetRestarts(namespace string, chronicOnly bool) []PodRestarts {
+	return m.restarts.list(namespace, chronicOnly, time.Now())
+}
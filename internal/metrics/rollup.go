@@ -0,0 +1,385 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// 指标样本的降采样层：原始样本保留1小时，1分钟聚合保留24小时，10分钟聚合保留14天。
+// 后台任务定期将上一层已结束的时间窗口聚合写入下一层，查询时按时间范围选择层
+
+// 聚合层在存储中的集合名
+const (
+	SampleCollection1m  = "metric_samples_1m"
+	SampleCollection10m = "metric_samples_10m"
+)
+
+// 层名称
+const (
+	TierRaw = "raw"
+	Tier1m  = "1m"
+	Tier10m = "10m"
+)
+
+// rollupDelay 时间窗口结束后等待的时长，样本时间戳（如Metrics Server的采集窗口）可能略早于写入时间
+const rollupDelay = 2 * time.Minute
+
+// rollupMaxSpan 每次读取的最长时间范围
+const rollupMaxSpan = 6 * time.Hour
+
+// HistoryTier 样本历史的一层
+type HistoryTier struct {
+	Name       string
+	Collection string
+	Resolution time.Duration // 聚合窗口，原始样本为0
+	Retention  time.Duration // 与默认的存储保留策略一致
+}
+
+// HistoryTiers 从细到粗的各层
+var HistoryTiers = []HistoryTier{
+	{Name: TierRaw, Collection: SampleCollection, Retention: time.Hour},
+	{Name: Tier1m, Collection: SampleCollection1m, Resolution: time.Minute, Retention: 24 * time.Hour},
+	{Name: Tier10m, Collection: SampleCollection10m, Resolution: 10 * time.Minute, Retention: 14 * 24 * time.Hour},
+}
+
+// LookupTier 按名称查找层
+func LookupTier(name string) (HistoryTier, bool) {
+	for _, tier := range HistoryTiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return HistoryTier{}, false
+}
+
+// tierSlack 选择层时允许的误差，例如 from=1h 在解析参数之后才选择层时仍使用原始样本
+const tierSlack = time.Minute
+
+// SelectTier 选择保留时长能覆盖 from 的最细的层，from 为零值时选择最粗的层
+func SelectTier(from, now time.Time) HistoryTier {
+	if !from.IsZero() {
+		for _, tier := range HistoryTiers {
+			if now.Sub(from) <= tier.Retention+tierSlack {
+				return tier
+			}
+		}
+	}
+	return HistoryTiers[len(HistoryTiers)-1]
+}
+
+// RollupValue 一个时间窗口内某个指标的聚合值，原始样本视为只有一个值的窗口
+type RollupValue struct {
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+}
+
+func rawValue(value float64) RollupValue {
+	return RollupValue{Count: 1, Sum: value, Min: value, Max: value, First: value, Last: value}
+}
+
+// Avg 窗口内的平均值
+func (v RollupValue) Avg() float64 {
+	if v.Count == 0 {
+		return 0
+	}
+	return v.Sum / float64(v.Count)
+}
+
+// merge 合并时间上在其之后的窗口
+func (v *RollupValue) merge(next RollupValue) {
+	if v.Count == 0 {
+		*v = next
+		return
+	}
+	v.Count += next.Count
+	v.Sum += next.Sum
+	if next.Min < v.Min {
+		v.Min = next.Min
+	}
+	if next.Max > v.Max {
+		v.Max = next.Max
+	}
+	v.Last = next.Last
+}
+
+// HistoryPoint 从某一层读取的一条记录：原始层为一次采集，聚合层为一个时间窗口
+type HistoryPoint struct {
+	Key       string
+	Timestamp time.Time // 采集时间或聚合窗口的开始时间
+	Values    map[string]RollupValue
+}
+
+// decodeHistory 解析记录中的指标，metric 不为空时只解析该字段
+func decodeHistory(tier HistoryTier, record *storage.Record, metric string) (HistoryPoint, bool) {
+	point := HistoryPoint{Key: record.Key, Timestamp: record.Timestamp}
+
+	if tier.Resolution > 0 {
+		var values map[string]RollupValue
+		if err := json.Unmarshal(record.Data, &values); err != nil {
+			return point, false
+		}
+		if metric != "" {
+			value, ok := values[metric]
+			if !ok {
+				return point, false
+			}
+			values = map[string]RollupValue{metric: value}
+		}
+		point.Values = values
+		return point, len(values) > 0
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(record.Data, &fields); err != nil {
+		return point, false
+	}
+	point.Values = make(map[string]RollupValue, len(fields))
+	for name, raw := range fields {
+		if metric != "" && name != metric {
+			continue
+		}
+		switch value := raw.(type) {
+		case float64:
+			point.Values[name] = rawValue(value)
+		case bool:
+			if value {
+				point.Values[name] = rawValue(1)
+			} else {
+				point.Values[name] = rawValue(0)
+			}
+		}
+	}
+	return point, len(point.Values) > 0
+}
+
+// readTier 读取一层中时间范围内的记录
+func readTier(ctx context.Context, store storage.Store, tier HistoryTier, query storage.Query, metric string) ([]HistoryPoint, error) {
+	records, err := store.List(ctx, tier.Collection, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+	points := make([]HistoryPoint, 0, len(records))
+	for _, record := range records {
+		if point, ok := decodeHistory(tier, record, metric); ok {
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+// ReadHistory 读取节点、Pod和Pod间网络的样本历史（key 为空时读取所有对象，metric 为空时读取所有指标）。
+// tier 为空时按时间范围选择层，所选的聚合层在范围内没有数据时（未启用降采样或刚升级）退回原始样本
+func ReadHistory(ctx context.Context, store storage.Store, tier, key, metric string, from, to time.Time) ([]HistoryPoint, HistoryTier, error) {
+	query := storage.Query{Key: key, From: from, To: to}
+	if tier != "" {
+		selected, ok := LookupTier(tier)
+		if !ok {
+			return nil, HistoryTier{}, fmt.Errorf("unknown tier %q", tier)
+		}
+		points, err := readTier(ctx, store, selected, query, metric)
+		return points, selected, err
+	}
+
+	selected := SelectTier(from, time.Now())
+	points, err := readTier(ctx, store, selected, query, metric)
+	if err != nil || len(points) > 0 || selected.Resolution == 0 {
+		return points, selected, err
+	}
+	points, err = readTier(ctx, store, HistoryTiers[0], query, metric)
+	return points, HistoryTiers[0], err
+}
+
+// RollupStats 一个聚合层的降采样任务状态
+type RollupStats struct {
+	Tier       string    `json:"tier"`
+	Collection string    `json:"collection"`
+	Resolution string    `json:"resolution"`
+	Through    time.Time `json:"through,omitempty"` // 已聚合到的时间（不含）
+	Written    int64     `json:"written"`           // 已写入的聚合记录数
+	LastRun    time.Time `json:"last_run,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// rollupTracker 记录各聚合层的进度
+type rollupTracker struct {
+	mu    sync.Mutex
+	stats map[string]*RollupStats
+}
+
+func newRollupTracker() *rollupTracker {
+	stats := make(map[string]*RollupStats, len(HistoryTiers))
+	for _, tier := range HistoryTiers[1:] {
+		stats[tier.Name] = &RollupStats{Tier: tier.Name, Collection: tier.Collection, Resolution: tier.Resolution.String()}
+	}
+	return &rollupTracker{stats: stats}
+}
+
+// list 返回各聚合层的状态（从细到粗）
+func (t *rollupTracker) list() []RollupStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]RollupStats, 0, len(t.stats))
+	for _, tier := range HistoryTiers[1:] {
+		result = append(result, *t.stats[tier.Name])
+	}
+	return result
+}
+
+// rollupLoop 定期将已结束的时间窗口聚合到下一层
+func (m *Manager) rollupLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.rollupInterval)
+	defer ticker.Stop()
+
+	for {
+		m.rollupOnce(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollupOnce 依次聚合各层（先写入较细的层，较粗的层才能读到）
+func (m *Manager) rollupOnce(ctx context.Context, now time.Time) {
+	for i := 1; i < len(HistoryTiers); i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		source, target := HistoryTiers[i-1], HistoryTiers[i]
+
+		m.rollups.mu.Lock()
+		through := m.rollups.stats[target.Name].Through
+		m.rollups.mu.Unlock()
+
+		written, through, err := m.rollupTier(ctx, source, target, through, now)
+
+		m.rollups.mu.Lock()
+		stats := m.rollups.stats[target.Name]
+		stats.Through = through
+		stats.Written += int64(written)
+		stats.LastRun = now.UTC()
+		stats.LastError = ""
+		if err != nil {
+			stats.LastError = err.Error()
+		}
+		m.rollups.mu.Unlock()
+
+		if err != nil {
+			m.logger.Warnf("Failed to roll up metric samples into %s tier: %v", target.Name, err)
+		} else if written > 0 {
+			m.logger.Debugf("Rolled up %d %s metric windows (through %s)", written, target.Name, through.Format(time.RFC3339))
+		}
+	}
+}
+
+// rollupTier 将 source 中 [through, 已结束的最后一个窗口) 的记录按对象和窗口聚合写入 target，返回写入数和新的进度
+func (m *Manager) rollupTier(ctx context.Context, source, target HistoryTier, through, now time.Time) (int, time.Time, error) {
+	if through.IsZero() {
+		start, err := m.rollupStart(ctx, source, target, now)
+		if err != nil {
+			return 0, through, err
+		}
+		through = start
+	}
+
+	// 长时间停止后分段处理，避免一次读取过多样本
+	end := now.Add(-rollupDelay).Truncate(target.Resolution)
+	written, failed := 0, 0
+	for through.Before(end) && ctx.Err() == nil {
+		chunkEnd := through.Add(rollupMaxSpan).Truncate(target.Resolution)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		n, f, err := m.rollupRange(ctx, source, target, through, chunkEnd)
+		written, failed = written+n, failed+f
+		if err != nil {
+			return written, through, err
+		}
+		through = chunkEnd
+	}
+	// 与原始样本一样，写入失败的窗口不重试（重试会重复写入已成功的窗口）
+	if failed > 0 {
+		return written, through, fmt.Errorf("failed to store %d %s windows", failed, target.Name)
+	}
+	return written, through, nil
+}
+
+// rollupRange 聚合 [from, to) 内的记录，返回写入数和写入失败数，读取失败时返回错误
+func (m *Manager) rollupRange(ctx context.Context, source, target HistoryTier, from, to time.Time) (int, int, error) {
+	// Query.To 为闭区间
+	points, err := readTier(ctx, m.store, source, storage.Query{From: from, To: to.Add(-time.Nanosecond)}, "")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	windows := make(map[string]*HistoryPoint)
+	var order []*HistoryPoint
+	for _, point := range points {
+		start := point.Timestamp.Truncate(target.Resolution)
+		id := fmt.Sprintf("%s@%d", point.Key, start.UnixNano())
+		window := windows[id]
+		if window == nil {
+			window = &HistoryPoint{Key: point.Key, Timestamp: start, Values: make(map[string]RollupValue, len(point.Values))}
+			windows[id] = window
+			order = append(order, window)
+		}
+		for name, value := range point.Values {
+			merged := window.Values[name]
+			merged.merge(value)
+			window.Values[name] = merged
+		}
+	}
+
+	written, failed := 0, 0
+	for _, window := range order {
+		data, err := json.Marshal(window.Values)
+		if err != nil {
+			failed++
+			continue
+		}
+		record := &storage.Record{
+			ID:        fmt.Sprintf("%s@%d", window.Key, window.Timestamp.UnixNano()),
+			Key:       window.Key,
+			Timestamp: window.Timestamp,
+			Data:      data,
+		}
+		if err := m.store.Append(ctx, target.Collection, record); err != nil {
+			failed++
+			continue
+		}
+		written++
+	}
+	return written, failed, nil
+}
+
+// rollupStart 首次聚合的起点：target 中最新记录之后的窗口（服务重启后继续），
+// 没有记录时从 source 保留范围的开始
+func (m *Manager) rollupStart(ctx context.Context, source, target HistoryTier, now time.Time) (time.Time, error) {
+	records, err := m.store.List(ctx, target.Collection, storage.Query{From: now.Add(-target.Retention), Limit: 1})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read %s rollup progress: %w", target.Name, err)
+	}
+	if len(records) > 0 {
+		return records[0].Timestamp.Add(target.Resolution), nil
+	}
+	return now.Add(-source.Retention).Truncate(target.Resolution), nil
+}
+
+// RollupStatus 返回各聚合层的降采样任务状态（未启用时为空）
+func (m *Manager) RollupStatus() []RollupStats {
+	if m.rollups == nil {
+		return nil
+	}
+	return m.rollups.list()
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// SampleCollection 指标样本在存储中的集合名
+const SampleCollection = "metric_samples"
+
+// 样本实体类型
+const (
+	TargetNode = "node"
+	TargetPod  = "pod"
+	TargetPair = "pair"
+	TargetUAV  = "uav"
+)
+
+// SampleKey 生成样本的实体标识，例如 node/worker-1、pod/default/nginx、pair/a|b
+func SampleKey(target string, parts ...string) string {
+	switch target {
+	case TargetPair:
+		if len(parts) == 2 {
+			return fmt.Sprintf("%s/%s|%s", target, parts[0], parts[1])
+		}
+	case TargetPod:
+		if len(parts) == 2 {
+			return fmt.Sprintf("%s/%s/%s", target, parts[0], parts[1])
+		}
+	}
+	if len(parts) == 0 {
+		return target
+	}
+	return target + "/" + parts[0]
+}
+
+// keyedSample 一个对象在一次采集中的数值指标
+type keyedSample struct {
+	key       string
+	timestamp time.Time
+	values    map[string]float64
+}
+
+// snapshotSamples 提取快照中节点、Pod和Pod间网络的数值指标
+func snapshotSamples(snapshot *metricstypes.MetricsSnapshot) []keyedSample {
+	samples := make([]keyedSample, 0, len(snapshot.NodeMetrics)+len(snapshot.PodMetrics)+len(snapshot.NetworkMetrics))
+	appendSample := func(key string, timestamp time.Time, values map[string]float64) {
+		if timestamp.IsZero() {
+			timestamp = snapshot.Timestamp
+		}
+		samples = append(samples, keyedSample{key: key, timestamp: timestamp, values: values})
+	}
+
+	for nodeName, node := range snapshot.NodeMetrics {
+		healthy := 0.0
+		if node.Healthy {
+			healthy = 1
+		}
+		appendSample(SampleKey(TargetNode, nodeName), node.Timestamp, map[string]float64{
+			"cpu_usage":         float64(node.CPUUsage),
+			"cpu_usage_rate":    node.CPUUsageRate,
+			"memory_usage":      float64(node.MemoryUsage),
+			"memory_usage_rate": node.MemoryUsageRate,
+			"disk_usage_rate":   node.DiskUsageRate,
+			"network_latency":   node.NetworkLatency,
+			"cpu_usage_trend":   node.CPUUsageTrend,
+			"network_rx_rate":   node.NetworkRxRate,
+			"network_tx_rate":   node.NetworkTxRate,
+			"healthy":           healthy,
+		})
+	}
+
+	for _, pod := range snapshot.PodMetrics {
+		ready := 0.0
+		if pod.Ready {
+			ready = 1
+		}
+		values := map[string]float64{
+			"cpu_usage":         float64(pod.CPUUsage),
+			"cpu_usage_rate":    pod.CPUUsageRate,
+			"memory_usage":      float64(pod.MemoryUsage),
+			"memory_usage_rate": pod.MemoryUsageRate,
+			"restarts":          float64(pod.Restarts),
+			"restart_rate":      pod.RestartRate,
+			"cpu_usage_trend":   pod.CPUUsageTrend,
+			"ready":             ready,
+		}
+		// 只有分配或使用了GPU的Pod记录GPU样本
+		if pod.GPURequest > 0 || len(pod.GPUDevices) > 0 {
+			values["gpu_usage"] = pod.GPUUsage
+			values["gpu_usage_rate"] = pod.GPUUsageRate
+			values["gpu_memory_used"] = float64(pod.GPUMemoryUsed)
+		}
+		appendSample(SampleKey(TargetPod, pod.Namespace, pod.PodName), pod.Timestamp, values)
+	}
+
+	for _, pair := range snapshot.NetworkMetrics {
+		connected := 0.0
+		if pair.Connected {
+			connected = 1
+		}
+		appendSample(SampleKey(TargetPair, pair.SourcePod, pair.TargetPod), pair.Timestamp, map[string]float64{
+			"rtt_ms":         pair.RTT,
+			"packet_loss":    pair.PacketLoss,
+			"bandwidth_mbps": pair.Bandwidth,
+			"jitter_ms":      pair.Jitter,
+			"quality_score":  pair.QualityScore,
+			"connected":      connected,
+		})
+	}
+	return samples
+}
+
+// uavSamples 提取UAV状态中的数值指标，字段名与UAV遥测历史一致，样本键为节点名
+func uavSamples(entries map[string]*models.UAVMetricsEntry) []keyedSample {
+	samples := make([]keyedSample, 0, len(entries))
+	for node, entry := range entries {
+		state := entry.State
+		if state == nil {
+			continue
+		}
+		timestamp := entry.LastHeartbeat
+		armed := 0.0
+		if state.Flight.Armed {
+			armed = 1
+		}
+		samples = append(samples, keyedSample{key: node, timestamp: timestamp, values: map[string]float64{
+			"battery_percent":   state.Battery.RemainingPercent,
+			"battery_voltage":   state.Battery.Voltage,
+			"latitude":          state.GPS.Latitude,
+			"longitude":         state.GPS.Longitude,
+			"altitude":          state.GPS.Altitude,
+			"relative_altitude": state.GPS.RelativeAltitude,
+			"ground_speed":      state.GPS.GroundSpeed,
+			"armed":             armed,
+		}})
+	}
+	return samples
+}
+
+// recordHistory 将一次采集结果写入内存历史
+func (m *Manager) recordHistory(snapshot *metricstypes.MetricsSnapshot, uavs map[string]*models.UAVMetricsEntry) {
+	entry := historyEntry{timestamp: snapshot.Timestamp, samples: make(map[string]map[string]float64)}
+	for _, s := range append(snapshotSamples(snapshot), uavSamples(uavs)...) {
+		entry.samples[s.key] = s.values
+	}
+	m.history.add(entry)
+}
+
+// recordSamples 将一次采集结果中的数值指标写入存储，供聚合查询使用
+func (m *Manager) recordSamples(ctx context.Context, snapshot *metricstypes.MetricsSnapshot) {
+	if m.store == nil {
+		return
+	}
+
+	written, failed := 0, 0
+	for _, s := range snapshotSamples(snapshot) {
+		data, err := json.Marshal(s.values)
+		if err != nil {
+			failed++
+			continue
+		}
+		record := &storage.Record{
+			ID:        fmt.Sprintf("%s@%d", s.key, s.timestamp.UnixNano()),
+			Key:       s.key,
+			Timestamp: s.timestamp,
+			Data:      data,
+		}
+		if err := m.store.Append(ctx, SampleCollection, record); err != nil {
+			failed++
+			continue
+		}
+		written++
+	}
+
+	if failed > 0 {
+		m.logger.Warnf("Failed to store %d metric samples (%d stored)", failed, written)
+	} else {
+		m.logger.Debugf("Stored %d metric samples", written)
+	}
+}
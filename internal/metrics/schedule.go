@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// scheduleSlack 下次采集时间在该范围内的数据源合并到同一轮采集
+const scheduleSlack = 500 * time.Millisecond
+
+// minScheduleInterval 采集间隔的下限
+const minScheduleInterval = time.Second
+
+// collectorSchedule 单个数据源的采集间隔和下次采集时间
+type collectorSchedule struct {
+	interval time.Duration // 为0时使用全局采集间隔
+	next     time.Time     // 为零值时下一轮立即采集
+}
+
+// collectorScheduler 按各数据源自己的间隔（加随机抖动）决定每轮采集哪些数据源，
+// 避免所有数据源按最慢的节奏采集或在同一时刻同时触发
+type collectorScheduler struct {
+	mu        sync.Mutex
+	interval  time.Duration // 全局采集间隔
+	jitter    float64       // 每次调度额外等待 [0, jitter*间隔) 的随机时间
+	schedules map[string]*collectorSchedule
+	rand      *rand.Rand
+}
+
+// newCollectorScheduler 创建调度器，intervals 中没有配置的数据源使用全局间隔
+func newCollectorScheduler(interval time.Duration, jitter float64, names []string, intervals map[string]time.Duration) *collectorScheduler {
+	s := &collectorScheduler{
+		interval:  interval,
+		jitter:    clampJitter(jitter),
+		schedules: make(map[string]*collectorSchedule, len(names)),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, name := range names {
+		s.schedules[name] = &collectorSchedule{interval: intervals[name]}
+	}
+	return s
+}
+
+// clampJitter 抖动比例限制在 [0, 1]
+func clampJitter(jitter float64) float64 {
+	if jitter < 0 {
+		return 0
+	}
+	if jitter > 1 {
+		return 1
+	}
+	return jitter
+}
+
+// effective 数据源实际使用的间隔（调用方持有锁）
+func (s *collectorScheduler) effective(schedule *collectorSchedule) time.Duration {
+	interval := schedule.interval
+	if interval <= 0 {
+		interval = s.interval
+	}
+	if interval < minScheduleInterval {
+		interval = minScheduleInterval
+	}
+	return interval
+}
+
+// setInterval 调整全局采集间隔，使用全局间隔的数据源按新间隔重新调度
+func (s *collectorScheduler) setInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = interval
+	now := time.Now()
+	for _, schedule := range s.schedules {
+		if schedule.interval <= 0 && !schedule.next.IsZero() {
+			schedule.next = now.Add(s.delay(interval))
+		}
+	}
+}
+
+// setIntervals 更新各数据源单独的间隔和抖动比例，intervals 中没有的数据源改用全局间隔；
+// 间隔变化的数据源按新间隔重新调度，抖动比例从下次调度起生效
+func (s *collectorScheduler) setIntervals(intervals map[string]time.Duration, jitter float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jitter = clampJitter(jitter)
+	now := time.Now()
+	for name, schedule := range s.schedules {
+		if intervals[name] == schedule.interval {
+			continue
+		}
+		schedule.interval = intervals[name]
+		if !schedule.next.IsZero() {
+			schedule.next = now.Add(s.delay(s.effective(schedule)))
+		}
+	}
+}
+
+// delay 间隔加上随机抖动（调用方持有锁）
+func (s *collectorScheduler) delay(interval time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(s.rand.Float64()*s.jitter*float64(interval))
+}
+
+// due 返回本轮应当采集的数据源，并为它们安排下次采集时间
+func (s *collectorScheduler) due(now time.Time) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make(map[string]bool, len(s.schedules))
+	for name, schedule := range s.schedules {
+		if schedule.next.IsZero() || !schedule.next.After(now.Add(scheduleSlack)) {
+			due[name] = true
+			schedule.next = now.Add(s.delay(s.effective(schedule)))
+		}
+	}
+	return due
+}
+
+// untilNext 距离最早一个数据源下次采集的时间（没有数据源时为全局间隔）
+func (s *collectorScheduler) untilNext(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.schedules) == 0 {
+		return s.effective(&collectorSchedule{})
+	}
+	var earliest time.Time
+	first := true
+	for _, schedule := range s.schedules {
+		if first || schedule.next.Before(earliest) {
+			earliest, first = schedule.next, false
+		}
+	}
+	if wait := earliest.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// shortest 所有数据源中最短的采集间隔（决定内存历史的容量）
+func (s *collectorScheduler) shortest() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shortest := time.Duration(0)
+	for _, schedule := range s.schedules {
+		if interval := s.effective(schedule); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+	}
+	if shortest == 0 {
+		shortest = s.effective(&collectorSchedule{})
+	}
+	return shortest
+}
+
+// lookup 数据源实际使用的间隔和下次采集时间
+func (s *collectorScheduler) lookup(name string) (time.Duration, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[name]
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return s.effective(schedule), schedule.next, true
+}
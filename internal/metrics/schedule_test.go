@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// 热更新后间隔变化的数据源按新间隔重新调度，其余数据源保持原有的下次采集时间
+func TestCollectorSchedulerSetIntervals(t *testing.T) {
+	s := newCollectorScheduler(time.Minute, 0, []string{"node", "pod", "network"}, map[string]time.Duration{"network": 5 * time.Minute})
+	now := time.Now()
+	s.due(now)
+	_, podNext, _ := s.lookup("pod")
+
+	s.setIntervals(map[string]time.Duration{"node": 10 * time.Second, "gpu": time.Second}, 0.5)
+
+	if interval, next, _ := s.lookup("node"); interval != 10*time.Second || next.After(time.Now().Add(15*time.Second)) {
+		t.Errorf("node = %v, next in %v, want 10s within 15s", interval, time.Until(next))
+	}
+	if interval, next, _ := s.lookup("pod"); interval != time.Minute || !next.Equal(podNext) {
+		t.Errorf("pod = %v, next %v, want unchanged 1m at %v", interval, next, podNext)
+	}
+	// 删除单独配置的数据源回到全局间隔
+	if interval, next, _ := s.lookup("network"); interval != time.Minute || next.After(time.Now().Add(90*time.Second)) {
+		t.Errorf("network = %v, next in %v, want 1m within 90s", interval, time.Until(next))
+	}
+	if _, _, ok := s.lookup("gpu"); ok {
+		t.Error("setIntervals() added a collector that is not enabled")
+	}
+	if shortest := s.shortest(); shortest != 10*time.Second {
+		t.Errorf("shortest() = %v, want 10s", shortest)
+	}
+	if s.jitter != 0.5 {
+		t.Errorf("jitter = %v, want 0.5", s.jitter)
+	}
+	if s.setIntervals(nil, 3); s.jitter != 1 {
+		t.Errorf("jitter = %v, want clamped to 1", s.jitter)
+	}
+}
+
+// 尚未采集过的数据源保持立即采集
+func TestCollectorSchedulerSetIntervalsBeforeStart(t *testing.T) {
+	s := newCollectorScheduler(time.Minute, 0, []string{"node"}, nil)
+	s.setIntervals(map[string]time.Duration{"node": 10 * time.Second}, 0)
+	if due := s.due(time.Now()); !due["node"] {
+		t.Error("node not due on the first round after setIntervals()")
+	}
+}
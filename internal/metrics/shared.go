@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 多副本共享状态在存储中的位置
+const (
+	sharedBucket      = "shared"
+	sharedSnapshotKey = "snapshot"
+	sharedUAVBucket   = "shared_uav" // 每个UAV节点一个键
+)
+
+// sharedTimeout 单次读写共享状态的超时
+const sharedTimeout = 5 * time.Second
+
+// publishSnapshot 将本副本采集的快照写入共享存储；共享存储中已有更新的快照时不覆盖
+func (m *Manager) publishSnapshot(ctx context.Context, snapshot *metricstypes.MetricsSnapshot) {
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+
+	if current, err := m.loadSharedSnapshot(ctx); err == nil && current != nil && !current.Timestamp.Before(snapshot.Timestamp) {
+		return
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		m.logger.Warnf("Failed to encode shared snapshot: %v", err)
+		return
+	}
+	if err := m.store.Put(ctx, sharedBucket, sharedSnapshotKey, data); err != nil {
+		m.logger.Warnf("Failed to publish shared snapshot: %v", err)
+	}
+}
+
+// publishUAV 将收到的UAV上报写入共享存储，其他副本同步后可查询
+func (m *Manager) publishUAV(nodeName string, entry *models.UAVMetricsEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		m.logger.Warnf("Failed to encode shared UAV state of %s: %v", nodeName, err)
+		return
+	}
+	if err := m.store.Put(ctx, sharedUAVBucket, nodeName, data); err != nil {
+		m.logger.Warnf("Failed to publish shared UAV state of %s: %v", nodeName, err)
+	}
+}
+
+// loadSharedSnapshot 读取共享快照，不存在时返回空
+func (m *Manager) loadSharedSnapshot(ctx context.Context) (*metricstypes.MetricsSnapshot, error) {
+	data, err := m.store.Get(ctx, sharedBucket, sharedSnapshotKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshot metricstypes.MetricsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode shared snapshot: %w", err)
+	}
+	normalizeSnapshot(&snapshot)
+	return &snapshot, nil
+}
+
+// syncShared 读取其他副本写入的快照和UAV状态，只采用比本地更新的数据。
+// 返回共享快照的时间（没有时为零值）
+func (m *Manager) syncShared(ctx context.Context) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, sharedTimeout)
+	defer cancel()
+
+	snapshot, err := m.loadSharedSnapshot(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var sharedAt time.Time
+	if snapshot != nil {
+		sharedAt = snapshot.Timestamp
+		m.snapshotMutex.Lock()
+		adopt := m.snapshot == nil || snapshot.Timestamp.After(m.snapshot.Timestamp)
+		if adopt {
+			m.snapshot = snapshot
+		}
+		m.snapshotMutex.Unlock()
+		if adopt {
+			// 样本和异常由采集该快照的副本记录，这里只补充内存历史
+			m.recordHistory(snapshot, m.GetUAVMetrics())
+			m.logger.Debugf("Adopted shared snapshot collected at %s", snapshot.Timestamp.Format(time.RFC3339))
+		}
+	}
+
+	enumerator, ok := m.store.(storage.Enumerator)
+	if !ok {
+		return sharedAt, nil
+	}
+	nodes, err := enumerator.Keys(ctx, sharedUAVBucket)
+	if err != nil {
+		return sharedAt, fmt.Errorf("failed to list shared UAV states: %w", err)
+	}
+	for _, nodeName := range nodes {
+		data, err := m.store.Get(ctx, sharedUAVBucket, nodeName)
+		if err != nil {
+			continue
+		}
+		entry, ok := decodeUAVEntry(data)
+		if !ok {
+			continue
+		}
+		m.snapshotMutex.Lock()
+		if current, exists := m.uavSnapshot[nodeName]; !exists || entry.LastHeartbeat.After(current.LastHeartbeat) {
+			m.uavSnapshot[nodeName] = entry
+		}
+		m.snapshotMutex.Unlock()
+	}
+	return sharedAt, nil
+}
+
+// collectedElsewhere 其他副本是否刚完成一次采集（此时本副本跳过本轮采集，直接使用共享快照）
+func (m *Manager) collectedElsewhere(ctx context.Context) bool {
+	sharedAt, err := m.syncShared(ctx)
+	if err != nil {
+		m.logger.Warnf("Failed to sync shared metrics state: %v", err)
+		return false
+	}
+	m.runMutex.Lock()
+	interval := m.interval
+	m.runMutex.Unlock()
+	return !sharedAt.IsZero() && time.Since(sharedAt) < interval/2
+}
+
+// sharedLoop 定期同步其他副本的快照和UAV上报
+func (m *Manager) sharedLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			if _, err := m.syncShared(ctx); err != nil {
+				m.logger.Warnf("Failed to sync shared metrics state: %v", err)
+			}
+		}
+	}
+}
+
+// decodeUAVEntry 解码共享的UAV条目
+func decodeUAVEntry(data []byte) (*models.UAVMetricsEntry, bool) {
+	var entry models.UAVMetricsEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, !entry.LastHeartbeat.IsZero()
+}
+
+// normalizeSnapshot 为解码得到的快照补全空集合
+func normalizeSnapshot(snapshot *metricstypes.MetricsSnapshot) {
+	if snapshot.NodeMetrics == nil {
+		snapshot.NodeMetrics = make(map[string]*metricstypes.NodeMetrics)
+	}
+	if snapshot.PodMetrics == nil {
+		snapshot.PodMetrics = make(map[string]*metricstypes.PodMetrics)
+	}
+	if snapshot.NetworkMetrics == nil {
+		snapshot.NetworkMetrics = []*metricstypes.NetworkMetrics{}
+	}
+	if snapshot.ClusterMetrics == nil {
+		snapshot.ClusterMetrics = &metricstypes.ClusterMetrics{}
+	}
+}
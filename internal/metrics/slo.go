@@ -0,0 +1,322 @@
+package metrics
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// SLO跟踪参数
+const (
+	sloBucketSize      = 5 * time.Minute     // 达标计数按固定窗口累计，窗口内的样本合并保存
+	sloBurnRateWindow  = time.Hour           // 计算错误预算消耗速率的时间窗口
+	sloDefaultWindow   = 30 * 24 * time.Hour // 未配置窗口时的滚动窗口
+	sloAtRiskRemaining = 25.0                // 剩余错误预算低于该比例时视为有风险
+	maxSLOViolations   = 20                  // 每个SLO最多列出的未达标对象
+)
+
+// SLO状态
+const (
+	SLOStatusOK       = "ok"
+	SLOStatusAtRisk   = "at_risk"  // 仍达标，但剩余错误预算不足25%
+	SLOStatusBreached = "breached" // 窗口内达标率低于目标
+	SLOStatusNoData   = "no_data"  // 窗口内没有匹配的样本
+)
+
+// SLODefinition 一个服务等级目标：匹配对象的每个样本满足 metric <operator> threshold 时计为达标
+type SLODefinition struct {
+	Name        string
+	Description string
+	Target      string // node、pod、pair、uav
+	Match       string // 对象名称的glob（节点名、namespace/pod、source|dest、UAV节点名），为空时匹配全部
+	Metric      string
+	Operator    string // <、<=、>、>=、==、!=
+	Threshold   float64
+	Objective   float64       // 目标达标比例 (0-100)
+	Window      time.Duration // 滚动窗口，为0时为30天
+}
+
+// Condition 达标条件的文字描述，例如 rtt_ms < 50
+func (d SLODefinition) Condition() string {
+	return fmt.Sprintf("%s %s %g", d.Metric, d.Operator, d.Threshold)
+}
+
+// matches 样本键是否属于该SLO的对象，返回对象名称
+func (d SLODefinition) matches(key string) (string, bool) {
+	name := key
+	if d.Target != TargetUAV {
+		var ok bool
+		if name, ok = strings.CutPrefix(key, d.Target+"/"); !ok {
+			return "", false
+		}
+	} else if strings.Contains(key, "/") {
+		return "", false
+	}
+	if d.Match == "" {
+		return name, true
+	}
+	ok, _ := path.Match(d.Match, name)
+	return name, ok
+}
+
+// good 样本值是否达标
+func (d SLODefinition) good(value float64) bool {
+	switch d.Operator {
+	case "<":
+		return value < d.Threshold
+	case "<=":
+		return value <= d.Threshold
+	case ">":
+		return value > d.Threshold
+	case ">=":
+		return value >= d.Threshold
+	case "==":
+		return value == d.Threshold
+	case "!=":
+		return value != d.Threshold
+	}
+	return false
+}
+
+// ErrorBudget 窗口内的错误预算（以未达标样本数计）
+type ErrorBudget struct {
+	Allowed   float64 `json:"allowed"`   // 按目标比例允许的未达标样本数
+	Consumed  int     `json:"consumed"`  // 实际未达标样本数
+	Remaining float64 `json:"remaining"` // 剩余预算比例 (%)，超支时为负数
+	BurnRate  float64 `json:"burn_rate"` // 最近一小时的消耗速率，1表示恰好在窗口结束时用完
+}
+
+// SLOStatus 一个SLO在滚动窗口内的达标情况
+type SLOStatus struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Target      string      `json:"target"`
+	Match       string      `json:"match,omitempty"`
+	Condition   string      `json:"condition"`
+	Objective   float64     `json:"objective"`
+	Window      string      `json:"window"`
+	Status      string      `json:"status"`
+	Good        int         `json:"good"`       // 窗口内达标的样本数
+	Total       int         `json:"total"`      // 窗口内的样本数
+	Compliance  float64     `json:"compliance"` // 达标比例 (%)
+	ErrorBudget ErrorBudget `json:"error_budget"`
+	Violations  []string    `json:"violations,omitempty"` // 最近一次采集中未达标的对象（最多20个）
+	UpdatedAt   time.Time   `json:"updated_at,omitempty"` // 最近一次有匹配样本的采集时间
+}
+
+// sloBucket 一个固定窗口内的达标计数
+type sloBucket struct {
+	Start time.Time `json:"start"`
+	Good  int       `json:"good"`
+	Total int       `json:"total"`
+}
+
+// sloState 一个SLO的计数和最近一次采集的结果
+type sloState struct {
+	def        SLODefinition
+	buckets    []sloBucket // 按时间从旧到新
+	violations []string
+	updatedAt  time.Time
+}
+
+// sloTracker 按采集到的样本累计各SLO的达标计数
+type sloTracker struct {
+	mu   sync.RWMutex
+	slos []*sloState
+}
+
+// newSLOTracker 创建SLO跟踪器
+func newSLOTracker(defs []SLODefinition) *sloTracker {
+	t := &sloTracker{}
+	for _, def := range defs {
+		if def.Window <= 0 {
+			def.Window = sloDefaultWindow
+		}
+		t.slos = append(t.slos, &sloState{def: def})
+	}
+	return t
+}
+
+// observe 累计一次采集的样本，只计入本轮新采集的数据
+func (t *sloTracker) observe(samples []keyedSample, now time.Time) {
+	if len(t.slos) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := now.Truncate(sloBucketSize)
+	for _, slo := range t.slos {
+		good, total := 0, 0
+		var violations []string
+		for _, s := range samples {
+			name, ok := slo.def.matches(s.key)
+			if !ok {
+				continue
+			}
+			value, ok := s.values[slo.def.Metric]
+			if !ok {
+				continue
+			}
+			total++
+			if slo.def.good(value) {
+				good++
+			} else {
+				violations = append(violations, name)
+			}
+		}
+		if total == 0 {
+			continue
+		}
+
+		sort.Strings(violations)
+		slo.violations, slo.updatedAt = violations, now
+		if n := len(slo.buckets); n > 0 && slo.buckets[n-1].Start.Equal(start) {
+			slo.buckets[n-1].Good += good
+			slo.buckets[n-1].Total += total
+		} else {
+			slo.buckets = append(slo.buckets, sloBucket{Start: start, Good: good, Total: total})
+		}
+		slo.prune(now)
+	}
+}
+
+// prune 删除滚动窗口之外的计数
+func (s *sloState) prune(now time.Time) {
+	cutoff := now.Add(-s.def.Window)
+	i := 0
+	for i < len(s.buckets) && !s.buckets[i].Start.Add(sloBucketSize).After(cutoff) {
+		i++
+	}
+	s.buckets = s.buckets[i:]
+}
+
+// status 计算滚动窗口内的达标率和错误预算
+func (s *sloState) status(now time.Time) SLOStatus {
+	status := SLOStatus{
+		Name:        s.def.Name,
+		Description: s.def.Description,
+		Target:      s.def.Target,
+		Match:       s.def.Match,
+		Condition:   s.def.Condition(),
+		Objective:   s.def.Objective,
+		Window:      s.def.Window.String(),
+		UpdatedAt:   s.updatedAt,
+	}
+
+	cutoff := now.Add(-s.def.Window)
+	recentGood, recentTotal := 0, 0
+	for _, b := range s.buckets {
+		if !b.Start.Add(sloBucketSize).After(cutoff) {
+			continue
+		}
+		status.Good += b.Good
+		status.Total += b.Total
+		if b.Start.Add(sloBucketSize).After(now.Add(-sloBurnRateWindow)) {
+			recentGood += b.Good
+			recentTotal += b.Total
+		}
+	}
+	if status.Total == 0 {
+		status.Status = SLOStatusNoData
+		return status
+	}
+
+	allowedRatio := 1 - s.def.Objective/100
+	budget := &status.ErrorBudget
+	status.Compliance = float64(status.Good) / float64(status.Total) * 100
+	budget.Allowed = float64(status.Total) * allowedRatio
+	budget.Consumed = status.Total - status.Good
+	budget.Remaining = (1 - float64(budget.Consumed)/budget.Allowed) * 100
+	if recentTotal > 0 {
+		budget.BurnRate = float64(recentTotal-recentGood) / float64(recentTotal) / allowedRatio
+	}
+
+	if len(s.violations) > maxSLOViolations {
+		status.Violations = s.violations[:maxSLOViolations]
+	} else {
+		status.Violations = s.violations
+	}
+
+	switch {
+	case status.Compliance < s.def.Objective:
+		status.Status = SLOStatusBreached
+	case budget.Remaining < sloAtRiskRemaining:
+		status.Status = SLOStatusAtRisk
+	default:
+		status.Status = SLOStatusOK
+	}
+	budget.Allowed, budget.Remaining, budget.BurnRate = round(budget.Allowed), round(budget.Remaining), round(budget.BurnRate)
+	return status
+}
+
+// statuses 各SLO的当前状态（按配置顺序）
+func (t *sloTracker) statuses(now time.Time) []SLOStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]SLOStatus, 0, len(t.slos))
+	for _, slo := range t.slos {
+		result = append(result, slo.status(now))
+	}
+	return result
+}
+
+// issues 未达标的SLO，作为集群健康问题
+func (t *sloTracker) issues(now time.Time) []string {
+	var issues []string
+	for _, status := range t.statuses(now) {
+		if status.Status == SLOStatusBreached {
+			issues = append(issues, fmt.Sprintf("SLO %q breached: %.2f%% of samples meet %s (objective %g%%)",
+				status.Name, status.Compliance, status.Condition, status.Objective))
+		}
+	}
+	return issues
+}
+
+// export 导出各SLO的计数用于持久化
+func (t *sloTracker) export() map[string][]sloBucket {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.slos) == 0 {
+		return nil
+	}
+	result := make(map[string][]sloBucket, len(t.slos))
+	for _, slo := range t.slos {
+		result[slo.def.Name] = append([]sloBucket(nil), slo.buckets...)
+	}
+	return result
+}
+
+// restore 恢复持久化的计数（按名称对应，已删除的SLO忽略），窗口之外的计数被丢弃
+func (t *sloTracker) restore(saved map[string][]sloBucket, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, slo := range t.slos {
+		buckets, ok := saved[slo.def.Name]
+		if !ok {
+			continue
+		}
+		slo.buckets = append([]sloBucket(nil), buckets...)
+		slo.prune(now)
+	}
+}
+
+// SLOs 返回各SLO在滚动窗口内的达标率和错误预算
+func (m *Manager) SLOs() []SLOStatus {
+	return m.slos.statuses(time.Now())
+}
+
+// observeSLOs 使用本轮新采集的节点、Pod、Pod对和UAV样本更新SLO计数
+func (m *Manager) observeSLOs(fresh *metricstypes.MetricsSnapshot, uavs map[string]*models.UAVMetricsEntry) {
+	m.slos.observe(append(snapshotSamples(fresh), uavSamples(uavs)...), fresh.Timestamp)
+}
@@ -0,0 +1,190 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// 自定义资源的默认字段
+const (
+	defaultNodeField   = "spec.nodeName"
+	defaultValuesField = "status"
+)
+
+// CustomResource 一个提供节点指标的自定义资源（例如节点Agent发布的 gpu.monitoring.io 指标对象）
+type CustomResource struct {
+	Group       string
+	Version     string
+	Resource    string            // 复数资源名，例如 gpumetrics
+	Namespace   string            // 为空时读取所有命名空间（或集群范围资源）
+	NodeField   string            // 节点名所在字段（点分隔路径），默认 spec.nodeName
+	ValuesField string            // 未配置 Fields 时读取该字段下的所有标量值，默认 status
+	Fields      map[string]string // 指标名 -> 字段路径
+}
+
+// gvr 资源的GroupVersionResource
+func (r CustomResource) gvr() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// CustomMetricsCollector 通过动态客户端读取自定义资源，按节点汇总指标
+type CustomMetricsCollector struct {
+	client      dynamic.Interface
+	resources   []CustomResource
+	logger      *logrus.Entry
+	permissions *PermissionTracker
+}
+
+// NewCustomMetricsCollector 创建自定义指标采集器，缺少版本或资源名的配置被忽略
+func NewCustomMetricsCollector(client dynamic.Interface, resources []CustomResource) *CustomMetricsCollector {
+	logger := logging.For("metrics.custom")
+
+	valid := make([]CustomResource, 0, len(resources))
+	for _, r := range resources {
+		if r.Version == "" || r.Resource == "" {
+			logger.Warnf("Ignoring custom metrics resource %q: version and resource are required", r.Group+"/"+r.Version+"/"+r.Resource)
+			continue
+		}
+		if r.NodeField == "" {
+			r.NodeField = defaultNodeField
+		}
+		if r.ValuesField == "" {
+			r.ValuesField = defaultValuesField
+		}
+		valid = append(valid, r)
+	}
+
+	return &CustomMetricsCollector{
+		client:    client,
+		resources: valid,
+		logger:    logger,
+	}
+}
+
+// SetPermissionTracker 设置权限跟踪器（权限不足时跳过该资源）
+func (c *CustomMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
+// CollectCustomMetrics 读取所有配置的资源，返回 节点名 -> map[string]interface{}（指标名 -> 值）。
+// 同一节点有多个同类对象时，指标名前加上对象名。部分资源读取失败时返回其余结果和 *PartialError
+func (c *CustomMetricsCollector) CollectCustomMetrics(ctx context.Context) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	var failed []string
+
+	for _, r := range c.resources {
+		if !c.permissions.Allow(CollectorCustom, "list", r.Group, r.Resource, r.Namespace) {
+			continue
+		}
+		list, err := c.client.Resource(r.gvr()).Namespace(r.Namespace).List(ctx, metav1.ListOptions{})
+		if c.permissions.Observe(CollectorCustom, "list", r.Group, r.Resource, r.Namespace, err) {
+			continue
+		}
+		if err != nil {
+			c.logger.Warnf("Failed to list %s: %v", r.gvr().String(), err)
+			failed = append(failed, r.gvr().GroupResource().String())
+			continue
+		}
+
+		perNode := make(map[string][]*unstructured.Unstructured)
+		for i := range list.Items {
+			item := &list.Items[i]
+			node, _, _ := unstructured.NestedString(item.Object, splitPath(r.NodeField)...)
+			if node == "" {
+				c.logger.Debugf("Skipping %s %s: no node name in %s", r.Resource, item.GetName(), r.NodeField)
+				continue
+			}
+			perNode[node] = append(perNode[node], item)
+		}
+
+		for node, items := range perNode {
+			values, _ := result[node].(map[string]interface{})
+			if values == nil {
+				values = make(map[string]interface{})
+				result[node] = values
+			}
+			for _, item := range items {
+				prefix := ""
+				if len(items) > 1 {
+					prefix = item.GetName() + "."
+				}
+				for name, value := range r.extract(item) {
+					values[prefix+name] = value
+				}
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		err := fmt.Errorf("failed to list custom metrics resources: %s", strings.Join(failed, ", "))
+		if len(failed) == len(c.resources) {
+			return result, err
+		}
+		return result, partial([]error{err})
+	}
+	return result, nil
+}
+
+// extract 按配置的字段或 ValuesField 下的所有标量值提取指标
+func (r CustomResource) extract(item *unstructured.Unstructured) map[string]interface{} {
+	values := make(map[string]interface{})
+	if len(r.Fields) > 0 {
+		for name, path := range r.Fields {
+			value, found, err := unstructured.NestedFieldNoCopy(item.Object, splitPath(path)...)
+			if err != nil || !found {
+				continue
+			}
+			if scalar, ok := scalarValue(value); ok {
+				values[name] = scalar
+			}
+		}
+		return values
+	}
+
+	root, found, err := unstructured.NestedFieldNoCopy(item.Object, splitPath(r.ValuesField)...)
+	if err != nil || !found {
+		return values
+	}
+	flatten(r.Resource, root, values)
+	return values
+}
+
+// flatten 将嵌套对象中的标量值展开为 resource.a.b 形式的指标名（忽略列表）
+func flatten(prefix string, value interface{}, out map[string]interface{}) {
+	if object, ok := value.(map[string]interface{}); ok {
+		for key, child := range object {
+			flatten(prefix+"."+key, child, out)
+		}
+		return
+	}
+	if scalar, ok := scalarValue(value); ok {
+		out[prefix] = scalar
+	}
+}
+
+// scalarValue 数值统一转换为float64，保留字符串和布尔值
+func scalarValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64, string, bool:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// splitPath 拆分点分隔的字段路径
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "."), ".")
+}
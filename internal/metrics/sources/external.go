@@ -0,0 +1,143 @@
+package sources
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// ExternalTargetsCollector 由各节点上的探测Agent访问集群外的目标（LLM服务API、对象存储等），
+// 测量DNS解析、TCP建连、TLS握手和HTTP请求的耗时
+type ExternalTargetsCollector struct {
+	k8sClient      *k8s.Client
+	agents         *probe.Client
+	agentNamespace string
+	targets        []ExternalTarget
+	timeout        time.Duration
+	concurrency    int
+	logger         *logrus.Entry
+}
+
+// ExternalTarget 一个外部目标
+type ExternalTarget struct {
+	Name         string
+	URL          string // http(s) URL
+	ExpectStatus int    // 期望的HTTP状态码，为0时收到任何响应都算可达
+}
+
+// ExternalTargetsConfig 外部目标探测配置
+type ExternalTargetsConfig struct {
+	Agents      ProbeAgentConfig // 探测Agent（必须启用）
+	Targets     []ExternalTarget
+	Timeout     time.Duration // 单次请求超时，默认10秒
+	Concurrency int           // 同时进行的请求数，默认5
+}
+
+// NewExternalTargetsCollector 创建外部目标探测采集器
+func NewExternalTargetsCollector(k8sClient *k8s.Client, config ExternalTargetsConfig) *ExternalTargetsCollector {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Timeout > probe.MaxTimeout {
+		config.Timeout = probe.MaxTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 5
+	}
+	return &ExternalTargetsCollector{
+		k8sClient:      k8sClient,
+		agents:         probe.NewClient(config.Agents.Port, config.Agents.Token),
+		agentNamespace: config.Agents.Namespace,
+		targets:        config.Targets,
+		timeout:        config.Timeout,
+		concurrency:    config.Concurrency,
+		logger:         logging.For("metrics.external"),
+	}
+}
+
+// externalCheck 由一个节点的Agent访问一个目标
+type externalCheck struct {
+	node, agent string
+	target      int // targets 中的下标
+}
+
+// CollectExternalTargets 由每个有就绪探测Agent的节点访问所有外部目标。
+// 单次访问失败记录在该节点的结果中；未能完成所有访问时返回已有的结果和 *PartialError
+func (c *ExternalTargetsCollector) CollectExternalTargets(ctx context.Context) (*metricstypes.ExternalReachability, error) {
+	agents, err := c.k8sClient.ProbeAgents(ctx, c.agentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]string, 0, len(agents))
+	for node := range agents {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	checks := make([]externalCheck, 0, len(nodes)*len(c.targets))
+	for i := range c.targets {
+		for _, node := range nodes {
+			checks = append(checks, externalCheck{node: node, agent: agents[node], target: i})
+		}
+	}
+
+	results, err := workpool.Map(ctx, workpool.Options{Concurrency: c.concurrency}, checks,
+		func(check externalCheck) string { return c.targets[check.target].Name + "@" + check.node },
+		func(ctx context.Context, check externalCheck) (metricstypes.ExternalCheck, error) {
+			return c.check(ctx, check), nil
+		})
+	if err != nil {
+		c.logger.Warnf("External target checks did not complete: %v", err)
+		err = partial([]error{err})
+	}
+
+	byTarget := make([][]metricstypes.ExternalCheck, len(c.targets))
+	for i, result := range results {
+		if result.Node != "" {
+			byTarget[checks[i].target] = append(byTarget[checks[i].target], result)
+		}
+	}
+	reachability := &metricstypes.ExternalReachability{Timestamp: time.Now(), Nodes: nodes, Targets: make([]metricstypes.ExternalTarget, len(c.targets))}
+	for i, target := range c.targets {
+		reachability.Targets[i] = metricstypes.NewExternalTarget(target.Name, target.URL, byTarget[i])
+		if summary := reachability.Targets[i]; summary.Unreachable > 0 {
+			c.logger.Debugf("External target %s unreachable from %d of %d nodes", target.Name, summary.Unreachable, len(summary.Checks))
+		}
+	}
+	return reachability, err
+}
+
+// check 由节点上的Agent访问目标
+func (c *ExternalTargetsCollector) check(ctx context.Context, check externalCheck) metricstypes.ExternalCheck {
+	target := c.targets[check.target]
+	result := metricstypes.ExternalCheck{Node: check.node}
+	probed, err := c.agents.Probe(ctx, check.agent, probe.Request{
+		Method:       probe.MethodExternal,
+		Target:       target.URL,
+		ExpectStatus: target.ExpectStatus,
+		TimeoutMs:    int(c.timeout / time.Millisecond),
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Reachable = probed.Success
+	result.StatusCode = probed.StatusCode
+	result.DNSTime = probed.DNSTime
+	result.ConnectTime = probed.ConnectTime
+	result.TLSTime = probed.TLSTime
+	result.Total = probed.RTT
+	result.Addresses = probed.Addresses
+	result.CertExpiry = probed.CertExpiry
+	result.FailedPhase = probed.FailedPhase
+	result.Error = probed.Error
+	return result
+}
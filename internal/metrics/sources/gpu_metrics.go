@@ -0,0 +1,338 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DCGM Exporter 导出的指标名
+const (
+	dcgmGPUUtil    = "DCGM_FI_DEV_GPU_UTIL"    // 使用率 (%)
+	dcgmFBUsed     = "DCGM_FI_DEV_FB_USED"     // 已用显存 (MiB)
+	dcgmFBFree     = "DCGM_FI_DEV_FB_FREE"     // 空闲显存 (MiB)
+	dcgmFBReserved = "DCGM_FI_DEV_FB_RESERVED" // 驱动保留显存 (MiB)
+	dcgmFBTotal    = "DCGM_FI_DEV_FB_TOTAL"    // 总显存 (MiB)，较新版本才有
+)
+
+// dcgmMaxBody 单个Exporter响应的最大长度
+const dcgmMaxBody = 8 << 20
+
+// GPUCollectorConfig GPU采集器配置
+type GPUCollectorConfig struct {
+	Namespace   string            // DCGM Exporter所在的namespace（默认 gpu-operator）
+	Selector    string            // DCGM Exporter Pod的label selector（默认 app=nvidia-dcgm-exporter）
+	Port        int               // Exporter端口（默认9400）
+	Endpoints   map[string]string // 节点名 -> 指标地址，配置后不再发现Exporter Pod
+	Timeout     time.Duration     // 单次拉取超时
+	Concurrency int               // 同时拉取的Exporter数量（默认10）
+}
+
+// GPUMetricsCollector 从DCGM Exporter拉取每个GPU的使用率和显存
+type GPUMetricsCollector struct {
+	kubeClient  *kubernetes.Clientset
+	config      GPUCollectorConfig
+	httpClient  *http.Client
+	logger      *logrus.Entry
+	permissions *PermissionTracker
+}
+
+// dcgmTarget 一个Exporter及其所在节点
+type dcgmTarget struct {
+	node string
+	url  string
+}
+
+// NewGPUMetricsCollector 创建GPU指标采集器
+func NewGPUMetricsCollector(kubeClient *kubernetes.Clientset, config GPUCollectorConfig) *GPUMetricsCollector {
+	if config.Namespace == "" {
+		config.Namespace = "gpu-operator"
+	}
+	if config.Selector == "" {
+		config.Selector = "app=nvidia-dcgm-exporter"
+	}
+	if config.Port <= 0 {
+		config.Port = 9400
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 10
+	}
+
+	return &GPUMetricsCollector{
+		kubeClient: kubeClient,
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		logger:     logging.For("metrics.gpu"),
+	}
+}
+
+// SetPermissionTracker 设置权限跟踪器（无法列出Exporter Pod时跳过）
+func (c *GPUMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
+// CollectGPUMetrics 拉取所有Exporter，返回 节点名 -> 按GPU编号排序的设备指标（部分Exporter失败时返回 *PartialError）
+func (c *GPUMetricsCollector) CollectGPUMetrics(ctx context.Context) (map[string][]metricstypes.GPUDevice, error) {
+	result := make(map[string][]metricstypes.GPUDevice)
+
+	targets, err := c.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		c.logger.Debug("No DCGM exporters found")
+		return result, nil
+	}
+
+	devices, err := workpool.Map(ctx, workpool.Options{Concurrency: c.config.Concurrency}, targets,
+		func(t dcgmTarget) string { return t.node },
+		c.scrape)
+	if err != nil {
+		c.logger.Warnf("Failed to scrape %d DCGM exporter(s): %v", workpool.Failed(err), err)
+		if workpool.Failed(err) == len(targets) {
+			return result, fmt.Errorf("failed to scrape DCGM exporters: %w", err)
+		}
+		err = partial([]error{err})
+	}
+	for i, list := range devices {
+		if len(list) > 0 {
+			result[targets[i].node] = list
+		}
+	}
+
+	c.logger.Debugf("Collected GPU metrics from %d/%d DCGM exporters", len(result), len(targets))
+	return result, err
+}
+
+// targets 返回配置的Exporter地址，未配置时列出各节点上运行的Exporter Pod
+func (c *GPUMetricsCollector) targets(ctx context.Context) ([]dcgmTarget, error) {
+	if len(c.config.Endpoints) > 0 {
+		targets := make([]dcgmTarget, 0, len(c.config.Endpoints))
+		for node, url := range c.config.Endpoints {
+			targets = append(targets, dcgmTarget{node: node, url: url})
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i].node < targets[j].node })
+		return targets, nil
+	}
+
+	namespace := c.config.Namespace
+	if !c.permissions.Allow(CollectorGPU, "list", "", "pods", namespace) {
+		return nil, nil
+	}
+	pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: c.config.Selector,
+		FieldSelector: "status.phase=Running",
+	})
+	if c.permissions.Observe(CollectorGPU, "list", "", "pods", namespace, err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DCGM exporter pods: %w", err)
+	}
+
+	targets := make([]dcgmTarget, 0, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.PodIP == "" {
+			continue
+		}
+		targets = append(targets, dcgmTarget{
+			node: pod.Spec.NodeName,
+			url:  fmt.Sprintf("http://%s/metrics", podAddress(pod, c.config.Port)),
+		})
+	}
+	return targets, nil
+}
+
+// podAddress Pod IP和端口（IPv6地址加方括号）
+func podAddress(pod *corev1.Pod, port int) string {
+	ip := pod.Status.PodIP
+	if strings.Contains(ip, ":") {
+		ip = "[" + ip + "]"
+	}
+	return fmt.Sprintf("%s:%d", ip, port)
+}
+
+// scrape 拉取并解析一个Exporter的指标
+func (c *GPUMetricsCollector) scrape(ctx context.Context, target dcgmTarget) ([]metricstypes.GPUDevice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape %s: %w", target.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", target.url, resp.StatusCode)
+	}
+	return parseDCGMMetrics(io.LimitReader(resp.Body, dcgmMaxBody))
+}
+
+// parseDCGMMetrics 解析DCGM Exporter的Prometheus文本格式输出，按 gpu 标签汇总每个GPU的指标
+func parseDCGMMetrics(r io.Reader) ([]metricstypes.GPUDevice, error) {
+	type gpuValues struct {
+		device                      metricstypes.GPUDevice
+		used, free, reserved, total float64
+		hasUsed, hasFree, hasTotal  bool
+	}
+	gpus := make(map[int]*gpuValues)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, labels, value, ok := parseSampleLine(line)
+		if !ok || !strings.HasPrefix(name, "DCGM_FI_DEV_") {
+			continue
+		}
+		index, err := strconv.Atoi(labels["gpu"])
+		if err != nil {
+			continue
+		}
+		gpu := gpus[index]
+		if gpu == nil {
+			gpu = &gpuValues{device: metricstypes.GPUDevice{Index: index}}
+			gpus[index] = gpu
+		}
+		if model := labels["modelName"]; model != "" {
+			gpu.device.Model = model
+		}
+		if pod := dcgmPod(labels); pod != "" && !containsString(gpu.device.Pods, pod) {
+			gpu.device.Pods = append(gpu.device.Pods, pod)
+		}
+
+		switch name {
+		case dcgmGPUUtil:
+			gpu.device.Utilization = value
+		case dcgmFBUsed:
+			gpu.used, gpu.hasUsed = value, true
+		case dcgmFBFree:
+			gpu.free, gpu.hasFree = value, true
+		case dcgmFBReserved:
+			gpu.reserved = value
+		case dcgmFBTotal:
+			gpu.total, gpu.hasTotal = value, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read DCGM metrics: %w", err)
+	}
+
+	devices := make([]metricstypes.GPUDevice, 0, len(gpus))
+	for _, gpu := range gpus {
+		gpu.device.MemoryUsed = int64(gpu.used)
+		switch {
+		case gpu.hasTotal:
+			gpu.device.MemoryTotal = int64(gpu.total)
+		case gpu.hasUsed && gpu.hasFree:
+			gpu.device.MemoryTotal = int64(gpu.used + gpu.free + gpu.reserved)
+		}
+		sort.Strings(gpu.device.Pods)
+		devices = append(devices, gpu.device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Index < devices[j].Index })
+	return devices, nil
+}
+
+// dcgmPod 样本所属的Pod（namespace/name）。Exporter开启Kubernetes映射后为分配到该GPU的Pod
+// 添加 pod/namespace 标签（旧版本为 pod_name/pod_namespace），GPU共享时每个Pod各有一条样本
+func dcgmPod(labels map[string]string) string {
+	name, namespace := labels["pod"], labels["namespace"]
+	if name == "" {
+		name, namespace = labels["pod_name"], labels["pod_namespace"]
+	}
+	if name == "" || namespace == "" {
+		return ""
+	}
+	return namespace + "/" + name
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSampleLine 解析一行样本：name{label="value",...} value [timestamp]
+func parseSampleLine(line string) (string, map[string]string, float64, bool) {
+	labels := make(map[string]string)
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return "", nil, 0, false
+	}
+	name, rest := line[:nameEnd], line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		i := 1
+		for i < len(rest) && rest[i] != '}' {
+			eq := strings.IndexByte(rest[i:], '=')
+			if eq < 0 || i+eq+1 >= len(rest) || rest[i+eq+1] != '"' {
+				return "", nil, 0, false
+			}
+			key := strings.TrimSpace(strings.TrimLeft(rest[i:i+eq], ","))
+			var value strings.Builder
+			j := i + eq + 2
+			for ; j < len(rest) && rest[j] != '"'; j++ {
+				if rest[j] == '\\' && j+1 < len(rest) {
+					j++
+					switch rest[j] {
+					case 'n':
+						value.WriteByte('\n')
+					default:
+						value.WriteByte(rest[j])
+					}
+					continue
+				}
+				value.WriteByte(rest[j])
+			}
+			if j >= len(rest) {
+				return "", nil, 0, false
+			}
+			labels[key] = value.String()
+			i = j + 1
+			for i < len(rest) && (rest[i] == ',' || rest[i] == ' ') {
+				i++
+			}
+		}
+		if i >= len(rest) {
+			return "", nil, 0, false
+		}
+		rest = rest[i+1:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, false
+	}
+	return name, labels, value, true
+}
@@ -2,13 +2,18 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
 	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -16,29 +21,70 @@ import (
 
 // NetworkMetricsCollector 网络指标采集器
 type NetworkMetricsCollector struct {
-	kubeClient *kubernetes.Clientset
-	k8sClient  *k8s.Client
-	namespaces []string
-	logger     *logrus.Logger
+	kubeClient  *kubernetes.Clientset
+	k8sClient   *k8s.Client
+	namespaces  []string
+	logger      *logrus.Entry
+	permissions *PermissionTracker
 
 	// 配置
-	maxPodPairs     int           // 最大测试Pod对数量（避免过多测试）
-	testTimeout     time.Duration // 单次测试超时时间
-	enableAutoTest  bool          // 是否自动选择测试对象
+	pairs          *pairSelector // 选择测试的Pod对
+	testTimeout    time.Duration // 单次测试超时时间
+	budget         time.Duration // 每轮自动测试的时间预算，为0时不限制
+	enableAutoTest bool          // 是否自动选择测试对象
+	concurrency    int           // 并发测试数
+	bandwidth      *k8s.BandwidthOptions
+
+	// 探测Agent（为nil时exec进入应用Pod测试）
+	agents         *probe.Client
+	agentNamespace string
+	dnsName        string
 }
 
 // NetworkCollectorConfig 网络采集器配置
 type NetworkCollectorConfig struct {
 	Namespaces     []string
-	MaxPodPairs    int           // 默认10对
-	TestTimeout    time.Duration // 默认10秒
-	EnableAutoTest bool          // 默认true
+	MaxPodPairs    int                   // 默认10对
+	Strategy       string                // Pod对的选择策略（PairStrategy*），默认 node_pair
+	Seed           int64                 // 选择代表Pod和随机抽样的种子，为0时随机
+	TestTimeout    time.Duration         // 默认10秒
+	Budget         time.Duration         // 每轮自动测试的时间预算，超出时取消剩余的测试，为0时不限制
+	EnableAutoTest bool                  // 默认true
+	Concurrency    int                   // 默认3，避免同时exec过多Pod
+	Bandwidth      *k8s.BandwidthOptions // 连通的Pod对额外进行iperf3带宽测试，为nil时不测试
+	Agents         *ProbeAgentConfig     // 从源Pod所在节点的探测Agent发起测试，为nil时exec进入应用Pod
+}
+
+// PodTestOptions 按需测试的方法和次数，零值为自动采集时的默认测试
+type PodTestOptions struct {
+	Methods      []string // ping、tcp、udp、http、dns（udp和dns需要探测Agent）
+	Count        int      // ping/tcp/udp的次数，默认3
+	Port         int      // tcp/udp的目标端口，默认取目标Pod声明的第一个对应协议的端口
+	AllowNoReply bool     // udp：目标不回复也算成功（服务不做echo），只有端口不可达算失败
+}
+
+// portFor 返回tcp/udp测试使用的目标端口
+func (o PodTestOptions) portFor(pair PodPair, method string) int {
+	if o.Port > 0 {
+		return o.Port
+	}
+	if method == probe.MethodUDP {
+		return pair.TargetUDPPort
+	}
+	return pair.TargetPort
+}
+
+// ProbeAgentConfig 探测Agent配置
+type ProbeAgentConfig struct {
+	Namespace string // Agent DaemonSet所在命名空间
+	Port      int    // Agent端口（默认9091）
+	Token     string // 调用Agent的Bearer token
+	DNSName   string // DNS测试解析的域名，为空时不测试DNS
 }
 
 // NewNetworkMetricsCollector 创建网络指标采集器
 func NewNetworkMetricsCollector(kubeClient *kubernetes.Clientset, k8sClient *k8s.Client, config NetworkCollectorConfig) *NetworkMetricsCollector {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("metrics.network")
 
 	// 设置默认值
 	if config.MaxPodPairs == 0 {
@@ -50,19 +96,36 @@ func NewNetworkMetricsCollector(kubeClient *kubernetes.Clientset, k8sClient *k8s
 	if len(config.Namespaces) == 0 {
 		config.Namespaces = []string{"default"}
 	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 3
+	}
 
-	return &NetworkMetricsCollector{
+	collector := &NetworkMetricsCollector{
 		kubeClient:     kubeClient,
 		k8sClient:      k8sClient,
 		namespaces:     config.Namespaces,
 		logger:         logger,
-		maxPodPairs:    config.MaxPodPairs,
+		pairs:          newPairSelector(config.Strategy, config.MaxPodPairs, config.Seed),
 		testTimeout:    config.TestTimeout,
+		budget:         config.Budget,
 		enableAutoTest: config.EnableAutoTest,
+		concurrency:    config.Concurrency,
+		bandwidth:      config.Bandwidth,
 	}
+	if config.Agents != nil {
+		collector.agents = probe.NewClient(config.Agents.Port, config.Agents.Token)
+		collector.agentNamespace = config.Agents.Namespace
+		collector.dnsName = config.Agents.DNSName
+	}
+	return collector
+}
+
+// SetPermissionTracker 设置权限跟踪器（权限不足的命名空间降级跳过）
+func (c *NetworkMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
 }
 
-// CollectNetworkMetrics 采集网络指标
+// CollectNetworkMetrics 采集网络指标（测试未全部完成时返回已完成的结果和 *PartialError）
 func (c *NetworkMetricsCollector) CollectNetworkMetrics(ctx context.Context) ([]*metricstypes.NetworkMetrics, error) {
 	c.logger.Debug("Collecting network metrics...")
 
@@ -80,43 +143,51 @@ func (c *NetworkMetricsCollector) CollectNetworkMetrics(ctx context.Context) ([]
 	c.logger.Infof("Selected %d pod pairs for network testing", len(podPairs))
 
 	// 2. 并发测试所有Pod对
-	results := make([]*metricstypes.NetworkMetrics, 0, len(podPairs))
-	resultsChan := make(chan *metricstypes.NetworkMetrics, len(podPairs))
-	var wg sync.WaitGroup
-
-	// 限制并发数，避免过载
-	semaphore := make(chan struct{}, 3)
-
-	for _, pair := range podPairs {
-		wg.Add(1)
-		go func(p PodPair) {
-			defer wg.Done()
+	results, err := c.testPairs(ctx, podPairs, c.probeAgents(ctx))
+	c.logger.Infof("Network metrics collection completed: %d tests", len(results))
+	return results, err
+}
 
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+// ErrBudgetExceeded 本轮网络测试超出了时间预算，剩余的Pod对没有测试
+var ErrBudgetExceeded = errors.New("network test cycle budget exceeded")
 
-			// 执行测试
-			metric := c.testPodPair(ctx, p)
-			resultsChan <- metric
-		}(pair)
+// testPairs 以有限并发测试Pod对（testPodPair 自带单次测试超时，失败结果记录在指标中）。
+// 超出时间预算时取消进行中的测试并不再开始新的测试，被中断和未开始的Pod对不计入结果（不记为不连通），
+// 返回已完成的结果和包含 ErrBudgetExceeded 的 *PartialError
+func (c *NetworkMetricsCollector) testPairs(ctx context.Context, pairs []PodPair, agents map[string]string) ([]*metricstypes.NetworkMetrics, error) {
+	testCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.budget > 0 {
+		testCtx, cancel = context.WithTimeout(ctx, c.budget)
 	}
+	defer cancel()
 
-	// 等待所有测试完成
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	metrics, err := workpool.Map(testCtx, workpool.Options{Concurrency: c.concurrency}, pairs, PodPair.String,
+		func(ctx context.Context, p PodPair) (*metricstypes.NetworkMetrics, error) {
+			metric := c.testPodPair(ctx, p, agents, PodTestOptions{})
+			if ctx.Err() != nil {
+				// 测试被中途取消，结果不完整
+				return nil, ctx.Err()
+			}
+			return metric, nil
+		})
 
-	// 收集结果
-	for metric := range resultsChan {
+	results := make([]*metricstypes.NetworkMetrics, 0, len(metrics))
+	for _, metric := range metrics {
 		if metric != nil {
 			results = append(results, metric)
 		}
 	}
 
-	c.logger.Infof("Network metrics collection completed: %d tests", len(results))
-	return results, nil
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(testCtx.Err(), context.DeadlineExceeded) {
+			c.logger.Warnf("Network test cycle exceeded its %s budget: tested %d of %d pod pairs, skipped the rest", c.budget, len(results), len(pairs))
+			err = fmt.Errorf("%w after %s (tested %d of %d pod pairs)", ErrBudgetExceeded, c.budget, len(results), len(pairs))
+		} else {
+			c.logger.Warnf("Network tests did not complete: %v", err)
+		}
+		err = partial([]error{err})
+	}
+	return results, err
 }
 
 // PodPair 表示需要测试的Pod对
@@ -124,9 +195,48 @@ type PodPair struct {
 	SourceNamespace string
 	SourcePod       string
 	SourceIP        string
+	SourceNode      string
 	TargetNamespace string
 	TargetPod       string
 	TargetIP        string
+	TargetPort      int // 目标Pod声明的第一个TCP端口（探测Agent的tcp测试），没有时为0
+	TargetHTTPPort  int // 目标Pod的HTTP端口（80或8080），没有时为0
+	TargetUDPPort   int // 目标Pod声明的第一个UDP端口，没有时为0
+}
+
+// newPodPair 由两个Pod构建测试对
+func newPodPair(source, target *corev1.Pod) PodPair {
+	pair := PodPair{
+		SourceNamespace: source.Namespace,
+		SourcePod:       source.Name,
+		SourceIP:        source.Status.PodIP,
+		SourceNode:      source.Spec.NodeName,
+		TargetNamespace: target.Namespace,
+		TargetPod:       target.Name,
+		TargetIP:        target.Status.PodIP,
+	}
+	for _, container := range target.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Protocol == corev1.ProtocolUDP && pair.TargetUDPPort == 0 {
+				pair.TargetUDPPort = int(port.ContainerPort)
+			}
+			if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+				continue
+			}
+			if pair.TargetPort == 0 {
+				pair.TargetPort = int(port.ContainerPort)
+			}
+			if pair.TargetHTTPPort == 0 && (port.ContainerPort == 80 || port.ContainerPort == 8080) {
+				pair.TargetHTTPPort = int(port.ContainerPort)
+			}
+		}
+	}
+	return pair
+}
+
+// String 返回Pod对的可读名称
+func (p PodPair) String() string {
+	return fmt.Sprintf("%s/%s->%s/%s", p.SourceNamespace, p.SourcePod, p.TargetNamespace, p.TargetPod)
 }
 
 // selectPodPairs 选择需要测试的Pod对
@@ -135,13 +245,32 @@ func (c *NetworkMetricsCollector) selectPodPairs(ctx context.Context) ([]PodPair
 		return []PodPair{}, nil
 	}
 
+	// 全局关闭exec测试时不再选择Pod对
+	var policy *k8s.ExecPolicy
+	if c.k8sClient != nil {
+		policy = c.k8sClient.ExecPolicy()
+	}
+	if !policy.Enabled() && c.agents == nil {
+		return []PodPair{}, nil
+	}
+
 	var allPods []*corev1.Pod
 
-	// 获取所有命名空间的Pod
+	// 获取所有命名空间的Pod，跳过通过注解退出测试的命名空间
 	for _, namespace := range c.namespaces {
+		if c.namespaceOptedOut(ctx, namespace) {
+			c.logger.Debugf("Namespace %s opted out of network testing", namespace)
+			continue
+		}
+		if !c.permissions.Allow(CollectorNetwork, "list", "", "pods", namespace) {
+			continue
+		}
 		pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 			FieldSelector: "status.phase=Running",
 		})
+		if c.permissions.Observe(CollectorNetwork, "list", "", "pods", namespace, err) {
+			continue
+		}
 		if err != nil {
 			c.logger.Warnf("Failed to list pods in namespace %s: %v", namespace, err)
 			continue
@@ -149,8 +278,8 @@ func (c *NetworkMetricsCollector) selectPodPairs(ctx context.Context) ([]PodPair
 
 		for i := range pods.Items {
 			pod := &pods.Items[i]
-			// 只选择有IP的Running Pod
-			if pod.Status.PodIP != "" {
+			// 只选择有IP的Running Pod，跳过带宽测试创建的临时服务端Pod
+			if pod.Status.PodIP != "" && pod.Labels[k8s.BandwidthServerLabel] == "" && !k8s.IsProbeAgent(pod) {
 				allPods = append(allPods, pod)
 			}
 		}
@@ -160,53 +289,76 @@ func (c *NetworkMetricsCollector) selectPodPairs(ctx context.Context) ([]PodPair
 		return []PodPair{}, nil
 	}
 
-	// 选择Pod对进行测试
-	// 策略：选择不同节点、不同命名空间的Pod对，更有代表性
-	pairs := []PodPair{}
-
-	for i := 0; i < len(allPods) && len(pairs) < c.maxPodPairs; i++ {
-		for j := i + 1; j < len(allPods) && len(pairs) < c.maxPodPairs; j++ {
-			source := allPods[i]
-			target := allPods[j]
-
-			// 优先选择不同节点的Pod
-			if source.Spec.NodeName != target.Spec.NodeName {
-				pairs = append(pairs, PodPair{
-					SourceNamespace: source.Namespace,
-					SourcePod:       source.Name,
-					SourceIP:        source.Status.PodIP,
-					TargetNamespace: target.Namespace,
-					TargetPod:       target.Name,
-					TargetIP:        target.Status.PodIP,
-				})
-			}
-		}
+	var services map[string][]string
+	if c.pairs.strategy == PairStrategyServicePair {
+		services = podServices(allPods, c.listServices(ctx))
 	}
+	return c.pairs.selectPairs(allPods, services), nil
+}
 
-	// 如果没找到跨节点的Pod对，就选择同节点的
-	if len(pairs) == 0 {
-		for i := 0; i < len(allPods) && len(pairs) < c.maxPodPairs; i++ {
-			for j := i + 1; j < len(allPods) && len(pairs) < c.maxPodPairs; j++ {
-				source := allPods[i]
-				target := allPods[j]
-
-				pairs = append(pairs, PodPair{
-					SourceNamespace: source.Namespace,
-					SourcePod:       source.Name,
-					SourceIP:        source.Status.PodIP,
-					TargetNamespace: target.Namespace,
-					TargetPod:       target.Name,
-					TargetIP:        target.Status.PodIP,
-				})
-			}
+// namespaceOptedOut 命名空间是否带有退出网络测试的注解。无法读取命名空间时不跳过（Pod上的注解仍然有效）
+func (c *NetworkMetricsCollector) namespaceOptedOut(ctx context.Context, namespace string) bool {
+	if !c.permissions.Allow(CollectorNetwork, "get", "", "namespaces", "") {
+		return false
+	}
+	ns, err := c.kubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if c.permissions.Observe(CollectorNetwork, "get", "", "namespaces", "", err) {
+		return false
+	}
+	if err != nil {
+		c.logger.Debugf("Failed to get namespace %s: %v", namespace, err)
+		return false
+	}
+	return k8s.NamespaceProbeOptedOut(ns)
+}
+
+// listServices 列出各命名空间的Service（service_pair 策略），失败的命名空间跳过
+func (c *NetworkMetricsCollector) listServices(ctx context.Context) []corev1.Service {
+	var services []corev1.Service
+	for _, namespace := range c.namespaces {
+		if !c.permissions.Allow(CollectorNetwork, "list", "", "services", namespace) {
+			continue
+		}
+		list, err := c.kubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if c.permissions.Observe(CollectorNetwork, "list", "", "services", namespace, err) {
+			continue
+		}
+		if err != nil {
+			c.logger.Warnf("Failed to list services in namespace %s: %v", namespace, err)
+			continue
 		}
+		services = append(services, list.Items...)
+	}
+	return services
+}
+
+// orientPair 调整Pod对方向，使发起方为未退出测试的Pod；两个Pod都退出时返回false
+func orientPair(a, b *corev1.Pod) (*corev1.Pod, *corev1.Pod, bool) {
+	switch {
+	case !k8s.ProbeOptedOut(a):
+		return a, b, true
+	case !k8s.ProbeOptedOut(b):
+		return b, a, true
+	default:
+		return nil, nil, false
 	}
+}
 
-	return pairs, nil
+// probeAgents 列出就绪的探测Agent（节点名 -> IP），未启用或列出失败时为nil（回退到exec测试）
+func (c *NetworkMetricsCollector) probeAgents(ctx context.Context) map[string]string {
+	if c.agents == nil || c.k8sClient == nil {
+		return nil
+	}
+	agents, err := c.k8sClient.ProbeAgents(ctx, c.agentNamespace)
+	if err != nil {
+		c.logger.Warnf("Failed to list probe agents, falling back to exec: %v", err)
+		return nil
+	}
+	return agents
 }
 
-// testPodPair 测试单个Pod对的网络连通性
-func (c *NetworkMetricsCollector) testPodPair(ctx context.Context, pair PodPair) *metricstypes.NetworkMetrics {
+// testPodPair 测试单个Pod对的网络连通性：源Pod所在节点有探测Agent时由Agent发起，否则exec进入源Pod
+func (c *NetworkMetricsCollector) testPodPair(ctx context.Context, pair PodPair, agents map[string]string, opts PodTestOptions) *metricstypes.NetworkMetrics {
 	testCtx, cancel := context.WithTimeout(ctx, c.testTimeout)
 	defer cancel()
 
@@ -218,6 +370,18 @@ func (c *NetworkMetricsCollector) testPodPair(ctx context.Context, pair PodPair)
 		TestMethod: "mixed",
 	}
 
+	if agentIP, ok := agents[pair.SourceNode]; ok {
+		c.testWithAgent(testCtx, agentIP, pair, metric, opts)
+		if metric.Connected && c.bandwidth != nil {
+			c.testBandwidth(ctx, k8s.NewRTTTester(c.k8sClient), metric)
+		}
+		return metric
+	}
+	if c.agents != nil {
+		c.logger.Debugf("No probe agent on node %s, testing %s -> %s via exec", pair.SourceNode, metric.SourcePod, metric.TargetPod)
+	}
+	metric.ProbeSource = "exec"
+
 	// 使用RTT Tester进行测试
 	if c.k8sClient == nil {
 		metric.Error = "K8s client not available"
@@ -229,34 +393,53 @@ func (c *NetworkMetricsCollector) testPodPair(ctx context.Context, pair PodPair)
 	// 测试Pod连通性（包含ping和HTTP测试）
 	c.logger.Debugf("Testing connectivity: %s -> %s", metric.SourcePod, metric.TargetPod)
 
-	testResult, err := tester.TestPodConnectivity(testCtx, metric.SourcePod, metric.TargetPod)
+	rttOpts := k8s.RTTOptions{Count: opts.Count, Port: opts.portFor(pair, probe.MethodTCP), HTTPPort: pair.TargetHTTPPort}
+	for _, method := range opts.Methods {
+		if method == probe.MethodDNS || method == probe.MethodUDP {
+			metric.Tests = append(metric.Tests, metricstypes.NetworkTest{Method: method, Error: method + " tests require probe agents"})
+			continue
+		}
+		rttOpts.Methods = append(rttOpts.Methods, method)
+	}
+	if len(opts.Methods) > 0 && len(rttOpts.Methods) == 0 {
+		metric.Error = "all tests failed"
+		return metric
+	}
+
+	testResult, err := tester.TestPodConnectivityWithOptions(testCtx, metric.SourcePod, metric.TargetPod, rttOpts)
 	if err != nil {
 		metric.Error = fmt.Sprintf("connectivity test failed: %v", err)
 		c.logger.Warnf("Connectivity test failed for %s -> %s: %v", metric.SourcePod, metric.TargetPod, err)
 		return metric
 	}
+	for _, rtt := range testResult.RTTResults {
+		metric.Tests = append(metric.Tests, metricstypes.NetworkTest{
+			Method:     rtt.Method,
+			Success:    rtt.Success,
+			RTT:        rtt.RTT,
+			PacketLoss: rtt.PacketLoss,
+			Error:      rtt.ErrorMessage,
+		})
+	}
 
 	// 转换测试结果
 	if testResult.SuccessRate > 0 {
 		metric.Connected = true
 		metric.RTT = testResult.AverageRTT
 
-		// 从RTT结果中获取丢包率（取ping测试的丢包率）
-		for _, rtt := range testResult.RTTResults {
-			if rtt.Method == "ping" && rtt.Success {
+		// 从RTT结果中获取丢包率（取ping测试的丢包率，没有ping时取tcp建连）
+		for _, method := range []string{probe.MethodPing, probe.MethodTCP} {
+			if rtt := successfulTest(testResult.RTTResults, method); rtt != nil {
 				metric.PacketLoss = rtt.PacketLoss
-				metric.TestMethod = "ping"
+				metric.TestMethod = method
 				break
 			}
 		}
 
 		// 如果有HTTP测试成功，使用HTTP的RTT
-		for _, rtt := range testResult.RTTResults {
-			if rtt.Method == "http" && rtt.Success {
-				metric.RTT = rtt.RTT
-				metric.TestMethod = "http"
-				break
-			}
+		if rtt := successfulTest(testResult.RTTResults, probe.MethodHTTP); rtt != nil {
+			metric.RTT = rtt.RTT
+			metric.TestMethod = probe.MethodHTTP
 		}
 
 		c.logger.Debugf("Test success: %s -> %s, RTT=%.2fms, Method=%s, Loss=%.1f%%",
@@ -266,9 +449,148 @@ func (c *NetworkMetricsCollector) testPodPair(ctx context.Context, pair PodPair)
 		c.logger.Warnf("All tests failed for %s -> %s", metric.SourcePod, metric.TargetPod)
 	}
 
+	if metric.Connected && c.bandwidth != nil {
+		c.testBandwidth(ctx, tester, metric)
+	}
+
 	return metric
 }
 
+// successfulTest 返回指定方法第一个成功的结果
+func successfulTest(results []models.RTTResult, method string) *models.RTTResult {
+	for i := range results {
+		if results[i].Method == method && results[i].Success {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+// testBandwidth 对连通的Pod对进行iperf3带宽测试（使用单独的超时），失败时记录在 BandwidthError 中
+func (c *NetworkMetricsCollector) testBandwidth(ctx context.Context, tester *k8s.RTTTester, metric *metricstypes.NetworkMetrics) {
+	ctx, cancel := context.WithTimeout(ctx, c.bandwidth.Timeout())
+	defer cancel()
+
+	result, err := tester.TestBandwidth(ctx, metric.SourcePod, metric.TargetPod, *c.bandwidth)
+	if err != nil {
+		metric.BandwidthError = err.Error()
+		c.logger.Warnf("Bandwidth test failed for %s -> %s: %v", metric.SourcePod, metric.TargetPod, err)
+		return
+	}
+	metric.Bandwidth = result.Bandwidth
+	c.logger.Debugf("Bandwidth %s -> %s: %.1f Mbps (%d retransmits)",
+		metric.SourcePod, metric.TargetPod, result.Bandwidth, result.Retransmits)
+}
+
+// testWithAgent 由探测Agent对目标Pod IP依次进行ping、tcp、http和DNS测试。RTT和丢包率优先取ping的结果
+// （ICMP被禁止时取tcp建连），目标有HTTP端口时RTT使用HTTP测试的耗时，与exec测试的结果一致
+func (c *NetworkMetricsCollector) testWithAgent(ctx context.Context, agentIP string, pair PodPair, metric *metricstypes.NetworkMetrics, opts PodTestOptions) {
+	metric.ProbeSource = "agent"
+	requests, errs := agentRequests(pair, c.dnsName, opts)
+	for _, msg := range errs {
+		metric.Tests = append(metric.Tests, metricstypes.NetworkTest{Method: msg.method, Error: msg.err})
+	}
+
+	results := make(map[string]*probe.Result, len(requests))
+	var failures []string
+	for _, req := range requests {
+		result, err := c.agents.Probe(ctx, agentIP, req)
+		if err != nil {
+			failures = append(failures, err.Error())
+			metric.Tests = append(metric.Tests, metricstypes.NetworkTest{Method: req.Method, Error: err.Error()})
+			continue
+		}
+		results[req.Method] = result
+		metric.Tests = append(metric.Tests, metricstypes.NetworkTest{
+			Method:     req.Method,
+			Success:    result.Success,
+			RTT:        result.RTT,
+			PacketLoss: result.PacketLoss,
+			Error:      result.Error,
+		})
+		if !result.Success && result.Error != "" {
+			failures = append(failures, result.Error)
+		}
+	}
+
+	if dns := results[probe.MethodDNS]; dns != nil && dns.Success {
+		metric.DNSLatency = dns.RTT
+	}
+	for _, method := range []string{probe.MethodPing, probe.MethodTCP} {
+		if result := results[method]; result != nil && result.Success {
+			metric.Connected = true
+			metric.RTT = result.RTT
+			metric.PacketLoss = result.PacketLoss
+			metric.TestMethod = method
+			break
+		}
+	}
+	if result := results[probe.MethodHTTP]; result != nil && result.Success {
+		metric.Connected = true
+		metric.RTT = result.RTT
+		metric.TestMethod = probe.MethodHTTP
+	}
+
+	if !metric.Connected {
+		metric.Error = "all tests failed"
+		if len(failures) > 0 {
+			metric.Error += ": " + strings.Join(failures, "; ")
+		}
+		c.logger.Warnf("All agent tests failed for %s -> %s: %v", metric.SourcePod, metric.TargetPod, failures)
+		return
+	}
+	c.logger.Debugf("Agent test success: %s -> %s, RTT=%.2fms, Method=%s, Loss=%.1f%%",
+		metric.SourcePod, metric.TargetPod, metric.RTT, metric.TestMethod, metric.PacketLoss)
+}
+
+// skippedTest 无法执行的测试及原因
+type skippedTest struct {
+	method, err string
+}
+
+// agentRequests 生成由探测Agent执行的测试请求。未指定方法时为默认测试（不含udp）：ping，
+// 目标声明了TCP端口时加tcp，有HTTP端口时加http，配置了域名时加dns
+func agentRequests(pair PodPair, dnsName string, opts PodTestOptions) ([]probe.Request, []skippedTest) {
+	if len(opts.Methods) == 0 {
+		requests := []probe.Request{{Method: probe.MethodPing, Target: pair.TargetIP}}
+		if pair.TargetPort > 0 {
+			requests = append(requests, probe.Request{Method: probe.MethodTCP, Target: pair.TargetIP, Port: pair.TargetPort})
+		}
+		if pair.TargetHTTPPort > 0 {
+			requests = append(requests, probe.Request{Method: probe.MethodHTTP, Target: pair.TargetIP, Port: pair.TargetHTTPPort})
+		}
+		if dnsName != "" {
+			requests = append(requests, probe.Request{Method: probe.MethodDNS, Target: dnsName, Count: 1})
+		}
+		return requests, nil
+	}
+
+	var requests []probe.Request
+	var skipped []skippedTest
+	for _, method := range opts.Methods {
+		switch method {
+		case probe.MethodPing:
+			requests = append(requests, probe.Request{Method: method, Target: pair.TargetIP, Count: opts.Count})
+		case probe.MethodTCP, probe.MethodUDP:
+			port := opts.portFor(pair, method)
+			if port == 0 {
+				skipped = append(skipped, skippedTest{method, fmt.Sprintf("no port given and the target pod declares no %s port", strings.ToUpper(method))})
+				continue
+			}
+			requests = append(requests, probe.Request{Method: method, Target: pair.TargetIP, Port: port, Count: opts.Count, AllowNoReply: opts.AllowNoReply})
+		case probe.MethodHTTP:
+			requests = append(requests, probe.Request{Method: method, Target: pair.TargetIP, Port: pair.TargetHTTPPort})
+		case probe.MethodDNS:
+			if dnsName == "" {
+				skipped = append(skipped, skippedTest{method, "no DNS name configured (metrics.probe_agents.dns_name)"})
+				continue
+			}
+			requests = append(requests, probe.Request{Method: method, Target: dnsName, Count: 1})
+		}
+	}
+	return requests, skipped
+}
+
 // hasHTTPService 检查Pod是否暴露HTTP服务
 func (c *NetworkMetricsCollector) hasHTTPService(ctx context.Context, namespace, podName string) bool {
 	pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
@@ -289,7 +611,7 @@ func (c *NetworkMetricsCollector) hasHTTPService(ctx context.Context, namespace,
 }
 
 // TestPodConnectivity 测试指定的Pod对（供API调用，实现NetworkMetricsSource接口）
-func (c *NetworkMetricsCollector) TestPodConnectivity(ctx context.Context, sourcePod, targetPod string) (*metricstypes.NetworkMetrics, error) {
+func (c *NetworkMetricsCollector) TestPodConnectivity(ctx context.Context, sourcePod, targetPod string, opts PodTestOptions) (*metricstypes.NetworkMetrics, error) {
 	// 解析Pod名称（namespace/pod）
 	sourceNs, sourceName, err := parsePodName(sourcePod)
 	if err != nil {
@@ -312,16 +634,7 @@ func (c *NetworkMetricsCollector) TestPodConnectivity(ctx context.Context, sourc
 		return nil, fmt.Errorf("failed to get target pod: %w", err)
 	}
 
-	pair := PodPair{
-		SourceNamespace: sourceNs,
-		SourcePod:       sourceName,
-		SourceIP:        sourcePodObj.Status.PodIP,
-		TargetNamespace: targetNs,
-		TargetPod:       targetName,
-		TargetIP:        targetPodObj.Status.PodIP,
-	}
-
-	return c.testPodPair(ctx, pair), nil
+	return c.testPodPair(ctx, newPodPair(sourcePodObj, targetPodObj), c.probeAgents(ctx), opts), nil
 }
 
 // parsePodName 解析Pod名称（namespace/pod-name）
@@ -0,0 +1,108 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+)
+
+func TestAgentRequests(t *testing.T) {
+	pair := PodPair{TargetIP: "10.0.0.7", TargetPort: 5432}
+
+	tests := []struct {
+		name    string
+		pair    PodPair
+		dnsName string
+		opts    PodTestOptions
+		methods []string
+		skipped []string
+	}{
+		{"defaults", pair, "kubernetes.default", PodTestOptions{}, []string{"ping", "tcp", "dns"}, nil},
+		{"defaults without ports", PodPair{TargetIP: "10.0.0.7"}, "", PodTestOptions{}, []string{"ping"}, nil},
+		{"selected methods", pair, "", PodTestOptions{Methods: []string{"http", "ping"}, Count: 5}, []string{"http", "ping"}, nil},
+		{"tcp without a declared port", PodPair{TargetIP: "10.0.0.7"}, "", PodTestOptions{Methods: []string{"tcp"}}, nil, []string{"tcp"}},
+		{"dns without a name", pair, "", PodTestOptions{Methods: []string{"dns", "ping"}}, []string{"ping"}, []string{"dns"}},
+		{"udp to a declared port", PodPair{TargetIP: "10.0.0.7", TargetUDPPort: 14550}, "", PodTestOptions{Methods: []string{"udp"}}, []string{"udp"}, nil},
+		{"udp without a port", pair, "", PodTestOptions{Methods: []string{"udp", "tcp"}}, []string{"tcp"}, []string{"udp"}},
+		{"explicit port", pair, "", PodTestOptions{Methods: []string{"udp", "tcp"}, Port: 9000}, []string{"udp", "tcp"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, skipped := agentRequests(tt.pair, tt.dnsName, tt.opts)
+
+			var methods, skippedMethods []string
+			for _, req := range requests {
+				methods = append(methods, req.Method)
+				if req.Method == probe.MethodPing && req.Count != tt.opts.Count {
+					t.Errorf("ping count = %d, want %d", req.Count, tt.opts.Count)
+				}
+				if tt.opts.Port > 0 && (req.Method == probe.MethodTCP || req.Method == probe.MethodUDP) && req.Port != tt.opts.Port {
+					t.Errorf("%s port = %d, want %d", req.Method, req.Port, tt.opts.Port)
+				}
+			}
+			for _, s := range skipped {
+				skippedMethods = append(skippedMethods, s.method)
+			}
+			if !reflect.DeepEqual(methods, tt.methods) {
+				t.Errorf("methods = %v, want %v", methods, tt.methods)
+			}
+			if !reflect.DeepEqual(skippedMethods, tt.skipped) {
+				t.Errorf("skipped = %v, want %v", skippedMethods, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestTestPairsBudget(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一个Pod对立即返回，之后的都超出预算
+		if calls.Add(1) > 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-release:
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"success":true,"rtt_ms":1.5}}`))
+	}))
+	defer agent.Close()
+	defer close(release)
+
+	_, port, _ := net.SplitHostPort(agent.Listener.Addr().String())
+	agentPort, _ := strconv.Atoi(port)
+	collector := NewNetworkMetricsCollector(nil, nil, NetworkCollectorConfig{
+		Agents:      &ProbeAgentConfig{Port: agentPort},
+		Concurrency: 1,
+		Budget:      300 * time.Millisecond,
+	})
+
+	pairs := []PodPair{
+		{SourceNamespace: "default", SourcePod: "a", SourceNode: "node-a", TargetNamespace: "default", TargetPod: "b", TargetIP: "10.0.0.2"},
+		{SourceNamespace: "default", SourcePod: "a", SourceNode: "node-a", TargetNamespace: "default", TargetPod: "c", TargetIP: "10.0.0.3"},
+		{SourceNamespace: "default", SourcePod: "a", SourceNode: "node-a", TargetNamespace: "default", TargetPod: "d", TargetIP: "10.0.0.4"},
+	}
+	start := time.Now()
+	results, err := collector.testPairs(context.Background(), pairs, map[string]string{"node-a": "127.0.0.1"})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("testPairs took %s, want it cut off by the budget", elapsed)
+	}
+	if !IsPartial(err) || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want a partial error wrapping ErrBudgetExceeded", err)
+	}
+	if len(results) != 1 || results[0].TargetPod != "default/b" || !results[0].Connected {
+		t.Fatalf("results = %+v, want only the completed default/b test", results)
+	}
+}
@@ -0,0 +1,128 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// NodeLatencyCollector 由各节点上的探测Agent互相ping，测量节点间的往返时延矩阵
+type NodeLatencyCollector struct {
+	k8sClient      *k8s.Client
+	agents         *probe.Client
+	agentNamespace string
+	count          int
+	concurrency    int
+	logger         *logrus.Entry
+}
+
+// NodeLatencyConfig 节点间时延测量配置
+type NodeLatencyConfig struct {
+	Agents      ProbeAgentConfig // 探测Agent（必须启用）
+	Count       int              // 每对节点的ping次数，默认3
+	Concurrency int              // 同时测量的节点对数，默认5
+}
+
+// NewNodeLatencyCollector 创建节点间时延采集器
+func NewNodeLatencyCollector(k8sClient *k8s.Client, config NodeLatencyConfig) *NodeLatencyCollector {
+	if config.Count <= 0 {
+		config.Count = probe.DefaultCount
+	}
+	if config.Count > probe.MaxCount {
+		config.Count = probe.MaxCount
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 5
+	}
+	return &NodeLatencyCollector{
+		k8sClient:      k8sClient,
+		agents:         probe.NewClient(config.Agents.Port, config.Agents.Token),
+		agentNamespace: config.Agents.Namespace,
+		count:          config.Count,
+		concurrency:    config.Concurrency,
+		logger:         logging.For("metrics.node_latency"),
+	}
+}
+
+// nodePair 一对有探测Agent的节点
+type nodePair struct {
+	source, sourceAgent string
+	target, targetAgent string
+}
+
+func (p nodePair) String() string {
+	return p.source + "->" + p.target
+}
+
+// CollectNodeLatency 测量所有有就绪探测Agent的节点之间（双向）的时延。
+// 单个节点对失败记录在该链路的 Error 中；未能完成所有测量时返回已有的结果和 *PartialError
+func (c *NodeLatencyCollector) CollectNodeLatency(ctx context.Context) (*metricstypes.NodeLatencyMatrix, error) {
+	agents, err := c.k8sClient.ProbeAgents(ctx, c.agentNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]string, 0, len(agents))
+	for node := range agents {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var pairs []nodePair
+	for _, source := range nodes {
+		for _, target := range nodes {
+			if source != target {
+				pairs = append(pairs, nodePair{source, agents[source], target, agents[target]})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return metricstypes.NewNodeLatencyMatrix(time.Now(), nodes, nil), nil
+	}
+
+	results, err := workpool.Map(ctx, workpool.Options{Concurrency: c.concurrency}, pairs, nodePair.String,
+		func(ctx context.Context, p nodePair) (metricstypes.NodeLatency, error) {
+			return c.measure(ctx, p), nil
+		})
+	if err != nil {
+		c.logger.Warnf("Node latency measurement did not complete: %v", err)
+		err = partial([]error{err})
+	}
+
+	links := make([]metricstypes.NodeLatency, 0, len(results))
+	for _, link := range results {
+		if link.Source != "" {
+			links = append(links, link)
+		}
+	}
+	c.logger.Debugf("Measured latency between %d nodes (%d links)", len(nodes), len(links))
+	return metricstypes.NewNodeLatencyMatrix(time.Now(), nodes, links), err
+}
+
+// measure 由源节点的Agent ping 目标节点的Agent
+func (c *NodeLatencyCollector) measure(ctx context.Context, p nodePair) metricstypes.NodeLatency {
+	link := metricstypes.NodeLatency{Source: p.source, Target: p.target}
+	result, err := c.agents.Probe(ctx, p.sourceAgent, probe.Request{Method: probe.MethodPing, Target: p.targetAgent, Count: c.count})
+	if err != nil {
+		link.Error = err.Error()
+		return link
+	}
+	link.Reachable = result.Success
+	link.RTT = result.RTT
+	link.PacketLoss = result.PacketLoss
+	if !result.Success {
+		link.Error = result.Error
+		if link.Error == "" {
+			link.Error = fmt.Sprintf("%.0f%% packet loss", result.PacketLoss)
+		}
+	}
+	return link
+}
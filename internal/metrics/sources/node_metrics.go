@@ -3,9 +3,11 @@ package sources
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
 	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,17 +16,25 @@ import (
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+// GPU相关的节点资源和标签（NVIDIA device plugin 和 GPU Feature Discovery）
+const (
+	gpuResourceName = corev1.ResourceName("nvidia.com/gpu")
+	gpuProductLabel = "nvidia.com/gpu.product"
+	gpuMemoryLabel  = "nvidia.com/gpu.memory" // 单个GPU的显存 (MB)
+	defaultGPUModel = "nvidia"
+)
+
 // NodeMetricsCollector Node指标采集器（使用K8s Metrics Server）
 type NodeMetricsCollector struct {
 	kubeClient    *kubernetes.Clientset
 	metricsClient *metricsclientset.Clientset
-	logger        *logrus.Logger
+	logger        *logrus.Entry
+	permissions   *PermissionTracker
 }
 
 // NewNodeMetricsCollector 创建Node指标采集器
 func NewNodeMetricsCollector(kubeClient *kubernetes.Clientset, metricsClient *metricsclientset.Clientset) *NodeMetricsCollector {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("metrics.node")
 
 	return &NodeMetricsCollector{
 		kubeClient:    kubeClient,
@@ -33,40 +43,57 @@ func NewNodeMetricsCollector(kubeClient *kubernetes.Clientset, metricsClient *me
 	}
 }
 
-// CollectNodeMetrics 采集所有节点的指标
+// SetPermissionTracker 设置权限跟踪器（权限不足时降级）
+func (c *NodeMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
+// CollectNodeMetrics 采集所有节点的指标（Metrics Server不可用时返回基础信息和 *PartialError）
 func (c *NodeMetricsCollector) CollectNodeMetrics(ctx context.Context) (map[string]*metricstypes.NodeMetrics, error) {
 	c.logger.Debug("Collecting node metricstypes...")
 
-	// 1. 获取所有节点的基本信息
+	// 1. 获取所有节点的基本信息（没有集群范围的nodes权限时降级为空结果）
+	result := make(map[string]*metricstypes.NodeMetrics)
+	if !c.permissions.Allow(CollectorNode, "list", "", "nodes", "") {
+		return result, nil
+	}
 	nodes, err := c.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if c.permissions.Observe(CollectorNode, "list", "", "nodes", "", err) {
+		return result, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list nodes: %w", err)
 	}
 
 	// 2. 获取节点的实时指标（CPU、内存使用情况）
-	nodeMetrics, err := c.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		c.logger.Warnf("Failed to get node metrics from metrics server: %v (metrics may be incomplete)", err)
-		// 如果Metrics Server不可用，仍然可以返回基础信息
-		nodeMetrics = &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{}}
+	var errs []error
+	nodeMetrics := &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{}}
+	if c.permissions.Allow(CollectorNode, "list", "metrics.k8s.io", "nodes", "") {
+		list, err := c.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if forbidden := c.permissions.Observe(CollectorNode, "list", "metrics.k8s.io", "nodes", "", err); err == nil {
+			nodeMetrics = list
+		} else if !forbidden {
+			// 如果Metrics Server不可用，仍然可以返回基础信息
+			c.logger.Warnf("Failed to get node metrics from metrics server: %v (metrics may be incomplete)", err)
+			errs = append(errs, fmt.Errorf("failed to get node metrics from metrics server: %w", err))
+		}
 	}
 
 	// 3. 创建指标映射（方便查找）
-	metricsMap := make(map[string]*metricsv1beta1.NodeMetrics)
+	metricsMap := make(map[string]*metricsv1beta1.NodeMetrics, len(nodeMetrics.Items))
 	for i := range nodeMetrics.Items {
 		nm := &nodeMetrics.Items[i]
 		metricsMap[nm.Name] = nm
 	}
 
 	// 4. 组合数据
-	result := make(map[string]*metricstypes.NodeMetrics)
 	for _, node := range nodes.Items {
 		nodeMetric := c.buildNodeMetrics(&node, metricsMap[node.Name])
 		result[node.Name] = nodeMetric
 	}
 
 	c.logger.Debugf("Successfully collected metrics for %d nodes", len(result))
-	return result, nil
+	return result, partial(errs)
 }
 
 // CollectSingleNodeMetrics 采集单个节点的指标
@@ -113,7 +140,7 @@ func (c *NodeMetricsCollector) buildNodeMetrics(node *corev1.Node, metric *metri
 		memoryUsage = metric.Usage.Memory().Value()
 	}
 
-	// 磁盘使用情况（从allocatable计算）
+	// 磁盘使用情况（从allocatable估算，启用kubelet Summary采集时替换为实际用量）
 	diskUsage := int64(0)
 	if ephemeralStorage := node.Status.Allocatable.StorageEphemeral(); ephemeralStorage != nil {
 		// 使用 capacity - allocatable 作为已使用量的估算
@@ -142,7 +169,19 @@ func (c *NodeMetricsCollector) buildNodeMetrics(node *corev1.Node, metric *metri
 	// 检查节点健康状态
 	healthy := true
 	var conditions []string
+	var details []metricstypes.NodeCondition
 	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case corev1.NodeReady, corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure, corev1.NodeNetworkUnavailable:
+			details = append(details, metricstypes.NodeCondition{
+				Type:               string(condition.Type),
+				Status:             string(condition.Status),
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+				LastTransitionTime: condition.LastTransitionTime.Time,
+			})
+		}
+
 		// Ready条件应该为True
 		if condition.Type == corev1.NodeReady {
 			if condition.Status != corev1.ConditionTrue {
@@ -163,6 +202,25 @@ func (c *NodeMetricsCollector) buildNodeMetrics(node *corev1.Node, metric *metri
 		}
 	}
 
+	// GPU数量来自device plugin上报的容量，型号和显存来自GFD标签（没有时使用默认值）
+	gpuCount := 0
+	if quantity, ok := node.Status.Capacity[gpuResourceName]; ok {
+		gpuCount = int(quantity.Value())
+	}
+	gpuModels := make([]string, gpuCount)
+	gpuMemoryTotal := make([]int64, 0, gpuCount)
+	model := node.Labels[gpuProductLabel]
+	if model == "" {
+		model = defaultGPUModel
+	}
+	memory, err := strconv.ParseInt(node.Labels[gpuMemoryLabel], 10, 64)
+	for i := range gpuModels {
+		gpuModels[i] = model
+		if err == nil {
+			gpuMemoryTotal = append(gpuMemoryTotal, memory)
+		}
+	}
+
 	// 获取节点标签
 	labels := make(map[string]string)
 	for k, v := range node.Labels {
@@ -189,16 +247,17 @@ func (c *NodeMetricsCollector) buildNodeMetrics(node *corev1.Node, metric *metri
 		NetworkLatency:   0,
 		NetworkBandwidth: 0,
 
-		// GPU指标暂时为0，后续通过CRD补充
-		GPUCount:       0,
-		GPUModels:      []string{},
+		// GPU使用率和已用显存由GPU采集器（DCGM Exporter）补充
+		GPUCount:       gpuCount,
+		GPUModels:      gpuModels,
 		GPUUsage:       []float64{},
-		GPUMemoryTotal: []int64{},
+		GPUMemoryTotal: gpuMemoryTotal,
 		GPUMemoryUsed:  []int64{},
 
-		Healthy:    healthy,
-		Conditions: conditions,
-		Labels:     labels,
+		Healthy:          healthy,
+		Conditions:       conditions,
+		ConditionDetails: details,
+		Labels:           labels,
 
 		CustomMetrics: make(map[string]interface{}),
 	}
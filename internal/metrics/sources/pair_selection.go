@@ -0,0 +1,205 @@
+package sources
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// 自动网络测试选择Pod对的策略
+const (
+	PairStrategyNodePair      = "node_pair"      // 每对节点选一对代表Pod（默认）
+	PairStrategyServicePair   = "service_pair"   // 每对Service选一对代表Pod，只测试属于Service的Pod
+	PairStrategyNamespacePair = "namespace_pair" // 每对命名空间选一对代表Pod
+	PairStrategyRandom        = "random"         // 随机抽样Pod对
+	PairStrategyFirst         = "first"          // 按列出顺序，优先跨节点（早期版本的行为）
+)
+
+// pairSelector 按策略从候选Pod中选择测试对。分组策略（节点、Service、命名空间）为每对分组选一对代表Pod，
+// 代表Pod和分组对的顺序每轮随机，Pod对数超过上限时各分组对被选中的机会均等
+type pairSelector struct {
+	strategy string
+	maxPairs int
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newPairSelector 创建选择器，seed 为0时使用随机种子
+func newPairSelector(strategy string, maxPairs int, seed int64) *pairSelector {
+	if strategy == "" {
+		strategy = PairStrategyNodePair
+	}
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	return &pairSelector{strategy: strategy, maxPairs: maxPairs, rng: rand.New(rand.NewSource(seed))}
+}
+
+// selectPairs 选择Pod对。services 为每个Pod（namespace/name）所属的Service，只在 service_pair 策略中使用
+func (s *pairSelector) selectPairs(pods []*corev1.Pod, services map[string][]string) []PodPair {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 按名称排序，使相同种子得到相同的结果，与API返回的顺序无关
+	sort.Slice(pods, func(i, j int) bool { return podKey(pods[i]) < podKey(pods[j]) })
+
+	switch s.strategy {
+	case PairStrategyFirst:
+		return firstPairs(pods, s.maxPairs)
+	case PairStrategyRandom:
+		return s.randomPairs(pods)
+	case PairStrategyServicePair:
+		return s.groupPairs(pods, func(pod *corev1.Pod) []string { return services[podKey(pod)] })
+	case PairStrategyNamespacePair:
+		return s.groupPairs(pods, func(pod *corev1.Pod) []string { return []string{pod.Namespace} })
+	default:
+		return s.groupPairs(pods, func(pod *corev1.Pod) []string { return []string{pod.Spec.NodeName} })
+	}
+}
+
+// groupPairs 按分组选择：每对不同分组选一对代表Pod，之后还有余量时每个分组在组内选一对
+func (s *pairSelector) groupPairs(pods []*corev1.Pod, groupsOf func(*corev1.Pod) []string) []PodPair {
+	members := make(map[string][]*corev1.Pod)
+	for _, pod := range pods {
+		for _, group := range groupsOf(pod) {
+			if group != "" {
+				members[group] = append(members[group], pod)
+			}
+		}
+	}
+	groups := make([]string, 0, len(members))
+	for group := range members {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var across, within [][2]string
+	for i := range groups {
+		within = append(within, [2]string{groups[i], groups[i]})
+		for j := i + 1; j < len(groups); j++ {
+			across = append(across, [2]string{groups[i], groups[j]})
+		}
+	}
+	s.shuffle(across)
+	s.shuffle(within)
+	candidates := append(across, within...)
+
+	pairs := make([]PodPair, 0, min(len(candidates), s.maxPairs))
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if len(pairs) >= s.maxPairs {
+			break
+		}
+		source, target, ok := s.representatives(members[candidate[0]], members[candidate[1]], candidate[0] == candidate[1])
+		if !ok {
+			continue
+		}
+		// 一个Pod属于多个Service时，不同的Service对可能选出同一对Pod
+		pair := newPodPair(source, target)
+		if seen[pair.String()] {
+			continue
+		}
+		seen[pair.String()] = true
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+func (s *pairSelector) shuffle(candidates [][2]string) {
+	s.rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+}
+
+// representatives 从两个分组中各随机选一个Pod（同一分组时选两个不同的Pod），发起方优先为未退出测试的Pod
+func (s *pairSelector) representatives(a, b []*corev1.Pod, sameGroup bool) (*corev1.Pod, *corev1.Pod, bool) {
+	if sameGroup && len(a) < 2 {
+		return nil, nil, false
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		i, j := s.rng.Intn(len(a)), s.rng.Intn(len(b))
+		if sameGroup {
+			// 从其余的Pod中选第二个
+			j = (i + 1 + s.rng.Intn(len(a)-1)) % len(a)
+		}
+		if source, target, ok := orientPair(a[i], b[j]); ok && source != target {
+			return source, target, true
+		}
+	}
+	return nil, nil, false
+}
+
+// randomPairs 随机抽样不重复的Pod对
+func (s *pairSelector) randomPairs(pods []*corev1.Pod) []PodPair {
+	n := len(pods)
+	possible := n * (n - 1) / 2
+	want := min(s.maxPairs, possible)
+
+	pairs := make([]PodPair, 0, want)
+	seen := make(map[[2]int]bool, want)
+	// 发起方都退出测试的Pod对无法使用，限制尝试次数
+	for attempt := 0; len(pairs) < want && attempt < want*10; attempt++ {
+		i, j := s.rng.Intn(n), s.rng.Intn(n)
+		if i == j {
+			continue
+		}
+		if i > j {
+			i, j = j, i
+		}
+		if seen[[2]int{i, j}] {
+			continue
+		}
+		seen[[2]int{i, j}] = true
+		if source, target, ok := orientPair(pods[i], pods[j]); ok {
+			pairs = append(pairs, newPodPair(source, target))
+		}
+	}
+	return pairs
+}
+
+// firstPairs 按顺序选择跨节点的Pod对，没有时选择同节点的（早期版本的行为）
+func firstPairs(pods []*corev1.Pod, maxPairs int) []PodPair {
+	pairs := []PodPair{}
+	for _, crossNode := range []bool{true, false} {
+		for i := 0; i < len(pods) && len(pairs) < maxPairs; i++ {
+			for j := i + 1; j < len(pods) && len(pairs) < maxPairs; j++ {
+				source, target, ok := orientPair(pods[i], pods[j])
+				if !ok {
+					continue
+				}
+				if !crossNode || source.Spec.NodeName != target.Spec.NodeName {
+					pairs = append(pairs, newPodPair(source, target))
+				}
+			}
+		}
+		if len(pairs) > 0 {
+			break
+		}
+	}
+	return pairs
+}
+
+// podServices 每个Pod（namespace/name）所属的Service（namespace/name），只考虑有选择器的Service
+func podServices(pods []*corev1.Pod, services []corev1.Service) map[string][]string {
+	result := make(map[string][]string)
+	for i := range services {
+		svc := &services[i]
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for _, pod := range pods {
+			if pod.Namespace == svc.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				key := podKey(pod)
+				result[key] = append(result[key], svc.Namespace+"/"+svc.Name)
+			}
+		}
+	}
+	return result
+}
+
+func podKey(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
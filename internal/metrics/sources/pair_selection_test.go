@@ -0,0 +1,146 @@
+package sources
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod(namespace, name, node string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+}
+
+// testCluster 3个节点、2个命名空间，每个节点上每个命名空间2个Pod
+func testCluster() []*corev1.Pod {
+	var pods []*corev1.Pod
+	for _, namespace := range []string{"shop", "payments"} {
+		for n := 1; n <= 3; n++ {
+			for i := 0; i < 2; i++ {
+				app := fmt.Sprintf("%s-app%d", namespace, i)
+				pods = append(pods, testPod(namespace, fmt.Sprintf("%s-%d", app, n), fmt.Sprintf("node-%d", n), map[string]string{"app": app}))
+			}
+		}
+	}
+	return pods
+}
+
+// pairGroups 统计Pod对两端所在的分组
+func pairGroups(pairs []PodPair, pods []*corev1.Pod, groupOf func(*corev1.Pod) string) map[[2]string]int {
+	byName := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		byName[podKey(pod)] = pod
+	}
+	groups := make(map[[2]string]int)
+	for _, pair := range pairs {
+		a := groupOf(byName[pair.SourceNamespace+"/"+pair.SourcePod])
+		b := groupOf(byName[pair.TargetNamespace+"/"+pair.TargetPod])
+		if a > b {
+			a, b = b, a
+		}
+		groups[[2]string{a, b}]++
+	}
+	return groups
+}
+
+func TestSelectPairsNodePair(t *testing.T) {
+	pods := testCluster()
+	pairs := newPairSelector(PairStrategyNodePair, 3, 1).selectPairs(pods, nil)
+
+	groups := pairGroups(pairs, pods, func(pod *corev1.Pod) string { return pod.Spec.NodeName })
+	want := map[[2]string]int{{"node-1", "node-2"}: 1, {"node-1", "node-3"}: 1, {"node-2", "node-3"}: 1}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("node pairs = %v, want one pair per node pair %v", groups, want)
+	}
+
+	// 还有余量时每个节点在节点内选一对
+	pairs = newPairSelector(PairStrategyNodePair, 10, 1).selectPairs(pods, nil)
+	if len(pairs) != 6 {
+		t.Errorf("got %d pairs, want 3 across nodes and 3 within", len(pairs))
+	}
+}
+
+func TestSelectPairsNamespacePair(t *testing.T) {
+	pods := testCluster()
+	pairs := newPairSelector(PairStrategyNamespacePair, 1, 1).selectPairs(pods, nil)
+
+	groups := pairGroups(pairs, pods, func(pod *corev1.Pod) string { return pod.Namespace })
+	if !reflect.DeepEqual(groups, map[[2]string]int{{"payments", "shop"}: 1}) {
+		t.Errorf("namespace pairs = %v, want the cross-namespace pair first", groups)
+	}
+}
+
+func TestSelectPairsServicePair(t *testing.T) {
+	pods := append(testCluster(), testPod("shop", "debug", "node-1", nil))
+	services := []corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "app0"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "shop-app0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "app1"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "shop-app1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "app0"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "payments-app0"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "external"}},
+	}
+	membership := podServices(pods, services)
+	if len(membership) != 9 {
+		t.Fatalf("%d pods belong to a service, want 9", len(membership))
+	}
+
+	pairs := newPairSelector(PairStrategyServicePair, 3, 1).selectPairs(pods, membership)
+	groups := pairGroups(pairs, pods, func(pod *corev1.Pod) string {
+		if services := membership[podKey(pod)]; len(services) > 0 {
+			return services[0]
+		}
+		return ""
+	})
+	want := map[[2]string]int{{"payments/app0", "shop/app0"}: 1, {"payments/app0", "shop/app1"}: 1, {"shop/app0", "shop/app1"}: 1}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("service pairs = %v, want %v", groups, want)
+	}
+}
+
+func TestSelectPairsRandomIsSeeded(t *testing.T) {
+	pods := testCluster()
+	first := newPairSelector(PairStrategyRandom, 5, 42).selectPairs(pods, nil)
+	second := newPairSelector(PairStrategyRandom, 5, 42).selectPairs(testCluster(), nil)
+
+	if len(first) != 5 {
+		t.Fatalf("got %d pairs, want 5", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed selected different pairs:\n%v\n%v", first, second)
+	}
+	seen := make(map[string]bool)
+	for _, pair := range first {
+		if pair.SourcePod == pair.TargetPod || seen[pair.String()] {
+			t.Errorf("invalid or duplicate pair %s", pair)
+		}
+		seen[pair.String()] = true
+	}
+}
+
+func TestSelectPairsSkipsOptedOutSources(t *testing.T) {
+	pods := []*corev1.Pod{
+		testPod("default", "a", "node-1", nil),
+		testPod("default", "b", "node-2", nil),
+	}
+	for _, pod := range pods {
+		pod.Annotations = map[string]string{k8s.ProbeOptOutAnnotation: "true"}
+	}
+	for _, strategy := range []string{PairStrategyNodePair, PairStrategyRandom, PairStrategyFirst} {
+		if pairs := newPairSelector(strategy, 5, 1).selectPairs(pods, nil); len(pairs) != 0 {
+			t.Errorf("%s: selected %v although both pods opted out", strategy, pairs)
+		}
+	}
+
+	// 只有一端退出时由另一端发起
+	pods[0].Annotations = nil
+	pairs := newPairSelector(PairStrategyNodePair, 5, 1).selectPairs(pods, nil)
+	if len(pairs) != 1 || pairs[0].SourcePod != "a" {
+		t.Errorf("pairs = %v, want a single pair from a", pairs)
+	}
+}
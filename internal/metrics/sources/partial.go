@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"errors"
+	"strings"
+)
+
+// PartialError 采集部分失败：同时返回的结果可用但不完整，
+// 例如Metrics Server不可用时节点和Pod只有基础信息、没有CPU/内存用量
+type PartialError struct {
+	Errors []error
+}
+
+// Error 实现 error 接口
+func (e *PartialError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "incomplete results: " + strings.Join(messages, "; ")
+}
+
+// Unwrap 返回各个原始错误
+func (e *PartialError) Unwrap() []error {
+	return e.Errors
+}
+
+// IsPartial 判断错误是否只是部分失败（结果仍然可用）
+func IsPartial(err error) bool {
+	var target *PartialError
+	return errors.As(err, &target)
+}
+
+// appendErrors 追加错误，PartialError 展开为其中的各个错误
+func appendErrors(errs []error, err error) []error {
+	var target *PartialError
+	if errors.As(err, &target) {
+		return append(errs, target.Errors...)
+	}
+	return append(errs, err)
+}
+
+// partial 将采集过程中的错误包装为 PartialError，没有错误时返回nil
+func partial(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PartialError{Errors: errs}
+}
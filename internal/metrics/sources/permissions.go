@@ -0,0 +1,160 @@
+package sources
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// 采集器名称（用于权限跟踪和状态报告）
+const (
+	CollectorNode    = "node"
+	CollectorPod     = "pod"
+	CollectorNetwork = "network"
+	CollectorUAV     = "uav"
+	CollectorCustom  = "custom"
+	CollectorGPU     = "gpu"
+	CollectorSummary = "summary"
+
+	CollectorNodeLatency = "node_latency"
+	CollectorExternal    = "external"
+)
+
+// defaultPermissionRetry 权限不足的资源重新尝试访问的间隔
+const defaultPermissionRetry = 10 * time.Minute
+
+// MissingPermission 采集器缺少的一项RBAC权限
+type MissingPermission struct {
+	Collector   string    `json:"collector"`
+	Verb        string    `json:"verb"`
+	Group       string    `json:"group"` // 为空表示core API组
+	Resource    string    `json:"resource"`
+	Namespace   string    `json:"namespace,omitempty"` // 为空表示集群范围
+	Error       string    `json:"error"`
+	Since       time.Time `json:"since"`
+	LastChecked time.Time `json:"last_checked"`
+}
+
+// String 以RBAC规则的形式描述缺少的权限
+func (p MissingPermission) String() string {
+	group := p.Group
+	if group == "" {
+		group = "core"
+	}
+	scope := "cluster-wide"
+	if p.Namespace != "" {
+		scope = "namespace " + p.Namespace
+	}
+	return fmt.Sprintf("%s %s.%s (%s)", p.Verb, p.Resource, group, scope)
+}
+
+// PermissionTracker 记录采集器访问被拒绝（403）的资源；
+// 被拒绝的资源在重试间隔内直接跳过，避免每个采集周期重复请求和刷屏日志
+type PermissionTracker struct {
+	denied map[string]*MissingPermission
+	retry  time.Duration
+	mu     sync.RWMutex
+	logger *logrus.Entry
+}
+
+// NewPermissionTracker 创建权限跟踪器，retry为被拒绝资源的重试间隔
+func NewPermissionTracker(retry time.Duration, logger *logrus.Entry) *PermissionTracker {
+	if retry <= 0 {
+		retry = defaultPermissionRetry
+	}
+	if logger == nil {
+		logger = logging.For("metrics.permissions")
+	}
+	return &PermissionTracker{
+		denied: make(map[string]*MissingPermission),
+		retry:  retry,
+		logger: logger,
+	}
+}
+
+// permissionKey 权限记录的唯一标识
+func permissionKey(collector, verb, group, resource, namespace string) string {
+	return collector + "|" + verb + "|" + group + "|" + resource + "|" + namespace
+}
+
+// Allow 判断是否应当访问该资源：未被拒绝过或已到重试时间时返回true
+func (t *PermissionTracker) Allow(collector, verb, group, resource, namespace string) bool {
+	if t == nil {
+		return true
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	entry, ok := t.denied[permissionKey(collector, verb, group, resource, namespace)]
+	return !ok || time.Since(entry.LastChecked) >= t.retry
+}
+
+// Observe 记录一次访问结果，err为403时返回true（调用方应降级而不是报错）；
+// 之前被拒绝的资源访问成功后自动恢复
+func (t *PermissionTracker) Observe(collector, verb, group, resource, namespace string, err error) bool {
+	forbidden := err != nil && apierrors.IsForbidden(err)
+	if t == nil {
+		return forbidden
+	}
+
+	key := permissionKey(collector, verb, group, resource, namespace)
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, known := t.denied[key]
+	switch {
+	case forbidden && known:
+		entry.LastChecked = now
+		entry.Error = err.Error()
+	case forbidden:
+		entry = &MissingPermission{
+			Collector:   collector,
+			Verb:        verb,
+			Group:       group,
+			Resource:    resource,
+			Namespace:   namespace,
+			Error:       err.Error(),
+			Since:       now,
+			LastChecked: now,
+		}
+		t.denied[key] = entry
+		t.logger.Warnf("Collector %s degraded: missing permission %s, retrying every %s", collector, entry, t.retry)
+	case err == nil && known:
+		delete(t.denied, key)
+		t.logger.Infof("Collector %s recovered: permission %s granted", collector, entry)
+	}
+	return forbidden
+}
+
+// Missing 返回当前缺少的权限（可按采集器过滤）
+func (t *PermissionTracker) Missing(collector string) []MissingPermission {
+	result := []MissingPermission{}
+	if t == nil {
+		return result
+	}
+
+	t.mu.RLock()
+	for _, entry := range t.denied {
+		if collector == "" || entry.Collector == collector {
+			result = append(result, *entry)
+		}
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Collector != result[j].Collector {
+			return result[i].Collector < result[j].Collector
+		}
+		return permissionKey("", result[i].Verb, result[i].Group, result[i].Resource, result[i].Namespace) <
+			permissionKey("", result[j].Verb, result[j].Group, result[j].Resource, result[j].Namespace)
+	})
+	return result
+}
@@ -2,10 +2,15 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
 	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,18 +19,54 @@ import (
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+// PodFilter 在命名空间内进一步限定采集的Pod：selector在API Server端过滤，Exclude在本地过滤
+type PodFilter struct {
+	LabelSelector string
+	FieldSelector string
+	Exclude       []string // namespace 或 namespace/pod 形式的glob，例如 kube-system、default/debug-*
+}
+
+// excludesNamespace 整个命名空间是否被排除
+func (f PodFilter) excludesNamespace(namespace string) bool {
+	for _, pattern := range f.Exclude {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludes Pod是否被排除
+func (f PodFilter) excludes(namespace, name string) bool {
+	if f.excludesNamespace(namespace) {
+		return true
+	}
+	for _, pattern := range f.Exclude {
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, namespace+"/"+name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // PodMetricsCollector Pod指标采集器
 type PodMetricsCollector struct {
 	kubeClient    *kubernetes.Clientset
 	metricsClient *metricsclientset.Clientset
-	namespaces    []string // 要监控的命名空间列表
-	logger        *logrus.Logger
+	namespaces    []string  // 要监控的命名空间列表
+	filter        PodFilter // 命名空间内的Pod过滤条件
+	namespacesMu  sync.RWMutex
+	logger        *logrus.Entry
+	permissions   *PermissionTracker
 }
 
 // NewPodMetricsCollector 创建Pod指标采集器
 func NewPodMetricsCollector(kubeClient *kubernetes.Clientset, metricsClient *metricsclientset.Clientset, namespaces []string) *PodMetricsCollector {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("metrics.pod")
 
 	// 如果没有指定namespace，默认监控所有
 	if len(namespaces) == 0 {
@@ -40,17 +81,62 @@ func NewPodMetricsCollector(kubeClient *kubernetes.Clientset, metricsClient *met
 	}
 }
 
+// SetNamespaces 更新要监控的命名空间（配置热更新）
+func (c *PodMetricsCollector) SetNamespaces(namespaces []string) {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	c.namespacesMu.Lock()
+	c.namespaces = append([]string(nil), namespaces...)
+	c.namespacesMu.Unlock()
+}
+
+// SetFilter 更新Pod过滤条件（配置热更新）
+func (c *PodMetricsCollector) SetFilter(filter PodFilter) {
+	filter.Exclude = append([]string(nil), filter.Exclude...)
+
+	c.namespacesMu.Lock()
+	c.filter = filter
+	c.namespacesMu.Unlock()
+}
+
+// SetPermissionTracker 设置权限跟踪器（权限不足的命名空间降级跳过）
+func (c *PodMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
 // CollectPodMetrics 采集所有Pod指标
 func (c *PodMetricsCollector) CollectPodMetrics(ctx context.Context) (map[string]*metricstypes.PodMetrics, error) {
 	c.logger.Debug("Collecting pod metricstypes...")
 
 	result := make(map[string]*metricstypes.PodMetrics)
 
-	// 遍历所有要监控的namespace
-	for _, namespace := range c.namespaces {
-		podMetrics, err := c.CollectNamespacePodMetrics(ctx, namespace)
+	c.namespacesMu.RLock()
+	namespaces, filter := c.namespaces, c.filter
+	c.namespacesMu.RUnlock()
+
+	// 遍历所有要监控的namespace，部分namespace失败时返回其余namespace的结果
+	var errs []error
+	collected, failed := 0, 0
+	for _, namespace := range namespaces {
+		if namespace != "" && filter.excludesNamespace(namespace) {
+			continue
+		}
+		collected++
+		podMetrics, err := c.collectNamespace(ctx, namespace, filter)
 		if err != nil {
-			c.logger.Warnf("Failed to collect pod metrics for namespace %s: %v", namespace, err)
+			errs = appendErrors(errs, err)
+			if !IsPartial(err) {
+				c.logger.Warnf("Failed to collect pod metrics for namespace %s: %v", namespace, err)
+				failed++
+				continue
+			}
+		}
+
+		// 只有一个namespace（或全部namespace）时直接使用结果，避免大集群下重复拷贝
+		if len(result) == 0 {
+			result = podMetrics
 			continue
 		}
 
@@ -60,41 +146,72 @@ func (c *PodMetricsCollector) CollectPodMetrics(ctx context.Context) (map[string
 		}
 	}
 
+	if failed > 0 && failed == collected {
+		return nil, fmt.Errorf("failed to collect pod metrics: %w", errors.Join(errs...))
+	}
+
 	c.logger.Debugf("Successfully collected metrics for %d pods", len(result))
-	return result, nil
+	return result, partial(errs)
 }
 
-// CollectNamespacePodMetrics 采集指定namespace的Pod指标
+// CollectNamespacePodMetrics 采集指定namespace的Pod指标（应用Pod过滤条件，Metrics Server不可用时返回 *PartialError）
 func (c *PodMetricsCollector) CollectNamespacePodMetrics(ctx context.Context, namespace string) (map[string]*metricstypes.PodMetrics, error) {
-	// 1. 获取Pod列表
-	pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	c.namespacesMu.RLock()
+	filter := c.filter
+	c.namespacesMu.RUnlock()
+	return c.collectNamespace(ctx, namespace, filter)
+}
+
+// collectNamespace 按过滤条件采集一个namespace（为空表示所有namespace）的Pod指标
+func (c *PodMetricsCollector) collectNamespace(ctx context.Context, namespace string, filter PodFilter) (map[string]*metricstypes.PodMetrics, error) {
+	// 1. 获取Pod列表（没有该命名空间的权限时降级为空结果）
+	result := make(map[string]*metricstypes.PodMetrics)
+	if !c.permissions.Allow(CollectorPod, "list", "", "pods", namespace) {
+		return result, nil
+	}
+	pods, err := c.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: filter.LabelSelector,
+		FieldSelector: filter.FieldSelector,
+	})
+	if c.permissions.Observe(CollectorPod, "list", "", "pods", namespace, err) {
+		return result, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
 	}
 
 	// 2. 获取Pod的实时指标
-	podMetrics, err := c.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		c.logger.Warnf("Failed to get pod metrics from metrics server for namespace %s: %v (metrics may be incomplete)", namespace, err)
-		podMetrics = &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{}}
+	var errs []error
+	podMetrics := &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{}}
+	if c.permissions.Allow(CollectorPod, "list", "metrics.k8s.io", "pods", namespace) {
+		// metrics API 只支持label selector，field selector 通过与Pod列表关联实现
+		list, err := c.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{LabelSelector: filter.LabelSelector})
+		if forbidden := c.permissions.Observe(CollectorPod, "list", "metrics.k8s.io", "pods", namespace, err); err == nil {
+			podMetrics = list
+		} else if !forbidden {
+			c.logger.Warnf("Failed to get pod metrics from metrics server for namespace %s: %v (metrics may be incomplete)", namespace, err)
+			errs = append(errs, fmt.Errorf("failed to get pod metrics from metrics server for namespace %q: %w", namespace, err))
+		}
 	}
 
-	// 3. 创建指标映射
-	metricsMap := make(map[string]*metricsv1beta1.PodMetrics)
+	// 3. 创建指标映射（按数量预分配，5k Pod规模下避免反复扩容）
+	metricsMap := make(map[string]*metricsv1beta1.PodMetrics, len(podMetrics.Items))
 	for i := range podMetrics.Items {
 		pm := &podMetrics.Items[i]
-		metricsMap[pm.Name] = pm
+		metricsMap[pm.Namespace+"/"+pm.Name] = pm
 	}
 
-	// 4. 组合数据
-	result := make(map[string]*metricstypes.PodMetrics)
-	for _, pod := range pods.Items {
-		podMetric := c.buildPodMetrics(&pod, metricsMap[pod.Name])
-		key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
-		result[key] = podMetric
+	// 4. 组合数据（跳过被排除的Pod）
+	result = make(map[string]*metricstypes.PodMetrics, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if filter.excludes(pod.Namespace, pod.Name) {
+			continue
+		}
+		result[pod.Namespace+"/"+pod.Name] = c.buildPodMetrics(pod, metricsMap[pod.Namespace+"/"+pod.Name])
 	}
 
-	return result, nil
+	return result, partial(errs)
 }
 
 // buildPodMetrics 构建PodMetrics对象
@@ -102,7 +219,7 @@ func (c *PodMetricsCollector) buildPodMetrics(pod *corev1.Pod, metric *metricsv1
 	now := time.Now()
 
 	// 计算Pod的资源请求和限制
-	var cpuRequest, cpuLimit, memoryRequest, memoryLimit int64
+	var cpuRequest, cpuLimit, memoryRequest, memoryLimit, gpuRequest int64
 	for _, container := range pod.Spec.Containers {
 		if req := container.Resources.Requests.Cpu(); req != nil {
 			cpuRequest += req.MilliValue()
@@ -116,6 +233,10 @@ func (c *PodMetricsCollector) buildPodMetrics(pod *corev1.Pod, metric *metricsv1
 		if lim := container.Resources.Limits.Memory(); lim != nil {
 			memoryLimit += lim.Value()
 		}
+		// device plugin按limit分配GPU（扩展资源的request必须等于limit）
+		if gpus, ok := container.Resources.Limits[gpuResourceName]; ok {
+			gpuRequest += gpus.Value()
+		}
 	}
 
 	// 计算Pod的实际使用量
@@ -170,10 +291,20 @@ func (c *PodMetricsCollector) buildPodMetrics(pod *corev1.Pod, metric *metricsv1
 		memoryUsageRate = float64(memoryUsage) / float64(memoryLimit) * 100.0
 	}
 
-	// 计算Pod重启次数
+	// 计算Pod重启次数，并记录各容器最近一次终止的原因
 	var restarts int32
+	var containerRestarts []metricstypes.ContainerRestart
 	for _, containerStatus := range pod.Status.ContainerStatuses {
 		restarts += containerStatus.RestartCount
+		restart := metricstypes.ContainerRestart{Name: containerStatus.Name, Restarts: containerStatus.RestartCount}
+		if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil {
+			restart.Reason = terminated.Reason
+			restart.ExitCode = terminated.ExitCode
+			restart.FinishedAt = terminated.FinishedAt.Time
+		}
+		if restart.Restarts > 0 || restart.Reason != "" {
+			containerRestarts = append(containerRestarts, restart)
+		}
 	}
 
 	// 检查Pod是否就绪
@@ -208,7 +339,10 @@ func (c *PodMetricsCollector) buildPodMetrics(pod *corev1.Pod, metric *metricsv1
 		CPUUsageRate:    cpuUsageRate,
 		MemoryUsageRate: memoryUsageRate,
 
-		Containers: containerMetrics,
+		GPURequest: gpuRequest,
+
+		Containers:        containerMetrics,
+		ContainerRestarts: containerRestarts,
 
 		Phase:     string(pod.Status.Phase),
 		Ready:     ready,
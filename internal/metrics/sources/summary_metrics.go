@@ -0,0 +1,163 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SummaryCollectorConfig kubelet Summary API 采集器配置
+type SummaryCollectorConfig struct {
+	Timeout     time.Duration // 单个节点的请求超时（默认10秒）
+	Concurrency int           // 同时请求的节点数量（默认10）
+}
+
+// SummaryMetricsCollector 通过apiserver代理读取各节点kubelet的 /stats/summary，
+// 获取实际的文件系统用量、网卡流量和Pod临时存储用量
+type SummaryMetricsCollector struct {
+	kubeClient  *kubernetes.Clientset
+	config      SummaryCollectorConfig
+	logger      *logrus.Entry
+	permissions *PermissionTracker
+}
+
+// statsSummary /stats/summary 响应中用到的字段
+type statsSummary struct {
+	Node struct {
+		Fs      *fsStats      `json:"fs"`
+		Network *networkStats `json:"network"`
+	} `json:"node"`
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		EphemeralStorage *fsStats `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+// fsStats 文件系统用量
+type fsStats struct {
+	CapacityBytes *uint64 `json:"capacityBytes"`
+	UsedBytes     *uint64 `json:"usedBytes"`
+}
+
+// networkStats 默认网卡的累计流量
+type networkStats struct {
+	RxBytes *uint64 `json:"rxBytes"`
+	TxBytes *uint64 `json:"txBytes"`
+}
+
+// NewSummaryMetricsCollector 创建kubelet Summary API采集器
+func NewSummaryMetricsCollector(kubeClient *kubernetes.Clientset, config SummaryCollectorConfig) *SummaryMetricsCollector {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 10
+	}
+	return &SummaryMetricsCollector{
+		kubeClient: kubeClient,
+		config:     config,
+		logger:     logging.For("metrics.summary"),
+	}
+}
+
+// SetPermissionTracker 设置权限跟踪器（缺少 nodes/proxy 权限时跳过，节点磁盘用量退回估算值）
+func (c *SummaryMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
+// CollectSummaryMetrics 读取所有节点的Summary，返回 节点名 -> 统计（部分节点失败时返回 *PartialError）
+func (c *SummaryMetricsCollector) CollectSummaryMetrics(ctx context.Context) (map[string]*metricstypes.KubeletSummary, error) {
+	result := make(map[string]*metricstypes.KubeletSummary)
+
+	if !c.permissions.Allow(CollectorSummary, "list", "", "nodes", "") {
+		return result, nil
+	}
+	nodes, err := c.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if c.permissions.Observe(CollectorSummary, "list", "", "nodes", "", err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if !c.permissions.Allow(CollectorSummary, "get", "", "nodes/proxy", "") {
+		return result, nil
+	}
+
+	names := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		names = append(names, node.Name)
+	}
+	summaries, err := workpool.Map(ctx, workpool.Options{Concurrency: c.config.Concurrency, TaskTimeout: c.config.Timeout}, names,
+		func(name string) string { return name },
+		c.collectNode)
+	if err != nil {
+		c.logger.Warnf("Failed to read kubelet summary of %d node(s): %v", workpool.Failed(err), err)
+		if workpool.Failed(err) == len(names) {
+			return result, fmt.Errorf("failed to read kubelet summaries: %w", err)
+		}
+		err = partial([]error{err})
+	}
+	for i, summary := range summaries {
+		if summary != nil {
+			result[names[i]] = summary
+		}
+	}
+
+	c.logger.Debugf("Collected kubelet summary from %d/%d nodes", len(result), len(names))
+	return result, err
+}
+
+// collectNode 通过 /api/v1/nodes/<node>/proxy/stats/summary 读取单个节点的Summary
+func (c *SummaryMetricsCollector) collectNode(ctx context.Context, nodeName string) (*metricstypes.KubeletSummary, error) {
+	data, err := c.kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", nodeName, "proxy", "stats", "summary").
+		DoRaw(ctx)
+	if c.permissions.Observe(CollectorSummary, "get", "", "nodes/proxy", "", err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubelet summary: %w", err)
+	}
+
+	var raw statsSummary
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode kubelet summary: %w", err)
+	}
+
+	summary := &metricstypes.KubeletSummary{Timestamp: time.Now(), PodEphemeralStorage: make(map[string]int64, len(raw.Pods))}
+	if fs := raw.Node.Fs; fs != nil {
+		summary.FSCapacity = bytesValue(fs.CapacityBytes)
+		summary.FSUsed = bytesValue(fs.UsedBytes)
+	}
+	if network := raw.Node.Network; network != nil {
+		summary.NetworkRxBytes = bytesValue(network.RxBytes)
+		summary.NetworkTxBytes = bytesValue(network.TxBytes)
+	}
+	for _, pod := range raw.Pods {
+		if pod.EphemeralStorage == nil || pod.EphemeralStorage.UsedBytes == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", pod.PodRef.Namespace, pod.PodRef.Name)
+		summary.PodEphemeralStorage[key] = bytesValue(pod.EphemeralStorage.UsedBytes)
+	}
+	return summary, nil
+}
+
+// bytesValue Summary中可选的字节数（缺失时为0）
+func bytesValue(value *uint64) int64 {
+	if value == nil {
+		return 0
+	}
+	return int64(*value)
+}
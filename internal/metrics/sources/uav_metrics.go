@@ -1,14 +1,21 @@
 package sources
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	"github.com/yourusername/k8s-llm-monitor/internal/workpool"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,9 +26,12 @@ import (
 type UAVMetricsCollector struct {
 	kubeClient  *kubernetes.Clientset
 	namespace   string
-	logger      *logrus.Logger
+	logger      *logrus.Entry
 	httpClient  *http.Client
+	scheme      string // http，或使用mTLS客户端时为https
 	uavPodLabel string // 用于识别UAV Agent Pod的label
+	permissions *PermissionTracker
+	concurrency int
 }
 
 // UAVCollectorConfig UAV采集器配置
@@ -29,12 +39,13 @@ type UAVCollectorConfig struct {
 	Namespace   string        // UAV Agent所在的namespace
 	UAVLabel    string        // UAV Pod的label selector (默认: app=uav-agent)
 	Timeout     time.Duration // HTTP请求超时时间
+	HTTPClient  *http.Client  // 自定义客户端（mTLS），设置后通过HTTPS访问Agent
+	Concurrency int           // 同时拉取的Agent数量（默认10）
 }
 
 // NewUAVMetricsCollector 创建UAV指标采集器
 func NewUAVMetricsCollector(kubeClient *kubernetes.Clientset, config UAVCollectorConfig) *UAVMetricsCollector {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("metrics.uav")
 
 	// 设置默认值
 	if config.Namespace == "" {
@@ -46,133 +57,176 @@ func NewUAVMetricsCollector(kubeClient *kubernetes.Clientset, config UAVCollecto
 	if config.Timeout == 0 {
 		config.Timeout = 5 * time.Second
 	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 10
+	}
+
+	httpClient, scheme := config.HTTPClient, "https"
+	if httpClient == nil {
+		httpClient, scheme = &http.Client{Timeout: config.Timeout}, "http"
+	}
+	// 每次拉取记录为子span，并向Agent传播trace上下文
+	httpClient = &http.Client{Timeout: httpClient.Timeout, Transport: tracing.Transport(httpClient.Transport)}
 
 	return &UAVMetricsCollector{
 		kubeClient:  kubeClient,
 		namespace:   config.Namespace,
 		logger:      logger,
 		uavPodLabel: config.UAVLabel,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		httpClient:  httpClient,
+		scheme:      scheme,
 	}
 }
 
-// CollectUAVMetrics 采集所有UAV的指标
-func (c *UAVMetricsCollector) CollectUAVMetrics(ctx context.Context) (map[string]interface{}, error) {
+// SetPermissionTracker 设置权限跟踪器（权限不足时降级为仅接收推送上报）
+func (c *UAVMetricsCollector) SetPermissionTracker(tracker *PermissionTracker) {
+	c.permissions = tracker
+}
+
+// CollectUAVMetrics 采集所有Agent模拟的所有UAV的指标（部分Agent失败时返回其余结果和 *PartialError）
+func (c *UAVMetricsCollector) CollectUAVMetrics(ctx context.Context) (map[models.UAVRef]*uav.UAVState, error) {
 	c.logger.Debug("Collecting UAV metrics...")
 
 	// 1. 获取所有UAV Agent Pod
+	if !c.permissions.Allow(CollectorUAV, "list", "", "pods", c.namespace) {
+		return make(map[models.UAVRef]*uav.UAVState), nil
+	}
 	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: c.uavPodLabel,
 		FieldSelector: "status.phase=Running",
 	})
+	if c.permissions.Observe(CollectorUAV, "list", "", "pods", c.namespace, err) {
+		return make(map[models.UAVRef]*uav.UAVState), nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list UAV agent pods: %w", err)
 	}
 
 	if len(pods.Items) == 0 {
 		c.logger.Warn("No running UAV agent pods found")
-		return make(map[string]interface{}), nil
+		return make(map[models.UAVRef]*uav.UAVState), nil
 	}
 
 	c.logger.Infof("Found %d UAV agent pods", len(pods.Items))
 
-	// 2. 并发采集所有UAV的状态
-	results := make(map[string]interface{})
-	resultsChan := make(chan uavResult, len(pods.Items))
-	var wg sync.WaitGroup
-
+	// 2. 并发采集各Agent的所有UAV的状态（单个Agent超时由httpClient控制）
+	pointers := make([]*corev1.Pod, len(pods.Items))
 	for i := range pods.Items {
-		wg.Add(1)
-		go func(pod *corev1.Pod) {
-			defer wg.Done()
-
-			state, err := c.collectSingleUAV(ctx, pod)
-			resultsChan <- uavResult{
-				nodeName: pod.Spec.NodeName,
-				state:    state,
-				err:      err,
+		pointers[i] = &pods.Items[i]
+	}
+	fleets, err := workpool.Map(ctx, workpool.Options{Concurrency: c.concurrency}, pointers,
+		func(pod *corev1.Pod) string { return pod.Spec.NodeName },
+		c.collectAgent)
+	if err != nil {
+		c.logger.Warnf("Failed to collect UAV metrics from %d agent(s): %v", workpool.Failed(err), err)
+		if workpool.Failed(err) == len(pointers) {
+			return nil, fmt.Errorf("failed to collect UAV metrics: %w", err)
+		}
+	}
+
+	results := make(map[models.UAVRef]*uav.UAVState, len(fleets))
+	for i, states := range fleets {
+		for index, state := range states {
+			if state != nil {
+				results[models.UAVRef{NodeName: pointers[i].Spec.NodeName, Index: index}] = state
 			}
-		}(&pods.Items[i])
+		}
 	}
 
-	// 等待所有采集完成
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	c.logger.Infof("UAV metrics collection completed: %d UAVs from %d/%d agents", len(results), len(pods.Items)-workpool.Failed(err), len(pods.Items))
+	if err != nil {
+		return results, partial([]error{err})
+	}
+	return results, nil
+}
 
-	// 收集结果
-	for result := range resultsChan {
-		if result.err != nil {
-			c.logger.Warnf("Failed to collect UAV metrics from node %s: %v", result.nodeName, result.err)
-			continue
-		}
-		if result.state != nil {
-			results[result.nodeName] = result.state
+// collectAgent 采集一个Agent模拟的所有UAV的状态，下标为UAV的序号
+func (c *UAVMetricsCollector) collectAgent(ctx context.Context, pod *corev1.Pod) ([]*uav.UAVState, error) {
+	count, err := c.countUAVs(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*uav.UAVState, count)
+	for index := range states {
+		if states[index], err = c.collectSingleUAV(ctx, pod, index); err != nil {
+			return nil, fmt.Errorf("uav %d: %w", index, err)
 		}
 	}
+	return states, nil
+}
 
-	c.logger.Infof("UAV metrics collection completed: %d/%d successful", len(results), len(pods.Items))
-	return results, nil
+// countUAVs Agent模拟的UAV数量；不支持 /api/v1/uavs 的旧版本Agent只有一架
+func (c *UAVMetricsCollector) countUAVs(ctx context.Context, pod *corev1.Pod) (int, error) {
+	var uavs []struct {
+		Index int `json:"index"`
+	}
+	status, err := c.getAgent(ctx, pod, "/api/v1/uavs", &uavs)
+	if status == http.StatusNotFound {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(uavs) == 0 || len(uavs) > models.MaxUAVsPerAgent {
+		return 0, fmt.Errorf("agent reported %d UAVs", len(uavs))
+	}
+	return len(uavs), nil
 }
 
-// uavResult 采集结果
-type uavResult struct {
-	nodeName string
-	state    *uav.UAVState
-	err      error
+// collectSingleUAV 采集Agent中第 index 架UAV的状态
+func (c *UAVMetricsCollector) collectSingleUAV(ctx context.Context, pod *corev1.Pod, index int) (*uav.UAVState, error) {
+	path := "/api/v1/state"
+	if index > 0 {
+		path += fmt.Sprintf("?uav=%d", index)
+	}
+	var state *uav.UAVState
+	if _, err := c.getAgent(ctx, pod, path, &state); err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+
+	c.logger.Debugf("Collected UAV metrics from %s (node: %s, uav: %d)", pod.Name, pod.Spec.NodeName, index)
+	return state, nil
 }
 
-// collectSingleUAV 采集单个UAV的状态
-func (c *UAVMetricsCollector) collectSingleUAV(ctx context.Context, pod *corev1.Pod) (*uav.UAVState, error) {
+// getAgent 请求Agent的接口并将响应的 data 解码到 data 中，返回HTTP状态码
+func (c *UAVMetricsCollector) getAgent(ctx context.Context, pod *corev1.Pod, path string, data interface{}) (int, error) {
 	// 使用Pod IP直接访问（在集群内部部署时）
-	// 或者使用Headless Service访问
 	podIP := pod.Status.PodIP
 	if podIP == "" {
-		return nil, fmt.Errorf("pod %s has no IP", pod.Name)
+		return 0, fmt.Errorf("pod %s has no IP", pod.Name)
 	}
 
-	url := fmt.Sprintf("http://%s:9090/api/v1/state", podIP)
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	url := fmt.Sprintf("%s://%s:9090%s", c.scheme, podIP, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return 0, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// 解析响应
-	var apiResp struct {
-		Status string         `json:"status"`
-		Data   *uav.UAVState  `json:"data"`
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
+	apiResp := struct {
+		Status string      `json:"status"`
+		Data   interface{} `json:"data"`
+	}{Data: data}
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if apiResp.Data == nil {
-		return nil, fmt.Errorf("no data in response")
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	c.logger.Debugf("Collected UAV metrics from %s (node: %s)", pod.Name, pod.Spec.NodeName)
-	return apiResp.Data, nil
+	return resp.StatusCode, nil
 }
 
 // CollectSingleUAVMetrics 采集指定节点的UAV指标
-func (c *UAVMetricsCollector) CollectSingleUAVMetrics(ctx context.Context, nodeName string) (interface{}, error) {
+func (c *UAVMetricsCollector) CollectSingleUAVMetrics(ctx context.Context, nodeName string) (*uav.UAVState, error) {
 	// 查找该节点上的UAV Agent Pod
 	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: c.uavPodLabel,
@@ -186,102 +240,139 @@ func (c *UAVMetricsCollector) CollectSingleUAVMetrics(ctx context.Context, nodeN
 		return nil, fmt.Errorf("no UAV agent found on node %s", nodeName)
 	}
 
-	return c.collectSingleUAV(ctx, &pods.Items[0])
+	return c.collectSingleUAV(ctx, &pods.Items[0], 0)
 }
 
 // GetUAVByNode 按节点名获取UAV状态（便捷方法）
-func (c *UAVMetricsCollector) GetUAVByNode(ctx context.Context, nodeName string) (interface{}, error) {
+func (c *UAVMetricsCollector) GetUAVByNode(ctx context.Context, nodeName string) (*uav.UAVState, error) {
 	return c.CollectSingleUAVMetrics(ctx, nodeName)
 }
 
 // GetHealthyUAVCount 获取健康的UAV数量
 func (c *UAVMetricsCollector) GetHealthyUAVCount(ctx context.Context) (int, error) {
 	states, err := c.CollectUAVMetrics(ctx)
-	if err != nil {
+	if err != nil && !IsPartial(err) {
 		return 0, err
 	}
 
 	count := 0
-	for _, stateInterface := range states {
-		if state, ok := stateInterface.(*uav.UAVState); ok {
-			if state.Health.SystemStatus == "OK" {
-				count++
-			}
+	for _, state := range states {
+		if state.Health.SystemStatus == "OK" {
+			count++
 		}
 	}
 
 	return count, nil
 }
 
-// GetLowBatteryUAVs 获取低电量的UAV列表
+// GetLowBatteryUAVs 获取低电量的UAV标识列表（见 models.UAVKey）
 func (c *UAVMetricsCollector) GetLowBatteryUAVs(ctx context.Context, threshold float64) ([]string, error) {
 	states, err := c.CollectUAVMetrics(ctx)
-	if err != nil {
+	if err != nil && !IsPartial(err) {
 		return nil, err
 	}
 
 	var lowBatteryUAVs []string
-	for nodeName, stateInterface := range states {
-		if state, ok := stateInterface.(*uav.UAVState); ok {
-			if state.Battery.RemainingPercent < threshold {
-				lowBatteryUAVs = append(lowBatteryUAVs, nodeName)
-			}
+	for ref, state := range states {
+		if state.Battery.RemainingPercent < threshold {
+			lowBatteryUAVs = append(lowBatteryUAVs, ref.Key())
 		}
 	}
 
 	return lowBatteryUAVs, nil
 }
 
-// SendCommandToUAV 向指定节点的UAV发送命令
-func (c *UAVMetricsCollector) SendCommandToUAV(ctx context.Context, nodeName, command string, payload interface{}) error {
+// UAVCommands Agent支持的控制命令
+var UAVCommands = []string{"arm", "disarm", "takeoff", "land", "rtl", "mode"}
+
+// ErrNoUAVAgent 节点上没有运行中的UAV Agent
+var ErrNoUAVAgent = errors.New("no UAV agent found on node")
+
+// UAVCommandError Agent拒绝了命令（参数错误，或UAV当前状态不允许，如未满足解锁条件）
+type UAVCommandError struct {
+	StatusCode int    // Agent返回的HTTP状态码
+	Code       string // Agent返回的错误码（如 not_armed、no_gps_fix），旧版本Agent可能为空
+	Message    string // Agent返回的原因
+}
+
+// Error 实现 error 接口
+func (e *UAVCommandError) Error() string {
+	return fmt.Sprintf("UAV agent rejected command (status %d): %s", e.StatusCode, e.Message)
+}
+
+// SendCommandToUAV 向指定节点的UAV发送命令，index 为UAV在Agent中的序号（Agent只模拟一架UAV时为0），
+// payload 不为nil时作为JSON请求体（如起飞高度、飞行模式）。返回Agent的响应消息；Agent拒绝命令时返回 *UAVCommandError
+func (c *UAVMetricsCollector) SendCommandToUAV(ctx context.Context, nodeName string, index int, command string, payload interface{}) (string, error) {
 	// 查找该节点上的UAV Agent Pod
 	pods, err := c.kubeClient.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: c.uavPodLabel,
 		FieldSelector: fmt.Sprintf("spec.nodeName=%s,status.phase=Running", nodeName),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to list UAV agent pods: %w", err)
+		return "", fmt.Errorf("failed to list UAV agent pods: %w", err)
 	}
 
 	if len(pods.Items) == 0 {
-		return fmt.Errorf("no UAV agent found on node %s", nodeName)
+		return "", fmt.Errorf("%w %s", ErrNoUAVAgent, nodeName)
 	}
 
 	pod := &pods.Items[0]
-	url := fmt.Sprintf("http://%s:9090/api/v1/command/%s", pod.Status.PodIP, command)
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+	url := fmt.Sprintf("%s://%s:9090/api/v1/command/%s", c.scheme, pod.Status.PodIP, command)
+	if index > 0 {
+		url += fmt.Sprintf("?uav=%d", index)
+	}
 
-	// 创建请求
-	var req *http.Request
+	// Agent要求 Content-Type 为 application/json 的请求体，没有参数时发送空对象
+	body := []byte("{}")
 	if payload != nil {
-		body, err := json.Marshal(payload)
-		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
-		}
-		req, err = http.NewRequestWithContext(ctx, "POST", url, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Body = http.NoBody
-		_ = body // TODO: 使用body
-	} else {
-		var err error
-		req, err = http.NewRequestWithContext(ctx, "POST", url, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+		if body, err = json.Marshal(payload); err != nil {
+			return "", fmt.Errorf("failed to marshal payload: %w", err)
 		}
 	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send command: %w", err)
+		return "", fmt.Errorf("failed to send command: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("command failed with status: %d", resp.StatusCode)
+	// 成功和失败的响应都是 {"status": ..., "message": ...}，参数校验失败时原因在 error.message 中
+	var apiResp struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Error   *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("failed to read command response: %w", err)
+	}
+	if err := json.Unmarshal(data, &apiResp); err != nil {
+		apiResp.Message = strings.TrimSpace(string(data))
+	}
+	if apiResp.Error != nil && apiResp.Message == "" {
+		apiResp.Message = apiResp.Error.Message
+	}
+
+	if resp.StatusCode != http.StatusOK || apiResp.Status == "error" {
+		rejected := &UAVCommandError{StatusCode: resp.StatusCode, Message: apiResp.Message}
+		if apiResp.Error != nil {
+			rejected.Code = apiResp.Error.Code
+		}
+		return "", rejected
 	}
 
-	return nil
+	c.logger.Infof("Sent %s command to UAV %d on node %s: %s", command, index, nodeName, apiResp.Message)
+	return apiResp.Message, nil
 }
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// UAVStalenessConfig UAV心跳超时检测配置
+type UAVStalenessConfig struct {
+	StaleAfter    int           // 错过多少个心跳间隔后标记为stale
+	LostAfter     int           // 错过多少个心跳间隔后标记为lost
+	CheckInterval time.Duration // 检查间隔
+}
+
+// uavStaleness 各UAV上一次检查的心跳状态
+type uavStaleness struct {
+	cfg    UAVStalenessConfig
+	levels map[string]string // 节点名 -> stale/lost，正常的节点不记录（snapshotMutex 保护）
+
+	mu       sync.Mutex
+	onChange func(ctx context.Context, changes []*models.UAVStatusChange)
+}
+
+func newUAVStaleness(cfg UAVStalenessConfig) *uavStaleness {
+	if cfg.StaleAfter < 1 {
+		cfg.StaleAfter = 3
+	}
+	if cfg.LostAfter <= cfg.StaleAfter {
+		cfg.LostAfter = cfg.StaleAfter + 1
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Second
+	}
+	return &uavStaleness{cfg: cfg, levels: make(map[string]string)}
+}
+
+// level 按错过的心跳数得到的状态，未超时为空
+func (s *uavStaleness) level(missed int) string {
+	switch {
+	case missed >= s.cfg.LostAfter:
+		return models.UAVStatusLost
+	case missed >= s.cfg.StaleAfter:
+		return models.UAVStatusStale
+	}
+	return ""
+}
+
+// OnUAVStatusChange 设置UAV心跳状态变化的回调（如更新UAVMetric CRD状态、记录K8s事件），
+// 在检查任务中同步调用；未启用心跳超时检测时不会调用
+func (m *Manager) OnUAVStatusChange(fn func(ctx context.Context, changes []*models.UAVStatusChange)) {
+	if m.staleness == nil {
+		return
+	}
+	m.staleness.mu.Lock()
+	m.staleness.onChange = fn
+	m.staleness.mu.Unlock()
+}
+
+// defaultHeartbeatInterval 没有上报心跳间隔的条目（主动拉取或旧版Agent）使用UAV采集间隔
+func (m *Manager) defaultHeartbeatInterval() time.Duration {
+	if interval, _, ok := m.scheduler.lookup(sources.CollectorUAV); ok {
+		return interval
+	}
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+	return m.interval
+}
+
+// checkUAVStaleness 按最后心跳时间检查各UAV错过的心跳数：超时的条目状态改为 stale/lost，
+// 重新收到心跳的条目恢复为上报的状态；状态变化时推送给订阅者并通知回调
+func (m *Manager) checkUAVStaleness(ctx context.Context, now time.Time) {
+	s := m.staleness
+	fallback := m.defaultHeartbeatInterval()
+	var changes []*models.UAVStatusChange
+
+	m.snapshotMutex.Lock()
+	for node := range s.levels {
+		if _, ok := m.uavSnapshot[node]; !ok {
+			delete(s.levels, node)
+		}
+	}
+	for node, entry := range m.uavSnapshot {
+		interval := fallback
+		if entry.HeartbeatIntervalSeconds > 0 {
+			interval = time.Duration(entry.HeartbeatIntervalSeconds) * time.Second
+		}
+		missed := 0
+		if interval > 0 && !entry.LastHeartbeat.IsZero() {
+			missed = int(now.Sub(entry.LastHeartbeat) / interval)
+		}
+		level := s.level(missed)
+
+		previous, tracked := s.levels[node]
+		if !tracked && (entry.Status == models.UAVStatusStale || entry.Status == models.UAVStatusLost) {
+			previous = entry.Status // 从持久化状态恢复的条目，之后收到心跳时同样记为恢复
+			s.levels[node] = previous
+		}
+		if level == previous {
+			continue
+		}
+
+		change := &models.UAVStatusChange{
+			NodeName:         entry.NodeName,
+			UAVID:            entry.UAVID,
+			Index:            entry.Index,
+			From:             entry.Status,
+			To:               level,
+			LastHeartbeat:    entry.LastHeartbeat,
+			MissedHeartbeats: missed,
+			Timestamp:        now.UTC(),
+		}
+		if change.NodeName == "" {
+			change.NodeName = node
+		}
+		if level == "" {
+			// 收到新心跳的条目已经是上报的状态
+			change.From, change.To = previous, entry.Status
+			delete(s.levels, node)
+		} else {
+			// 条目可能正被其他地方读取，替换为新的条目而不是就地修改
+			updated := entry.Clone()
+			updated.Status = level
+			m.uavSnapshot[node] = updated
+			s.levels[node] = level
+		}
+		changes = append(changes, change)
+	}
+	m.snapshotMutex.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	for _, change := range changes {
+		if change.Recovered() {
+			m.logger.Infof("UAV %s on node %s recovered (%s -> %s)", change.UAVID, change.NodeName, change.From, change.To)
+		} else {
+			m.logger.Warnf("UAV %s on node %s is %s: missed %d heartbeats, last heartbeat at %s",
+				change.UAVID, change.NodeName, change.To, change.MissedHeartbeats, change.LastHeartbeat.Format(time.RFC3339))
+		}
+		m.streamUAV(change.Key())
+	}
+
+	s.mu.Lock()
+	onChange := s.onChange
+	s.mu.Unlock()
+	if onChange != nil {
+		onChange(ctx, changes)
+	}
+}
+
+// stalenessLoop 定期检查UAV心跳超时
+func (m *Manager) stalenessLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.staleness.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			m.checkUAVStaleness(ctx, now)
+		}
+	}
+}
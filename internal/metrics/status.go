@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/remotewrite"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// CollectorStatus 单个采集器的状态
+type CollectorStatus struct {
+	Name               string                      `json:"name"`
+	Enabled            bool                        `json:"enabled"`
+	Degraded           bool                        `json:"degraded"` // 因权限不足部分或全部跳过
+	MissingPermissions []sources.MissingPermission `json:"missing_permissions"`
+	Interval           string                      `json:"interval,omitempty"`        // 实际使用的采集间隔
+	NextCollection     time.Time                   `json:"next_collection,omitempty"` // 下次采集时间
+	Health             *metricstypes.SourceStatus  `json:"health,omitempty"`          // 最近一次采集的结果
+}
+
+// Status 指标采集状态
+type Status struct {
+	Running            bool                        `json:"running"`
+	Interval           string                      `json:"interval"`
+	LastCollection     time.Time                   `json:"last_collection"`
+	Degraded           bool                        `json:"degraded"`
+	Collectors         []CollectorStatus           `json:"collectors"`
+	MissingPermissions []sources.MissingPermission `json:"missing_permissions"`
+	RemoteWrite        *remotewrite.Stats          `json:"remote_write,omitempty"` // 未配置 metrics.remote_write 时为空
+	Rollups            []RollupStats               `json:"rollups,omitempty"`      // 样本降采样各聚合层的进度
+}
+
+// Status 返回采集器状态以及缺少的RBAC权限
+func (m *Manager) Status() *Status {
+	m.runMutex.Lock()
+	running, interval := m.running, m.interval
+	m.runMutex.Unlock()
+
+	m.snapshotMutex.RLock()
+	lastCollection := m.snapshot.Timestamp
+	collection := m.snapshot.CollectionStatus.Clone()
+	m.snapshotMutex.RUnlock()
+
+	status := &Status{
+		Running:            running,
+		Interval:           interval.String(),
+		LastCollection:     lastCollection,
+		MissingPermissions: m.permissions.Missing(""),
+	}
+	if m.remoteWrite != nil {
+		stats := m.remoteWrite.Stats()
+		status.RemoteWrite = &stats
+	}
+	status.Rollups = m.RollupStatus()
+
+	for _, c := range m.collectors() {
+		missing := m.permissions.Missing(c.name)
+		collector := CollectorStatus{
+			Name:               c.name,
+			Enabled:            c.enabled,
+			Degraded:           c.enabled && len(missing) > 0,
+			MissingPermissions: missing,
+		}
+		if interval, next, ok := m.scheduler.lookup(c.name); ok {
+			collector.Interval = interval.String()
+			collector.NextCollection = next
+		}
+		if collection != nil {
+			collector.Health = collection.Sources[c.name]
+		}
+		status.Degraded = status.Degraded || collector.Degraded
+		status.Collectors = append(status.Collectors, collector)
+	}
+
+	return status
+}
+
+// observeSources 记录本轮采集的数据源的结果（results 中没有的数据源本轮未采集，数据标记为过期），
+// 返回写入快照的采集状态（调用方持有 collectMutex）
+func (m *Manager) observeSources(at time.Time, results map[string]error) *metricstypes.CollectionStatus {
+	if m.sourceStatus == nil {
+		m.sourceStatus = make(map[string]*metricstypes.SourceStatus)
+	}
+
+	collection := &metricstypes.CollectionStatus{Sources: make(map[string]*metricstypes.SourceStatus)}
+	for _, c := range m.collectors() {
+		if !c.enabled {
+			continue
+		}
+		status := m.sourceStatus[c.name]
+		if status == nil {
+			status = &metricstypes.SourceStatus{}
+			m.sourceStatus[c.name] = status
+		}
+
+		if err, ok := results[c.name]; ok {
+			status.LastAttempt = at
+			status.Status, status.Error, status.Stale = metricstypes.SourceOK, "", false
+			switch {
+			case err == nil:
+				status.LastSuccess = at
+			case sources.IsPartial(err):
+				status.Status, status.Error = metricstypes.SourcePartial, err.Error()
+				status.LastSuccess = at
+			default:
+				status.Status, status.Error, status.Stale = metricstypes.SourceFailed, err.Error(), true
+			}
+		} else if !status.LastAttempt.IsZero() {
+			status.Stale = true
+		}
+
+		if status.Status == metricstypes.SourcePartial || status.Status == metricstypes.SourceFailed {
+			collection.Degraded = true
+		}
+		clone := *status
+		collection.Sources[c.name] = &clone
+	}
+	return collection
+}
+
+// collectorState 采集器名称及是否启用
+type collectorState struct {
+	name    string
+	enabled bool
+}
+
+// collectors 所有采集器及其启用状态
+func (m *Manager) collectors() []collectorState {
+	return []collectorState{
+		{sources.CollectorNode, m.nodeSource != nil},
+		{sources.CollectorPod, m.podSource != nil},
+		{sources.CollectorNetwork, m.networkSource != nil},
+		{sources.CollectorUAV, m.uavSource != nil},
+		{sources.CollectorCustom, m.customSource != nil},
+		{sources.CollectorGPU, m.gpuSource != nil},
+		{sources.CollectorSummary, m.summarySource != nil},
+		{sources.CollectorNodeLatency, m.nodeLatencySource != nil},
+		{sources.CollectorExternal, m.externalSource != nil},
+	}
+}
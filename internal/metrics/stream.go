@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 实时推送的消息类型
+const (
+	StreamSnapshot = "snapshot" // 一次采集完成
+	StreamUAV      = "uav"      // 收到一次UAV上报
+)
+
+// streamBuffer 每个订阅者最多缓存的未发送更新，超出时断开该订阅者（客户端重连后重新获取完整快照）
+const streamBuffer = 16
+
+// StreamUpdate 推送给订阅者的更新。snapshot 消息默认只包含本轮新采集的数据源：
+// 节点/Pod按名称覆盖，removed_* 中的已不存在，未包含的保持不变；Full 为true时为完整快照
+type StreamUpdate struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Full      bool      `json:"full,omitempty"`
+
+	Nodes            map[string]*metricstypes.NodeMetrics `json:"nodes,omitempty"`
+	Pods             map[string]*metricstypes.PodMetrics  `json:"pods,omitempty"`
+	RemovedNodes     []string                             `json:"removed_nodes,omitempty"`
+	RemovedPods      []string                             `json:"removed_pods,omitempty"`
+	Network          []*metricstypes.NetworkMetrics       `json:"network,omitempty"` // 本轮完成网络测试时为全部结果
+	Cluster          *metricstypes.ClusterMetrics         `json:"cluster,omitempty"`
+	CollectionStatus *metricstypes.CollectionStatus       `json:"collection_status,omitempty"`
+	UAVs             map[string]*models.UAVMetricsEntry   `json:"uavs,omitempty"` // 本轮采集到的UAV状态，格式同 /api/v1/metrics/uav
+
+	Node string                  `json:"node,omitempty"` // uav 消息：上报的节点
+	UAV  *models.UAVMetricsEntry `json:"uav,omitempty"`  // uav 消息：该节点最新的UAV状态
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// JSON 编码后的消息（多个订阅者共用同一次编码）
+func (u *StreamUpdate) JSON() ([]byte, error) {
+	u.once.Do(func() {
+		u.data, u.err = json.Marshal(u)
+	})
+	return u.data, u.err
+}
+
+// streamHub 管理实时更新的订阅者
+type streamHub struct {
+	mu          sync.Mutex
+	subscribers map[chan *StreamUpdate]struct{}
+}
+
+// active 是否有订阅者（没有时不生成更新）
+func (h *streamHub) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers) > 0
+}
+
+// broadcast 发送更新，订阅者的缓冲已满时关闭其channel
+func (h *streamHub) broadcast(update *StreamUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- update:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe 订阅实时更新：第一条消息为当前的完整快照，之后每次采集完成或收到UAV上报时推送更新。
+// 消费过慢时channel被关闭；调用返回的函数取消订阅
+func (m *Manager) Subscribe() (<-chan *StreamUpdate, func()) {
+	ch := make(chan *StreamUpdate, streamBuffer)
+
+	// 持有锁直到注册完成，保证完整快照之后的更新不会丢失或先于完整快照到达
+	m.stream.mu.Lock()
+	defer m.stream.mu.Unlock()
+
+	snapshot := m.ViewSnapshot()
+	ch <- &StreamUpdate{
+		Type:             StreamSnapshot,
+		Timestamp:        snapshot.Timestamp,
+		Full:             true,
+		Nodes:            snapshot.NodeMetrics,
+		Pods:             snapshot.PodMetrics,
+		Network:          snapshot.NetworkMetrics,
+		Cluster:          snapshot.ClusterMetrics,
+		CollectionStatus: snapshot.CollectionStatus,
+		UAVs:             m.GetUAVMetrics(),
+	}
+
+	if m.stream.subscribers == nil {
+		m.stream.subscribers = make(map[chan *StreamUpdate]struct{})
+	}
+	m.stream.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		m.stream.mu.Lock()
+		defer m.stream.mu.Unlock()
+		if _, ok := m.stream.subscribers[ch]; ok {
+			delete(m.stream.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// streamSnapshot 推送一次采集的结果：fresh 只包含本轮新采集的数据源，
+// 与上一次快照比较得到已消失的节点和Pod
+func (m *Manager) streamSnapshot(previous, snapshot, fresh *metricstypes.MetricsSnapshot, nodes, pods, network, uavs bool) {
+	if !m.stream.active() {
+		return
+	}
+
+	update := &StreamUpdate{
+		Type:             StreamSnapshot,
+		Timestamp:        snapshot.Timestamp,
+		Cluster:          snapshot.ClusterMetrics,
+		CollectionStatus: snapshot.CollectionStatus,
+	}
+	if uavs {
+		update.UAVs = m.GetUAVMetrics()
+	}
+	if nodes {
+		update.Nodes = fresh.NodeMetrics
+		if previous != nil {
+			update.RemovedNodes = removedKeys(previous.NodeMetrics, snapshot.NodeMetrics)
+		}
+	}
+	if pods {
+		update.Pods = fresh.PodMetrics
+		if previous != nil {
+			update.RemovedPods = removedKeys(previous.PodMetrics, snapshot.PodMetrics)
+		}
+	}
+	if network {
+		update.Network = fresh.NetworkMetrics
+	}
+	m.stream.broadcast(update)
+}
+
+// streamUAV 推送一次UAV上报
+func (m *Manager) streamUAV(nodeName string) {
+	if !m.stream.active() {
+		return
+	}
+	entry, ok := m.GetSingleUAVMetrics(nodeName)
+	if !ok {
+		return
+	}
+	m.stream.broadcast(&StreamUpdate{Type: StreamUAV, Timestamp: time.Now().UTC(), Node: nodeName, UAV: entry})
+}
+
+// removedKeys previous 中有而 current 中没有的键
+func removedKeys[T any](previous, current map[string]T) []string {
+	var removed []string
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,253 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// NodeConditionCollection 节点条件变化在存储中的集合名
+const NodeConditionCollection = "node_conditions"
+
+// ErrNodeNotFound 最近一次快照中没有该节点
+var ErrNodeNotFound = errors.New("node not found")
+
+// 条件时间线参数
+const (
+	maxConditionTransitions = 200 // 未配置存储时每个节点在内存中保留的变化记录
+	conditionSeedLimit      = 100 // 节点首次出现时从存储读取的最近变化数，用于恢复各条件的上一次状态
+	defaultTimelineWindow   = 24 * time.Hour
+)
+
+// ConditionTransition 节点条件的一次状态变化
+type ConditionTransition struct {
+	Node       string    `json:"node"`
+	Condition  string    `json:"condition"`          // Ready、MemoryPressure、DiskPressure等
+	Status     string    `json:"status"`             // 变化后的状态
+	Previous   string    `json:"previous,omitempty"` // 变化前的状态，首次观察到异常时为空
+	Reason     string    `json:"reason,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Abnormal   bool      `json:"abnormal"`    // 变化后是否为异常状态（NotReady或存在压力）
+	Timestamp  time.Time `json:"timestamp"`   // 条件的 lastTransitionTime
+	DetectedAt time.Time `json:"detected_at"` // 采集时发现变化的时间
+}
+
+// NodePodRestart 节点上一次Pod容器重启，用于与条件变化对照
+type NodePodRestart struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	RestartEvent
+}
+
+// NodeTimeline 节点在时间范围内的条件变化和Pod重启（均按时间升序）
+type NodeTimeline struct {
+	Node        string                       `json:"node"`
+	From        time.Time                    `json:"from"`
+	To          time.Time                    `json:"to"`
+	Source      string                       `json:"source"`  // storage 或 memory
+	Current     []metricstypes.NodeCondition `json:"current"` // 最近一次采集到的条件状态
+	Transitions []ConditionTransition        `json:"transitions"`
+	PodRestarts []NodePodRestart             `json:"pod_restarts"`
+}
+
+// conditionTracker 记录各节点条件的上一次状态，比较每次采集结果得到条件变化
+type conditionTracker struct {
+	mu          sync.RWMutex
+	states      map[string]map[string]string // node -> condition -> status
+	transitions map[string][]ConditionTransition
+}
+
+// newConditionTracker 创建条件跟踪器
+func newConditionTracker() *conditionTracker {
+	return &conditionTracker{
+		states:      make(map[string]map[string]string),
+		transitions: make(map[string][]ConditionTransition),
+	}
+}
+
+// known 是否已经记录过该节点的条件状态
+func (t *conditionTracker) known(node string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.states[node]
+	return ok
+}
+
+// seed 用存储中的最近变化恢复节点各条件的上一次状态（按时间升序，后面的覆盖前面的）
+func (t *conditionTracker) seed(node string, transitions []ConditionTransition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	states := make(map[string]string)
+	for _, transition := range transitions {
+		states[transition.Condition] = transition.Status
+	}
+	t.states[node] = states
+}
+
+// observe 比较节点当前的条件与上一次状态，返回发生的变化。
+// 首次出现的条件只在处于异常状态时记录
+func (t *conditionTracker) observe(node string, conditions []metricstypes.NodeCondition, now time.Time) []ConditionTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := t.states[node]
+	if states == nil {
+		states = make(map[string]string)
+		t.states[node] = states
+	}
+
+	var changes []ConditionTransition
+	for _, condition := range conditions {
+		previous, seen := states[condition.Type]
+		states[condition.Type] = condition.Status
+		if seen && previous == condition.Status {
+			continue
+		}
+		if !seen && !condition.Abnormal() {
+			continue
+		}
+		timestamp := condition.LastTransitionTime
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+		changes = append(changes, ConditionTransition{
+			Node:       node,
+			Condition:  condition.Type,
+			Status:     condition.Status,
+			Previous:   previous,
+			Reason:     condition.Reason,
+			Message:    condition.Message,
+			Abnormal:   condition.Abnormal(),
+			Timestamp:  timestamp,
+			DetectedAt: now,
+		})
+	}
+	return changes
+}
+
+// remember 未配置存储时将变化保存在内存中
+func (t *conditionTracker) remember(transition ConditionTransition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	transitions := append(t.transitions[transition.Node], transition)
+	if len(transitions) > maxConditionTransitions {
+		transitions = transitions[len(transitions)-maxConditionTransitions:]
+	}
+	t.transitions[transition.Node] = transitions
+}
+
+// list 内存中节点在时间范围内的变化（按时间升序）
+func (t *conditionTracker) list(node string, from, to time.Time) []ConditionTransition {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := []ConditionTransition{}
+	for _, transition := range t.transitions[node] {
+		if transition.Timestamp.Before(from) || transition.Timestamp.After(to) {
+			continue
+		}
+		result = append(result, transition)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result
+}
+
+// recordConditions 记录本轮采集到的节点条件变化，配置了存储时写入存储，否则保存在内存中
+func (m *Manager) recordConditions(ctx context.Context, nodes map[string]*metricstypes.NodeMetrics, now time.Time) {
+	for name, node := range nodes {
+		if m.store != nil && !m.conditions.known(name) {
+			previous, err := m.storedTransitions(ctx, storage.Query{Key: name, Limit: conditionSeedLimit})
+			if err != nil {
+				m.logger.Warnf("Failed to load condition history of node %s: %v", name, err)
+			}
+			m.conditions.seed(name, previous)
+		}
+
+		for _, transition := range m.conditions.observe(name, node.ConditionDetails, now) {
+			m.logger.Infof("Node %s condition %s changed to %s", name, transition.Condition, transition.Status)
+			if m.store == nil {
+				m.conditions.remember(transition)
+				continue
+			}
+			data, err := json.Marshal(transition)
+			if err != nil {
+				continue
+			}
+			record := &storage.Record{
+				ID:        fmt.Sprintf("%s/%s@%d", name, transition.Condition, transition.Timestamp.UnixNano()),
+				Key:       name,
+				Timestamp: transition.Timestamp,
+				Data:      data,
+			}
+			if err := m.store.Append(ctx, NodeConditionCollection, record); err != nil {
+				m.logger.Warnf("Failed to store condition transition of node %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// storedTransitions 从存储读取条件变化（按时间升序）
+func (m *Manager) storedTransitions(ctx context.Context, query storage.Query) ([]ConditionTransition, error) {
+	records, err := m.store.List(ctx, NodeConditionCollection, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list condition transitions: %w", err)
+	}
+	transitions := make([]ConditionTransition, 0, len(records))
+	for _, record := range records {
+		var transition ConditionTransition
+		if err := json.Unmarshal(record.Data, &transition); err != nil {
+			continue
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, nil
+}
+
+// NodeTimeline 返回节点在时间范围内的条件变化（最多 limit 条，超出时保留最新的）以及该节点上Pod的容器重启，
+// 方便将“节点在14:02变为NotReady”与Pod故障对照。from 为零值时为 to 之前24小时
+func (m *Manager) NodeTimeline(ctx context.Context, node string, from, to time.Time, limit int) (*NodeTimeline, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultTimelineWindow)
+	}
+
+	current, ok := m.ViewSnapshot().NodeMetrics[node]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, node)
+	}
+
+	timeline := &NodeTimeline{
+		Node:        node,
+		From:        from,
+		To:          to,
+		Source:      "memory",
+		Current:     current.ConditionDetails,
+		PodRestarts: m.restarts.onNode(node, from, to),
+	}
+	if timeline.Current == nil {
+		timeline.Current = []metricstypes.NodeCondition{}
+	}
+
+	if m.store != nil {
+		timeline.Source = "storage"
+		transitions, err := m.storedTransitions(ctx, storage.Query{Key: node, From: from, To: to, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		timeline.Transitions = transitions
+	} else {
+		timeline.Transitions = m.conditions.list(node, from, to)
+		if limit > 0 && len(timeline.Transitions) > limit {
+			timeline.Transitions = timeline.Transitions[len(timeline.Transitions)-limit:]
+		}
+	}
+	return timeline, nil
+}
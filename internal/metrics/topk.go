@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// TopQuery 节点/Pod列表的过滤、排序和数量限制
+type TopQuery struct {
+	Namespace string // 只对Pod有效，为空时包含所有命名空间
+	Sort      string // 排序字段，为空时按名称排序
+	Desc      bool
+	Limit     int // 为0时不限制
+}
+
+// podSortFields Pod可排序的字段
+var podSortFields = map[string]func(*metricstypes.PodMetrics) float64{
+	"cpu_usage":               func(p *metricstypes.PodMetrics) float64 { return float64(p.CPUUsage) },
+	"cpu_usage_rate":          func(p *metricstypes.PodMetrics) float64 { return p.CPUUsageRate },
+	"cpu_usage_trend":         func(p *metricstypes.PodMetrics) float64 { return p.CPUUsageTrend },
+	"cpu_request":             func(p *metricstypes.PodMetrics) float64 { return float64(p.CPURequest) },
+	"memory_usage":            func(p *metricstypes.PodMetrics) float64 { return float64(p.MemoryUsage) },
+	"memory_usage_rate":       func(p *metricstypes.PodMetrics) float64 { return p.MemoryUsageRate },
+	"memory_request":          func(p *metricstypes.PodMetrics) float64 { return float64(p.MemoryRequest) },
+	"restarts":                func(p *metricstypes.PodMetrics) float64 { return float64(p.Restarts) },
+	"restart_rate":            func(p *metricstypes.PodMetrics) float64 { return p.RestartRate },
+	"ephemeral_storage_usage": func(p *metricstypes.PodMetrics) float64 { return float64(p.EphemeralStorageUsage) },
+	"gpu_request":             func(p *metricstypes.PodMetrics) float64 { return float64(p.GPURequest) },
+	"gpu_usage":               func(p *metricstypes.PodMetrics) float64 { return p.GPUUsage },
+	"gpu_usage_rate":          func(p *metricstypes.PodMetrics) float64 { return p.GPUUsageRate },
+	"gpu_memory_used":         func(p *metricstypes.PodMetrics) float64 { return float64(p.GPUMemoryUsed) },
+}
+
+// nodeSortFields 节点可排序的字段
+var nodeSortFields = map[string]func(*metricstypes.NodeMetrics) float64{
+	"cpu_usage":         func(n *metricstypes.NodeMetrics) float64 { return float64(n.CPUUsage) },
+	"cpu_usage_rate":    func(n *metricstypes.NodeMetrics) float64 { return n.CPUUsageRate },
+	"cpu_usage_trend":   func(n *metricstypes.NodeMetrics) float64 { return n.CPUUsageTrend },
+	"memory_usage":      func(n *metricstypes.NodeMetrics) float64 { return float64(n.MemoryUsage) },
+	"memory_usage_rate": func(n *metricstypes.NodeMetrics) float64 { return n.MemoryUsageRate },
+	"disk_usage_rate":   func(n *metricstypes.NodeMetrics) float64 { return n.DiskUsageRate },
+	"network_latency":   func(n *metricstypes.NodeMetrics) float64 { return n.NetworkLatency },
+	"network_rx_rate":   func(n *metricstypes.NodeMetrics) float64 { return n.NetworkRxRate },
+	"network_tx_rate":   func(n *metricstypes.NodeMetrics) float64 { return n.NetworkTxRate },
+	"gpu_count":         func(n *metricstypes.NodeMetrics) float64 { return float64(n.GPUCount) },
+}
+
+// TopPods 按条件过滤并排序Pod，返回前 Limit 个以及过滤后的总数
+func TopPods(pods map[string]*metricstypes.PodMetrics, query TopQuery) ([]*metricstypes.PodMetrics, int, error) {
+	value, err := sortField(podSortFields, query.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keys := make([]string, 0, len(pods))
+	for key, pod := range pods {
+		if query.Namespace == "" || pod.Namespace == query.Namespace {
+			keys = append(keys, key)
+		}
+	}
+	total := len(keys)
+	sortKeys(keys, query.Desc, func(key string) float64 { return value(pods[key]) }, value != nil)
+	if query.Limit > 0 && len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+
+	result := make([]*metricstypes.PodMetrics, len(keys))
+	for i, key := range keys {
+		result[i] = pods[key]
+	}
+	return result, total, nil
+}
+
+// TopNodes 按条件排序节点，返回前 Limit 个以及节点总数
+func TopNodes(nodes map[string]*metricstypes.NodeMetrics, query TopQuery) ([]*metricstypes.NodeMetrics, int, error) {
+	value, err := sortField(nodeSortFields, query.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keys := make([]string, 0, len(nodes))
+	for key := range nodes {
+		keys = append(keys, key)
+	}
+	sortKeys(keys, query.Desc, func(key string) float64 { return value(nodes[key]) }, value != nil)
+	total := len(keys)
+	if query.Limit > 0 && len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+
+	result := make([]*metricstypes.NodeMetrics, len(keys))
+	for i, key := range keys {
+		result[i] = nodes[key]
+	}
+	return result, total, nil
+}
+
+// sortField 查找排序字段，为空时返回nil（按名称排序）
+func sortField[T any](fields map[string]func(T) float64, name string) (func(T) float64, error) {
+	if name == "" || name == "name" {
+		return nil, nil
+	}
+	value, ok := fields[name]
+	if !ok {
+		names := make([]string, 0, len(fields))
+		for field := range fields {
+			names = append(names, field)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unsupported sort field %q (supported: name, %s)", name, strings.Join(names, ", "))
+	}
+	return value, nil
+}
+
+// sortKeys 按字段值排序（值相同时按名称），byValue 为false时只按名称排序
+func sortKeys(keys []string, desc bool, value func(string) float64, byValue bool) {
+	sort.Slice(keys, func(i, j int) bool {
+		if byValue {
+			if a, b := value(keys[i]), value(keys[j]); a != b {
+				return (a > b) == desc
+			}
+		}
+		return (keys[i] < keys[j]) != (desc && !byValue)
+	})
+}
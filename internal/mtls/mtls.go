@@ -0,0 +1,256 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options 证书配置（cert-manager签发的Secret挂载后对应 tls.crt / tls.key / ca.crt）
+type Options struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	// AllowedSANs 允许的对端身份（DNS名称或URI SAN，支持 path.Match 通配符），为空时只校验证书链
+	AllowedSANs []string
+}
+
+// ErrNoPeerCertificate 请求没有携带经过验证的客户端证书
+var ErrNoPeerCertificate = errors.New("mtls: no verified client certificate")
+
+// NodePlaceholder 节点SAN模板中替换为节点名的占位符
+const NodePlaceholder = "{node}"
+
+// CodeNodeMismatch 客户端证书与上报节点不一致的错误码
+const CodeNodeMismatch = "client_identity_mismatch"
+
+// keyPairLoader 按需重新加载证书（cert-manager轮换证书时无需重启）
+type keyPairLoader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// load 证书文件有变化时重新加载
+func (l *keyPairLoader) load() (*tls.Certificate, error) {
+	info, err := os.Stat(l.certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat certificate %s: %w", l.certFile, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cert != nil && !info.ModTime().After(l.modTime) {
+		return l.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		if l.cert != nil {
+			// 轮换过程中文件可能尚未写完，继续使用旧证书
+			return l.cert, nil
+		}
+		return nil, fmt.Errorf("failed to load key pair: %w", err)
+	}
+	l.cert = &cert
+	l.modTime = info.ModTime()
+	return l.cert, nil
+}
+
+// loadCAPool 读取CA证书
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file %s: %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+	return pool, nil
+}
+
+// newLoader 创建证书加载器并立即校验一次
+func newLoader(opts Options) (*keyPairLoader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, fmt.Errorf("mtls: cert and key files are required")
+	}
+	loader := &keyPairLoader{certFile: opts.CertFile, keyFile: opts.KeyFile}
+	if _, err := loader.load(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// ServerConfig 创建服务端TLS配置；clientAuth 决定是否强制要求客户端证书
+func ServerConfig(opts Options, clientAuth tls.ClientAuthType) (*tls.Config, error) {
+	loader, err := newLoader(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return loader.load()
+		},
+	}
+
+	if clientAuth != tls.NoClientCert {
+		if opts.CAFile == "" {
+			return nil, fmt.Errorf("mtls: CA file is required to verify client certificates")
+		}
+		if config.ClientCAs, err = loadCAPool(opts.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// ClientConfig 创建客户端TLS配置：携带本端证书，按CA校验服务端证书链，
+// 并按 AllowedSANs 校验服务端身份（Pod通常通过IP访问，因此不使用主机名校验）
+func ClientConfig(opts Options) (*tls.Config, error) {
+	loader, err := newLoader(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CAFile == "" {
+		return nil, fmt.Errorf("mtls: CA file is required to verify server certificates")
+	}
+	roots, err := loadCAPool(opts.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    roots,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loader.load()
+		},
+	}
+
+	if len(opts.AllowedSANs) > 0 {
+		allowed := opts.AllowedSANs
+		// 自行校验证书链和SAN，代替默认的主机名校验
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("mtls: server presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			leaf := state.PeerCertificates[0]
+			if _, err := leaf.Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}); err != nil {
+				return fmt.Errorf("mtls: invalid server certificate: %w", err)
+			}
+			if !MatchSAN(CertificateSANs(leaf), allowed) {
+				return fmt.Errorf("mtls: server identity %v not allowed", CertificateSANs(leaf))
+			}
+			return nil
+		}
+	}
+	return config, nil
+}
+
+// HTTPClient 创建使用mTLS的HTTP客户端
+func HTTPClient(opts Options, timeout time.Duration) (*http.Client, error) {
+	config, err := ClientConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// CertificateSANs 返回证书中的DNS和URI SAN
+func CertificateSANs(cert *x509.Certificate) []string {
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+// PeerSANs 返回请求中经过验证的客户端证书的SAN
+func PeerSANs(r *http.Request) ([]string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoPeerCertificate
+	}
+	return CertificateSANs(r.TLS.VerifiedChains[0][0]), nil
+}
+
+// MatchSAN 判断任一SAN是否匹配允许的模式
+func MatchSAN(sans, patterns []string) bool {
+	for _, pattern := range patterns {
+		for _, san := range sans {
+			if ok, err := path.Match(pattern, san); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchNode 判断SAN中是否有属于该节点的身份：模板中的 {node} 替换为节点名后精确匹配
+func MatchNode(sans, templates []string, node string) bool {
+	if node == "" {
+		return false
+	}
+	for _, template := range templates {
+		want := strings.ReplaceAll(template, NodePlaceholder, node)
+		for _, san := range sans {
+			if san == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// identityContextKey 校验通过的客户端证书SAN在请求上下文中的键
+type identityContextKey struct{}
+
+// PeerIdentity 返回 RequireSAN 校验通过的客户端证书SAN
+func PeerIdentity(ctx context.Context) ([]string, bool) {
+	sans, ok := ctx.Value(identityContextKey{}).([]string)
+	return sans, ok
+}
+
+// RequireSAN 要求请求携带经过验证的客户端证书，且SAN匹配允许的模式（为空时任何有效证书均可）；
+// 证书SAN写入请求上下文，处理函数可用 MatchNode 确认其与请求内容中的节点一致
+func RequireSAN(patterns []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sans, err := PeerSANs(r)
+		if err != nil {
+			http.Error(w, "Client certificate required", http.StatusUnauthorized)
+			return
+		}
+		if len(patterns) > 0 && !MatchSAN(sans, patterns) {
+			http.Error(w, fmt.Sprintf("Client identity %v not allowed", sans), http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), identityContextKey{}, sans)))
+	}
+}
@@ -0,0 +1,282 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA 测试用的自签名CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	file := filepath.Join(t.TempDir(), "ca.crt")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue 签发带给定DNS SAN的证书（同时可用于服务端和客户端认证），返回 Options
+func (ca *testCA) issue(t *testing.T, dnsNames ...string) Options {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	opts := Options{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key"), CAFile: ca.file}
+	writePEM(t, opts.CertFile, "CERTIFICATE", der)
+	writePEM(t, opts.KeyFile, "EC PRIVATE KEY", keyDER)
+	return opts
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+// startServer 启动要求客户端证书SAN匹配 patterns 的HTTPS服务，处理函数返回 PeerIdentity；返回服务地址。
+// 不使用 httptest 的TLS服务，因为它会用自带的证书代替 GetCertificate
+func startServer(t *testing.T, opts Options, patterns []string) string {
+	t.Helper()
+	config, err := ServerConfig(opts, tls.VerifyClientCertIfGiven)
+	if err != nil {
+		t.Fatalf("ServerConfig() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{
+		Handler: RequireSAN(patterns, func(w http.ResponseWriter, r *http.Request) {
+			sans, _ := PeerIdentity(r.Context())
+			io.WriteString(w, strings.Join(sans, ","))
+		}),
+		TLSConfig: config,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+func TestRequireSAN(t *testing.T) {
+	ca := newTestCA(t)
+	server := startServer(t, ca.issue(t, "k8s-llm-monitor.default.svc"), []string{"uav-agent.*.svc"})
+
+	tests := []struct {
+		name       string
+		client     Options
+		noCert     bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "allowed agent", client: ca.issue(t, "uav-agent.default.svc", "edge-a.uav-agent.default.svc"), wantStatus: http.StatusOK, wantBody: "uav-agent.default.svc,edge-a.uav-agent.default.svc"},
+		{name: "identity not allowed", client: ca.issue(t, "dashboard.default.svc"), wantStatus: http.StatusForbidden},
+		{name: "no client certificate", noCert: true, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client *http.Client
+			if tt.noCert {
+				pool := x509.NewCertPool()
+				pool.AddCert(ca.cert)
+				client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+			} else {
+				var err error
+				if client, err = HTTPClient(tt.client, 5*time.Second); err != nil {
+					t.Fatalf("HTTPClient() error = %v", err)
+				}
+			}
+
+			resp, err := client.Get(server)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("PeerIdentity() = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// 其他CA签发的客户端证书在握手时被拒绝
+func TestServerRejectsUntrustedClient(t *testing.T) {
+	ca := newTestCA(t)
+	server := startServer(t, ca.issue(t, "k8s-llm-monitor.default.svc"), nil)
+
+	other := newTestCA(t)
+	opts := other.issue(t, "uav-agent.default.svc")
+	opts.CAFile = ca.file // 信任服务端证书
+	client, err := HTTPClient(opts, 5*time.Second)
+	if err != nil {
+		t.Fatalf("HTTPClient() error = %v", err)
+	}
+	if resp, err := client.Get(server); err == nil {
+		resp.Body.Close()
+		t.Fatalf("GET with an untrusted client certificate status = %d, want handshake error", resp.StatusCode)
+	}
+}
+
+// 客户端按 AllowedSANs 校验服务端身份
+func TestClientVerifiesServerSAN(t *testing.T) {
+	ca := newTestCA(t)
+	server := startServer(t, ca.issue(t, "uav-agent.default.svc"), nil)
+
+	tests := []struct {
+		name    string
+		allowed []string
+		wantErr bool
+	}{
+		{name: "allowed", allowed: []string{"uav-agent.*.svc"}},
+		{name: "not allowed", allowed: []string{"k8s-llm-monitor.*.svc"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ca.issue(t, "k8s-llm-monitor.default.svc")
+			opts.AllowedSANs = tt.allowed
+			client, err := HTTPClient(opts, 5*time.Second)
+			if err != nil {
+				t.Fatalf("HTTPClient() error = %v", err)
+			}
+			resp, err := client.Get(server)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GET error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchNode(t *testing.T) {
+	templates := []string{"{node}.uav-agent.default.svc", "spiffe://cluster.local/node/{node}"}
+	tests := []struct {
+		name string
+		sans []string
+		node string
+		want bool
+	}{
+		{name: "dns identity", sans: []string{"uav-agent.default.svc", "edge-a.uav-agent.default.svc"}, node: "edge-a", want: true},
+		{name: "uri identity", sans: []string{"spiffe://cluster.local/node/edge-a"}, node: "edge-a", want: true},
+		{name: "other node", sans: []string{"edge-a.uav-agent.default.svc"}, node: "edge-b"},
+		{name: "node name is a suffix", sans: []string{"x-edge-a.uav-agent.default.svc"}, node: "edge-a"},
+		{name: "shared certificate", sans: []string{"uav-agent.default.svc"}, node: "edge-a"},
+		{name: "no node", sans: []string{".uav-agent.default.svc"}, node: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchNode(tt.sans, templates, tt.node); got != tt.want {
+				t.Errorf("MatchNode(%v, %q) = %v, want %v", tt.sans, tt.node, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchSAN(t *testing.T) {
+	patterns := []string{"uav-agent.*.svc", "spiffe://cluster.local/*"}
+	if !MatchSAN([]string{"uav-agent.default.svc"}, patterns) {
+		t.Error("MatchSAN() = false for a matching DNS SAN")
+	}
+	if !MatchSAN([]string{"spiffe://cluster.local/agent"}, patterns) {
+		t.Error("MatchSAN() = false for a matching URI SAN")
+	}
+	if MatchSAN([]string{"uav-agent.default.svc.cluster.local", "spiffe://cluster.local/a/b"}, patterns) {
+		t.Error("MatchSAN() = true for non-matching SANs")
+	}
+}
+
+// 证书文件更新后按需重新加载，写入不完整时继续使用旧证书
+func TestKeyPairReload(t *testing.T) {
+	ca := newTestCA(t)
+	opts := ca.issue(t, "first.default.svc")
+	loader, err := newLoader(opts)
+	if err != nil {
+		t.Fatalf("newLoader() error = %v", err)
+	}
+
+	rotated := ca.issue(t, "second.default.svc")
+	for _, pair := range [][2]string{{rotated.CertFile, opts.CertFile}, {rotated.KeyFile, opts.KeyFile}} {
+		data, _ := os.ReadFile(pair[0])
+		os.WriteFile(pair[1], data, 0o600)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(opts.CertFile, future, future)
+
+	cert, err := loader.load()
+	if err != nil {
+		t.Fatalf("load() after rotation error = %v", err)
+	}
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf == nil || leaf.DNSNames[0] != "second.default.svc" {
+		t.Fatalf("load() after rotation did not pick up the new certificate")
+	}
+
+	os.WriteFile(opts.KeyFile, []byte("partial"), 0o600)
+	later := future.Add(time.Minute)
+	os.Chtimes(opts.CertFile, later, later)
+	if _, err := loader.load(); err != nil {
+		t.Errorf("load() with a half-written key pair error = %v, want the previous certificate", err)
+	}
+}
@@ -0,0 +1,350 @@
+package netprobe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// Collection 探测结果在存储中的集合名（记录的Key为探测名称）
+const Collection = "connectivity_probes"
+
+// 探测状态
+const (
+	StateUnknown  = "unknown"  // 还没有确定的结果
+	StateUp       = "up"       // 最近一次成功
+	StateDown     = "down"     // 最近一次失败
+	StateFlapping = "flapping" // 最近的结果中成功/失败频繁切换
+)
+
+// checkTimeout 单次探测的超时
+const checkTimeout = 30 * time.Second
+
+// ErrNotFound 探测不存在
+var ErrNotFound = errors.New("connectivity probe not found")
+
+// Checker 执行一次连通性探测，由 *k8s.Client 实现
+type Checker interface {
+	CheckConnectivity(ctx context.Context, check k8s.ConnectivityCheck) (*k8s.ConnectivityResult, error)
+}
+
+// Sample 一次探测的结果。Inconclusive 表示没能选出源或目标Pod，不参与状态和抖动的判断
+type Sample struct {
+	Timestamp time.Time `json:"timestamp"`
+	k8s.ConnectivityResult
+	Inconclusive bool `json:"inconclusive,omitempty"`
+}
+
+// Status 探测的当前状态
+type Status struct {
+	Name        string     `json:"name"`
+	Source      string     `json:"source"` // namespace/selector
+	Target      string     `json:"target"` // namespace/selector 或 URL
+	Method      string     `json:"method"`
+	Interval    int        `json:"interval"` // 秒
+	State       string     `json:"state"`
+	Transitions int        `json:"transitions"`  // 抖动窗口内成功/失败的切换次数
+	SuccessRate float64    `json:"success_rate"` // 保留的历史中成功的比例 (0-100)
+	AverageRTT  float64    `json:"avg_rtt_ms"`   // 保留的历史中成功探测的平均耗时
+	LastChange  *time.Time `json:"last_change,omitempty"`
+	Last        *Sample    `json:"last,omitempty"`
+	Samples     int        `json:"samples"`
+}
+
+// Scheduler 按各探测的间隔定期执行连通性探测，保留每个探测的历史并判断链路是否抖动
+type Scheduler struct {
+	checker       Checker
+	store         storage.Store
+	probes        []config.ConnectivityProbeConfig
+	history       int
+	flapWindow    int
+	flapThreshold int
+	logger        *logrus.Entry
+
+	mu      sync.RWMutex
+	samples map[string][]Sample
+	states  map[string]string
+}
+
+// NewScheduler 创建探测调度器，store 可为空（只保留内存中的历史）
+func NewScheduler(cfg config.ConnectivityProbesConfig, checker Checker, store storage.Store) *Scheduler {
+	if cfg.History <= 0 {
+		cfg.History = 120
+	}
+	if cfg.FlapWindow <= 1 {
+		cfg.FlapWindow = 10
+	}
+	if cfg.FlapThreshold <= 0 {
+		cfg.FlapThreshold = 3
+	}
+	return &Scheduler{
+		checker:       checker,
+		store:         store,
+		probes:        cfg.Probes,
+		history:       cfg.History,
+		flapWindow:    cfg.FlapWindow,
+		flapThreshold: cfg.FlapThreshold,
+		logger:        logging.For("netprobe"),
+		samples:       make(map[string][]Sample, len(cfg.Probes)),
+		states:        make(map[string]string, len(cfg.Probes)),
+	}
+}
+
+// Start 从存储恢复历史，并为每个探测启动定时循环，阻塞直到ctx取消
+func (s *Scheduler) Start(ctx context.Context) {
+	s.restore(ctx)
+	s.logger.Infof("Connectivity probes started (%d probes, flap window: %d, threshold: %d)", len(s.probes), s.flapWindow, s.flapThreshold)
+
+	var wg sync.WaitGroup
+	for _, p := range s.probes {
+		wg.Add(1)
+		go func(p config.ConnectivityProbeConfig) {
+			defer wg.Done()
+			s.loop(ctx, p)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, p config.ConnectivityProbeConfig) {
+	ticker := time.NewTicker(time.Duration(p.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx, p)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一次探测并记录结果
+func (s *Scheduler) RunOnce(ctx context.Context, p config.ConnectivityProbeConfig) Sample {
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	sample := Sample{Timestamp: time.Now().UTC()}
+	result, err := s.checker.CheckConnectivity(checkCtx, k8s.ConnectivityCheck{
+		SourceNamespace: p.SourceNamespace,
+		SourceSelector:  p.SourceSelector,
+		TargetNamespace: p.TargetNamespace,
+		TargetSelector:  p.TargetSelector,
+		URL:             p.URL,
+		Method:          p.Method,
+		Port:            p.Port,
+		AllowNoReply:    p.AllowNoReply,
+	})
+	if err != nil {
+		sample.Inconclusive = true
+		sample.Error = err.Error()
+	} else {
+		sample.ConnectivityResult = *result
+	}
+	if ctx.Err() != nil {
+		return sample
+	}
+	s.record(ctx, p.Name, sample)
+	return sample
+}
+
+// record 追加结果、更新状态，状态变化时记录日志
+func (s *Scheduler) record(ctx context.Context, name string, sample Sample) {
+	s.mu.Lock()
+	samples := append(s.samples[name], sample)
+	if len(samples) > s.history {
+		samples = samples[len(samples)-s.history:]
+	}
+	s.samples[name] = samples
+	state, transitions, _ := s.evaluate(samples)
+	previous := s.states[name]
+	s.states[name] = state
+	s.mu.Unlock()
+
+	if previous != "" && previous != state {
+		switch state {
+		case StateDown, StateFlapping:
+			s.logger.Warnf("Connectivity probe %s is %s (was %s, %d transitions in the last %d results): %s",
+				name, state, previous, transitions, s.flapWindow, sample.Error)
+		default:
+			s.logger.Infof("Connectivity probe %s is %s (was %s)", name, state, previous)
+		}
+	}
+
+	if s.store == nil {
+		return
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return
+	}
+	record := &storage.Record{
+		ID:        name + "-" + strconv.FormatInt(sample.Timestamp.UnixNano(), 10),
+		Key:       name,
+		Timestamp: sample.Timestamp,
+		Data:      data,
+	}
+	if err := s.store.Append(ctx, Collection, record); err != nil {
+		s.logger.Warnf("Failed to store result of connectivity probe %s: %v", name, err)
+	}
+}
+
+// evaluate 根据最近的确定结果计算状态：抖动窗口内切换次数达到阈值为 flapping，否则取最近一次的结果
+func (s *Scheduler) evaluate(samples []Sample) (state string, transitions int, lastChange time.Time) {
+	conclusive := make([]Sample, 0, len(samples))
+	for _, sample := range samples {
+		if !sample.Inconclusive {
+			conclusive = append(conclusive, sample)
+		}
+	}
+	if len(conclusive) == 0 {
+		return StateUnknown, 0, time.Time{}
+	}
+
+	for i := 1; i < len(conclusive); i++ {
+		if conclusive[i].Success != conclusive[i-1].Success {
+			lastChange = conclusive[i].Timestamp
+		}
+	}
+
+	window := conclusive
+	if len(window) > s.flapWindow {
+		window = window[len(window)-s.flapWindow:]
+	}
+	for i := 1; i < len(window); i++ {
+		if window[i].Success != window[i-1].Success {
+			transitions++
+		}
+	}
+
+	switch {
+	case transitions >= s.flapThreshold:
+		return StateFlapping, transitions, lastChange
+	case conclusive[len(conclusive)-1].Success:
+		return StateUp, transitions, lastChange
+	default:
+		return StateDown, transitions, lastChange
+	}
+}
+
+// Statuses 返回所有探测的当前状态（按名称排序）
+func (s *Scheduler) Statuses() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(s.probes))
+	for _, p := range s.probes {
+		statuses = append(statuses, s.status(p))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Get 返回单个探测的状态和保留的历史（按时间升序）
+func (s *Scheduler) Get(name string) (*Status, []Sample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.probes {
+		if p.Name == name {
+			status := s.status(p)
+			return &status, append([]Sample(nil), s.samples[name]...), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// status 调用方需持有读锁
+func (s *Scheduler) status(p config.ConnectivityProbeConfig) Status {
+	samples := s.samples[p.Name]
+	state, transitions, lastChange := s.evaluate(samples)
+
+	status := Status{
+		Name:        p.Name,
+		Source:      p.SourceNamespace + "/" + p.SourceSelector,
+		Method:      p.Method,
+		Interval:    p.Interval,
+		State:       state,
+		Transitions: transitions,
+		Samples:     len(samples),
+	}
+	if p.URL != "" {
+		status.Target = p.URL
+		status.Method = "http"
+	} else {
+		namespace := p.TargetNamespace
+		if namespace == "" {
+			namespace = p.SourceNamespace
+		}
+		status.Target = namespace + "/" + p.TargetSelector
+		if status.Method == "" {
+			status.Method = "ping"
+		}
+	}
+	if !lastChange.IsZero() {
+		status.LastChange = &lastChange
+	}
+	if len(samples) > 0 {
+		last := samples[len(samples)-1]
+		status.Last = &last
+	}
+
+	var conclusive, succeeded int
+	var totalRTT float64
+	for _, sample := range samples {
+		if sample.Inconclusive {
+			continue
+		}
+		conclusive++
+		if sample.Success {
+			succeeded++
+			totalRTT += sample.RTT
+		}
+	}
+	if conclusive > 0 {
+		status.SuccessRate = float64(succeeded) / float64(conclusive) * 100
+	}
+	if succeeded > 0 {
+		status.AverageRTT = totalRTT / float64(succeeded)
+	}
+	return status
+}
+
+// restore 从存储读取每个探测最近的结果
+func (s *Scheduler) restore(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+	for _, p := range s.probes {
+		records, err := s.store.List(ctx, Collection, storage.Query{Key: p.Name, Limit: s.history})
+		if err != nil {
+			s.logger.Warnf("Failed to restore history of connectivity probe %s: %v", p.Name, err)
+			continue
+		}
+		samples := make([]Sample, 0, len(records))
+		for _, record := range records {
+			var sample Sample
+			if err := json.Unmarshal(record.Data, &sample); err == nil {
+				samples = append(samples, sample)
+			}
+		}
+
+		s.mu.Lock()
+		s.samples[p.Name] = samples
+		s.states[p.Name], _, _ = s.evaluate(samples)
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,100 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+)
+
+// scriptedChecker 依次返回预设的成功/失败结果，nil 表示无法选出Pod
+type scriptedChecker struct {
+	results []*bool
+}
+
+func (c *scriptedChecker) CheckConnectivity(ctx context.Context, check k8s.ConnectivityCheck) (*k8s.ConnectivityResult, error) {
+	next := c.results[0]
+	c.results = c.results[1:]
+	if next == nil {
+		return nil, k8s.ErrNoMatchingPod
+	}
+	return &k8s.ConnectivityResult{Success: *next, RTT: 1}, nil
+}
+
+func script(values ...interface{}) []*bool {
+	var results []*bool
+	for _, v := range values {
+		if v == nil {
+			results = append(results, nil)
+			continue
+		}
+		b := v.(bool)
+		results = append(results, &b)
+	}
+	return results
+}
+
+func TestSchedulerStates(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     []*bool
+		state       string
+		transitions int
+	}{
+		{"no results", script(nil, nil), StateUnknown, 0},
+		{"up", script(true, true, true), StateUp, 0},
+		{"down after recovery", script(true, false, false), StateDown, 1},
+		{"inconclusive results are ignored", script(true, nil, false, nil, true), StateUp, 2},
+		{"flapping", script(true, false, true, false), StateFlapping, 3},
+		{"flaps age out of the window", script(true, false, true, false, true, true, true, true, true), StateUp, 1},
+	}
+
+	probe := config.ConnectivityProbeConfig{Name: "api-to-db", SourceNamespace: "default", SourceSelector: "app=api", TargetSelector: "app=db", Interval: 60}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &scriptedChecker{results: tt.results}
+			s := NewScheduler(config.ConnectivityProbesConfig{
+				FlapWindow:    6,
+				FlapThreshold: 3,
+				Probes:        []config.ConnectivityProbeConfig{probe},
+			}, checker, nil)
+			for range tt.results {
+				s.RunOnce(context.Background(), probe)
+			}
+
+			status, samples, err := s.Get(probe.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.State != tt.state || status.Transitions != tt.transitions {
+				t.Errorf("state = %s (%d transitions), want %s (%d)", status.State, status.Transitions, tt.state, tt.transitions)
+			}
+			if len(samples) != len(tt.results) {
+				t.Errorf("history has %d samples, want %d", len(samples), len(tt.results))
+			}
+		})
+	}
+}
+
+func TestSchedulerHistoryLimit(t *testing.T) {
+	probe := config.ConnectivityProbeConfig{Name: "p", SourceNamespace: "default", SourceSelector: "app=a", TargetSelector: "app=b", Interval: 1}
+	checker := &scriptedChecker{results: script(false, true, true, true, true)}
+	s := NewScheduler(config.ConnectivityProbesConfig{History: 3, Probes: []config.ConnectivityProbeConfig{probe}}, checker, nil)
+	for i := 0; i < 5; i++ {
+		s.RunOnce(context.Background(), probe)
+	}
+
+	status, samples, _ := s.Get("p")
+	if len(samples) != 3 || status.SuccessRate != 100 {
+		t.Errorf("samples = %d, success rate = %.0f, want 3 and 100", len(samples), status.SuccessRate)
+	}
+	if status.LastChange != nil {
+		t.Errorf("the failure was dropped from the history, last change = %v", status.LastChange)
+	}
+
+	if _, _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,71 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultPort 探测Agent的默认端口
+const DefaultPort = 9091
+
+// Client 调用探测Agent的 /api/v1/probe 接口
+type Client struct {
+	httpClient *http.Client
+	port       int
+	token      string
+}
+
+// NewClient 创建Agent客户端，token 为空时不发送认证头
+func NewClient(port int, token string) *Client {
+	if port <= 0 {
+		port = DefaultPort
+	}
+	// 单次请求的超时由Agent端的探测超时和调用方的context控制
+	return &Client{httpClient: &http.Client{Timeout: MaxTimeout + 5*time.Second}, port: port, token: token}
+}
+
+// Probe 让 agentIP 上的Agent执行一次探测
+func (c *Client) Probe(ctx context.Context, agentIP string, req Request) (*Result, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := "http://" + net.JoinHostPort(agentIP, strconv.Itoa(c.port)) + "/api/v1/probe"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call probe agent %s: %w", agentIP, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read probe agent response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("probe agent %s returned %d: %s", agentIP, resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var envelope struct {
+		Data *Result `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Data == nil {
+		return nil, fmt.Errorf("invalid probe agent response from %s", agentIP)
+	}
+	return envelope.Data, nil
+}
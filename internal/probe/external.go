@@ -0,0 +1,150 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// external探测失败时所处的阶段
+const (
+	PhaseDNS     = "dns"
+	PhaseConnect = "connect"
+	PhaseTLS     = "tls"
+	PhaseHTTP    = "http"
+)
+
+// validateExternalURL 外部目标必须是绝对的http(s) URL
+func validateExternalURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target must be an absolute http(s) URL for external probes, got %q", target)
+	}
+	return nil
+}
+
+// externalTrace 记录请求各阶段的时间。拨号在传输层的goroutine中进行，回调需要加锁
+type externalTrace struct {
+	mu           sync.Mutex
+	phase        string
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+	addresses    []string
+	certExpiry   time.Time
+}
+
+func (t *externalTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = time.Since(t.dnsStart)
+			for _, addr := range info.Addrs {
+				t.addresses = append(t.addresses, addr.String())
+			}
+			if info.Err == nil {
+				t.phase = PhaseConnect
+			}
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// 目标为IP时没有DNS阶段；多个地址并行尝试时从第一次开始计时
+			if t.phase == PhaseDNS {
+				t.phase = PhaseConnect
+			}
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && t.connect == 0 {
+				t.connect = time.Since(t.connectStart)
+				t.phase = PhaseHTTP
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+			t.phase = PhaseTLS
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err != nil {
+				return
+			}
+			t.tls = time.Since(t.tlsStart)
+			t.phase = PhaseHTTP
+			if len(state.PeerCertificates) > 0 {
+				t.certExpiry = state.PeerCertificates[0].NotAfter
+			}
+		},
+	}
+}
+
+// runExternal 对外部URL发送一次GET，分别记录DNS解析、TCP建连、TLS握手和首字节的耗时。
+// 收到HTTP响应即为可达（ExpectStatus 不为0时状态码还需一致），不跟随重定向，不使用代理
+func runExternal(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	trace := &externalTrace{phase: PhaseDNS}
+	httpReq, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()), http.MethodGet, req.Target, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("User-Agent", "k8s-llm-monitor-probe")
+	client := &http.Client{
+		Transport:     &http.Transport{DisableKeepAlives: true, TLSHandshakeTimeout: timeout},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	total := time.Since(start)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	result.RTT = durationMs(total)
+	result.DNSTime = durationMs(trace.dns)
+	result.ConnectTime = durationMs(trace.connect)
+	result.TLSTime = durationMs(trace.tls)
+	result.Addresses = trace.addresses
+	if !trace.certExpiry.IsZero() {
+		expiry := trace.certExpiry.UTC()
+		result.CertExpiry = &expiry
+	}
+	if err != nil {
+		result.FailedPhase = trace.phase
+		return fmt.Errorf("%s failed: %w", trace.phase, err)
+	}
+
+	result.StatusCode = resp.StatusCode
+	if req.ExpectStatus != 0 && resp.StatusCode != req.ExpectStatus {
+		result.FailedPhase = PhaseHTTP
+		return fmt.Errorf("unexpected status %d (want %d)", resp.StatusCode, req.ExpectStatus)
+	}
+	result.Success = true
+	return nil
+}
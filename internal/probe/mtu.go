@@ -0,0 +1,110 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// MTU探测的限制（字节，IP包总长）
+const (
+	DefaultMaxMTU = 1500
+	MinMTU        = 576  // IPv4要求所有链路都能传输的最小长度
+	MaxMTU        = 9000 // 巨帧
+	pingOverhead  = 28   // IPv4头（20）+ ICMP头（8），ping -s 指定的是ICMP数据长度
+	mtuBaseline   = 84   // 先用默认大小（56字节数据）确认目标可达
+)
+
+// pingMTURe 匹配 iputils ping 报告的MTU：本地发送时的 "message too long, mtu=1450"，
+// 以及路由器返回的 "Frag needed and DF set (mtu = 1400)"
+var pingMTURe = regexp.MustCompile(`mtu\s*=\s*(\d+)`)
+
+// runMTU 用设置了DF位的ping二分查找到目标的路径MTU，每个长度只发一个包
+func runMTU(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	result.InterfaceMTU = interfaceMTU(req.Target)
+	seconds := int((timeout + time.Second - 1) / time.Second)
+	try := func(size int) (bool, int) {
+		output, _ := exec.CommandContext(ctx, "ping", "-M", "do", "-c", "1", "-W", strconv.Itoa(seconds),
+			"-s", strconv.Itoa(size-pingOverhead), req.Target).CombinedOutput()
+		_, _, ok := ParsePingOutput(string(output))
+		return ok, PingReportedMTU(string(output))
+	}
+
+	if ok, _ := try(mtuBaseline); !ok {
+		result.PacketLoss = 100
+		return fmt.Errorf("no reply from %s to a %d-byte ping", req.Target, mtuBaseline)
+	}
+	result.Success = true
+	result.PathMTU, result.ReportedMTU = SearchPathMTU(mtuBaseline, req.MaxMTU, try)
+	return nil
+}
+
+// SearchPathMTU 在 [low, high] 内二分查找能通过的最大长度（low 已知能通过）。
+// try 返回该长度是否收到回复以及ping报告的MTU；报告的MTU在范围内时优先尝试，通常一次即可确定
+func SearchPathMTU(low, high int, try func(size int) (bool, int)) (pathMTU, reported int) {
+	ok, hint := try(high)
+	if ok {
+		return high, 0
+	}
+	reported = hint
+	// 此时 low 可达、high 不可达
+	for high-low > 1 {
+		size := (low + high) / 2
+		if hint > low && hint < high {
+			size, hint = hint, 0
+		}
+		ok, h := try(size)
+		if h > 0 {
+			reported = h
+			hint = h
+		}
+		if ok {
+			low = size
+		} else {
+			high = size
+		}
+	}
+	return low, reported
+}
+
+// PingReportedMTU 从ping输出中提取报告的MTU，没有时为0
+func PingReportedMTU(output string) int {
+	match := pingMTURe.FindStringSubmatch(output)
+	if match == nil {
+		return 0
+	}
+	mtu, _ := strconv.Atoi(match[1])
+	return mtu
+}
+
+// interfaceMTU 发往目标的本地网卡MTU（按路由选择的源地址查找网卡），无法确定时为0
+func interfaceMTU(target string) int {
+	// UDP的Dial只选择路由和源地址，不发送数据
+	conn, err := net.Dial("udp", net.JoinHostPort(target, "9"))
+	if err != nil {
+		return 0
+	}
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return 0
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(local) {
+				return iface.MTU
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,386 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 网络探测由每个节点上的探测Agent执行，不依赖应用容器的镜像中是否有 ping/curl/shell
+
+// 探测方式
+const (
+	MethodPing = "ping" // ICMP（Agent镜像中的ping命令）
+	MethodTCP  = "tcp"  // TCP建连时间
+	MethodHTTP = "http" // HTTP GET的总耗时
+	MethodDNS  = "dns"  // 解析域名的耗时
+	MethodUDP  = "udp"  // 发送UDP数据报并等待回复（echo）的耗时
+	MethodMTU  = "mtu"  // 设置DF位的ping二分查找路径MTU
+
+	MethodExternal = "external" // 访问集群外的http(s) URL，分阶段测量DNS、建连、TLS和HTTP耗时
+)
+
+// 请求的限制
+const (
+	DefaultCount   = 3
+	MaxCount       = 10
+	DefaultTimeout = 5 * time.Second
+	MaxTimeout     = 30 * time.Second
+
+	DefaultUDPPayload = "k8s-llm-monitor-probe"
+	MaxUDPPayload     = 512
+)
+
+// Request 一次探测请求。ping/tcp/http 的目标必须是IP地址（Pod IP），避免Agent被用来访问任意主机名；
+// external 的目标为服务端配置的外部URL（metrics.external_targets）
+type Request struct {
+	Method    string `json:"method"`
+	Target    string `json:"target"`               // 目标IP（dns为要解析的域名，external为URL）
+	Port      int    `json:"port,omitempty"`       // tcp/udp/http端口，http默认80
+	Path      string `json:"path,omitempty"`       // http路径，默认 /
+	Count     int    `json:"count,omitempty"`      // ping/tcp/udp的次数，默认3
+	TimeoutMs int    `json:"timeout_ms,omitempty"` // 单次探测超时，默认5000
+
+	// udp：发送的内容，默认 DefaultUDPPayload。AllowNoReply 时目标不回复也算成功（服务不做echo，如MAVLink），
+	// 只有收到ICMP端口不可达才算失败，此时丢包率为未收到回复的比例
+	Payload      string `json:"payload,omitempty"`
+	AllowNoReply bool   `json:"allow_no_reply,omitempty"`
+
+	// mtu：查找的上限（IP包总长），默认 DefaultMaxMTU
+	MaxMTU int `json:"max_mtu,omitempty"`
+
+	// external：期望的HTTP状态码，为0时收到任何响应都算可达
+	ExpectStatus int `json:"expect_status,omitempty"`
+}
+
+// Result 探测结果
+type Result struct {
+	Method     string    `json:"method"`
+	Target     string    `json:"target"`
+	Node       string    `json:"node,omitempty"` // 执行探测的Agent所在节点
+	Success    bool      `json:"success"`
+	RTT        float64   `json:"rtt_ms"`              // 平均耗时 (ms)
+	PacketLoss float64   `json:"packet_loss"`         // 失败比例 (0-100)，ping、tcp和udp有效
+	Addresses  []string  `json:"addresses,omitempty"` // dns解析得到的地址
+	Rcode      string    `json:"rcode,omitempty"`     // dns失败的原因：NXDOMAIN、SERVFAIL、TIMEOUT
+	Error      string    `json:"error,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// mtu：能通过的最大IP包长、发往目标的本地网卡MTU，以及ping报告的MTU（本地网卡或路由器的ICMP需要分片消息，未报告时为0）
+	PathMTU      int `json:"path_mtu,omitempty"`
+	InterfaceMTU int `json:"interface_mtu,omitempty"`
+	ReportedMTU  int `json:"reported_mtu,omitempty"`
+
+	// external：各阶段耗时 (ms，未进行到该阶段时为0)、HTTP状态码、服务端证书的过期时间和失败时所处的阶段，RTT为请求总耗时
+	DNSTime     float64    `json:"dns_ms,omitempty"`
+	ConnectTime float64    `json:"connect_ms,omitempty"`
+	TLSTime     float64    `json:"tls_ms,omitempty"`
+	StatusCode  int        `json:"status_code,omitempty"`
+	CertExpiry  *time.Time `json:"cert_expiry,omitempty"`
+	FailedPhase string     `json:"failed_phase,omitempty"`
+}
+
+// Validate 检查请求并补全默认值
+func (r *Request) Validate() error {
+	switch r.Method {
+	case MethodPing, MethodTCP, MethodHTTP, MethodUDP, MethodMTU:
+		if net.ParseIP(r.Target) == nil {
+			return fmt.Errorf("target must be an IP address for %s probes, got %q", r.Method, r.Target)
+		}
+	case MethodDNS:
+		if strings.TrimSpace(r.Target) == "" {
+			return fmt.Errorf("target is required for dns probes")
+		}
+	case MethodExternal:
+		if err := validateExternalURL(r.Target); err != nil {
+			return err
+		}
+		if r.ExpectStatus != 0 && (r.ExpectStatus < 100 || r.ExpectStatus > 599) {
+			return fmt.Errorf("expect_status must be between 100 and 599, got %d", r.ExpectStatus)
+		}
+	default:
+		return fmt.Errorf("unknown probe method %q (supported: ping, tcp, udp, http, dns, mtu, external)", r.Method)
+	}
+	if (r.Method == MethodTCP || r.Method == MethodUDP) && (r.Port <= 0 || r.Port > 65535) {
+		return fmt.Errorf("port must be between 1 and 65535 for %s probes", r.Method)
+	}
+	if r.Method == MethodUDP {
+		if r.Payload == "" {
+			r.Payload = DefaultUDPPayload
+		}
+		if len(r.Payload) > MaxUDPPayload {
+			return fmt.Errorf("payload must be at most %d bytes", MaxUDPPayload)
+		}
+	}
+	if r.Method == MethodMTU {
+		if r.MaxMTU == 0 {
+			r.MaxMTU = DefaultMaxMTU
+		}
+		if r.MaxMTU < MinMTU || r.MaxMTU > MaxMTU {
+			return fmt.Errorf("max_mtu must be between %d and %d", MinMTU, MaxMTU)
+		}
+	}
+	if r.Method == MethodHTTP && r.Port == 0 {
+		r.Port = 80
+	}
+	if r.Count <= 0 {
+		r.Count = DefaultCount
+	}
+	if r.Count > MaxCount {
+		return fmt.Errorf("count must be at most %d", MaxCount)
+	}
+	if r.TimeoutMs <= 0 {
+		r.TimeoutMs = int(DefaultTimeout / time.Millisecond)
+	}
+	if time.Duration(r.TimeoutMs)*time.Millisecond > MaxTimeout {
+		return fmt.Errorf("timeout_ms must be at most %d", MaxTimeout/time.Millisecond)
+	}
+	return nil
+}
+
+// Run 执行探测（请求需已通过 Validate），失败记录在结果的 Error 中
+func Run(ctx context.Context, req Request) *Result {
+	result := &Result{Method: req.Method, Target: req.Target, Timestamp: time.Now()}
+	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
+
+	var err error
+	switch req.Method {
+	case MethodPing:
+		err = runPing(ctx, req, timeout, result)
+	case MethodTCP:
+		err = runTCP(ctx, req, timeout, result)
+	case MethodUDP:
+		err = runUDP(ctx, req, timeout, result)
+	case MethodHTTP:
+		err = runHTTP(ctx, req, timeout, result)
+	case MethodDNS:
+		err = runDNS(ctx, req, timeout, result)
+	case MethodMTU:
+		err = runMTU(ctx, req, timeout, result)
+	case MethodExternal:
+		err = runExternal(ctx, req, timeout, result)
+	}
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func runPing(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	seconds := int((timeout + time.Second - 1) / time.Second)
+	output, err := exec.CommandContext(ctx, "ping", "-c", strconv.Itoa(req.Count), "-W", strconv.Itoa(seconds), req.Target).Output()
+	// 全部丢包时ping以非0退出，仍然解析输出中的丢包率
+	result.RTT, result.PacketLoss, result.Success = ParsePingOutput(string(output))
+	if !result.Success && err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
+
+func runTCP(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	address := net.JoinHostPort(req.Target, strconv.Itoa(req.Port))
+	dialer := net.Dialer{Timeout: timeout}
+	var total time.Duration
+	var lastErr error
+	succeeded := 0
+	for i := 0; i < req.Count; i++ {
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		total += time.Since(start)
+		conn.Close()
+		succeeded++
+	}
+	result.PacketLoss = float64(req.Count-succeeded) / float64(req.Count) * 100
+	if succeeded == 0 {
+		return fmt.Errorf("tcp connect to %s failed: %w", address, lastErr)
+	}
+	result.Success = true
+	result.RTT = durationMs(total) / float64(succeeded)
+	return nil
+}
+
+// runUDP 向目标端口发送数据报并等待回复。连接型UDP套接字上的读取会返回对端的ICMP端口不可达（ECONNREFUSED）
+func runUDP(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	address := net.JoinHostPort(req.Target, strconv.Itoa(req.Port))
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return fmt.Errorf("udp socket to %s failed: %w", address, err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 1500)
+	var total time.Duration
+	var lastErr error
+	replies, refused := 0, false
+	for i := 0; i < req.Count && ctx.Err() == nil; i++ {
+		start := time.Now()
+		conn.SetDeadline(start.Add(timeout))
+		if _, err := conn.Write([]byte(req.Payload)); err != nil {
+			lastErr = err
+			refused = refused || errors.Is(err, syscall.ECONNREFUSED)
+			continue
+		}
+		if _, err := conn.Read(buf); err != nil {
+			lastErr = err
+			refused = refused || errors.Is(err, syscall.ECONNREFUSED)
+			continue
+		}
+		total += time.Since(start)
+		replies++
+	}
+	result.PacketLoss = float64(req.Count-replies) / float64(req.Count) * 100
+
+	switch {
+	case replies > 0:
+		result.Success = true
+		result.RTT = durationMs(total) / float64(replies)
+		return nil
+	case refused:
+		return fmt.Errorf("udp port %s unreachable: %w", address, lastErr)
+	case req.AllowNoReply:
+		// 没有回复也没有端口不可达：端口开放或被过滤，对不做echo的服务视为可达
+		result.Success = true
+		return nil
+	default:
+		return fmt.Errorf("no udp reply from %s: %w", address, lastErr)
+	}
+}
+
+func runHTTP(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	path := req.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := "http://" + net.JoinHostPort(req.Target, strconv.Itoa(req.Port)) + path
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	// 不跟随重定向，测量的是到目标Pod本身的耗时
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("http request to %s failed: %w", url, err)
+	}
+	resp.Body.Close()
+	result.Success = true
+	result.RTT = durationMs(time.Since(start))
+	return nil
+}
+
+func runDNS(ctx context.Context, req Request, timeout time.Duration, result *Result) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, req.Target)
+	result.RTT = durationMs(time.Since(start))
+	if err != nil {
+		result.Rcode = DNSRcode(err)
+		return fmt.Errorf("dns lookup of %s failed: %w", req.Target, err)
+	}
+	result.Success = true
+	result.Addresses = addresses
+	return nil
+}
+
+// DNS失败的原因
+const (
+	RcodeNXDomain = "NXDOMAIN"
+	RcodeServFail = "SERVFAIL"
+	RcodeTimeout  = "TIMEOUT"
+)
+
+// DNSRcode 由解析错误推断DNS失败的原因，无法判断时为空
+func DNSRcode(err error) string {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		return ""
+	}
+	switch {
+	case dnsErr.IsNotFound:
+		return RcodeNXDomain
+	case dnsErr.IsTimeout:
+		return RcodeTimeout
+	case strings.Contains(dnsErr.Err, "server misbehaving"):
+		// Go的解析器将SERVFAIL报告为 server misbehaving
+		return RcodeServFail
+	}
+	return ""
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ParsePingOutput 解析ping命令的输出，返回平均RTT (ms)、丢包率 (0-100) 以及是否收到回复
+func ParsePingOutput(output string) (float64, float64, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	var rttSum, packetLoss float64
+	var rttCount int
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// 例如 "64 bytes from 10.244.0.4: icmp_seq=1 ttl=64 time=0.123 ms"
+		if strings.Contains(line, "time=") && strings.Contains(line, "ms") {
+			if rtt := pingLineRTT(line); rtt > 0 {
+				rttSum += rtt
+				rttCount++
+			}
+		}
+
+		// 例如 "3 packets transmitted, 3 received, 0% packet loss"
+		if strings.Contains(line, "packet loss") {
+			packetLoss = pingLineLoss(line)
+		}
+	}
+
+	if rttCount == 0 {
+		return 0, packetLoss, false
+	}
+	return rttSum / float64(rttCount), packetLoss, true
+}
+
+// pingLineRTT 从一行回复中提取RTT
+func pingLineRTT(line string) float64 {
+	parts := strings.Split(line, "time=")
+	if len(parts) < 2 {
+		return 0
+	}
+	timePart := strings.TrimSuffix(strings.Split(parts[1], " ")[0], "ms")
+	rtt, err := strconv.ParseFloat(timePart, 64)
+	if err != nil {
+		return 0
+	}
+	return rtt
+}
+
+// pingLineLoss 从统计行中提取丢包率
+func pingLineLoss(line string) float64 {
+	for _, part := range strings.Split(line, " ") {
+		if strings.Contains(part, "%") {
+			if loss, err := strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64); err == nil {
+				return loss
+			}
+		}
+	}
+	return 0
+}
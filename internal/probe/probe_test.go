@@ -0,0 +1,209 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// udpServer 在本地监听UDP端口，echo 为true时原样回复
+func udpServer(t *testing.T, echo bool) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if echo {
+				conn.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// closedUDPPort 返回一个刚释放的本地UDP端口
+func closedUDPPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+	return port
+}
+
+func TestRunUDP(t *testing.T) {
+	echoPort := udpServer(t, true)
+	silentPort := udpServer(t, false)
+	closedPort := closedUDPPort(t)
+
+	tests := []struct {
+		name         string
+		port         int
+		allowNoReply bool
+		success      bool
+		loss         float64
+	}{
+		{"echo", echoPort, false, true, 0},
+		{"silent service", silentPort, false, false, 100},
+		{"silent service without echo", silentPort, true, true, 100},
+		{"closed port", closedPort, true, false, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Method: MethodUDP, Target: "127.0.0.1", Port: tt.port, Count: 2, TimeoutMs: 200, AllowNoReply: tt.allowNoReply}
+			if err := req.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			result := Run(context.Background(), req)
+			if result.Success != tt.success || result.PacketLoss != tt.loss {
+				t.Errorf("success = %v, loss = %v (%s), want %v, %v", result.Success, result.PacketLoss, result.Error, tt.success, tt.loss)
+			}
+		})
+	}
+}
+
+func TestValidateUDP(t *testing.T) {
+	req := Request{Method: MethodUDP, Target: "10.0.0.1"}
+	if err := req.Validate(); err == nil {
+		t.Error("udp probe without a port should be rejected")
+	}
+
+	req = Request{Method: MethodUDP, Target: "10.0.0.1", Port: 14550}
+	if err := req.Validate(); err != nil || req.Payload != DefaultUDPPayload {
+		t.Errorf("err = %v, payload = %q", err, req.Payload)
+	}
+
+	req = Request{Method: MethodUDP, Target: "uav.default.svc", Port: 14550}
+	if err := req.Validate(); err == nil {
+		t.Error("udp probe to a host name should be rejected")
+	}
+}
+
+func TestSearchPathMTU(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      int  // 实际的路径MTU
+		reports   bool // 超长时是否报告MTU（本地网卡或ICMP需要分片）
+		high      int
+		want      int
+		maxProbes int
+	}{
+		{"full size passes", 1500, false, 1500, 1500, 1},
+		{"silent drop", 1450, false, 1500, 1450, 12},
+		{"reported mtu", 1450, true, 1500, 1450, 8},
+		{"jumbo frames", 8950, false, 9000, 8950, 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := 0
+			got, reported := SearchPathMTU(84, tt.high, func(size int) (bool, int) {
+				probes++
+				if size <= tt.path {
+					return true, 0
+				}
+				if tt.reports {
+					return false, tt.path
+				}
+				return false, 0
+			})
+			if got != tt.want {
+				t.Errorf("path mtu = %d, want %d", got, tt.want)
+			}
+			if tt.reports && reported != tt.path {
+				t.Errorf("reported mtu = %d, want %d", reported, tt.path)
+			}
+			if probes > tt.maxProbes {
+				t.Errorf("used %d probes, want at most %d", probes, tt.maxProbes)
+			}
+		})
+	}
+}
+
+func TestPingReportedMTU(t *testing.T) {
+	tests := map[string]int{
+		"ping: local error: message too long, mtu=1450":                           1450,
+		"From 10.0.0.1 icmp_seq=1 Frag needed and DF set (mtu = 1400)":            1400,
+		"1 packets transmitted, 0 received, 100% packet loss, time 0ms":           0,
+		"64 bytes from 10.244.1.5: icmp_seq=1 ttl=63 time=0.512 ms\n1 received\n": 0,
+	}
+	for output, want := range tests {
+		if got := PingReportedMTU(output); got != want {
+			t.Errorf("PingReportedMTU(%q) = %d, want %d", output, got, want)
+		}
+	}
+}
+
+func TestRunExternal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	// 自签名证书，Agent校验证书时在TLS阶段失败
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer tlsServer.Close()
+	closed := httptest.NewServer(nil)
+	closedURL := closed.URL
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		expect  int
+		success bool
+		status  int
+		phase   string
+	}{
+		{"any response is reachable", strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/v1/models", 0, true, http.StatusUnauthorized, ""},
+		{"unexpected status", server.URL, http.StatusOK, false, http.StatusUnauthorized, PhaseHTTP},
+		{"untrusted certificate", tlsServer.URL, 0, false, 0, PhaseTLS},
+		{"connection refused", closedURL, 0, false, 0, PhaseConnect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Method: MethodExternal, Target: tt.url, ExpectStatus: tt.expect, TimeoutMs: 2000}
+			if err := req.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			result := Run(context.Background(), req)
+			if result.Success != tt.success || result.StatusCode != tt.status || result.FailedPhase != tt.phase {
+				t.Errorf("success = %v, status = %d, phase = %q (%s), want %v, %d, %q",
+					result.Success, result.StatusCode, result.FailedPhase, result.Error, tt.success, tt.status, tt.phase)
+			}
+			if tt.success && (result.ConnectTime <= 0 || result.RTT < result.ConnectTime) {
+				t.Errorf("connect = %v ms, total = %v ms", result.ConnectTime, result.RTT)
+			}
+		})
+	}
+}
+
+func TestValidateExternal(t *testing.T) {
+	for _, target := range []string{"10.0.0.1", "api.openai.com", "ftp://example.com/file", "https://"} {
+		req := Request{Method: MethodExternal, Target: target}
+		if err := req.Validate(); err == nil {
+			t.Errorf("external probe to %q should be rejected", target)
+		}
+	}
+	req := Request{Method: MethodExternal, Target: "https://api.openai.com/v1/models", ExpectStatus: 700}
+	if err := req.Validate(); err == nil {
+		t.Error("expect_status outside 100-599 should be rejected")
+	}
+	req = Request{Method: MethodHTTP, Target: "https://api.openai.com"}
+	if err := req.Validate(); err == nil {
+		t.Error("http probes still require an IP target")
+	}
+}
@@ -0,0 +1,119 @@
+package prompts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ErrUnknownTemplate 模板或版本不存在
+var ErrUnknownTemplate = errors.New("unknown prompt template")
+
+// SourceBuiltin 内置模板的来源
+const SourceBuiltin = "builtin"
+
+// Template 提示词模板，System 和 User 使用 text/template 语法（如 {{.namespace}}）
+type Template struct {
+	Name        string   `json:"name"`
+	Version     int      `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Variables   []string `json:"variables,omitempty"` // 渲染时必须提供的变量
+	System      string   `json:"system"`
+	User        string   `json:"user"`
+	Source      string   `json:"source,omitempty"` // builtin 或模板文件路径
+
+	system *template.Template
+	user   *template.Template
+}
+
+// Rendered 渲染后的提示词
+type Rendered struct {
+	Template string // 模板标识（name@vN），记录在分析结果中便于对比不同版本
+	System   string
+	User     string
+}
+
+// funcs 模板中可用的函数
+var funcs = template.FuncMap{
+	// json 将值序列化为JSON（用于嵌入证据数据）
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+	// default 值为空时使用默认值：{{default "unknown" .reason}}
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// ID 模板标识 name@vN
+func (t *Template) ID() string {
+	return fmt.Sprintf("%s@v%d", t.Name, t.Version)
+}
+
+// compile 校验并解析模板
+func (t *Template) compile() error {
+	if t.Name == "" {
+		return errors.New("template name is required")
+	}
+	if t.Version <= 0 {
+		return fmt.Errorf("template %s: version must be positive", t.Name)
+	}
+	if strings.TrimSpace(t.System) == "" && strings.TrimSpace(t.User) == "" {
+		return fmt.Errorf("template %s: system or user prompt is required", t.ID())
+	}
+
+	var err error
+	if t.system, err = parse(t.ID()+"/system", t.System); err != nil {
+		return err
+	}
+	if t.user, err = parse(t.ID()+"/user", t.User); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render 使用变量渲染模板
+func (t *Template) Render(vars map[string]interface{}) (*Rendered, error) {
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+	for _, name := range t.Variables {
+		if _, ok := vars[name]; !ok {
+			return nil, fmt.Errorf("prompt template %s: missing variable %q", t.ID(), name)
+		}
+	}
+
+	system, err := execute(t.system, vars)
+	if err != nil {
+		return nil, err
+	}
+	user, err := execute(t.user, vars)
+	if err != nil {
+		return nil, err
+	}
+	return &Rendered{Template: t.ID(), System: system, User: user}, nil
+}
+
+func execute(tmpl *template.Template, vars map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
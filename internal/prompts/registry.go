@@ -0,0 +1,232 @@
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"sigs.k8s.io/yaml"
+)
+
+// Registry 提示词模板注册表：内置模板加上模板目录中的 *.yaml，
+// 同名同版本时文件覆盖内置模板；默认使用每个模板的最高版本，可通过配置固定版本
+type Registry struct {
+	mu        sync.RWMutex
+	cfg       config.PromptsConfig
+	templates map[string][]*Template // 模板名 -> 按版本升序
+	logger    *logrus.Entry
+}
+
+// Info 模板列表中的一项
+type Info struct {
+	*Template
+	Active bool `json:"active"` // 当前使用的版本
+}
+
+// NewRegistry 加载内置模板和模板目录
+func NewRegistry(cfg config.PromptsConfig) (*Registry, error) {
+	r := &Registry{logger: logging.For("prompts")}
+	if err := r.Update(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Update 应用新配置并重新加载模板目录；加载失败时保留原有模板
+func (r *Registry) Update(cfg config.PromptsConfig) error {
+	templates, err := load(cfg.Dir)
+	if err != nil {
+		return err
+	}
+	for name, version := range cfg.Versions {
+		if findVersion(templates[name], version) == nil {
+			return fmt.Errorf("analysis.prompts.versions.%s: %w: %s@v%d", name, ErrUnknownTemplate, name, version)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = cfg
+	r.templates = templates
+
+	count := 0
+	for _, versions := range templates {
+		count += len(versions)
+	}
+	if cfg.Dir != "" {
+		r.logger.Infof("Loaded %d prompt templates (%d names), directory %s", count, len(templates), cfg.Dir)
+	} else {
+		r.logger.Infof("Loaded %d builtin prompt templates", count)
+	}
+	return nil
+}
+
+// Reload 重新读取模板目录（修改模板文件后调用）
+func (r *Registry) Reload() error {
+	r.mu.RLock()
+	cfg := r.cfg
+	r.mu.RUnlock()
+	return r.Update(cfg)
+}
+
+// Get 返回模板当前使用的版本
+func (r *Registry) Get(name string) (*Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t := r.active(name); t != nil {
+		return t, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+}
+
+// active 模板当前使用的版本：配置固定的版本，否则为最高版本。调用方需持有锁
+func (r *Registry) active(name string) *Template {
+	versions := r.templates[name]
+	if len(versions) == 0 {
+		return nil
+	}
+	if version, ok := r.cfg.Versions[name]; ok {
+		if t := findVersion(versions, version); t != nil {
+			return t
+		}
+	}
+	return versions[len(versions)-1]
+}
+
+// Version 返回模板的指定版本
+func (r *Registry) Version(name string, version int) (*Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t := findVersion(r.templates[name], version); t != nil {
+		return t, nil
+	}
+	return nil, fmt.Errorf("%w: %s@v%d", ErrUnknownTemplate, name, version)
+}
+
+// Render 使用当前版本渲染模板
+func (r *Registry) Render(name string, vars map[string]interface{}) (*Rendered, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Render(vars)
+}
+
+// List 列出所有模板的所有版本（按名称、版本排序）
+func (r *Registry) List() []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]Info, 0, len(names))
+	for _, name := range names {
+		active := r.active(name)
+		for _, t := range r.templates[name] {
+			list = append(list, Info{Template: t, Active: t == active})
+		}
+	}
+	return list
+}
+
+// load 合并内置模板和模板目录
+func load(dir string) (map[string][]*Template, error) {
+	byID := make(map[string]*Template)
+	for _, t := range builtin() {
+		t.Source = SourceBuiltin
+		if err := t.compile(); err != nil {
+			return nil, err
+		}
+		byID[t.ID()] = t
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		more, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, more...)
+		sort.Strings(files)
+
+		fromFiles := make(map[string]string)
+		for _, file := range files {
+			templates, err := loadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range templates {
+				if previous, ok := fromFiles[t.ID()]; ok {
+					return nil, fmt.Errorf("prompt template %s defined in both %s and %s", t.ID(), previous, file)
+				}
+				fromFiles[t.ID()] = file
+				byID[t.ID()] = t
+			}
+		}
+	}
+
+	templates := make(map[string][]*Template)
+	for _, t := range byID {
+		templates[t.Name] = append(templates[t.Name], t)
+	}
+	for _, versions := range templates {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+	return templates, nil
+}
+
+// loadFile 读取模板文件：单个模板，或 templates 列表
+func loadFile(path string) ([]*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt file: %w", err)
+	}
+
+	// 内嵌 Template 的字段提升到顶层，支持单模板文件
+	var file struct {
+		Template
+		Templates []*Template `json:"templates"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt file %s: %w", path, err)
+	}
+
+	templates := file.Templates
+	if file.Name != "" {
+		single := file.Template
+		templates = append(templates, &single)
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("prompt file %s: no templates defined", path)
+	}
+	for _, t := range templates {
+		t.Source = path
+		if err := t.compile(); err != nil {
+			return nil, fmt.Errorf("prompt file %s: %w", path, err)
+		}
+	}
+	return templates, nil
+}
+
+func findVersion(versions []*Template, version int) *Template {
+	for _, t := range versions {
+		if t.Version == version {
+			return t
+		}
+	}
+	return nil
+}
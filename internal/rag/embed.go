@@ -0,0 +1,147 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+)
+
+// embedBatch 每次向量化请求的最大文本数
+const embedBatch = 64
+
+// Embedder 文本向量化；Name 标识模型，不同模型的向量不能相互比较
+type Embedder interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// hashEmbedder 本地特征哈希向量：词和相邻词对哈希到固定维数，不需要模型，
+// 适合匹配节点名、Pod名、事件原因等重复出现的关键词
+type hashEmbedder struct {
+	dims int
+}
+
+// Name 模型标识
+func (e *hashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", e.dims)
+}
+
+// Embed 文本向量化
+func (e *hashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.dims)
+		tokens := tokenize(text)
+		for j, token := range tokens {
+			e.add(vector, token, 1)
+			if j > 0 {
+				e.add(vector, tokens[j-1]+" "+token, 0.5)
+			}
+		}
+		normalize(vector)
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// add 将特征哈希到向量中，用哈希的最高位决定符号以减少冲突的影响
+func (e *hashEmbedder) add(vector []float32, feature string, weight float32) {
+	h := fnv.New32a()
+	h.Write([]byte(feature))
+	sum := h.Sum32()
+	if sum&(1<<31) != 0 {
+		weight = -weight
+	}
+	vector[int(sum%uint32(e.dims))] += weight
+}
+
+// tokenize 小写分词；名称（如 db-0、kube-system/coredns）同时保留整体和各部分
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '/' && r != '.'
+	})
+	tokens := make([]string, 0, len(fields)*2)
+	for _, field := range fields {
+		field = strings.Trim(field, "-_/.")
+		if field == "" {
+			continue
+		}
+		tokens = append(tokens, field)
+		parts := strings.FieldsFunc(field, func(r rune) bool { return r == '-' || r == '_' || r == '/' || r == '.' })
+		if len(parts) > 1 {
+			tokens = append(tokens, parts...)
+		}
+	}
+	return tokens
+}
+
+// normalize 归一化为单位向量
+func normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
+
+// llmEmbedder 调用LLM配置的embedding接口；每次调用时按当前配置创建客户端（配置热更新后生效）
+type llmEmbedder struct {
+	service *llm.Service
+	profile string
+	model   string
+}
+
+// Name 模型标识
+func (e *llmEmbedder) Name() string {
+	return "llm/" + e.model
+}
+
+// Embed 分批向量化
+func (e *llmEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	client, err := e.service.Embedder(e.profile, e.model)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatch {
+		end := start + embedBatch
+		if end > len(texts) {
+			end = len(texts)
+		}
+		result, err := client.Embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, result.Vectors...)
+	}
+	return vectors, nil
+}
+
+// newEmbedder 根据配置创建向量化方式
+func newEmbedder(kind string, dims int, service *llm.Service, profile, model string) (Embedder, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "hash":
+		if dims <= 0 {
+			return nil, fmt.Errorf("analysis.rag.dimensions must be positive")
+		}
+		return &hashEmbedder{dims: dims}, nil
+	case "llm":
+		if model == "" {
+			return nil, fmt.Errorf("analysis.rag.model is required when embedder is llm")
+		}
+		return &llmEmbedder{service: service, profile: profile, model: model}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedder: %s", kind)
+	}
+}
@@ -0,0 +1,334 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/telemetry"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// reembedBatch 每轮重新向量化的最大摘要数（切换 embedder 后逐步迁移）
+const reembedBatch = 256
+
+// incidentLookback 每轮重新检查最近该时间内有变化的故障
+const incidentLookback = 24 * time.Hour
+
+// Status 索引状态
+type Status struct {
+	Store     string         `json:"store"`
+	Embedder  string         `json:"embedder"`
+	Documents map[string]int `json:"documents"` // 按类型
+	Interval  int            `json:"interval"`
+	LastRun   *time.Time     `json:"last_run,omitempty"`
+	Indexed   int            `json:"indexed"` // 最近一轮新增或更新的摘要数
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// Index 定期为指标快照、故障和UAV遥测生成摘要并向量化，供分析时检索相似的历史
+type Index struct {
+	cfg       config.RAGConfig
+	vectors   VectorStore
+	embedder  Embedder
+	sources   analysis.Sources
+	incidents *incidents.Engine
+	store     storage.Store
+	logger    *logrus.Entry
+
+	mu           sync.Mutex
+	lastRun      time.Time
+	lastSnapshot string // 最近一次索引的快照摘要（去掉时间），相同的快照不重复索引
+	indexed      int
+	lastErr      error
+}
+
+// New 创建索引；sources 提供指标快照和事件，engine 可以为空（未启用故障关联）
+func New(ctx context.Context, cfg config.RAGConfig, store storage.Store, service *llm.Service, sources analysis.Sources, engine *incidents.Engine) (*Index, error) {
+	embedder, err := newEmbedder(cfg.Embedder, cfg.Dimensions, service, cfg.Profile, cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := NewVectorStore(ctx, cfg.Store, store, cfg.MaxDocuments)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 300
+	}
+	if cfg.Store == "" {
+		cfg.Store = "memory"
+	}
+	sources.History = nil
+	return &Index{
+		cfg:       cfg,
+		vectors:   vectors,
+		embedder:  embedder,
+		sources:   sources,
+		incidents: engine,
+		store:     store,
+		logger:    logging.For("rag"),
+	}, nil
+}
+
+// Start 定期生成摘要，直到 ctx 取消
+func (x *Index) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(x.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := x.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			x.logger.Warnf("RAG indexing failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 索引上次运行以来的快照、故障和UAV遥测，并重新向量化其他模型生成的摘要
+func (x *Index) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	x.mu.Lock()
+	since := x.lastRun
+	x.mu.Unlock()
+	if since.IsZero() {
+		since = now.Add(-time.Duration(x.cfg.Interval) * time.Second)
+	}
+
+	var docs []*Document
+	var errs []error
+	if doc := x.snapshotDocument(ctx, now); doc != nil {
+		docs = append(docs, doc)
+	}
+	incidentDocs, err := x.incidentDocuments(ctx, now)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	docs = append(docs, incidentDocs...)
+	uavDocs, err := x.uavDocuments(ctx, since, now)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	docs = append(docs, uavDocs...)
+
+	stale, err := x.vectors.Stale(ctx, x.embedder.Name(), reembedBatch)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	docs = append(docs, stale...)
+
+	if len(docs) > 0 {
+		if err := x.embed(ctx, docs, now); err != nil {
+			errs = append(errs, err)
+		} else if err := x.vectors.Upsert(ctx, docs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err = errors.Join(errs...)
+	x.mu.Lock()
+	x.lastRun = now
+	x.indexed = len(docs)
+	x.lastErr = err
+	x.mu.Unlock()
+	if len(docs) > 0 && err == nil {
+		x.logger.Debugf("Indexed %d RAG documents (%d re-embedded)", len(docs), len(stale))
+	}
+	return err
+}
+
+// embed 为摘要生成向量
+func (x *Index) embed(ctx context.Context, docs []*Document, now time.Time) error {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	vectors, err := x.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed %d documents: %w", len(docs), err)
+	}
+	for i, doc := range docs {
+		doc.Vector = vectors[i]
+		doc.Embedder = x.embedder.Name()
+		doc.IndexedAt = now
+	}
+	return nil
+}
+
+// snapshotDocument 最新指标快照和最近事件的摘要；没有异常或与上次相同时返回空
+func (x *Index) snapshotDocument(ctx context.Context, now time.Time) *Document {
+	if x.sources.Metrics == nil && x.sources.Events == nil && x.sources.K8s == nil {
+		return nil
+	}
+	evidence := x.sources.Collect(ctx, analysis.Scope{Since: time.Duration(x.cfg.Interval) * time.Second})
+	at := evidence.SnapshotTime
+	if at.IsZero() {
+		at = now
+	}
+	text, notable := snapshotSummary(evidence, at)
+	if !notable {
+		return nil
+	}
+
+	// 去掉第一句（时间）比较，集群状态没有变化时不重复索引
+	body := text[strings.Index(text, ".")+1:]
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if body == x.lastSnapshot {
+		return nil
+	}
+	x.lastSnapshot = body
+	return &Document{
+		ID:   fmt.Sprintf("snapshot-%d", at.Unix()),
+		Kind: KindSnapshot,
+		Time: at.UTC(),
+		Text: text,
+	}
+}
+
+// incidentDocuments 最近有变化的故障；摘要与已索引的相同时跳过
+func (x *Index) incidentDocuments(ctx context.Context, now time.Time) ([]*Document, error) {
+	if x.incidents == nil {
+		return nil, nil
+	}
+	list, err := x.incidents.List(ctx, incidents.Filter{From: now.Add(-incidentLookback)})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*Document, 0)
+	for _, incident := range list {
+		text := incidentSummary(incident)
+		if existing, err := x.vectors.Get(ctx, incident.ID); err == nil && existing.Text == text {
+			continue
+		}
+		docs = append(docs, &Document{
+			ID:   incident.ID,
+			Kind: KindIncident,
+			Ref:  incident.ID,
+			Time: incident.StartedAt.UTC(),
+			Text: text,
+		})
+	}
+	return docs, nil
+}
+
+// uavDocuments 各UAV在 [from, to) 内的遥测摘要，只保留有异常（电量不足、状态异常、飞行模式变化）的
+func (x *Index) uavDocuments(ctx context.Context, from, to time.Time) ([]*Document, error) {
+	records, err := x.store.List(ctx, telemetry.UAVCollection, storage.Query{From: from, To: to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uav telemetry: %w", err)
+	}
+
+	byNode := make(map[string][]*models.UAVTelemetryPoint)
+	for _, record := range records {
+		var point models.UAVTelemetryPoint
+		if err := json.Unmarshal(record.Data, &point); err != nil {
+			continue
+		}
+		node := point.NodeName
+		if node == "" {
+			node = record.Key
+		}
+		byNode[node] = append(byNode[node], &point)
+	}
+
+	nodes := make([]string, 0, len(byNode))
+	for node := range byNode {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	docs := make([]*Document, 0)
+	for _, node := range nodes {
+		points := byNode[node]
+		sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+		text, notable := uavSummary(node, points)
+		if !notable {
+			continue
+		}
+		docs = append(docs, &Document{
+			ID:   fmt.Sprintf("uav-%s-%d", node, points[0].Timestamp.Unix()),
+			Kind: KindUAV,
+			Ref:  node,
+			Time: points[0].Timestamp.UTC(),
+			Text: text,
+		})
+	}
+	return docs, nil
+}
+
+// Search 检索与 text 相似的摘要；kinds 为空表示所有类型，before 为零值表示不限制时间，limit<=0 时使用 top_k
+func (x *Index) Search(ctx context.Context, text string, kinds []string, before time.Time, limit int) ([]Match, error) {
+	vectors, err := x.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if limit <= 0 {
+		limit = x.cfg.TopK
+	}
+	return x.vectors.Search(ctx, Query{
+		Vector:   vectors[0],
+		Embedder: x.embedder.Name(),
+		Kinds:    kinds,
+		Before:   before,
+		Limit:    limit,
+		MinScore: x.cfg.MinScore,
+	})
+}
+
+// Similar 实现 analysis.Retriever
+func (x *Index) Similar(ctx context.Context, text string, before time.Time, limit int) ([]analysis.PastCase, error) {
+	matches, err := x.Search(ctx, text, nil, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]analysis.PastCase, len(matches))
+	for i, m := range matches {
+		cases[i] = analysis.PastCase{Kind: m.Kind, Ref: m.Ref, Time: m.Time, Summary: m.Text, Score: m.Score}
+	}
+	return cases, nil
+}
+
+// Status 索引状态
+func (x *Index) Status(ctx context.Context) Status {
+	counts, err := x.vectors.Counts(ctx)
+	if err != nil {
+		counts = map[string]int{}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	status := Status{
+		Store:     x.cfg.Store,
+		Embedder:  x.embedder.Name(),
+		Documents: counts,
+		Interval:  x.cfg.Interval,
+		Indexed:   x.indexed,
+	}
+	if !x.lastRun.IsZero() {
+		lastRun := x.lastRun
+		status.LastRun = &lastRun
+	}
+	if x.lastErr != nil {
+		status.LastError = x.lastErr.Error()
+	} else if err != nil {
+		status.LastError = err.Error()
+	}
+	return status
+}
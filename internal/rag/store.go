@@ -0,0 +1,249 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// Collection 摘要在存储中的集合名
+const Collection = "rag_documents"
+
+// 摘要类型
+const (
+	KindSnapshot = "snapshot"
+	KindIncident = "incident"
+	KindUAV      = "uav"
+)
+
+// ErrNotFound 摘要不存在
+var ErrNotFound = errors.New("rag document not found")
+
+// Document 一条历史摘要及其向量
+type Document struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Ref       string    `json:"ref,omitempty"` // 关联对象，如故障ID或UAV节点名
+	Time      time.Time `json:"time"`          // 摘要描述的时间（快照时间、故障开始时间等）
+	Text      string    `json:"text"`
+	Embedder  string    `json:"embedder"` // 生成向量的模型，切换模型后重新向量化
+	Vector    []float32 `json:"vector"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+// Match 检索结果
+type Match struct {
+	ID    string    `json:"id"`
+	Kind  string    `json:"kind"`
+	Ref   string    `json:"ref,omitempty"`
+	Time  time.Time `json:"time"`
+	Text  string    `json:"text"`
+	Score float64   `json:"score"`
+}
+
+// Query 检索条件
+type Query struct {
+	Vector   []float32
+	Embedder string    // 只比较同一模型生成的向量
+	Kinds    []string  // 为空表示所有类型
+	Before   time.Time // 只检索此前的摘要，零值表示不限制
+	Limit    int
+	MinScore float64
+}
+
+// VectorStore 向量存储
+type VectorStore interface {
+	// Upsert 写入摘要，相同ID的摘要被替换
+	Upsert(ctx context.Context, docs []*Document) error
+	// Get 读取摘要，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Document, error)
+	// Search 按余弦相似度降序返回匹配的摘要
+	Search(ctx context.Context, query Query) ([]Match, error)
+	// Stale 返回最多 limit 条不是由 embedder 生成向量的摘要（需要重新向量化）
+	Stale(ctx context.Context, embedder string, limit int) ([]*Document, error)
+	// Counts 按类型统计摘要数
+	Counts(ctx context.Context) (map[string]int, error)
+}
+
+// NewVectorStore 创建向量存储
+func NewVectorStore(ctx context.Context, kind string, store storage.Store, maxDocuments int) (VectorStore, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "memory":
+		return newMemoryStore(ctx, store, maxDocuments)
+	case "pgvector":
+		return nil, fmt.Errorf("vector store pgvector is not available in this build (no PostgreSQL driver)")
+	default:
+		return nil, fmt.Errorf("unsupported vector store: %s", kind)
+	}
+}
+
+// memoryStore 内存向量存储：线性扫描检索，摘要追加到 storage 集合中，启动时加载
+type memoryStore struct {
+	mu    sync.RWMutex
+	docs  map[string]*Document
+	store storage.Store
+	max   int
+}
+
+func newMemoryStore(ctx context.Context, store storage.Store, maxDocuments int) (*memoryStore, error) {
+	m := &memoryStore{docs: make(map[string]*Document), store: store, max: maxDocuments}
+
+	records, err := store.List(ctx, Collection, storage.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rag documents: %w", err)
+	}
+	// 记录按写入时间升序，同一摘要以最后写入的为准
+	for _, record := range records {
+		var doc Document
+		if err := json.Unmarshal(record.Data, &doc); err != nil {
+			continue
+		}
+		m.docs[doc.ID] = &doc
+	}
+	m.evict()
+	return m, nil
+}
+
+// Upsert 写入摘要
+func (m *memoryStore) Upsert(ctx context.Context, docs []*Document) error {
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rag document %s: %w", doc.ID, err)
+		}
+		if err := m.store.Append(ctx, Collection, &storage.Record{
+			ID:        fmt.Sprintf("%s@%d", doc.ID, doc.IndexedAt.UnixNano()),
+			Key:       doc.Kind,
+			Timestamp: doc.IndexedAt,
+			Data:      data,
+		}); err != nil {
+			return fmt.Errorf("failed to store rag document %s: %w", doc.ID, err)
+		}
+
+		m.mu.Lock()
+		m.docs[doc.ID] = doc
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	m.evict()
+	m.mu.Unlock()
+	return nil
+}
+
+// evict 超出上限时淘汰最早的摘要，调用方需持有锁（加载时除外）
+func (m *memoryStore) evict() {
+	if m.max <= 0 || len(m.docs) <= m.max {
+		return
+	}
+	docs := make([]*Document, 0, len(m.docs))
+	for _, doc := range m.docs {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Time.Before(docs[j].Time) })
+	for _, doc := range docs[:len(docs)-m.max] {
+		delete(m.docs, doc.ID)
+	}
+}
+
+// Get 读取摘要
+func (m *memoryStore) Get(ctx context.Context, id string) (*Document, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	doc, ok := m.docs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return doc, nil
+}
+
+// Search 线性扫描计算余弦相似度
+func (m *memoryStore) Search(ctx context.Context, query Query) ([]Match, error) {
+	kinds := make(map[string]bool, len(query.Kinds))
+	for _, kind := range query.Kinds {
+		kinds[kind] = true
+	}
+
+	m.mu.RLock()
+	matches := make([]Match, 0)
+	for _, doc := range m.docs {
+		if doc.Embedder != query.Embedder || len(doc.Vector) != len(query.Vector) {
+			continue
+		}
+		if len(kinds) > 0 && !kinds[doc.Kind] {
+			continue
+		}
+		if !query.Before.IsZero() && !doc.Time.Before(query.Before) {
+			continue
+		}
+		score := cosine(query.Vector, doc.Vector)
+		if score < query.MinScore {
+			continue
+		}
+		matches = append(matches, Match{ID: doc.ID, Kind: doc.Kind, Ref: doc.Ref, Time: doc.Time, Text: doc.Text, Score: score})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Time.After(matches[j].Time)
+	})
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	return matches, nil
+}
+
+// Stale 返回需要重新向量化的摘要（最近的优先）
+func (m *memoryStore) Stale(ctx context.Context, embedder string, limit int) ([]*Document, error) {
+	m.mu.RLock()
+	stale := make([]*Document, 0)
+	for _, doc := range m.docs {
+		if doc.Embedder != embedder {
+			copied := *doc
+			stale = append(stale, &copied)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Time.After(stale[j].Time) })
+	if limit > 0 && len(stale) > limit {
+		stale = stale[:limit]
+	}
+	return stale, nil
+}
+
+// Counts 按类型统计摘要数
+func (m *memoryStore) Counts(ctx context.Context) (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	counts := make(map[string]int)
+	for _, doc := range m.docs {
+		counts[doc.Kind]++
+	}
+	return counts, nil
+}
+
+// cosine 余弦相似度
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,215 @@
+package rag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 摘要使用的阈值
+const (
+	highUsage      = 90.0 // 节点CPU/内存使用率（%）达到该值视为高负载
+	lowBattery     = 30.0 // UAV电量（%）低于该值视为电量不足
+	maxSummaryPods = 10
+	maxSummaryRefs = 10
+)
+
+// timeLayout 摘要中的时间格式
+const timeLayout = "2006-01-02 15:04 UTC"
+
+// snapshotSummary 集群快照摘要；没有异常（异常节点、高负载、异常Pod、Warning事件）时 notable 为 false
+func snapshotSummary(evidence *analysis.Evidence, at time.Time) (text string, notable bool) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cluster snapshot at %s.", at.UTC().Format(timeLayout))
+	if cluster := evidence.Cluster; cluster != nil {
+		fmt.Fprintf(&b, " Nodes %d (%d healthy), pods %d (%d running), CPU %.0f%%, memory %.0f%%.",
+			cluster.TotalNodes, cluster.HealthyNodes, cluster.TotalPods, cluster.RunningPods,
+			cluster.CPUUsageRate, cluster.MemoryUsageRate)
+	}
+
+	for _, node := range evidence.Nodes {
+		switch {
+		case !node.Healthy:
+			fmt.Fprintf(&b, " Node %s unhealthy", node.Name)
+			if len(node.Conditions) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(node.Conditions, ", "))
+			}
+		case node.CPUUsageRate >= highUsage || node.MemoryUsageRate >= highUsage:
+			fmt.Fprintf(&b, " Node %s under high load", node.Name)
+		default:
+			continue
+		}
+		fmt.Fprintf(&b, ": CPU %.0f%%, memory %.0f%%, disk %.0f%%.", node.CPUUsageRate, node.MemoryUsageRate, node.DiskUsageRate)
+		notable = true
+	}
+
+	for i, pod := range evidence.Pods {
+		if i == maxSummaryPods {
+			fmt.Fprintf(&b, " And %d more pods with problems.", len(evidence.Pods)-i)
+			break
+		}
+		fmt.Fprintf(&b, " Pod %s", pod.Name)
+		if pod.Node != "" {
+			fmt.Fprintf(&b, " on %s", pod.Node)
+		}
+		fmt.Fprintf(&b, ": %s", pod.Phase)
+		if !pod.Ready {
+			b.WriteString(", not ready")
+		}
+		if pod.Restarts > 0 {
+			fmt.Fprintf(&b, ", %d restarts", pod.Restarts)
+		}
+		fmt.Fprintf(&b, ", CPU %.0f%%, memory %.0f%%.", pod.CPUUsageRate, pod.MemoryUsageRate)
+		notable = true
+	}
+
+	if warnings := warningSummary(evidence.Events); warnings != "" {
+		b.WriteString(" Warning events: ")
+		b.WriteString(warnings)
+		b.WriteString(".")
+		notable = true
+	}
+	return b.String(), notable
+}
+
+// warningSummary 按原因和对象合并Warning事件，次数多的在前
+func warningSummary(events []*models.EventInfo) string {
+	type group struct {
+		key   string
+		count int
+	}
+	counts := make(map[string]int)
+	for _, event := range events {
+		if !strings.EqualFold(event.Type, "Warning") {
+			continue
+		}
+		object := event.ObjectName
+		if event.Namespace != "" {
+			object = event.Namespace + "/" + object
+		}
+		count := int(event.Count)
+		if count < 1 {
+			count = 1
+		}
+		counts[event.Reason+" on "+object] += count
+	}
+
+	groups := make([]group, 0, len(counts))
+	for key, count := range counts {
+		groups = append(groups, group{key, count})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return groups[i].key < groups[j].key
+	})
+
+	parts := make([]string, 0, maxSummaryRefs)
+	for i, g := range groups {
+		if i == maxSummaryRefs {
+			parts = append(parts, fmt.Sprintf("%d more", len(groups)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (x%d)", g.key, g.count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// incidentSummary 故障摘要（包括LLM描述，如果有）
+func incidentSummary(incident *incidents.Incident) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Incident %q (%s, %s) started %s", incident.Title, incident.Status, incident.Severity,
+		incident.StartedAt.UTC().Format(timeLayout))
+	if incident.ResolvedAt != nil {
+		fmt.Fprintf(&b, ", resolved after %s", incident.ResolvedAt.Sub(incident.StartedAt).Round(time.Minute))
+	}
+	fmt.Fprintf(&b, ", %d events.", incident.EventCount)
+
+	categories := make([]string, 0, len(incident.Categories))
+	for category, count := range incident.Categories {
+		categories = append(categories, fmt.Sprintf("%s x%d", category, count))
+	}
+	sort.Strings(categories)
+	if len(categories) > 0 {
+		fmt.Fprintf(&b, " Categories: %s.", strings.Join(categories, ", "))
+	}
+	writeList(&b, "Workloads", incident.Workloads)
+	writeList(&b, "Pods", incident.Pods)
+	writeList(&b, "Nodes", incident.Nodes)
+	for _, signal := range incident.Metrics {
+		fmt.Fprintf(&b, " %s %s %s peaked at %.0f (baseline %.0f).", signal.Target, signal.Key, signal.Metric, signal.Max, signal.Baseline)
+	}
+	if d := incident.Description; d != nil {
+		if d.Summary != "" {
+			fmt.Fprintf(&b, " Summary: %s", d.Summary)
+		}
+		if d.ProbableCause != "" {
+			fmt.Fprintf(&b, " Probable cause: %s", d.ProbableCause)
+		}
+	}
+	return b.String()
+}
+
+// writeList 写入名称列表，过长时截断
+func writeList(b *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	if len(items) > maxSummaryRefs {
+		fmt.Fprintf(b, " %s: %s and %d more.", label, strings.Join(items[:maxSummaryRefs], ", "), len(items)-maxSummaryRefs)
+		return
+	}
+	fmt.Fprintf(b, " %s: %s.", label, strings.Join(items, ", "))
+}
+
+// uavSummary UAV在一段时间内的遥测摘要；电量不足、系统状态异常或飞行模式变化时 notable 为 true
+func uavSummary(node string, points []*models.UAVTelemetryPoint) (text string, notable bool) {
+	first, last := points[0], points[len(points)-1]
+	minBattery, maxAltitude, maxSpeed := first.BatteryPercent, first.RelativeAltitude, first.GroundSpeed
+	modes := []string{first.FlightMode}
+	statuses := []string{first.SystemStatus}
+	armed := false
+	for _, p := range points {
+		if p.BatteryPercent < minBattery {
+			minBattery = p.BatteryPercent
+		}
+		if p.RelativeAltitude > maxAltitude {
+			maxAltitude = p.RelativeAltitude
+		}
+		if p.GroundSpeed > maxSpeed {
+			maxSpeed = p.GroundSpeed
+		}
+		if p.FlightMode != modes[len(modes)-1] {
+			modes = append(modes, p.FlightMode)
+		}
+		if p.SystemStatus != statuses[len(statuses)-1] {
+			statuses = append(statuses, p.SystemStatus)
+		}
+		armed = armed || p.Armed
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "UAV %s on node %s from %s to %s: battery %.0f%% to %.0f%% (min %.0f%%), system status %s, flight mode %s",
+		first.UAVID, node, first.Timestamp.UTC().Format(timeLayout), last.Timestamp.UTC().Format(timeLayout),
+		first.BatteryPercent, last.BatteryPercent, minBattery,
+		strings.Join(statuses, " -> "), strings.Join(modes, " -> "))
+	if armed {
+		fmt.Fprintf(&b, ", armed, max relative altitude %.0fm, max ground speed %.1fm/s", maxAltitude, maxSpeed)
+	}
+	b.WriteString(".")
+
+	notable = minBattery < lowBattery || len(modes) > 1
+	for _, status := range statuses {
+		switch strings.ToUpper(status) {
+		case "WARNING", "CRITICAL", "ERROR":
+			notable = true
+		}
+	}
+	return b.String(), notable
+}
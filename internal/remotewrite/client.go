@@ -0,0 +1,355 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+)
+
+// 默认参数
+const (
+	defaultBatchSize     = 2000
+	defaultQueueSize     = 100000
+	defaultFlushInterval = 5 * time.Second
+	defaultTimeout       = 10 * time.Second
+	defaultMinBackoff    = 500 * time.Millisecond
+	defaultMaxBackoff    = 30 * time.Second
+)
+
+// Label 时间序列的标签
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample 一个样本，Labels 包含 __name__
+type Sample struct {
+	Labels    []Label
+	Value     float64
+	Timestamp time.Time
+}
+
+// Config remote_write客户端配置
+type Config struct {
+	URL            string            // 例如 http://prometheus:9090/api/v1/write、http://mimir/api/v1/push
+	Headers        map[string]string // 额外的请求头，例如 X-Scope-OrgID
+	BearerToken    string
+	Username       string // 设置后使用Basic认证
+	Password       string
+	ExternalLabels map[string]string // 附加到每个序列的标签（序列已有同名标签时不覆盖），例如 cluster=edge-1
+
+	BatchSize     int           // 每个请求最多包含的样本数
+	QueueSize     int           // 等待发送的样本上限，超出时丢弃最旧的样本
+	FlushInterval time.Duration // 队列未满一批时的发送间隔
+	Timeout       time.Duration // 单个请求超时
+	MinBackoff    time.Duration // 发送失败后的重试等待，每次翻倍直到 MaxBackoff
+	MaxBackoff    time.Duration
+
+	HTTPClient *http.Client // 为空时使用带 Timeout 的默认客户端
+}
+
+// Stats 发送统计
+type Stats struct {
+	URL         string    `json:"url"`
+	Queued      int       `json:"queued"`          // 等待发送的样本数
+	Sent        int64     `json:"samples_sent"`    // 已成功发送的样本数
+	Dropped     int64     `json:"samples_dropped"` // 因队列已满或被拒绝而丢弃的样本数
+	Requests    int64     `json:"requests"`
+	Failures    int64     `json:"failures"` // 失败的请求数（包括之后重试成功的）
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+// Client 将样本分批推送到Prometheus remote_write接口（Prometheus、Mimir、VictoriaMetrics等）。
+// 样本先进入有界队列，后台按批发送；可重试的失败（网络错误、5xx、429）按指数退避重试同一批，
+// 期间新样本继续排队，队列满时丢弃最旧的样本
+type Client struct {
+	url    string
+	cfg    Config
+	client *http.Client
+	logger *logrus.Entry
+
+	mu       sync.Mutex
+	queue    []Sample
+	inflight int // 队首正在发送的样本数，队列满时不丢弃这些样本
+	stats    Stats
+	notify   chan struct{}
+}
+
+// sendError 发送失败，retryable 表示可以重试
+type sendError struct {
+	err       error
+	retryable bool
+}
+
+func (e *sendError) Error() string { return e.err.Error() }
+func (e *sendError) Unwrap() error { return e.err }
+
+// New 创建remote_write客户端
+func New(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote_write url %q", cfg.URL)
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.QueueSize < cfg.BatchSize {
+		cfg.QueueSize = cfg.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = defaultMaxBackoff
+		if cfg.MaxBackoff < cfg.MinBackoff {
+			cfg.MaxBackoff = cfg.MinBackoff
+		}
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	// 日志和统计中不包含URL里的认证信息
+	redacted := *u
+	redacted.User = nil
+
+	return &Client{
+		url:    u.String(),
+		cfg:    cfg,
+		client: client,
+		logger: logging.For("remotewrite"),
+		stats:  Stats{URL: redacted.String()},
+		notify: make(chan struct{}, 1),
+	}, nil
+}
+
+// Enqueue 将样本加入发送队列并附加外部标签，队列满时丢弃最旧的样本，返回丢弃的数量
+func (c *Client) Enqueue(samples []Sample) int {
+	if len(samples) == 0 {
+		return 0
+	}
+	for i := range samples {
+		samples[i].Labels = c.withExternalLabels(samples[i].Labels)
+	}
+
+	c.mu.Lock()
+	c.queue = append(c.queue, samples...)
+	dropped := 0
+	if overflow := len(c.queue) - c.cfg.QueueSize; overflow > 0 {
+		if pending := len(c.queue) - c.inflight; overflow > pending {
+			overflow = pending
+		}
+		c.queue = append(c.queue[:c.inflight], c.queue[c.inflight+overflow:]...)
+		dropped = overflow
+		c.stats.Dropped += int64(overflow)
+	}
+	ready := len(c.queue) >= c.cfg.BatchSize
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		c.logger.Warnf("Remote write queue full, dropped %d oldest samples", dropped)
+	}
+	if ready {
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	}
+	return dropped
+}
+
+// withExternalLabels 附加外部标签并按名称排序
+func (c *Client) withExternalLabels(labels []Label) []Label {
+	result := make([]Label, 0, len(labels)+len(c.cfg.ExternalLabels))
+	result = append(result, labels...)
+	for name, value := range c.cfg.ExternalLabels {
+		exists := false
+		for _, label := range labels {
+			if label.Name == name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			result = append(result, Label{Name: name, Value: value})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Start 启动后台发送循环，ctx 结束时尽量发送剩余的样本后返回
+func (c *Client) Start(ctx context.Context) {
+	c.logger.Infof("Remote write started (url: %s, batch size: %d, queue size: %d)", c.stats.URL, c.cfg.BatchSize, c.cfg.QueueSize)
+
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	backoff := time.Duration(0)
+	for {
+		if backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				c.drain()
+				return
+			case <-ticker.C:
+			case <-c.notify:
+			}
+		}
+
+		if err := c.flush(ctx); err != nil {
+			backoff = nextBackoff(backoff, c.cfg.MinBackoff, c.cfg.MaxBackoff)
+			c.logger.Warnf("Remote write failed, retrying in %v: %v", backoff, err)
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// nextBackoff 指数退避
+func nextBackoff(current, min, max time.Duration) time.Duration {
+	if current <= 0 {
+		return min
+	}
+	current *= 2
+	if current > max {
+		return max
+	}
+	return current
+}
+
+// drain 退出前用独立的超时尽量发送剩余样本
+func (c *Client) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+	if err := c.flush(ctx); err != nil {
+		c.logger.Warnf("Failed to flush remote write queue on shutdown: %v", err)
+	}
+}
+
+// flush 按批发送队列中的所有样本，遇到可重试的失败时保留该批样本并返回错误
+func (c *Client) flush(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		n := len(c.queue)
+		if n > c.cfg.BatchSize {
+			n = c.cfg.BatchSize
+		}
+		batch := append([]Sample(nil), c.queue[:n]...)
+		c.inflight = n
+		c.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := c.send(ctx, batch)
+
+		c.mu.Lock()
+		c.inflight = 0
+		c.stats.Requests++
+		var failure *sendError
+		if err != nil {
+			c.stats.Failures++
+			c.stats.LastError, c.stats.LastErrorAt = err.Error(), time.Now()
+		}
+		retry := err != nil && (!errors.As(err, &failure) || failure.retryable)
+		if !retry {
+			c.queue = append(c.queue[:0], c.queue[len(batch):]...)
+			if err == nil {
+				c.stats.Sent += int64(len(batch))
+				c.stats.LastSuccess = time.Now()
+			} else {
+				c.stats.Dropped += int64(len(batch))
+			}
+		}
+		c.mu.Unlock()
+
+		if retry {
+			return err
+		}
+		if err != nil {
+			c.logger.Errorf("Remote write rejected %d samples: %v", len(batch), err)
+		}
+	}
+}
+
+// send 编码并发送一批样本
+func (c *Client) send(ctx context.Context, batch []Sample) error {
+	body := snappyEncode(encodeWriteRequest(batch))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return &sendError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "k8s-llm-monitor")
+	for name, value := range c.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	case c.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &sendError{err: fmt.Errorf("request failed: %w", err), retryable: true}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	err = fmt.Errorf("server returned %s", resp.Status)
+	if message, _ := io.ReadAll(io.LimitReader(resp.Body, 512)); len(bytes.TrimSpace(message)) > 0 {
+		err = fmt.Errorf("%w: %s", err, bytes.TrimSpace(message))
+	}
+	return &sendError{
+		err:       err,
+		retryable: resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests,
+	}
+}
+
+// Stats 返回发送统计
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Queued = len(c.queue)
+	return stats
+}
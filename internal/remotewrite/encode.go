@@ -0,0 +1,178 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Prometheus remote_write 1.0 的 WriteRequest（protobuf）编码和snappy块格式压缩。
+// 只实现发送需要的部分，避免引入prometheus/prompb和snappy依赖（测试中用 golang/snappy 和 protobuf 解码验证）
+
+// protobuf字段的wire type
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// series 同一标签集合的样本
+type series struct {
+	labels  []Label
+	samples []Sample
+}
+
+// encodeWriteRequest 按标签集合分组样本并编码为 WriteRequest，
+// 标签按名称排序，同一序列的样本按时间升序
+func encodeWriteRequest(samples []Sample) []byte {
+	index := make(map[string]*series)
+	var order []*series
+	for _, sample := range samples {
+		key := labelsKey(sample.Labels)
+		s := index[key]
+		if s == nil {
+			s = &series{labels: sample.Labels}
+			index[key] = s
+			order = append(order, s)
+		}
+		s.samples = append(s.samples, sample)
+	}
+
+	var buf, ts, item []byte
+	for _, s := range order {
+		sort.SliceStable(s.samples, func(i, j int) bool { return s.samples[i].Timestamp.Before(s.samples[j].Timestamp) })
+
+		ts = ts[:0]
+		for _, label := range s.labels {
+			item = item[:0]
+			item = appendString(item, 1, label.Name)
+			item = appendString(item, 2, label.Value)
+			ts = appendBytes(ts, 1, item)
+		}
+		for _, sample := range s.samples {
+			item = item[:0]
+			item = appendTag(item, 1, wireFixed64)
+			item = binary.LittleEndian.AppendUint64(item, math.Float64bits(sample.Value))
+			item = appendTag(item, 2, wireVarint)
+			item = binary.AppendUvarint(item, uint64(sample.Timestamp.UnixMilli()))
+			ts = appendBytes(ts, 2, item)
+		}
+		buf = appendBytes(buf, 1, ts)
+	}
+	return buf
+}
+
+// labelsKey 标签集合的唯一标识（标签已排序）
+func labelsKey(labels []Label) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.Name)
+		b.WriteByte(0)
+		b.WriteString(label.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func appendTag(buf []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(field<<3|wireType))
+}
+
+func appendBytes(buf []byte, field int, value []byte) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+func appendString(buf []byte, field int, value string) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// snappy块格式参数
+const (
+	snappyMaxBlock  = 1 << 16 // 按64KB分块查找匹配，保证偏移量可以用2字节表示
+	snappyTableBits = 14
+	snappyMinInput  = 16 // 小于该长度的块直接作为字面量输出
+	snappyMaxCopy   = 64 // 2字节偏移的复制操作最多复制64字节
+)
+
+// snappyEncode 按snappy块格式（非framed）压缩，remote_write要求的Content-Encoding: snappy。
+// 使用简单的贪心哈希匹配，压缩率低于官方实现但输出可被任何snappy解码器解码
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+	for len(src) > 0 {
+		block := src
+		if len(block) > snappyMaxBlock {
+			block = block[:snappyMaxBlock]
+		}
+		dst = snappyEncodeBlock(dst, block)
+		src = src[len(block):]
+	}
+	return dst
+}
+
+func snappyEncodeBlock(dst, src []byte) []byte {
+	if len(src) < snappyMinInput {
+		return snappyLiteral(dst, src)
+	}
+
+	var table [1 << snappyTableBits]int32 // 哈希 -> 位置+1（0表示空）
+	literal := 0
+	for i := 0; i+4 <= len(src); {
+		current := binary.LittleEndian.Uint32(src[i:])
+		h := (current * 0x1e35a7bd) >> (32 - snappyTableBits)
+		candidate := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if candidate < 0 || binary.LittleEndian.Uint32(src[candidate:]) != current {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = snappyLiteral(dst, src[literal:i])
+		dst = snappyCopy(dst, i-candidate, length)
+		i += length
+		literal = i
+	}
+	return snappyLiteral(dst, src[literal:])
+}
+
+// snappyLiteral 输出字面量
+func snappyLiteral(dst, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+	n := len(literal) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n<<2))
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+// snappyCopy 输出复制操作（2字节偏移，每次最多64字节）
+func snappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := length
+		if n > snappyMaxCopy {
+			n = snappyMaxCopy
+		}
+		dst = append(dst, byte(n-1)<<2|2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,230 @@
+package remotewrite
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestSnappyEncodeReferenceDecoder(t *testing.T) {
+	random := make([]byte, 200<<10)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	// 可压缩的文本，跨越多个64KB块
+	var text bytes.Buffer
+	for i := 0; text.Len() < 300<<10; i++ {
+		text.WriteString(`k8s_llm_monitor_node_cpu_usage{node="node-`)
+		text.WriteByte(byte('0' + i%10))
+		text.WriteString(`",cluster="edge-1"} `)
+	}
+
+	tests := []struct {
+		name         string
+		src          []byte
+		compressible bool // 压缩后应小于原大小的一半
+	}{
+		{name: "empty", src: nil},
+		{name: "short literal", src: []byte("remote_write")},
+		{name: "exactly min input", src: []byte("0123456789abcdef")},
+		{name: "overlapping copy offset 1", src: bytes.Repeat([]byte{'a'}, 1000), compressible: true},
+		{name: "overlapping copy offset 3", src: bytes.Repeat([]byte("abc"), 1000), compressible: true},
+		{name: "copy longer than 64 bytes", src: append(bytes.Repeat([]byte("0123456789"), 30), "tail"...)},
+		{name: "block boundary", src: bytes.Repeat([]byte{'z'}, snappyMaxBlock), compressible: true},
+		{name: "zeros over 64KB", src: make([]byte, 3*snappyMaxBlock+17), compressible: true},
+		{name: "random over 64KB", src: random},
+		{name: "text over 64KB", src: text.Bytes(), compressible: true},
+		{name: "random prefix then repeats", src: append(append([]byte{}, random[:snappyMaxBlock-5]...), bytes.Repeat(random[:100], 50)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := snappyEncode(tt.src)
+			if n, err := snappy.DecodedLen(encoded); err != nil || n != len(tt.src) {
+				t.Fatalf("DecodedLen() = %d, %v, want %d", n, err, len(tt.src))
+			}
+			decoded, err := snappy.Decode(nil, encoded)
+			if err != nil {
+				t.Fatalf("snappy.Decode() error = %v", err)
+			}
+			if !bytes.Equal(decoded, tt.src) {
+				t.Fatalf("decoded %d bytes differ from the %d byte input", len(decoded), len(tt.src))
+			}
+			if tt.compressible && len(encoded) > len(tt.src)/2 {
+				t.Errorf("compressed %d bytes to %d", len(tt.src), len(encoded))
+			}
+		})
+	}
+}
+
+// writeRequestDescriptor 与 prompb（prometheus/prompb 的 remote.proto 和 types.proto）一致的
+// WriteRequest 消息定义，只包含发送用到的字段
+func writeRequestDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, repeated bool, message string) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(), Label: label.Enum()}
+		if message != "" {
+			f.TypeName = proto.String(".prometheus." + message)
+		}
+		return f
+	}
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("remote.proto"),
+		Package: proto.String("prometheus"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("WriteRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("timeseries", 1, message, true, "TimeSeries"),
+			}},
+			{Name: proto.String("TimeSeries"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("labels", 1, message, true, "Label"),
+				field("samples", 2, message, true, "Sample"),
+			}},
+			{Name: proto.String("Label"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, false, ""),
+				field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, false, ""),
+			}},
+			{Name: proto.String("Sample"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("value", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, false, ""),
+				field("timestamp", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, false, ""),
+			}},
+		},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile() error = %v", err)
+	}
+	return fd.Messages().ByName("WriteRequest")
+}
+
+// decodedSeries 用protobuf解码得到的一个序列
+type decodedSeries struct {
+	labels []Label
+	values []float64
+	stamps []int64
+}
+
+func decodeWriteRequest(t *testing.T, data []byte) []decodedSeries {
+	t.Helper()
+	desc := writeRequestDescriptor(t)
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+	if len(msg.GetUnknown()) != 0 {
+		t.Fatalf("WriteRequest has %d bytes of unknown fields", len(msg.GetUnknown()))
+	}
+
+	var result []decodedSeries
+	list := msg.Get(desc.Fields().ByName("timeseries")).List()
+	for i := 0; i < list.Len(); i++ {
+		ts := list.Get(i).Message()
+		tsDesc := ts.Descriptor()
+		var s decodedSeries
+		labels := ts.Get(tsDesc.Fields().ByName("labels")).List()
+		for j := 0; j < labels.Len(); j++ {
+			label := labels.Get(j).Message()
+			fields := label.Descriptor().Fields()
+			s.labels = append(s.labels, Label{Name: label.Get(fields.ByName("name")).String(), Value: label.Get(fields.ByName("value")).String()})
+		}
+		samples := ts.Get(tsDesc.Fields().ByName("samples")).List()
+		for j := 0; j < samples.Len(); j++ {
+			sample := samples.Get(j).Message()
+			fields := sample.Descriptor().Fields()
+			s.values = append(s.values, sample.Get(fields.ByName("value")).Float())
+			s.stamps = append(s.stamps, sample.Get(fields.ByName("timestamp")).Int())
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestEncodeWriteRequestReferenceDecoder(t *testing.T) {
+	base := time.UnixMilli(1700000000123)
+	cpu := []Label{{Name: "__name__", Value: "k8s_llm_monitor_node_cpu_usage"}, {Name: "node", Value: "node-1"}}
+	uav := []Label{{Name: "__name__", Value: "k8s_llm_monitor_uav_battery_percent"}, {Name: "uav", Value: "无人机-1"}}
+	samples := []Sample{
+		{Labels: cpu, Value: 0.5, Timestamp: base.Add(2 * time.Second)},
+		{Labels: uav, Value: 87.25, Timestamp: base},
+		{Labels: cpu, Value: math.Inf(1), Timestamp: base},
+		{Labels: cpu, Value: -3, Timestamp: base.Add(time.Second)},
+	}
+
+	decoded := decodeWriteRequest(t, encodeWriteRequest(samples))
+	if len(decoded) != 2 {
+		t.Fatalf("got %d series, want 2", len(decoded))
+	}
+	want := []struct {
+		labels []Label
+		values []float64
+		stamps []int64
+	}{
+		{cpu, []float64{math.Inf(1), -3, 0.5}, []int64{base.UnixMilli(), base.UnixMilli() + 1000, base.UnixMilli() + 2000}},
+		{uav, []float64{87.25}, []int64{base.UnixMilli()}},
+	}
+	for i, w := range want {
+		got := decoded[i]
+		if len(got.labels) != len(w.labels) {
+			t.Fatalf("series %d labels = %v, want %v", i, got.labels, w.labels)
+		}
+		for j := range w.labels {
+			if got.labels[j] != w.labels[j] {
+				t.Errorf("series %d label %d = %v, want %v", i, j, got.labels[j], w.labels[j])
+			}
+		}
+		if len(got.values) != len(w.values) {
+			t.Fatalf("series %d has %d samples, want %d", i, len(got.values), len(w.values))
+		}
+		for j := range w.values {
+			if got.values[j] != w.values[j] || got.stamps[j] != w.stamps[j] {
+				t.Errorf("series %d sample %d = %v@%d, want %v@%d", i, j, got.values[j], got.stamps[j], w.values[j], w.stamps[j])
+			}
+		}
+	}
+}
+
+// 完整的发送路径：大批量样本编码后压缩（超过64KB），由参考实现解压并解码
+func TestEncodeLargeWriteRequest(t *testing.T) {
+	base := time.UnixMilli(1700000000000)
+	var samples []Sample
+	for i := 0; i < 5000; i++ {
+		labels := []Label{{Name: "__name__", Value: "k8s_llm_monitor_pod_cpu_usage"}, {Name: "pod", Value: "app-" + string(rune('a'+i%26))}}
+		samples = append(samples, Sample{Labels: labels, Value: float64(i), Timestamp: base.Add(time.Duration(i) * time.Millisecond)})
+	}
+	payload := encodeWriteRequest(samples)
+	if len(payload) <= snappyMaxBlock {
+		t.Fatalf("payload is %d bytes, want more than one snappy block", len(payload))
+	}
+	raw, err := snappy.Decode(nil, snappyEncode(payload))
+	if err != nil {
+		t.Fatalf("snappy.Decode() error = %v", err)
+	}
+	decoded := decodeWriteRequest(t, raw)
+	if len(decoded) != 26 {
+		t.Fatalf("got %d series, want 26", len(decoded))
+	}
+	total := 0
+	for _, s := range decoded {
+		total += len(s.values)
+		for j := 1; j < len(s.stamps); j++ {
+			if s.stamps[j] < s.stamps[j-1] {
+				t.Fatalf("series %v samples are not sorted by time", s.labels)
+			}
+		}
+	}
+	if total != len(samples) {
+		t.Errorf("decoded %d samples, want %d", total, len(samples))
+	}
+}
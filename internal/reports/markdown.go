@@ -0,0 +1,137 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Markdown 将报告渲染为Markdown
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Cluster Health Report %s\n\n", r.Date)
+	fmt.Fprintf(&b, "Period: %s – %s (UTC)", r.From.Format("2006-01-02 15:04"), r.To.Format("2006-01-02 15:04"))
+	if r.Partial {
+		b.WriteString(" — partial, the day was not over when generated")
+	}
+	fmt.Fprintf(&b, "  \nGenerated: %s (%s)\n\n", r.GeneratedAt.Format("2006-01-02 15:04:05"), r.Trigger)
+
+	if s := r.Summary; s != nil {
+		if s.Headline != "" {
+			fmt.Fprintf(&b, "**%s**", s.Headline)
+			if s.Health != "" {
+				fmt.Fprintf(&b, " (%s)", s.Health)
+			}
+			b.WriteString("\n\n")
+		}
+		if s.Summary != "" {
+			b.WriteString(s.Summary + "\n\n")
+		}
+		writeList(&b, "Highlights", s.Highlights)
+		writeList(&b, "Risks", s.Risks)
+		writeList(&b, "Recommendations", s.Recommendations)
+	} else if r.SummaryError != "" {
+		fmt.Fprintf(&b, "> Summary unavailable: %s\n\n", r.SummaryError)
+	}
+
+	if r.Stats != nil {
+		writeStats(&b, r.Stats)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## %s\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+	b.WriteString("\n")
+}
+
+func writeStats(b *strings.Builder, s *Stats) {
+	fmt.Fprintf(b, "## Nodes\n\n%d metric snapshots.\n\n", s.Snapshots)
+	if len(s.Nodes) > 0 {
+		b.WriteString("| Node | CPU avg | CPU max | Memory avg | Memory max | Disk max |\n|---|---|---|---|---|---|\n")
+		for _, n := range s.Nodes {
+			fmt.Fprintf(b, "| %s | %.1f%% | %.1f%% | %.1f%% | %.1f%% | %.1f%% |\n", n.Name, n.CPUAvg, n.CPUMax, n.MemoryAvg, n.MemoryMax, n.DiskMax)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "## Pods\n\n%d pods, %d restarted (%d restarts).\n\n", s.Pods.Count, s.Pods.Restarted, s.Pods.Restarts)
+	if len(s.Pods.Top) > 0 {
+		b.WriteString("| Pod | Restarts | CPU max | Memory max |\n|---|---|---|---|\n")
+		for _, p := range s.Pods.Top {
+			fmt.Fprintf(b, "| %s | %d | %.1f%% | %.1f%% |\n", p.Name, p.Restarts, p.CPUMax, p.MemoryMax)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "## Network\n\n%d tests across %d pod pairs, %d failed; RTT avg %.2f ms, p95 %.2f ms.\n\n",
+		s.Network.Tests, s.Network.Pairs, s.Network.Failures, s.Network.RTTAvg, s.Network.RTTP95)
+	if len(s.Network.Worst) > 0 {
+		b.WriteString("| Pair | Tests | Failures | RTT avg | RTT max |\n|---|---|---|---|---|\n")
+		for _, p := range s.Network.Worst {
+			fmt.Fprintf(b, "| %s | %d | %d | %.2f ms | %.2f ms |\n", strings.Replace(p.Pair, "|", " → ", 1), p.Tests, p.Failures, p.RTTAvg, p.RTTMax)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.UAVs) > 0 {
+		b.WriteString("## UAVs\n\n| Node | UAV | Reports | Battery min | Battery last | Flight modes | Armed | Warnings |\n|---|---|---|---|---|---|---|---|\n")
+		for _, u := range s.UAVs {
+			battery := fmt.Sprintf("%.0f%%", u.BatteryMin)
+			if u.BatteryMin < lowBatteryLevel {
+				battery += " ⚠"
+			}
+			fmt.Fprintf(b, "| %s | %s | %d | %s | %.0f%% | %s | %s | %d |\n",
+				u.Node, u.UAVID, u.Reports, battery, u.BatteryLast, strings.Join(u.FlightModes, ", "), u.ArmedTime, u.Warnings)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "## Incidents\n\n%d incidents, %d critical.\n\n", s.Incidents.Total, s.Incidents.Critical)
+	for _, i := range s.Incidents.Items {
+		fmt.Fprintf(b, "- [%s] %s — %s, %d events, %s (`%s`)\n", i.Severity, i.Title, i.Status, i.Events, i.Duration, i.ID)
+	}
+	if len(s.Incidents.Items) > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "## Anomalies\n\n%d anomalies detected.\n\n", s.Anomalies.Total)
+	for _, a := range s.Anomalies.Top {
+		fmt.Fprintf(b, "- %s %s: peak %.2f vs baseline %.2f (z=%.1f) at %s", a.Key, a.Metric, a.Peak, a.Baseline, a.ZScore, a.At.Format("15:04"))
+		if a.Severity != "" {
+			fmt.Fprintf(b, ", %s", a.Severity)
+		}
+		b.WriteString("\n")
+	}
+	if len(s.Anomalies.Top) > 0 {
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "## Events\n\n%d warning events.\n\n", s.Events.Warnings)
+	if len(s.Events.TopReasons) > 0 {
+		reasons := make([]string, 0, len(s.Events.TopReasons))
+		for reason := range s.Events.TopReasons {
+			reasons = append(reasons, reason)
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			if s.Events.TopReasons[reasons[i]] != s.Events.TopReasons[reasons[j]] {
+				return s.Events.TopReasons[reasons[i]] > s.Events.TopReasons[reasons[j]]
+			}
+			return reasons[i] < reasons[j]
+		})
+		for _, reason := range reasons {
+			fmt.Fprintf(b, "- %s: %d\n", reason, s.Events.TopReasons[reason])
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Gaps) > 0 {
+		writeList(b, "Data gaps", s.Gaps)
+	}
+}
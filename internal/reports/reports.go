@@ -0,0 +1,287 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// Collection 报告在存储中的集合名
+const Collection = "reports"
+
+// TypeReport 报告摘要在 llm.routing 中使用的分析类型
+const TypeReport = "report"
+
+// DateLayout 报告日期格式（UTC）
+const DateLayout = "2006-01-02"
+
+// 生成方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	// ErrNotFound 报告不存在
+	ErrNotFound = errors.New("report not found")
+	// ErrFutureDate 日期尚未开始
+	ErrFutureDate = errors.New("report date is in the future")
+
+	errEmptySummary = errors.New("model returned an empty summary")
+)
+
+// Summary LLM给出的摘要
+type Summary struct {
+	Headline        string   `json:"headline"`
+	Health          string   `json:"health"` // healthy, degraded, critical
+	Summary         string   `json:"summary"`
+	Highlights      []string `json:"highlights,omitempty"`
+	Risks           []string `json:"risks,omitempty"`
+	Recommendations []string `json:"recommendations,omitempty"`
+}
+
+// Report 一个UTC日的集群健康报告
+type Report struct {
+	ID           string    `json:"id"`
+	Date         string    `json:"date"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Partial      bool      `json:"partial,omitempty"` // 生成时当天尚未结束
+	Trigger      string    `json:"trigger"`
+	Stats        *Stats    `json:"stats"`
+	Summary      *Summary  `json:"summary,omitempty"`
+	SummaryError string    `json:"summary_error,omitempty"` // LLM摘要失败的原因，统计数据仍然有效
+	Model        string    `json:"model,omitempty"`
+	Prompt       string    `json:"prompt,omitempty"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// Filter 报告查询条件（按报告日期）
+type Filter struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// Generator 按cron计划汇总前一天的指标、故障、网络测试和UAV遥测，由LLM生成摘要
+type Generator struct {
+	cfg       config.ReportsConfig
+	schedule  *Schedule
+	store     storage.Store
+	service   *llm.Service
+	templates *prompts.Registry
+	sources   analysis.Sources
+	incidents *incidents.Engine
+	logger    *logrus.Entry
+
+	mu sync.Mutex // 同一时间只生成一份报告
+}
+
+// New 创建报告生成器；engine 可以为空（未启用故障关联）
+func New(cfg config.ReportsConfig, store storage.Store, service *llm.Service, templates *prompts.Registry, sources analysis.Sources, engine *incidents.Engine) (*Generator, error) {
+	if cfg.Schedule == "" {
+		cfg.Schedule = "10 0 * * *"
+	}
+	schedule, err := ParseSchedule(cfg.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{
+		cfg:       cfg,
+		schedule:  schedule,
+		store:     store,
+		service:   service,
+		templates: templates,
+		sources:   sources,
+		incidents: engine,
+		logger:    logging.For("reports"),
+	}, nil
+}
+
+// Start 按计划生成上一个完整UTC日的报告，直到 ctx 取消
+func (g *Generator) Start(ctx context.Context) {
+	for {
+		next := g.schedule.Next(time.Now())
+		if next.IsZero() {
+			g.logger.Warnf("Report schedule %q never fires", g.cfg.Schedule)
+			return
+		}
+		g.logger.Debugf("Next daily report at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		day := next.AddDate(0, 0, -1)
+		report, err := g.Generate(ctx, day, g.cfg.Profile, TriggerSchedule)
+		switch {
+		case err != nil:
+			g.logger.Warnf("Failed to generate report for %s: %v", day.Format(DateLayout), err)
+		case report.SummaryError != "":
+			g.logger.Warnf("Report %s generated without summary: %s", report.ID, report.SummaryError)
+		default:
+			g.logger.Infof("Report %s generated", report.ID)
+		}
+	}
+}
+
+// Generate 生成（或重新生成）day 所在UTC日的报告；LLM失败时保存不含摘要的报告并在 SummaryError 中记录原因
+func (g *Generator) Generate(ctx context.Context, day time.Time, profile, trigger string) (*Report, error) {
+	now := time.Now().UTC()
+	from := day.UTC().Truncate(24 * time.Hour)
+	to := from.Add(24 * time.Hour)
+	if from.After(now) {
+		return nil, ErrFutureDate
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	report := &Report{
+		ID:      reportID(from),
+		Date:    from.Format(DateLayout),
+		From:    from,
+		To:      to,
+		Trigger: trigger,
+	}
+	if to.After(now) {
+		report.To, report.Partial = now, true
+	}
+	report.Stats = g.collect(ctx, report.From, report.To)
+
+	if err := g.summarize(ctx, report, profile); err != nil {
+		report.SummaryError = err.Error()
+	}
+	report.GeneratedAt = time.Now().UTC()
+
+	if err := g.save(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// summarize 由LLM根据汇总数据生成摘要
+func (g *Generator) summarize(ctx context.Context, report *Report, profile string) error {
+	client, err := g.service.Client(TypeReport, profile)
+	if err != nil {
+		return err
+	}
+	prompt, err := g.templates.Render(prompts.DailyReport, map[string]interface{}{
+		"date":  report.Date,
+		"stats": report.Stats,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := client.Chat(ctx, llm.Request{
+		Messages: []llm.Message{llm.SystemMessage(prompt.System), llm.UserMessage(prompt.User)},
+		JSONMode: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var summary Summary
+	if err := llm.DecodeJSON(resp.Content, &summary); err != nil {
+		return err
+	}
+	if summary.Headline == "" && summary.Summary == "" {
+		return errEmptySummary
+	}
+	summary.Health = strings.ToLower(strings.TrimSpace(summary.Health))
+	switch summary.Health {
+	case "healthy", "degraded", "critical":
+	default:
+		summary.Health = ""
+	}
+	report.Summary = &summary
+	report.Model = resp.Model
+	report.Prompt = prompt.Template
+	return nil
+}
+
+// Get 根据ID（report-2006-01-02）或日期获取报告
+func (g *Generator) Get(ctx context.Context, id string) (*Report, error) {
+	if !strings.HasPrefix(id, "report-") {
+		id = "report-" + id
+	}
+	data, err := g.store.Get(ctx, Collection, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// List 查询报告（按日期降序）
+func (g *Generator) List(ctx context.Context, filter Filter) ([]*Report, error) {
+	records, err := g.store.List(ctx, Collection, storage.Query{From: filter.From, To: filter.To})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	// 重新生成的报告追加新记录，以最后一条为准
+	latest := make(map[string]*Report)
+	for _, record := range records {
+		var report Report
+		if err := json.Unmarshal(record.Data, &report); err != nil {
+			continue
+		}
+		latest[report.ID] = &report
+	}
+
+	result := make([]*Report, 0, len(latest))
+	for _, report := range latest {
+		result = append(result, report)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date > result[j].Date })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// save 保存报告；重新生成时追加新记录，查询时以最后一条为准
+func (g *Generator) save(ctx context.Context, report *Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := g.store.Put(ctx, Collection, report.ID, data); err != nil {
+		return fmt.Errorf("failed to store report %s: %w", report.ID, err)
+	}
+	return g.store.Append(ctx, Collection, &storage.Record{
+		ID:        report.ID,
+		Key:       TypeReport,
+		Timestamp: report.From,
+		Data:      data,
+	})
+}
+
+func reportID(day time.Time) string {
+	return "report-" + day.Format(DateLayout)
+}
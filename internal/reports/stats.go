@@ -0,0 +1,540 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/telemetry"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 报告中各类列表的最大条数
+const (
+	maxTopPods      = 10
+	maxWorstPairs   = 5
+	maxTopItems     = 10
+	lowBatteryLevel = 30
+)
+
+// NodeStats 节点一天的资源使用
+type NodeStats struct {
+	Name      string  `json:"name"`
+	Samples   int     `json:"samples"`
+	CPUAvg    float64 `json:"cpu_avg"`
+	CPUMax    float64 `json:"cpu_max"`
+	MemoryAvg float64 `json:"memory_avg"`
+	MemoryMax float64 `json:"memory_max"`
+	DiskMax   float64 `json:"disk_max"`
+}
+
+// PodStats 重启最多或负载最高的Pod
+type PodStats struct {
+	Name      string  `json:"name"` // namespace/name
+	Restarts  int     `json:"restarts"`
+	CPUMax    float64 `json:"cpu_max"`
+	MemoryMax float64 `json:"memory_max"`
+}
+
+// PodSummary Pod汇总
+type PodSummary struct {
+	Count     int        `json:"count"`
+	Restarted int        `json:"restarted"` // 当天有重启的Pod数
+	Restarts  int        `json:"restarts"`  // 当天的重启总次数
+	Top       []PodStats `json:"top,omitempty"`
+}
+
+// PairStats 一对Pod的网络测试
+type PairStats struct {
+	Pair     string  `json:"pair"` // source|target
+	Tests    int     `json:"tests"`
+	Failures int     `json:"failures"`
+	RTTAvg   float64 `json:"rtt_avg_ms"`
+	RTTMax   float64 `json:"rtt_max_ms"`
+}
+
+// NetworkStats 网络测试汇总
+type NetworkStats struct {
+	Pairs    int         `json:"pairs"`
+	Tests    int         `json:"tests"`
+	Failures int         `json:"failures"`
+	RTTAvg   float64     `json:"rtt_avg_ms"`
+	RTTP95   float64     `json:"rtt_p95_ms"`
+	Worst    []PairStats `json:"worst,omitempty"`
+}
+
+// UAVStats UAV一天的遥测
+type UAVStats struct {
+	Node        string   `json:"node"`
+	UAVID       string   `json:"uav_id,omitempty"`
+	Reports     int      `json:"reports"`
+	BatteryMin  float64  `json:"battery_min"`
+	BatteryLast float64  `json:"battery_last"`
+	FlightModes []string `json:"flight_modes"` // 按出现顺序去重
+	ModeChanges int      `json:"mode_changes"`
+	ArmedTime   string   `json:"armed_time,omitempty"` // 解锁状态的大致时长
+	Warnings    int      `json:"warnings"`             // 系统状态为 WARNING/CRITICAL/ERROR 的上报数
+}
+
+// IncidentBrief 故障摘要
+type IncidentBrief struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Severity  string    `json:"severity"`
+	Status    string    `json:"status"`
+	Events    int       `json:"events"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  string    `json:"duration"`
+}
+
+// IncidentStats 故障汇总
+type IncidentStats struct {
+	Total      int             `json:"total"`
+	Critical   int             `json:"critical"`
+	Categories map[string]int  `json:"categories,omitempty"`
+	Items      []IncidentBrief `json:"items,omitempty"`
+}
+
+// AnomalyBrief 异常摘要
+type AnomalyBrief struct {
+	Target   string    `json:"target"`
+	Key      string    `json:"key"`
+	Metric   string    `json:"metric"`
+	Peak     float64   `json:"peak"`
+	Baseline float64   `json:"baseline"`
+	ZScore   float64   `json:"z_score"`
+	At       time.Time `json:"detected_at"`
+	Severity string    `json:"severity,omitempty"` // LLM分级结果
+}
+
+// AnomalyStats 异常汇总
+type AnomalyStats struct {
+	Total    int            `json:"total"`
+	ByMetric map[string]int `json:"by_metric,omitempty"`
+	Top      []AnomalyBrief `json:"top,omitempty"`
+}
+
+// EventStats Warning事件汇总
+type EventStats struct {
+	Warnings   int            `json:"warnings"`
+	TopReasons map[string]int `json:"top_reasons,omitempty"`
+}
+
+// Stats 一天的汇总数据
+type Stats struct {
+	Snapshots int           `json:"snapshots"` // 指标采集次数
+	Nodes     []NodeStats   `json:"nodes"`
+	Pods      PodSummary    `json:"pods"`
+	Network   NetworkStats  `json:"network"`
+	UAVs      []UAVStats    `json:"uavs"`
+	Incidents IncidentStats `json:"incidents"`
+	Anomalies AnomalyStats  `json:"anomalies"`
+	Events    EventStats    `json:"events"`
+	Gaps      []string      `json:"gaps,omitempty"` // 不可用的数据来源
+}
+
+// series 一个实体各指标的记录（原始样本，或较长时间范围内的降采样窗口）
+type series map[string][]metrics.RollupValue
+
+func (s series) add(values map[string]metrics.RollupValue) {
+	for name, value := range values {
+		s[name] = append(s[name], value)
+	}
+}
+
+// mean 按原始样本计算的平均值
+func (s series) mean(name string) float64 {
+	sum, count := 0.0, 0
+	for _, value := range s[name] {
+		sum += value.Sum
+		count += value.Count
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// peak 最大值
+func (s series) peak(name string) float64 {
+	result := 0.0
+	for i, value := range s[name] {
+		if i == 0 || value.Max > result {
+			result = value.Max
+		}
+	}
+	return result
+}
+
+// count 原始样本数
+func (s series) count(name string) int {
+	count := 0
+	for _, value := range s[name] {
+		count += value.Count
+	}
+	return count
+}
+
+// collect 汇总 [from, to) 内的指标样本、UAV遥测、故障、异常和事件
+func (g *Generator) collect(ctx context.Context, from, to time.Time) *Stats {
+	stats := &Stats{Nodes: []NodeStats{}, UAVs: []UAVStats{}}
+	if err := g.sampleStats(ctx, stats, from, to); err != nil {
+		stats.Gaps = append(stats.Gaps, "metrics: "+err.Error())
+	}
+	if err := g.uavStats(ctx, stats, from, to); err != nil {
+		stats.Gaps = append(stats.Gaps, "uav telemetry: "+err.Error())
+	}
+
+	if g.incidents == nil {
+		stats.Gaps = append(stats.Gaps, "incidents: correlation disabled")
+	} else if err := g.incidentStats(ctx, stats, from, to); err != nil {
+		stats.Gaps = append(stats.Gaps, "incidents: "+err.Error())
+	}
+
+	if g.sources.Metrics == nil || g.sources.Metrics.Anomalies() == nil {
+		stats.Gaps = append(stats.Gaps, "anomalies: detection disabled")
+	} else if err := anomalyStats(ctx, g.sources.Metrics.Anomalies(), stats, from, to); err != nil {
+		stats.Gaps = append(stats.Gaps, "anomalies: "+err.Error())
+	}
+
+	if g.sources.Events == nil {
+		stats.Gaps = append(stats.Gaps, "events: no event history")
+	} else if err := eventStats(ctx, g.sources.Events, stats, from, to); err != nil {
+		stats.Gaps = append(stats.Gaps, "events: "+err.Error())
+	}
+	return stats
+}
+
+// sampleStats 节点、Pod和Pod间网络的样本
+func (g *Generator) sampleStats(ctx context.Context, stats *Stats, from, to time.Time) error {
+	points, _, err := metrics.ReadHistory(ctx, g.store, "", "", "", from, to)
+	if err != nil {
+		return err
+	}
+
+	entities := make(map[string]series)
+	snapshots := make(map[int64]int) // 采集时间（或窗口） -> 采集次数
+	for _, point := range points {
+		s, ok := entities[point.Key]
+		if !ok {
+			s = make(series)
+			entities[point.Key] = s
+		}
+		s.add(point.Values)
+		if strings.HasPrefix(point.Key, metrics.TargetNode+"/") {
+			slot := point.Timestamp.Truncate(time.Second).Unix()
+			if n := point.Values["cpu_usage_rate"].Count; n > snapshots[slot] {
+				snapshots[slot] = n
+			}
+		}
+	}
+	for _, n := range snapshots {
+		stats.Snapshots += n
+	}
+
+	var rtts []float64
+	for key, s := range entities {
+		target, name, _ := strings.Cut(key, "/")
+		switch target {
+		case metrics.TargetNode:
+			stats.Nodes = append(stats.Nodes, NodeStats{
+				Name:      name,
+				Samples:   s.count("cpu_usage_rate"),
+				CPUAvg:    round(s.mean("cpu_usage_rate")),
+				CPUMax:    round(s.peak("cpu_usage_rate")),
+				MemoryAvg: round(s.mean("memory_usage_rate")),
+				MemoryMax: round(s.peak("memory_usage_rate")),
+				DiskMax:   round(s.peak("disk_usage_rate")),
+			})
+		case metrics.TargetPod:
+			restarts := 0
+			if len(s["restarts"]) > 0 {
+				// 重启次数是累计值，Pod重建后会归零，只累加增加的部分（降采样窗口取首尾的值）
+				values := make([]float64, 0, 2*len(s["restarts"]))
+				for _, value := range s["restarts"] {
+					values = append(values, value.First, value.Last)
+				}
+				for i := 1; i < len(values); i++ {
+					if values[i] > values[i-1] {
+						restarts += int(values[i] - values[i-1])
+					}
+				}
+			}
+			stats.Pods.Count++
+			stats.Pods.Restarts += restarts
+			if restarts > 0 {
+				stats.Pods.Restarted++
+			}
+			stats.Pods.Top = append(stats.Pods.Top, PodStats{
+				Name:      name,
+				Restarts:  restarts,
+				CPUMax:    round(s.peak("cpu_usage_rate")),
+				MemoryMax: round(s.peak("memory_usage_rate")),
+			})
+		case metrics.TargetPair:
+			pair := PairStats{Pair: name, Tests: s.count("connected")}
+			var connected, peaks []float64
+			for i, ok := range s["connected"] {
+				// connected 为0/1，窗口内的和即连通的次数
+				pair.Failures += ok.Count - int(math.Round(ok.Sum))
+				if ok.Sum > 0 && i < len(s["rtt_ms"]) {
+					connected = append(connected, s["rtt_ms"][i].Avg())
+					peaks = append(peaks, s["rtt_ms"][i].Max)
+				}
+			}
+			pair.RTTAvg, pair.RTTMax = round(avg(connected)), round(maxOf(peaks))
+			rtts = append(rtts, connected...)
+			stats.Network.Pairs++
+			stats.Network.Tests += pair.Tests
+			stats.Network.Failures += pair.Failures
+			stats.Network.Worst = append(stats.Network.Worst, pair)
+		}
+	}
+
+	sort.Slice(stats.Nodes, func(i, j int) bool { return stats.Nodes[i].Name < stats.Nodes[j].Name })
+
+	top := stats.Pods.Top
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Restarts != top[j].Restarts {
+			return top[i].Restarts > top[j].Restarts
+		}
+		return math.Max(top[i].CPUMax, top[i].MemoryMax) > math.Max(top[j].CPUMax, top[j].MemoryMax)
+	})
+	if len(top) > maxTopPods {
+		top = top[:maxTopPods]
+	}
+	stats.Pods.Top = top
+
+	worst := stats.Network.Worst
+	sort.Slice(worst, func(i, j int) bool {
+		if worst[i].Failures != worst[j].Failures {
+			return worst[i].Failures > worst[j].Failures
+		}
+		return worst[i].RTTAvg > worst[j].RTTAvg
+	})
+	if len(worst) > maxWorstPairs {
+		worst = worst[:maxWorstPairs]
+	}
+	stats.Network.Worst = worst
+	stats.Network.RTTAvg = round(avg(rtts))
+	stats.Network.RTTP95 = round(percentile(rtts, 95))
+	return nil
+}
+
+// uavStats 各UAV的遥测
+func (g *Generator) uavStats(ctx context.Context, stats *Stats, from, to time.Time) error {
+	records, err := g.store.List(ctx, telemetry.UAVCollection, storage.Query{From: from, To: to})
+	if err != nil {
+		return err
+	}
+
+	byNode := make(map[string][]*models.UAVTelemetryPoint)
+	for _, record := range records {
+		var point models.UAVTelemetryPoint
+		if err := json.Unmarshal(record.Data, &point); err != nil {
+			continue
+		}
+		node := point.NodeName
+		if node == "" {
+			node = record.Key
+		}
+		byNode[node] = append(byNode[node], &point)
+	}
+
+	for node, points := range byNode {
+		sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+		last := points[len(points)-1]
+		uav := UAVStats{
+			Node:        node,
+			UAVID:       last.UAVID,
+			Reports:     len(points),
+			BatteryMin:  points[0].BatteryPercent,
+			BatteryLast: last.BatteryPercent,
+			FlightModes: []string{},
+		}
+		var armed time.Duration
+		seen := make(map[string]bool)
+		for i, p := range points {
+			uav.BatteryMin = math.Min(uav.BatteryMin, p.BatteryPercent)
+			if p.FlightMode != "" && !seen[p.FlightMode] {
+				seen[p.FlightMode] = true
+				uav.FlightModes = append(uav.FlightModes, p.FlightMode)
+			}
+			if i > 0 {
+				if p.FlightMode != points[i-1].FlightMode {
+					uav.ModeChanges++
+				}
+				if points[i-1].Armed {
+					armed += p.Timestamp.Sub(points[i-1].Timestamp)
+				}
+			}
+			switch strings.ToUpper(p.SystemStatus) {
+			case "WARNING", "CRITICAL", "ERROR":
+				uav.Warnings++
+			}
+		}
+		if armed > 0 {
+			uav.ArmedTime = armed.Round(time.Minute).String()
+		}
+		stats.UAVs = append(stats.UAVs, uav)
+	}
+	sort.Slice(stats.UAVs, func(i, j int) bool { return stats.UAVs[i].Node < stats.UAVs[j].Node })
+	return nil
+}
+
+// incidentStats 当天开始或仍在持续的故障
+func (g *Generator) incidentStats(ctx context.Context, stats *Stats, from, to time.Time) error {
+	list, err := g.incidents.List(ctx, incidents.Filter{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	stats.Incidents.Total = len(list)
+	stats.Incidents.Categories = make(map[string]int)
+	for _, incident := range list {
+		if incident.Severity == "critical" {
+			stats.Incidents.Critical++
+		}
+		for category := range incident.Categories {
+			stats.Incidents.Categories[category]++
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Severity == "critical") != (list[j].Severity == "critical") {
+			return list[i].Severity == "critical"
+		}
+		return list[i].EventCount > list[j].EventCount
+	})
+	for _, incident := range list {
+		if len(stats.Incidents.Items) >= maxTopItems {
+			break
+		}
+		end := incident.LastSeen
+		if incident.ResolvedAt != nil {
+			end = *incident.ResolvedAt
+		}
+		stats.Incidents.Items = append(stats.Incidents.Items, IncidentBrief{
+			ID:        incident.ID,
+			Title:     incident.Title,
+			Severity:  incident.Severity,
+			Status:    incident.Status,
+			Events:    incident.EventCount,
+			StartedAt: incident.StartedAt,
+			Duration:  end.Sub(incident.StartedAt).Round(time.Minute).String(),
+		})
+	}
+	return nil
+}
+
+// anomalyStats 当天检测到的异常，按z分数取前几个
+func anomalyStats(ctx context.Context, detector *metrics.AnomalyDetector, stats *Stats, from, to time.Time) error {
+	list, err := detector.List(ctx, metrics.AnomalyFilter{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	stats.Anomalies.Total = len(list)
+	stats.Anomalies.ByMetric = make(map[string]int)
+	for _, a := range list {
+		stats.Anomalies.ByMetric[a.Metric]++
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ZScore > list[j].ZScore })
+	for _, a := range list {
+		if len(stats.Anomalies.Top) >= maxTopItems {
+			break
+		}
+		brief := AnomalyBrief{
+			Target:   a.Target,
+			Key:      a.Key,
+			Metric:   a.Metric,
+			Peak:     a.Peak,
+			Baseline: a.Baseline,
+			ZScore:   a.ZScore,
+			At:       a.DetectedAt,
+		}
+		if a.Triage != nil {
+			brief.Severity = a.Triage.Severity
+		}
+		stats.Anomalies.Top = append(stats.Anomalies.Top, brief)
+	}
+	return nil
+}
+
+// eventStats Warning事件数和最常见的原因
+func eventStats(ctx context.Context, history *events.History, stats *Stats, from, to time.Time) error {
+	list, err := history.Query(ctx, events.Query{From: from, To: to, Type: "Warning"})
+	if err != nil {
+		return err
+	}
+	stats.Events.Warnings = len(list)
+
+	counts := make(map[string]int)
+	for _, event := range list {
+		counts[event.Reason]++
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	if len(reasons) > 0 {
+		stats.Events.TopReasons = make(map[string]int)
+	}
+	for i, reason := range reasons {
+		if i >= maxTopItems {
+			break
+		}
+		stats.Events.TopReasons[reason] = counts[reason]
+	}
+	return nil
+}
+
+func avg(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func maxOf(values []float64) float64 {
+	result := 0.0
+	for i, v := range values {
+		if i == 0 || v > result {
+			result = v
+		}
+	}
+	return result
+}
+
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
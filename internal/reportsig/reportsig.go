@@ -0,0 +1,231 @@
+package reportsig
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+)
+
+// 签名相关的请求头
+const (
+	HeaderSignature = "X-UAV-Signature" // v1=<hex(HMAC-SHA256)>
+	HeaderTimestamp = "X-UAV-Timestamp" // Unix秒
+	HeaderNode      = "X-UAV-Node"      // 签名所用密钥对应的节点
+)
+
+// signatureVersion 签名格式版本
+const signatureVersion = "v1"
+
+// CodeInvalidSignature 签名校验失败的错误码
+const CodeInvalidSignature = "invalid_signature"
+
+// defaultMaxSkew 允许的时间偏差，超出窗口的签名视为过期
+const defaultMaxSkew = 5 * time.Minute
+
+// 签名校验错误
+var (
+	ErrMissingSignature = errors.New("report signature missing")
+	ErrUnknownNode      = errors.New("no signing key for node")
+	ErrStaleSignature   = errors.New("report timestamp outside allowed window")
+	ErrBadSignature     = errors.New("report signature mismatch")
+	ErrReplayed         = errors.New("report signature already used")
+)
+
+// DeriveKey 由主密钥派生节点密钥：hex(HMAC-SHA256(master, node))。
+// 等价于 printf %s <node> | openssl dgst -sha256 -hmac <master>
+func DeriveKey(master, node string) string {
+	mac := hmac.New(sha256.New, []byte(master))
+	mac.Write([]byte(node))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signature 计算签名：HMAC-SHA256(key, "v1:<timestamp>:<node>:<body>")
+func signature(key, node string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%s:%d:%s:", signatureVersion, timestamp, node)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Sign 为上报请求设置签名头，body必须与实际发送的请求体一致
+func Sign(req *http.Request, key, node string, body []byte) {
+	timestamp := time.Now().Unix()
+	req.Header.Set(HeaderNode, node)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, signatureVersion+"="+hex.EncodeToString(signature(key, node, timestamp, body)))
+}
+
+// VerifierOptions 签名校验参数
+type VerifierOptions struct {
+	MasterKey          string            // 主密钥，节点密钥由 DeriveKey 派生
+	PreviousMasterKeys []string          // 轮换前的主密钥，Agent全部切换到新密钥之前仍接受其派生密钥的签名
+	Keys               map[string]string // 显式指定的节点密钥（优先于派生密钥）
+	Required           bool              // 为false时放行未签名的上报（便于逐步迁移），但签名错误的上报始终拒绝
+	MaxSkew            time.Duration
+}
+
+// Verifier 校验UAV上报的HMAC签名。
+// 时间窗口内已使用过的签名会被拒绝（防止重放）；记录只保存在本进程中，
+// 多副本部署时同一个签名请求在窗口内重放到其他副本仍会被接受
+type Verifier struct {
+	opts   VerifierOptions
+	logger *logrus.Entry
+
+	seen map[string]time.Time // 已使用的签名 -> 过期时间
+	mu   sync.Mutex
+}
+
+// NewVerifier 创建签名校验器
+func NewVerifier(opts VerifierOptions, logger *logrus.Entry) (*Verifier, error) {
+	if opts.MasterKey == "" && len(opts.PreviousMasterKeys) == 0 && len(opts.Keys) == 0 {
+		return nil, fmt.Errorf("report signing requires a master key or per-node keys")
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = defaultMaxSkew
+	}
+	if logger == nil {
+		logger = logging.For("reportsig")
+	}
+	return &Verifier{opts: opts, logger: logger, seen: make(map[string]time.Time)}, nil
+}
+
+// keysFor 返回节点可用的签名密钥：显式指定的密钥优先，否则为当前和轮换前主密钥的派生密钥
+func (v *Verifier) keysFor(node string) []string {
+	if key, ok := v.opts.Keys[node]; ok && key != "" {
+		return []string{key}
+	}
+	var keys []string
+	for _, master := range append([]string{v.opts.MasterKey}, v.opts.PreviousMasterKeys...) {
+		if master != "" {
+			keys = append(keys, DeriveKey(master, node))
+		}
+	}
+	return keys
+}
+
+// markUsed 记录已使用的签名，签名在窗口内已使用过时返回false
+func (v *Verifier) markUsed(sig []byte, timestamp int64) bool {
+	now := time.Now()
+	id := hex.EncodeToString(sig)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for seen, expires := range v.seen {
+		if now.After(expires) {
+			delete(v.seen, seen)
+		}
+	}
+	if _, ok := v.seen[id]; ok {
+		return false
+	}
+	// 时间戳超出窗口后签名本身就会被拒绝，无需继续记录
+	v.seen[id] = time.Unix(timestamp, 0).Add(v.opts.MaxSkew)
+	return true
+}
+
+// Verify 校验请求头中的签名
+func (v *Verifier) Verify(header http.Header, body []byte) (string, error) {
+	node := strings.TrimSpace(header.Get(HeaderNode))
+	value := header.Get(HeaderSignature)
+	if node == "" || value == "" {
+		return "", ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return node, fmt.Errorf("%w: invalid timestamp", ErrStaleSignature)
+	}
+	if skew := time.Since(time.Unix(timestamp, 0)); skew > v.opts.MaxSkew || skew < -v.opts.MaxSkew {
+		return node, ErrStaleSignature
+	}
+
+	keys := v.keysFor(node)
+	if len(keys) == 0 {
+		return node, ErrUnknownNode
+	}
+
+	provided, err := hex.DecodeString(strings.TrimPrefix(value, signatureVersion+"="))
+	if err != nil || !strings.HasPrefix(value, signatureVersion+"=") {
+		return node, ErrBadSignature
+	}
+	for _, key := range keys {
+		if hmac.Equal(provided, signature(key, node, timestamp, body)) {
+			if !v.markUsed(provided, timestamp) {
+				return node, ErrReplayed
+			}
+			return node, nil
+		}
+	}
+	return node, ErrBadSignature
+}
+
+// nodeContextKey 校验通过的节点名在请求上下文中的键
+type nodeContextKey struct{}
+
+// SignedNode 返回签名校验通过的节点名；未签名（且允许）时返回false
+func SignedNode(ctx context.Context) (string, bool) {
+	node, ok := ctx.Value(nodeContextKey{}).(string)
+	return node, ok
+}
+
+// Middleware 校验请求体签名后再交给下一个处理函数；
+// 校验通过的节点名写入请求上下文，处理函数应确认其与上报内容中的节点一致
+func (v *Verifier) Middleware(maxBody int64, next http.HandlerFunc) http.HandlerFunc {
+	if maxBody <= 0 {
+		maxBody = httpjson.DefaultMaxBodyBytes
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+
+		if r.Header.Get(HeaderSignature) == "" && !v.opts.Required {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				httpjson.WriteError(w, &httpjson.Error{
+					Status:  http.StatusRequestEntityTooLarge,
+					Code:    httpjson.CodeBodyTooLarge,
+					Message: fmt.Sprintf("request body must not exceed %d bytes", maxBody),
+				})
+				return
+			}
+			httpjson.WriteError(w, httpjson.BadRequest("failed to read request body: %v", err))
+			return
+		}
+
+		node, err := v.Verify(r.Header, body)
+		if err != nil {
+			v.logger.Warnf("Rejected UAV report from %s (node %q): %v", r.RemoteAddr, node, err)
+			httpjson.WriteError(w, &httpjson.Error{
+				Status:  http.StatusUnauthorized,
+				Code:    CodeInvalidSignature,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r.WithContext(context.WithValue(r.Context(), nodeContextKey{}, node)))
+	}
+}
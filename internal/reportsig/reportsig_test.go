@@ -0,0 +1,247 @@
+package reportsig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testMaster = "master-secret"
+
+func newTestVerifier(t *testing.T, opts VerifierOptions) *Verifier {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	v, err := NewVerifier(opts, logrus.NewEntry(logger))
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	return v
+}
+
+// signedHeader 构造指定时间戳的签名头
+func signedHeader(key, node string, timestamp time.Time, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(HeaderNode, node)
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(HeaderSignature, signatureVersion+"="+hex.EncodeToString(signature(key, node, timestamp.Unix(), body)))
+	return header
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"node_name":"edge-1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/uav/report", bytes.NewReader(body))
+	Sign(req, DeriveKey(testMaster, "edge-1"), "edge-1", body)
+
+	v := newTestVerifier(t, VerifierOptions{MasterKey: testMaster})
+	node, err := v.Verify(req.Header, body)
+	if err != nil || node != "edge-1" {
+		t.Errorf("Verify() = %q, %v, want edge-1", node, err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	body := []byte(`{"node_name":"edge-1","battery":80}`)
+	key := DeriveKey(testMaster, "edge-1")
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		opts   VerifierOptions
+		want   error
+	}{
+		{name: "within skew in the past", header: signedHeader(key, "edge-1", now.Add(-5*time.Minute+2*time.Second), body)},
+		{name: "within skew in the future", header: signedHeader(key, "edge-1", now.Add(5*time.Minute-2*time.Second), body)},
+		{name: "past skew window", header: signedHeader(key, "edge-1", now.Add(-5*time.Minute-2*time.Second), body), want: ErrStaleSignature},
+		{name: "future skew window", header: signedHeader(key, "edge-1", now.Add(5*time.Minute+2*time.Second), body), want: ErrStaleSignature},
+		{name: "custom skew", header: signedHeader(key, "edge-1", now.Add(-time.Minute), body), opts: VerifierOptions{MasterKey: testMaster, MaxSkew: 30 * time.Second}, want: ErrStaleSignature},
+		{name: "tampered body", header: signedHeader(key, "edge-1", now, body), body: []byte(`{"node_name":"edge-1","battery":10}`), want: ErrBadSignature},
+		{name: "other node's key", header: signedHeader(DeriveKey(testMaster, "edge-2"), "edge-1", now, body), want: ErrBadSignature},
+		{name: "other master key", header: signedHeader(DeriveKey("other-master", "edge-1"), "edge-1", now, body), want: ErrBadSignature},
+		{
+			name:   "missing signature",
+			header: http.Header{HeaderNode: []string{"edge-1"}, HeaderTimestamp: []string{strconv.FormatInt(now.Unix(), 10)}},
+			want:   ErrMissingSignature,
+		},
+		{
+			name: "missing node",
+			header: func() http.Header {
+				h := signedHeader(key, "edge-1", now, body)
+				h.Del(HeaderNode)
+				return h
+			}(),
+			want: ErrMissingSignature,
+		},
+		{
+			name: "invalid timestamp",
+			header: func() http.Header {
+				h := signedHeader(key, "edge-1", now, body)
+				h.Set(HeaderTimestamp, "yesterday")
+				return h
+			}(),
+			want: ErrStaleSignature,
+		},
+		{
+			name: "unknown signature version",
+			header: func() http.Header {
+				h := signedHeader(key, "edge-1", now, body)
+				h.Set(HeaderSignature, "v2="+strings.TrimPrefix(h.Get(HeaderSignature), "v1="))
+				return h
+			}(),
+			want: ErrBadSignature,
+		},
+		{name: "node without explicit key", header: signedHeader("k", "edge-1", now, body), opts: VerifierOptions{Keys: map[string]string{"edge-2": "k"}}, want: ErrUnknownNode},
+		{name: "explicit key", header: signedHeader("k", "edge-1", now, body), opts: VerifierOptions{MasterKey: testMaster, Keys: map[string]string{"edge-1": "k"}}},
+		{name: "explicit key overrides derived key", header: signedHeader(key, "edge-1", now, body), opts: VerifierOptions{MasterKey: testMaster, Keys: map[string]string{"edge-1": "k"}}, want: ErrBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.MasterKey == "" && len(tt.opts.Keys) == 0 {
+				tt.opts.MasterKey = testMaster
+			}
+			if tt.body == nil {
+				tt.body = body
+			}
+			v := newTestVerifier(t, tt.opts)
+			if _, err := v.Verify(tt.header, tt.body); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// 轮换期间新旧主密钥派生的签名都被接受，旧密钥移除后拒绝
+func TestVerifyMasterKeyRotation(t *testing.T) {
+	body := []byte(`{}`)
+	oldSigned := signedHeader(DeriveKey("old-master", "edge-1"), "edge-1", time.Now(), body)
+	newSigned := signedHeader(DeriveKey("new-master", "edge-1"), "edge-1", time.Now(), body)
+
+	rotating := newTestVerifier(t, VerifierOptions{MasterKey: "new-master", PreviousMasterKeys: []string{"old-master"}})
+	if _, err := rotating.Verify(oldSigned, body); err != nil {
+		t.Errorf("Verify() with the previous master key error = %v", err)
+	}
+	if _, err := rotating.Verify(newSigned, body); err != nil {
+		t.Errorf("Verify() with the new master key error = %v", err)
+	}
+
+	rotated := newTestVerifier(t, VerifierOptions{MasterKey: "new-master"})
+	if _, err := rotated.Verify(oldSigned, body); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() with a removed master key error = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	body := []byte(`{"node_name":"edge-1"}`)
+	header := signedHeader(DeriveKey(testMaster, "edge-1"), "edge-1", time.Now(), body)
+	v := newTestVerifier(t, VerifierOptions{MasterKey: testMaster})
+
+	if _, err := v.Verify(header, body); err != nil {
+		t.Fatalf("first Verify() error = %v", err)
+	}
+	if _, err := v.Verify(header, body); !errors.Is(err, ErrReplayed) {
+		t.Errorf("replayed Verify() error = %v, want ErrReplayed", err)
+	}
+
+	// 签名错误的请求不占用记录
+	forged := signedHeader("wrong", "edge-1", time.Now(), body)
+	v.Verify(forged, body)
+	if len(v.seen) != 1 {
+		t.Errorf("%d signatures recorded, want 1", len(v.seen))
+	}
+
+	// 过期的记录被清理
+	v.seen["stale"] = time.Now().Add(-time.Second)
+	v.Verify(signedHeader(DeriveKey(testMaster, "edge-1"), "edge-1", time.Now(), []byte(`{"n":2}`)), []byte(`{"n":2}`))
+	if _, ok := v.seen["stale"]; ok {
+		t.Error("expired signature was not pruned")
+	}
+}
+
+// errReader 读取失败的请求体
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestMiddleware(t *testing.T) {
+	body := []byte(`{"node_name":"edge-1"}`)
+	key := DeriveKey(testMaster, "edge-1")
+
+	tests := []struct {
+		name       string
+		required   bool
+		method     string
+		body       io.Reader
+		sign       bool
+		wantStatus int
+		wantCode   string
+		wantNode   string // 下游处理函数从上下文中取得的签名节点
+	}{
+		{name: "signed", required: true, sign: true, wantStatus: http.StatusOK, wantNode: "edge-1"},
+		{name: "unsigned when required", required: true, wantStatus: http.StatusUnauthorized, wantCode: CodeInvalidSignature},
+		{name: "unsigned when optional", wantStatus: http.StatusOK},
+		{name: "get is not checked", required: true, method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "body too large", required: true, sign: true, body: strings.NewReader(strings.Repeat("x", 2048)), wantStatus: http.StatusRequestEntityTooLarge, wantCode: "body_too_large"},
+		{name: "body read error", required: true, sign: true, body: errReader{}, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, VerifierOptions{MasterKey: testMaster, Required: tt.required})
+			var gotNode string
+			var gotBody []byte
+			handler := v.Middleware(1024, func(w http.ResponseWriter, r *http.Request) {
+				gotNode, _ = SignedNode(r.Context())
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			reader := tt.body
+			if reader == nil {
+				reader = bytes.NewReader(body)
+			}
+			req := httptest.NewRequest(method, "/api/v1/uav/report", reader)
+			if tt.sign {
+				Sign(req, key, "edge-1", body)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode != "" {
+				var resp struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error.Code != tt.wantCode {
+					t.Errorf("error code = %q, want %q (%s)", resp.Error.Code, tt.wantCode, rec.Body.String())
+				}
+				return
+			}
+			if gotNode != tt.wantNode {
+				t.Errorf("SignedNode() = %q, want %q", gotNode, tt.wantNode)
+			}
+			if method == http.MethodPost && string(gotBody) != string(body) {
+				t.Errorf("handler read body %q, want %q", gotBody, body)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
 	"github.com/yourusername/k8s-llm-monitor/pkg/models"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,7 +35,7 @@ var (
 
 // Controller 简单调度器控制器
 type Controller struct {
-	logger     *logrus.Logger
+	logger     *logrus.Entry
 	dynamic    dynamic.Interface
 	kubeClient *kubernetes.Clientset
 	k8sClient  *k8s.Client
@@ -48,8 +49,7 @@ type Config struct {
 
 // NewController 构造控制器
 func NewController(dynamic dynamic.Interface, kubeClient *kubernetes.Clientset, k8sClient *k8s.Client, cfg Config) *Controller {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
+	logger := logging.For("scheduler")
 
 	if cfg.Interval == 0 {
 		cfg.Interval = 10 * time.Second
@@ -178,18 +178,21 @@ func (c *Controller) buildCandidates(spec models.SchedulingRequestSpec, uavList
 	}
 
 	var candidates []models.SchedulingCandidate
-	for _, item := range uavList.Items {
-		uavSpec, _, _ := unstructured.NestedMap(item.Object, "spec")
-		uavStatus, _, _ := unstructured.NestedMap(item.Object, "status")
-
-		nodeName, _ := uavSpec["node_name"].(string)
-		uavID, _ := uavSpec["uav_id"].(string)
-		battery := readFloat(uavSpec, "battery", "remaining_percent")
-		collectionStatus := strings.ToLower(readString(uavStatus, "collection_status"))
-
-		if nodeName == "" {
+	for i := range uavList.Items {
+		entry, err := k8s.UAVMetricsEntryFromCRD(&uavList.Items[i])
+		if err != nil {
+			c.logger.Warnf("Skipping UAVMetric: %v", err)
 			continue
 		}
+		if entry.NodeName == "" {
+			continue
+		}
+
+		var battery float64
+		if entry.State != nil {
+			battery = entry.State.Battery.RemainingPercent
+		}
+		collectionStatus := strings.ToLower(entry.Status)
 
 		if spec.MinBatteryPercent > 0 && battery < spec.MinBatteryPercent {
 			continue
@@ -199,19 +202,16 @@ func (c *Controller) buildCandidates(spec models.SchedulingRequestSpec, uavList
 			continue
 		}
 
-		heartbeatStr := readString(uavStatus, "last_update")
-		heartbeat, _ := time.Parse(time.RFC3339, heartbeatStr)
-
 		score := battery
-		if _, ok := preferredSet[strings.ToLower(nodeName)]; ok {
+		if _, ok := preferredSet[strings.ToLower(entry.NodeName)]; ok {
 			score += 10
 		}
 
 		candidate := models.SchedulingCandidate{
-			NodeName:      nodeName,
-			UAVID:         uavID,
+			NodeName:      entry.NodeName,
+			UAVID:         entry.UAVID,
 			Battery:       battery,
-			LastHeartbeat: heartbeat,
+			LastHeartbeat: entry.LastHeartbeat,
 			Score:         score,
 		}
 		candidates = append(candidates, candidate)
@@ -248,41 +248,3 @@ func (c *Controller) updateStatus(ctx context.Context, req *unstructured.Unstruc
 		UpdateStatus(ctx, req, metav1.UpdateOptions{})
 	return err
 }
-
-func readFloat(m map[string]interface{}, fields ...string) float64 {
-	current := interface{}(m)
-	for _, field := range fields {
-		if mp, ok := current.(map[string]interface{}); ok {
-			current = mp[field]
-		} else {
-			return 0
-		}
-	}
-
-	switch v := current.(type) {
-	case float64:
-		return v
-	case int:
-		return float64(v)
-	case int64:
-		return float64(v)
-	default:
-		return 0
-	}
-}
-
-func readString(m map[string]interface{}, fields ...string) string {
-	current := interface{}(m)
-	for _, field := range fields {
-		if mp, ok := current.(map[string]interface{}); ok {
-			current = mp[field]
-		} else {
-			return ""
-		}
-	}
-
-	if s, ok := current.(string); ok {
-		return s
-	}
-	return ""
-}
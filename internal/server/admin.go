@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// maxRestoreSize 恢复请求体的最大大小
+const maxRestoreSize = 512 << 20
+
+// requireAdmin 管理接口鉴权：要求与 server.admin_token 一致的 Bearer Token。
+// 未配置 server.admin_token 时管理接口关闭，所有请求返回403
+func requireAdmin(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			httpjson.WriteError(w, &httpjson.Error{
+				Status:  http.StatusForbidden,
+				Code:    "admin_disabled",
+				Message: "admin endpoints are disabled: server.admin_token is not configured",
+			})
+			return
+		}
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// backupHandler 导出存储数据和（脱敏后的）配置
+func backupHandler(store storage.Store, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filename := fmt.Sprintf("k8s-llm-monitor-backup-%s.jsonl.gz", time.Now().UTC().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		meta := map[string]interface{}{
+			"storage_type": cfg.Storage.Type,
+			"config":       cfg.Redacted(),
+		}
+		// 加密的数据以密文导出，恢复的实例需要配置同一密钥（或放入 previous_keys）
+		if health, ok := store.(storage.HealthReporter); ok && health.Health().Encrypted {
+			meta["encryption_key_id"] = health.Health().KeyID
+		}
+
+		summary, err := storage.Backup(r.Context(), store, w, meta)
+		if err != nil {
+			// 响应可能已部分写出，只能记录日志
+			log.Printf("Backup failed: %v", err)
+			return
+		}
+		log.Printf("Backup completed: %d keys, %d records", summary.Keys, summary.Records)
+	}
+}
+
+// configDumpHandler 导出当前生效的配置（敏感字段已脱敏）
+func configDumpHandler(watcher *config.Watcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      watcher.Current().Redacted(),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// restoreHandler 从备份文件导入数据
+func restoreHandler(store storage.Store, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+		case "application/gzip", "application/x-gzip", "application/octet-stream":
+		default:
+			httpjson.WriteError(w, &httpjson.Error{
+				Status:  http.StatusUnsupportedMediaType,
+				Code:    httpjson.CodeUnsupportedMediaType,
+				Message: "Content-Type must be application/gzip or application/octet-stream",
+			})
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxRestoreSize)
+		defer body.Close()
+
+		summary, err := storage.Restore(r.Context(), store, body)
+		details := map[string]interface{}{}
+		if summary != nil {
+			details["keys"], details["records"] = summary.Keys, summary.Records
+		}
+		auditLog.LogRequest(r, audit.ActionRestore, "storage", details, err)
+		if err != nil {
+			log.Printf("Restore failed: %v", err)
+			status := http.StatusBadRequest
+			if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "error",
+				"error":     err.Error(),
+				"data":      summary,
+				"timestamp": time.Now().UTC(),
+			})
+			return
+		}
+
+		log.Printf("Restore completed: %d keys, %d records", summary.Keys, summary.Records)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      summary,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
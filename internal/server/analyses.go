@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+)
+
+// analysesHandler 分析结果列表处理函数
+func analysesHandler(results *analysis.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit, err := parseLimitParam(r, 100)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		records, err := results.List(r.Context(), analysis.Filter{
+			Type:   query.Get("type"),
+			Status: query.Get("status"),
+			Pod:    query.Get("pod"),
+			From:   from,
+			To:     to,
+			Limit:  limit,
+		})
+		if err != nil {
+			http.Error(w, "Failed to list analyses: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      records,
+			"count":     len(records),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// analysisHandler 单个分析结果处理函数
+func analysisHandler(results *analysis.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/analyses/")
+		if id == "" {
+			http.Error(w, "Analysis id is required", http.StatusBadRequest)
+			return
+		}
+
+		record, err := results.Get(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, analysis.ErrNotFound) {
+				http.Error(w, "Analysis not found: "+id, http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to get analysis: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      record,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
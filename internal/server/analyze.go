@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxAnalysisWindow 分析事件时间窗口的上限
+const maxAnalysisWindow = 7 * 24 * time.Hour
+
+// llmWriteTimeout 调用LLM的接口的写超时（服务器默认的 WriteTimeout 不足以等待模型响应）
+const llmWriteTimeout = 2 * time.Minute
+
+// extendWriteDeadline 延长当前请求的写超时
+func extendWriteDeadline(w http.ResponseWriter, timeout time.Duration) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		log.Printf("Failed to extend write deadline: %v", err)
+	}
+}
+
+// rootCauseHandler POST /api/v1/analyze/root-cause 汇总事件、Pod指标和节点状态，由LLM给出可能的根因和修复建议
+//
+// 请求体为 models.AnalysisRequest，parameters 支持 namespace、pod（[namespace/]name）、
+// symptom（现象描述）、since（事件时间窗口，如 "30m"，默认1小时）和 profile（LLM配置名）。
+// 请求 Accept: text/event-stream（或 ?stream=true）时以SSE逐段输出模型结果，最后输出 result 事件
+func rootCauseHandler(service *llm.Service, templates *prompts.Registry, sources analysis.Sources, results *analysis.Store, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		extendWriteDeadline(w, llmWriteTimeout)
+
+		var request models.AnalysisRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if request.Type != "" && request.Type != analysis.TypeRootCause {
+			httpjson.WriteError(w, httpjson.BadRequest("type must be %q", analysis.TypeRootCause))
+			return
+		}
+
+		rcRequest, err := parseRootCauseParams(request.Parameters)
+		if err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		var stream *sseWriter
+		if wantsStream(r) {
+			stream = newSSEWriter(w)
+			rcRequest.Stream = stream.Token
+		}
+
+		result, err := analysis.RootCause(r.Context(), service, templates, sources, rcRequest)
+		if err != nil {
+			if stream != nil {
+				stream.Error(err)
+			} else {
+				writeLLMError(w, err)
+			}
+			return
+		}
+
+		var pods []string
+		if pod := rcRequest.Scope.Pod; pod != "" {
+			if rcRequest.Scope.Namespace != "" {
+				pod = rcRequest.Scope.Namespace + "/" + pod
+			}
+			pods = []string{pod}
+		}
+		response := models.AnalysisResponse{
+			Status:    "success",
+			Result:    resultMap(result),
+			Timestamp: time.Now().UTC(),
+		}
+		record, err := results.Save(r.Context(), analysis.TypeRootCause, "completed", result.Summary, pods, result)
+		if err != nil {
+			log.Printf("Failed to store root cause analysis: %v", err)
+		} else {
+			response.RequestID = record.ID
+		}
+
+		if stream != nil {
+			stream.Send(sseEventResult, response)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// parseRootCauseParams 解析并校验根因分析参数
+func parseRootCauseParams(params map[string]interface{}) (analysis.RootCauseRequest, error) {
+	var request analysis.RootCauseRequest
+
+	str := func(key string) (string, error) {
+		value, ok := params[key]
+		if !ok || value == nil {
+			return "", nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", httpjson.BadRequest("parameters.%s must be a string", key)
+		}
+		return strings.TrimSpace(s), nil
+	}
+
+	namespace, err := str("namespace")
+	if err != nil {
+		return request, err
+	}
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return request, httpjson.BadRequest("parameters.namespace is invalid: %s", strings.Join(errs, "; "))
+		}
+	}
+	request.Scope.Namespace = namespace
+
+	pod, err := str("pod")
+	if err != nil {
+		return request, err
+	}
+	if pod != "" {
+		if err := validatePodRef("parameters.pod", pod); err != nil {
+			return request, err
+		}
+		if ns, name, ok := strings.Cut(pod, "/"); ok {
+			if namespace != "" && namespace != ns {
+				return request, httpjson.BadRequest("parameters.pod namespace %q does not match parameters.namespace %q", ns, namespace)
+			}
+			request.Scope.Namespace, pod = ns, name
+		}
+		request.Scope.Pod = pod
+	}
+
+	since, err := str("since")
+	if err != nil {
+		return request, err
+	}
+	if since != "" {
+		window, err := time.ParseDuration(since)
+		if err != nil || window <= 0 || window > maxAnalysisWindow {
+			return request, httpjson.BadRequest("parameters.since must be a positive duration up to %s", maxAnalysisWindow)
+		}
+		request.Scope.Since = window
+	}
+
+	if request.Symptom, err = str("symptom"); err != nil {
+		return request, err
+	}
+	if request.Profile, err = str("profile"); err != nil {
+		return request, err
+	}
+	return request, nil
+}
+
+// resultMap 将分析结果转换为 AnalysisResponse.Result 使用的通用结构
+func resultMap(result interface{}) map[string]interface{} {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
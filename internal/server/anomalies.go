@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+)
+
+// errAnomaliesUnavailable 异常检测依赖指标采集，开发模式或关闭 metrics.anomalies 时不可用
+var errAnomaliesUnavailable = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "anomalies_unavailable",
+	Message: "anomaly detection not available (requires metrics collection and metrics.anomalies.enabled)",
+}
+
+// anomalyDetector 返回指标管理器的异常检测器，未启用时为空
+func anomalyDetector(manager *metrics.Manager) *metrics.AnomalyDetector {
+	if manager == nil {
+		return nil
+	}
+	return manager.Anomalies()
+}
+
+// anomaliesHandler GET /api/v1/anomalies 异常列表（按发现时间降序），参数 status、target、key、metric、from、to、limit
+func anomaliesHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		detector := anomalyDetector(manager)
+		if detector == nil {
+			httpjson.WriteError(w, errAnomaliesUnavailable)
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 100)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", metrics.AnomalyOpen, metrics.AnomalyResolved:
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("status must be %q or %q", metrics.AnomalyOpen, metrics.AnomalyResolved))
+			return
+		}
+
+		list, err := detector.List(r.Context(), metrics.AnomalyFilter{
+			Status: status,
+			Target: query.Get("target"),
+			Key:    query.Get("key"),
+			Metric: query.Get("metric"),
+			From:   from,
+			To:     to,
+			Limit:  limit,
+		})
+		if err != nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      list,
+			"count":     len(list),
+			"open":      detector.OpenCount(),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// anomalyRouter 单个异常: GET /api/v1/anomalies/{id}，POST /api/v1/anomalies/{id}/triage
+func anomalyRouter(manager *metrics.Manager, service *llm.Service, templates *prompts.Registry, sources analysis.Sources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/anomalies/"), "/"), "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.NotFound(w, r)
+			return
+		}
+		detector := anomalyDetector(manager)
+		if detector == nil {
+			httpjson.WriteError(w, errAnomaliesUnavailable)
+			return
+		}
+
+		id := parts[0]
+		if len(parts) == 1 {
+			anomalyHandler(detector, w, r, id)
+			return
+		}
+		switch parts[1] {
+		case "triage":
+			anomalyTriageHandler(detector, service, templates, sources, w, r, id)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// anomalyHandler 返回单个异常
+func anomalyHandler(detector *metrics.AnomalyDetector, w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	anomaly, err := detector.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, metrics.ErrAnomalyNotFound) {
+			writeAnomalyError(w, id, err)
+		} else {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      anomaly,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// anomalyTriageHandler 由LLM为异常分级（或重新分级），可选参数 profile 指定LLM配置
+func anomalyTriageHandler(detector *metrics.AnomalyDetector, service *llm.Service, templates *prompts.Registry, sources analysis.Sources, w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	anomaly, err := detector.Get(r.Context(), id)
+	if err != nil {
+		writeAnomalyError(w, id, err)
+		return
+	}
+
+	extendWriteDeadline(w, llmWriteTimeout)
+
+	if err := analysis.TriageAnomalies(r.Context(), service, templates, sources, []*metrics.Anomaly{anomaly}, r.URL.Query().Get("profile")); err != nil {
+		writeAnomalyError(w, id, err)
+		return
+	}
+	anomaly, err = detector.Get(r.Context(), id)
+	if err != nil {
+		writeAnomalyError(w, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      anomaly,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// writeAnomalyError 异常不存在时返回404，其余按LLM错误处理
+func writeAnomalyError(w http.ResponseWriter, id string, err error) {
+	if errors.Is(err, metrics.ErrAnomalyNotFound) {
+		httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: "anomaly not found: " + id})
+		return
+	}
+	writeLLMError(w, err)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+)
+
+// auditHandler 审计日志查询处理函数
+func auditHandler(auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit, err := parseLimitParam(r, 200)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		entries, err := auditLog.Query(r.Context(), audit.Filter{
+			Action:   query.Get("action"),
+			Resource: query.Get("resource"),
+			Actor:    query.Get("actor"),
+			Outcome:  query.Get("outcome"),
+			From:     from,
+			To:       to,
+			Limit:    limit,
+		})
+		if err != nil {
+			http.Error(w, "Failed to query audit log: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      entries,
+			"count":     len(entries),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
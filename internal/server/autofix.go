@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/autofix"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+)
+
+// errAutofixDisabled 未开启 analysis.enable_auto_fix
+var errAutofixDisabled = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "autofix_disabled",
+	Message: "autofix is disabled (requires analysis.enable_auto_fix)",
+}
+
+// autofixProposeRequest 生成修复计划的请求体
+type autofixProposeRequest struct {
+	AnalysisID string `json:"analysis_id"`
+}
+
+// autofixPlansHandler GET /api/v1/autofix/plans 计划列表（参数 status、analysis_id、from、to、limit），
+// POST 根据分析结果生成修复计划（只预演，不执行）
+func autofixPlansHandler(engine *autofix.Engine, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if engine == nil {
+			httpjson.WriteError(w, errAutofixDisabled)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var request autofixProposeRequest
+			if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			if request.AnalysisID == "" {
+				httpjson.WriteError(w, httpjson.BadRequest("analysis_id is required"))
+				return
+			}
+
+			plan, err := engine.Propose(r.Context(), request.AnalysisID)
+			if err != nil {
+				writeAutofixError(w, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"data":      plan,
+				"timestamp": time.Now().UTC(),
+			})
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 100)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", autofix.StatusProposed, autofix.StatusExecuted, autofix.StatusFailed, autofix.StatusExpired:
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("unsupported status %q", status))
+			return
+		}
+
+		list, err := engine.List(r.Context(), autofix.Filter{
+			Status:     status,
+			AnalysisID: query.Get("analysis_id"),
+			From:       from,
+			To:         to,
+			Limit:      limit,
+		})
+		if err != nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      list,
+			"count":     len(list),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// autofixPlanRouter 单个计划: GET /api/v1/autofix/plans/{id}，POST /api/v1/autofix/plans/{id}/confirm（需要管理Token）
+func autofixPlanRouter(engine *autofix.Engine, adminToken string) http.HandlerFunc {
+	confirm := requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/autofix/plans/"), "/"), "/")[0]
+		plan, err := engine.Execute(audit.WithRequest(r.Context(), r), id)
+		if err != nil {
+			writeAutofixError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      plan,
+			"timestamp": time.Now().UTC(),
+		})
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/autofix/plans/"), "/"), "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.NotFound(w, r)
+			return
+		}
+		if engine == nil {
+			httpjson.WriteError(w, errAutofixDisabled)
+			return
+		}
+
+		if len(parts) == 1 {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			plan, err := engine.Get(r.Context(), parts[0])
+			if err != nil {
+				writeAutofixError(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"data":      plan,
+				"timestamp": time.Now().UTC(),
+			})
+			return
+		}
+
+		switch parts[1] {
+		case "confirm":
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			confirm(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// writeAutofixError 将修复引擎的错误映射为HTTP状态码
+func writeAutofixError(w http.ResponseWriter, err error) {
+	apiErr := &httpjson.Error{Status: http.StatusInternalServerError, Code: "autofix_error", Message: err.Error()}
+	switch {
+	case errors.Is(err, autofix.ErrNotFound), errors.Is(err, analysis.ErrNotFound):
+		apiErr.Status, apiErr.Code = http.StatusNotFound, "not_found"
+	case errors.Is(err, autofix.ErrUnsupported):
+		apiErr.Status, apiErr.Code = http.StatusBadRequest, "unsupported_analysis"
+	case errors.Is(err, autofix.ErrNotPending), errors.Is(err, autofix.ErrNoActions):
+		apiErr.Status, apiErr.Code = http.StatusConflict, "plan_not_executable"
+	case errors.Is(err, autofix.ErrNoK8s):
+		apiErr.Status, apiErr.Code = http.StatusServiceUnavailable, "k8s_unavailable"
+	}
+	httpjson.WriteError(w, apiErr)
+}
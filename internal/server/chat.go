@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/assistant"
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+)
+
+// chatSessionRequest 创建会话的请求体
+type chatSessionRequest struct {
+	Title   string `json:"title"`
+	Profile string `json:"profile"`
+}
+
+// chatMessageRequest 会话消息的请求体
+type chatMessageRequest struct {
+	Message string `json:"message"`
+	Profile string `json:"profile"` // 为空时使用会话的配置
+}
+
+// chatSessionsHandler POST /api/v1/chat/sessions 创建多轮对话会话
+func chatSessionsHandler(sessions *assistant.Sessions, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request chatSessionRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		session, err := sessions.Create(r.Context(), request.Title, request.Profile)
+		if err != nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      session,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// chatSessionRouter 单个会话: GET /api/v1/chat/sessions/{id}，POST /api/v1/chat/sessions/{id}/messages
+func chatSessionRouter(sessions *assistant.Sessions, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/chat/sessions/"), "/"), "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.NotFound(w, r)
+			return
+		}
+
+		id := parts[0]
+		if len(parts) == 1 {
+			chatSessionHandler(sessions, w, r, id)
+			return
+		}
+		switch parts[1] {
+		case "messages":
+			chatMessageHandler(sessions, w, r, id, maxBody)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// chatSessionHandler 返回会话及保存的对话历史
+func chatSessionHandler(sessions *assistant.Sessions, w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := sessions.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, assistant.ErrSessionNotFound) {
+			writeLLMError(w, chatError(id, err))
+		} else {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      session,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// chatMessageHandler 在会话中提问，每轮注入最新的集群现状。
+// 请求 Accept: text/event-stream（或 ?stream=true）时以SSE输出模型片段和工具调用，最后输出 result 事件
+func chatMessageHandler(sessions *assistant.Sessions, w http.ResponseWriter, r *http.Request, id string, maxBody int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	extendWriteDeadline(w, llmWriteTimeout)
+
+	var request chatMessageRequest
+	if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+		httpjson.WriteError(w, err)
+		return
+	}
+	message := strings.TrimSpace(request.Message)
+	if message == "" {
+		httpjson.WriteError(w, httpjson.BadRequest("message is required"))
+		return
+	}
+	if len(message) > maxQuestionLength {
+		httpjson.WriteError(w, httpjson.BadRequest("message must be at most %d bytes", maxQuestionLength))
+		return
+	}
+
+	var stream *sseWriter
+	var observer assistant.Observer
+	if wantsStream(r) {
+		stream = newSSEWriter(w)
+		observer.Token = stream.Token
+		observer.Step = func(step assistant.Step) {
+			stream.Send(sseEventStep, step)
+		}
+	}
+
+	answer, err := sessions.Send(audit.WithRequest(r.Context(), r), id, message, request.Profile, observer)
+	if err != nil {
+		if stream != nil {
+			stream.Error(chatError(id, err))
+		} else {
+			writeLLMError(w, chatError(id, err))
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"status":     "success",
+		"session_id": id,
+		"message":    message,
+		"data":       answer,
+		"timestamp":  time.Now().UTC(),
+	}
+	if stream != nil {
+		stream.Send(sseEventResult, response)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// chatError 会话不存在时转换为404，其余按LLM错误处理
+func chatError(id string, err error) error {
+	if errors.Is(err, assistant.ErrSessionNotFound) {
+		return &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: "chat session not found: " + id}
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+)
+
+// startDebugServer 在独立端口上提供 pprof 和 expvar（仅 server.debug 开启时启动）。
+// 默认只监听 127.0.0.1，通过 kubectl port-forward 访问
+func startDebugServer(addr, adminToken string, manager *metrics.Manager) *http.Server {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+	if manager != nil {
+		expvar.Publish("metrics_collection", expvar.Func(func() interface{} {
+			return manager.Status()
+		}))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           requireAdmin(adminToken, mux.ServeHTTP),
+		ReadHeaderTimeout: 10 * time.Second,
+		// CPU profile 和 trace 按 seconds 参数持续采样，不设置写超时
+	}
+
+	go func() {
+		log.Printf("Debug server (pprof, expvar) listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Debug server stopped: %v", err)
+		}
+	}()
+	return server
+}
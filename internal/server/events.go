@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+)
+
+// eventHistoryHandler 事件历史查询处理函数
+func eventHistoryHandler(history *events.History) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if history == nil {
+			http.Error(w, "Event history not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit, err := parseLimitParam(r, 500)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		class := query.Get("class")
+		switch class {
+		case "", events.ClassNoise, events.ClassInformational, events.ClassActionable:
+		default:
+			http.Error(w, "unsupported class "+class, http.StatusBadRequest)
+			return
+		}
+
+		result, err := history.Query(r.Context(), events.Query{
+			From:      from,
+			To:        to,
+			Namespace: query.Get("namespace"),
+			Reasons:   splitListParam(query.Get("reason")),
+			Type:      query.Get("type"),
+			Object:    query.Get("object"),
+			Class:     class,
+			Limit:     limit,
+		})
+		if err != nil {
+			http.Error(w, "Failed to query event history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      result,
+			"count":     len(result),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// eventClassifierHandler GET /api/v1/events/classifier 事件分类统计
+func eventClassifierHandler(classifier *events.Classifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if classifier == nil {
+			http.Error(w, "Event classifier not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      classifier.Stats(),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
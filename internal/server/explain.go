@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// explainHandler GET /api/v1/explain?target=node/worker-1&metric=memory_usage_rate 读取对象最近的指标序列，
+// 由LLM说明指标含义、当前值是否值得关注以及下一步检查什么。
+// 参数: from、to（默认最近1小时，最长7天）、profile（LLM配置名）
+func explainHandler(store storage.Store, service *llm.Service, templates *prompts.Registry, sources analysis.Sources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		request := analysis.ExplainRequest{
+			Target:  strings.TrimSpace(query.Get("target")),
+			Metric:  strings.TrimSpace(query.Get("metric")),
+			Profile: query.Get("profile"),
+		}
+		if request.Target == "" || request.Metric == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("target and metric parameters are required"))
+			return
+		}
+		if _, _, err := analysis.ParseMetricTarget(request.Target); err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		end := to
+		if end.IsZero() {
+			end = time.Now().UTC()
+		}
+		if !from.IsZero() && (!from.Before(end) || end.Sub(from) > analysis.MaxExplainWindow) {
+			httpjson.WriteError(w, httpjson.BadRequest("from must be before to and within %s", analysis.MaxExplainWindow))
+			return
+		}
+		request.From, request.To = from, to
+
+		extendWriteDeadline(w, llmWriteTimeout)
+		explanation, err := analysis.ExplainMetric(r.Context(), service, templates, store, sources, request)
+		if err != nil {
+			switch {
+			case errors.Is(err, analysis.ErrNoSamples):
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "no_data", Message: err.Error()})
+			case errors.Is(err, analysis.ErrMetricsUnavailable):
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "storage_error", Message: err.Error()})
+			default:
+				writeLLMError(w, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      explanation,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/tabular"
+)
+
+// 快照和历史接口支持的导出格式
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+)
+
+// 导出格式对应的Content-Type
+var exportContentTypes = map[string]string{
+	formatCSV:     "text/csv; charset=utf-8",
+	formatParquet: "application/vnd.apache.parquet",
+}
+
+// exportFormat 解析 format 参数（json、csv、parquet），未指定时按Accept头选择，默认为json
+func exportFormat(r *http.Request) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	switch format {
+	case formatJSON, formatCSV, formatParquet:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid format %q (supported: json, csv, parquet)", format)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		return formatCSV, nil
+	case strings.Contains(accept, exportContentTypes[formatParquet]):
+		return formatParquet, nil
+	}
+	return formatJSON, nil
+}
+
+// writeTable 以CSV或Parquet写出表格，name 为下载的文件名（不含扩展名）
+func writeTable(w http.ResponseWriter, format, name string, table *tabular.Table) {
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+	var err error
+	if format == formatParquet {
+		err = table.WriteParquet(w)
+	} else {
+		err = table.WriteCSV(w)
+	}
+	if err != nil {
+		log.Printf("Failed to write %s export %s: %v", format, name, err)
+	}
+}
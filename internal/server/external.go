@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// networkExternalHandler GET /api/v1/network/external?target=name 返回最近一轮从各节点访问外部目标
+// （metrics.external_targets）的DNS、建连、TLS和HTTP耗时，target 只返回指定的目标
+func networkExternalHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		reachability := manager.GetExternalReachability()
+		if reachability == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_measured",
+				Message: "External targets not checked yet (configure metrics.external_targets and enable metrics.probe_agents)"})
+			return
+		}
+
+		if name := r.URL.Query().Get("target"); name != "" {
+			filtered := *reachability
+			filtered.Targets = nil
+			for _, target := range reachability.Targets {
+				if target.Name == name {
+					filtered.Targets = []metricstypes.ExternalTarget{target}
+				}
+			}
+			if filtered.Targets == nil {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: "external target " + name + " not found"})
+				return
+			}
+			reachability = &filtered
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      reachability,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
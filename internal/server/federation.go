@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/federation"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// registerPeerRequest 注册对端的请求体
+type registerPeerRequest struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// federationClustersHandler GET 列出联邦中的集群；POST 注册对端（需要管理Token）
+func federationClustersHandler(registry *federation.Registry, adminToken string, auditLog *audit.Logger, maxBody int64) http.HandlerFunc {
+	register := requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		var request registerPeerRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		status, err := registry.Register(r.Context(), federation.Peer{
+			Name:  strings.TrimSpace(request.Name),
+			URL:   request.URL,
+			Token: request.Token,
+		})
+		auditLog.LogRequest(r, audit.ActionFederationPeer, request.Name, map[string]interface{}{
+			"operation": "register",
+			"url":       request.URL,
+		}, err)
+		if err != nil {
+			writeFederationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   status,
+		})
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		switch r.Method {
+		case http.MethodGet:
+			clusters := registry.Clusters()
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"local":     registry.LocalName(),
+				"data":      clusters,
+				"count":     len(clusters),
+				"timestamp": time.Now().UTC(),
+			})
+		case http.MethodPost:
+			register(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// federationClusterHandler DELETE /api/v1/federation/clusters/{name} 删除通过API注册的对端
+func federationClusterHandler(registry *federation.Registry, adminToken string, auditLog *audit.Logger) http.HandlerFunc {
+	return requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/federation/clusters/"), "/")
+		if name == "" {
+			http.Error(w, "Cluster name is required", http.StatusBadRequest)
+			return
+		}
+
+		err := registry.Remove(r.Context(), name)
+		auditLog.LogRequest(r, audit.ActionFederationPeer, name, map[string]interface{}{
+			"operation": "remove",
+		}, err)
+		if err != nil {
+			writeFederationError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "success",
+			"message": "cluster removed: " + name,
+		})
+	})
+}
+
+// writeFederationError 将注册表错误映射为HTTP状态码
+func writeFederationError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, federation.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, federation.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, federation.ErrConflict), errors.Is(err, federation.ErrReadOnly):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// federationClusterMetricsHandler 汇总所有集群的整体指标
+func federationClusterMetricsHandler(registry *federation.Registry, manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		clusters := make(map[string]*metricstypes.ClusterMetrics)
+		errs := make(map[string]string)
+		if manager != nil {
+			clusters[registry.LocalName()] = manager.GetClusterMetrics()
+		} else {
+			errs[registry.LocalName()] = "metrics manager not available"
+		}
+
+		for _, result := range registry.Fetch(r.Context(), "/api/v1/metrics/cluster") {
+			cluster, err := federation.DecodeClusterMetrics(result)
+			if err != nil {
+				errs[result.Cluster] = err.Error()
+				continue
+			}
+			clusters[result.Cluster] = cluster
+		}
+
+		response := map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"total":    federation.AggregateClusterMetrics(clusters),
+				"clusters": clusters,
+			},
+			"count":     len(clusters),
+			"timestamp": time.Now().UTC(),
+		}
+		if len(errs) > 0 {
+			// 部分集群不可达时仍返回其余集群的数据
+			response["status"] = "partial"
+			response["errors"] = errs
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// federationUAVHandler 合并所有集群的UAV列表
+func federationUAVHandler(registry *federation.Registry, manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		clusters := make(map[string]map[string]*models.UAVMetricsEntry)
+		errs := make(map[string]string)
+		if manager != nil {
+			clusters[registry.LocalName()] = manager.GetUAVMetrics()
+		} else {
+			errs[registry.LocalName()] = "metrics manager not available"
+		}
+
+		for _, result := range registry.Fetch(r.Context(), "/api/v1/metrics/uav") {
+			if result.Error != "" {
+				errs[result.Cluster] = result.Error
+				continue
+			}
+			var uavs map[string]*models.UAVMetricsEntry
+			if err := json.Unmarshal(result.Data, &uavs); err != nil {
+				errs[result.Cluster] = "invalid UAV list: " + err.Error()
+				continue
+			}
+			clusters[result.Cluster] = uavs
+		}
+
+		uavs := federation.MergeUAVs(clusters)
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      uavs,
+			"count":     len(uavs),
+			"clusters":  len(clusters),
+			"timestamp": time.Now().UTC(),
+		}
+		if len(errs) > 0 {
+			response["status"] = "partial"
+			response["errors"] = errs
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+)
+
+// errIncidentsUnavailable 故障关联依赖事件历史，开发模式或关闭 analysis.incidents 时不可用
+var errIncidentsUnavailable = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "incidents_unavailable",
+	Message: "incident correlation not available (requires a K8s connection and analysis.incidents.enabled)",
+}
+
+// incidentsHandler GET /api/v1/incidents 故障列表（按开始时间降序），参数 status、namespace、pod、from、to、limit
+func incidentsHandler(engine *incidents.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if engine == nil {
+			httpjson.WriteError(w, errIncidentsUnavailable)
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 100)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", incidents.StatusOpen, incidents.StatusResolved:
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("status must be %q or %q", incidents.StatusOpen, incidents.StatusResolved))
+			return
+		}
+
+		list, err := engine.List(r.Context(), incidents.Filter{
+			Status:    status,
+			Namespace: query.Get("namespace"),
+			Pod:       query.Get("pod"),
+			From:      from,
+			To:        to,
+			Limit:     limit,
+		})
+		if err != nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      list,
+			"count":     len(list),
+			"open":      engine.OpenCount(),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// incidentRouter 单个故障: GET /api/v1/incidents/{id}，POST /api/v1/incidents/{id}/describe
+func incidentRouter(engine *incidents.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/incidents/"), "/"), "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.NotFound(w, r)
+			return
+		}
+		if engine == nil {
+			httpjson.WriteError(w, errIncidentsUnavailable)
+			return
+		}
+
+		id := parts[0]
+		if len(parts) == 1 {
+			incidentHandler(engine, w, r, id)
+			return
+		}
+		switch parts[1] {
+		case "describe":
+			incidentDescribeHandler(engine, w, r, id)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// incidentHandler 返回单个故障
+func incidentHandler(engine *incidents.Engine, w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	incident, err := engine.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, incidents.ErrNotFound) {
+			writeIncidentError(w, id, err)
+		} else {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      incident,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// incidentDescribeHandler 由LLM为故障生成（或重新生成）名称和描述，可选参数 profile 指定LLM配置
+func incidentDescribeHandler(engine *incidents.Engine, w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	extendWriteDeadline(w, llmWriteTimeout)
+
+	incident, err := engine.Describe(r.Context(), id, r.URL.Query().Get("profile"))
+	if err != nil {
+		writeIncidentError(w, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"data":      incident,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// writeIncidentError 故障不存在时返回404，其余按LLM错误处理
+func writeIncidentError(w http.ResponseWriter, id string, err error) {
+	if errors.Is(err, incidents.ErrNotFound) {
+		httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: "incident not found: " + id})
+		return
+	}
+	writeLLMError(w, err)
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ingressAnalysisRequest Ingress链路分析的请求体，ingress 与 service 二选一
+type ingressAnalysisRequest struct {
+	Ingress string `json:"ingress"` // [namespace/]name，默认命名空间 default
+	Service string `json:"service"` // LoadBalancer Service，[namespace/]name
+	Host    string `json:"host"`    // 为空时取Ingress第一条规则的host
+	Path    string `json:"path"`    // 默认 /
+	Probe   bool   `json:"probe"`   // 从监控服务向外部地址发送HTTP请求
+}
+
+// ingressAnalysisHandler POST /api/v1/analyze/ingress 沿Ingress的host/path（或LoadBalancer Service）
+// 追踪到Service、Endpoints和Pod，检查TLS Secret和后端健康状况，可选外部HTTP探测，报告链路在哪里断开
+func ingressAnalysisHandler(k8sClient *k8s.Client, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request ingressAnalysisRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if (request.Ingress == "") == (request.Service == "") {
+			httpjson.WriteError(w, httpjson.BadRequest("exactly one of ingress and service is required"))
+			return
+		}
+		ref, kind := request.Ingress, "ingress"
+		if request.Service != "" {
+			ref, kind = request.Service, "service"
+		}
+		if err := validateObjectRef(kind, ref, kind); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if request.Host != "" {
+			if errs := validation.IsDNS1123Subdomain(request.Host); len(errs) > 0 {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid host %q: %s", request.Host, strings.Join(errs, "; ")))
+				return
+			}
+		}
+		if request.Path != "" && !strings.HasPrefix(request.Path, "/") {
+			httpjson.WriteError(w, httpjson.BadRequest("path must start with /"))
+			return
+		}
+		if k8sClient == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "k8s_unavailable", Message: "K8s client not available - running in development mode"})
+			return
+		}
+
+		extendWriteDeadline(w, networkTestWriteTimeout)
+
+		namespace, name, _ := strings.Cut(qualifyPodRef(ref), "/")
+		check := k8s.IngressCheck{Namespace: namespace, Host: request.Host, Path: request.Path, Probe: request.Probe}
+		if kind == "ingress" {
+			check.Ingress = name
+		} else {
+			check.Service = name
+		}
+
+		result, err := k8sClient.AnalyzeIngress(r.Context(), check)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			} else {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "k8s_error", Message: err.Error()})
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      result,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
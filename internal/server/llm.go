@@ -0,0 +1,227 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+)
+
+// llmProfilesHandler 列出可用的LLM配置及分析类型路由（API Key已脱敏）
+func llmProfilesHandler(watcher *config.Watcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		llmCfg := watcher.Current().Redacted().LLM
+		profiles := make([]config.LLMProfile, 0, len(llmCfg.Profiles)+1)
+		for _, name := range llmCfg.ProfileNames() {
+			if profile, err := llmCfg.Profile(name); err == nil {
+				profiles = append(profiles, profile)
+			}
+		}
+
+		defaultProfile := llmCfg.DefaultProfile
+		if defaultProfile == "" {
+			defaultProfile = config.DefaultProfileName
+		}
+
+		response := map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"profiles":        profiles,
+				"default_profile": defaultProfile,
+				"routing":         llmCfg.Routing,
+			},
+			"count":     len(profiles),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// llmStatusHandler GET /api/v1/llm/status 各LLM配置的熔断状态、错误率和延迟，以及发送前的脱敏统计
+func llmStatusHandler(service *llm.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		statuses := service.Status()
+		healthy := 0
+		for _, status := range statuses {
+			if status.Healthy {
+				healthy++
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      statuses,
+			"count":     len(statuses),
+			"healthy":   healthy,
+			"redaction": service.RedactionStats(),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// llmCompleteRequest 直接调用模型的请求体
+type llmCompleteRequest struct {
+	Prompt  string `json:"prompt"`
+	System  string `json:"system"`
+	Profile string `json:"profile"`
+}
+
+// llmCompleteHandler POST /api/v1/llm/complete 使用指定配置直接调用模型（用于验证配置）
+func llmCompleteHandler(service *llm.Service, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		extendWriteDeadline(w, llmWriteTimeout)
+
+		var request llmCompleteRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if strings.TrimSpace(request.Prompt) == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("prompt is required"))
+			return
+		}
+
+		client, err := service.Client("", request.Profile)
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+
+		messages := []llm.Message{llm.UserMessage(request.Prompt)}
+		if request.System != "" {
+			messages = append([]llm.Message{llm.SystemMessage(request.System)}, messages...)
+		}
+		resp, err := client.Chat(r.Context(), llm.Request{Messages: messages})
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      resp,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// llmUsageHandler GET /api/v1/llm/usage token用量、估算费用和每日预算，参数 days（默认7，最多90）
+func llmUsageHandler(service *llm.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		days := 7
+		if raw := r.URL.Query().Get("days"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > 90 {
+				httpjson.WriteError(w, httpjson.BadRequest("days must be an integer between 1 and 90"))
+				return
+			}
+			days = n
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      service.Usage(r.Context(), days),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// llmCacheHandler GET /api/v1/llm/cache 回答缓存状态；DELETE（需要管理Token）清空缓存
+func llmCacheHandler(service *llm.Service, adminToken string, auditLog *audit.Logger) http.HandlerFunc {
+	invalidate := requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		removed := service.InvalidateCache()
+		auditLog.LogRequest(r, audit.ActionConfigChange, "llm.cache", map[string]interface{}{
+			"invalidate": true,
+			"removed":    removed,
+		}, nil)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"removed":   removed,
+			"data":      service.CacheStats(),
+			"timestamp": time.Now().UTC(),
+		})
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"data":      service.CacheStats(),
+				"timestamp": time.Now().UTC(),
+			})
+		case http.MethodDelete:
+			invalidate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// writeLLMError 将LLM错误映射为HTTP状态码并输出
+func writeLLMError(w http.ResponseWriter, err error) {
+	httpjson.WriteError(w, llmError(err))
+}
+
+// llmError 将LLM错误转换为带HTTP状态码的错误
+func llmError(err error) *httpjson.Error {
+	var reqErr *httpjson.Error
+	var apiErr *llm.APIError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr
+	case errors.Is(err, config.ErrUnknownProfile):
+		return httpjson.BadRequest("%s", err.Error())
+	case errors.Is(err, llm.ErrNotConfigured):
+		return &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "llm_not_configured", Message: err.Error()}
+	case errors.Is(err, llm.ErrCircuitOpen):
+		return &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "llm_unavailable", Message: err.Error()}
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		return &httpjson.Error{Status: http.StatusTooManyRequests, Code: "llm_budget_exceeded", Message: err.Error()}
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return &httpjson.Error{Status: http.StatusTooManyRequests, Code: "llm_rate_limited", Message: err.Error()}
+	default:
+		return &httpjson.Error{Status: http.StatusBadGateway, Code: "llm_error", Message: err.Error()}
+	}
+}
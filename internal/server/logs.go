@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxLogBytes 日志摘要读取的日志大小上限
+const maxLogBytes = 4 << 20
+
+// podRouter 单个Pod的子资源: /api/v1/pods/{namespace}/{name}/logs/summary
+func podRouter(k8sClient *k8s.Client, service *llm.Service, templates *prompts.Registry) http.HandlerFunc {
+	summaryHandler := podLogSummaryHandler(k8sClient, service, templates)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/pods/"), "/"), "/")
+		if len(parts) < 3 || parts[0] == "" || parts[1] == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		namespace, name, action := parts[0], parts[1], strings.Join(parts[2:], "/")
+		switch action {
+		case "logs/summary":
+			summaryHandler(w, r, namespace, name)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// podLogSummaryHandler GET /api/v1/pods/{namespace}/{name}/logs/summary 读取最近的日志并由LLM总结错误、堆栈和可能的原因。
+// 参数: lines（默认500，最多5000）、container、previous（上一次容器实例）、profile（LLM配置名）
+func podLogSummaryHandler(k8sClient *k8s.Client, service *llm.Service, templates *prompts.Registry) func(http.ResponseWriter, *http.Request, string, string) {
+	return func(w http.ResponseWriter, r *http.Request, namespace, name string) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := validatePodRef("pod", namespace+"/"+name); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if k8sClient == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "k8s_unavailable", Message: "K8s client not available - running in development mode"})
+			return
+		}
+
+		query := r.URL.Query()
+		lines := analysis.DefaultLogLines
+		if value := query.Get("lines"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > analysis.MaxLogLines {
+				httpjson.WriteError(w, httpjson.BadRequest("lines must be an integer between 1 and %d", analysis.MaxLogLines))
+				return
+			}
+			lines = n
+		}
+		previous := false
+		if value := query.Get("previous"); value != "" {
+			var err error
+			if previous, err = strconv.ParseBool(value); err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("previous must be a boolean"))
+				return
+			}
+		}
+		request := analysis.LogSummaryRequest{
+			Pod:       namespace + "/" + name,
+			Container: query.Get("container"),
+			Previous:  previous,
+			Profile:   query.Get("profile"),
+		}
+
+		extendWriteDeadline(w, llmWriteTimeout)
+
+		logs, err := k8sClient.GetPodLogsWithOptions(r.Context(), namespace, name, k8s.LogOptions{
+			Container:  request.Container,
+			TailLines:  int64(lines),
+			Previous:   previous,
+			LimitBytes: maxLogBytes,
+		})
+		if err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			case apierrors.IsBadRequest(err):
+				// 多容器Pod未指定container、容器没有上一次实例等
+				httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			default:
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "k8s_error", Message: err.Error()})
+			}
+			return
+		}
+
+		summary, err := analysis.SummarizeLogs(r.Context(), service, templates, request, logs)
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      summary,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// metricsQueryHandler 历史指标聚合查询处理函数
+// 例如 /api/v1/metrics/query?target=node&name=worker-1&metric=cpu_usage_rate&agg=avg,max,p95&from=6h&step=5m&tier=
+func metricsQueryHandler(store storage.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		query, err := parseAggregateQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format, err := exportFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := metrics.Aggregate(r.Context(), store, *query)
+		if err != nil {
+			http.Error(w, "Failed to query metrics: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if format != formatJSON {
+			writeTable(w, format, "metrics-query", result.Table(query.Aggregations))
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      result,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsHistoryHandler 内存中近期指标序列查询处理函数（保留 metrics.cache_retention 秒）
+// 例如 /api/v1/metrics/history?target=node/worker-1&metric=cpu_usage_rate&from=5m&step=30s
+func metricsHistoryHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		target, metric := strings.TrimSpace(params.Get("target")), strings.TrimSpace(params.Get("metric"))
+		if target == "" || metric == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("target and metric parameters are required"))
+			return
+		}
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		var step time.Duration
+		if value := params.Get("step"); value != "" {
+			if step, err = time.ParseDuration(value); err != nil || step <= 0 {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid step %q", value))
+				return
+			}
+		}
+
+		format, err := exportFormat(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		series, err := manager.GetSeries(metric, target, from, to, step)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		if format != formatJSON {
+			writeTable(w, format, "metrics-history", series.Table())
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      series,
+			"count":     len(series.Points),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// metricsDiffHandler 比较两个时间点的集群状态
+// 例如 /api/v1/metrics/diff?from=30m&to=&threshold=20&limit=50
+func metricsDiffHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		if from.IsZero() {
+			httpjson.WriteError(w, httpjson.BadRequest("from parameter is required"))
+			return
+		}
+		threshold := metrics.DefaultDiffThreshold
+		if value := params.Get("threshold"); value != "" {
+			if threshold, err = strconv.ParseFloat(value, 64); err != nil || threshold <= 0 {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid threshold %q", value))
+				return
+			}
+		}
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		diff, err := manager.Diff(r.Context(), from, to, threshold)
+		if err != nil {
+			if errors.Is(err, metrics.ErrNoSnapshotData) {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "no_data", Message: err.Error()})
+				return
+			}
+			http.Error(w, "Failed to compare snapshots: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		total := len(diff.Changes)
+		if limit > 0 && len(diff.Changes) > limit {
+			diff.Changes = diff.Changes[:limit]
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      diff,
+			"count":     len(diff.Changes),
+			"total":     total,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// metricsEfficiencyHandler 资源效率报告处理函数（请求 vs 实际用量）
+// 例如 /api/v1/metrics/efficiency?namespace=default&from=24h&percentile=95
+func metricsEfficiencyHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		opts := metrics.EfficiencyOptions{Namespace: strings.TrimSpace(params.Get("namespace")), From: from, To: to}
+		if value := params.Get("percentile"); value != "" {
+			if opts.Percentile, err = strconv.ParseFloat(value, 64); err != nil || opts.Percentile <= 0 || opts.Percentile > 100 {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid percentile %q", value))
+				return
+			}
+		}
+
+		report, err := manager.Efficiency(r.Context(), opts)
+		if err != nil {
+			http.Error(w, "Failed to build efficiency report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      report,
+			"count":     len(report.Namespaces),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// metricsRestartsHandler 容器重启历史处理函数
+// 例如 /api/v1/metrics/restarts?namespace=default&chronic=true
+func metricsRestartsHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		params := r.URL.Query()
+		chronic := false
+		if value := params.Get("chronic"); value != "" {
+			var err error
+			if chronic, err = strconv.ParseBool(value); err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid chronic %q", value))
+				return
+			}
+		}
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		restarts := manager.GetRestarts(strings.TrimSpace(params.Get("namespace")), chronic)
+		total := len(restarts)
+		if limit > 0 && len(restarts) > limit {
+			restarts = restarts[:limit]
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      restarts,
+			"count":     len(restarts),
+			"total":     total,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// metricsLinkQualityHandler Pod对链路质量趋势处理函数
+// 例如 /api/v1/metrics/network/quality?degraded=true
+func metricsLinkQualityHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		degraded := false
+		if value := r.URL.Query().Get("degraded"); value != "" {
+			var err error
+			if degraded, err = strconv.ParseBool(value); err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid degraded %q", value))
+				return
+			}
+		}
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		links := manager.GetLinkTrends(degraded)
+		total := len(links)
+		if limit > 0 && len(links) > limit {
+			links = links[:limit]
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      links,
+			"count":     len(links),
+			"total":     total,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// parseAggregateQuery 解析聚合查询参数
+func parseAggregateQuery(r *http.Request) (*metrics.AggregateQuery, error) {
+	params := r.URL.Query()
+
+	query := &metrics.AggregateQuery{
+		Target:       strings.ToLower(strings.TrimSpace(params.Get("target"))),
+		Metric:       strings.TrimSpace(params.Get("metric")),
+		Aggregations: splitListParam(params.Get("agg")),
+	}
+	if query.Metric == "" {
+		return nil, fmt.Errorf("metric parameter is required")
+	}
+	for _, agg := range query.Aggregations {
+		if err := metrics.ValidateAggregation(agg); err != nil {
+			return nil, err
+		}
+	}
+
+	name := strings.TrimSpace(params.Get("name"))
+	switch query.Target {
+	case metrics.TargetNode, metrics.TargetUAV:
+		if name == "" {
+			return nil, fmt.Errorf("name parameter is required")
+		}
+		query.Key = name
+		if query.Target == metrics.TargetNode {
+			query.Key = metrics.SampleKey(metrics.TargetNode, name)
+		}
+	case metrics.TargetPod:
+		if name == "" {
+			return nil, fmt.Errorf("name parameter is required (namespace/pod)")
+		}
+		namespace, podName := "default", name
+		if ns := params.Get("namespace"); ns != "" {
+			namespace = ns
+		} else if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+			namespace, podName = parts[0], parts[1]
+		}
+		query.Key = metrics.SampleKey(metrics.TargetPod, namespace, podName)
+	case metrics.TargetPair:
+		source, dest := params.Get("source"), params.Get("dest")
+		if source == "" || dest == "" {
+			return nil, fmt.Errorf("source and dest parameters are required for pair queries")
+		}
+		query.Key = metrics.SampleKey(metrics.TargetPair, source, dest)
+	default:
+		return nil, fmt.Errorf("target must be one of node, pod, pair, uav")
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() {
+		from = time.Now().UTC().Add(-time.Hour)
+	}
+	query.From, query.To = from, to
+
+	if step := params.Get("step"); step != "" {
+		d, err := time.ParseDuration(step)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid step %q", step)
+		}
+		query.Step = d
+	}
+
+	if tier := strings.TrimSpace(params.Get("tier")); tier != "" {
+		if _, ok := metrics.LookupTier(tier); !ok {
+			return nil, fmt.Errorf("tier must be one of raw, 1m, 10m")
+		}
+		query.Tier = tier
+	}
+
+	return query, nil
+}
+
+// parseTopQuery 解析 sort、order、limit 和 namespace 参数。指定了 sort 或 limit 时返回true，
+// 此时接口按顺序返回列表，否则保持按名称索引的map
+func parseTopQuery(r *http.Request) (metrics.TopQuery, bool, error) {
+	params := r.URL.Query()
+	query := metrics.TopQuery{
+		Namespace: strings.TrimSpace(params.Get("namespace")),
+		Sort:      strings.TrimSpace(params.Get("sort")),
+	}
+
+	// 按数值字段排序时默认从大到小（top N），按名称排序时默认从小到大
+	query.Desc = query.Sort != "" && query.Sort != "name"
+	switch order := strings.ToLower(strings.TrimSpace(params.Get("order"))); order {
+	case "":
+	case "asc":
+		query.Desc = false
+	case "desc":
+		query.Desc = true
+	default:
+		return query, false, fmt.Errorf("invalid order %q (expected asc or desc)", order)
+	}
+
+	limit, err := parseLimitParam(r, 0)
+	if err != nil {
+		return query, false, err
+	}
+	query.Limit = limit
+	return query, query.Sort != "" || params.Get("limit") != "", nil
+}
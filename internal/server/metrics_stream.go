@@ -0,0 +1,69 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/websocket"
+)
+
+// 实时推送连接的参数
+const (
+	streamWriteTimeout = 10 * time.Second
+	streamPingInterval = 30 * time.Second // 定期ping，避免空闲连接被代理断开
+)
+
+// metricsStreamHandler 实时指标推送（WebSocket）：连接后先收到完整快照，
+// 之后每次采集完成或收到UAV上报时收到增量更新。跨域连接只允许 allowedOrigins 中的来源
+func metricsStreamHandler(manager *metrics.Manager, allowedOrigins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := websocket.Upgrade(w, r, websocket.Options{WriteTimeout: streamWriteTimeout, AllowedOrigins: allowedOrigins})
+		if err != nil {
+			return
+		}
+
+		updates, cancel := manager.Subscribe()
+		defer cancel()
+		go conn.ReadLoop()
+
+		ping := time.NewTicker(streamPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-conn.Done():
+				return
+			case <-ping.C:
+				if err := conn.Ping(); err != nil {
+					return
+				}
+			case update, ok := <-updates:
+				if !ok {
+					// 消费过慢被取消订阅，客户端重连后重新获取完整快照
+					conn.Close(websocket.CloseTryAgainLater, "client too slow")
+					return
+				}
+				data, err := update.JSON()
+				if err != nil {
+					log.Printf("Failed to encode metrics stream update: %v", err)
+					continue
+				}
+				if err := conn.WriteText(data); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
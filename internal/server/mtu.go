@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// mtuDiagnosisRequest MTU诊断的请求体
+type mtuDiagnosisRequest struct {
+	Kind   string `json:"kind"`   // pod（默认）或 node
+	Source string `json:"source"` // Pod为 [namespace/]name，节点为节点名
+	Target string `json:"target"`
+	MaxMTU int    `json:"max_mtu"` // 查找上限，默认1500，巨帧网络可设为9000
+}
+
+// networkMTUHandler POST /api/v1/network/mtu 由源节点上的探测Agent用设置DF位的ping测量两个Pod（或节点）之间
+// Pod网络和节点网络的路径MTU，发现超过路径MTU的包被静默丢弃（overlay MTU不匹配）时给出解决建议
+func networkMTUHandler(k8sClient *k8s.Client, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request mtuDiagnosisRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		check := k8s.MTUCheck{MaxMTU: request.MaxMTU}
+		switch strings.ToLower(strings.TrimSpace(request.Kind)) {
+		case "", "pod":
+			if err := validatePodRef("source", request.Source); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			if err := validatePodRef("target", request.Target); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			check.SourcePod, check.TargetPod = qualifyPodRef(request.Source), qualifyPodRef(request.Target)
+		case "node":
+			if err := validateNodeName("source", request.Source); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			if err := validateNodeName("target", request.Target); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			check.SourceNode, check.TargetNode = request.Source, request.Target
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("kind must be pod or node"))
+			return
+		}
+		if request.MaxMTU != 0 && (request.MaxMTU < probe.MinMTU || request.MaxMTU > probe.MaxMTU) {
+			httpjson.WriteError(w, httpjson.BadRequest("max_mtu must be between %d and %d", probe.MinMTU, probe.MaxMTU))
+			return
+		}
+		if k8sClient == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "k8s_unavailable", Message: "K8s client not available - running in development mode"})
+			return
+		}
+
+		extendWriteDeadline(w, networkTestWriteTimeout)
+
+		diagnosis, err := k8sClient.DiagnoseMTU(r.Context(), check)
+		if err != nil {
+			switch {
+			case errors.Is(err, k8s.ErrNoProbeAgent):
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "probe_agent_unavailable", Message: err.Error() + " (MTU diagnosis requires metrics.probe_agents)"})
+			case apierrors.IsNotFound(err):
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			default:
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "k8s_error", Message: err.Error()})
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      diagnosis,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
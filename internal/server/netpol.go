@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxPolicyPorts 网络策略建议请求中的端口数上限
+const maxPolicyPorts = 20
+
+// policySuggestRequest 网络策略建议的请求体
+type policySuggestRequest struct {
+	Source       string            `json:"source"`
+	Destination  string            `json:"destination"`
+	Connectivity string            `json:"connectivity"`
+	Ports        []models.PortRule `json:"ports"`
+	Profile      string            `json:"profile"`
+}
+
+// networkPolicySuggestHandler POST /api/v1/network-policies/suggest 读取两个工作负载所在命名空间的现有网络策略，
+// 由LLM生成实现期望连通性的NetworkPolicy YAML，并说明它与现有策略的关系
+func networkPolicySuggestHandler(k8sClient *k8s.Client, service *llm.Service, templates *prompts.Registry, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request policySuggestRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := validateWorkloadRef("source", request.Source); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := validateWorkloadRef("destination", request.Destination); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		request.Connectivity = strings.ToLower(strings.TrimSpace(request.Connectivity))
+		switch request.Connectivity {
+		case "":
+			request.Connectivity = analysis.ConnectivityAllow
+		case analysis.ConnectivityAllow, analysis.ConnectivityDeny:
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("connectivity must be %q or %q", analysis.ConnectivityAllow, analysis.ConnectivityDeny))
+			return
+		}
+		if len(request.Ports) > maxPolicyPorts {
+			httpjson.WriteError(w, httpjson.BadRequest("at most %d ports are allowed", maxPolicyPorts))
+			return
+		}
+		for i := range request.Ports {
+			port := &request.Ports[i]
+			if port.Port < 1 || port.Port > 65535 {
+				httpjson.WriteError(w, httpjson.BadRequest("ports[%d].port must be between 1 and 65535", i))
+				return
+			}
+			port.Protocol = strings.ToUpper(strings.TrimSpace(port.Protocol))
+			switch port.Protocol {
+			case "":
+				port.Protocol = "TCP"
+			case "TCP", "UDP", "SCTP":
+			default:
+				httpjson.WriteError(w, httpjson.BadRequest("ports[%d].protocol must be TCP, UDP or SCTP", i))
+				return
+			}
+		}
+		if k8sClient == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "k8s_unavailable", Message: "K8s client not available - running in development mode"})
+			return
+		}
+
+		extendWriteDeadline(w, llmWriteTimeout)
+
+		current, err := k8s.NewNetworkAnalyzer(k8sClient).PolicyContext(r.Context(), request.Source, request.Destination)
+		if err != nil {
+			if errors.Is(err, k8s.ErrWorkloadNotFound) {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			} else {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "k8s_error", Message: err.Error()})
+			}
+			return
+		}
+
+		suggestion, err := analysis.SuggestNetworkPolicy(r.Context(), service, templates, current, analysis.PolicyRequest{
+			Source:       request.Source,
+			Destination:  request.Destination,
+			Connectivity: request.Connectivity,
+			Ports:        request.Ports,
+			Profile:      request.Profile,
+		})
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      suggestion,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// validateWorkloadRef 校验 [kind/][namespace/]name 形式的工作负载引用
+func validateWorkloadRef(field, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return httpjson.BadRequest("%s is required", field)
+	}
+	_, namespace, name, err := k8s.ParseWorkloadRef(value)
+	if err != nil {
+		return httpjson.BadRequest("%s: %s", field, err.Error())
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return httpjson.BadRequest("%s has invalid namespace %q: %s", field, namespace, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return httpjson.BadRequest("%s has invalid name: %s", field, strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/netprobe"
+)
+
+// errProbesUnavailable 连通性探测依赖K8s连接和 metrics.connectivity_probes.probes 中的定义
+var errProbesUnavailable = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "probes_unavailable",
+	Message: "connectivity probes not available (requires a K8s connection and metrics.connectivity_probes.probes)",
+}
+
+// networkProbesHandler GET /api/v1/network/probes 所有连通性探测的当前状态（up、down、flapping、unknown）
+func networkProbesHandler(scheduler *netprobe.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if scheduler == nil {
+			httpjson.WriteError(w, errProbesUnavailable)
+			return
+		}
+
+		statuses := scheduler.Statuses()
+		counts := make(map[string]int)
+		for _, status := range statuses {
+			counts[status.State]++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      statuses,
+			"count":     len(statuses),
+			"states":    counts,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// networkProbeHandler GET /api/v1/network/probes/{name} 单个探测的状态和保留的历史
+func networkProbeHandler(scheduler *netprobe.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/network/probes/"), "/")
+		if name == "" || strings.Contains(name, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if scheduler == nil {
+			httpjson.WriteError(w, errProbesUnavailable)
+			return
+		}
+
+		status, samples, err := scheduler.Get(name)
+		if errors.Is(err, netprobe.ErrNotFound) {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      status,
+			"history":   samples,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
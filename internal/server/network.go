@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/netprobe"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/topology"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// networkTestWriteTimeout 按需网络测试的写超时（带宽测试可能需要较长时间）
+const networkTestWriteTimeout = time.Minute
+
+// networkTestMethods 按需网络测试支持的方法
+var networkTestMethods = []string{probe.MethodPing, probe.MethodTCP, probe.MethodUDP, probe.MethodHTTP, probe.MethodDNS}
+
+// networkTestRequest 按需网络测试的请求体
+type networkTestRequest struct {
+	SourcePod string   `json:"source_pod"` // [namespace/]name，默认命名空间 default
+	TargetPod string   `json:"target_pod"`
+	Methods   []string `json:"methods"` // 为空时执行与自动采集相同的测试
+	Count     *int     `json:"count"`   // ping/tcp/udp次数，1-10，省略时使用默认次数
+	Port      *int     `json:"port"`    // tcp/udp端口，省略时取目标Pod声明的端口
+
+	AllowNoReply bool `json:"allow_no_reply"` // udp：目标不做echo时，没有回复也算成功
+}
+
+// metricsNetworkTestHandler POST /api/v1/metrics/network/test 同步测试指定Pod对的连通性，返回 NetworkMetrics
+// 测试会exec进入源Pod（可能添加临时调试容器），路由需要管理员Token
+func metricsNetworkTestHandler(manager *metrics.Manager, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		var request networkTestRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := validatePodRef("source_pod", request.SourcePod); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := validatePodRef("target_pod", request.TargetPod); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		seen := make(map[string]bool, len(request.Methods))
+		for _, method := range request.Methods {
+			if !slices.Contains(networkTestMethods, method) {
+				httpjson.WriteError(w, httpjson.BadRequest("unknown method %q (supported: %s)", method, strings.Join(networkTestMethods, ", ")))
+				return
+			}
+			if seen[method] {
+				httpjson.WriteError(w, httpjson.BadRequest("duplicate method %q", method))
+				return
+			}
+			seen[method] = true
+		}
+		var options sources.PodTestOptions
+		if request.Count != nil {
+			if *request.Count < 1 || *request.Count > probe.MaxCount {
+				httpjson.WriteError(w, httpjson.BadRequest("count must be between 1 and %d", probe.MaxCount))
+				return
+			}
+			options.Count = *request.Count
+		}
+		if request.Port != nil {
+			if *request.Port < 1 || *request.Port > 65535 {
+				httpjson.WriteError(w, httpjson.BadRequest("port must be between 1 and 65535"))
+				return
+			}
+			options.Port = *request.Port
+		}
+		options.Methods = request.Methods
+		options.AllowNoReply = request.AllowNoReply
+
+		extendWriteDeadline(w, networkTestWriteTimeout)
+
+		result, err := manager.TestPodCommunication(r.Context(), qualifyPodRef(request.SourcePod), qualifyPodRef(request.TargetPod), options)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, metrics.ErrNetworkDisabled):
+				status = http.StatusServiceUnavailable
+			case apierrors.IsNotFound(err):
+				status = http.StatusNotFound
+			}
+			httpjson.WriteError(w, &httpjson.Error{Status: status, Code: "network_test_failed", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      result,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// qualifyPodRef 为没有命名空间的Pod引用补上 default
+func qualifyPodRef(ref string) string {
+	if strings.Contains(ref, "/") {
+		return ref
+	}
+	return "default/" + ref
+}
+
+// networkTopologyHandler GET /api/v1/network/topology 网络拓扑图：Pod、Service、节点，以及网络测试、
+// 节点间时延和连通性探测观察到的连接（边带 rtt_ms/quality 注解），可用 ?namespace= 限定，跨命名空间的连接保留
+func networkTopologyHandler(manager *metrics.Manager, k8sClient *k8s.Client, scheduler *netprobe.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		namespace := r.URL.Query().Get("namespace")
+		input := topology.NetworkInput{}
+		if manager != nil {
+			input.Snapshot = manager.ViewSnapshot()
+			input.NodeLatency = manager.GetNodeLatency()
+			if namespace != "" && input.Snapshot != nil {
+				input.Snapshot = filterSnapshotNamespace(input.Snapshot, namespace)
+			}
+		}
+		if scheduler != nil {
+			input.Probes = probeLinks(scheduler.Statuses(), namespace)
+		}
+
+		var warnings []string
+		input.Pods, input.Services, warnings = listPodsAndServices(k8sClient, namespace)
+
+		response := map[string]interface{}{
+			"status": "success",
+			"data":   topology.BuildNetwork(input),
+		}
+		if len(warnings) > 0 {
+			response["warnings"] = warnings
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// probeLinks 由探测状态得到拓扑中的连接（使用最近一次实际探测的源和目标Pod），namespace 不为空时只保留一端在其中的探测
+func probeLinks(statuses []netprobe.Status, namespace string) []topology.ProbeLink {
+	links := make([]topology.ProbeLink, 0, len(statuses))
+	prefix := namespace + "/"
+	for _, status := range statuses {
+		if status.Last == nil || status.Last.Source == "" || status.Last.Target == "" {
+			continue
+		}
+		link := topology.ProbeLink{
+			Name:        status.Name,
+			Source:      status.Last.Source,
+			Target:      status.Last.Target,
+			External:    strings.Contains(status.Last.Target, "://"),
+			State:       status.State,
+			RTT:         status.AverageRTT,
+			SuccessRate: status.SuccessRate,
+		}
+		if namespace != "" && !strings.HasPrefix(link.Source, prefix) && (link.External || !strings.HasPrefix(link.Target, prefix)) {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+)
+
+// nodeRouter 处理 /api/v1/nodes/{name}/... 形式的节点接口
+func nodeRouter(manager *metrics.Manager) http.HandlerFunc {
+	timelineHandler := nodeTimelineHandler(manager)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/nodes/"), "/"), "/")
+		if len(parts) < 2 || parts[0] == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		nodeName, action := parts[0], parts[1]
+		switch {
+		case action == "timeline" && len(parts) == 2:
+			timelineHandler(w, r, nodeName)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// nodeTimelineHandler 节点条件变化时间线处理函数
+func nodeTimelineHandler(manager *metrics.Manager) func(http.ResponseWriter, *http.Request, string) {
+	return func(w http.ResponseWriter, r *http.Request, nodeName string) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		timeline, err := manager.NodeTimeline(r.Context(), nodeName, from, to, limit)
+		if err != nil {
+			if errors.Is(err, metrics.ErrNodeNotFound) {
+				http.Error(w, "Node not found: "+nodeName, http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to query node timeline: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      timeline,
+			"count":     len(timeline.Transitions),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
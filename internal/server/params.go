@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimeParam 解析时间查询参数，支持RFC3339、Unix秒以及相对时长（如 1h 表示一小时前）
+func parseTimeParam(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time value %q (expected RFC3339, unix seconds or duration)", value)
+}
+
+// parseTimeRange 解析 from/to 查询参数
+func parseTimeRange(r *http.Request) (from, to time.Time, err error) {
+	query := r.URL.Query()
+	if from, err = parseTimeParam(query.Get("from")); err != nil {
+		return
+	}
+	if to, err = parseTimeParam(query.Get("to")); err != nil {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		err = fmt.Errorf("'to' must not be earlier than 'from'")
+	}
+	return
+}
+
+// parseLimitParam 解析 limit 查询参数
+func parseLimitParam(r *http.Request, defaultLimit int) (int, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("limit"))
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid limit %q", value)
+	}
+	return limit, nil
+}
+
+// splitListParam 解析逗号分隔的查询参数
+func splitListParam(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+)
+
+// ensureProbeAgents 启动时创建或更新探测Agent DaemonSet，失败时网络测试回退到exec
+func ensureProbeAgents(k8sClient *k8s.Client, cfg config.ProbeAgentsConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := k8sClient.EnsureProbeAgents(ctx, k8s.ProbeAgentSpec{
+		Namespace: cfg.Namespace,
+		Image:     cfg.Image,
+		Port:      cfg.Port,
+		Token:     cfg.Token,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to deploy probe agents: %v", err)
+		return
+	}
+	log.Printf("Probe agents deployed in namespace %s", cfg.Namespace)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+// networkProbeResultExportTimeout 每轮导出（写入和清理）的超时
+const networkProbeResultExportTimeout = 30 * time.Second
+
+// networkProbeResultExporter 将每轮网络测试的各Pod对结果写入NetworkProbeResult CRD，
+// 并删除 export_ttl 内没有更新的结果（Pod对不再被选中或Pod已删除）
+func networkProbeResultExporter(k8sClient *k8s.Client, cfg config.NetworkTestsConfig, auditLog *audit.Logger) func(context.Context, []*metricstypes.NetworkMetrics) {
+	ttl := time.Duration(cfg.ExportTTL) * time.Second
+	return func(ctx context.Context, metrics []*metricstypes.NetworkMetrics) {
+		exportCtx, cancel := context.WithTimeout(ctx, networkProbeResultExportTimeout)
+		defer cancel()
+
+		var errs []error
+		updated := 0
+		for _, metric := range metrics {
+			if err := k8sClient.UpsertNetworkProbeResult(exportCtx, cfg.ExportNamespace, metric); err != nil {
+				log.Printf("Failed to export network probe result %s -> %s: %v", metric.SourcePod, metric.TargetPod, err)
+				errs = append(errs, err)
+				continue
+			}
+			updated++
+		}
+		pruned, err := k8sClient.PruneNetworkProbeResults(exportCtx, cfg.ExportNamespace, time.Now().Add(-ttl))
+		if err != nil {
+			log.Printf("Failed to prune network probe results: %v", err)
+			errs = append(errs, err)
+		}
+
+		auditLog.Log(ctx, audit.ActorSystem, "", audit.ActionCRDUpsert, "networkproberesults", map[string]interface{}{
+			"updated": updated,
+			"pruned":  pruned,
+			"failed":  len(metrics) - updated,
+		}, errors.Join(errs...))
+	}
+}
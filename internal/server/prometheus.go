@@ -0,0 +1,33 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+)
+
+// prometheusHandler GET /metrics 以Prometheus文本格式输出采集到的节点、Pod、网络和UAV指标
+func prometheusHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		// 先写入缓冲区，出错时返回500而不是不完整的输出
+		var buf bytes.Buffer
+		if err := manager.WritePrometheus(&buf); err != nil {
+			log.Printf("Failed to write Prometheus metrics: %v", err)
+			http.Error(w, "Failed to write metrics", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", metrics.PrometheusContentType)
+		w.Write(buf.Bytes())
+	}
+}
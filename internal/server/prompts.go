@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+)
+
+// promptsHandler GET /api/v1/prompts 列出提示词模板的所有版本（active 为当前使用的版本）
+func promptsHandler(registry *prompts.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		templates := registry.List()
+		if name := r.URL.Query().Get("name"); name != "" {
+			filtered := templates[:0]
+			for _, t := range templates {
+				if t.Name == name {
+					filtered = append(filtered, t)
+				}
+			}
+			templates = filtered
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      templates,
+			"count":     len(templates),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// promptsReloadHandler POST /api/v1/prompts/reload 重新读取模板目录；文件有错误时保留原有模板并返回400
+func promptsReloadHandler(registry *prompts.Registry, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := registry.Reload()
+		auditLog.LogRequest(r, audit.ActionConfigChange, "analysis.prompts", map[string]interface{}{"reload": true}, err)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		templates := registry.List()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      templates,
+			"count":     len(templates),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/assistant"
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+)
+
+// analysisTypeQuery 自然语言查询在 llm.routing 中使用的分析类型
+const analysisTypeQuery = "query"
+
+// maxQuestionLength 问题的最大长度
+const maxQuestionLength = 2000
+
+// queryRequest 自然语言查询请求体
+type queryRequest struct {
+	Question string `json:"question"`
+	Profile  string `json:"profile"`
+}
+
+// queryHandler POST /api/v1/query 由LLM调用只读工具查询集群状态并回答问题。
+// 请求 Accept: text/event-stream（或 ?stream=true）时以SSE输出模型片段和工具调用，最后输出 result 事件
+func queryHandler(service *llm.Service, tools *assistant.Toolset, sources analysis.Sources, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		extendWriteDeadline(w, llmWriteTimeout)
+
+		var request queryRequest
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		question := strings.TrimSpace(request.Question)
+		if question == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("question is required"))
+			return
+		}
+		if len(question) > maxQuestionLength {
+			httpjson.WriteError(w, httpjson.BadRequest("question must be at most %d bytes", maxQuestionLength))
+			return
+		}
+
+		client, err := service.Client(analysisTypeQuery, request.Profile)
+		if err != nil {
+			writeLLMError(w, err)
+			return
+		}
+
+		var stream *sseWriter
+		var observer assistant.Observer
+		if wantsStream(r) {
+			stream = newSSEWriter(w)
+			observer.Token = stream.Token
+			observer.Step = func(step assistant.Step) {
+				stream.Send(sseEventStep, step)
+			}
+		}
+
+		// 时间精确到分钟，集群状态（指标快照）未变化时相同的问题可以命中回答缓存
+		messages := []llm.Message{
+			llm.SystemMessage(assistant.SystemPrompt + "\nCurrent time: " + time.Now().UTC().Truncate(time.Minute).Format(time.RFC3339)),
+			llm.UserMessage(question),
+		}
+		ctx := llm.WithFingerprint(r.Context(), sources.Fingerprint())
+		ctx = assistant.WithAnalysis(audit.WithRequest(ctx, r), analysisTypeQuery)
+		answer, _, err := assistant.AskStream(ctx, client, tools, messages, observer)
+		if err != nil {
+			if stream != nil {
+				stream.Error(err)
+			} else {
+				writeLLMError(w, err)
+			}
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"question":  question,
+			"data":      answer,
+			"timestamp": time.Now().UTC(),
+		}
+		if stream != nil {
+			stream.Send(sseEventResult, response)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
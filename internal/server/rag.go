@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/rag"
+)
+
+// maxRAGResults 单次检索返回的最大条数
+const maxRAGResults = 50
+
+// errRAGUnavailable 历史检索未启用或初始化失败
+var errRAGUnavailable = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "rag_unavailable",
+	Message: "history retrieval not available (requires analysis.rag.enabled)",
+}
+
+// ragSearchHandler GET /api/v1/rag/search 检索相似的历史摘要，参数 q、kind（逗号分隔）、before、limit
+func ragSearchHandler(index *rag.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if index == nil {
+			httpjson.WriteError(w, errRAGUnavailable)
+			return
+		}
+
+		query := r.URL.Query()
+		text := strings.TrimSpace(query.Get("q"))
+		if text == "" {
+			httpjson.WriteError(w, httpjson.BadRequest("q is required"))
+			return
+		}
+		kinds := splitListParam(query.Get("kind"))
+		for _, kind := range kinds {
+			switch kind {
+			case rag.KindSnapshot, rag.KindIncident, rag.KindUAV:
+			default:
+				httpjson.WriteError(w, httpjson.BadRequest("unsupported kind %q (expected %s, %s or %s)", kind, rag.KindSnapshot, rag.KindIncident, rag.KindUAV))
+				return
+			}
+		}
+		before, err := parseTimeParam(query.Get("before"))
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		if limit > maxRAGResults {
+			limit = maxRAGResults
+		}
+
+		matches, err := index.Search(r.Context(), text, kinds, before, limit)
+		if err != nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "embedding_failed", Message: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      matches,
+			"count":     len(matches),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// ragStatusHandler GET /api/v1/rag/status 索引状态（向量存储、模型、各类摘要数、最近一轮结果）
+func ragStatusHandler(index *rag.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if index == nil {
+			httpjson.WriteError(w, errRAGUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      index.Status(r.Context()),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxReachabilityNamespaces 可达性矩阵最多包含的命名空间数
+const maxReachabilityNamespaces = 20
+
+// networkReachabilityHandler GET /api/v1/network/reachability?namespaces=a,b,c&port=8080&protocol=TCP
+// 按NetworkPolicy计算命名空间间的N×N放行/拦截矩阵（不发送实际流量），namespaces 默认为监控的命名空间
+func networkReachabilityHandler(k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		params := r.URL.Query()
+		var namespaces []string
+		seen := make(map[string]bool)
+		for _, namespace := range strings.Split(params.Get("namespaces"), ",") {
+			namespace = strings.TrimSpace(namespace)
+			if namespace == "" || seen[namespace] {
+				continue
+			}
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				httpjson.WriteError(w, httpjson.BadRequest("invalid namespace %q: %s", namespace, strings.Join(errs, "; ")))
+				return
+			}
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+		if len(namespaces) > maxReachabilityNamespaces {
+			httpjson.WriteError(w, httpjson.BadRequest("at most %d namespaces are allowed", maxReachabilityNamespaces))
+			return
+		}
+
+		var port int64
+		if value := params.Get("port"); value != "" {
+			var err error
+			if port, err = strconv.ParseInt(value, 10, 32); err != nil || port < 1 || port > 65535 {
+				httpjson.WriteError(w, httpjson.BadRequest("port must be between 1 and 65535"))
+				return
+			}
+		}
+		protocol := strings.ToUpper(strings.TrimSpace(params.Get("protocol")))
+		switch protocol {
+		case "", "TCP", "UDP", "SCTP":
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("protocol must be TCP, UDP or SCTP"))
+			return
+		}
+
+		if k8sClient == nil {
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "k8s_unavailable", Message: "K8s client not available - running in development mode"})
+			return
+		}
+		if len(namespaces) == 0 {
+			namespaces = k8sClient.Namespaces()
+		}
+
+		matrix, err := k8sClient.PolicyReachability(r.Context(), namespaces, int32(port), protocol)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found", Message: err.Error()})
+			} else {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusBadGateway, Code: "k8s_error", Message: err.Error()})
+			}
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      matrix,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// configReloader 将配置文件的变更应用到运行中的组件
+func configReloader(configPath string, manager *metrics.Manager, history *events.History, classifier *events.Classifier, auditLog *audit.Logger, llmService *llm.Service, promptRegistry *prompts.Registry) config.ChangeHandler {
+	return func(old, current *config.Config, changes []config.Change) {
+		var applied, pending []string
+		llmChanged, promptsChanged, filterChanged, podFilterChanged, anomaliesChanged, intervalsChanged := false, false, false, false, false, false
+
+		for _, change := range changes {
+			if !change.HotReload {
+				pending = append(pending, change.Key)
+				continue
+			}
+
+			switch {
+			case change.Key == "logging.level":
+				if err := logging.SetLevel(current.Logging.Level); err != nil {
+					log.Printf("Ignoring invalid log level: %v", err)
+					continue
+				}
+			case change.Key == "metrics.collect_interval":
+				if manager != nil {
+					manager.SetInterval(time.Duration(current.Metrics.CollectInterval) * time.Second)
+				}
+			case change.Key == "metrics.intervals", change.Key == "metrics.jitter":
+				intervalsChanged = true
+			case change.Key == "metrics.namespaces":
+				if manager != nil {
+					manager.SetNamespaces(current.Metrics.Namespaces)
+				}
+			case strings.HasPrefix(change.Key, "metrics.pod_filter."):
+				podFilterChanged = true
+			case change.Key == "metrics.cache_retention":
+				if manager != nil {
+					manager.SetHistoryRetention(time.Duration(current.Metrics.CacheRetention) * time.Second)
+				}
+			case strings.HasPrefix(change.Key, "metrics.anomalies."):
+				anomaliesChanged = true
+			case change.Key == "monitoring.event_retention":
+				if history != nil {
+					history.SetRetention(time.Duration(current.Monitoring.EventRetention) * time.Hour)
+				}
+			case strings.HasPrefix(change.Key, "monitoring.event_filter."):
+				filterChanged = true
+			case strings.HasPrefix(change.Key, "llm."):
+				llmChanged = true
+			case strings.HasPrefix(change.Key, "analysis.prompts."):
+				promptsChanged = true
+			}
+			applied = append(applied, fmt.Sprintf("%s: %v -> %v", change.Key, change.Old, change.New))
+		}
+
+		// LLM配置整体替换，已创建的客户端在下次调用时按新配置重建
+		if llmChanged && llmService != nil {
+			llmService.Update(current.LLM)
+		}
+		// 各采集器的间隔和抖动整体替换，已启用的采集器按新间隔重新调度
+		if intervalsChanged && manager != nil {
+			manager.SetCollectorIntervals(collectorIntervals(current.Metrics), current.Metrics.Jitter)
+		}
+		// Pod过滤条件整体替换（配置加载时已校验语法）
+		if podFilterChanged && manager != nil {
+			manager.SetPodFilter(podFilter(current.Metrics.PodFilter))
+		}
+		// 异常检测阈值整体替换；启动时未开启异常检测则不生效（enabled 需要重启）
+		if anomaliesChanged && manager != nil && manager.Anomalies() != nil {
+			manager.Anomalies().SetConfig(anomalyConfig(current.Metrics.Anomalies))
+		}
+		// 事件分类的覆盖项等整体替换
+		if filterChanged && classifier != nil {
+			classifier.Update(current.Monitoring.EventFilter)
+		}
+		// 模板目录或固定版本变更时重新加载模板，加载失败时保留原有模板
+		if promptsChanged && promptRegistry != nil {
+			if err := promptRegistry.Update(current.Analysis.Prompts); err != nil {
+				log.Printf("Failed to reload prompt templates, keeping previous templates: %v", err)
+			}
+		}
+
+		if len(applied) > 0 {
+			log.Printf("Config reloaded, applied: %s", strings.Join(applied, "; "))
+		}
+		if len(pending) > 0 {
+			log.Printf("Config changes require restart to take effect: %s", strings.Join(pending, ", "))
+		}
+
+		// 审计日志按配置项记录（值已脱敏）
+		for _, change := range changes {
+			auditLog.Log(context.Background(), audit.ActorSystem, "", audit.ActionConfigChange, change.Key, map[string]interface{}{
+				"source":     configPath,
+				"old":        change.Old,
+				"new":        change.New,
+				"hot_reload": change.HotReload,
+			}, nil)
+		}
+
+		// 记录配置变更事件，便于与后续的指标变化关联
+		if history != nil {
+			message := fmt.Sprintf("applied: [%s]", strings.Join(applied, "; "))
+			if len(pending) > 0 {
+				message += fmt.Sprintf(", restart required: [%s]", strings.Join(pending, ", "))
+			}
+			event := &models.EventInfo{
+				UID:        fmt.Sprintf("config-%d", time.Now().UnixNano()),
+				ObjectKind: "Config",
+				ObjectName: configPath,
+				Type:       "Normal",
+				Reason:     "ConfigChanged",
+				Message:    message,
+				Source:     "k8s-llm-monitor",
+				Timestamp:  time.Now().UTC(),
+				Count:      1,
+			}
+			if err := history.Record(context.Background(), event); err != nil {
+				log.Printf("Failed to record config change event: %v", err)
+			}
+		}
+	}
+}
+
+// podFilter 将配置转换为Pod指标采集的过滤条件
+func podFilter(c config.PodFilterConfig) sources.PodFilter {
+	return sources.PodFilter{
+		LabelSelector: c.LabelSelector,
+		FieldSelector: c.FieldSelector,
+		Exclude:       c.Exclude,
+	}
+}
+
+// collectorIntervals 各采集器单独的采集间隔，node_latency 和 external 未在 metrics.intervals 中配置时使用各自的 interval
+func collectorIntervals(c config.MetricsConfig) map[string]time.Duration {
+	intervals := make(map[string]time.Duration, len(c.Intervals)+2)
+	for name, seconds := range c.Intervals {
+		intervals[name] = time.Duration(seconds) * time.Second
+	}
+	if _, ok := intervals[sources.CollectorNodeLatency]; !ok && c.NodeLatency.Enabled {
+		intervals[sources.CollectorNodeLatency] = time.Duration(c.NodeLatency.Interval) * time.Second
+	}
+	if _, ok := intervals[sources.CollectorExternal]; !ok && len(c.ExternalTargets.Targets) > 0 {
+		intervals[sources.CollectorExternal] = time.Duration(c.ExternalTargets.Interval) * time.Second
+	}
+	return intervals
+}
+
+// anomalyConfig 将配置转换为异常检测参数
+func anomalyConfig(c config.AnomaliesConfig) metrics.AnomalyConfig {
+	return metrics.AnomalyConfig{
+		ZThreshold:   c.ZThreshold,
+		Alpha:        c.Alpha,
+		MinSamples:   c.MinSamples,
+		ResolveAfter: c.ResolveAfter,
+	}
+}
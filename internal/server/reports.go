@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/reports"
+)
+
+// errReportsUnavailable 未启用 analysis.reports 或计划表达式无效
+var errReportsUnavailable = &httpjson.Error{
+	Status:  http.StatusServiceUnavailable,
+	Code:    "reports_disabled",
+	Message: "daily reports are not enabled (requires analysis.reports.enabled)",
+}
+
+// reportGenerateRequest 手动生成报告的请求体
+type reportGenerateRequest struct {
+	Date    string `json:"date"`    // YYYY-MM-DD（UTC），默认昨天
+	Profile string `json:"profile"` // LLM配置名
+}
+
+// reportsHandler GET /api/v1/reports 报告列表（参数 from、to、limit），
+// POST 立即生成（或重新生成）指定日期的报告；当天的报告标记为 partial
+func reportsHandler(generator *reports.Generator, service *llm.Service, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if generator == nil {
+			httpjson.WriteError(w, errReportsUnavailable)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var request reportGenerateRequest
+			if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+			day := time.Now().UTC().AddDate(0, 0, -1)
+			if request.Date != "" {
+				parsed, err := time.Parse(reports.DateLayout, request.Date)
+				if err != nil {
+					httpjson.WriteError(w, httpjson.BadRequest("invalid date %q (expected YYYY-MM-DD)", request.Date))
+					return
+				}
+				day = parsed
+			}
+			// LLM未配置时仍生成只含统计数据的报告，只拒绝不存在的配置名
+			if _, err := service.Client(reports.TypeReport, request.Profile); errors.Is(err, config.ErrUnknownProfile) {
+				writeLLMError(w, err)
+				return
+			}
+
+			extendWriteDeadline(w, llmWriteTimeout)
+			report, err := generator.Generate(r.Context(), day, request.Profile, reports.TriggerManual)
+			if err != nil {
+				writeReportError(w, err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"data":      report,
+				"timestamp": time.Now().UTC(),
+			})
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		limit, err := parseLimitParam(r, 30)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		list, err := generator.List(r.Context(), reports.Filter{From: from, To: to, Limit: limit})
+		if err != nil {
+			writeReportError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      list,
+			"count":     len(list),
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
+
+// reportRouter GET /api/v1/reports/{id}，id 为 report-YYYY-MM-DD 或日期；
+// ?format=markdown 或 Accept: text/markdown 时返回Markdown
+func reportRouter(generator *reports.Generator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/reports/"), "/")
+		if id == "" || strings.Contains(id, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if generator == nil {
+			httpjson.WriteError(w, errReportsUnavailable)
+			return
+		}
+
+		report, err := generator.Get(r.Context(), id)
+		if err != nil {
+			writeReportError(w, err)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		switch {
+		case format == "markdown" || format == "md" || (format == "" && strings.Contains(r.Header.Get("Accept"), "text/markdown")):
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Write([]byte(report.Markdown()))
+		case format == "" || format == "json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":    "success",
+				"data":      report,
+				"timestamp": time.Now().UTC(),
+			})
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("unsupported format %q (json, markdown)", format))
+		}
+	}
+}
+
+// writeReportError 将报告生成器的错误映射为HTTP状态码
+func writeReportError(w http.ResponseWriter, err error) {
+	apiErr := &httpjson.Error{Status: http.StatusInternalServerError, Code: "storage_error", Message: err.Error()}
+	switch {
+	case errors.Is(err, reports.ErrNotFound):
+		apiErr.Status, apiErr.Code = http.StatusNotFound, "not_found"
+	case errors.Is(err, reports.ErrFutureDate):
+		apiErr = httpjson.BadRequest("%s", err.Error())
+	}
+	httpjson.WriteError(w, apiErr)
+}
@@ -0,0 +1,1462 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/analysis"
+	"github.com/yourusername/k8s-llm-monitor/internal/archive"
+	"github.com/yourusername/k8s-llm-monitor/internal/assistant"
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/autofix"
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/events"
+	"github.com/yourusername/k8s-llm-monitor/internal/federation"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/incidents"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/llm"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/mtls"
+	"github.com/yourusername/k8s-llm-monitor/internal/netprobe"
+	"github.com/yourusername/k8s-llm-monitor/internal/probe"
+	"github.com/yourusername/k8s-llm-monitor/internal/prompts"
+	"github.com/yourusername/k8s-llm-monitor/internal/rag"
+	"github.com/yourusername/k8s-llm-monitor/internal/remotewrite"
+	"github.com/yourusername/k8s-llm-monitor/internal/reports"
+	"github.com/yourusername/k8s-llm-monitor/internal/reportsig"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/telemetry"
+	"github.com/yourusername/k8s-llm-monitor/internal/tracing"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Options 与运行方式相关、不属于配置文件的启动参数
+type Options struct {
+	ConfigSource       string                  // 配置来源描述（文件路径或ConfigMap），记录在配置变更的审计日志中
+	ConfigMap          *config.ConfigMapSource // 从ConfigMap加载配置时监听其变更，否则监听配置文件
+	DisableConfigWatch bool                    // 不监听配置变更（嵌入测试时使用）
+}
+
+// Server 按配置组装的API服务器：存储、K8s客户端、指标采集、后台任务和HTTP路由
+type Server struct {
+	cfg            *config.Config
+	handler        http.Handler
+	httpServer     *http.Server
+	debugServer    *http.Server
+	tlsConfig      *tls.Config
+	metricsManager *metrics.Manager
+
+	cancel  context.CancelFunc // 停止后台任务
+	closers []func()           // 关闭时按相反顺序执行
+}
+
+// New 按配置创建各组件并启动后台任务（事件监听、指标采集、数据保留等），
+// 不监听端口：由 ListenAndServe 启动服务，或通过 Handler 嵌入其他服务器。
+// K8s或可选组件不可用时降级运行，只有配置错误时返回错误
+func New(cfg *config.Config, opts Options) (*Server, error) {
+	// 后台任务的生命周期上下文
+	appCtx, appCancel := context.WithCancel(context.Background())
+	s := &Server{cfg: cfg, cancel: appCancel}
+	started := false
+	defer func() {
+		if !started {
+			s.cancel()
+			s.close()
+		}
+	}()
+
+	log.Printf("Starting K8s LLM Monitor...")
+	log.Printf("Server: %s:%d", cfg.Server.Host, cfg.Server.Port)
+	log.Printf("K8s Namespace: %s", cfg.K8s.Namespace)
+	log.Printf("LLM Provider: %s", cfg.LLM.Provider)
+
+	// LLM客户端（按分析类型路由到命名配置）
+	llmService := llm.NewService(cfg.LLM)
+	if _, err := llmService.Client("", ""); err != nil {
+		log.Printf("Warning: LLM not available, analysis falls back to rule-based results: %v", err)
+	}
+	promptRegistry, err := prompts.NewRegistry(cfg.Analysis.Prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	// 链路追踪
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "k8s-llm-monitor")
+	if err != nil {
+		log.Printf("Warning: Failed to set up tracing: %v", err)
+	} else if cfg.Tracing.Enabled {
+		log.Printf("Tracing enabled (endpoint: %s, sample ratio: %.2f)", cfg.Tracing.Endpoint, cfg.Tracing.SampleRatio)
+	}
+	s.closers = append(s.closers, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	})
+
+	// 初始化持久化存储
+	store, err := storage.New(&cfg.Storage)
+	if err != nil {
+		log.Printf("Warning: Failed to create %s storage: %v, falling back to memory", cfg.Storage.Type, err)
+		store = storage.NewMemoryStore()
+	}
+	s.closers = append(s.closers, func() { store.Close() })
+	log.Printf("Storage: %s", cfg.Storage.Type)
+	if health, ok := store.(storage.HealthReporter); ok && health.Health().Encrypted {
+		log.Printf("Storage encryption enabled (key %s)", health.Health().KeyID)
+	}
+	if err := llmService.SetStore(context.Background(), store); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// 审计日志
+	var auditLog *audit.Logger
+	if cfg.Audit.Enabled {
+		if auditLog, err = audit.NewLogger(store, cfg.Audit.Path); err != nil {
+			return nil, fmt.Errorf("failed to create audit log: %w", err)
+		}
+		s.closers = append(s.closers, func() { auditLog.Close() })
+	}
+
+	// 分析结果存储
+	analysisStore := analysis.NewStore(store)
+
+	// UAV遥测历史
+	uavHistory := telemetry.NewUAVHistory(store, time.Duration(cfg.Storage.UAVHistoryStep)*time.Second)
+
+	// 数据保留与压缩任务
+	var retentionJob *storage.RetentionJob
+	if retentionCfg := cfg.Storage.Retention; retentionCfg.Enabled && len(retentionCfg.Policies) > 0 {
+		policies := make([]storage.RetentionPolicy, 0, len(retentionCfg.Policies))
+		for _, p := range retentionCfg.Policies {
+			policies = append(policies, storage.RetentionPolicy{
+				Collection:      p.Collection,
+				MaxAge:          time.Duration(p.MaxAge) * time.Hour,
+				DownsampleAfter: time.Duration(p.DownsampleAfter) * time.Hour,
+				DownsampleStep:  time.Duration(p.DownsampleStep) * time.Second,
+			})
+		}
+		retentionJob = storage.NewRetentionJob(store, policies, time.Duration(retentionCfg.Interval)*time.Second)
+		go retentionJob.Start(appCtx)
+	}
+
+	// 归档导出（S3兼容对象存储）
+	if archiveCfg := cfg.Storage.Archive; archiveCfg.Enabled {
+		s3Client, err := archive.NewS3Client(archive.S3Options{
+			Endpoint:  archiveCfg.Endpoint,
+			Region:    archiveCfg.Region,
+			Bucket:    archiveCfg.Bucket,
+			AccessKey: archiveCfg.AccessKey,
+			SecretKey: archiveCfg.SecretKey,
+			PathStyle: archiveCfg.PathStyle,
+		})
+		if err != nil {
+			log.Printf("Warning: Archive export disabled: %v", err)
+		} else {
+			exporter := archive.NewExporter(store, s3Client, archive.ExporterConfig{
+				Prefix:       archiveCfg.Prefix,
+				Collections:  archiveCfg.Collections,
+				Interval:     time.Duration(archiveCfg.Interval) * time.Second,
+				LookbackDays: archiveCfg.LookbackDays,
+			})
+			go exporter.Start(appCtx)
+			log.Printf("Archive export enabled: bucket=%s prefix=%s", archiveCfg.Bucket, archiveCfg.Prefix)
+		}
+	}
+
+	// 组件间mTLS
+	serverTLS, agentClient, err := setupTLS(&cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up mTLS: %w", err)
+	}
+	if serverTLS != nil {
+		log.Printf("mTLS enabled (client_auth: %s, agent SANs: %v)", cfg.TLS.ClientAuth, cfg.TLS.AgentSANs)
+	}
+
+	// 1. 初始化K8s客户端
+	var k8sClient *k8s.Client
+	var metricsManager *metrics.Manager
+	var eventHistory *events.History
+	var eventClassifier *events.Classifier
+
+	if client, err := k8s.NewClient(&cfg.K8s); err != nil {
+		log.Printf("Warning: Failed to create k8s client: %v", err)
+		log.Printf("Running in development mode without K8s connection")
+	} else {
+		// 测试K8s连接
+		if err := client.TestConnection(); err != nil {
+			log.Printf("Warning: Failed to connect to k8s: %v", err)
+			log.Printf("Running in development mode without K8s connection")
+		} else {
+			k8sClient = client
+			log.Printf("Successfully connected to Kubernetes cluster")
+
+			// 记录K8s事件历史
+			eventHistory = events.NewHistory(store, time.Duration(cfg.Monitoring.EventRetention)*time.Hour)
+			go eventHistory.Start(appCtx)
+			var eventHandler k8s.EventHandler = eventHistory
+			// 事件分类：记录前标记为 noise、informational 或 actionable
+			if cfg.Monitoring.EventFilter.Enabled {
+				eventClassifier = events.NewClassifier(cfg.Monitoring.EventFilter, eventHistory, llmService, promptRegistry)
+				go eventClassifier.Start(appCtx)
+				eventHandler = eventClassifier
+			}
+			if err := k8sClient.WatchResources(appCtx, eventHandler); err != nil {
+				log.Printf("Warning: Failed to start event watcher: %v", err)
+			}
+
+			// 2. 初始化指标采集管理器
+			if cfg.Metrics.Enabled {
+				restConfig, err := clientcmd.BuildConfigFromFlags("", cfg.K8s.Kubeconfig)
+				if err != nil {
+					log.Printf("Warning: Failed to create rest config: %v", err)
+				} else {
+					managerConfig := metrics.ManagerConfig{
+						Namespaces:           cfg.Metrics.Namespaces,
+						CollectInterval:      time.Duration(cfg.Metrics.CollectInterval) * time.Second,
+						EnableNode:           cfg.Metrics.EnableNode,
+						EnablePod:            cfg.Metrics.EnablePod,
+						EnableNetwork:        cfg.Metrics.EnableNetwork,
+						EnableCustom:         cfg.Metrics.EnableCustom,
+						EnableGPU:            cfg.Metrics.GPU.Enabled,
+						EnableSummary:        cfg.Metrics.EnableSummary,
+						EnableUAV:            true, // 启用UAV指标采集
+						NetworkMaxPairs:      cfg.Metrics.Network.MaxPairs,
+						NetworkStrategy:      cfg.Metrics.Network.Strategy,
+						NetworkSeed:          cfg.Metrics.Network.Seed,
+						NetworkQualityWindow: cfg.Metrics.Network.QualityWindow,
+						NetworkDegradation:   cfg.Metrics.Network.DegradationThreshold,
+						NetworkTestTimeout:   10 * time.Second,
+						NetworkConcurrency:   cfg.Metrics.Network.Concurrency,
+						NetworkBudget:        time.Duration(cfg.Metrics.Network.CycleBudget) * time.Second,
+						K8sClient:            k8sClient, // 传递K8s client用于网络测试
+						Store:                store,
+						PersistInterval:      time.Duration(cfg.Storage.SnapshotInterval) * time.Second,
+						HistoryRetention:     time.Duration(cfg.Metrics.CacheRetention) * time.Second,
+						ShareState:           strings.EqualFold(cfg.Storage.Type, "redis") && cfg.Storage.Redis.ShareState,
+						SyncInterval:         time.Duration(cfg.Storage.Redis.SyncInterval) * time.Second,
+						UAVHTTPClient:        agentClient,
+					}
+					managerConfig.PodFilter = podFilter(cfg.Metrics.PodFilter)
+					managerConfig.Jitter = cfg.Metrics.Jitter
+					managerConfig.CollectorIntervals = collectorIntervals(cfg.Metrics)
+					for _, r := range cfg.Metrics.CustomResources {
+						managerConfig.CustomResources = append(managerConfig.CustomResources, sources.CustomResource{
+							Group:       r.Group,
+							Version:     r.Version,
+							Resource:    r.Resource,
+							Namespace:   r.Namespace,
+							NodeField:   r.NodeField,
+							ValuesField: r.ValuesField,
+							Fields:      r.Fields,
+						})
+					}
+					if cfg.Metrics.GPU.Enabled {
+						managerConfig.GPU = sources.GPUCollectorConfig{
+							Namespace: cfg.Metrics.GPU.Namespace,
+							Selector:  cfg.Metrics.GPU.Selector,
+							Port:      cfg.Metrics.GPU.Port,
+							Endpoints: cfg.Metrics.GPU.Endpoints,
+							Timeout:   time.Duration(cfg.Metrics.GPU.Timeout) * time.Second,
+						}
+					}
+					if cfg.Metrics.Anomalies.Enabled {
+						anomalies := anomalyConfig(cfg.Metrics.Anomalies)
+						managerConfig.Anomalies = &anomalies
+					}
+
+					if rw := cfg.Metrics.RemoteWrite; rw.Enabled {
+						managerConfig.RemoteWrite = &remotewrite.Config{
+							URL:            rw.URL,
+							Headers:        rw.Headers,
+							BearerToken:    rw.BearerToken,
+							Username:       rw.Username,
+							Password:       rw.Password,
+							ExternalLabels: rw.ExternalLabels,
+							BatchSize:      rw.BatchSize,
+							QueueSize:      rw.QueueSize,
+							FlushInterval:  time.Duration(rw.FlushInterval) * time.Second,
+							Timeout:        time.Duration(rw.Timeout) * time.Second,
+							MaxBackoff:     time.Duration(rw.MaxBackoff) * time.Second,
+						}
+					}
+					if cfg.Metrics.Rollup.Enabled {
+						managerConfig.RollupInterval = time.Duration(cfg.Metrics.Rollup.Interval) * time.Second
+					}
+					for _, slo := range cfg.Metrics.SLOs {
+						managerConfig.SLOs = append(managerConfig.SLOs, metrics.SLODefinition{
+							Name:        slo.Name,
+							Description: slo.Description,
+							Target:      slo.Target,
+							Match:       slo.Match,
+							Metric:      slo.Metric,
+							Operator:    slo.Operator,
+							Threshold:   slo.Threshold,
+							Objective:   slo.Objective,
+							Window:      time.Duration(slo.Window) * time.Hour,
+						})
+					}
+
+					if st := cfg.Metrics.UAVStaleness; st.Enabled {
+						managerConfig.UAVStaleness = &metrics.UAVStalenessConfig{
+							StaleAfter:    st.StaleAfter,
+							LostAfter:     st.LostAfter,
+							CheckInterval: time.Duration(st.CheckInterval) * time.Second,
+						}
+					}
+					if bw := cfg.Metrics.Bandwidth; bw.Enabled {
+						managerConfig.NetworkBandwidth = &k8s.BandwidthOptions{
+							Server:   bw.Server,
+							Image:    bw.Image,
+							Port:     bw.Port,
+							Duration: time.Duration(bw.Duration) * time.Second,
+						}
+					}
+					if pa := cfg.Metrics.ProbeAgents; pa.Enabled {
+						managerConfig.NetworkAgents = &sources.ProbeAgentConfig{
+							Namespace: pa.Namespace,
+							Port:      pa.Port,
+							Token:     pa.Token,
+							DNSName:   pa.DNSName,
+						}
+						k8sClient.SetProbeAgents(pa.Namespace, probe.NewClient(pa.Port, pa.Token))
+						if pa.Manage {
+							ensureProbeAgents(k8sClient, pa)
+						}
+					}
+					if nl := cfg.Metrics.NodeLatency; nl.Enabled && managerConfig.NetworkAgents != nil {
+						managerConfig.NodeLatency = &sources.NodeLatencyConfig{
+							Agents:      *managerConfig.NetworkAgents,
+							Count:       nl.Count,
+							Concurrency: nl.Concurrency,
+						}
+					}
+					if et := cfg.Metrics.ExternalTargets; len(et.Targets) > 0 && managerConfig.NetworkAgents != nil {
+						targets := make([]sources.ExternalTarget, len(et.Targets))
+						for i, t := range et.Targets {
+							targets[i] = sources.ExternalTarget{Name: t.Name, URL: t.URL, ExpectStatus: t.ExpectStatus}
+						}
+						managerConfig.ExternalTargets = &sources.ExternalTargetsConfig{
+							Agents:      *managerConfig.NetworkAgents,
+							Targets:     targets,
+							Timeout:     time.Duration(et.Timeout) * time.Second,
+							Concurrency: et.Concurrency,
+						}
+					}
+
+					manager, err := metrics.NewManager(restConfig, managerConfig)
+					if err != nil {
+						log.Printf("Warning: Failed to create metrics manager: %v", err)
+					} else {
+						metricsManager = manager
+						log.Printf("Metrics manager created successfully")
+
+						// UAV心跳超时或恢复时更新UAVMetric状态并记录K8s事件
+						metricsManager.OnUAVStatusChange(uavStatusRecorder(k8sClient, auditLog))
+						// UAV越出或回到地理围栏时更新UAVMetric状态并记录K8s事件
+						metricsManager.OnGeofenceChange(uavGeofenceRecorder(k8sClient, auditLog))
+						if cfg.Metrics.Network.ExportCRD {
+							metricsManager.OnNetworkMetrics(networkProbeResultExporter(k8sClient, cfg.Metrics.Network, auditLog))
+						}
+
+						// 启动指标采集
+						go func() {
+							if err := metricsManager.Start(appCtx); err != nil {
+								log.Printf("Metrics manager stopped: %v", err)
+							}
+						}()
+						log.Printf("Metrics collection started (interval: %d seconds)", cfg.Metrics.CollectInterval)
+					}
+				}
+			} else {
+				log.Printf("Metrics collection is disabled in config")
+			}
+		}
+	}
+
+	// 事件关联与故障分组（依赖事件历史）
+	var incidentEngine *incidents.Engine
+	if eventHistory != nil && cfg.Analysis.Incidents.Enabled {
+		incidentEngine = incidents.NewEngine(cfg.Analysis.Incidents, eventHistory, store, metricsManager, llmService, promptRegistry)
+		go incidentEngine.Start(appCtx)
+	}
+
+	// 定时连通性探测（按配置中的源/目标定义，记录历史并检测抖动）
+	var probeScheduler *netprobe.Scheduler
+	if k8sClient != nil && len(cfg.Metrics.ConnectivityProbes.Probes) > 0 {
+		probeScheduler = netprobe.NewScheduler(cfg.Metrics.ConnectivityProbes, k8sClient, store)
+		go probeScheduler.Start(appCtx)
+	}
+
+	// 监听配置文件变更，热更新可安全修改的配置项
+	configWatcher := config.NewWatcher(cfg)
+	configWatcher.OnChange(configReloader(opts.ConfigSource, metricsManager, eventHistory, eventClassifier, auditLog, llmService, promptRegistry))
+	switch {
+	case opts.DisableConfigWatch:
+	case opts.ConfigMap != nil && opts.ConfigMap.Name != "":
+		if err := configWatcher.WatchConfigMap(appCtx, opts.ConfigMap); err != nil {
+			log.Printf("Warning: Failed to watch config ConfigMap: %v", err)
+		}
+	default:
+		configWatcher.Start()
+	}
+
+	// 3. 设置HTTP路由
+	mux := http.NewServeMux()
+
+	// 静态文件服务（Web界面）
+	mux.Handle("/", http.FileServer(http.Dir("./web/")))
+
+	// 健康检查接口
+	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/readyz", readyzHandler(store, k8sClient))
+
+	// 集群状态接口
+	mux.HandleFunc("/api/v1/cluster/status", clusterStatusHandler(k8sClient))
+
+	// Pod列表接口
+	mux.HandleFunc("/api/v1/pods", podsHandler(k8sClient))
+	mux.HandleFunc("/api/v1/pods/", podRouter(k8sClient, llmService, promptRegistry))
+
+	// 事件历史接口
+	mux.HandleFunc("/api/v1/events/history", eventHistoryHandler(eventHistory))
+	mux.HandleFunc("/api/v1/events/classifier", eventClassifierHandler(eventClassifier))
+
+	// Pod通信分析接口
+	mux.HandleFunc("/api/v1/analyze/pod-communication", podCommunicationHandler(k8sClient, llmService, promptRegistry, analysisStore, cfg.Server.MaxBodyBytes))
+	// Ingress/LoadBalancer链路分析：从外部地址追踪到后端Pod
+	mux.HandleFunc("/api/v1/analyze/ingress", ingressAnalysisHandler(k8sClient, cfg.Server.MaxBodyBytes))
+	// 网络策略建议：根据现有策略生成实现期望连通性的NetworkPolicy
+	mux.HandleFunc("/api/v1/network-policies/suggest", networkPolicySuggestHandler(k8sClient, llmService, promptRegistry, cfg.Server.MaxBodyBytes))
+
+	// LLM根因分析接口
+	analysisSources := analysis.Sources{Metrics: metricsManager, K8s: k8sClient, Events: eventHistory}
+
+	// 历史检索：定期为指标快照、故障和UAV遥测生成摘要，分析时检索相似的历史情况
+	var ragIndex *rag.Index
+	if cfg.Analysis.RAG.Enabled {
+		index, err := rag.New(appCtx, cfg.Analysis.RAG, store, llmService, analysisSources, incidentEngine)
+		if err != nil {
+			log.Printf("Warning: RAG index disabled: %v", err)
+		} else {
+			ragIndex = index
+			analysisSources.History = index
+			go index.Start(appCtx)
+		}
+	}
+	// 异常检测：新异常由LLM自动分级（可选）
+	if cfg.Metrics.Anomalies.Triage && metricsManager != nil && metricsManager.Anomalies() != nil {
+		metricsManager.Anomalies().OnDetect(func(ctx context.Context, list []*metrics.Anomaly) {
+			ctx, cancel := context.WithTimeout(ctx, llmWriteTimeout)
+			defer cancel()
+			if err := analysis.TriageAnomalies(ctx, llmService, promptRegistry, analysisSources, list, ""); err != nil && !errors.Is(err, llm.ErrNotConfigured) && !errors.Is(err, llm.ErrBudgetExceeded) {
+				log.Printf("Warning: Failed to triage %d anomalies: %v", len(list), err)
+			}
+		})
+	}
+	// 每日报告：按计划汇总前一天的数据，由LLM生成摘要
+	var reportGenerator *reports.Generator
+	if cfg.Analysis.Reports.Enabled {
+		generator, err := reports.New(cfg.Analysis.Reports, store, llmService, promptRegistry, analysisSources, incidentEngine)
+		if err != nil {
+			log.Printf("Warning: Daily reports disabled: %v", err)
+		} else {
+			reportGenerator = generator
+			go generator.Start(appCtx)
+		}
+	}
+	// 指标解释：读取最近的序列，由LLM说明含义和是否值得关注
+	mux.HandleFunc("/api/v1/explain", explainHandler(store, llmService, promptRegistry, analysisSources))
+	mux.HandleFunc("/api/v1/analyze/root-cause", rootCauseHandler(llmService, promptRegistry, analysisSources, analysisStore, cfg.Server.MaxBodyBytes))
+
+	// 修复建议：根据分析结果生成预演的修复计划，确认后才执行
+	var autofixEngine *autofix.Engine
+	if cfg.Analysis.EnableAutoFix {
+		autofixEngine = autofix.NewEngine(analysisStore, k8sClient, store, auditLog)
+	}
+
+	// 自然语言查询接口（模型通过只读工具查询集群状态，每次工具调用记录到审计日志）
+	queryTools := assistant.NewToolset(assistant.ClusterTools(analysisSources)...)
+	queryTools.SetAudit(auditLog)
+	mux.HandleFunc("/api/v1/query", queryHandler(llmService, queryTools, analysisSources, cfg.Server.MaxBodyBytes))
+
+	// 多轮对话接口（保存对话历史，每轮注入最新的集群现状）
+	chatSessions := assistant.NewSessions(store, llmService, queryTools, analysisSources)
+	mux.HandleFunc("/api/v1/chat/sessions", chatSessionsHandler(chatSessions, cfg.Server.MaxBodyBytes))
+	mux.HandleFunc("/api/v1/chat/sessions/", chatSessionRouter(chatSessions, cfg.Server.MaxBodyBytes))
+
+	// 分析结果查询接口
+	mux.HandleFunc("/api/v1/storage/retention", retentionStatsHandler(retentionJob))
+
+	// 管理接口：备份与恢复（未配置 server.admin_token 时全部返回403）
+	if cfg.Server.AdminToken == "" {
+		log.Printf("server.admin_token is not configured, admin endpoints are disabled")
+	}
+	mux.HandleFunc("/api/v1/admin/backup", requireAdmin(cfg.Server.AdminToken, backupHandler(store, cfg)))
+	mux.HandleFunc("/api/v1/admin/restore", requireAdmin(cfg.Server.AdminToken, restoreHandler(store, auditLog)))
+	mux.HandleFunc("/api/v1/audit", requireAdmin(cfg.Server.AdminToken, auditHandler(auditLog)))
+	mux.HandleFunc("/api/v1/llm/profiles", llmProfilesHandler(configWatcher))
+	mux.HandleFunc("/api/v1/prompts", promptsHandler(promptRegistry))
+	mux.HandleFunc("/api/v1/prompts/reload", requireAdmin(cfg.Server.AdminToken, promptsReloadHandler(promptRegistry, auditLog)))
+	mux.HandleFunc("/api/v1/llm/status", llmStatusHandler(llmService))
+	mux.HandleFunc("/api/v1/llm/usage", llmUsageHandler(llmService))
+	mux.HandleFunc("/api/v1/llm/cache", llmCacheHandler(llmService, cfg.Server.AdminToken, auditLog))
+	mux.HandleFunc("/api/v1/llm/complete", requireAdmin(cfg.Server.AdminToken, llmCompleteHandler(llmService, cfg.Server.MaxBodyBytes)))
+	mux.HandleFunc("/api/v1/admin/config", requireAdmin(cfg.Server.AdminToken, configDumpHandler(configWatcher)))
+	mux.HandleFunc("/api/v1/analyses", analysesHandler(analysisStore))
+	mux.HandleFunc("/api/v1/analyses/", analysisHandler(analysisStore))
+	mux.HandleFunc("/api/v1/incidents", incidentsHandler(incidentEngine))
+	mux.HandleFunc("/api/v1/incidents/", incidentRouter(incidentEngine))
+	mux.HandleFunc("/api/v1/autofix/plans", autofixPlansHandler(autofixEngine, cfg.Server.MaxBodyBytes))
+	mux.HandleFunc("/api/v1/autofix/plans/", autofixPlanRouter(autofixEngine, cfg.Server.AdminToken))
+	mux.HandleFunc("/api/v1/anomalies", anomaliesHandler(metricsManager))
+	mux.HandleFunc("/api/v1/anomalies/", anomalyRouter(metricsManager, llmService, promptRegistry, analysisSources))
+	mux.HandleFunc("/api/v1/reports", reportsHandler(reportGenerator, llmService, cfg.Server.MaxBodyBytes))
+	mux.HandleFunc("/api/v1/reports/", reportRouter(reportGenerator))
+	mux.HandleFunc("/api/v1/rag/search", ragSearchHandler(ragIndex))
+	mux.HandleFunc("/api/v1/rag/status", ragStatusHandler(ragIndex))
+
+	// === 新增：指标相关接口 ===
+	// 集群整体指标
+	mux.HandleFunc("/api/v1/metrics/cluster", metricsClusterHandler(metricsManager))
+
+	// Prometheus抓取接口
+	mux.HandleFunc("/metrics", prometheusHandler(metricsManager))
+
+	// 采集器状态与缺少的RBAC权限
+	mux.HandleFunc("/api/v1/metrics/status", metricsStatusHandler(metricsManager))
+
+	// 所有节点指标
+	mux.HandleFunc("/api/v1/metrics/nodes", metricsNodesHandler(metricsManager))
+
+	// 单个节点指标
+	mux.HandleFunc("/api/v1/metrics/nodes/", metricsNodeHandler(metricsManager))
+
+	// 所有Pod指标
+	mux.HandleFunc("/api/v1/metrics/pods", metricsPodsHandler(metricsManager))
+
+	// 完整快照
+	mux.HandleFunc("/api/v1/metrics/snapshot", metricsSnapshotHandler(metricsManager))
+
+	// 网络指标
+	mux.HandleFunc("/api/v1/metrics/network", metricsNetworkHandler(metricsManager))
+
+	// 节点间时延矩阵
+	mux.HandleFunc("/api/v1/metrics/network/nodes", metricsNodeLatencyHandler(metricsManager))
+	// 按需测试指定Pod对的连通性（exec进入Pod，需要管理员Token）
+	mux.HandleFunc("/api/v1/metrics/network/test", requireAdmin(cfg.Server.AdminToken, metricsNetworkTestHandler(metricsManager, cfg.Server.MaxBodyBytes)))
+
+	// 实时指标推送（WebSocket）
+	mux.HandleFunc("/api/v1/metrics/stream", metricsStreamHandler(metricsManager, cfg.Server.AllowedOrigins))
+
+	// 历史指标聚合查询
+	mux.HandleFunc("/api/v1/metrics/query", metricsQueryHandler(store))
+
+	// 内存中的近期指标序列（用于绘制图表）
+	mux.HandleFunc("/api/v1/metrics/history", metricsHistoryHandler(metricsManager))
+
+	// 两个时间点之间的快照差异
+	mux.HandleFunc("/api/v1/metrics/diff", metricsDiffHandler(metricsManager))
+
+	// 资源效率报告（请求 vs 实际用量）
+	mux.HandleFunc("/api/v1/metrics/efficiency", metricsEfficiencyHandler(metricsManager))
+
+	// 容器重启和OOMKill历史
+	mux.HandleFunc("/api/v1/metrics/restarts", metricsRestartsHandler(metricsManager))
+
+	// Pod对链路质量趋势（抖动、质量评分与24小时基线）
+	mux.HandleFunc("/api/v1/metrics/network/quality", metricsLinkQualityHandler(metricsManager))
+
+	// 节点条件变化时间线
+	mux.HandleFunc("/api/v1/nodes/", nodeRouter(metricsManager))
+
+	// SLO达标率和错误预算
+	mux.HandleFunc("/api/v1/slo", sloHandler(metricsManager))
+
+	// UAV指标
+	mux.HandleFunc("/api/v1/metrics/uav", metricsUAVHandler(metricsManager))
+	mux.HandleFunc("/api/v1/metrics/uav/", metricsUAVNodeHandler(metricsManager))
+
+	// UAV数据上报接口
+	var agentNodeSANs []string
+	if serverTLS != nil {
+		agentNodeSANs = cfg.TLS.AgentNodeSANs
+	}
+	reportHandler := uavReportHandler(metricsManager, k8sClient, uavHistory, auditLog, agentNodeSANs, cfg.Server.MaxBodyBytes)
+	if signingCfg := cfg.UAV.ReportSigning; signingCfg.Enabled {
+		verifier, err := reportsig.NewVerifier(reportsig.VerifierOptions{
+			MasterKey:          signingCfg.MasterKey,
+			PreviousMasterKeys: signingCfg.PreviousMasterKeys,
+			Keys:               signingCfg.Keys,
+			Required:           signingCfg.Required,
+			MaxSkew:            time.Duration(signingCfg.MaxSkew) * time.Second,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure UAV report signing: %w", err)
+		}
+		reportHandler = verifier.Middleware(cfg.Server.MaxBodyBytes, reportHandler)
+		log.Printf("UAV report signing enabled (required: %v)", signingCfg.Required)
+	}
+	if serverTLS != nil {
+		// 启用mTLS时只接受SAN匹配的Agent证书
+		reportHandler = mtls.RequireSAN(cfg.TLS.AgentSANs, reportHandler)
+	}
+	mux.HandleFunc("/api/v1/uav/report", reportHandler)
+	// UAV遥测历史: /api/v1/uav/{node}/history；控制命令: /api/v1/uav/{node}/command/{command}（需要管理Token）
+	mux.HandleFunc("/api/v1/uav/", uavNodeRouter(uavHistory, uavCommandHandler(metricsManager, cfg.Server.AdminToken, auditLog, cfg.Server.MaxBodyBytes)))
+	// UAV CRD数据
+	mux.HandleFunc("/api/v1/crd/uav", uavCRDHandler(k8sClient))
+
+	// 连通性探测状态与历史
+	mux.HandleFunc("/api/v1/network/probes", networkProbesHandler(probeScheduler))
+	mux.HandleFunc("/api/v1/network/probes/", networkProbeHandler(probeScheduler))
+	// 网络拓扑图（Pod、Service、节点及观察到的连接）
+	mux.HandleFunc("/api/v1/network/topology", networkTopologyHandler(metricsManager, k8sClient, probeScheduler))
+	// 按NetworkPolicy计算的命名空间间可达性矩阵
+	mux.HandleFunc("/api/v1/network/reachability", networkReachabilityHandler(k8sClient))
+	// 路径MTU诊断（overlay MTU不匹配）
+	mux.HandleFunc("/api/v1/network/mtu", networkMTUHandler(k8sClient, cfg.Server.MaxBodyBytes))
+	mux.HandleFunc("/api/v1/network/external", networkExternalHandler(metricsManager))
+
+	// 拓扑图（Web界面的集群/机群地图）
+	mux.HandleFunc("/api/v1/topology/graph", topologyGraphHandler(metricsManager, k8sClient))
+
+	// 多集群联邦
+	if cfg.Federation.Enabled {
+		registry, err := federation.NewRegistry(appCtx, cfg.Federation, store, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure federation: %w", err)
+		}
+		mux.HandleFunc("/api/v1/federation/clusters", federationClustersHandler(registry, cfg.Server.AdminToken, auditLog, cfg.Server.MaxBodyBytes))
+		mux.HandleFunc("/api/v1/federation/clusters/", federationClusterHandler(registry, cfg.Server.AdminToken, auditLog))
+		mux.HandleFunc("/api/v1/federation/metrics/cluster", federationClusterMetricsHandler(registry, metricsManager))
+		mux.HandleFunc("/api/v1/federation/uav", federationUAVHandler(registry, metricsManager))
+		log.Printf("Federation enabled (cluster: %s, peers: %d)", registry.LocalName(), len(registry.Clusters())-1)
+	}
+
+	// 4. 创建HTTP服务器
+	s.handler = tracing.Handler(mux, "k8s-llm-monitor")
+	s.httpServer = &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		Handler:      s.handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		TLSConfig:    serverTLS,
+	}
+	s.tlsConfig = serverTLS
+	s.metricsManager = metricsManager
+
+	// 调试端口：pprof 和 expvar
+	if cfg.Server.Debug {
+		s.debugServer = startDebugServer(cfg.Server.DebugAddr, cfg.Server.AdminToken, metricsManager)
+	}
+
+	started = true
+	return s, nil
+}
+
+// Handler 所有HTTP路由（包含链路追踪中间件）
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// TLSConfig 服务端TLS配置，未启用mTLS时为nil
+func (s *Server) TLSConfig() *tls.Config {
+	return s.tlsConfig
+}
+
+// MetricsManager 指标采集管理器，未连接K8s或未启用指标采集时为nil
+func (s *Server) MetricsManager() *metrics.Manager {
+	return s.metricsManager
+}
+
+// ListenAndServe 在配置的地址上启动HTTP服务（配置了mTLS时为HTTPS），Shutdown 后返回 http.ErrServerClosed
+func (s *Server) ListenAndServe() error {
+	if s.tlsConfig != nil {
+		log.Printf("HTTPS Server starting on %s", s.httpServer.Addr)
+		return s.httpServer.ListenAndServeTLS("", "")
+	}
+	log.Printf("HTTP Server starting on %s", s.httpServer.Addr)
+	return s.httpServer.ListenAndServe()
+}
+
+// Shutdown 停止后台任务和HTTP服务，保存最新的指标状态后关闭存储等资源
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.cancel()
+	err := s.httpServer.Shutdown(ctx)
+	if s.debugServer != nil {
+		s.debugServer.Shutdown(ctx)
+	}
+
+	// 退出前保存最新状态，便于重启后快速恢复
+	if s.metricsManager != nil {
+		if err := s.metricsManager.PersistState(ctx); err != nil {
+			log.Printf("Failed to persist metrics state: %v", err)
+		}
+	}
+	s.close()
+	return err
+}
+
+// close 按创建的相反顺序释放资源
+func (s *Server) close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+	s.closers = nil
+}
+
+// healthHandler 健康检查处理函数
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"status":    "healthy",
+		"timestamp": time.Now().UTC(),
+		"version":   "1.0.0",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// clusterStatusHandler 集群状态处理函数
+func clusterStatusHandler(k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		// 检查K8s连接
+		if k8sClient == nil {
+			response := map[string]interface{}{
+				"status":    "warning",
+				"message":   "K8s client not available - running in development mode",
+				"timestamp": time.Now().UTC(),
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// 获取集群信息
+		clusterInfo, err := k8sClient.GetClusterInfo()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get cluster info: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":       "success",
+			"cluster_info": clusterInfo,
+			"timestamp":    time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// podCommunicationHandler Pod通信分析处理函数。规则检查完成后默认由LLM解读原始数据给出按置信度排序的诊断
+// （use_llm: false 关闭）；LLM未配置或调用失败时返回规则检查结论，失败原因见 llm_error。
+// 请求 Accept: text/event-stream（或 ?stream=true）时以SSE输出模型片段，最后输出 result 事件
+func podCommunicationHandler(k8sClient *k8s.Client, llmService *llm.Service, templates *prompts.Registry, results *analysis.Store, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// 检查K8s连接
+		if k8sClient == nil {
+			http.Error(w, "K8s client not available - running in development mode", http.StatusServiceUnavailable)
+			return
+		}
+
+		// 解析请求参数
+		var request struct {
+			PodA    string `json:"pod_a"`
+			PodB    string `json:"pod_b"`
+			UseLLM  *bool  `json:"use_llm"`
+			Profile string `json:"profile"`
+		}
+
+		if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		if err := validatePodRef("pod_a", request.PodA); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+		if err := validatePodRef("pod_b", request.PodB); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		useLLM := request.UseLLM == nil || *request.UseLLM
+		if useLLM {
+			extendWriteDeadline(w, llmWriteTimeout)
+		}
+
+		// 执行网络分析
+		networkAnalyzer := k8s.NewNetworkAnalyzer(k8sClient)
+		result, err := networkAnalyzer.AnalyzePodCommunication(r.Context(), request.PodA, request.PodB)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Analysis failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		var stream *sseWriter
+		if useLLM {
+			interpret := analysis.InterpretRequest{Profile: request.Profile}
+			if wantsStream(r) {
+				stream = newSSEWriter(w)
+				interpret.Stream = stream.Token
+			}
+			err := analysis.InterpretCommunication(r.Context(), llmService, templates, result, interpret)
+			switch {
+			case err == nil:
+			case errors.Is(err, config.ErrUnknownProfile),
+				errors.Is(err, llm.ErrBudgetExceeded) && !llmService.DegradeOnBudget():
+				httpjson.WriteError(w, llmError(err))
+				return
+			case errors.Is(err, llm.ErrNotConfigured) && request.UseLLM == nil:
+				// 未配置LLM且未明确要求时直接返回规则检查结论
+			default:
+				log.Printf("LLM interpretation of %s -> %s failed, using rule-based result: %v", request.PodA, request.PodB, err)
+				result.LLMError = err.Error()
+			}
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"analysis":  result,
+			"timestamp": time.Now().UTC(),
+		}
+
+		// 保存分析结果，便于事后查询
+		summary := result.Summary
+		if summary == "" {
+			summary = strings.Join(result.Issues, "; ")
+		}
+		record, err := results.Save(r.Context(), analysis.TypePodCommunication, result.Status, summary,
+			[]string{request.PodA, request.PodB}, result)
+		if err != nil {
+			log.Printf("Failed to store pod communication analysis: %v", err)
+		} else {
+			response["analysis_id"] = record.ID
+		}
+
+		if stream != nil {
+			stream.Send(sseEventResult, response)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// podsHandler Pod列表处理函数
+func podsHandler(k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		// 检查K8s连接
+		if k8sClient == nil {
+			response := map[string]interface{}{
+				"status":    "warning",
+				"message":   "K8s client not available - running in development mode",
+				"pods":      []interface{}{},
+				"timestamp": time.Now().UTC(),
+			}
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// 获取所有监控命名空间的Pod
+		allPods := []*models.PodInfo{}
+		for _, namespace := range k8sClient.Namespaces() {
+			pods, err := k8sClient.GetPods(namespace)
+			if err != nil {
+				log.Printf("Failed to get pods from namespace %s: %v", namespace, err)
+				continue
+			}
+			allPods = append(allPods, pods...)
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"pods":      allPods,
+			"count":     len(allPods),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// === 指标相关处理函数 ===
+
+// metricsStatusHandler 指标采集状态处理函数（包含因权限不足而降级的采集器）
+func metricsStatusHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      manager.Status(),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsClusterHandler 集群整体指标处理函数
+func metricsClusterHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		cluster := manager.GetClusterMetrics()
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      cluster,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsNodesHandler 所有节点指标处理函数
+func metricsNodesHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		query, ordered, err := parseTopQuery(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		snapshot := manager.ViewSnapshot()
+
+		// 指定了 sort 或 limit 时按顺序返回列表，例如 ?sort=cpu_usage_rate&limit=5
+		if ordered {
+			nodes, total, err := metrics.TopNodes(snapshot.NodeMetrics, query)
+			if err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+				return
+			}
+			httpjson.WriteObject(w,
+				httpjson.Field{Key: "status", Value: "success"},
+				httpjson.Field{Key: "data", Value: nodes},
+				httpjson.Field{Key: "count", Value: len(nodes)},
+				httpjson.Field{Key: "total", Value: total},
+				httpjson.Field{Key: "timestamp", Value: snapshot.Timestamp},
+				httpjson.Field{Key: "collection", Value: snapshot.CollectionStatus.Source(sources.CollectorNode)},
+			)
+			return
+		}
+
+		httpjson.WriteObject(w,
+			httpjson.Field{Key: "status", Value: "success"},
+			httpjson.Field{Key: "data", Value: httpjson.Map(snapshot.NodeMetrics)},
+			httpjson.Field{Key: "count", Value: len(snapshot.NodeMetrics)},
+			httpjson.Field{Key: "timestamp", Value: snapshot.Timestamp},
+			httpjson.Field{Key: "collection", Value: snapshot.CollectionStatus.Source(sources.CollectorNode)},
+		)
+	}
+}
+
+// metricsNodeHandler 单个节点指标处理函数
+func metricsNodeHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		// 从URL路径中提取节点名称
+		nodeName := r.URL.Path[len("/api/v1/metrics/nodes/"):]
+		if nodeName == "" {
+			http.Error(w, "Node name is required", http.StatusBadRequest)
+			return
+		}
+
+		nodeMetrics, err := manager.GetNodeMetrics(nodeName)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Node not found: %v", err), http.StatusNotFound)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      nodeMetrics,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsPodsHandler 所有Pod指标处理函数
+func metricsPodsHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		query, ordered, err := parseTopQuery(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+
+		snapshot := manager.ViewSnapshot()
+
+		// 指定了 sort 或 limit 时按顺序返回列表，例如 ?sort=memory_usage&limit=20&namespace=default
+		if ordered {
+			pods, total, err := metrics.TopPods(snapshot.PodMetrics, query)
+			if err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+				return
+			}
+			httpjson.WriteObject(w,
+				httpjson.Field{Key: "status", Value: "success"},
+				httpjson.Field{Key: "data", Value: pods},
+				httpjson.Field{Key: "count", Value: len(pods)},
+				httpjson.Field{Key: "total", Value: total},
+				httpjson.Field{Key: "timestamp", Value: snapshot.Timestamp},
+				httpjson.Field{Key: "collection", Value: snapshot.CollectionStatus.Source(sources.CollectorPod)},
+			)
+			return
+		}
+
+		podMetrics := snapshot.PodMetrics
+		if query.Namespace != "" {
+			podMetrics = make(map[string]*metricstypes.PodMetrics)
+			for key, pod := range snapshot.PodMetrics {
+				if pod.Namespace == query.Namespace {
+					podMetrics[key] = pod
+				}
+			}
+		}
+
+		// 5k Pod 规模下逐个Pod编码写出，避免整体拷贝和一次性编码
+		httpjson.WriteObject(w,
+			httpjson.Field{Key: "status", Value: "success"},
+			httpjson.Field{Key: "data", Value: httpjson.Map(podMetrics)},
+			httpjson.Field{Key: "count", Value: len(podMetrics)},
+			httpjson.Field{Key: "timestamp", Value: snapshot.Timestamp},
+			httpjson.Field{Key: "collection", Value: snapshot.CollectionStatus.Source(sources.CollectorPod)},
+		)
+	}
+}
+
+// metricsSnapshotHandler 完整快照处理函数
+func metricsSnapshotHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		format, err := exportFormat(r)
+		if err != nil {
+			httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+			return
+		}
+		if format != formatJSON {
+			// CSV/Parquet每次导出一类对象，table 默认为 nodes
+			name := strings.TrimSpace(r.URL.Query().Get("table"))
+			if name == "" {
+				name = "nodes"
+			}
+			table, err := metrics.SnapshotTable(manager.ViewSnapshot(), manager.GetUAVMetrics(), name)
+			if err != nil {
+				httpjson.WriteError(w, httpjson.BadRequest("%s", err.Error()))
+				return
+			}
+			writeTable(w, format, "snapshot-"+name, table)
+			return
+		}
+
+		httpjson.WriteObject(w,
+			httpjson.Field{Key: "status", Value: "success"},
+			httpjson.Field{Key: "data", Value: metrics.SnapshotJSON(manager.ViewSnapshot())},
+		)
+	}
+}
+
+// metricsNetworkHandler 网络指标处理函数
+func metricsNetworkHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		networkMetrics := manager.GetNetworkMetrics()
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      networkMetrics,
+			"count":     len(networkMetrics),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsNodeLatencyHandler 节点间时延矩阵处理函数
+func metricsNodeLatencyHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		matrix := manager.GetNodeLatency()
+		if matrix == nil {
+			http.Error(w, "Node latency not measured (enable metrics.node_latency and metrics.probe_agents)", http.StatusNotFound)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      matrix,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsUAVHandler 所有UAV指标处理函数
+func metricsUAVHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		uavMetrics := manager.GetUAVMetrics()
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      uavMetrics,
+			"count":     len(uavMetrics),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// metricsUAVNodeHandler 单个节点UAV指标处理函数
+func metricsUAVNodeHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		// 从URL路径中提取节点名称
+		nodeName := r.URL.Path[len("/api/v1/metrics/uav/"):]
+		if nodeName == "" {
+			http.Error(w, "Node name is required", http.StatusBadRequest)
+			return
+		}
+
+		uavMetric, exists := manager.GetSingleUAVMetrics(nodeName)
+		if !exists {
+			http.Error(w, fmt.Sprintf("UAV not found on node: %s", nodeName), http.StatusNotFound)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      uavMetric,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// uavReportHandler UAV状态上报处理函数
+func uavReportHandler(manager *metrics.Manager, k8sClient *k8s.Client, history *telemetry.UAVHistory, auditLog *audit.Logger, agentNodeSANs []string, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		var report models.UAVReport
+		if err := httpjson.Decode(w, r, &report, maxBody); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		if err := validateUAVReport(&report); err != nil {
+			httpjson.WriteError(w, err)
+			return
+		}
+
+		// 签名密钥属于哪个节点，就只能上报该节点的数据
+		if signedNode, ok := reportsig.SignedNode(r.Context()); ok && signedNode != report.NodeName {
+			err := fmt.Errorf("report for node %s signed with key of node %s", report.NodeName, signedNode)
+			auditLog.Record(r.Context(), &audit.Entry{
+				Actor:      "uav-agent:" + signedNode,
+				RemoteAddr: audit.ClientAddr(r),
+				Action:     audit.ActionUAVReport,
+				Resource:   "uavmetrics/" + report.NodeName,
+				Outcome:    audit.OutcomeDenied,
+				Error:      err.Error(),
+			})
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusForbidden, Code: reportsig.CodeInvalidSignature, Message: err.Error()})
+			return
+		}
+
+		// 证书属于哪个节点，就只能上报该节点的数据
+		if sans, ok := mtls.PeerIdentity(r.Context()); ok && len(agentNodeSANs) > 0 && !mtls.MatchNode(sans, agentNodeSANs, report.NodeName) {
+			err := fmt.Errorf("report for node %s sent with certificate for %s", report.NodeName, strings.Join(sans, ","))
+			auditLog.Record(r.Context(), &audit.Entry{
+				Actor:      "uav-agent:" + strings.Join(sans, ","),
+				RemoteAddr: audit.ClientAddr(r),
+				Action:     audit.ActionUAVReport,
+				Resource:   "uavmetrics/" + report.NodeName,
+				Outcome:    audit.OutcomeDenied,
+				Error:      err.Error(),
+			})
+			httpjson.WriteError(w, &httpjson.Error{Status: http.StatusForbidden, Code: mtls.CodeNodeMismatch, Message: err.Error()})
+			return
+		}
+
+		if report.UAVID == "" {
+			report.UAVID = fmt.Sprintf("uav-%s", report.Key())
+		}
+
+		if report.Timestamp.IsZero() {
+			report.Timestamp = time.Now().UTC()
+		}
+
+		if report.Source == "" {
+			report.Source = "agent"
+		}
+
+		if report.Status == "" {
+			report.Status = "active"
+		}
+
+		if manager != nil {
+			manager.UpdateUAVReport(&report)
+		} else {
+			log.Printf("Metrics manager unavailable, skipping cache update for node %s", report.NodeName)
+		}
+
+		if err := history.Record(r.Context(), &report); err != nil {
+			log.Printf("Failed to record UAV telemetry history for %s: %v", report.Key(), err)
+		}
+
+		crdStatus := "unavailable"
+		var crdError string
+		if k8sClient != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+			defer cancel()
+			err := k8sClient.UpsertUAVMetric(ctx, "", models.NewUAVMetricsEntry(&report))
+			if err != nil {
+				log.Printf("Failed to upsert UAVMetric for %s: %v", report.Key(), err)
+				crdStatus = "error"
+				crdError = err.Error()
+			} else {
+				crdStatus = "updated"
+			}
+			auditLog.Log(r.Context(), "uav-agent:"+report.NodeName, audit.ClientAddr(r), audit.ActionCRDUpsert,
+				"uavmetrics/"+report.Key(), map[string]interface{}{"uav_id": report.UAVID, "uav_status": report.Status}, err)
+		}
+
+		response := map[string]interface{}{
+			"status":     "success",
+			"crd_status": crdStatus,
+			"timestamp":  time.Now().UTC(),
+			"node_name":  report.NodeName,
+			"uav_key":    report.Key(),
+			"uav_id":     report.UAVID,
+			"uav_status": report.Status,
+		}
+
+		if report.HeartbeatIntervalSeconds > 0 {
+			response["heartbeat_interval_seconds"] = report.HeartbeatIntervalSeconds
+		}
+
+		if crdError != "" {
+			response["message"] = crdError
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// uavCRDHandler UAV CRD数据处理函数
+func uavCRDHandler(k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if k8sClient == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "error",
+				"message": "K8s client not available",
+			})
+			return
+		}
+
+		namespace := strings.TrimSpace(r.URL.Query().Get("namespace"))
+		if strings.EqualFold(namespace, "all") {
+			namespace = ""
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+
+		crdData, err := k8sClient.ListUAVMetricsCRD(ctx, namespace)
+		if err != nil {
+			log.Printf("Failed to list UAV CRD data: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "error",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"count":     len(crdData),
+			"data":      crdData,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+)
+
+// sloHandler 各SLO在滚动窗口内的达标率和错误预算（metrics.slos），
+// status 参数只返回处于该状态的SLO
+func sloHandler(manager *metrics.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if manager == nil {
+			http.Error(w, "Metrics manager not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		status := strings.TrimSpace(r.URL.Query().Get("status"))
+		switch status {
+		case "", metrics.SLOStatusOK, metrics.SLOStatusAtRisk, metrics.SLOStatusBreached, metrics.SLOStatusNoData:
+		default:
+			httpjson.WriteError(w, httpjson.BadRequest("invalid status %q (supported: ok, at_risk, breached, no_data)", status))
+			return
+		}
+
+		slos := []metrics.SLOStatus{}
+		breached := 0
+		for _, slo := range manager.SLOs() {
+			if slo.Status == metrics.SLOStatusBreached {
+				breached++
+			}
+			if status == "" || slo.Status == status {
+				slos = append(slos, slo)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    "success",
+			"data":      slos,
+			"count":     len(slos),
+			"breached":  breached,
+			"timestamp": time.Now().UTC(),
+		})
+	}
+}
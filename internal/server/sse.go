@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSE 事件类型
+const (
+	sseEventToken  = "token"  // 模型输出片段 {"delta": "..."}
+	sseEventStep   = "step"   // 工具调用完成（仅 /api/v1/query）
+	sseEventResult = "result" // 最终结果，与非流式响应体相同
+	sseEventError  = "error"  // 处理失败，格式同错误响应体
+)
+
+// wantsStream 请求是否要求以 Server-Sent Events 流式返回：
+// Accept: text/event-stream 或查询参数 stream=true
+func wantsStream(r *http.Request) bool {
+	if r.URL.Query().Get("stream") == "true" {
+		return true
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
+
+// sseWriter 输出 Server-Sent Events。响应头在第一个事件时才写出，
+// 因此之前发生的错误仍可以按普通JSON错误返回
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+	err     error // 第一次写入失败的错误（客户端断开），之后的事件直接丢弃
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	return &sseWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+// Started 是否已经开始输出事件
+func (s *sseWriter) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started
+}
+
+// Send 输出一个事件并立即刷新
+func (s *sseWriter) Send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if !s.started {
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // 关闭 nginx 等反向代理的缓冲
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		s.err = err
+		return err
+	}
+	if err := s.rc.Flush(); err != nil {
+		s.err = err
+		return err
+	}
+	return nil
+}
+
+// Token 作为 llm.StreamHandler 输出模型片段
+func (s *sseWriter) Token(delta string) error {
+	return s.Send(sseEventToken, map[string]string{"delta": delta})
+}
+
+// Error 输出错误：尚未开始输出事件时写普通的JSON错误响应
+func (s *sseWriter) Error(err error) {
+	if !s.Started() {
+		writeLLMError(s.w, err)
+		return
+	}
+	if sendErr := s.Send(sseEventError, map[string]interface{}{
+		"status":    "error",
+		"error":     llmError(err),
+		"timestamp": time.Now().UTC(),
+	}); sendErr != nil {
+		log.Printf("Failed to send SSE error event: %v", sendErr)
+	}
+}
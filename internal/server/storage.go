@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+)
+
+// readyzHandler 就绪检查：包含存储后端和K8s连接状态。
+// 存储熔断时服务以内存模式继续运行，状态标记为degraded但仍返回200
+func readyzHandler(store storage.Store, k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := "ready"
+		checks := map[string]interface{}{
+			"k8s": map[string]interface{}{
+				"connected": k8sClient != nil,
+			},
+		}
+
+		if reporter, ok := store.(storage.HealthReporter); ok {
+			health := reporter.Health()
+			checks["storage"] = health
+			if !health.Healthy {
+				status = "degraded"
+			}
+		} else {
+			checks["storage"] = map[string]interface{}{"healthy": true}
+		}
+
+		response := map[string]interface{}{
+			"status":    status,
+			"checks":    checks,
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// retentionStatsHandler 数据保留任务统计处理函数
+func retentionStatsHandler(job *storage.RetentionJob) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if job == nil {
+			http.Error(w, "Retention job not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		stats := job.Stats()
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      stats,
+			"count":     len(stats),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+	"github.com/yourusername/k8s-llm-monitor/internal/mtls"
+)
+
+// setupTLS 根据 tls 配置创建服务端TLS配置，以及访问UAV Agent使用的mTLS客户端
+func setupTLS(cfg *config.TLSConfig) (*tls.Config, *http.Client, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	var clientAuth tls.ClientAuthType
+	switch strings.ToLower(strings.TrimSpace(cfg.ClientAuth)) {
+	case "", "verify_if_given":
+		clientAuth = tls.VerifyClientCertIfGiven
+	case "require":
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, nil, fmt.Errorf("invalid tls.client_auth %q (expected verify_if_given or require)", cfg.ClientAuth)
+	}
+
+	for _, template := range cfg.AgentNodeSANs {
+		if !strings.Contains(template, mtls.NodePlaceholder) {
+			return nil, nil, fmt.Errorf("invalid tls.agent_node_sans %q: missing %s placeholder", template, mtls.NodePlaceholder)
+		}
+	}
+
+	opts := mtls.Options{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.CAFile,
+	}
+
+	serverTLS, err := mtls.ServerConfig(opts, clientAuth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create server TLS config: %w", err)
+	}
+
+	// 访问Agent时按SAN校验Agent身份
+	opts.AllowedSANs = cfg.AgentSANs
+	agentClient, err := mtls.HTTPClient(opts, 5*time.Second)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create agent TLS client: %w", err)
+	}
+
+	return serverTLS, agentClient, nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/topology"
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// topologyGraphHandler 返回集群/机群拓扑图（节点、Pod、Service、UAV及网络测试边），
+// 可用 ?namespace= 限定Pod和Service的命名空间
+func topologyGraphHandler(manager *metrics.Manager, k8sClient *k8s.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		input := topology.Input{}
+		if manager != nil {
+			input.Snapshot = manager.ViewSnapshot()
+			input.UAVs = manager.GetUAVMetrics()
+		}
+
+		var warnings []string
+		input.Pods, input.Services, warnings = listPodsAndServices(k8sClient, r.URL.Query().Get("namespace"))
+		if namespace := r.URL.Query().Get("namespace"); namespace != "" && input.Snapshot != nil {
+			input.Snapshot = filterSnapshotNamespace(input.Snapshot, namespace)
+		}
+
+		response := map[string]interface{}{
+			"status": "success",
+			"data":   topology.Build(input),
+		}
+		if len(warnings) > 0 {
+			response["warnings"] = warnings
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// listPodsAndServices 列出监控的命名空间（或指定的命名空间）中的Pod和Service，失败的命名空间记录为警告
+func listPodsAndServices(k8sClient *k8s.Client, namespace string) ([]*models.PodInfo, []*models.ServiceInfo, []string) {
+	if k8sClient == nil {
+		return nil, nil, nil
+	}
+	namespaces := k8sClient.Namespaces()
+	if namespace != "" {
+		namespaces = []string{namespace}
+	}
+
+	var allPods []*models.PodInfo
+	var allServices []*models.ServiceInfo
+	var warnings []string
+	for _, namespace := range namespaces {
+		pods, err := k8sClient.GetPods(namespace)
+		if err != nil {
+			log.Printf("Warning: topology: failed to list pods in %q: %v", namespace, err)
+			warnings = append(warnings, err.Error())
+		}
+		services, err := k8sClient.GetServices(namespace)
+		if err != nil {
+			log.Printf("Warning: topology: failed to list services in %q: %v", namespace, err)
+			warnings = append(warnings, err.Error())
+		}
+		allPods = append(allPods, pods...)
+		allServices = append(allServices, services...)
+	}
+	return allPods, allServices, warnings
+}
+
+// filterSnapshotNamespace 只保留指定命名空间的Pod和网络测试（节点保留），不修改原快照
+func filterSnapshotNamespace(snapshot *metricstypes.MetricsSnapshot, namespace string) *metricstypes.MetricsSnapshot {
+	filtered := &metricstypes.MetricsSnapshot{
+		Timestamp:      snapshot.Timestamp,
+		NodeMetrics:    snapshot.NodeMetrics,
+		PodMetrics:     make(map[string]*metricstypes.PodMetrics),
+		ClusterMetrics: snapshot.ClusterMetrics,
+	}
+	for key, pod := range snapshot.PodMetrics {
+		if pod.Namespace == namespace {
+			filtered.PodMetrics[key] = pod
+		}
+	}
+	prefix := namespace + "/"
+	for _, network := range snapshot.NetworkMetrics {
+		if strings.HasPrefix(network.SourcePod, prefix) || strings.HasPrefix(network.TargetPod, prefix) {
+			filtered.NetworkMetrics = append(filtered.NetworkMetrics, network)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/audit"
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/internal/k8s"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics"
+	"github.com/yourusername/k8s-llm-monitor/internal/metrics/sources"
+	"github.com/yourusername/k8s-llm-monitor/internal/telemetry"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// uavNodeRouter 处理 /api/v1/uav/{node}/... 形式的节点级UAV接口
+func uavNodeRouter(history *telemetry.UAVHistory, commandHandler func(http.ResponseWriter, *http.Request, string, string)) http.HandlerFunc {
+	historyHandler := uavHistoryHandler(history)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/uav/"), "/"), "/")
+		if len(parts) < 2 || parts[0] == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		nodeName, action := parts[0], parts[1]
+		switch {
+		case action == "history" && len(parts) == 2:
+			historyHandler(w, r, nodeName)
+		case action == "command" && len(parts) == 3:
+			commandHandler(w, r, nodeName, parts[2])
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// uavCommandRequest UAV控制命令的请求体，字段按命令使用，其他命令可以不带请求体
+type uavCommandRequest struct {
+	Altitude float64 `json:"altitude,omitempty"` // takeoff：目标高度（米），为0时由Agent使用默认高度
+	Mode     string  `json:"mode,omitempty"`     // mode：飞行模式
+}
+
+// uavCommandHandler 通过节点上的UAV Agent发送控制命令（需要管理Token）：
+// POST /api/v1/uav/{node}/command/{arm|disarm|takeoff|land|rtl|mode}，每次命令写入审计日志
+func uavCommandHandler(manager *metrics.Manager, adminToken string, auditLog *audit.Logger, maxBody int64) func(http.ResponseWriter, *http.Request, string, string) {
+	return func(w http.ResponseWriter, r *http.Request, nodeName, command string) {
+		requireAdmin(adminToken, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+
+			if !slices.Contains(sources.UAVCommands, command) {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusNotFound, Code: "not_found",
+					Message: fmt.Sprintf("unknown UAV command %q (supported: %s)", command, strings.Join(sources.UAVCommands, ", "))})
+				return
+			}
+			if manager == nil {
+				httpjson.WriteError(w, &httpjson.Error{Status: http.StatusServiceUnavailable, Code: "uav_unavailable", Message: "Metrics manager not available"})
+				return
+			}
+
+			var request uavCommandRequest
+			if r.ContentLength != 0 {
+				if err := httpjson.Decode(w, r, &request, maxBody); err != nil {
+					httpjson.WriteError(w, err)
+					return
+				}
+			}
+			payload, err := uavCommandPayload(command, request)
+			if err != nil {
+				httpjson.WriteError(w, err)
+				return
+			}
+
+			message, err := manager.SendUAVCommand(r.Context(), nodeName, command, payload)
+			details := map[string]interface{}{"command": command}
+			if payload != nil {
+				details["payload"] = payload
+			}
+			auditLog.LogRequest(r, audit.ActionUAVCommand, "uav/"+nodeName, details, err)
+			if err != nil {
+				writeUAVCommandError(w, err)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": "success",
+				"data": map[string]interface{}{
+					"node":    nodeName,
+					"command": command,
+					"message": message,
+				},
+				"timestamp": time.Now().UTC(),
+			})
+		})(w, r)
+	}
+}
+
+// uavCommandPayload 校验请求体并构建发给Agent的参数，不需要参数的命令返回nil
+func uavCommandPayload(command string, request uavCommandRequest) (interface{}, error) {
+	if request.Altitude != 0 && command != "takeoff" {
+		return nil, httpjson.BadRequest("altitude is only valid for the takeoff command")
+	}
+	if request.Mode != "" && command != "mode" {
+		return nil, httpjson.BadRequest("mode is only valid for the mode command")
+	}
+
+	switch command {
+	case "takeoff":
+		if request.Altitude < 0 {
+			return nil, httpjson.BadRequest("altitude must not be negative")
+		}
+		return map[string]interface{}{"altitude": request.Altitude}, nil
+	case "mode":
+		mode := strings.TrimSpace(request.Mode)
+		if mode == "" {
+			return nil, httpjson.BadRequest("mode is required")
+		}
+		return map[string]interface{}{"mode": mode}, nil
+	}
+	return nil, nil
+}
+
+// writeUAVCommandError 将发送命令的错误映射为HTTP状态码：Agent拒绝命令时沿用其4xx状态码和错误码
+// （400参数错误、409状态不允许、422解锁或起飞条件不满足），Agent不可达时为502
+func writeUAVCommandError(w http.ResponseWriter, err error) {
+	apiErr := &httpjson.Error{Status: http.StatusBadGateway, Code: "agent_error", Message: err.Error()}
+	var rejected *sources.UAVCommandError
+	switch {
+	case errors.Is(err, metrics.ErrUAVCommandsUnavailable):
+		apiErr.Status, apiErr.Code = http.StatusServiceUnavailable, "uav_unavailable"
+	case errors.Is(err, sources.ErrNoUAVAgent):
+		apiErr.Status, apiErr.Code = http.StatusNotFound, "not_found"
+	case errors.As(err, &rejected):
+		apiErr.Message = rejected.Message
+		switch {
+		case rejected.StatusCode == http.StatusOK:
+			apiErr.Status, apiErr.Code = http.StatusConflict, "command_rejected"
+		case rejected.StatusCode >= 400 && rejected.StatusCode < 500:
+			apiErr.Status, apiErr.Code = rejected.StatusCode, "command_rejected"
+			if rejected.Code != "" {
+				apiErr.Code = rejected.Code
+			}
+		}
+	}
+	httpjson.WriteError(w, apiErr)
+}
+
+// uavHistoryHandler UAV遥测历史处理函数
+func uavHistoryHandler(history *telemetry.UAVHistory) func(http.ResponseWriter, *http.Request, string) {
+	return func(w http.ResponseWriter, r *http.Request, nodeName string) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if from.IsZero() {
+			from = time.Now().UTC().Add(-time.Hour)
+		}
+
+		limit, err := parseLimitParam(r, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format, err := exportFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := history.Query(r.Context(), nodeName, from, to, limit)
+		if err != nil {
+			http.Error(w, "Failed to query UAV history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if format != formatJSON {
+			writeTable(w, format, "uav-history-"+nodeName, result.Table())
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    "success",
+			"data":      result,
+			"count":     len(result.Points),
+			"timestamp": time.Now().UTC(),
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// uavStatusRecorder 将UAV心跳状态的变化写入UAVMetric的状态并记录K8s事件
+func uavStatusRecorder(k8sClient *k8s.Client, auditLog *audit.Logger) func(context.Context, []*models.UAVStatusChange) {
+	return func(ctx context.Context, changes []*models.UAVStatusChange) {
+		for _, change := range changes {
+			recordCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			err := k8sClient.RecordUAVStatusChange(recordCtx, "", change)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to record UAV status change for %s: %v", change.Key(), err)
+			}
+			auditLog.Log(ctx, audit.ActorSystem, "", audit.ActionCRDUpsert, "uavmetrics/"+change.Key(), map[string]interface{}{
+				"from":              change.From,
+				"to":                change.To,
+				"missed_heartbeats": change.MissedHeartbeats,
+			}, err)
+		}
+	}
+}
+
+// uavGeofenceRecorder UAV越出或回到地理围栏时更新UAVMetric的围栏状态、记录K8s事件并写入审计日志
+func uavGeofenceRecorder(k8sClient *k8s.Client, auditLog *audit.Logger) func(context.Context, []*models.UAVGeofenceChange) {
+	return func(ctx context.Context, changes []*models.UAVGeofenceChange) {
+		for _, change := range changes {
+			recordCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			err := k8sClient.RecordUAVGeofenceChange(recordCtx, "", change)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to record geofence change for %s: %v", change.Key(), err)
+			}
+			auditLog.Log(ctx, audit.ActorSystem, "", audit.ActionCRDUpsert, "uavmetrics/"+change.Key(), map[string]interface{}{
+				"geofence_breached": change.Breached,
+				"breach_count":      change.BreachCount,
+				"latitude":          change.Latitude,
+				"longitude":         change.Longitude,
+				"relative_altitude": change.RelativeAltitude,
+				"action":            change.Action,
+			}, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/httpjson"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// UAV上报中元数据的限制
+const (
+	maxUAVMetadataEntries = 32
+	maxUAVMetadataValue   = 256
+	maxUAVFieldLength     = 128
+)
+
+// validatePodRef 校验 [namespace/]name 形式的Pod引用
+func validatePodRef(field, value string) error {
+	return validateObjectRef(field, value, "pod")
+}
+
+// validateObjectRef 校验 [namespace/]name 形式的命名空间内对象引用，kind 用于错误信息
+func validateObjectRef(field, value, kind string) error {
+	if value == "" {
+		return httpjson.BadRequest("%s is required", field)
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) > 2 {
+		return httpjson.BadRequest("%s must be in the form [namespace/]name", field)
+	}
+	if len(parts) == 2 {
+		if errs := validation.IsDNS1123Label(parts[0]); len(errs) > 0 {
+			return httpjson.BadRequest("%s has invalid namespace %q: %s", field, parts[0], strings.Join(errs, "; "))
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(parts[len(parts)-1]); len(errs) > 0 {
+		return httpjson.BadRequest("%s has invalid %s name: %s", field, kind, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateNodeName 校验节点名
+func validateNodeName(field, value string) error {
+	if value == "" {
+		return httpjson.BadRequest("%s is required", field)
+	}
+	if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+		return httpjson.BadRequest("%s has invalid node name: %s", field, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateUAVReport 校验UAV上报内容（节点名会用作CRD名称）
+func validateUAVReport(report *models.UAVReport) error {
+	if report.NodeName == "" {
+		return httpjson.BadRequest("node_name is required")
+	}
+	if errs := validation.IsDNS1123Subdomain(report.NodeName); len(errs) > 0 {
+		return httpjson.BadRequest("invalid node_name: %s", strings.Join(errs, "; "))
+	}
+
+	for field, value := range map[string]string{
+		"uav_id":  report.UAVID,
+		"node_ip": report.NodeIP,
+		"source":  report.Source,
+		"status":  report.Status,
+	} {
+		if len(value) > maxUAVFieldLength {
+			return httpjson.BadRequest("%s must not exceed %d characters", field, maxUAVFieldLength)
+		}
+	}
+
+	if report.Index < 0 || report.Index >= models.MaxUAVsPerAgent {
+		return httpjson.BadRequest("index must be between 0 and %d", models.MaxUAVsPerAgent-1)
+	}
+
+	if report.HeartbeatIntervalSeconds < 0 {
+		return httpjson.BadRequest("heartbeat_interval_seconds must not be negative")
+	}
+
+	if len(report.Metadata) > maxUAVMetadataEntries {
+		return httpjson.BadRequest("metadata must not have more than %d entries", maxUAVMetadataEntries)
+	}
+	for key, value := range report.Metadata {
+		if len(key) > maxUAVFieldLength || len(value) > maxUAVMetadataValue {
+			return httpjson.BadRequest("metadata entry %q is too long", key)
+		}
+	}
+	return nil
+}
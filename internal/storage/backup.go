@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BackupVersion 备份格式版本
+const BackupVersion = 1
+
+// Enumerator 可枚举全部数据的存储（备份需要）
+type Enumerator interface {
+	// Buckets 列出所有键值bucket
+	Buckets(ctx context.Context) ([]string, error)
+
+	// Keys 列出bucket中的所有键
+	Keys(ctx context.Context, bucket string) ([]string, error)
+
+	// Collections 列出所有记录集合
+	Collections(ctx context.Context) ([]string, error)
+}
+
+// Unwrapper 转换数据内容的存储层（如静态加密），Unwrap 返回下一层存储
+type Unwrapper interface {
+	Unwrap() Store
+}
+
+// rawStore 去掉所有转换层，返回实际保存数据的存储（加密数据在其中是密文）
+func rawStore(store Store) Store {
+	for {
+		unwrapper, ok := store.(Unwrapper)
+		if !ok {
+			return store
+		}
+		store = unwrapper.Unwrap()
+	}
+}
+
+// backupEntry 备份文件中的一行（gzip压缩的JSONL）
+type backupEntry struct {
+	Type       string                 `json:"type"` // header, kv, record
+	Version    int                    `json:"version,omitempty"`
+	CreatedAt  time.Time              `json:"created_at,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+	Bucket     string                 `json:"bucket,omitempty"`
+	Key        string                 `json:"key,omitempty"`
+	Value      []byte                 `json:"value,omitempty"`
+	Collection string                 `json:"collection,omitempty"`
+	Record     *Record                `json:"record,omitempty"`
+}
+
+// BackupSummary 备份/恢复统计
+type BackupSummary struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Keys      int            `json:"keys"`
+	Records   int            `json:"records"`
+	PerBucket map[string]int `json:"per_bucket"`
+	PerColl   map[string]int `json:"per_collection"`
+}
+
+func newBackupSummary() *BackupSummary {
+	return &BackupSummary{
+		Version:   BackupVersion,
+		PerBucket: make(map[string]int),
+		PerColl:   make(map[string]int),
+	}
+}
+
+// Backup 将存储中的全部数据写为可移植的备份（gzip压缩的JSONL），meta会写入文件头。
+// 数据从加密层下的存储读取，加密的集合和bucket以密文导出
+func Backup(ctx context.Context, store Store, w io.Writer, meta map[string]interface{}) (*BackupSummary, error) {
+	enumerator, ok := store.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support backup")
+	}
+	raw := rawStore(store)
+
+	summary := newBackupSummary()
+	summary.CreatedAt = time.Now().UTC()
+
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+
+	if err := encoder.Encode(backupEntry{
+		Type:      "header",
+		Version:   BackupVersion,
+		CreatedAt: summary.CreatedAt,
+		Meta:      meta,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	buckets, err := enumerator.Buckets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	for _, bucket := range buckets {
+		keys, err := enumerator.Keys(ctx, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list keys of %s: %w", bucket, err)
+		}
+		for _, key := range keys {
+			value, err := raw.Get(ctx, bucket, key)
+			if err != nil {
+				continue
+			}
+			if err := encoder.Encode(backupEntry{Type: "kv", Bucket: bucket, Key: key, Value: value}); err != nil {
+				return nil, fmt.Errorf("failed to write backup entry: %w", err)
+			}
+			summary.Keys++
+			summary.PerBucket[bucket]++
+		}
+	}
+
+	collections, err := enumerator.Collections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	for _, collection := range collections {
+		records, err := raw.List(ctx, collection, Query{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list records of %s: %w", collection, err)
+		}
+		for _, record := range records {
+			if err := encoder.Encode(backupEntry{Type: "record", Collection: collection, Record: record}); err != nil {
+				return nil, fmt.Errorf("failed to write backup entry: %w", err)
+			}
+			summary.Records++
+			summary.PerColl[collection]++
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return summary, nil
+}
+
+// Restore 从备份中导入数据（键值覆盖写入，记录追加写入）。
+// 密文原样写回加密层下的存储（按原位置解密），明文经过 store 写入，需要加密的数据在写入时加密
+func Restore(ctx context.Context, store Store, r io.Reader) (*BackupSummary, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	raw := rawStore(store)
+	target := func(data []byte) Store {
+		if _, encrypted := parseEnvelope(data); encrypted {
+			return raw
+		}
+		return store
+	}
+
+	summary := newBackupSummary()
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	headerSeen := false
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		var entry backupEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return summary, fmt.Errorf("invalid backup entry: %w", err)
+		}
+
+		switch entry.Type {
+		case "header":
+			if entry.Version > BackupVersion {
+				return summary, fmt.Errorf("unsupported backup version: %d", entry.Version)
+			}
+			summary.CreatedAt = entry.CreatedAt
+			headerSeen = true
+		case "kv":
+			if err := target(entry.Value).Put(ctx, entry.Bucket, entry.Key, entry.Value); err != nil {
+				return summary, fmt.Errorf("failed to restore %s/%s: %w", entry.Bucket, entry.Key, err)
+			}
+			summary.Keys++
+			summary.PerBucket[entry.Bucket]++
+		case "record":
+			if entry.Record == nil {
+				continue
+			}
+			if err := target(entry.Record.Data).Append(ctx, entry.Collection, entry.Record); err != nil {
+				return summary, fmt.Errorf("failed to restore record into %s: %w", entry.Collection, err)
+			}
+			summary.Records++
+			summary.PerColl[entry.Collection]++
+		}
+
+		if !headerSeen {
+			return summary, fmt.Errorf("backup header missing")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if !headerSeen {
+		return summary, fmt.Errorf("backup is empty")
+	}
+	return summary, nil
+}
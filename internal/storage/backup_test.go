@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // base64("0123456789abcdef0123456789abcdef")
+
+func newTestEncryptedStore(t *testing.T, backend Store) *EncryptedStore {
+	t.Helper()
+	store, err := NewEncryptedStore(backend, EncryptionOptions{
+		Key:         testEncryptionKey,
+		Collections: []string{"uav_telemetry"},
+		Buckets:     []string{"state"},
+	})
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	return store
+}
+
+// backupPayloads 解压备份并返回每个键值和记录的原始内容（键值在JSONL中是base64编码）
+func backupPayloads(t *testing.T, archive []byte) string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to read backup: %v", err)
+	}
+
+	var payloads []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry backupEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid backup line %q: %v", line, err)
+		}
+		switch entry.Type {
+		case "kv":
+			payloads = append(payloads, string(entry.Value))
+		case "record":
+			payloads = append(payloads, string(entry.Record.Data))
+		}
+	}
+	return strings.Join(payloads, "\n")
+}
+
+// 加密的集合和bucket以密文导出，恢复到使用同一密钥的存储后可以读取
+func TestBackupKeepsEncryptedDataEncrypted(t *testing.T) {
+	ctx := context.Background()
+	store := newTestEncryptedStore(t, NewMemoryStore())
+
+	const secret = "lat=39.9042,lon=116.4074"
+	store.Put(ctx, "state", "metrics", []byte(`{"position":"`+secret+`"}`))
+	store.Put(ctx, "reports", "daily", []byte(`{"summary":"plain report"}`))
+	store.Append(ctx, "uav_telemetry", &Record{ID: "r1", Key: "UAV-1", Timestamp: time.Now(), Data: json.RawMessage(`{"gps":"` + secret + `"}`)})
+	store.Append(ctx, "node-metrics", &Record{ID: "r2", Key: "node-1", Timestamp: time.Now(), Data: json.RawMessage(`{"cpu":1}`)})
+
+	var archive bytes.Buffer
+	summary, err := Backup(ctx, store, &archive, nil)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if summary.Keys != 2 || summary.Records != 2 {
+		t.Errorf("Backup() = %d keys, %d records, want 2 and 2", summary.Keys, summary.Records)
+	}
+	text := backupPayloads(t, archive.Bytes())
+	if strings.Contains(text, "39.9042") || strings.Contains(text, "116.4074") {
+		t.Fatalf("backup contains plaintext of encrypted data:\n%s", text)
+	}
+	if strings.Count(text, `"enc":"`+EncryptionAlgorithm+`"`) != 2 {
+		t.Errorf("backup should contain 2 encrypted envelopes:\n%s", text)
+	}
+	if !strings.Contains(text, "plain report") {
+		t.Errorf("unencrypted bucket missing from backup:\n%s", text)
+	}
+
+	// 恢复后密文原样保存，并按原位置解密
+	restoredBackend := NewMemoryStore()
+	restored := newTestEncryptedStore(t, restoredBackend)
+	if _, err := Restore(ctx, restored, bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	value, err := restored.Get(ctx, "state", "metrics")
+	if err != nil || !strings.Contains(string(value), secret) {
+		t.Errorf("restored Get() = %s, %v", value, err)
+	}
+	records, err := restored.List(ctx, "uav_telemetry", Query{})
+	if err != nil || len(records) != 1 || !strings.Contains(string(records[0].Data), secret) {
+		t.Fatalf("restored List() = %v, %v", records, err)
+	}
+	raw, _ := restoredBackend.Get(ctx, "state", "metrics")
+	if strings.Contains(string(raw), secret) {
+		t.Errorf("restored backend holds plaintext: %s", raw)
+	}
+}
+
+// 加密开启前的明文备份恢复到加密存储时，需要加密的数据在写入时加密
+func TestRestorePlaintextBackupIntoEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	plain := NewMemoryStore()
+	plain.Put(ctx, "state", "metrics", []byte(`{"position":"39.9042"}`))
+	plain.Append(ctx, "uav_telemetry", &Record{ID: "r1", Key: "UAV-1", Timestamp: time.Now(), Data: json.RawMessage(`{"gps":"39.9042"}`)})
+
+	var archive bytes.Buffer
+	if _, err := Backup(ctx, plain, &archive, nil); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	backend := NewMemoryStore()
+	store := newTestEncryptedStore(t, backend)
+	if _, err := Restore(ctx, store, &archive); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	raw, _ := backend.Get(ctx, "state", "metrics")
+	records, _ := backend.List(ctx, "uav_telemetry", Query{})
+	if strings.Contains(string(raw), "39.9042") || len(records) != 1 || strings.Contains(string(records[0].Data), "39.9042") {
+		t.Errorf("restored plaintext was not encrypted: %s / %v", raw, records)
+	}
+	if value, err := store.Get(ctx, "state", "metrics"); err != nil || !strings.Contains(string(value), "39.9042") {
+		t.Errorf("Get() = %s, %v", value, err)
+	}
+}
@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+)
+
+// ErrCircuitOpen 熔断器打开，存储后端暂不可用
+var ErrCircuitOpen = errors.New("storage: circuit breaker open")
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// maxTransitions 保留的状态变化记录数
+const maxTransitions = 20
+
+// BreakerOptions 熔断器参数
+type BreakerOptions struct {
+	FailureThreshold int           // 连续失败多少次后打开
+	Cooldown         time.Duration // 打开后多久允许探测
+	SlowThreshold    time.Duration // 超过该耗时的操作视为失败
+	OpTimeout        time.Duration // 单次操作超时，避免阻塞采集周期
+}
+
+// BreakerTransition 熔断器状态变化
+type BreakerTransition struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Health 存储健康状态
+type Health struct {
+	Backend      string              `json:"backend"`
+	State        string              `json:"state"`
+	Healthy      bool                `json:"healthy"`
+	Operations   int64               `json:"operations"`
+	Errors       int64               `json:"errors"`
+	ErrorRate    float64             `json:"error_rate"`     // 最近操作的错误率（指数加权）
+	AvgLatencyMs float64             `json:"avg_latency_ms"` // 最近操作的平均耗时（指数加权）
+	LastError    string              `json:"last_error,omitempty"`
+	OpenedAt     time.Time           `json:"opened_at,omitempty"`
+	Transitions  []BreakerTransition `json:"transitions,omitempty"`
+	Buffer       *BufferStats        `json:"buffer,omitempty"`
+	Encrypted    bool                `json:"encrypted,omitempty"` // 是否启用静态加密
+	KeyID        string              `json:"key_id,omitempty"`    // 当前加密密钥ID
+}
+
+// HealthReporter 可报告健康状态的存储
+type HealthReporter interface {
+	Health() Health
+}
+
+// BreakerStore 带熔断器的存储：监控后端耗时和错误率，后端异常时快速失败，
+// 由外层的 BufferedStore 在内存中暂存写入
+type BreakerStore struct {
+	backend Store
+	name    string
+	opts    BreakerOptions
+	logger  *logrus.Entry
+
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+	health              Health
+	mu                  sync.Mutex
+}
+
+// NewBreakerStore 创建带熔断器的存储
+func NewBreakerStore(backend Store, name string, opts BreakerOptions) *BreakerStore {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 30 * time.Second
+	}
+	if opts.SlowThreshold <= 0 {
+		opts.SlowThreshold = 2 * time.Second
+	}
+	if opts.OpTimeout <= 0 {
+		opts.OpTimeout = 5 * time.Second
+	}
+
+	logger := logging.For("storage.breaker")
+
+	return &BreakerStore{
+		backend: backend,
+		name:    name,
+		opts:    opts,
+		logger:  logger,
+		state:   BreakerClosed,
+	}
+}
+
+// Put 写入键值数据
+func (s *BreakerStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	return s.guard(ctx, func(ctx context.Context) error {
+		return s.backend.Put(ctx, bucket, key, value)
+	})
+}
+
+// Get 读取键值数据
+func (s *BreakerStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.guard(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = s.backend.Get(ctx, bucket, key)
+		return err
+	})
+	return value, err
+}
+
+// Append 追加记录
+func (s *BreakerStore) Append(ctx context.Context, collection string, record *Record) error {
+	return s.guard(ctx, func(ctx context.Context) error {
+		return s.backend.Append(ctx, collection, record)
+	})
+}
+
+// List 查询记录
+func (s *BreakerStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	var records []*Record
+	err := s.guard(ctx, func(ctx context.Context) error {
+		var err error
+		records, err = s.backend.List(ctx, collection, query)
+		return err
+	})
+	return records, err
+}
+
+// DeleteBefore 删除过期记录
+func (s *BreakerStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	var deleted int
+	err := s.guard(ctx, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.backend.DeleteBefore(ctx, collection, before)
+		return err
+	})
+	return deleted, err
+}
+
+// Buckets 列出后端的bucket
+func (s *BreakerStore) Buckets(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Buckets(ctx)
+}
+
+// Keys 列出后端bucket中的键
+func (s *BreakerStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Keys(ctx, bucket)
+}
+
+// Collections 列出后端的集合
+func (s *BreakerStore) Collections(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Collections(ctx)
+}
+
+// Close 关闭后端
+func (s *BreakerStore) Close() error {
+	return s.backend.Close()
+}
+
+// Health 返回后端健康状态
+func (s *BreakerStore) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	health := s.health
+	health.Backend = s.name
+	health.State = s.state
+	health.Healthy = s.state == BreakerClosed
+	health.OpenedAt = s.openedAt
+	health.Transitions = append([]BreakerTransition(nil), s.health.Transitions...)
+	return health
+}
+
+// guard 在熔断器保护下执行操作
+func (s *BreakerStore) guard(ctx context.Context, op func(ctx context.Context) error) error {
+	probe, err := s.allow()
+	if err != nil {
+		return err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, s.opts.OpTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = op(opCtx)
+	latency := time.Since(start)
+
+	// 调用方取消或数据不存在不计入后端故障
+	failed := err != nil && !errors.Is(err, ErrNotFound) && ctx.Err() == nil
+	if latency > s.opts.SlowThreshold && !failed {
+		failed = true
+		if err == nil {
+			s.logger.Warnf("Storage backend %s slow operation: %s", s.name, latency)
+		}
+	}
+
+	s.record(latency, failed, err, probe)
+	return err
+}
+
+// allow 判断是否允许执行操作，返回是否为半开状态下的探测请求
+func (s *BreakerStore) allow() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case BreakerOpen:
+		if time.Since(s.openedAt) < s.opts.Cooldown {
+			return false, ErrCircuitOpen
+		}
+		s.transition(BreakerHalfOpen, "cooldown elapsed")
+		s.probing = true
+		return true, nil
+	case BreakerHalfOpen:
+		if s.probing {
+			return false, ErrCircuitOpen
+		}
+		s.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record 记录操作结果并更新熔断器状态
+func (s *BreakerStore) record(latency time.Duration, failed bool, err error, probe bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const alpha = 0.2
+	latencyMs := float64(latency.Microseconds()) / 1000
+	failure := 0.0
+	if failed {
+		failure = 1
+	}
+	if s.health.Operations == 0 {
+		s.health.AvgLatencyMs = latencyMs
+		s.health.ErrorRate = failure
+	} else {
+		s.health.AvgLatencyMs = alpha*latencyMs + (1-alpha)*s.health.AvgLatencyMs
+		s.health.ErrorRate = alpha*failure + (1-alpha)*s.health.ErrorRate
+	}
+	s.health.Operations++
+
+	if probe {
+		s.probing = false
+	}
+
+	if !failed {
+		s.consecutiveFailures = 0
+		if s.state != BreakerClosed {
+			s.transition(BreakerClosed, "probe succeeded")
+			s.logger.Infof("Storage backend %s recovered, circuit breaker closed", s.name)
+		}
+		return
+	}
+
+	s.health.Errors++
+	if err != nil {
+		s.health.LastError = err.Error()
+	} else {
+		s.health.LastError = fmt.Sprintf("slow operation: %s", latency)
+	}
+	s.consecutiveFailures++
+
+	if s.state == BreakerHalfOpen || (s.state == BreakerClosed && s.consecutiveFailures >= s.opts.FailureThreshold) {
+		s.openedAt = time.Now()
+		s.transition(BreakerOpen, s.health.LastError)
+		s.logger.Errorf("ALERT: storage backend %s unhealthy (%d consecutive failures, last error: %s), switching to memory-only mode for %s",
+			s.name, s.consecutiveFailures, s.health.LastError, s.opts.Cooldown)
+	}
+}
+
+// transition 切换状态（调用方持有锁）
+func (s *BreakerStore) transition(to, reason string) {
+	s.health.Transitions = append(s.health.Transitions, BreakerTransition{
+		From:   s.state,
+		To:     to,
+		At:     time.Now().UTC(),
+		Reason: reason,
+	})
+	if n := len(s.health.Transitions); n > maxTransitions {
+		s.health.Transitions = s.health.Transitions[n-maxTransitions:]
+	}
+	s.state = to
+}
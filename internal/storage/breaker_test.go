@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errBackendDown = errors.New("backend down")
+
+// flakyStore 可模拟故障和慢操作的内存存储
+type flakyStore struct {
+	*MemoryStore
+
+	mu    sync.Mutex
+	err   error
+	delay time.Duration
+	calls int
+}
+
+func newFlakyStore() *flakyStore {
+	return &flakyStore{MemoryStore: NewMemoryStore()}
+}
+
+func (s *flakyStore) set(err error, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err, s.delay = err, delay
+}
+
+func (s *flakyStore) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *flakyStore) before() error {
+	s.mu.Lock()
+	s.calls++
+	err, delay := s.err, s.delay
+	s.mu.Unlock()
+	time.Sleep(delay)
+	return err
+}
+
+func (s *flakyStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	if err := s.before(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Put(ctx, bucket, key, value)
+}
+
+func (s *flakyStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	if err := s.before(); err != nil {
+		return nil, err
+	}
+	return s.MemoryStore.Get(ctx, bucket, key)
+}
+
+func (s *flakyStore) Append(ctx context.Context, collection string, record *Record) error {
+	if err := s.before(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Append(ctx, collection, record)
+}
+
+func (s *flakyStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	if err := s.before(); err != nil {
+		return nil, err
+	}
+	return s.MemoryStore.List(ctx, collection, query)
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	breaker := NewBreakerStore(backend, "test", BreakerOptions{FailureThreshold: 3, Cooldown: 50 * time.Millisecond})
+
+	backend.set(errBackendDown, 0)
+	for i := 0; i < 3; i++ {
+		if err := breaker.Put(ctx, "b", "k", []byte("v")); !errors.Is(err, errBackendDown) {
+			t.Fatalf("Put() #%d error = %v, want backend error", i+1, err)
+		}
+	}
+	health := breaker.Health()
+	if health.State != BreakerOpen || health.Healthy || health.Errors != 3 || health.LastError != errBackendDown.Error() {
+		t.Fatalf("Health() after %d failures = %+v", 3, health)
+	}
+
+	// 打开期间快速失败，不访问后端
+	calls := backend.callCount()
+	if err := breaker.Put(ctx, "b", "k", []byte("v")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Put() while open error = %v, want ErrCircuitOpen", err)
+	}
+	if backend.callCount() != calls {
+		t.Error("open breaker called the backend")
+	}
+
+	// 冷却后探测失败，重新打开
+	time.Sleep(60 * time.Millisecond)
+	if err := breaker.Put(ctx, "b", "k", []byte("v")); !errors.Is(err, errBackendDown) {
+		t.Errorf("probe error = %v, want backend error", err)
+	}
+	if state := breaker.Health().State; state != BreakerOpen {
+		t.Errorf("state after failed probe = %s, want open", state)
+	}
+
+	// 冷却后探测成功，关闭
+	backend.set(nil, 0)
+	time.Sleep(60 * time.Millisecond)
+	if err := breaker.Put(ctx, "b", "k", []byte("v")); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	health = breaker.Health()
+	if health.State != BreakerClosed || !health.Healthy {
+		t.Errorf("Health() after successful probe = %+v", health)
+	}
+	var path []string
+	for _, transition := range health.Transitions {
+		path = append(path, transition.To)
+	}
+	want := []string{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(path) != len(want) {
+		t.Fatalf("transitions = %v, want %v", path, want)
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", path, want)
+		}
+	}
+}
+
+// 半开状态只允许一个探测请求，其余请求快速失败
+func TestBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	breaker := NewBreakerStore(backend, "test", BreakerOptions{FailureThreshold: 1, Cooldown: 10 * time.Millisecond, SlowThreshold: time.Second})
+
+	backend.set(errBackendDown, 0)
+	breaker.Put(ctx, "b", "k", []byte("v"))
+	time.Sleep(20 * time.Millisecond)
+
+	backend.set(nil, 100*time.Millisecond)
+	probeDone := make(chan error, 1)
+	go func() { probeDone <- breaker.Put(ctx, "b", "k", []byte("v")) }()
+	time.Sleep(30 * time.Millisecond)
+	if err := breaker.Put(ctx, "b", "k2", []byte("v")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second request during probe error = %v, want ErrCircuitOpen", err)
+	}
+	if err := <-probeDone; err != nil {
+		t.Errorf("probe error = %v", err)
+	}
+	if state := breaker.Health().State; state != BreakerClosed {
+		t.Errorf("state after probe = %s, want closed", state)
+	}
+}
+
+// 数据不存在和调用方取消不算后端故障，慢操作算故障
+func TestBreakerFailureClassification(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	breaker := NewBreakerStore(backend, "test", BreakerOptions{FailureThreshold: 2, SlowThreshold: 20 * time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		if _, err := breaker.Get(ctx, "b", "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get() error = %v, want ErrNotFound", err)
+		}
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	backend.set(context.Canceled, 0)
+	for i := 0; i < 3; i++ {
+		breaker.Put(canceled, "b", "k", []byte("v"))
+	}
+	if health := breaker.Health(); health.State != BreakerClosed || health.Errors != 0 {
+		t.Fatalf("Health() after not-found and canceled operations = %+v", health)
+	}
+
+	backend.set(nil, 30*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := breaker.Put(ctx, "b", "k", []byte("v")); err != nil {
+			t.Fatalf("slow Put() error = %v", err)
+		}
+	}
+	if health := breaker.Health(); health.State != BreakerOpen || health.LastError == "" {
+		t.Errorf("Health() after slow operations = %+v, want open", health)
+	}
+}
@@ -0,0 +1,443 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+)
+
+// ErrBufferFull 写缓冲已满，写入被丢弃
+var ErrBufferFull = errors.New("storage: write buffer full")
+
+const (
+	opPut    = "put"
+	opAppend = "append"
+)
+
+// bufferedOp 待重放的写操作
+type bufferedOp struct {
+	Op         string    `json:"op"`
+	Bucket     string    `json:"bucket,omitempty"`
+	Key        string    `json:"key,omitempty"`
+	Value      []byte    `json:"value,omitempty"`
+	Collection string    `json:"collection,omitempty"`
+	Record     *Record   `json:"record,omitempty"`
+	QueuedAt   time.Time `json:"queued_at"`
+}
+
+// BufferOptions 写缓冲参数
+type BufferOptions struct {
+	MaxEntries    int           // 缓冲最大条目数
+	Path          string        // 非空时缓冲落盘，进程重启后继续重放
+	DrainInterval time.Duration // 后端恢复检测间隔
+}
+
+// BufferStats 写缓冲状态
+type BufferStats struct {
+	Pending   int       `json:"pending"`
+	Dropped   int64     `json:"dropped"`
+	Replayed  int64     `json:"replayed"`
+	LastError string    `json:"last_error,omitempty"`
+	LastFlush time.Time `json:"last_flush,omitempty"`
+}
+
+// BufferedStore 带写前缓冲的存储：后端不可用时将写操作排队，恢复后按顺序重放
+type BufferedStore struct {
+	backend Store
+	opts    BufferOptions
+	logger  *logrus.Entry
+
+	pending []*bufferedOp
+	stats   BufferStats
+	mu      sync.Mutex
+
+	// 串行化重放，避免与新写入交错
+	drainMu sync.Mutex
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewBufferedStore 包装存储后端并启动后台重放循环
+func NewBufferedStore(backend Store, opts BufferOptions) (*BufferedStore, error) {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 10000
+	}
+	if opts.DrainInterval <= 0 {
+		opts.DrainInterval = 5 * time.Second
+	}
+
+	logger := logging.For("storage.buffer")
+
+	s := &BufferedStore{
+		backend: backend,
+		opts:    opts,
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	if opts.Path != "" {
+		pending, err := loadBufferFile(opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		s.pending = pending
+		s.stats.Pending = len(pending)
+		if len(pending) > 0 {
+			logger.Infof("Loaded %d buffered writes from %s", len(pending), opts.Path)
+		}
+	}
+
+	go s.drainLoop()
+	return s, nil
+}
+
+// Put 写入键值数据，后端失败时进入缓冲
+func (s *BufferedStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	return s.write(ctx, &bufferedOp{
+		Op:     opPut,
+		Bucket: bucket,
+		Key:    key,
+		Value:  append([]byte(nil), value...),
+	})
+}
+
+// Get 读取键值数据，优先返回缓冲中尚未写入的最新值
+func (s *BufferedStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	s.mu.Lock()
+	for i := len(s.pending) - 1; i >= 0; i-- {
+		op := s.pending[i]
+		if op.Op == opPut && op.Bucket == bucket && op.Key == key {
+			value := append([]byte(nil), op.Value...)
+			s.mu.Unlock()
+			return value, nil
+		}
+	}
+	s.mu.Unlock()
+
+	return s.backend.Get(ctx, bucket, key)
+}
+
+// Append 追加记录，后端失败时进入缓冲
+func (s *BufferedStore) Append(ctx context.Context, collection string, record *Record) error {
+	clone := *record
+	clone.Data = append([]byte(nil), record.Data...)
+	return s.write(ctx, &bufferedOp{
+		Op:         opAppend,
+		Collection: collection,
+		Record:     &clone,
+	})
+}
+
+// List 查询记录，并合并缓冲中尚未写入的记录
+func (s *BufferedStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	records, err := s.backend.List(ctx, collection, query)
+
+	s.mu.Lock()
+	var buffered []*Record
+	for _, op := range s.pending {
+		if op.Op == opAppend && op.Collection == collection && query.Match(op.Record) {
+			buffered = append(buffered, op.Record)
+		}
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		if len(buffered) == 0 {
+			return nil, err
+		}
+		// 后端不可用时至少返回缓冲中的数据
+		s.logger.Warnf("Storage backend list failed, serving buffered records only: %v", err)
+	}
+	if len(buffered) == 0 {
+		return records, nil
+	}
+
+	merged := append(append([]*Record(nil), records...), buffered...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return applyLimit(merged, query.Limit), nil
+}
+
+// DeleteBefore 删除过期记录（不缓冲）
+func (s *BufferedStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	return s.backend.DeleteBefore(ctx, collection, before)
+}
+
+// Health 返回后端健康状态及写缓冲状态
+func (s *BufferedStore) Health() Health {
+	health := Health{State: BreakerClosed, Healthy: true}
+	if reporter, ok := s.backend.(HealthReporter); ok {
+		health = reporter.Health()
+	}
+
+	stats := s.Stats()
+	health.Buffer = &stats
+	return health
+}
+
+// Buckets 列出后端的bucket
+func (s *BufferedStore) Buckets(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Buckets(ctx)
+}
+
+// Keys 列出后端bucket中的键
+func (s *BufferedStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Keys(ctx, bucket)
+}
+
+// Collections 列出后端的集合
+func (s *BufferedStore) Collections(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Collections(ctx)
+}
+
+// Stats 返回写缓冲状态
+func (s *BufferedStore) Stats() BufferStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Pending = len(s.pending)
+	return stats
+}
+
+// Close 停止重放循环，尽量写入剩余缓冲后关闭后端
+func (s *BufferedStore) Close() error {
+	close(s.stopCh)
+	<-s.doneCh
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.drain(ctx)
+
+	if remaining := s.Stats().Pending; remaining > 0 {
+		s.logger.Warnf("Closing storage with %d buffered writes pending", remaining)
+	}
+	return s.backend.Close()
+}
+
+// write 写入后端；若已有排队写操作或后端失败则进入缓冲，保证写入顺序
+func (s *BufferedStore) write(ctx context.Context, op *bufferedOp) error {
+	s.mu.Lock()
+	hasPending := len(s.pending) > 0
+	s.mu.Unlock()
+
+	if !hasPending {
+		err := s.apply(ctx, op)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if !errors.Is(err, ErrCircuitOpen) {
+			s.logger.Warnf("Storage backend write failed, buffering: %v", err)
+		}
+		s.mu.Lock()
+		s.stats.LastError = err.Error()
+		s.mu.Unlock()
+	}
+
+	return s.enqueue(op)
+}
+
+func (s *BufferedStore) enqueue(op *bufferedOp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= s.opts.MaxEntries {
+		s.stats.Dropped++
+		return ErrBufferFull
+	}
+
+	op.QueuedAt = time.Now().UTC()
+	s.pending = append(s.pending, op)
+
+	if s.opts.Path != "" {
+		if err := appendBufferFile(s.opts.Path, op); err != nil {
+			s.logger.Warnf("Failed to persist buffered write: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *BufferedStore) apply(ctx context.Context, op *bufferedOp) error {
+	switch op.Op {
+	case opPut:
+		return s.backend.Put(ctx, op.Bucket, op.Key, op.Value)
+	case opAppend:
+		return s.backend.Append(ctx, op.Collection, op.Record)
+	default:
+		return fmt.Errorf("unknown buffered op: %s", op.Op)
+	}
+}
+
+func (s *BufferedStore) drainLoop() {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(s.opts.DrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.opts.DrainInterval*2)
+			s.drain(ctx)
+			cancel()
+		}
+	}
+}
+
+// drain 按顺序重放缓冲中的写操作，遇到失败即停止等待下次重试
+func (s *BufferedStore) drain(ctx context.Context) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	s.mu.Lock()
+	batch := append([]*bufferedOp(nil), s.pending...)
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	applied := 0
+	var lastErr error
+	for _, op := range batch {
+		if err := s.apply(ctx, op); err != nil {
+			lastErr = err
+			break
+		}
+		applied++
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 重放期间新进入缓冲的操作排在batch之后，只移除已写入的部分
+	s.pending = s.pending[applied:]
+	s.stats.Replayed += int64(applied)
+	if lastErr != nil {
+		s.stats.LastError = lastErr.Error()
+	} else {
+		s.stats.LastError = ""
+		s.stats.LastFlush = time.Now().UTC()
+	}
+
+	if applied > 0 {
+		s.logger.Infof("Replayed %d buffered writes (%d pending)", applied, len(s.pending))
+		if s.opts.Path != "" {
+			if err := rewriteBufferFile(s.opts.Path, s.pending); err != nil {
+				s.logger.Warnf("Failed to rewrite write buffer file: %v", err)
+			}
+		}
+	}
+}
+
+// loadBufferFile 读取落盘的缓冲
+func loadBufferFile(path string) ([]*bufferedOp, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write buffer %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var ops []*bufferedOp
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var op bufferedOp
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			// 进程崩溃可能留下不完整的最后一行
+			continue
+		}
+		ops = append(ops, &op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read write buffer %s: %w", path, err)
+	}
+	return ops, nil
+}
+
+func appendBufferFile(path string, op *bufferedOp) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+func rewriteBufferFile(path string, ops []*bufferedOp) error {
+	if len(ops) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".buffer-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	writer := bufio.NewWriter(tmp)
+	for _, op := range ops {
+		data, err := json.Marshal(op)
+		if err != nil {
+			continue
+		}
+		writer.Write(data)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestBufferedStore(t *testing.T, backend Store, opts BufferOptions) *BufferedStore {
+	t.Helper()
+	if opts.DrainInterval == 0 {
+		opts.DrainInterval = time.Hour // 测试中手动重放
+	}
+	store, err := NewBufferedStore(backend, opts)
+	if err != nil {
+		t.Fatalf("NewBufferedStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// 后端故障时写入进入缓冲，读取能看到缓冲的数据，恢复后按顺序重放
+func TestBufferedStoreReplaysAfterRecovery(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	store := newTestBufferedStore(t, backend, BufferOptions{})
+
+	backend.set(errBackendDown, 0)
+	now := time.Now()
+	if err := store.Put(ctx, "state", "k", []byte("v1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	store.Put(ctx, "state", "k", []byte("v2"))
+	store.Append(ctx, "metrics", testRecord("node-1", now))
+
+	if got, err := store.Get(ctx, "state", "k"); err != nil || string(got) != "v2" {
+		t.Errorf("Get() from buffer = %s, %v, want v2", got, err)
+	}
+	if records, err := store.List(ctx, "metrics", Query{}); err != nil || len(records) != 1 {
+		t.Errorf("List() while backend down = %v, %v, want the buffered record", records, err)
+	}
+	stats := store.Stats()
+	if stats.Pending != 3 || stats.LastError != errBackendDown.Error() {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	// 缓冲非空时新的写入排在后面，即使后端已恢复
+	backend.set(nil, 0)
+	store.Put(ctx, "state", "k", []byte("v3"))
+	if _, err := backend.MemoryStore.Get(ctx, "state", "k"); !errors.Is(err, ErrNotFound) {
+		t.Error("write bypassed pending buffered writes")
+	}
+
+	store.Append(ctx, "metrics", testRecord("node-1", now.Add(time.Second)))
+	store.drain(ctx)
+	stats = store.Stats()
+	if stats.Pending != 0 || stats.Replayed != 5 || stats.LastError != "" || stats.LastFlush.IsZero() {
+		t.Errorf("Stats() after drain = %+v", stats)
+	}
+	if got, _ := backend.MemoryStore.Get(ctx, "state", "k"); string(got) != "v3" {
+		t.Errorf("backend value after replay = %s, want v3", got)
+	}
+	if records, _ := backend.MemoryStore.List(ctx, "metrics", Query{}); len(records) != 2 {
+		t.Errorf("backend records after replay = %d, want 2", len(records))
+	}
+}
+
+func TestBufferedStoreDropsWhenFull(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	backend.set(errBackendDown, 0)
+	store := newTestBufferedStore(t, backend, BufferOptions{MaxEntries: 2})
+
+	store.Put(ctx, "state", "a", []byte("1"))
+	store.Put(ctx, "state", "b", []byte("2"))
+	if err := store.Put(ctx, "state", "c", []byte("3")); !errors.Is(err, ErrBufferFull) {
+		t.Errorf("Put() into a full buffer error = %v, want ErrBufferFull", err)
+	}
+	if stats := store.Stats(); stats.Pending != 2 || stats.Dropped != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+// 重放在第一个失败处停止，剩余写入保持顺序
+func TestBufferedStoreDrainStopsAtFailure(t *testing.T) {
+	ctx := context.Background()
+	backend := newFlakyStore()
+	backend.set(errBackendDown, 0)
+	store := newTestBufferedStore(t, backend, BufferOptions{})
+	store.Put(ctx, "state", "a", []byte("1"))
+	store.Put(ctx, "state", "b", []byte("2"))
+
+	store.drain(ctx)
+	if stats := store.Stats(); stats.Pending != 2 || stats.Replayed != 0 {
+		t.Fatalf("Stats() after failed drain = %+v", stats)
+	}
+
+	backend.set(nil, 0)
+	store.drain(ctx)
+	if stats := store.Stats(); stats.Pending != 0 || stats.Replayed != 2 {
+		t.Errorf("Stats() after drain = %+v", stats)
+	}
+}
+
+// 落盘的缓冲在重启后加载并重放，忽略崩溃留下的不完整行
+func TestBufferedStorePersistsPendingWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "buffer", "pending.jsonl")
+	backend := newFlakyStore()
+	backend.set(errBackendDown, 0)
+
+	first, err := NewBufferedStore(backend, BufferOptions{Path: path, DrainInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewBufferedStore() error = %v", err)
+	}
+	first.Put(ctx, "state", "k", []byte("v"))
+	first.Append(ctx, "metrics", testRecord("node-1", time.Now()))
+	first.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("buffer file not written: %v", err)
+	}
+	file.WriteString(`{"op":"put","buck`)
+	file.Close()
+
+	backend.set(nil, 0)
+	second := newTestBufferedStore(t, backend, BufferOptions{Path: path})
+	if pending := second.Stats().Pending; pending != 2 {
+		t.Fatalf("loaded %d buffered writes, want 2", pending)
+	}
+	second.drain(ctx)
+	if got, err := backend.MemoryStore.Get(ctx, "state", "k"); err != nil || string(got) != "v" {
+		t.Errorf("backend value after replay = %s, %v", got, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("buffer file still exists after replay: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EncryptionAlgorithm 加密数据的算法标识
+const EncryptionAlgorithm = "aes-256-gcm"
+
+// ErrDecrypt 数据无法解密（密钥不匹配或数据被篡改）
+var ErrDecrypt = errors.New("storage: failed to decrypt data")
+
+// EncryptionOptions 静态加密参数
+type EncryptionOptions struct {
+	Key          string   // 当前密钥（32字节，base64或hex编码），用于加密新数据
+	PreviousKeys []string // 轮换前的旧密钥，只用于解密
+	Collections  []string // 需要加密的记录集合
+	Buckets      []string // 需要加密的键值bucket
+	Strict       bool     // 拒绝读取未加密的数据（历史明文迁移完成后开启）
+}
+
+// encryptedEnvelope 加密后写入后端的数据格式（保持为合法JSON，兼容各存储后端）
+type encryptedEnvelope struct {
+	Enc  string `json:"enc"`
+	KID  string `json:"kid"`
+	Data string `json:"data"` // base64(nonce || ciphertext)
+}
+
+// EncryptedStore 对指定集合和bucket的数据做静态加密（AES-256-GCM）。
+// 未加密的历史数据读取时原样返回，开启加密前写入的数据无需迁移；
+// 严格模式下拒绝未加密的数据，防止绕过加密直接写入后端的明文被当作可信数据读取
+type EncryptedStore struct {
+	backend     Store
+	current     string
+	keys        map[string]cipher.AEAD
+	collections map[string]bool
+	buckets     map[string]bool
+	strict      bool
+}
+
+// NewEncryptedStore 创建加密存储
+func NewEncryptedStore(backend Store, opts EncryptionOptions) (*EncryptedStore, error) {
+	s := &EncryptedStore{
+		backend:     backend,
+		keys:        make(map[string]cipher.AEAD),
+		collections: toSet(opts.Collections),
+		buckets:     toSet(opts.Buckets),
+		strict:      opts.Strict,
+	}
+
+	kid, aead, err := newAEAD(opts.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	s.current = kid
+	s.keys[kid] = aead
+
+	for i, key := range opts.PreviousKeys {
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		kid, aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous encryption key #%d: %w", i+1, err)
+		}
+		if _, exists := s.keys[kid]; !exists {
+			s.keys[kid] = aead
+		}
+	}
+	return s, nil
+}
+
+// newAEAD 解析密钥并创建AES-GCM实例，返回密钥ID（密钥SHA-256摘要的前4字节）
+func newAEAD(encoded string) (string, cipher.AEAD, error) {
+	key, err := decodeKey(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4]), aead, nil
+}
+
+// decodeKey 解码32字节密钥（支持hex和标准/URL base64）
+func decodeKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("key is empty")
+	}
+	if len(encoded) == 64 {
+		if key, err := hex.DecodeString(encoded); err == nil {
+			return key, nil
+		}
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(encoded); err == nil {
+			if len(key) != 32 {
+				return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key must be 32 bytes encoded as base64 or hex")
+}
+
+// toSet 字符串列表转为集合
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// seal 加密数据；associated 绑定数据所在位置，防止密文被移动到其他键下
+func (s *EncryptedStore) seal(plaintext []byte, associated string) ([]byte, error) {
+	aead := s.keys[s.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(associated))
+
+	return json.Marshal(encryptedEnvelope{
+		Enc:  EncryptionAlgorithm,
+		KID:  s.current,
+		Data: base64.StdEncoding.EncodeToString(sealed),
+	})
+}
+
+// parseEnvelope 解析加密格式的数据，不是加密格式时返回false
+func parseEnvelope(data []byte) (*encryptedEnvelope, bool) {
+	if len(data) == 0 || data[0] != '{' {
+		return nil, false
+	}
+	var envelope encryptedEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Enc != EncryptionAlgorithm || envelope.Data == "" {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// open 解密数据；不是加密格式的数据原样返回，严格模式下返回 ErrDecrypt
+func (s *EncryptedStore) open(data []byte, associated string) ([]byte, error) {
+	envelope, ok := parseEnvelope(data)
+	if !ok {
+		if s.strict {
+			return nil, fmt.Errorf("%w: data is not encrypted", ErrDecrypt)
+		}
+		return data, nil
+	}
+
+	aead, ok := s.keys[envelope.KID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %s", ErrDecrypt, envelope.KID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(envelope.Data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrDecrypt)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(associated))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return plaintext, nil
+}
+
+// Put 写入键值数据
+func (s *EncryptedStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	if s.buckets[bucket] {
+		sealed, err := s.seal(value, bucket+"/"+key)
+		if err != nil {
+			return err
+		}
+		value = sealed
+	}
+	return s.backend.Put(ctx, bucket, key, value)
+}
+
+// Get 读取键值数据
+func (s *EncryptedStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	value, err := s.backend.Get(ctx, bucket, key)
+	if err != nil || !s.buckets[bucket] {
+		return value, err
+	}
+	return s.open(value, bucket+"/"+key)
+}
+
+// Append 追加记录（不修改调用方的记录）
+func (s *EncryptedStore) Append(ctx context.Context, collection string, record *Record) error {
+	if !s.collections[collection] || record == nil {
+		return s.backend.Append(ctx, collection, record)
+	}
+	sealed, err := s.seal(record.Data, collection+"/"+record.Key)
+	if err != nil {
+		return err
+	}
+	encrypted := *record
+	encrypted.Data = sealed
+	return s.backend.Append(ctx, collection, &encrypted)
+}
+
+// List 查询记录；无法解密的记录会导致查询失败，而不是静默丢弃
+func (s *EncryptedStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	records, err := s.backend.List(ctx, collection, query)
+	if err != nil || !s.collections[collection] {
+		return records, err
+	}
+
+	result := make([]*Record, 0, len(records))
+	for _, record := range records {
+		plaintext, err := s.open(record.Data, collection+"/"+record.Key)
+		if err != nil {
+			return nil, fmt.Errorf("record %s in %s: %w", record.ID, collection, err)
+		}
+		decrypted := *record
+		decrypted.Data = plaintext
+		result = append(result, &decrypted)
+	}
+	return result, nil
+}
+
+// DeleteBefore 删除过期记录
+func (s *EncryptedStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	return s.backend.DeleteBefore(ctx, collection, before)
+}
+
+// Buckets 列出后端的bucket
+func (s *EncryptedStore) Buckets(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Buckets(ctx)
+}
+
+// Keys 列出后端bucket中的键
+func (s *EncryptedStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Keys(ctx, bucket)
+}
+
+// Collections 列出后端的集合
+func (s *EncryptedStore) Collections(ctx context.Context) ([]string, error) {
+	enumerator, ok := s.backend.(Enumerator)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support enumeration")
+	}
+	return enumerator.Collections(ctx)
+}
+
+// Health 返回后端健康状态，并标记已启用加密
+func (s *EncryptedStore) Health() Health {
+	health := Health{State: BreakerClosed, Healthy: true}
+	if reporter, ok := s.backend.(HealthReporter); ok {
+		health = reporter.Health()
+	}
+	health.Encrypted = true
+	health.KeyID = s.current
+	return health
+}
+
+// Unwrap 返回保存密文的后端（备份直接导出密文）
+func (s *EncryptedStore) Unwrap() Store {
+	return s.backend
+}
+
+// Close 关闭后端
+func (s *EncryptedStore) Close() error {
+	return s.backend.Close()
+}
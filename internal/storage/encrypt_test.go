@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const otherEncryptionKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=" // base64("fedcba9876543210fedcba9876543210")
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryStore()
+	store := newTestEncryptedStore(t, backend)
+
+	value := []byte(`{"position":"39.9042,116.4074"}`)
+	if err := store.Put(ctx, "state", "metrics", value); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	record := &Record{ID: "r1", Key: "UAV-1", Timestamp: time.Now(), Data: json.RawMessage(`{"gps":"39.9042"}`)}
+	if err := store.Append(ctx, "uav_telemetry", record); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if string(record.Data) != `{"gps":"39.9042"}` {
+		t.Errorf("Append() modified the caller's record: %s", record.Data)
+	}
+
+	raw, _ := backend.Get(ctx, "state", "metrics")
+	envelope, ok := parseEnvelope(raw)
+	if !ok || strings.Contains(string(raw), "39.9042") {
+		t.Fatalf("backend value is not an envelope: %s", raw)
+	}
+	if envelope.KID != store.Health().KeyID {
+		t.Errorf("envelope kid = %s, want %s", envelope.KID, store.Health().KeyID)
+	}
+
+	got, err := store.Get(ctx, "state", "metrics")
+	if err != nil || string(got) != string(value) {
+		t.Errorf("Get() = %s, %v, want %s", got, err, value)
+	}
+	records, err := store.List(ctx, "uav_telemetry", Query{})
+	if err != nil || len(records) != 1 || string(records[0].Data) != `{"gps":"39.9042"}` {
+		t.Errorf("List() = %v, %v", records, err)
+	}
+
+	// 不在加密范围内的数据原样写入
+	store.Put(ctx, "reports", "daily", []byte("plain"))
+	if raw, _ := backend.Get(ctx, "reports", "daily"); string(raw) != "plain" {
+		t.Errorf("unencrypted bucket stored as %s", raw)
+	}
+}
+
+// 密文绑定所在位置，被移动到其他键或记录下时无法解密
+func TestEncryptedStoreBindsLocation(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryStore()
+	store := newTestEncryptedStore(t, backend)
+
+	store.Put(ctx, "state", "uav-1", []byte(`{"battery":90}`))
+	raw, _ := backend.Get(ctx, "state", "uav-1")
+	backend.Put(ctx, "state", "uav-2", raw)
+	if _, err := store.Get(ctx, "state", "uav-2"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Get() of a value moved to another key error = %v, want ErrDecrypt", err)
+	}
+
+	store.Append(ctx, "uav_telemetry", &Record{ID: "r1", Key: "UAV-1", Timestamp: time.Now(), Data: json.RawMessage(`{}`)})
+	records, _ := backend.List(ctx, "uav_telemetry", Query{})
+	moved := *records[0]
+	moved.ID, moved.Key = "r2", "UAV-2"
+	backend.Append(ctx, "uav_telemetry", &moved)
+	if _, err := store.List(ctx, "uav_telemetry", Query{Key: "UAV-2"}); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("List() of a record moved to another key error = %v, want ErrDecrypt", err)
+	}
+}
+
+// 轮换后新数据使用新密钥，旧数据用 previous_keys 中的旧密钥解密
+func TestEncryptedStoreKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryStore()
+	old := newTestEncryptedStore(t, backend)
+	old.Put(ctx, "state", "before", []byte("old data"))
+
+	rotated, err := NewEncryptedStore(backend, EncryptionOptions{
+		Key:          otherEncryptionKey,
+		PreviousKeys: []string{testEncryptionKey, " "},
+		Buckets:      []string{"state"},
+	})
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	if rotated.Health().KeyID == old.Health().KeyID {
+		t.Fatalf("rotated store uses the old key id %s", old.Health().KeyID)
+	}
+	if got, err := rotated.Get(ctx, "state", "before"); err != nil || string(got) != "old data" {
+		t.Errorf("Get() with previous key = %s, %v", got, err)
+	}
+
+	rotated.Put(ctx, "state", "after", []byte("new data"))
+	raw, _ := backend.Get(ctx, "state", "after")
+	if envelope, ok := parseEnvelope(raw); !ok || envelope.KID != rotated.Health().KeyID {
+		t.Errorf("new data sealed as %s, want key id %s", raw, rotated.Health().KeyID)
+	}
+
+	// 旧密钥移出 previous_keys 后旧数据不可读
+	newOnly, _ := NewEncryptedStore(backend, EncryptionOptions{Key: otherEncryptionKey, Buckets: []string{"state"}})
+	if _, err := newOnly.Get(ctx, "state", "before"); !errors.Is(err, ErrDecrypt) || !strings.Contains(err.Error(), "unknown key id") {
+		t.Errorf("Get() without the previous key error = %v, want unknown key id", err)
+	}
+}
+
+func TestEncryptedStoreDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryStore()
+	store := newTestEncryptedStore(t, backend)
+	store.Put(ctx, "state", "metrics", []byte(`{"battery":90}`))
+	raw, _ := backend.Get(ctx, "state", "metrics")
+	envelope, _ := parseEnvelope(raw)
+	sealed, _ := base64.StdEncoding.DecodeString(envelope.Data)
+
+	tests := []struct {
+		name   string
+		mutate func(e encryptedEnvelope) encryptedEnvelope
+	}{
+		{name: "flipped ciphertext bit", mutate: func(e encryptedEnvelope) encryptedEnvelope {
+			data := append([]byte(nil), sealed...)
+			data[len(data)-1] ^= 0x01
+			e.Data = base64.StdEncoding.EncodeToString(data)
+			return e
+		}},
+		{name: "truncated", mutate: func(e encryptedEnvelope) encryptedEnvelope {
+			e.Data = base64.StdEncoding.EncodeToString(sealed[:4])
+			return e
+		}},
+		{name: "not base64", mutate: func(e encryptedEnvelope) encryptedEnvelope {
+			e.Data = "!!!"
+			return e
+		}},
+		{name: "unknown key id", mutate: func(e encryptedEnvelope) encryptedEnvelope {
+			e.KID = "00000000"
+			return e
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.mutate(*envelope))
+			backend.Put(ctx, "state", "metrics", data)
+			if got, err := store.Get(ctx, "state", "metrics"); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Get() = %s, %v, want ErrDecrypt", got, err)
+			}
+		})
+	}
+}
+
+// 默认兼容开启加密前的明文；严格模式下拒绝直接写入后端的明文
+func TestEncryptedStoreStrictRejectsPlaintext(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryStore()
+	backend.Put(ctx, "state", "metrics", []byte(`{"battery":5}`))
+	backend.Append(ctx, "uav_telemetry", &Record{ID: "r1", Key: "UAV-1", Timestamp: time.Now(), Data: json.RawMessage(`{"gps":"0,0"}`)})
+
+	lenient := newTestEncryptedStore(t, backend)
+	if got, err := lenient.Get(ctx, "state", "metrics"); err != nil || string(got) != `{"battery":5}` {
+		t.Errorf("lenient Get() = %s, %v", got, err)
+	}
+
+	strict, err := NewEncryptedStore(backend, EncryptionOptions{
+		Key:         testEncryptionKey,
+		Collections: []string{"uav_telemetry"},
+		Buckets:     []string{"state"},
+		Strict:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	if got, err := strict.Get(ctx, "state", "metrics"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("strict Get() of plaintext = %s, %v, want ErrDecrypt", got, err)
+	}
+	if records, err := strict.List(ctx, "uav_telemetry", Query{}); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("strict List() of plaintext = %v, %v, want ErrDecrypt", records, err)
+	}
+
+	// 加密写入的数据和不在加密范围内的明文照常读取
+	strict.Put(ctx, "state", "metrics", []byte(`{"battery":80}`))
+	if got, err := strict.Get(ctx, "state", "metrics"); err != nil || string(got) != `{"battery":80}` {
+		t.Errorf("strict Get() = %s, %v", got, err)
+	}
+	backend.Put(ctx, "reports", "daily", []byte("plain"))
+	if got, err := strict.Get(ctx, "reports", "daily"); err != nil || string(got) != "plain" {
+		t.Errorf("strict Get() of an unencrypted bucket = %s, %v", got, err)
+	}
+}
+
+func TestNewEncryptedStoreRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		opts EncryptionOptions
+	}{
+		{name: "empty key", opts: EncryptionOptions{}},
+		{name: "short key", opts: EncryptionOptions{Key: base64.StdEncoding.EncodeToString([]byte("short"))}},
+		{name: "not encoded", opts: EncryptionOptions{Key: "not a key!"}},
+		{name: "invalid previous key", opts: EncryptionOptions{Key: testEncryptionKey, PreviousKeys: []string{"bad"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEncryptedStore(NewMemoryStore(), tt.opts); err == nil {
+				t.Error("NewEncryptedStore() error = nil")
+			}
+		})
+	}
+
+	hexKey := strings.Repeat("ab", 32)
+	if _, err := NewEncryptedStore(NewMemoryStore(), EncryptionOptions{Key: hexKey}); err != nil {
+		t.Errorf("NewEncryptedStore() with a hex key error = %v", err)
+	}
+}
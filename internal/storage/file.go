@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const recordFileLayout = "2006-01-02"
+
+// FileStore 基于本地文件的存储，每个键保存为 <path>/<bucket>/<key>.json，
+// 时间序列记录按天追加到 <path>/<collection>/<YYYY-MM-DD>.jsonl
+type FileStore struct {
+	root string
+	mu   sync.RWMutex
+}
+
+// NewFileStore 创建文件存储
+func NewFileStore(root string) (*FileStore, error) {
+	if root == "" {
+		root = "./data"
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", root, err)
+	}
+
+	return &FileStore{root: root}, nil
+}
+
+// Put 写入键值数据（先写临时文件再重命名，避免写入中断导致文件损坏）
+func (s *FileStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.root, sanitizePathElement(bucket))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create bucket directory: %w", err)
+	}
+
+	target := filepath.Join(dir, sanitizePathElement(key)+".json")
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s/%s: %w", bucket, key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, target); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to rename %s: %w", target, err)
+	}
+	return nil
+}
+
+// Get 读取键值数据
+func (s *FileStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	target := filepath.Join(s.root, sanitizePathElement(bucket), sanitizePathElement(key)+".json")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s/%s: %w", bucket, key, err)
+	}
+	return data, nil
+}
+
+// Append 追加记录
+func (s *FileStore) Append(ctx context.Context, collection string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := s.collectionDir(collection)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create collection directory: %w", err)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	name := filepath.Join(dir, record.Timestamp.UTC().Format(recordFileLayout)+".jsonl")
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append record to %s: %w", name, err)
+	}
+	return nil
+}
+
+// List 查询记录
+func (s *FileStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	days, err := s.listDays(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Record
+	for _, day := range days {
+		// 跳过查询时间范围之外的文件
+		if !query.From.IsZero() && day.Add(24*time.Hour).Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && day.After(query.To) {
+			continue
+		}
+
+		records, err := s.readDay(collection, day)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if query.Match(record) {
+				result = append(result, record)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return applyLimit(result, query.Limit), nil
+}
+
+// DeleteBefore 删除早于指定时间的记录（整天过期的文件直接删除，边界文件重写）
+func (s *FileStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, err := s.listDays(collection)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, day := range days {
+		if !day.Before(before) {
+			continue
+		}
+
+		records, err := s.readDay(collection, day)
+		if err != nil {
+			return deleted, err
+		}
+
+		name := s.dayFile(collection, day)
+		if !day.Add(24 * time.Hour).After(before) {
+			if err := os.Remove(name); err != nil {
+				return deleted, fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+			deleted += len(records)
+			continue
+		}
+
+		var buf []byte
+		for _, record := range records {
+			if record.Timestamp.Before(before) {
+				deleted++
+				continue
+			}
+			line, err := json.Marshal(record)
+			if err != nil {
+				return deleted, fmt.Errorf("failed to marshal record: %w", err)
+			}
+			buf = append(buf, line...)
+			buf = append(buf, '\n')
+		}
+		if err := os.WriteFile(name, buf, 0o644); err != nil {
+			return deleted, fmt.Errorf("failed to rewrite %s: %w", name, err)
+		}
+	}
+
+	return deleted, nil
+}
+
+// collectionDir 集合目录
+func (s *FileStore) collectionDir(collection string) string {
+	return filepath.Join(s.root, sanitizePathElement(collection))
+}
+
+// dayFile 指定日期的记录文件
+func (s *FileStore) dayFile(collection string, day time.Time) string {
+	return filepath.Join(s.collectionDir(collection), day.Format(recordFileLayout)+".jsonl")
+}
+
+// listDays 列出集合中存在记录文件的日期（升序）
+func (s *FileStore) listDays(collection string) ([]time.Time, error) {
+	entries, err := os.ReadDir(s.collectionDir(collection))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read collection %s: %w", collection, err)
+	}
+
+	var days []time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		day, err := time.Parse(recordFileLayout, strings.TrimSuffix(name, ".jsonl"))
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// readDay 读取指定日期的全部记录
+func (s *FileStore) readDay(collection string, day time.Time) ([]*Record, error) {
+	name := s.dayFile(collection, day)
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			// 跳过损坏的行（例如写入过程中进程退出）
+			continue
+		}
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return records, nil
+}
+
+// Buckets 列出包含键值文件的目录
+func (s *FileStore) Buckets(ctx context.Context) ([]string, error) {
+	return s.listDirsWith(func(name string) bool {
+		return strings.HasSuffix(name, ".json") && !strings.HasPrefix(name, ".")
+	})
+}
+
+// Keys 列出bucket中的键
+func (s *FileStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.root, sanitizePathElement(bucket)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read bucket %s: %w", bucket, err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, ".json"))
+	}
+	return keys, nil
+}
+
+// Collections 列出包含按天记录文件的目录
+func (s *FileStore) Collections(ctx context.Context) ([]string, error) {
+	return s.listDirsWith(func(name string) bool {
+		if !strings.HasSuffix(name, ".jsonl") {
+			return false
+		}
+		_, err := time.Parse(recordFileLayout, strings.TrimSuffix(name, ".jsonl"))
+		return err == nil
+	})
+}
+
+// listDirsWith 列出至少包含一个满足条件文件的子目录
+func (s *FileStore) listDirsWith(match func(name string) bool) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.root, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !file.IsDir() && match(file.Name()) {
+				dirs = append(dirs, entry.Name())
+				break
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// Close 关闭存储
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// sanitizePathElement 将键转换为安全的文件名
+func sanitizePathElement(name string) string {
+	name = strings.TrimSpace(name)
+	replacer := strings.NewReplacer("/", "_", "\\", "_", "..", "_", ":", "_")
+	name = replacer.Replace(name)
+	if name == "" {
+		return "_"
+	}
+	return name
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore 内存存储（进程重启后数据丢失）
+type MemoryStore struct {
+	data    map[string]map[string][]byte
+	records map[string][]*Record
+	mu      sync.RWMutex
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data:    make(map[string]map[string][]byte),
+		records: make(map[string][]*Record),
+	}
+}
+
+// Put 写入键值数据
+func (s *MemoryStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data[bucket] == nil {
+		s.data[bucket] = make(map[string][]byte)
+	}
+	s.data[bucket][key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Get 读取键值数据
+func (s *MemoryStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.data[bucket][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Append 追加记录
+func (s *MemoryStore) Append(ctx context.Context, collection string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone := *record
+	clone.Data = append([]byte(nil), record.Data...)
+
+	records := s.records[collection]
+	records = append(records, &clone)
+	// 大多数记录按时间顺序到达，仅在乱序时排序
+	if n := len(records); n > 1 && records[n-1].Timestamp.Before(records[n-2].Timestamp) {
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		})
+	}
+	s.records[collection] = records
+	return nil
+}
+
+// List 查询记录
+func (s *MemoryStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Record
+	for _, record := range s.records[collection] {
+		if query.Match(record) {
+			result = append(result, record)
+		}
+	}
+	return applyLimit(result, query.Limit), nil
+}
+
+// DeleteBefore 删除早于指定时间的记录
+func (s *MemoryStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := s.records[collection]
+	kept := records[:0]
+	for _, record := range records {
+		if !record.Timestamp.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := len(records) - len(kept)
+	s.records[collection] = kept
+	return deleted, nil
+}
+
+// Close 关闭存储
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Buckets 列出所有bucket
+func (s *MemoryStore) Buckets(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buckets := make([]string, 0, len(s.data))
+	for bucket := range s.data {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+// Keys 列出bucket中的键
+func (s *MemoryStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.data[bucket]))
+	for key := range s.data[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Collections 列出所有集合
+func (s *MemoryStore) Collections(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	collections := make([]string, 0, len(s.records))
+	for collection, records := range s.records {
+		if len(records) > 0 {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
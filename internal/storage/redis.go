@@ -0,0 +1,401 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// redisMaxIdle 连接池中保留的空闲连接数
+const redisMaxIdle = 8
+
+// RedisOptions Redis存储参数
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+	Prefix   string        // 键前缀，多套部署共用一个Redis时区分数据
+	Timeout  time.Duration // 连接和单次命令的超时
+}
+
+// RedisStore Redis存储：键值数据保存为字符串，记录集合保存为按时间排序的有序集合，
+// 多个服务副本可共享同一份数据
+type RedisStore struct {
+	opts RedisOptions
+	idle []*redisConn
+	mu   sync.Mutex
+}
+
+// redisConn 一个RESP2连接
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// redisError Redis返回的错误回复
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisStore 创建Redis存储并检查连通性
+func NewRedisStore(opts RedisOptions) (*RedisStore, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("redis address is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "k8s-llm-monitor"
+	}
+
+	s := &RedisStore{opts: opts}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	if _, err := s.do(ctx, "PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Addr, err)
+	}
+	return s, nil
+}
+
+// kvKey 键值数据的Redis键
+func (s *RedisStore) kvKey(bucket, key string) string {
+	return s.opts.Prefix + ":kv:" + bucket + ":" + key
+}
+
+// collectionKey 记录集合的Redis键
+func (s *RedisStore) collectionKey(collection string) string {
+	return s.opts.Prefix + ":ts:" + collection
+}
+
+// Put 写入键值数据
+func (s *RedisStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	_, err := s.do(ctx, "SET", s.kvKey(bucket, key), string(value))
+	return err
+}
+
+// Get 读取键值数据
+func (s *RedisStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	reply, err := s.do(ctx, "GET", s.kvKey(bucket, key))
+	if err != nil {
+		return nil, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+// Append 追加记录，以毫秒时间戳作为有序集合的分数
+func (s *RedisStore) Append(ctx context.Context, collection string, record *Record) error {
+	member, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+	_, err = s.do(ctx, "ZADD", s.collectionKey(collection), strconv.FormatInt(record.Timestamp.UnixMilli(), 10), string(member))
+	return err
+}
+
+// List 按时间范围读取记录，再按键和精确时间过滤
+func (s *RedisStore) List(ctx context.Context, collection string, query Query) ([]*Record, error) {
+	min, max := "-inf", "+inf"
+	if !query.From.IsZero() {
+		min = strconv.FormatInt(query.From.UnixMilli(), 10)
+	}
+	if !query.To.IsZero() {
+		max = strconv.FormatInt(query.To.UnixMilli()+1, 10)
+	}
+	reply, err := s.do(ctx, "ZRANGEBYSCORE", s.collectionKey(collection), min, max)
+	if err != nil {
+		return nil, err
+	}
+	members, _ := reply.([]interface{})
+
+	result := make([]*Record, 0, len(members))
+	for _, member := range members {
+		text, _ := member.(string)
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			continue
+		}
+		if query.Match(&record) {
+			result = append(result, &record)
+		}
+	}
+	// 同一毫秒内的记录按成员字典序返回，这里按精确时间重新排序
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return applyLimit(result, query.Limit), nil
+}
+
+// DeleteBefore 删除早于指定时间的记录
+func (s *RedisStore) DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error) {
+	reply, err := s.do(ctx, "ZREMRANGEBYSCORE", s.collectionKey(collection), "-inf", "("+strconv.FormatInt(before.UnixMilli(), 10))
+	if err != nil {
+		return 0, err
+	}
+	removed, _ := reply.(int64)
+	return int(removed), nil
+}
+
+// Buckets 列出所有键值bucket
+func (s *RedisStore) Buckets(ctx context.Context) ([]string, error) {
+	keys, err := s.scan(ctx, escapeRedisPattern(s.opts.Prefix+":kv:")+"*")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var buckets []string
+	for _, key := range keys {
+		bucket, _, ok := strings.Cut(strings.TrimPrefix(key, s.opts.Prefix+":kv:"), ":")
+		if ok && !seen[bucket] {
+			seen[bucket] = true
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+// Keys 列出bucket中的所有键
+func (s *RedisStore) Keys(ctx context.Context, bucket string) ([]string, error) {
+	prefix := s.kvKey(bucket, "")
+	keys, err := s.scan(ctx, escapeRedisPattern(prefix)+"*")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, strings.TrimPrefix(key, prefix))
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Collections 列出所有记录集合
+func (s *RedisStore) Collections(ctx context.Context) ([]string, error) {
+	keys, err := s.scan(ctx, escapeRedisPattern(s.opts.Prefix+":ts:")+"*")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, strings.TrimPrefix(key, s.opts.Prefix+":ts:"))
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// scan 用SCAN遍历匹配的键（不阻塞Redis）
+func (s *RedisStore) scan(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := s.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "500")
+		if err != nil {
+			return nil, err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("redis: unexpected SCAN reply")
+		}
+		cursor, _ = parts[0].(string)
+		batch, _ := parts[1].([]interface{})
+		for _, key := range batch {
+			if text, ok := key.(string); ok {
+				keys = append(keys, text)
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// escapeRedisPattern 转义键中的glob特殊字符
+func escapeRedisPattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(value)
+}
+
+// Close 关闭空闲连接
+func (s *RedisStore) Close() error {
+	s.discardIdle()
+	return nil
+}
+
+// discardIdle 关闭并丢弃所有空闲连接
+func (s *RedisStore) discardIdle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.idle {
+		c.conn.Close()
+	}
+	s.idle = nil
+}
+
+// do 执行一条命令。连接出错时丢弃该连接，Redis的错误回复不影响连接复用。
+// 空闲连接可能已被Redis关闭（空闲超时、重启或主从切换），此时同一时刻关闭的其他空闲连接也不可用，
+// 丢弃全部空闲连接后在新连接上重试一次；这里用到的命令都是幂等的，重试不会重复写入
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, reused, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := s.exec(ctx, c, args)
+	if err != nil && reused && connClosed(err) {
+		s.discardIdle()
+		if c, _, err = s.conn(ctx); err != nil {
+			return nil, err
+		}
+		reply, err = s.exec(ctx, c, args)
+	}
+	return reply, err
+}
+
+// connClosed 错误是否表示连接已被对端关闭（不包括超时：命令可能已经执行）
+func connClosed(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// exec 在连接上执行命令，完成后归还连接，连接出错时关闭
+func (s *RedisStore) exec(ctx context.Context, c *redisConn, args []string) (interface{}, error) {
+	deadline := time.Now().Add(s.opts.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	reply, err := c.command(args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.conn.Close()
+		return nil, err
+	}
+	s.release(c)
+	return reply, err
+}
+
+// conn 取出空闲连接或建立新连接（认证并选择数据库），reused 表示是否为复用的空闲连接
+func (s *RedisStore) conn(ctx context.Context) (c *redisConn, reused bool, err error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, true, nil
+	}
+	s.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: s.opts.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.opts.Addr)
+	if err != nil {
+		return nil, false, err
+	}
+	c = &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(s.opts.Timeout))
+	if s.opts.Password != "" {
+		if _, err := c.command("AUTH", s.opts.Password); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	if s.opts.DB != 0 {
+		if _, err := c.command("SELECT", strconv.Itoa(s.opts.DB)); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	return c, false, nil
+}
+
+// release 归还连接
+func (s *RedisStore) release(c *redisConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.idle) >= redisMaxIdle {
+		c.conn.Close()
+		return
+	}
+	s.idle = append(s.idle, c)
+}
+
+// command 发送命令并读取回复
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply 读取一个RESP2回复：字符串、整数、nil（空回复）或数组
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			// 数组中的错误回复作为元素返回
+			item, err := c.readReply()
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisStore(t *testing.T, mr *miniredis.Miniredis, opts RedisOptions) *RedisStore {
+	t.Helper()
+	opts.Addr = mr.Addr()
+	opts.Timeout = 2 * time.Second
+	store, err := NewRedisStore(opts)
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func testRecord(key string, ts time.Time) *Record {
+	data, _ := json.Marshal(map[string]string{"key": key})
+	return &Record{ID: key + "-" + ts.Format(time.RFC3339Nano), Key: key, Timestamp: ts, Data: data}
+}
+
+func TestRedisStoreKeyValue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	// 前缀中的glob字符在SCAN时需要转义，不能匹配到其他部署的键
+	store := newTestRedisStore(t, mr, RedisOptions{Prefix: "mon*[a]"})
+	other := newTestRedisStore(t, mr, RedisOptions{Prefix: "monx[a]"})
+	ctx := context.Background()
+
+	binary := "line1\r\nline2\x00\xff"
+	if err := store.Put(ctx, "reports", "2024-01-01", []byte(binary)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	store.Put(ctx, "reports", "2024-01-02", []byte("b"))
+	store.Put(ctx, "config", "current", []byte("{}"))
+	store.Append(ctx, "node-metrics", testRecord("node-1", time.Now()))
+	other.Put(ctx, "other", "key", []byte("x"))
+	other.Append(ctx, "other-metrics", testRecord("node-1", time.Now()))
+
+	got, err := store.Get(ctx, "reports", "2024-01-01")
+	if err != nil || string(got) != binary {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, binary)
+	}
+	if _, err := store.Get(ctx, "reports", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing key error = %v, want ErrNotFound", err)
+	}
+
+	buckets, err := store.Buckets(ctx)
+	if err != nil || strings.Join(buckets, ",") != "config,reports" {
+		t.Errorf("Buckets() = %v, %v", buckets, err)
+	}
+	keys, err := store.Keys(ctx, "reports")
+	if err != nil || strings.Join(keys, ",") != "2024-01-01,2024-01-02" {
+		t.Errorf("Keys() = %v, %v", keys, err)
+	}
+	collections, err := store.Collections(ctx)
+	if err != nil || strings.Join(collections, ",") != "node-metrics" {
+		t.Errorf("Collections() = %v, %v", collections, err)
+	}
+}
+
+func TestRedisStoreAuthAndSelect(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireAuth("s3cret")
+	ctx := context.Background()
+
+	if _, err := NewRedisStore(RedisOptions{Addr: mr.Addr(), Password: "wrong"}); err == nil {
+		t.Fatal("NewRedisStore() with a wrong password should fail")
+	}
+	if _, err := NewRedisStore(RedisOptions{Addr: mr.Addr()}); err == nil {
+		t.Fatal("NewRedisStore() without a password should fail")
+	}
+
+	store := newTestRedisStore(t, mr, RedisOptions{Password: "s3cret", DB: 3, Prefix: "test"})
+	if err := store.Put(ctx, "config", "current", []byte("v1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Append(ctx, "events", testRecord("pod-1", time.Now())); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if value, err := mr.DB(3).Get("test:kv:config:current"); err != nil || value != "v1" {
+		t.Errorf("DB 3 value = %q, %v, want v1", value, err)
+	}
+	if members, err := mr.DB(3).ZMembers("test:ts:events"); err != nil || len(members) != 1 {
+		t.Errorf("DB 3 collection = %v, %v, want 1 member", members, err)
+	}
+	if mr.DB(0).Exists("test:kv:config:current") {
+		t.Error("value was written to DB 0")
+	}
+}
+
+func TestRedisStoreListAndDeleteBefore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newTestRedisStore(t, mr, RedisOptions{})
+	ctx := context.Background()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms float64) time.Time { return base.Add(time.Duration(ms * float64(time.Millisecond))) }
+	// 乱序追加，同一毫秒内有多条记录（有序集合按成员字典序返回）
+	for _, r := range []*Record{
+		testRecord("node-2", at(3)),
+		testRecord("node-1", at(1.7)),
+		testRecord("node-1", at(1.2)),
+		testRecord("node-2", at(0)),
+		testRecord("node-1", at(5)),
+		testRecord("node-2", at(1.5)),
+	} {
+		if err := store.Append(ctx, "metrics", r); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	stamps := func(records []*Record) []time.Duration {
+		var result []time.Duration
+		for _, r := range records {
+			result = append(result, r.Timestamp.Sub(base))
+		}
+		return result
+	}
+	ms := func(values ...float64) []time.Duration {
+		var result []time.Duration
+		for _, v := range values {
+			result = append(result, time.Duration(v*float64(time.Millisecond)))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []time.Duration
+	}{
+		{name: "all", query: Query{}, want: ms(0, 1.2, 1.5, 1.7, 3, 5)},
+		{name: "by key", query: Query{Key: "node-1"}, want: ms(1.2, 1.7, 5)},
+		// 分数精确到毫秒，From/To 之外但在同一毫秒内的记录由精确时间过滤掉
+		{name: "exact range", query: Query{From: at(1.5), To: at(3)}, want: ms(1.5, 1.7, 3)},
+		{name: "to inside a millisecond", query: Query{To: at(1.6)}, want: ms(0, 1.2, 1.5)},
+		{name: "limit keeps newest", query: Query{Limit: 2}, want: ms(3, 5)},
+		{name: "key and limit", query: Query{Key: "node-2", From: at(1), Limit: 1}, want: ms(3)},
+		{name: "empty range", query: Query{From: at(10)}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := store.List(ctx, "metrics", tt.query)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got := stamps(records); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if records, _ := store.List(ctx, "missing", Query{}); len(records) != 0 {
+		t.Errorf("List() on a missing collection = %d records", len(records))
+	}
+
+	// 早于3ms的记录（0、1.2、1.5、1.7）被删除，恰好在3ms的保留
+	removed, err := store.DeleteBefore(ctx, "metrics", at(3))
+	if err != nil || removed != 4 {
+		t.Fatalf("DeleteBefore() = %d, %v, want 4", removed, err)
+	}
+	records, _ := store.List(ctx, "metrics", Query{})
+	if got := stamps(records); fmt.Sprint(got) != fmt.Sprint(ms(3, 5)) {
+		t.Errorf("after DeleteBefore List() = %v", got)
+	}
+	if removed, err := store.DeleteBefore(ctx, "metrics", at(3)); err != nil || removed != 0 {
+		t.Errorf("second DeleteBefore() = %d, %v, want 0", removed, err)
+	}
+}
+
+// Redis的错误回复不影响连接复用，只有一个连接
+func TestRedisStoreErrorReplyKeepsConnection(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newTestRedisStore(t, mr, RedisOptions{Prefix: "test"})
+	ctx := context.Background()
+
+	mr.Set("test:ts:broken", "not a sorted set")
+	err := store.Append(ctx, "broken", testRecord("node-1", time.Now()))
+	var replyErr redisError
+	if !errors.As(err, &replyErr) || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Fatalf("Append() to a string key error = %v, want WRONGTYPE reply", err)
+	}
+	if _, err := store.List(ctx, "broken", Query{}); err == nil {
+		t.Error("List() on a string key should fail")
+	}
+	if err := store.Put(ctx, "config", "current", []byte("ok")); err != nil {
+		t.Fatalf("Put() after an error reply = %v", err)
+	}
+	if got := mr.TotalConnectionCount(); got != 1 {
+		t.Errorf("opened %d connections, want 1 reused connection", got)
+	}
+}
+
+// Redis重启后空闲连接都已失效，下一条命令丢弃它们并在新连接上执行成功
+func TestRedisStoreReconnectsAfterRestart(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newTestRedisStore(t, mr, RedisOptions{DB: 2})
+	ctx := context.Background()
+
+	// 并发请求在池中留下多个空闲连接
+	fillPool := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				store.Put(ctx, "config", "current", []byte("v1"))
+			}()
+		}
+		wg.Wait()
+	}
+	fillPool()
+	mr.Close()
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	// 重启后数据为空，新连接需要重新SELECT
+	if err := store.Put(ctx, "config", "current", []byte("v2")); err != nil {
+		t.Fatalf("Put() after restart error = %v", err)
+	}
+	if value, err := mr.DB(2).Get("k8s-llm-monitor:kv:config:current"); err != nil || value != "v2" {
+		t.Errorf("DB 2 value = %q, %v, want v2", value, err)
+	}
+	if got, err := store.Get(ctx, "config", "current"); err != nil || string(got) != "v2" {
+		t.Errorf("Get() after restart = %q, %v", got, err)
+	}
+
+	// Redis不可用期间命令失败，恢复后无需重建存储
+	fillPool()
+	mr.Close()
+	if err := store.Put(ctx, "config", "current", []byte("v3")); err == nil {
+		t.Fatal("Put() while redis is down should fail")
+	}
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if err := store.Put(ctx, "config", "current", []byte("v3")); err != nil {
+		t.Fatalf("Put() after redis came back error = %v", err)
+	}
+}
+
+func TestRedisStoreContextDeadline(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := newTestRedisStore(t, mr, RedisOptions{})
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if err := store.Put(ctx, "config", "current", []byte("v")); err == nil {
+		t.Fatal("Put() with an expired context should fail")
+	}
+	if err := store.Put(context.Background(), "config", "current", []byte("v")); err != nil {
+		t.Errorf("Put() after a timed out command error = %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/k8s-llm-monitor/internal/logging"
+)
+
+// RetentionPolicy 集合的保留与降采样策略
+type RetentionPolicy struct {
+	Collection      string
+	MaxAge          time.Duration // 超过该时长的记录被删除，0表示不删除
+	DownsampleAfter time.Duration // 超过该时长的记录被降采样，0表示不降采样
+	DownsampleStep  time.Duration // 降采样后每个实体在每个时间窗口内保留一条记录
+}
+
+// RetentionStats 保留任务统计
+type RetentionStats struct {
+	Collection      string        `json:"collection"`
+	Runs            int64         `json:"runs"`
+	RowsDeleted     int64         `json:"rows_deleted"`
+	RowsDownsampled int64         `json:"rows_downsampled"`
+	LastRun         time.Time     `json:"last_run,omitempty"`
+	LastDuration    time.Duration `json:"last_duration_ns"`
+	LastError       string        `json:"last_error,omitempty"`
+}
+
+// RetentionJob 后台压缩与保留任务
+type RetentionJob struct {
+	store    Store
+	policies []RetentionPolicy
+	interval time.Duration
+	logger   *logrus.Entry
+
+	stats map[string]*RetentionStats
+	mu    sync.RWMutex
+}
+
+// NewRetentionJob 创建保留任务
+func NewRetentionJob(store Store, policies []RetentionPolicy, interval time.Duration) *RetentionJob {
+	logger := logging.For("storage.retention")
+
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	stats := make(map[string]*RetentionStats, len(policies))
+	for _, policy := range policies {
+		stats[policy.Collection] = &RetentionStats{Collection: policy.Collection}
+	}
+
+	return &RetentionJob{
+		store:    store,
+		policies: policies,
+		interval: interval,
+		logger:   logger,
+		stats:    stats,
+	}
+}
+
+// Start 启动定时任务
+func (j *RetentionJob) Start(ctx context.Context) {
+	j.logger.Infof("Retention job started (%d policies, interval: %s)", len(j.policies), j.interval)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 对所有策略执行一次保留与降采样
+func (j *RetentionJob) RunOnce(ctx context.Context) {
+	for _, policy := range j.policies {
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		deleted, downsampled, err := j.apply(ctx, policy)
+		duration := time.Since(start)
+
+		j.mu.Lock()
+		stats := j.stats[policy.Collection]
+		stats.Runs++
+		stats.RowsDeleted += int64(deleted)
+		stats.RowsDownsampled += int64(downsampled)
+		stats.LastRun = start.UTC()
+		stats.LastDuration = duration
+		stats.LastError = ""
+		if err != nil {
+			stats.LastError = err.Error()
+		}
+		j.mu.Unlock()
+
+		if err != nil {
+			j.logger.Warnf("Retention for %s failed: %v", policy.Collection, err)
+		} else if deleted > 0 || downsampled > 0 {
+			j.logger.Infof("Retention for %s: deleted %d, downsampled %d rows in %s",
+				policy.Collection, deleted, downsampled, duration)
+		}
+	}
+}
+
+// Stats 返回各集合的任务统计
+func (j *RetentionJob) Stats() []RetentionStats {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	result := make([]RetentionStats, 0, len(j.policies))
+	for _, policy := range j.policies {
+		result = append(result, *j.stats[policy.Collection])
+	}
+	return result
+}
+
+// apply 执行单个策略，返回删除数与降采样移除数
+func (j *RetentionJob) apply(ctx context.Context, policy RetentionPolicy) (int, int, error) {
+	now := time.Now()
+
+	deleted := 0
+	if policy.MaxAge > 0 {
+		n, err := j.store.DeleteBefore(ctx, policy.Collection, now.Add(-policy.MaxAge))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to delete expired records: %w", err)
+		}
+		deleted = n
+	}
+
+	downsampled := 0
+	if policy.DownsampleAfter > 0 && policy.DownsampleStep > 0 {
+		n, err := j.downsample(ctx, policy, now.Add(-policy.DownsampleAfter))
+		if err != nil {
+			return deleted, 0, err
+		}
+		downsampled = n
+	}
+
+	return deleted, downsampled, nil
+}
+
+// downsample 将cutoff之前的记录按实体和时间窗口稀疏化
+func (j *RetentionJob) downsample(ctx context.Context, policy RetentionPolicy, cutoff time.Time) (int, error) {
+	// Query.To 为闭区间，DeleteBefore 为开区间，这里保持一致
+	records, err := j.store.List(ctx, policy.Collection, Query{To: cutoff.Add(-time.Nanosecond)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list records for downsampling: %w", err)
+	}
+
+	kept := make([]*Record, 0, len(records))
+	seen := make(map[string]struct{})
+	for _, record := range records {
+		slot := fmt.Sprintf("%s/%d", record.Key, record.Timestamp.Truncate(policy.DownsampleStep).Unix())
+		if _, exists := seen[slot]; exists {
+			continue
+		}
+		seen[slot] = struct{}{}
+		kept = append(kept, record)
+	}
+
+	removed := len(records) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	if _, err := j.store.DeleteBefore(ctx, policy.Collection, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete records for downsampling: %w", err)
+	}
+	for _, record := range kept {
+		if err := j.store.Append(ctx, policy.Collection, record); err != nil {
+			return removed, fmt.Errorf("failed to rewrite downsampled record: %w", err)
+		}
+	}
+	return removed, nil
+}
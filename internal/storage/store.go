@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/config"
+)
+
+// ErrNotFound 数据不存在
+var ErrNotFound = errors.New("storage: not found")
+
+// Store 持久化存储接口
+type Store interface {
+	// Put 写入键值数据（覆盖已有数据）
+	Put(ctx context.Context, bucket, key string, value []byte) error
+
+	// Get 读取键值数据，不存在时返回 ErrNotFound
+	Get(ctx context.Context, bucket, key string) ([]byte, error)
+
+	// Append 向集合追加一条时间序列记录
+	Append(ctx context.Context, collection string, record *Record) error
+
+	// List 按条件查询集合中的记录（按时间升序）
+	List(ctx context.Context, collection string, query Query) ([]*Record, error)
+
+	// DeleteBefore 删除集合中早于指定时间的记录，返回删除数量
+	DeleteBefore(ctx context.Context, collection string, before time.Time) (int, error)
+
+	// Close 关闭存储
+	Close() error
+}
+
+// Record 时间序列记录
+type Record struct {
+	ID        string          `json:"id"`
+	Key       string          `json:"key"` // 实体标识（节点名、Pod名等）
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Query 记录查询条件
+type Query struct {
+	Key   string    // 为空时不过滤
+	From  time.Time // 为零值时不限制
+	To    time.Time // 为零值时不限制
+	Limit int       // <=0 表示不限制，超出时保留最新的记录
+}
+
+// Match 判断记录是否满足查询条件
+func (q Query) Match(record *Record) bool {
+	if q.Key != "" && record.Key != q.Key {
+		return false
+	}
+	if !q.From.IsZero() && record.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && record.Timestamp.After(q.To) {
+		return false
+	}
+	return true
+}
+
+// applyLimit 截取最新的limit条记录
+func applyLimit(records []*Record, limit int) []*Record {
+	if limit > 0 && len(records) > limit {
+		return records[len(records)-limit:]
+	}
+	return records
+}
+
+// New 根据配置创建存储后端（启用熔断、写缓冲和加密时自动包装）
+func New(cfg *config.StorageConfig) (Store, error) {
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Breaker.Enabled {
+		name := cfg.Type
+		if name == "" {
+			name = "memory"
+		}
+		backend = NewBreakerStore(backend, name, BreakerOptions{
+			FailureThreshold: cfg.Breaker.FailureThreshold,
+			Cooldown:         time.Duration(cfg.Breaker.Cooldown) * time.Second,
+			SlowThreshold:    time.Duration(cfg.Breaker.SlowThreshold) * time.Millisecond,
+			OpTimeout:        time.Duration(cfg.Breaker.OpTimeout) * time.Second,
+		})
+	}
+
+	if cfg.Buffer.Enabled {
+		buffered, err := NewBufferedStore(backend, BufferOptions{
+			MaxEntries:    cfg.Buffer.MaxEntries,
+			Path:          cfg.Buffer.Path,
+			DrainInterval: time.Duration(cfg.Buffer.DrainInterval) * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		backend = buffered
+	}
+
+	// 加密放在最外层，写缓冲落盘的数据同样是密文
+	if !cfg.Encryption.Enabled {
+		return backend, nil
+	}
+	encrypted, err := NewEncryptedStore(backend, EncryptionOptions{
+		Key:          cfg.Encryption.Key,
+		PreviousKeys: cfg.Encryption.PreviousKeys,
+		Collections:  cfg.Encryption.Collections,
+		Buckets:      cfg.Encryption.Buckets,
+		Strict:       cfg.Encryption.Strict,
+	})
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// newBackend 创建底层存储后端
+func newBackend(cfg *config.StorageConfig) (Store, error) {
+	storageType := strings.ToLower(strings.TrimSpace(cfg.Type))
+	switch storageType {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(cfg.Path)
+	case "redis":
+		return NewRedisStore(RedisOptions{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			Prefix:   cfg.Redis.Prefix,
+			Timeout:  time.Duration(cfg.Redis.Timeout) * time.Second,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+	}
+}
@@ -0,0 +1,330 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet文件格式的最小实现：所有列为OPTIONAL，单个行组，每列一个PLAIN编码、不压缩的数据页，
+// 元数据使用Thrift compact协议编码。只实现导出需要的部分，避免引入Arrow/Parquet依赖（测试中用 parquet-go 读取验证）
+
+const parquetMagic = "PAR1"
+
+// Parquet的物理类型
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet枚举值
+const (
+	repetitionOptional   = 1
+	convertedUTF8        = 0
+	convertedTimestampMs = 9
+	encodingPlain        = 0
+	encodingRLE          = 3
+	codecUncompressed    = 0
+	pageTypeData         = 0
+	logicalTypeString    = 1 // LogicalType union中的字段ID
+	logicalTypeTimestamp = 8
+	timeUnitMillis       = 1
+	parquetFormatVersion = 1
+	parquetCreatedBy     = "k8s-llm-monitor"
+)
+
+// Thrift compact协议的类型
+const (
+	thriftStop      = 0
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// physicalType 列类型对应的Parquet物理类型
+func physicalType(t Type) int32 {
+	switch t {
+	case Float:
+		return parquetDouble
+	case Int, Time:
+		return parquetInt64
+	case Bool:
+		return parquetBoolean
+	default:
+		return parquetByteArray
+	}
+}
+
+// columnChunk 写出的一列数据的位置
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// WriteParquet 写出Parquet文件
+func (t *Table) WriteParquet(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(parquetMagic)
+
+	var chunks []columnChunk
+	if len(t.Rows) > 0 {
+		for col := range t.Columns {
+			page := t.encodeColumn(col)
+			header := encodePageHeader(len(t.Rows), len(page))
+			chunk := columnChunk{offset: int64(buf.Len()), size: int64(len(header) + len(page))}
+			buf.Write(header)
+			buf.Write(page)
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	metadata := t.encodeFileMetaData(chunks)
+	buf.Write(metadata)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(metadata))))
+	buf.WriteString(parquetMagic)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// encodeColumn 编码一列的数据页：定义级别（长度前缀 + RLE）和非空值（PLAIN）
+func (t *Table) encodeColumn(col int) []byte {
+	levels := make([]bool, len(t.Rows))
+	var values []byte
+	var bits []bool
+	for row := range t.Rows {
+		v, ok := t.value(row, col)
+		levels[row] = ok
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(v)))
+			values = append(values, v...)
+		case float64:
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(v))
+		case int64:
+			values = binary.LittleEndian.AppendUint64(values, uint64(v))
+		case time.Time:
+			values = binary.LittleEndian.AppendUint64(values, uint64(v.UnixMilli()))
+		case bool:
+			bits = append(bits, v)
+		}
+	}
+	if t.Columns[col].Type == Bool {
+		// BOOLEAN的PLAIN编码为按位打包，低位在前
+		values = make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				values[i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+
+	encoded := encodeLevels(levels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(encoded)))
+	page = append(page, encoded...)
+	return append(page, values...)
+}
+
+// encodeLevels 用RLE/位打包混合编码（位宽1）中的RLE游程编码定义级别
+func encodeLevels(levels []bool) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if levels[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// encodePageHeader 编码数据页的PageHeader
+func encodePageHeader(numValues, size int) []byte {
+	var e thriftEncoder
+	e.i32(1, pageTypeData)
+	e.i32(2, int32(size))
+	e.i32(3, int32(size))
+	e.beginStruct(5) // DataPageHeader
+	e.i32(1, int32(numValues))
+	e.i32(2, encodingPlain)
+	e.i32(3, encodingRLE)
+	e.i32(4, encodingRLE)
+	e.endStruct()
+	e.stop()
+	return e.buf
+}
+
+// encodeFileMetaData 编码文件尾部的FileMetaData
+func (t *Table) encodeFileMetaData(chunks []columnChunk) []byte {
+	var e thriftEncoder
+	e.i32(1, parquetFormatVersion)
+
+	// schema：根节点之后依次为各列
+	e.beginList(2, thriftStruct, len(t.Columns)+1)
+	e.beginElement()
+	e.binary(4, "schema")
+	e.i32(5, int32(len(t.Columns)))
+	e.endStruct()
+	for _, column := range t.Columns {
+		e.beginElement()
+		e.i32(1, physicalType(column.Type))
+		e.i32(3, repetitionOptional)
+		e.binary(4, column.Name)
+		switch column.Type {
+		case String:
+			e.i32(6, convertedUTF8)
+			e.beginStruct(10)
+			e.beginStruct(logicalTypeString)
+			e.endStruct()
+			e.endStruct()
+		case Time:
+			e.i32(6, convertedTimestampMs)
+			e.beginStruct(10)
+			e.beginStruct(logicalTypeTimestamp)
+			e.bool(1, true) // isAdjustedToUTC
+			e.beginStruct(2)
+			e.beginStruct(timeUnitMillis)
+			e.endStruct()
+			e.endStruct()
+			e.endStruct()
+			e.endStruct()
+		}
+		e.endStruct()
+	}
+
+	e.i64(3, int64(len(t.Rows)))
+
+	// 行组：有数据时为一个，包含全部行
+	if len(chunks) == 0 {
+		e.beginList(4, thriftStruct, 0)
+	} else {
+		e.beginList(4, thriftStruct, 1)
+		e.beginElement()
+		e.beginList(1, thriftStruct, len(chunks))
+		var total int64
+		for col, chunk := range chunks {
+			total += chunk.size
+			e.beginElement()
+			e.i64(2, chunk.offset)
+			e.beginStruct(3) // ColumnMetaData
+			e.i32(1, physicalType(t.Columns[col].Type))
+			e.beginList(2, thriftI32, 2)
+			e.listI32(encodingPlain)
+			e.listI32(encodingRLE)
+			e.beginList(3, thriftBinary, 1)
+			e.listBinary(t.Columns[col].Name)
+			e.i32(4, codecUncompressed)
+			e.i64(5, int64(len(t.Rows)))
+			e.i64(6, chunk.size)
+			e.i64(7, chunk.size)
+			e.i64(9, chunk.offset)
+			e.endStruct()
+			e.endStruct()
+		}
+		e.i64(2, total)
+		e.i64(3, int64(len(t.Rows)))
+		e.endStruct()
+	}
+
+	e.binary(6, parquetCreatedBy)
+	e.stop()
+	return e.buf
+}
+
+// thriftEncoder Thrift compact协议编码：字段头记录与上一个字段ID的差值，嵌套结构体各自从0开始
+type thriftEncoder struct {
+	buf    []byte
+	last   int16
+	nested []int16
+}
+
+func (e *thriftEncoder) field(id int16, typ byte) {
+	if delta := id - e.last; delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta)<<4|typ)
+	} else {
+		e.buf = append(e.buf, typ)
+		e.buf = binary.AppendVarint(e.buf, int64(id))
+	}
+	e.last = id
+}
+
+func (e *thriftEncoder) i32(id int16, v int32) {
+	e.field(id, thriftI32)
+	e.buf = binary.AppendVarint(e.buf, int64(v))
+}
+
+func (e *thriftEncoder) i64(id int16, v int64) {
+	e.field(id, thriftI64)
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *thriftEncoder) bool(id int16, v bool) {
+	if v {
+		e.field(id, thriftBoolTrue)
+	} else {
+		e.field(id, thriftBoolFalse)
+	}
+}
+
+func (e *thriftEncoder) binary(id int16, v string) {
+	e.field(id, thriftBinary)
+	e.listBinary(v)
+}
+
+// beginStruct 开始一个结构体类型的字段
+func (e *thriftEncoder) beginStruct(id int16) {
+	e.field(id, thriftStruct)
+	e.beginElement()
+}
+
+// beginElement 开始列表中的一个结构体元素
+func (e *thriftEncoder) beginElement() {
+	e.nested = append(e.nested, e.last)
+	e.last = 0
+}
+
+func (e *thriftEncoder) endStruct() {
+	e.stop()
+	e.last = e.nested[len(e.nested)-1]
+	e.nested = e.nested[:len(e.nested)-1]
+}
+
+func (e *thriftEncoder) stop() {
+	e.buf = append(e.buf, thriftStop)
+}
+
+func (e *thriftEncoder) beginList(id int16, elem byte, size int) {
+	e.field(id, thriftList)
+	if size < 15 {
+		e.buf = append(e.buf, byte(size)<<4|elem)
+	} else {
+		e.buf = append(e.buf, 0xF0|elem)
+		e.buf = binary.AppendUvarint(e.buf, uint64(size))
+	}
+}
+
+func (e *thriftEncoder) listI32(v int32) {
+	e.buf = binary.AppendVarint(e.buf, int64(v))
+}
+
+func (e *thriftEncoder) listBinary(v string) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(v)))
+	e.buf = append(e.buf, v...)
+}
@@ -0,0 +1,144 @@
+package tabular
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// readParquet 用 parquet-go 读取写出的文件，返回schema和全部行
+func readParquet(t *testing.T, table *Table) (*parquet.File, []parquet.Row) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := table.WriteParquet(&buf); err != nil {
+		t.Fatalf("WriteParquet() error = %v", err)
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("parquet.OpenFile() error = %v", err)
+	}
+
+	var rows []parquet.Row
+	for _, group := range file.RowGroups() {
+		reader := group.Rows()
+		batch := make([]parquet.Row, 16)
+		for {
+			n, err := reader.ReadRows(batch)
+			for _, row := range batch[:n] {
+				rows = append(rows, row.Clone())
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("ReadRows() error = %v", err)
+			}
+		}
+		reader.Close()
+	}
+	return file, rows
+}
+
+func TestWriteParquetRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 15, 250e6, time.UTC)
+	table := New(
+		Column{Name: "pod", Type: String},
+		Column{Name: "cpu", Type: Float},
+		Column{Name: "restarts", Type: Int},
+		Column{Name: "ready", Type: Bool},
+		Column{Name: "timestamp", Type: Time},
+	)
+	table.Append("web-1", 0.25, int64(3), true, ts)
+	table.Append(nil, nil, nil, nil, nil)
+	table.Append("", -1.5, int64(-7), false, time.Time{})
+	// 类型不匹配的值视为空
+	table.Append(42, "x", 1.0, "true", ts.Unix())
+	for i := 0; i < 20; i++ {
+		table.Append("bulk", float64(i), int64(i), i%3 == 0, ts.Add(time.Duration(i)*time.Second))
+	}
+
+	file, rows := readParquet(t, table)
+	if file.NumRows() != int64(len(table.Rows)) || len(rows) != len(table.Rows) {
+		t.Fatalf("got %d rows (metadata %d), want %d", len(rows), file.NumRows(), len(table.Rows))
+	}
+
+	fields := file.Schema().Fields()
+	for i, column := range table.Columns {
+		if fields[i].Name() != column.Name || !fields[i].Optional() {
+			t.Errorf("field %d = %s (optional %v), want optional %s", i, fields[i].Name(), fields[i].Optional(), column.Name)
+		}
+	}
+	if logical := fields[0].Type().LogicalType(); logical.String() != "STRING" {
+		t.Errorf("pod logical type = %v, want STRING", logical)
+	}
+	if logical := fields[4].Type().LogicalType(); logical.String() != "TIMESTAMP(isAdjustedToUTC=true,unit=MILLIS)" {
+		t.Errorf("timestamp logical type = %v, want TIMESTAMP(MILLIS, UTC)", logical)
+	}
+
+	for i, row := range rows {
+		if len(row) != len(table.Columns) {
+			t.Fatalf("row %d has %d values, want %d", i, len(row), len(table.Columns))
+		}
+		for col := range table.Columns {
+			want, ok := table.value(i, col)
+			got := row[col]
+			if got.Column() != col {
+				t.Errorf("row %d value %d belongs to column %d", i, col, got.Column())
+			}
+			if got.IsNull() != !ok {
+				t.Errorf("row %d column %s null = %v, want %v", i, table.Columns[col].Name, got.IsNull(), !ok)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			var match bool
+			switch want := want.(type) {
+			case string:
+				match = string(got.ByteArray()) == want
+			case float64:
+				match = got.Double() == want
+			case int64:
+				match = got.Int64() == want
+			case bool:
+				match = got.Boolean() == want
+			case time.Time:
+				match = got.Int64() == want.UnixMilli()
+			}
+			if !match {
+				t.Errorf("row %d column %s = %v, want %v", i, table.Columns[col].Name, got, want)
+			}
+		}
+	}
+}
+
+func TestWriteParquetEmptyTable(t *testing.T) {
+	table := New(Column{Name: "node", Type: String}, Column{Name: "ready", Type: Bool}, Column{Name: "timestamp", Type: Time})
+	file, rows := readParquet(t, table)
+	if file.NumRows() != 0 || len(rows) != 0 {
+		t.Errorf("empty table has %d rows (metadata %d)", len(rows), file.NumRows())
+	}
+	if got := len(file.Schema().Fields()); got != 3 {
+		t.Errorf("empty table schema has %d fields, want 3", got)
+	}
+}
+
+func TestWriteParquetAllNullColumn(t *testing.T) {
+	table := New(Column{Name: "error", Type: String}, Column{Name: "ok", Type: Bool})
+	for i := 0; i < 100; i++ {
+		table.Append(nil, true)
+	}
+	_, rows := readParquet(t, table)
+	if len(rows) != 100 {
+		t.Fatalf("got %d rows, want 100", len(rows))
+	}
+	for i, row := range rows {
+		if !row[0].IsNull() || row[1].IsNull() || !row[1].Boolean() {
+			t.Fatalf("row %d = %v, want [null true]", i, row)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// 导出为表格的数据：每列一个固定类型，供CSV和Parquet导出使用，
+// 数据分析时可以直接用 pandas.read_csv / read_parquet 读取而无需展开JSON
+
+// Type 列的类型
+type Type int
+
+const (
+	String Type = iota
+	Float
+	Int
+	Bool
+	Time // UTC时间，Parquet中为毫秒时间戳
+)
+
+// Column 列定义
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Table 按行保存的表格，值为nil（或零值时间）时为空
+type Table struct {
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// New 创建表格
+func New(columns ...Column) *Table {
+	return &Table{Columns: columns}
+}
+
+// Append 追加一行，值的顺序和类型与列定义一致：string、float64、int64、bool、time.Time 或nil
+func (t *Table) Append(values ...interface{}) {
+	if len(values) != len(t.Columns) {
+		panic(fmt.Sprintf("tabular: row has %d values, table has %d columns", len(values), len(t.Columns)))
+	}
+	t.Rows = append(t.Rows, values)
+}
+
+// value 取出某一单元格，类型不匹配时视为空
+func (t *Table) value(row, col int) (interface{}, bool) {
+	v := t.Rows[row][col]
+	switch t.Columns[col].Type {
+	case String:
+		s, ok := v.(string)
+		return s, ok
+	case Float:
+		f, ok := v.(float64)
+		return f, ok
+	case Int:
+		i, ok := v.(int64)
+		return i, ok
+	case Bool:
+		b, ok := v.(bool)
+		return b, ok
+	case Time:
+		ts, ok := v.(time.Time)
+		return ts, ok && !ts.IsZero()
+	}
+	return nil, false
+}
+
+// WriteCSV 写出带表头的CSV，时间为RFC3339（UTC），空值为空字符串
+func (t *Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		record[i] = column.Name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	for row := range t.Rows {
+		for col := range t.Columns {
+			record[col] = ""
+			v, ok := t.value(row, col)
+			if !ok {
+				continue
+			}
+			switch v := v.(type) {
+			case string:
+				record[col] = v
+			case float64:
+				record[col] = strconv.FormatFloat(v, 'g', -1, 64)
+			case int64:
+				record[col] = strconv.FormatInt(v, 10)
+			case bool:
+				record[col] = strconv.FormatBool(v)
+			case time.Time:
+				record[col] = v.UTC().Format(time.RFC3339Nano)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
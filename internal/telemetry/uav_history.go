@@ -0,0 +1,180 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/storage"
+	"github.com/yourusername/k8s-llm-monitor/internal/tabular"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// UAVCollection UAV遥测历史在存储中的集合名
+const UAVCollection = "uav_telemetry"
+
+// UAVHistory UAV遥测历史记录器
+type UAVHistory struct {
+	store storage.Store
+	step  time.Duration // 降采样间隔，同一架UAV在间隔内只保存一个点
+
+	// 每架UAV最近保存的数据点，用于降采样和检测模式变化
+	last map[string]*models.UAVTelemetryPoint
+	mu   sync.Mutex
+}
+
+// UAVHistoryResult UAV遥测历史查询结果
+type UAVHistoryResult struct {
+	NodeName    string                      `json:"node_name"`
+	From        time.Time                   `json:"from,omitempty"`
+	To          time.Time                   `json:"to,omitempty"`
+	Points      []*models.UAVTelemetryPoint `json:"points"`
+	ModeChanges []models.UAVModeChange      `json:"mode_changes"`
+}
+
+// Table 将遥测点导出为表格（CSV/Parquet），每个点一行
+func (r *UAVHistoryResult) Table() *tabular.Table {
+	table := tabular.New(
+		tabular.Column{Name: "timestamp", Type: tabular.Time},
+		tabular.Column{Name: "node_name", Type: tabular.String},
+		tabular.Column{Name: "uav_id", Type: tabular.String},
+		tabular.Column{Name: "status", Type: tabular.String},
+		tabular.Column{Name: "latitude", Type: tabular.Float},
+		tabular.Column{Name: "longitude", Type: tabular.Float},
+		tabular.Column{Name: "altitude", Type: tabular.Float},
+		tabular.Column{Name: "relative_altitude", Type: tabular.Float},
+		tabular.Column{Name: "ground_speed", Type: tabular.Float},
+		tabular.Column{Name: "battery_percent", Type: tabular.Float},
+		tabular.Column{Name: "battery_voltage", Type: tabular.Float},
+		tabular.Column{Name: "flight_mode", Type: tabular.String},
+		tabular.Column{Name: "armed", Type: tabular.Bool},
+		tabular.Column{Name: "system_status", Type: tabular.String},
+	)
+	for _, p := range r.Points {
+		table.Append(p.Timestamp, p.NodeName, p.UAVID, p.Status, p.Latitude, p.Longitude, p.Altitude,
+			p.RelativeAltitude, p.GroundSpeed, p.BatteryPercent, p.BatteryVoltage, p.FlightMode, p.Armed, p.SystemStatus)
+	}
+	return table
+}
+
+// NewUAVHistory 创建UAV遥测历史记录器
+func NewUAVHistory(store storage.Store, step time.Duration) *UAVHistory {
+	return &UAVHistory{
+		store: store,
+		step:  step,
+		last:  make(map[string]*models.UAVTelemetryPoint),
+	}
+}
+
+// Record 保存一次UAV上报；在降采样间隔内的上报会被跳过，
+// 但飞行模式、解锁状态或系统状态发生变化时总是保存
+func (h *UAVHistory) Record(ctx context.Context, report *models.UAVReport) error {
+	if report == nil || report.NodeName == "" || report.State == nil {
+		return nil
+	}
+
+	point := pointFromReport(report)
+	key := report.Key()
+
+	h.mu.Lock()
+	previous := h.last[key]
+	if previous != nil && h.step > 0 &&
+		point.Timestamp.Sub(previous.Timestamp) < h.step &&
+		!significantChange(previous, point) {
+		h.mu.Unlock()
+		return nil
+	}
+	h.last[key] = point
+	h.mu.Unlock()
+
+	data, err := json.Marshal(point)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry point: %w", err)
+	}
+
+	return h.store.Append(ctx, UAVCollection, &storage.Record{
+		ID:        fmt.Sprintf("%s-%d", key, point.Timestamp.UnixNano()),
+		Key:       key,
+		Timestamp: point.Timestamp,
+		Data:      data,
+	})
+}
+
+// Query 查询指定UAV在时间范围内的遥测历史，nodeName 为节点名或同一个Agent模拟的其他UAV的标识（见 models.UAVKey）
+func (h *UAVHistory) Query(ctx context.Context, nodeName string, from, to time.Time, limit int) (*UAVHistoryResult, error) {
+	records, err := h.store.List(ctx, UAVCollection, storage.Query{
+		Key:   nodeName,
+		From:  from,
+		To:    to,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list telemetry for node %s: %w", nodeName, err)
+	}
+
+	result := &UAVHistoryResult{
+		NodeName:    nodeName,
+		From:        from,
+		To:          to,
+		Points:      make([]*models.UAVTelemetryPoint, 0, len(records)),
+		ModeChanges: []models.UAVModeChange{},
+	}
+
+	var previous *models.UAVTelemetryPoint
+	for _, record := range records {
+		var point models.UAVTelemetryPoint
+		if err := json.Unmarshal(record.Data, &point); err != nil {
+			continue
+		}
+
+		if previous != nil && (previous.FlightMode != point.FlightMode || previous.Armed != point.Armed) {
+			result.ModeChanges = append(result.ModeChanges, models.UAVModeChange{
+				Timestamp: point.Timestamp,
+				From:      previous.FlightMode,
+				To:        point.FlightMode,
+				Armed:     point.Armed,
+			})
+		}
+
+		result.Points = append(result.Points, &point)
+		previous = &point
+	}
+
+	return result, nil
+}
+
+// pointFromReport 将UAV上报转换为历史数据点
+func pointFromReport(report *models.UAVReport) *models.UAVTelemetryPoint {
+	timestamp := report.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+
+	state := report.State
+	return &models.UAVTelemetryPoint{
+		Timestamp:        timestamp,
+		NodeName:         report.NodeName,
+		UAVID:            report.UAVID,
+		Status:           report.Status,
+		Latitude:         state.GPS.Latitude,
+		Longitude:        state.GPS.Longitude,
+		Altitude:         state.GPS.Altitude,
+		RelativeAltitude: state.GPS.RelativeAltitude,
+		GroundSpeed:      state.Flight.GroundSpeed,
+		BatteryPercent:   state.Battery.RemainingPercent,
+		BatteryVoltage:   state.Battery.Voltage,
+		FlightMode:       state.Flight.Mode,
+		Armed:            state.Flight.Armed,
+		SystemStatus:     state.Health.SystemStatus,
+	}
+}
+
+// significantChange 判断两个数据点之间是否有需要保留的状态变化
+func significantChange(previous, current *models.UAVTelemetryPoint) bool {
+	return previous.FlightMode != current.FlightMode ||
+		previous.Armed != current.Armed ||
+		previous.Status != current.Status ||
+		previous.SystemStatus != current.SystemStatus
+}
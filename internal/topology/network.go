@@ -0,0 +1,136 @@
+package topology
+
+import (
+	"strings"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// KindExternal 连通性探测的集群外目标（URL）
+const KindExternal = "external"
+
+// 网络拓扑中的边类型
+const (
+	EdgeNodeLatency = "node_latency" // Node -> Node 探测Agent测得的节点间时延
+	EdgeProbe       = "probe"        // Pod -> Pod/External 定时连通性探测
+)
+
+// ProbeLink 一个连通性探测最近一次的路径和状态（由 netprobe.Status 转换，避免拓扑依赖K8s客户端）
+type ProbeLink struct {
+	Name        string
+	Source      string // 最近一次实际使用的源Pod（namespace/name）
+	Target      string // 目标Pod（namespace/name）或URL
+	External    bool   // 目标为URL
+	State       string // up、down、flapping、unknown
+	RTT         float64
+	SuccessRate float64
+}
+
+// NetworkInput 构建网络拓扑图的数据来源，各项均可为空
+type NetworkInput struct {
+	Snapshot    *metricstypes.MetricsSnapshot
+	NodeLatency *metricstypes.NodeLatencyMatrix
+	Probes      []ProbeLink
+	Pods        []*models.PodInfo
+	Services    []*models.ServiceInfo
+}
+
+// BuildNetwork 构建网络拓扑图：Pod、Service、节点，以及网络测试、节点间时延和连通性探测观察到的连接。
+// 连接边带有 rtt_ms、quality 注解，跨命名空间的Pod连接标记 cross_namespace
+func BuildNetwork(in NetworkInput) *Graph {
+	b := &builder{vertices: make(map[string]*Vertex), edges: make(map[string]*Edge)}
+	b.addSnapshot(in.Snapshot)
+	b.addPods(in.Pods)
+	b.addServices(in.Services, in.Pods)
+	b.addNodeLatency(in.NodeLatency)
+	b.addProbes(in.Probes)
+	b.markCrossNamespace()
+
+	return b.graph()
+}
+
+// addNodeLatency 节点间的时延边（只有一个方向测得时也保留）
+func (b *builder) addNodeLatency(matrix *metricstypes.NodeLatencyMatrix) {
+	if matrix == nil {
+		return
+	}
+	for _, node := range matrix.Nodes {
+		b.vertex(KindNode, node, node, "", nil)
+	}
+	for _, link := range matrix.Links {
+		attributes := map[string]interface{}{
+			"reachable":   link.Reachable,
+			"rtt_ms":      link.RTT,
+			"packet_loss": link.PacketLoss,
+			"quality":     metricstypes.LinkQuality(link.Reachable, link.RTT),
+			"timestamp":   matrix.Timestamp,
+		}
+		if link.Error != "" {
+			attributes["error"] = link.Error
+		}
+		b.edge(EdgeNodeLatency, ID(KindNode, link.Source), ID(KindNode, link.Target), attributes)
+	}
+}
+
+// addProbes 连通性探测的边，目标为URL时添加 external 顶点
+func (b *builder) addProbes(probes []ProbeLink) {
+	for _, p := range probes {
+		if p.Source == "" || p.Target == "" {
+			continue
+		}
+		target := ID(KindPod, p.Target)
+		if p.External {
+			target = ID(KindExternal, p.Target)
+			b.vertex(KindExternal, p.Target, p.Target, "", nil)
+		} else {
+			b.vertex(KindPod, p.Target, podName(p.Target), "", nil)
+		}
+		b.vertex(KindPod, p.Source, podName(p.Source), "", nil)
+
+		attributes := map[string]interface{}{
+			"probe":        p.Name,
+			"state":        p.State,
+			"rtt_ms":       p.RTT,
+			"success_rate": p.SuccessRate,
+			"quality":      metricstypes.LinkQuality(p.State == "up" || p.State == "flapping", p.RTT),
+		}
+		b.namedEdge(EdgeProbe, p.Name, ID(KindPod, p.Source), target, attributes)
+	}
+}
+
+// namedEdge 与 edge 相同，但边ID中带上名称，同一对端点可以有多条同类型的边（例如多个探测）
+func (b *builder) namedEdge(kind, name, source, target string, attributes map[string]interface{}) {
+	id := kind + ":" + name + ":" + source + "->" + target
+	if _, ok := b.edges[id]; ok {
+		return
+	}
+	b.edges[id] = &Edge{ID: id, Kind: kind, Source: source, Target: target, Attributes: attributes}
+}
+
+// markCrossNamespace 标记两端Pod属于不同命名空间的连接边
+func (b *builder) markCrossNamespace() {
+	for _, e := range b.edges {
+		if e.Kind != EdgeNetwork && e.Kind != EdgeProbe {
+			continue
+		}
+		source, target := namespaceOf(e.Source), namespaceOf(e.Target)
+		if source == "" || target == "" || source == target {
+			continue
+		}
+		if e.Attributes == nil {
+			e.Attributes = map[string]interface{}{}
+		}
+		e.Attributes["cross_namespace"] = true
+	}
+}
+
+// namespaceOf 返回Pod顶点ID中的命名空间，其他顶点为空
+func namespaceOf(id string) string {
+	name, ok := strings.CutPrefix(id, KindPod+":")
+	if !ok {
+		return ""
+	}
+	namespace, _, _ := strings.Cut(name, "/")
+	return namespace
+}
@@ -0,0 +1,54 @@
+package topology
+
+import (
+	"testing"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+)
+
+func TestBuildNetwork(t *testing.T) {
+	snapshot := &metricstypes.MetricsSnapshot{NetworkMetrics: []*metricstypes.NetworkMetrics{
+		{SourcePod: "default/api", TargetPod: "data/postgres", Connected: true, RTT: 2},
+		{SourcePod: "default/api", TargetPod: "default/cache", Connected: true, RTT: 1},
+	}}
+	matrix := metricstypes.NewNodeLatencyMatrix(time.Now(), []string{"n1", "n2"}, []metricstypes.NodeLatency{
+		{Source: "n1", Target: "n2", Reachable: true, RTT: 0.4},
+	})
+	probes := []ProbeLink{
+		{Name: "api-db", Source: "default/api", Target: "data/postgres", State: "up", RTT: 3},
+		{Name: "api-egress", Source: "default/api", Target: "https://example.com", External: true, State: "down"},
+	}
+
+	graph := BuildNetwork(NetworkInput{Snapshot: snapshot, NodeLatency: matrix, Probes: probes})
+
+	edges := make(map[string]Edge, len(graph.Edges))
+	for _, e := range graph.Edges {
+		edges[e.ID] = e
+	}
+
+	cross := edges["network:pod:default/api->pod:data/postgres"]
+	if cross.Attributes["cross_namespace"] != true {
+		t.Errorf("cross-namespace test edge not marked: %+v", cross)
+	}
+	if local := edges["network:pod:default/api->pod:default/cache"]; local.Attributes["cross_namespace"] != nil {
+		t.Errorf("same-namespace edge marked as cross-namespace: %+v", local)
+	}
+
+	latency, ok := edges["node_latency:node:n1->node:n2"]
+	if !ok || latency.Attributes["quality"] != "excellent" {
+		t.Errorf("node latency edge = %+v", latency)
+	}
+
+	probe, ok := edges["probe:api-db:pod:default/api->pod:data/postgres"]
+	if !ok || probe.Attributes["cross_namespace"] != true {
+		t.Errorf("probe edge = %+v", probe)
+	}
+	egress, ok := edges["probe:api-egress:pod:default/api->external:https://example.com"]
+	if !ok || egress.Attributes["quality"] != "disconnected" {
+		t.Errorf("external probe edge = %+v", egress)
+	}
+	if graph.Counts[KindExternal] != 1 || graph.Counts[KindNode] != 2 || graph.Counts[KindPod] != 3 {
+		t.Errorf("counts = %v", graph.Counts)
+	}
+}
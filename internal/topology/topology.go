@@ -0,0 +1,267 @@
+package topology
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	metricstypes "github.com/yourusername/k8s-llm-monitor/pkg/metrics"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+)
+
+// 图中的顶点类型
+const (
+	KindNode    = "node"
+	KindPod     = "pod"
+	KindService = "service"
+	KindUAV     = "uav"
+)
+
+// 图中的边类型
+const (
+	EdgeScheduledOn = "scheduled_on" // Pod -> Node
+	EdgeSelects     = "selects"      // Service -> Pod
+	EdgeAttachedTo  = "attached_to"  // UAV -> Node
+	EdgeNetwork     = "network"      // Pod -> Pod 网络测试结果
+)
+
+// Vertex 图中的顶点
+type Vertex struct {
+	ID         string                 `json:"id"`
+	Kind       string                 `json:"kind"`
+	Label      string                 `json:"label"`
+	Status     string                 `json:"status,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Edge 图中的边
+type Edge struct {
+	ID         string                 `json:"id"`
+	Kind       string                 `json:"kind"`
+	Source     string                 `json:"source"`
+	Target     string                 `json:"target"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Graph 集群/机群拓扑图
+type Graph struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Nodes     []Vertex       `json:"nodes"`
+	Edges     []Edge         `json:"edges"`
+	Counts    map[string]int `json:"counts"` // 按顶点类型统计
+}
+
+// Input 构建拓扑图的数据来源，各项均可为空
+type Input struct {
+	Snapshot *metricstypes.MetricsSnapshot
+	UAVs     map[string]*models.UAVMetricsEntry // metrics.Manager.GetUAVMetrics 的结果，key见 models.UAVKey
+	Pods     []*models.PodInfo                  // 用于Service选择器匹配（PodMetrics不含标签）
+	Services []*models.ServiceInfo
+}
+
+// ID 生成顶点ID
+func ID(kind, name string) string {
+	return kind + ":" + name
+}
+
+// builder 去重并收集顶点和边
+type builder struct {
+	vertices map[string]*Vertex
+	edges    map[string]*Edge
+}
+
+// Build 根据指标快照、UAV状态和K8s资源构建拓扑图
+func Build(in Input) *Graph {
+	b := &builder{vertices: make(map[string]*Vertex), edges: make(map[string]*Edge)}
+	b.addSnapshot(in.Snapshot)
+	b.addPods(in.Pods)
+	b.addServices(in.Services, in.Pods)
+	b.addUAVs(in.UAVs)
+	return b.graph()
+}
+
+// vertex 添加顶点（已存在时合并属性，先写入的状态优先）
+func (b *builder) vertex(kind, name, label, status string, attributes map[string]interface{}) *Vertex {
+	id := ID(kind, name)
+	v, ok := b.vertices[id]
+	if !ok {
+		v = &Vertex{ID: id, Kind: kind, Label: label, Status: status, Attributes: map[string]interface{}{}}
+		b.vertices[id] = v
+	} else if v.Status == "" {
+		v.Status = status
+	}
+	for key, value := range attributes {
+		if _, exists := v.Attributes[key]; !exists {
+			v.Attributes[key] = value
+		}
+	}
+	return v
+}
+
+// edge 添加边（同类型同端点的边只保留一条）
+func (b *builder) edge(kind, source, target string, attributes map[string]interface{}) {
+	id := kind + ":" + source + "->" + target
+	if _, ok := b.edges[id]; ok {
+		return
+	}
+	b.edges[id] = &Edge{ID: id, Kind: kind, Source: source, Target: target, Attributes: attributes}
+}
+
+// addSnapshot 节点、Pod和网络测试边
+func (b *builder) addSnapshot(snapshot *metricstypes.MetricsSnapshot) {
+	if snapshot == nil {
+		return
+	}
+
+	for name, node := range snapshot.NodeMetrics {
+		status := "healthy"
+		if !node.Healthy {
+			status = "unhealthy"
+		} else if node.IsUnderPressure() {
+			status = "pressure"
+		}
+		b.vertex(KindNode, name, name, status, map[string]interface{}{
+			"cpu_usage_rate":    node.CPUUsageRate,
+			"memory_usage_rate": node.MemoryUsageRate,
+			"gpu_count":         node.GPUCount,
+			"conditions":        node.Conditions,
+		})
+	}
+
+	for key, pod := range snapshot.PodMetrics {
+		b.vertex(KindPod, key, pod.PodName, strings.ToLower(pod.Phase), map[string]interface{}{
+			"namespace":         pod.Namespace,
+			"ready":             pod.Ready,
+			"restarts":          pod.Restarts,
+			"cpu_usage_rate":    pod.CPUUsageRate,
+			"memory_usage_rate": pod.MemoryUsageRate,
+		})
+		if pod.NodeName != "" {
+			b.vertex(KindNode, pod.NodeName, pod.NodeName, "", nil)
+			b.edge(EdgeScheduledOn, ID(KindPod, key), ID(KindNode, pod.NodeName), nil)
+		}
+	}
+
+	for _, network := range snapshot.NetworkMetrics {
+		if network == nil || network.SourcePod == "" || network.TargetPod == "" {
+			continue
+		}
+		attributes := map[string]interface{}{
+			"connected":   network.Connected,
+			"rtt_ms":      network.RTT,
+			"packet_loss": network.PacketLoss,
+			"quality":     network.GetQuality(),
+			"test_method": network.TestMethod,
+			"timestamp":   network.Timestamp,
+		}
+		if network.Error != "" {
+			attributes["error"] = network.Error
+		}
+		b.vertex(KindPod, network.SourcePod, podName(network.SourcePod), "", nil)
+		b.vertex(KindPod, network.TargetPod, podName(network.TargetPod), "", nil)
+		b.edge(EdgeNetwork, ID(KindPod, network.SourcePod), ID(KindPod, network.TargetPod), attributes)
+	}
+}
+
+// addPods 补充指标快照中没有的Pod（例如Metrics Server不可用时）
+func (b *builder) addPods(pods []*models.PodInfo) {
+	for _, pod := range pods {
+		key := pod.Namespace + "/" + pod.Name
+		b.vertex(KindPod, key, pod.Name, strings.ToLower(pod.Status), map[string]interface{}{
+			"namespace": pod.Namespace,
+			"ip":        pod.IP,
+		})
+		if pod.NodeName != "" {
+			b.vertex(KindNode, pod.NodeName, pod.NodeName, "", nil)
+			b.edge(EdgeScheduledOn, ID(KindPod, key), ID(KindNode, pod.NodeName), nil)
+		}
+	}
+}
+
+// addServices Service及其按标签选择的Pod
+func (b *builder) addServices(services []*models.ServiceInfo, pods []*models.PodInfo) {
+	for _, svc := range services {
+		key := svc.Namespace + "/" + svc.Name
+		ports := make([]int32, 0, len(svc.Ports))
+		for _, port := range svc.Ports {
+			ports = append(ports, port.Port)
+		}
+		b.vertex(KindService, key, svc.Name, "", map[string]interface{}{
+			"namespace":  svc.Namespace,
+			"type":       svc.Type,
+			"cluster_ip": svc.ClusterIP,
+			"ports":      ports,
+		})
+
+		// 没有选择器的Service（如ExternalName）不关联Pod
+		if len(svc.Selector) == 0 {
+			continue
+		}
+		for _, pod := range pods {
+			if pod.Namespace == svc.Namespace && matchesSelector(pod.Labels, svc.Selector) {
+				b.edge(EdgeSelects, ID(KindService, key), ID(KindPod, pod.Namespace+"/"+pod.Name), nil)
+			}
+		}
+	}
+}
+
+// addUAVs UAV及其所在节点，附带电量、位置和飞行模式
+func (b *builder) addUAVs(uavs map[string]*models.UAVMetricsEntry) {
+	for key, entry := range uavs {
+		nodeName := entry.NodeName
+		if nodeName == "" {
+			nodeName = key
+		}
+		label := key
+		attributes := map[string]interface{}{"source": entry.Source, "last_heartbeat": entry.LastHeartbeat}
+		if entry.UAVID != "" {
+			label = entry.UAVID
+			attributes["uav_id"] = entry.UAVID
+		}
+		for key, value := range uavStateSummary(entry.State) {
+			attributes[key] = value
+		}
+
+		b.vertex(KindUAV, key, label, entry.Status, attributes)
+		b.vertex(KindNode, nodeName, nodeName, "", nil)
+		b.edge(EdgeAttachedTo, ID(KindUAV, key), ID(KindNode, nodeName), nil)
+	}
+}
+
+// graph 输出排序后的图，保证响应稳定（前端可以按ID做增量更新）
+func (b *builder) graph() *Graph {
+	graph := &Graph{
+		Timestamp: time.Now().UTC(),
+		Nodes:     make([]Vertex, 0, len(b.vertices)),
+		Edges:     make([]Edge, 0, len(b.edges)),
+		Counts:    map[string]int{KindNode: 0, KindPod: 0, KindService: 0, KindUAV: 0},
+	}
+	for _, v := range b.vertices {
+		if len(v.Attributes) == 0 {
+			v.Attributes = nil
+		}
+		graph.Nodes = append(graph.Nodes, *v)
+		graph.Counts[v.Kind]++
+	}
+	for _, e := range b.edges {
+		graph.Edges = append(graph.Edges, *e)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool { return graph.Edges[i].ID < graph.Edges[j].ID })
+	return graph
+}
+
+// matchesSelector 判断标签是否满足Service选择器
+func matchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// podName 从 namespace/name 中取出Pod名称
+func podName(key string) string {
+	return key[strings.LastIndex(key, "/")+1:]
+}
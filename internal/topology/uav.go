@@ -0,0 +1,22 @@
+package topology
+
+import (
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+)
+
+// uavStateSummary 提取UAV状态中地图展示需要的字段
+func uavStateSummary(state *uav.UAVState) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"battery_percent": state.Battery.RemainingPercent,
+		"latitude":        state.GPS.Latitude,
+		"longitude":       state.GPS.Longitude,
+		"altitude":        state.GPS.RelativeAltitude,
+		"flight_mode":     state.Flight.Mode,
+		"armed":           state.Flight.Armed,
+		"system_status":   state.Health.SystemStatus,
+	}
+}
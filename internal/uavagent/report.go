@@ -0,0 +1,127 @@
+// Package uavagent 实现UAV Agent向Master上报遥测状态的循环，uav-agent命令和集成测试共用
+package uavagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/k8s-llm-monitor/internal/reportsig"
+	"github.com/yourusername/k8s-llm-monitor/pkg/models"
+	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
+)
+
+// ReportOptions 一架UAV的状态上报参数
+type ReportOptions struct {
+	Client     *http.Client  // 为空时使用15秒超时的默认客户端
+	MasterURL  string        // Master API地址，上报到 {MasterURL}/api/v1/uav/report
+	Interval   time.Duration // 上报间隔，默认15秒
+	NodeName   string
+	NodeIP     string
+	Index      int    // UAV在本Agent中的序号
+	SigningKey string // 非空时用节点密钥签名上报请求
+}
+
+// RunReportLoop 立即上报一次UAV状态，之后按间隔定期上报，直到ctx取消
+func RunReportLoop(ctx context.Context, opts ReportOptions, simulator *uav.MAVLinkSimulator) {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	endpoint := strings.TrimRight(opts.MasterURL, "/") + "/api/v1/uav/report"
+	heartbeatSeconds := int(interval.Seconds())
+	if heartbeatSeconds <= 0 {
+		heartbeatSeconds = 15
+	}
+
+	sendReport := func() {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+
+		reportCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		state := simulator.GetState()
+		stateCopy := state
+
+		report := models.UAVReport{
+			NodeName:                 opts.NodeName,
+			NodeIP:                   opts.NodeIP,
+			UAVID:                    state.UAVID,
+			Index:                    opts.Index,
+			Source:                   "agent",
+			Status:                   "active",
+			Timestamp:                time.Now().UTC(),
+			HeartbeatIntervalSeconds: heartbeatSeconds,
+			State:                    &stateCopy,
+			Metadata: map[string]string{
+				"agent": "go-uav-agent",
+			},
+		}
+
+		if report.NodeName == "" {
+			report.NodeName = "unknown-node"
+		}
+		if report.UAVID == "" {
+			report.UAVID = fmt.Sprintf("UAV-%s", report.NodeName)
+		}
+
+		payload, err := json.Marshal(report)
+		if err != nil {
+			log.Printf("Failed to marshal UAV report: %v", err)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(reportCtx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			log.Printf("Failed to create UAV report request: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if opts.SigningKey != "" {
+			reportsig.Sign(req, opts.SigningKey, report.NodeName, payload)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Printf("Failed to send report for %s to %s: %v", report.UAVID, endpoint, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+			log.Printf("UAV report for %s rejected (%d): %s", report.UAVID, resp.StatusCode, strings.TrimSpace(string(body)))
+			return
+		}
+
+		log.Printf("UAV report for %s delivered (status %s)", report.UAVID, resp.Status)
+	}
+
+	sendReport()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("UAV report loop stopped")
+			return
+		case <-ticker.C:
+			sendReport()
+		}
+	}
+}
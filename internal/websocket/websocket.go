@@ -0,0 +1,408 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 最小化的服务端WebSocket实现（RFC 6455）：只发送文本消息，读取客户端消息时校验分片和控制帧，
+// 回复ping和close，丢弃数据消息。用于向浏览器推送实时数据
+
+// acceptGUID 计算 Sec-WebSocket-Accept 使用的固定GUID
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// 帧类型
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// 关闭状态码
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseTryAgainLater = 1013
+
+	closeProtocolError  = 1002
+	closeInvalidPayload = 1007
+	closeMessageTooBig  = 1009
+)
+
+// 读取的限制：客户端只应发送控制帧或很小的消息，分片消息按合并后的大小计算
+const (
+	maxMessageSize     = 64 * 1024
+	maxControlPayload  = 125
+	maxCloseReasonSize = maxControlPayload - 2
+)
+
+// ErrClosed 连接已关闭
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn 一个已完成握手的WebSocket连接，写入可以并发调用
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMu      sync.Mutex
+	writeTimeout time.Duration
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Options 握手参数
+type Options struct {
+	WriteTimeout time.Duration
+	// AllowedOrigins 允许跨域连接的Origin（如 https://grafana.example.com），"*" 允许任意来源。
+	// 同源请求和不带Origin的非浏览器客户端始终允许
+	AllowedOrigins []string
+}
+
+// protocolError 客户端违反协议，以对应的关闭码断开连接
+type protocolError struct {
+	code   int
+	reason string
+}
+
+func (e *protocolError) Error() string {
+	return "websocket: " + e.reason
+}
+
+// Upgrade 校验握手请求并接管HTTP连接，失败时已写出错误响应
+func Upgrade(w http.ResponseWriter, r *http.Request, opts Options) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: method %s not allowed", r.Method)
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusBadRequest)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing key")
+	}
+	// 浏览器跨域发起的WebSocket不受同源策略限制，必须校验Origin，防止其他网站借用户的网络访问实时数据
+	if !checkOrigin(r, opts.AllowedOrigins) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %q not allowed", r.Header.Get("Origin"))
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: failed to hijack connection: %w", err)
+	}
+	// 清除HTTP服务器设置的读写超时
+	conn.SetDeadline(time.Time{})
+
+	writeTimeout := opts.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = 10 * time.Second
+	}
+	c := &Conn{conn: conn, reader: rw.Reader, writeTimeout: writeTimeout, closed: make(chan struct{})}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+	return c, nil
+}
+
+// acceptKey 计算 Sec-WebSocket-Accept
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// checkOrigin Origin为空、与Host相同或在允许列表中时返回true
+func checkOrigin(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerContains 请求头（逗号分隔的列表）是否包含指定值，不区分大小写
+func headerContains(header http.Header, name, value string) bool {
+	for _, line := range header.Values(name) {
+		for _, token := range strings.Split(line, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteText 发送一条文本消息
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Ping 发送ping，浏览器会自动回复pong
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close 发送close帧并关闭连接
+func (c *Conn) Close(code int, reason string) error {
+	err := c.writeFrame(opClose, closePayload(code, reason))
+	c.shutdown()
+	return err
+}
+
+// closePayload close帧的内容：状态码和截断到控制帧上限的原因
+func closePayload(code int, reason string) []byte {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	if len(reason) > maxCloseReasonSize {
+		reason = reason[:maxCloseReasonSize]
+	}
+	return append(payload, reason...)
+}
+
+// Done 连接关闭时关闭的channel
+func (c *Conn) Done() <-chan struct{} {
+	return c.closed
+}
+
+func (c *Conn) shutdown() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+// writeFrame 写出一个完整的帧（服务端发送的帧不加掩码）
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|opcode)
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if _, err := (&net.Buffers{header, payload}).WriteTo(c.conn); err != nil {
+		c.shutdown()
+		return err
+	}
+	return nil
+}
+
+// ReadLoop 读取客户端发来的帧：回复ping、校验并丢弃数据消息，收到close或连接断开时关闭连接并返回。
+// 客户端违反协议时以1002（协议错误）、1007（非法UTF-8）或1009（消息过大）关闭并返回错误。
+// 调用方应在单独的goroutine中运行，以便及时发现客户端断开
+func (c *Conn) ReadLoop() error {
+	defer c.shutdown()
+	err := c.readMessages()
+	var perr *protocolError
+	if errors.As(err, &perr) {
+		c.writeFrame(opClose, closePayload(perr.code, ""))
+		return err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// readMessages 按帧读取直到收到close（返回nil）或出错。数据消息可以分片，
+// 分片之间可以穿插控制帧；文本消息合并后校验UTF-8
+func (c *Conn) readMessages() error {
+	var (
+		fragmented bool
+		text       bool
+		message    []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opPong:
+		case opClose:
+			code, err := parseClose(payload)
+			if err != nil {
+				return err
+			}
+			reply := []byte{}
+			if code != 0 {
+				reply = binary.BigEndian.AppendUint16(nil, uint16(code))
+			}
+			c.writeFrame(opClose, reply)
+			return nil
+		default:
+			if opcode == opContinuation && !fragmented {
+				return &protocolError{code: closeProtocolError, reason: "continuation frame without a message"}
+			}
+			if opcode != opContinuation {
+				if fragmented {
+					return &protocolError{code: closeProtocolError, reason: "new message before the previous one finished"}
+				}
+				text = opcode == opText
+				message = message[:0]
+			}
+			if len(message)+len(payload) > maxMessageSize {
+				return &protocolError{code: closeMessageTooBig, reason: fmt.Sprintf("message exceeds %d bytes", maxMessageSize)}
+			}
+			message = append(message, payload...)
+			fragmented = !fin
+			if fin && text && !utf8.Valid(message) {
+				return &protocolError{code: closeInvalidPayload, reason: "text message is not valid UTF-8"}
+			}
+		}
+	}
+}
+
+// parseClose 校验客户端close帧的内容，返回其中的状态码（没有状态码时为0）
+func parseClose(payload []byte) (int, error) {
+	switch {
+	case len(payload) == 0:
+		return 0, nil
+	case len(payload) == 1:
+		return 0, &protocolError{code: closeProtocolError, reason: "close frame payload of 1 byte"}
+	}
+	code := int(binary.BigEndian.Uint16(payload))
+	if !validCloseCode(code) {
+		return 0, &protocolError{code: closeProtocolError, reason: fmt.Sprintf("invalid close code %d", code)}
+	}
+	if !utf8.Valid(payload[2:]) {
+		return 0, &protocolError{code: closeInvalidPayload, reason: "close reason is not valid UTF-8"}
+	}
+	return code, nil
+}
+
+// validCloseCode 状态码能否出现在close帧中：1004-1006和1015为保留值，
+// 3000-4999供库和应用使用
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// readFrame 读取一个帧并去掉掩码。没有协商扩展，RSV位必须为0；
+// 控制帧不能分片且不超过125字节
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	rsv := head[0] & 0x70
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if rsv != 0 {
+		return false, 0, nil, &protocolError{code: closeProtocolError, reason: "reserved bits set without a negotiated extension"}
+	}
+	if !masked {
+		return false, 0, nil, &protocolError{code: closeProtocolError, reason: "client frame is not masked"}
+	}
+	switch opcode {
+	case opContinuation, opText, opBinary:
+		if length > maxMessageSize {
+			return false, 0, nil, &protocolError{code: closeMessageTooBig, reason: fmt.Sprintf("frame of %d bytes exceeds limit", length)}
+		}
+	case opClose, opPing, opPong:
+		if !fin {
+			return false, 0, nil, &protocolError{code: closeProtocolError, reason: "fragmented control frame"}
+		}
+		if length > maxControlPayload {
+			return false, 0, nil, &protocolError{code: closeProtocolError, reason: fmt.Sprintf("control frame of %d bytes", length)}
+		}
+	default:
+		return false, 0, nil, &protocolError{code: closeProtocolError, reason: fmt.Sprintf("unknown opcode %d", opcode)}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
@@ -0,0 +1,307 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 客户端帧
+type frame struct {
+	fin     bool
+	rsv     byte
+	opcode  byte
+	payload []byte
+	// unmasked 为true时不加掩码（违反协议）
+	unmasked bool
+}
+
+func (f frame) encode() []byte {
+	b0 := f.rsv<<4 | f.opcode
+	if f.fin {
+		b0 |= 0x80
+	}
+	buf := []byte{b0}
+	mask := byte(0x80)
+	if f.unmasked {
+		mask = 0
+	}
+	switch n := len(f.payload); {
+	case n < 126:
+		buf = append(buf, mask|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, mask|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, mask|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if f.unmasked {
+		return append(buf, f.payload...)
+	}
+	key := [4]byte{0x12, 0x34, 0x56, 0x78}
+	buf = append(buf, key[:]...)
+	for i, b := range f.payload {
+		buf = append(buf, b^key[i%4])
+	}
+	return buf
+}
+
+func text(payload string) frame { return frame{fin: true, opcode: opText, payload: []byte(payload)} }
+func ping(payload string) frame { return frame{fin: true, opcode: opPing, payload: []byte(payload)} }
+
+func closeFrame(code int, reason string) frame {
+	return frame{fin: true, opcode: opClose, payload: closePayload(code, reason)}
+}
+
+// testServer 升级连接并运行 ReadLoop，ReadLoop 的返回值写入 result
+func testServer(t *testing.T, opts Options, onConn func(*Conn)) (*httptest.Server, chan error) {
+	t.Helper()
+	result := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, opts)
+		if err != nil {
+			return
+		}
+		if onConn != nil {
+			onConn(conn)
+		}
+		result <- conn.ReadLoop()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, result
+}
+
+// dial 发送握手请求，返回连接和握手响应
+func dial(t *testing.T, srv *httptest.Server, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/stream", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("write handshake: %v", err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatalf("ReadResponse() error = %v", err)
+	}
+	return conn, reader, resp
+}
+
+// readServerFrame 读取服务端的一个帧，服务端帧不应加掩码
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	if head[0]&0x80 == 0 || head[0]&0x70 != 0 || head[1]&0x80 != 0 {
+		t.Fatalf("server frame header %#x %#x: want FIN, no RSV bits, unmasked", head[0], head[1])
+	}
+	length := int(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestAcceptKey(t *testing.T) {
+	// RFC 6455 第1.3节的示例
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() = %q", got)
+	}
+}
+
+func TestUpgradeOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string // "self" 替换为测试服务器的地址
+		allowed []string
+		want    int
+	}{
+		{name: "no origin", want: http.StatusSwitchingProtocols},
+		{name: "same origin", origin: "self", want: http.StatusSwitchingProtocols},
+		{name: "cross origin", origin: "https://evil.example.com", want: http.StatusForbidden},
+		{name: "allowed origin", origin: "https://grafana.example.com", allowed: []string{"https://grafana.example.com/"}, want: http.StatusSwitchingProtocols},
+		{name: "allowed origin case insensitive", origin: "https://Grafana.Example.com", allowed: []string{"https://grafana.example.com"}, want: http.StatusSwitchingProtocols},
+		{name: "other port", origin: "https://grafana.example.com:8443", allowed: []string{"https://grafana.example.com"}, want: http.StatusForbidden},
+		{name: "wildcard", origin: "https://evil.example.com", allowed: []string{"*"}, want: http.StatusSwitchingProtocols},
+		{name: "opaque origin", origin: "null", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := testServer(t, Options{AllowedOrigins: tt.allowed}, nil)
+			origin := tt.origin
+			if origin == "self" {
+				origin = "http://" + srv.Listener.Addr().String()
+			}
+			_, _, resp := dial(t, srv, origin)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusSwitchingProtocols && resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+				t.Errorf("Sec-WebSocket-Accept = %q", resp.Header.Get("Sec-WebSocket-Accept"))
+			}
+		})
+	}
+}
+
+func TestReadLoopProtocol(t *testing.T) {
+	euro := []byte("€") // 3字节的UTF-8字符，拆到两个分片中
+	tests := []struct {
+		name      string
+		frames    []frame
+		pongs     []string // 期望依次收到的pong
+		closeCode int      // 服务端close帧中的状态码，0表示不带状态码
+		wantErr   bool     // ReadLoop 是否因协议错误返回
+	}{
+		{name: "ping then close", frames: []frame{ping("hello"), closeFrame(CloseNormal, "bye")}, pongs: []string{"hello"}, closeCode: CloseNormal},
+		{name: "close without status", frames: []frame{{fin: true, opcode: opClose}}},
+		{name: "application close code echoed", frames: []frame{closeFrame(4000, "")}, closeCode: 4000},
+		{name: "unsolicited pong ignored", frames: []frame{{fin: true, opcode: opPong, payload: []byte("x")}, closeFrame(CloseGoingAway, "")}, closeCode: CloseGoingAway},
+		{
+			name: "fragmented text with interleaved ping",
+			frames: []frame{
+				{opcode: opText, payload: append([]byte("price "), euro[:1]...)},
+				ping("mid"),
+				{opcode: opContinuation, payload: euro[1:]},
+				{fin: true, opcode: opContinuation, payload: []byte("5")},
+				text("next"),
+				closeFrame(CloseNormal, ""),
+			},
+			pongs:     []string{"mid"},
+			closeCode: CloseNormal,
+		},
+		{
+			name:      "fragmented binary",
+			frames:    []frame{{opcode: opBinary, payload: []byte{0xff}}, {fin: true, opcode: opContinuation, payload: []byte{0xfe}}, closeFrame(CloseNormal, "")},
+			closeCode: CloseNormal,
+		},
+		{name: "continuation without message", frames: []frame{{fin: true, opcode: opContinuation, payload: []byte("x")}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "new message while fragmented", frames: []frame{{opcode: opText, payload: []byte("a")}, text("b")}, closeCode: closeProtocolError, wantErr: true},
+		{name: "fragmented ping", frames: []frame{{opcode: opPing, payload: []byte("a")}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "ping over 125 bytes", frames: []frame{ping(strings.Repeat("a", 126))}, closeCode: closeProtocolError, wantErr: true},
+		{name: "reserved bits", frames: []frame{{fin: true, rsv: 0x4, opcode: opText, payload: []byte("a")}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "reserved opcode", frames: []frame{{fin: true, opcode: 0x3}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "unmasked frame", frames: []frame{{fin: true, opcode: opText, payload: []byte("a"), unmasked: true}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "close payload of 1 byte", frames: []frame{{fin: true, opcode: opClose, payload: []byte{0x03}}}, closeCode: closeProtocolError, wantErr: true},
+		{name: "close code below 1000", frames: []frame{closeFrame(999, "")}, closeCode: closeProtocolError, wantErr: true},
+		{name: "reserved close code 1005", frames: []frame{closeFrame(1005, "")}, closeCode: closeProtocolError, wantErr: true},
+		{name: "unassigned close code", frames: []frame{closeFrame(2000, "")}, closeCode: closeProtocolError, wantErr: true},
+		{name: "close code over 4999", frames: []frame{closeFrame(5000, "")}, closeCode: closeProtocolError, wantErr: true},
+		{name: "invalid UTF-8 close reason", frames: []frame{{fin: true, opcode: opClose, payload: append(closePayload(CloseNormal, ""), 0xff)}}, closeCode: closeInvalidPayload, wantErr: true},
+		{name: "invalid UTF-8 text", frames: []frame{{fin: true, opcode: opText, payload: []byte{'a', 0xc3}}}, closeCode: closeInvalidPayload, wantErr: true},
+		{name: "truncated UTF-8 across fragments", frames: []frame{{opcode: opText, payload: euro[:2]}, {fin: true, opcode: opContinuation, payload: []byte("x")}}, closeCode: closeInvalidPayload, wantErr: true},
+		{name: "frame over limit", frames: []frame{text(strings.Repeat("a", maxMessageSize+1))}, closeCode: closeMessageTooBig, wantErr: true},
+		{
+			name: "fragments over limit",
+			frames: []frame{
+				{opcode: opBinary, payload: make([]byte, maxMessageSize/2)},
+				{opcode: opContinuation, payload: make([]byte, maxMessageSize/2)},
+				{fin: true, opcode: opContinuation, payload: []byte{0}},
+			},
+			closeCode: closeMessageTooBig,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, result := testServer(t, Options{}, nil)
+			conn, reader, resp := dial(t, srv, "")
+			if resp.StatusCode != http.StatusSwitchingProtocols {
+				t.Fatalf("handshake status = %d", resp.StatusCode)
+			}
+			for _, f := range tt.frames {
+				if _, err := conn.Write(f.encode()); err != nil {
+					t.Fatalf("write frame: %v", err)
+				}
+			}
+
+			for _, want := range tt.pongs {
+				opcode, payload := readServerFrame(t, reader)
+				if opcode != opPong || string(payload) != want {
+					t.Fatalf("got frame opcode %d payload %q, want pong %q", opcode, payload, want)
+				}
+			}
+			opcode, payload := readServerFrame(t, reader)
+			if opcode != opClose {
+				t.Fatalf("got frame opcode %d payload %q, want close", opcode, payload)
+			}
+			code := 0
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			if code != tt.closeCode || (code == 0 && len(payload) != 0) {
+				t.Errorf("close payload = %v (code %d), want code %d", payload, code, tt.closeCode)
+			}
+			// 服务端回复close后关闭TCP连接（有未读数据时客户端收到RST而不是EOF）
+			if b, err := reader.ReadByte(); err == nil {
+				t.Errorf("connection still open after close, read %#x", b)
+			}
+
+			select {
+			case err := <-result:
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ReadLoop() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("ReadLoop did not return")
+			}
+		})
+	}
+}
+
+func TestServerWriteAndClose(t *testing.T) {
+	longReason := strings.Repeat("r", 200)
+	srv, result := testServer(t, Options{}, func(conn *Conn) {
+		conn.WriteText([]byte(`{"type":"snapshot"}`))
+		conn.WriteText(bytes.Repeat([]byte("x"), 70000))
+		conn.Close(CloseTryAgainLater, longReason)
+	})
+	_, reader, _ := dial(t, srv, "")
+
+	if opcode, payload := readServerFrame(t, reader); opcode != opText || string(payload) != `{"type":"snapshot"}` {
+		t.Fatalf("got opcode %d payload %q, want snapshot text", opcode, payload)
+	}
+	if opcode, payload := readServerFrame(t, reader); opcode != opText || len(payload) != 70000 {
+		t.Fatalf("got opcode %d with %d bytes, want 70000 byte text", opcode, len(payload))
+	}
+	opcode, payload := readServerFrame(t, reader)
+	if opcode != opClose || len(payload) != maxControlPayload || int(binary.BigEndian.Uint16(payload)) != CloseTryAgainLater {
+		t.Fatalf("got opcode %d payload of %d bytes, want close 1013 truncated to %d bytes", opcode, len(payload), maxControlPayload)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("ReadLoop() after Close error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency 未指定并发数时的默认值
+const DefaultConcurrency = 8
+
+// Options 工作池参数
+type Options struct {
+	Concurrency int           // 最大并发数，<=0 时使用 DefaultConcurrency
+	TaskTimeout time.Duration // 单个任务超时时间，0 表示只受外层ctx控制
+}
+
+// TaskError 单个任务的失败信息
+type TaskError struct {
+	Index int    // 任务在输入中的位置
+	Name  string // 任务名称（节点名、Pod对等），便于日志定位
+	Err   error
+}
+
+// Error 实现error接口
+func (e TaskError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Errors 汇总的任务失败信息，按任务顺序排列
+type Errors []TaskError
+
+// Error 实现error接口
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, 0, len(e))
+	for _, taskErr := range e {
+		messages = append(messages, taskErr.Error())
+	}
+	return fmt.Sprintf("%d tasks failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap 支持 errors.Is / errors.As 匹配任一任务的错误
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, taskErr := range e {
+		errs[i] = taskErr
+	}
+	return errs
+}
+
+// Failed 返回失败任务的数量（err 为 nil 或不是 Errors 时分别为 0 和 1）
+func Failed(err error) int {
+	if err == nil {
+		return 0
+	}
+	var taskErrs Errors
+	if errors.As(err, &taskErrs) {
+		return len(taskErrs)
+	}
+	return 1
+}
+
+// Map 以有限并发对 items 执行 fn，结果与输入一一对应（失败的任务对应零值）。
+// 所有任务结束后返回；失败的任务汇总为 Errors，ctx 取消后尚未开始的任务不再执行并记为 ctx.Err()。
+// name 用于错误信息中标识任务，可以为nil
+func Map[T, R any](ctx context.Context, opts Options, items []T, name func(T) string, fn func(context.Context, T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	taskErrs := make([]error, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], taskErrs[i] = runTask(ctx, opts.TaskTimeout, items[i], fn)
+			}
+		}()
+	}
+
+	// 分发任务；ctx 取消后剩余任务直接记为取消
+dispatch:
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for j := i; j < len(items); j++ {
+				taskErrs[j] = ctx.Err()
+			}
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failed Errors
+	for i, err := range taskErrs {
+		if err == nil {
+			continue
+		}
+		taskErr := TaskError{Index: i, Err: err}
+		if name != nil {
+			taskErr.Name = name(items[i])
+		}
+		failed = append(failed, taskErr)
+	}
+	if len(failed) > 0 {
+		return results, failed
+	}
+	return results, nil
+}
+
+// Run 以有限并发执行一组任务，返回汇总的错误
+func Run(ctx context.Context, opts Options, tasks ...func(context.Context) error) error {
+	_, err := Map(ctx, opts, tasks, nil, func(ctx context.Context, task func(context.Context) error) (struct{}, error) {
+		return struct{}{}, task(ctx)
+	})
+	return err
+}
+
+// runTask 执行单个任务，应用超时并把panic转为错误，避免单个任务拖垮整个进程
+func runTask[T, R any](ctx context.Context, timeout time.Duration, item T, fn func(context.Context, T) (R, error)) (result R, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return fn(ctx, item)
+}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"math"
 	"time"
 )
 
@@ -19,7 +20,7 @@ type NodeMetrics struct {
 	MemoryUsage     int64   `json:"memory_usage"`      // 内存使用量 (bytes)
 	MemoryUsageRate float64 `json:"memory_usage_rate"` // 内存使用率 (0-100)
 
-	// 磁盘指标
+	// 磁盘指标（根文件系统，有kubelet Summary API数据时为实际用量）
 	DiskCapacity  int64   `json:"disk_capacity"`   // 磁盘总容量 (bytes)
 	DiskUsage     int64   `json:"disk_usage"`      // 磁盘使用量 (bytes)
 	DiskUsageRate float64 `json:"disk_usage_rate"` // 磁盘使用率 (0-100)
@@ -28,7 +29,16 @@ type NodeMetrics struct {
 	NetworkLatency   float64 `json:"network_latency"`   // 平均延迟 (ms)
 	NetworkBandwidth float64 `json:"network_bandwidth"` // 带宽 (Mbps) - 可选
 
-	// GPU 指标（来自CRD扩展）
+	// 默认网卡的累计流量（来自kubelet Summary API）
+	NetworkRxBytes int64 `json:"network_rx_bytes,omitempty"` // 接收字节数
+	NetworkTxBytes int64 `json:"network_tx_bytes,omitempty"` // 发送字节数
+
+	// 与上一次采集相比的变化速率（首次采集或计数器重置时为0）
+	CPUUsageTrend float64 `json:"cpu_usage_trend"`           // CPU使用量的变化 (核/秒)
+	NetworkRxRate float64 `json:"network_rx_rate,omitempty"` // 接收速率 (bytes/s)
+	NetworkTxRate float64 `json:"network_tx_rate,omitempty"` // 发送速率 (bytes/s)
+
+	// GPU 指标（数量来自节点的 nvidia.com/gpu 容量，使用率和显存来自DCGM Exporter）
 	GPUCount       int       `json:"gpu_count"`        // GPU数量
 	GPUModels      []string  `json:"gpu_models"`       // GPU型号列表
 	GPUUsage       []float64 `json:"gpu_usage"`        // 每个GPU的使用率 (0-100)
@@ -39,6 +49,9 @@ type NodeMetrics struct {
 	Healthy    bool     `json:"healthy"`    // 节点是否健康
 	Conditions []string `json:"conditions"` // 节点异常条件（如MemoryPressure, DiskPressure等）
 
+	// 各条件的当前状态（Ready和各压力条件），用于记录条件变化的时间线
+	ConditionDetails []NodeCondition `json:"condition_details,omitempty"`
+
 	// 节点标签
 	Labels map[string]string `json:"labels"`
 
@@ -67,9 +80,26 @@ type PodMetrics struct {
 	CPUUsageRate    float64 `json:"cpu_usage_rate"`    // CPU使用率 (0-100)
 	MemoryUsageRate float64 `json:"memory_usage_rate"` // 内存使用率 (0-100)
 
+	// 与上一次采集相比的变化速率（首次采集时为0）
+	CPUUsageTrend float64 `json:"cpu_usage_trend"` // CPU使用量的变化 (核/秒)
+	RestartRate   float64 `json:"restart_rate"`    // 重启速率 (次/小时)
+
+	// 临时存储使用量 (bytes)，来自kubelet Summary API
+	EphemeralStorageUsage int64 `json:"ephemeral_storage_usage,omitempty"`
+
+	// GPU 指标（分配数量来自容器的 nvidia.com/gpu 资源，使用率和显存按DCGM Exporter的pod标签归属）
+	GPURequest    int64   `json:"gpu_request,omitempty"`     // device plugin分配的GPU数量
+	GPUDevices    []int   `json:"gpu_devices,omitempty"`     // 使用的GPU编号（所在节点上）
+	GPUUsage      float64 `json:"gpu_usage,omitempty"`       // 使用的GPU使用率之和（每个GPU 0-100，共享的GPU按Pod数均分）
+	GPUUsageRate  float64 `json:"gpu_usage_rate,omitempty"`  // 相对于分配的GPU数量的使用率 (0-100)
+	GPUMemoryUsed int64   `json:"gpu_memory_used,omitempty"` // 已用显存 (MB)
+
 	// Container级别指标
 	Containers []ContainerMetrics `json:"containers"`
 
+	// 各容器的重启次数和最近一次终止的原因（用于跟踪OOMKill和重启）
+	ContainerRestarts []ContainerRestart `json:"container_restarts,omitempty"`
+
 	// Pod状态
 	Phase      string `json:"phase"`       // Running, Pending, Failed, etc.
 	Ready      bool   `json:"ready"`       // 是否就绪
@@ -89,6 +119,58 @@ type ContainerMetrics struct {
 	MemoryLimit   int64 `json:"memory_limit"`
 }
 
+// NodeCondition 节点条件的当前状态
+type NodeCondition struct {
+	Type               string    `json:"type"`   // Ready、MemoryPressure、DiskPressure、PIDPressure、NetworkUnavailable
+	Status             string    `json:"status"` // True、False、Unknown
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"last_transition_time"`
+}
+
+// Abnormal 条件是否处于异常状态（Ready不为True，或压力条件为True）
+func (c NodeCondition) Abnormal() bool {
+	if c.Type == "Ready" {
+		return c.Status != "True"
+	}
+	return c.Status == "True"
+}
+
+// ContainerRestart 容器的重启次数和最近一次终止状态
+type ContainerRestart struct {
+	Name       string    `json:"name"`
+	Restarts   int32     `json:"restarts"`
+	Reason     string    `json:"reason,omitempty"`      // 最近一次终止的原因，例如 OOMKilled、Error
+	ExitCode   int32     `json:"exit_code,omitempty"`   // 最近一次终止的退出码
+	FinishedAt time.Time `json:"finished_at,omitempty"` // 最近一次终止的时间
+}
+
+// GPUDevice 单个GPU的指标（来自DCGM Exporter）
+type GPUDevice struct {
+	Index       int     `json:"index"`
+	Model       string  `json:"model"`
+	Utilization float64 `json:"utilization"`  // 使用率 (0-100)
+	MemoryTotal int64   `json:"memory_total"` // 总显存 (MB)
+	MemoryUsed  int64   `json:"memory_used"`  // 已用显存 (MB)
+
+	// 使用该GPU的Pod（namespace/name），来自Exporter开启Kubernetes映射后的pod标签
+	Pods []string `json:"pods,omitempty"`
+}
+
+// KubeletSummary kubelet Summary API（/stats/summary）中一个节点的统计
+type KubeletSummary struct {
+	FSCapacity          int64            `json:"fs_capacity"`           // 根文件系统容量 (bytes)
+	FSUsed              int64            `json:"fs_used"`               // 根文件系统已用 (bytes)
+	NetworkRxBytes      int64            `json:"network_rx_bytes"`      // 默认网卡累计接收字节数
+	NetworkTxBytes      int64            `json:"network_tx_bytes"`      // 默认网卡累计发送字节数
+	PodEphemeralStorage map[string]int64 `json:"pod_ephemeral_storage"` // namespace/pod-name -> 临时存储使用量 (bytes)
+
+	// 与该节点上一次Summary比较得到的流量速率 (bytes/s)
+	Timestamp     time.Time `json:"timestamp"`
+	NetworkRxRate float64   `json:"network_rx_rate"`
+	NetworkTxRate float64   `json:"network_tx_rate"`
+}
+
 // NetworkMetrics 网络指标（Pod间通信）
 type NetworkMetrics struct {
 	SourcePod   string    `json:"source_pod"`
@@ -103,11 +185,144 @@ type NetworkMetrics struct {
 	RTT        float64 `json:"rtt_ms"`       // 往返时延 (ms)
 	PacketLoss float64 `json:"packet_loss"`  // 丢包率 (0-100)
 
-	// 带宽（可选，需要额外测试）
-	Bandwidth float64 `json:"bandwidth_mbps,omitempty"` // Mbps
+	// 由该Pod对最近几次测试计算的抖动（相邻RTT之差的平均值，ms）和综合链路质量评分 (0-100)
+	Jitter       float64 `json:"jitter_ms"`
+	QualityScore float64 `json:"quality_score"`
+
+	// 带宽（可选，启用iperf3带宽测试时测量）
+	Bandwidth      float64 `json:"bandwidth_mbps,omitempty"`  // Mbps
+	BandwidthError string  `json:"bandwidth_error,omitempty"` // 带宽测试失败的原因
+
+	// DNS解析耗时 (ms)，由探测Agent测量
+	DNSLatency float64 `json:"dns_latency_ms,omitempty"`
 
 	// 测试方法
-	TestMethod string `json:"test_method"` // ping, http, tcp, etc.
+	TestMethod  string `json:"test_method"`            // ping, http, tcp, etc.
+	ProbeSource string `json:"probe_source,omitempty"` // agent（节点上的探测Agent）或 exec（在源Pod内执行）
+
+	// 各测试方法的结果
+	Tests []NetworkTest `json:"tests,omitempty"`
+}
+
+// NetworkTest Pod对的单项网络测试结果
+type NetworkTest struct {
+	Method     string  `json:"method"`
+	Success    bool    `json:"success"`
+	RTT        float64 `json:"rtt_ms"`
+	PacketLoss float64 `json:"packet_loss"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// NodeLatency 两个节点之间的往返时延，由源节点上的探测Agent ping 目标节点上的Agent测量
+type NodeLatency struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Reachable  bool    `json:"reachable"`
+	RTT        float64 `json:"rtt_ms"`      // 平均往返时延 (ms)
+	PacketLoss float64 `json:"packet_loss"` // 丢包率 (0-100)
+	Error      string  `json:"error,omitempty"`
+}
+
+// NodeLatencyMatrix 节点间时延矩阵。RTT[i][j] 为 Nodes[i] 到 Nodes[j] 的时延，
+// 未测量或不可达时为null，对角线为0
+type NodeLatencyMatrix struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Nodes     []string      `json:"nodes"`
+	RTT       [][]*float64  `json:"rtt_ms"`
+	Links     []NodeLatency `json:"links"`
+}
+
+// NewNodeLatencyMatrix 由测量结果构建矩阵，nodes 为矩阵的行列顺序
+func NewNodeLatencyMatrix(timestamp time.Time, nodes []string, links []NodeLatency) *NodeLatencyMatrix {
+	index := make(map[string]int, len(nodes))
+	rtt := make([][]*float64, len(nodes))
+	for i, node := range nodes {
+		index[node] = i
+		rtt[i] = make([]*float64, len(nodes))
+		zero := 0.0
+		rtt[i][i] = &zero
+	}
+	for _, link := range links {
+		i, okSource := index[link.Source]
+		j, okTarget := index[link.Target]
+		if okSource && okTarget && link.Reachable {
+			value := link.RTT
+			rtt[i][j] = &value
+		}
+	}
+	return &NodeLatencyMatrix{Timestamp: timestamp, Nodes: nodes, RTT: rtt, Links: links}
+}
+
+// Latency 返回两个节点之间测得的时延，未测量或不可达时返回false
+func (m *NodeLatencyMatrix) Latency(source, target string) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	if source == target {
+		return 0, true
+	}
+	for _, link := range m.Links {
+		if link.Source == source && link.Target == target && link.Reachable {
+			return link.RTT, true
+		}
+	}
+	return 0, false
+}
+
+// ExternalCheck 一个节点上的探测Agent访问外部目标的结果。各阶段耗时 (ms) 为0表示未进行到该阶段
+type ExternalCheck struct {
+	Node        string     `json:"node"`
+	Reachable   bool       `json:"reachable"`
+	StatusCode  int        `json:"status_code,omitempty"`
+	DNSTime     float64    `json:"dns_ms"`
+	ConnectTime float64    `json:"connect_ms"`
+	TLSTime     float64    `json:"tls_ms"`
+	Total       float64    `json:"total_ms"`
+	Addresses   []string   `json:"addresses,omitempty"` // DNS解析得到的地址
+	CertExpiry  *time.Time `json:"cert_expiry,omitempty"`
+	FailedPhase string     `json:"failed_phase,omitempty"` // dns、connect、tls或http
+	Error       string     `json:"error,omitempty"`
+}
+
+// ExternalTarget 一个外部目标（LLM服务、对象存储等）在各节点上的可达性汇总
+type ExternalTarget struct {
+	Name        string          `json:"name"`
+	URL         string          `json:"url"`
+	Reachable   int             `json:"reachable_nodes"`
+	Unreachable int             `json:"unreachable_nodes"`
+	AvgTotal    float64         `json:"avg_total_ms"` // 可达节点的平均总耗时
+	MaxTotal    float64         `json:"max_total_ms"`
+	CertExpiry  *time.Time      `json:"cert_expiry,omitempty"` // 各节点看到的证书中最早的过期时间
+	Checks      []ExternalCheck `json:"checks"`
+}
+
+// NewExternalTarget 由各节点的结果汇总一个外部目标
+func NewExternalTarget(name, url string, checks []ExternalCheck) ExternalTarget {
+	target := ExternalTarget{Name: name, URL: url, Checks: checks}
+	var total float64
+	for _, check := range checks {
+		if check.CertExpiry != nil && (target.CertExpiry == nil || check.CertExpiry.Before(*target.CertExpiry)) {
+			target.CertExpiry = check.CertExpiry
+		}
+		if !check.Reachable {
+			target.Unreachable++
+			continue
+		}
+		target.Reachable++
+		total += check.Total
+		target.MaxTotal = math.Max(target.MaxTotal, check.Total)
+	}
+	if target.Reachable > 0 {
+		target.AvgTotal = total / float64(target.Reachable)
+	}
+	return target
+}
+
+// ExternalReachability 最近一轮从集群内部访问外部目标的结果
+type ExternalReachability struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Nodes     []string         `json:"nodes"` // 执行探测的节点（有就绪探测Agent的节点）
+	Targets   []ExternalTarget `json:"targets"`
 }
 
 // ClusterMetrics 集群整体指标摘要
@@ -145,6 +360,158 @@ type MetricsSnapshot struct {
 	PodMetrics     map[string]*PodMetrics   `json:"pod_metrics"`     // key: namespace/pod-name
 	NetworkMetrics []*NetworkMetrics        `json:"network_metrics"`
 	ClusterMetrics *ClusterMetrics          `json:"cluster_metrics"`
+
+	// 各数据源的采集结果，用于区分数据过期（采集失败后沿用旧数据）和确实为空
+	CollectionStatus *CollectionStatus `json:"collection_status,omitempty"`
+}
+
+// 数据源最近一次采集的结果
+const (
+	SourceOK      = "ok"      // 采集成功
+	SourcePartial = "partial" // 部分失败，数据不完整（例如Metrics Server不可用时没有CPU/内存用量）
+	SourceFailed  = "failed"  // 采集失败，快照中沿用上一次成功采集的数据
+)
+
+// SourceStatus 单个数据源的采集状态
+type SourceStatus struct {
+	Status      string    `json:"status"`                 // ok, partial, failed
+	Stale       bool      `json:"stale"`                  // 快照中的数据不是本轮采集的（采集失败或未到采集间隔）
+	LastAttempt time.Time `json:"last_attempt"`           // 最近一次采集时间
+	LastSuccess time.Time `json:"last_success,omitempty"` // 最近一次成功（含部分成功）的采集时间，快照中的数据来自这次采集
+	Error       string    `json:"error,omitempty"`        // 最近一次采集的错误
+}
+
+// CollectionStatus 快照中各数据源（键为采集器名称，如 node、pod）的采集状态
+type CollectionStatus struct {
+	Degraded bool                     `json:"degraded"` // 有数据源失败或数据不完整
+	Sources  map[string]*SourceStatus `json:"sources"`
+}
+
+// Source 返回数据源的采集状态（没有记录时为nil）
+func (c *CollectionStatus) Source(name string) *SourceStatus {
+	if c == nil {
+		return nil
+	}
+	return c.Sources[name]
+}
+
+// Clone 返回采集状态的深拷贝
+func (c *CollectionStatus) Clone() *CollectionStatus {
+	if c == nil {
+		return nil
+	}
+	clone := &CollectionStatus{Degraded: c.Degraded}
+	if c.Sources != nil {
+		clone.Sources = make(map[string]*SourceStatus, len(c.Sources))
+		for name, source := range c.Sources {
+			if source != nil {
+				status := *source
+				source = &status
+			}
+			clone.Sources[name] = source
+		}
+	}
+	return clone
+}
+
+// Clone 返回快照的深拷贝。快照发布后会被并发读取（HTTP序列化、分析、持久化），
+// 需要修改或长时间持有时应使用副本
+func (s *MetricsSnapshot) Clone() *MetricsSnapshot {
+	if s == nil {
+		return nil
+	}
+	clone := &MetricsSnapshot{
+		Timestamp:        s.Timestamp,
+		ClusterMetrics:   s.ClusterMetrics.Clone(),
+		CollectionStatus: s.CollectionStatus.Clone(),
+	}
+	if s.NodeMetrics != nil {
+		clone.NodeMetrics = make(map[string]*NodeMetrics, len(s.NodeMetrics))
+		for name, node := range s.NodeMetrics {
+			clone.NodeMetrics[name] = node.Clone()
+		}
+	}
+	if s.PodMetrics != nil {
+		clone.PodMetrics = make(map[string]*PodMetrics, len(s.PodMetrics))
+		for key, pod := range s.PodMetrics {
+			clone.PodMetrics[key] = pod.Clone()
+		}
+	}
+	if s.NetworkMetrics != nil {
+		clone.NetworkMetrics = make([]*NetworkMetrics, len(s.NetworkMetrics))
+		for i, network := range s.NetworkMetrics {
+			clone.NetworkMetrics[i] = network.Clone()
+		}
+	}
+	return clone
+}
+
+// Clone 返回Node指标的深拷贝
+func (n *NodeMetrics) Clone() *NodeMetrics {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	clone.GPUModels = cloneSlice(n.GPUModels)
+	clone.GPUUsage = cloneSlice(n.GPUUsage)
+	clone.GPUMemoryTotal = cloneSlice(n.GPUMemoryTotal)
+	clone.GPUMemoryUsed = cloneSlice(n.GPUMemoryUsed)
+	clone.Conditions = cloneSlice(n.Conditions)
+	clone.ConditionDetails = cloneSlice(n.ConditionDetails)
+	if n.Labels != nil {
+		clone.Labels = make(map[string]string, len(n.Labels))
+		for key, value := range n.Labels {
+			clone.Labels[key] = value
+		}
+	}
+	if n.CustomMetrics != nil {
+		// 自定义指标的值来自CRD解析结果，只拷贝一层
+		clone.CustomMetrics = make(map[string]interface{}, len(n.CustomMetrics))
+		for key, value := range n.CustomMetrics {
+			clone.CustomMetrics[key] = value
+		}
+	}
+	return &clone
+}
+
+// Clone 返回Pod指标的深拷贝
+func (p *PodMetrics) Clone() *PodMetrics {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Containers = cloneSlice(p.Containers)
+	clone.ContainerRestarts = cloneSlice(p.ContainerRestarts)
+	clone.GPUDevices = cloneSlice(p.GPUDevices)
+	return &clone
+}
+
+// Clone 返回网络指标的拷贝
+func (n *NetworkMetrics) Clone() *NetworkMetrics {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	clone.Tests = append([]NetworkTest(nil), n.Tests...)
+	return &clone
+}
+
+// Clone 返回集群指标的深拷贝
+func (c *ClusterMetrics) Clone() *ClusterMetrics {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.Issues = cloneSlice(c.Issues)
+	return &clone
+}
+
+// cloneSlice 拷贝切片，nil保持为nil（JSON输出不变）
+func cloneSlice[T any](values []T) []T {
+	if values == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(values)), values...)
 }
 
 // GetAvailableResources 计算Node可用资源
@@ -185,14 +552,31 @@ func (p *PodMetrics) IsOverLimit() bool {
 
 // GetQuality 获取网络质量评估
 func (n *NetworkMetrics) GetQuality() string {
-	if !n.Connected {
+	return LinkQuality(n.Connected, n.RTT)
+}
+
+// LinkQualityScore 综合往返时延、丢包率和抖动 (ms) 的链路质量评分 (0-100)：
+// 丢包按比例扣分，时延100ms、抖动20ms各使评分减半，不连通为0
+func LinkQualityScore(connected bool, rtt, packetLoss, jitter float64) float64 {
+	if !connected {
+		return 0
+	}
+	score := 100 * (1 - math.Min(math.Max(packetLoss, 0), 100)/100)
+	score /= 1 + math.Max(rtt, 0)/100
+	score /= 1 + math.Max(jitter, 0)/20
+	return math.Round(score*10) / 10
+}
+
+// LinkQuality 按往返时延 (ms) 评估链路质量，节点间时延和连通性探测使用相同的等级
+func LinkQuality(connected bool, rtt float64) string {
+	if !connected {
 		return "disconnected"
 	}
-	if n.RTT < 10 {
+	if rtt < 10 {
 		return "excellent"
-	} else if n.RTT < 50 {
+	} else if rtt < 50 {
 		return "good"
-	} else if n.RTT < 100 {
+	} else if rtt < 100 {
 		return "fair"
 	}
 	return "poor"
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewNodeLatencyMatrix(t *testing.T) {
+	links := []NodeLatency{
+		{Source: "a", Target: "b", Reachable: true, RTT: 0.4},
+		{Source: "b", Target: "a", Reachable: true, RTT: 0.5},
+		{Source: "a", Target: "c", Reachable: false, Error: "100% packet loss"},
+		{Source: "x", Target: "a", Reachable: true, RTT: 1}, // 不在节点列表中
+	}
+	m := NewNodeLatencyMatrix(time.Now(), []string{"a", "b", "c"}, links)
+
+	if got := m.RTT[0][1]; got == nil || *got != 0.4 {
+		t.Errorf("a->b = %v, want 0.4", got)
+	}
+	if got := m.RTT[1][0]; got == nil || *got != 0.5 {
+		t.Errorf("b->a = %v, want 0.5", got)
+	}
+	if m.RTT[0][2] != nil {
+		t.Errorf("unreachable a->c should be null, got %v", *m.RTT[0][2])
+	}
+	if m.RTT[2][1] != nil {
+		t.Errorf("unmeasured c->b should be null")
+	}
+	for i := range m.Nodes {
+		if m.RTT[i][i] == nil || *m.RTT[i][i] != 0 {
+			t.Errorf("diagonal %d should be 0", i)
+		}
+	}
+
+	if rtt, ok := m.Latency("b", "a"); !ok || rtt != 0.5 {
+		t.Errorf("Latency(b, a) = %v, %v", rtt, ok)
+	}
+	if _, ok := m.Latency("a", "c"); ok {
+		t.Error("Latency(a, c) should report unreachable")
+	}
+	if rtt, ok := m.Latency("c", "c"); !ok || rtt != 0 {
+		t.Errorf("Latency(c, c) = %v, %v", rtt, ok)
+	}
+	var empty *NodeLatencyMatrix
+	if _, ok := empty.Latency("a", "b"); ok {
+		t.Error("nil matrix should report no latency")
+	}
+}
+
+func TestLinkQualityScore(t *testing.T) {
+	tests := []struct {
+		name                    string
+		connected               bool
+		rtt, packetLoss, jitter float64
+		want                    float64
+	}{
+		{"perfect", true, 0, 0, 0, 100},
+		{"100ms rtt halves the score", true, 100, 0, 0, 50},
+		{"20ms jitter halves the score", true, 0, 0, 20, 50},
+		{"loss is proportional", true, 0, 25, 0, 75},
+		{"combined", true, 100, 50, 20, 12.5},
+		{"disconnected", false, 1, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := LinkQualityScore(tt.connected, tt.rtt, tt.packetLoss, tt.jitter); got != tt.want {
+			t.Errorf("%s: LinkQualityScore = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewExternalTarget(t *testing.T) {
+	soon := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	later := soon.Add(90 * 24 * time.Hour)
+	checks := []ExternalCheck{
+		{Node: "node-a", Reachable: true, StatusCode: 401, Total: 120, CertExpiry: &later},
+		{Node: "node-b", Reachable: true, StatusCode: 401, Total: 180, CertExpiry: &soon},
+		{Node: "node-c", FailedPhase: "dns", Error: "dns failed: no such host"},
+	}
+
+	target := NewExternalTarget("openai", "https://api.openai.com/v1/models", checks)
+	if target.Reachable != 2 || target.Unreachable != 1 {
+		t.Errorf("reachable = %d, unreachable = %d, want 2, 1", target.Reachable, target.Unreachable)
+	}
+	if target.AvgTotal != 150 || target.MaxTotal != 180 {
+		t.Errorf("avg = %v, max = %v, want 150, 180", target.AvgTotal, target.MaxTotal)
+	}
+	if target.CertExpiry == nil || !target.CertExpiry.Equal(soon) {
+		t.Errorf("cert expiry = %v, want the earliest %v", target.CertExpiry, soon)
+	}
+
+	if empty := NewExternalTarget("gcs", "https://storage.googleapis.com", nil); empty.AvgTotal != 0 || empty.CertExpiry != nil {
+		t.Errorf("target without checks = %+v", empty)
+	}
+}
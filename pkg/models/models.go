@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yourusername/k8s-llm-monitor/pkg/uav"
@@ -46,12 +49,19 @@ type ServicePort struct {
 
 // EventInfo 包含事件信息
 type EventInfo struct {
-	Type      string    `json:"type"`
-	Reason    string    `json:"reason"`
-	Message   string    `json:"message"`
-	Source    string    `json:"source"`
-	Timestamp time.Time `json:"timestamp"`
-	Count     int32     `json:"count"`
+	UID        string    `json:"uid,omitempty"`
+	Namespace  string    `json:"namespace,omitempty"`
+	ObjectKind string    `json:"object_kind,omitempty"` // 关联对象类型（Pod, Node...）
+	ObjectName string    `json:"object_name,omitempty"` // 关联对象名称
+	Type       string    `json:"type"`
+	Reason     string    `json:"reason"`
+	Message    string    `json:"message"`
+	Source     string    `json:"source"`
+	Timestamp  time.Time `json:"timestamp"`
+	Count      int32     `json:"count"`
+
+	Class        string `json:"class,omitempty"`         // noise, informational, actionable
+	ClassifiedBy string `json:"classified_by,omitempty"` // override, rules, llm, default
 }
 
 // NetworkPolicyInfo 包含网络策略信息
@@ -59,6 +69,7 @@ type NetworkPolicyInfo struct {
 	Name        string              `json:"name"`
 	Namespace   string              `json:"namespace"`
 	PodSelector map[string]string   `json:"pod_selector"`
+	PolicyTypes []string            `json:"policy_types,omitempty"` // Ingress、Egress
 	Ingress     []NetworkPolicyRule `json:"ingress"`
 	Egress      []NetworkPolicyRule `json:"egress"`
 }
@@ -80,6 +91,90 @@ type PortRule struct {
 type PeerRule struct {
 	PodSelector       map[string]string `json:"pod_selector"`
 	NamespaceSelector map[string]string `json:"namespace_selector"`
+	IPBlock           string            `json:"ip_block,omitempty"` // CIDR，例外网段以 except 列出
+}
+
+// WorkloadInfo 工作负载及其Pod模板的标签和端口（编写网络策略时用于选择器）
+type WorkloadInfo struct {
+	Kind            string            `json:"kind"` // Deployment, StatefulSet, DaemonSet, Pod
+	Namespace       string            `json:"namespace"`
+	Name            string            `json:"name"`
+	PodLabels       map[string]string `json:"pod_labels"`
+	NamespaceLabels map[string]string `json:"namespace_labels,omitempty"`
+	Ports           []PortRule        `json:"ports,omitempty"` // 容器声明的端口
+}
+
+// NetworkPolicyContext 两个工作负载之间的现有网络策略
+type NetworkPolicyContext struct {
+	Source      *WorkloadInfo        `json:"source"`
+	Destination *WorkloadInfo        `json:"destination"`
+	Policies    []*NetworkPolicyInfo `json:"policies"` // 两个命名空间中的所有策略
+	// 选中源（出站）和目标（入站）的策略名；非空时对应方向已被隔离，只放行策略允许的流量
+	SourceEgressPolicies       []string `json:"source_egress_policies"`
+	DestinationIngressPolicies []string `json:"destination_ingress_policies"`
+}
+
+// 策略可达性矩阵中一对命名空间的结论
+const (
+	ReachabilityAllowed = "allowed" // 所有Pod组合都放行
+	ReachabilityBlocked = "blocked" // 所有Pod组合都被拦截
+	ReachabilityPartial = "partial" // 部分Pod组合被拦截
+	ReachabilityEmpty   = "empty"   // 任一命名空间中没有Pod
+)
+
+// ReachabilityMatrix 按NetworkPolicy计算的命名空间间可达性（不发送实际流量），
+// Cells[i][j] 为从 Namespaces[i] 到 Namespaces[j] 的结论
+type ReachabilityMatrix struct {
+	Namespaces  []string             `json:"namespaces"`
+	Port        int32                `json:"port,omitempty"` // 为0时任一端口放行即视为可达
+	Protocol    string               `json:"protocol,omitempty"`
+	Cells       [][]ReachabilityCell `json:"cells"`
+	Environment *NetworkEnvironment  `json:"environment,omitempty"` // CNI不执行NetworkPolicy时矩阵只反映配置
+	Timestamp   time.Time            `json:"timestamp"`
+}
+
+// ReachabilityCell 两个命名空间之间的可达性，Pod按标签去重后两两计算
+type ReachabilityCell struct {
+	Status   string   `json:"status"`             // allowed、blocked、partial、empty
+	Allowed  int      `json:"allowed"`            // 放行的Pod组合数
+	Blocked  int      `json:"blocked"`            // 被拦截的Pod组合数
+	Policies []string `json:"policies,omitempty"` // 拦截流量的隔离策略（namespace/name）
+}
+
+// MTU诊断的结论
+const (
+	MTUOK          = "ok"          // 路径能通过网卡MTU大小的包
+	MTUReduced     = "reduced"     // 路径MTU小于网卡MTU，但有ICMP需要分片消息，PMTUD可以正常工作
+	MTUMismatch    = "mismatch"    // 超过路径MTU的包被静默丢弃（黑洞），典型的overlay MTU配置错误
+	MTUUnreachable = "unreachable" // 连默认大小的ping都没有回复
+)
+
+// MTUDiagnosis 两个节点或两个Pod之间的路径MTU诊断，由源节点上的探测Agent用设置DF位的ping测量
+type MTUDiagnosis struct {
+	Kind        string              `json:"kind"` // node 或 pod
+	Source      string              `json:"source"`
+	Target      string              `json:"target"`
+	SourceNode  string              `json:"source_node"`
+	TargetNode  string              `json:"target_node"`
+	MaxMTU      int                 `json:"max_mtu"`
+	Overlay     *MTUPath            `json:"overlay,omitempty"`  // Pod网络路径：到目标Pod（或目标节点上的Agent）的IP
+	Underlay    *MTUPath            `json:"underlay,omitempty"` // 到目标节点 InternalIP 的路径
+	Overhead    int                 `json:"overhead,omitempty"` // 两条路径MTU之差，通常为封装开销
+	Environment *NetworkEnvironment `json:"environment,omitempty"`
+	Status      string              `json:"status"` // ok、reduced、mismatch、unreachable
+	Issues      []string            `json:"issues"`
+	Solutions   []string            `json:"solutions"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+// MTUPath 一条路径的MTU测量结果（字节，IP包总长）
+type MTUPath struct {
+	Target       string `json:"target"` // 目标IP
+	PathMTU      int    `json:"path_mtu,omitempty"`
+	InterfaceMTU int    `json:"interface_mtu,omitempty"` // Agent发往目标的网卡MTU
+	ReportedMTU  int    `json:"reported_mtu,omitempty"`  // ping报告的MTU（本地网卡或ICMP需要分片消息）
+	Blackhole    bool   `json:"blackhole"`               // 超过路径MTU的包被静默丢弃
+	Error        string `json:"error,omitempty"`
 }
 
 // AnalysisRequest 分析请求
@@ -106,6 +201,162 @@ type CommunicationAnalysis struct {
 	Issues     []string `json:"issues"`
 	Solutions  []string `json:"solutions"`
 	Confidence float64  `json:"confidence"`
+
+	Method    string                   `json:"method"`              // "rules" 或 "llm"
+	Summary   string                   `json:"summary,omitempty"`   // LLM给出的结论
+	Diagnoses []CommunicationDiagnosis `json:"diagnoses,omitempty"` // LLM给出的诊断，按置信度从高到低
+	Findings  *CommunicationFindings   `json:"findings,omitempty"`  // 规则检查的原始数据
+	Model     string                   `json:"model,omitempty"`
+	Prompt    string                   `json:"prompt,omitempty"`    // 使用的提示词模板版本
+	LLMError  string                   `json:"llm_error,omitempty"` // LLM解读失败时的错误，结果为规则检查结论
+}
+
+// CommunicationFindings 通信分析各项检查的原始数据
+type CommunicationFindings struct {
+	PodA            *PodInfo             `json:"pod_a"`
+	PodB            *PodInfo             `json:"pod_b"`
+	NetworkPolicies []*NetworkPolicyInfo `json:"network_policies"`          // 选中任一Pod的网络策略
+	TargetServices  []string             `json:"target_services"`           // 指向Pod B的Service
+	ServicePaths    []ServicePath        `json:"service_paths,omitempty"`   // 经由各Service到Pod B的转发路径
+	CoreDNSRunning  *bool                `json:"coredns_running,omitempty"` // 为空表示未能检查
+	DNSLookups      []DNSLookup          `json:"dns_lookups,omitempty"`     // 从Pod A（或其节点上的探测Agent）进行的解析测试
+	RTT             *NetworkTestResult   `json:"rtt,omitempty"`
+	RTTSkipped      string               `json:"rtt_skipped,omitempty"` // 按exec策略跳过RTT测试的原因
+	RTTError        string               `json:"rtt_error,omitempty"`
+	Environment     *NetworkEnvironment  `json:"environment,omitempty"`  // 集群的CNI和kube-proxy模式
+	CheckErrors     []string             `json:"check_errors,omitempty"` // 未能完成的检查
+}
+
+// NetworkEnvironment 从kube-system中的工作负载和节点注解识别出的集群网络环境
+type NetworkEnvironment struct {
+	CNI           string `json:"cni"` // calico、cilium、flannel、weave、antrea、kube-router、canal，未识别时为 unknown
+	CNIVersion    string `json:"cni_version,omitempty"`
+	KubeProxyMode string `json:"kube_proxy_mode"` // iptables、ipvs、nftables、ebpf（由CNI替代kube-proxy），未识别时为 unknown
+	// 是否执行 NetworkPolicy：flannel 为false，calico、cilium 等为true，未知时为空
+	EnforcesNetworkPolicy *bool    `json:"enforces_network_policy,omitempty"`
+	PolicyMode            string   `json:"policy_mode,omitempty"` // cilium 的 enable-policy：default、always、never
+	Evidence              []string `json:"evidence,omitempty"`    // 识别依据
+	Notes                 []string `json:"notes,omitempty"`       // 与该环境相关的排查提示
+}
+
+// DNSLookup 一次DNS解析测试
+type DNSLookup struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`   // service（目标Service）、cluster（kubernetes.default）或 external
+	Source    string   `json:"source"` // agent 或 exec
+	Success   bool     `json:"success"`
+	Latency   float64  `json:"latency_ms"` // exec方式包含执行命令的开销
+	Addresses []string `json:"addresses,omitempty"`
+	Rcode     string   `json:"rcode,omitempty"` // NXDOMAIN、SERVFAIL、TIMEOUT
+	Error     string   `json:"error,omitempty"`
+}
+
+// Pod B在Service Endpoints中的状态
+const (
+	EndpointReady    = "ready"
+	EndpointNotReady = "not_ready"
+	EndpointMissing  = "missing"
+)
+
+// ServicePath Service到Pod B的转发路径检查
+type ServicePath struct {
+	Service        string            `json:"service"` // namespace/name
+	Type           string            `json:"type,omitempty"`
+	ClusterIP      string            `json:"cluster_ip,omitempty"`
+	ReadyEndpoints int               `json:"ready_endpoints"`
+	PodBEndpoint   string            `json:"pod_b_endpoint,omitempty"` // ready、not_ready、missing，为空表示未能检查
+	Ports          []ServicePortPath `json:"ports,omitempty"`
+	BrokenHop      string            `json:"broken_hop,omitempty"` // selector、endpoints、target_port、kube_proxy，为空表示路径完整
+	Detail         string            `json:"detail,omitempty"`
+}
+
+// ServicePortPath Service端口到容器端口的映射检查
+type ServicePortPath struct {
+	Port          int32  `json:"port"`
+	Protocol      string `json:"protocol"`
+	TargetPort    string `json:"target_port"`
+	ContainerPort int32  `json:"container_port,omitempty"` // 解析得到的容器端口，命名端口不存在时为0
+	OK            bool   `json:"ok"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+// IngressAnalysis 从集群外经由Ingress（或LoadBalancer Service）到后端Pod的链路检查
+type IngressAnalysis struct {
+	Ingress   string          `json:"ingress,omitempty"` // namespace/name，分析LoadBalancer Service时为空
+	Host      string          `json:"host,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Rule      string          `json:"rule,omitempty"` // 匹配的规则，例如 example.com/api (Prefix) 或 default backend
+	Class     string          `json:"class,omitempty"`
+	Addresses []string        `json:"addresses"` // Ingress或LoadBalancer分配的外部地址
+	TLS       *IngressTLS     `json:"tls,omitempty"`
+	Service   string          `json:"service,omitempty"` // 后端Service namespace/name
+	Port      string          `json:"port,omitempty"`    // 后端Service端口（数字或名称）
+	Backends  *IngressBackend `json:"backends,omitempty"`
+	External  *ExternalProbe  `json:"external,omitempty"`
+	Status    string          `json:"status"`               // reachable、broken，只做了集群内检查且都通过时为 configured
+	BrokenHop string          `json:"broken_hop,omitempty"` // 第一个断开的环节
+	Detail    string          `json:"detail,omitempty"`
+	Issues    []string        `json:"issues"`
+	Solutions []string        `json:"solutions"`
+
+	CheckErrors []string `json:"check_errors,omitempty"` // 未能完成的检查（例如没有读取Secret的权限）
+}
+
+// IngressTLS Ingress中覆盖该host的TLS配置
+type IngressTLS struct {
+	Secret       string     `json:"secret"` // namespace/name
+	Present      bool       `json:"present"`
+	CoversHost   bool       `json:"covers_host"` // 证书的SAN包含host
+	NotAfter     *time.Time `json:"not_after,omitempty"`
+	DNSNames     []string   `json:"dns_names,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Unverifiable bool       `json:"unverifiable,omitempty"` // 无法读取Secret，没有检查证书
+}
+
+// IngressBackend 后端Service的Endpoints和Pod
+type IngressBackend struct {
+	TargetPort        string              `json:"target_port,omitempty"`
+	ReadyEndpoints    int                 `json:"ready_endpoints"`
+	NotReadyEndpoints int                 `json:"not_ready_endpoints"`
+	Pods              []IngressBackendPod `json:"pods,omitempty"`
+}
+
+// IngressBackendPod Service选中的一个Pod
+type IngressBackendPod struct {
+	Name     string `json:"name"`
+	Node     string `json:"node,omitempty"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+}
+
+// ExternalProbe 从监控服务向外部地址发出的HTTP请求
+type ExternalProbe struct {
+	URL        string  `json:"url"`
+	Host       string  `json:"host,omitempty"` // 请求使用的Host头/SNI
+	Success    bool    `json:"success"`
+	StatusCode int     `json:"status_code,omitempty"`
+	Latency    float64 `json:"latency_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// CommunicationDiagnosis 通信问题的一项诊断
+type CommunicationDiagnosis struct {
+	Cause      string   `json:"cause"`
+	Confidence float64  `json:"confidence"`
+	Evidence   []string `json:"evidence,omitempty"`
+	Fix        string   `json:"fix,omitempty"`
+}
+
+// AnalysisRecord 持久化的分析结果
+type AnalysisRecord struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`   // "pod_communication", "root_cause", "anomaly"
+	Status    string          `json:"status"` // 分析结论状态，例如 connected/disconnected
+	Pods      []string        `json:"pods,omitempty"`
+	Summary   string          `json:"summary,omitempty"`
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 // SystemHealth 系统健康状态
@@ -178,11 +429,62 @@ type NetworkTestResult struct {
 	Latency     string      `json:"latency_assessment"` // 延迟评估：excellent, good, poor, very_poor
 }
 
+// BandwidthResult iperf3带宽测试结果
+type BandwidthResult struct {
+	PodA        string    `json:"pod_a"`
+	PodB        string    `json:"pod_b"`
+	Bandwidth   float64   `json:"bandwidth_mbps"`   // 接收端测得的TCP吞吐量 (Mbps)
+	Retransmits int       `json:"retransmits"`      // 发送端的TCP重传次数
+	Duration    float64   `json:"duration_seconds"` // 实际测试时长（秒）
+	Server      string    `json:"server"`           // 服务端运行方式：target 或 pod
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// MaxUAVsPerAgent 一个Agent最多模拟的UAV数量
+const MaxUAVsPerAgent = 64
+
+// uavKeySeparator UAV标识中节点名与序号的分隔符，节点名（DNS子域名）中不会出现
+const uavKeySeparator = ":"
+
+// UAVKey UAV在指标、CRD、历史和接口中的标识：Agent的第一架UAV（序号0）为节点名，
+// 同一个Agent模拟的其他UAV为 <节点名>:<序号>
+func UAVKey(nodeName string, index int) string {
+	if index <= 0 {
+		return nodeName
+	}
+	return nodeName + uavKeySeparator + strconv.Itoa(index)
+}
+
+// ParseUAVKey 从UAV标识中取出节点名和序号（UAVKey 的逆操作），没有序号的标识为节点名
+func ParseUAVKey(key string) (nodeName string, index int) {
+	node, suffix, ok := strings.Cut(key, uavKeySeparator)
+	if !ok {
+		return key, 0
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index <= 0 {
+		return key, 0
+	}
+	return node, index
+}
+
+// UAVRef 一架UAV：所在节点和在Agent中的序号
+type UAVRef struct {
+	NodeName string
+	Index    int
+}
+
+// Key UAV标识，见 UAVKey
+func (r UAVRef) Key() string {
+	return UAVKey(r.NodeName, r.Index)
+}
+
 // UAVReport 无人机遥测上报数据
 type UAVReport struct {
 	NodeName                 string            `json:"node_name"`
 	NodeIP                   string            `json:"node_ip,omitempty"`
 	UAVID                    string            `json:"uav_id"`
+	Index                    int               `json:"index,omitempty"` // UAV在Agent中的序号，一个Agent模拟多架UAV时区分各架
 	Source                   string            `json:"source"`
 	Status                   string            `json:"status"`
 	Timestamp                time.Time         `json:"timestamp"`
@@ -190,3 +492,152 @@ type UAVReport struct {
 	State                    *uav.UAVState     `json:"state,omitempty"`
 	Metadata                 map[string]string `json:"metadata,omitempty"`
 }
+
+// Key 上报的UAV标识，见 UAVKey
+func (r *UAVReport) Key() string {
+	return UAVKey(r.NodeName, r.Index)
+}
+
+// UAVMetricsEntry 指标管理器中一架UAV的状态（/api/v1/metrics/uav 的条目，key见 UAVKey），
+// 来源为主动拉取（pull）或Agent上报（agent）
+type UAVMetricsEntry struct {
+	NodeName                 string            `json:"node_name"`
+	NodeIP                   string            `json:"node_ip,omitempty"`
+	UAVID                    string            `json:"uav_id"`
+	Index                    int               `json:"index,omitempty"`
+	Status                   string            `json:"status"`
+	Source                   string            `json:"source"`
+	Timestamp                time.Time         `json:"timestamp"`
+	LastHeartbeat            time.Time         `json:"last_heartbeat"`
+	HeartbeatIntervalSeconds int               `json:"heartbeat_interval_seconds,omitempty"`
+	State                    *uav.UAVState     `json:"state,omitempty"`
+	Metadata                 map[string]string `json:"metadata,omitempty"`
+}
+
+// Key 条目的UAV标识，见 UAVKey
+func (e *UAVMetricsEntry) Key() string {
+	return UAVKey(e.NodeName, e.Index)
+}
+
+// UAV心跳超时后条目的状态
+const (
+	UAVStatusStale = "stale" // 错过 metrics.uav_staleness.stale_after 个心跳间隔
+	UAVStatusLost  = "lost"  // 错过 metrics.uav_staleness.lost_after 个心跳间隔
+)
+
+// UAVStatusChange UAV心跳状态的一次变化：超时降级为 stale/lost，或重新收到心跳后恢复
+type UAVStatusChange struct {
+	NodeName         string    `json:"node_name"`
+	UAVID            string    `json:"uav_id"`
+	Index            int       `json:"index,omitempty"`
+	From             string    `json:"from"`
+	To               string    `json:"to"`
+	LastHeartbeat    time.Time `json:"last_heartbeat"`
+	MissedHeartbeats int       `json:"missed_heartbeats"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// Key 发生变化的UAV标识，见 UAVKey
+func (c *UAVStatusChange) Key() string {
+	return UAVKey(c.NodeName, c.Index)
+}
+
+// Recovered 是否为恢复（由 stale/lost 变为其他状态）
+func (c *UAVStatusChange) Recovered() bool {
+	return c.To != UAVStatusStale && c.To != UAVStatusLost
+}
+
+// UAVGeofenceChange UAV越出地理围栏（Agent已触发返航），或越界后回到围栏内
+type UAVGeofenceChange struct {
+	NodeName         string    `json:"node_name"`
+	UAVID            string    `json:"uav_id"`
+	Index            int       `json:"index,omitempty"`
+	Breached         bool      `json:"breached"`
+	BreachCount      int       `json:"breach_count"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	RelativeAltitude float64   `json:"relative_altitude"`
+	Action           string    `json:"action,omitempty"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// Key 越界或恢复的UAV标识，见 UAVKey
+func (c *UAVGeofenceChange) Key() string {
+	return UAVKey(c.NodeName, c.Index)
+}
+
+// NewUAVMetricsEntry 由Agent上报生成UAV条目，缺省状态为active、来源为agent、时间为当前时间；
+// 状态和元数据为深拷贝，不与上报方共享
+func NewUAVMetricsEntry(report *UAVReport) *UAVMetricsEntry {
+	entry := &UAVMetricsEntry{
+		NodeName:                 report.NodeName,
+		NodeIP:                   report.NodeIP,
+		UAVID:                    report.UAVID,
+		Index:                    report.Index,
+		Status:                   report.Status,
+		Source:                   report.Source,
+		Timestamp:                report.Timestamp,
+		LastHeartbeat:            report.Timestamp,
+		HeartbeatIntervalSeconds: report.HeartbeatIntervalSeconds,
+		State:                    report.State.Clone(),
+	}
+	if entry.Status == "" {
+		entry.Status = "active"
+	}
+	if entry.Source == "" {
+		entry.Source = "agent"
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+		entry.LastHeartbeat = entry.Timestamp
+	}
+	if len(report.Metadata) > 0 {
+		entry.Metadata = make(map[string]string, len(report.Metadata))
+		for key, value := range report.Metadata {
+			entry.Metadata[key] = value
+		}
+	}
+	return entry
+}
+
+// Clone 返回条目的深拷贝
+func (e *UAVMetricsEntry) Clone() *UAVMetricsEntry {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.State = e.State.Clone()
+	if e.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(e.Metadata))
+		for key, value := range e.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+	return &clone
+}
+
+// UAVTelemetryPoint UAV遥测历史数据点
+type UAVTelemetryPoint struct {
+	Timestamp        time.Time `json:"timestamp"`
+	NodeName         string    `json:"node_name"`
+	UAVID            string    `json:"uav_id"`
+	Status           string    `json:"status"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	Altitude         float64   `json:"altitude"`
+	RelativeAltitude float64   `json:"relative_altitude"`
+	GroundSpeed      float64   `json:"ground_speed"`
+	BatteryPercent   float64   `json:"battery_percent"`
+	BatteryVoltage   float64   `json:"battery_voltage"`
+	FlightMode       string    `json:"flight_mode"`
+	Armed            bool      `json:"armed"`
+	SystemStatus     string    `json:"system_status"`
+}
+
+// UAVModeChange UAV飞行模式变化记录
+type UAVModeChange struct {
+	Timestamp time.Time `json:"timestamp"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Armed     bool      `json:"armed"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestUAVKey(t *testing.T) {
+	tests := []struct {
+		node  string
+		index int
+		key   string
+	}{
+		{"edge-1", 0, "edge-1"},
+		{"edge-1", 2, "edge-1:2"},
+		{"x", 1, "x:1"},
+		{"x-uav1", 0, "x-uav1"},
+		{"edge.example.com", 3, "edge.example.com:3"},
+	}
+	for _, tt := range tests {
+		if got := UAVKey(tt.node, tt.index); got != tt.key {
+			t.Errorf("UAVKey(%q, %d) = %q, want %q", tt.node, tt.index, got, tt.key)
+		}
+		if node, index := ParseUAVKey(tt.key); node != tt.node || index != tt.index {
+			t.Errorf("ParseUAVKey(%q) = %q, %d, want %q, %d", tt.key, node, index, tt.node, tt.index)
+		}
+	}
+
+	// 不是 UAVKey 生成的序号按节点名处理
+	for _, key := range []string{"edge-1:0", "edge-1:-1", "edge-1:two", "edge-1:"} {
+		if node, index := ParseUAVKey(key); node != key || index != 0 {
+			t.Errorf("ParseUAVKey(%q) = %q, %d, want the key as node name", key, node, index)
+		}
+	}
+}
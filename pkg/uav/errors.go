@@ -0,0 +1,19 @@
+package uav
+
+import "errors"
+
+// 控制命令被拒绝的原因。命令返回的错误包装了以下错误之一，调用方用 errors.Is 判断
+var (
+	// ErrInvalidCommand 命令参数不合法（如未知的飞行模式、超出范围的起飞高度）
+	ErrInvalidCommand = errors.New("invalid command")
+	// ErrNotArmed UAV未解锁
+	ErrNotArmed = errors.New("UAV is not armed")
+	// ErrInFlight UAV仍在空中，需要先降落
+	ErrInFlight = errors.New("UAV is in flight")
+	// ErrNoGPSFix GPS定位不满足要求（没有3D定位、卫星数不足或精度太差）
+	ErrNoGPSFix = errors.New("insufficient GPS fix")
+	// ErrLowBattery 电量低于解锁或起飞的最低要求
+	ErrLowBattery = errors.New("battery too low")
+	// ErrSensorsUnhealthy 有传感器处于不健康状态
+	ErrSensorsUnhealthy = errors.New("sensors unhealthy")
+)
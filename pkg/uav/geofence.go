@@ -0,0 +1,199 @@
+package uav
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 地理围栏类型
+const (
+	GeofencePolygon  = "polygon"  // 多边形区域（顶点按顺序给出）
+	GeofenceCylinder = "cylinder" // 以中心点和半径定义的圆柱区域
+)
+
+// MaxGeofenceVertices 多边形围栏的最大顶点数
+const MaxGeofenceVertices = 100
+
+// ErrOutsideGeofence 位置或目标高度在地理围栏之外
+var ErrOutsideGeofence = errors.New("outside the geofence")
+
+// GeoPoint 经纬度坐标
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geofence 允许飞行的区域。配置了多个围栏时，位于任意一个围栏内即为在围栏内
+type Geofence struct {
+	Name        string     `json:"name,omitempty"`
+	Type        string     `json:"type"`                   // polygon 或 cylinder
+	Points      []GeoPoint `json:"points,omitempty"`       // polygon：顶点（至少3个）
+	Center      GeoPoint   `json:"center"`                 // cylinder：中心点
+	Radius      float64    `json:"radius,omitempty"`       // cylinder：半径（米）
+	MaxAltitude float64    `json:"max_altitude,omitempty"` // 相对起飞点的高度上限（米），为0时不限制
+}
+
+// GeofenceStatus UAV相对地理围栏的状态
+type GeofenceStatus struct {
+	Enabled     bool       `json:"enabled"`               // 是否配置了围栏
+	Inside      bool       `json:"inside"`                // 当前位置是否在围栏内
+	Breached    bool       `json:"breached"`              // 飞行中越出围栏，回到围栏内后恢复
+	BreachCount int        `json:"breach_count"`          // 越界次数
+	LastBreach  *time.Time `json:"last_breach,omitempty"` // 最近一次越界的时间
+	Action      string     `json:"action,omitempty"`      // 越界时采取的动作（RTL）
+}
+
+// Validate 校验围栏定义
+func (f Geofence) Validate() error {
+	name := f.Name
+	if name == "" {
+		name = f.Type
+	}
+	if math.IsNaN(f.MaxAltitude) || f.MaxAltitude < 0 {
+		return fmt.Errorf("geofence %s: max_altitude must not be negative", name)
+	}
+
+	switch f.Type {
+	case GeofencePolygon:
+		if len(f.Points) < 3 || len(f.Points) > MaxGeofenceVertices {
+			return fmt.Errorf("geofence %s: a polygon needs between 3 and %d points", name, MaxGeofenceVertices)
+		}
+		for i, p := range f.Points {
+			if err := validateGeoPoint(p); err != nil {
+				return fmt.Errorf("geofence %s: point %d: %w", name, i+1, err)
+			}
+		}
+	case GeofenceCylinder:
+		if err := validateGeoPoint(f.Center); err != nil {
+			return fmt.Errorf("geofence %s: center: %w", name, err)
+		}
+		if math.IsNaN(f.Radius) || f.Radius <= 0 {
+			return fmt.Errorf("geofence %s: radius must be positive", name)
+		}
+	default:
+		return fmt.Errorf("geofence %s: type must be %s or %s, got %q", name, GeofencePolygon, GeofenceCylinder, f.Type)
+	}
+	return nil
+}
+
+// validateGeoPoint 校验经纬度范围
+func validateGeoPoint(p GeoPoint) error {
+	if math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// Contains 判断位置（相对高度，米）是否在围栏内
+func (f Geofence) Contains(lat, lon, altitude float64) bool {
+	if f.MaxAltitude > 0 && altitude > f.MaxAltitude {
+		return false
+	}
+	switch f.Type {
+	case GeofenceCylinder:
+		north := (lat - f.Center.Latitude) * metersPerDegree
+		east := (lon - f.Center.Longitude) * metersPerDegree * math.Cos(f.Center.Latitude*math.Pi/180)
+		return math.Hypot(north, east) <= f.Radius
+	case GeofencePolygon:
+		// 射线法：围栏范围较小，按经纬度平面计算
+		inside := false
+		for i, j := 0, len(f.Points)-1; i < len(f.Points); j, i = i, i+1 {
+			a, b := f.Points[i], f.Points[j]
+			if (a.Latitude > lat) != (b.Latitude > lat) &&
+				lon < (b.Longitude-a.Longitude)*(lat-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+				inside = !inside
+			}
+		}
+		return inside
+	}
+	return false
+}
+
+// ValidateGeofences 校验围栏列表
+func ValidateGeofences(fences []Geofence) error {
+	for _, fence := range fences {
+		if err := fence.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insideGeofences 位置是否在任意一个围栏内，没有配置围栏时总是在围栏内
+func insideGeofences(fences []Geofence, lat, lon, altitude float64) bool {
+	if len(fences) == 0 {
+		return true
+	}
+	for _, fence := range fences {
+		if fence.Contains(lat, lon, altitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGeofences 替换地理围栏，为空时关闭围栏检查
+func (m *MAVLinkSimulator) SetGeofences(fences []Geofence) error {
+	if err := ValidateGeofences(fences); err != nil {
+		return err
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	m.fences = append([]Geofence(nil), fences...)
+	gps := m.state.GPS
+	m.state.Geofence.Enabled = len(fences) > 0
+	m.state.Geofence.Inside = insideGeofences(m.fences, gps.Latitude, gps.Longitude, gps.RelativeAltitude)
+	if !m.state.Geofence.Enabled {
+		m.state.Geofence.Breached = false
+		m.state.Geofence.Action = ""
+	}
+	m.addMessage(fmt.Sprintf("Geofence updated: %d fences", len(fences)))
+	return nil
+}
+
+// GetGeofences 获取当前的地理围栏（线程安全）
+func (m *MAVLinkSimulator) GetGeofences() []Geofence {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+
+	return append([]Geofence{}, m.fences...)
+}
+
+// checkGeofence 检查当前位置：飞行中越出围栏时暂停任务并返航，回到围栏内后清除越界状态。调用方需持有 stateMu
+func (m *MAVLinkSimulator) checkGeofence(now time.Time) {
+	fence := &m.state.Geofence
+	if !fence.Enabled {
+		return
+	}
+	gps := m.state.GPS
+	fence.Inside = insideGeofences(m.fences, gps.Latitude, gps.Longitude, gps.RelativeAltitude)
+
+	switch {
+	case fence.Inside && fence.Breached:
+		fence.Breached = false
+		m.addMessage("Back inside geofence")
+	case !fence.Inside && !fence.Breached && m.state.Flight.Armed:
+		breachedAt := now
+		fence.Breached = true
+		fence.BreachCount++
+		fence.LastBreach = &breachedAt
+		fence.Action = "RTL"
+		m.state.Health.WarningCount++
+		if m.state.Health.SystemStatus == "OK" {
+			m.state.Health.SystemStatus = "WARNING"
+		}
+		m.addMessage(fmt.Sprintf("Geofence breach at %.6f,%.6f %.1fm - returning to launch", gps.Latitude, gps.Longitude, gps.RelativeAltitude))
+		// 已经在返航或降落时继续执行
+		if mode := m.state.Flight.Mode; mode != "RTL" && mode != "LAND" {
+			m.interruptMission()
+			m.state.Flight.Mode = "RTL"
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package uav
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGeofenceContains(t *testing.T) {
+	square := Geofence{Type: GeofencePolygon, MaxAltitude: 100, Points: []GeoPoint{
+		{Latitude: 39.90, Longitude: 116.40},
+		{Latitude: 39.90, Longitude: 116.41},
+		{Latitude: 39.91, Longitude: 116.41},
+		{Latitude: 39.91, Longitude: 116.40},
+	}}
+	cylinder := Geofence{Type: GeofenceCylinder, Center: GeoPoint{Latitude: 39.90, Longitude: 116.40}, Radius: 100}
+	east := func(meters float64) float64 { return 116.40 + meters/(metersPerDegree*math.Cos(39.90*math.Pi/180)) }
+
+	tests := []struct {
+		name          string
+		fence         Geofence
+		lat, lon, alt float64
+		want          bool
+	}{
+		{"inside the polygon", square, 39.905, 116.405, 50, true},
+		{"outside the polygon", square, 39.915, 116.405, 50, false},
+		{"above the polygon ceiling", square, 39.905, 116.405, 150, false},
+		{"inside the cylinder", cylinder, 39.90, east(90), 1000, true},
+		{"outside the cylinder", cylinder, 39.90, east(110), 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fence.Contains(tt.lat, tt.lon, tt.alt); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, invalid := range []Geofence{
+		{Type: "circle"},
+		{Type: GeofencePolygon, Points: square.Points[:2]},
+		{Type: GeofenceCylinder, Center: GeoPoint{Latitude: 91}, Radius: 10},
+		{Type: GeofenceCylinder, Center: cylinder.Center},
+		{Type: GeofenceCylinder, Center: cylinder.Center, Radius: 10, MaxAltitude: -1},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", invalid)
+		}
+	}
+}
+
+func TestGeofenceEnforcement(t *testing.T) {
+	sim := NewMAVLinkSimulator("UAV-test", "node-a")
+	home := sim.GetState().GPS
+	fence := Geofence{Type: GeofenceCylinder, Center: GeoPoint{Latitude: home.Latitude, Longitude: home.Longitude}, Radius: 50, MaxAltitude: 40}
+	if err := sim.SetGeofences([]Geofence{fence}); err != nil {
+		t.Fatalf("SetGeofences: %v", err)
+	}
+	if err := sim.Arm(); err != nil {
+		t.Fatalf("Arm: %v", err)
+	}
+
+	if err := sim.TakeOff(60); !errors.Is(err, ErrOutsideGeofence) {
+		t.Fatalf("TakeOff above the ceiling = %v, want ErrOutsideGeofence", err)
+	}
+	outside := []Waypoint{{Latitude: home.Latitude + 100/metersPerDegree, Longitude: home.Longitude, Altitude: 20}}
+	if err := sim.UploadMission(outside); !errors.Is(err, ErrInvalidMission) {
+		t.Fatalf("UploadMission outside the fence = %v, want ErrInvalidMission", err)
+	}
+	inside := []Waypoint{{Latitude: home.Latitude + 10/metersPerDegree, Longitude: home.Longitude, Altitude: 20}}
+	if err := sim.UploadMission(inside); err != nil {
+		t.Fatalf("UploadMission inside the fence: %v", err)
+	}
+	if err := sim.TakeOff(20); err != nil {
+		t.Fatalf("TakeOff inside the fence: %v", err)
+	}
+
+	// 围栏收缩后UAV越界：切换为返航
+	shrunk := Geofence{Type: GeofenceCylinder, Center: GeoPoint{Latitude: home.Latitude + 1000/metersPerDegree, Longitude: home.Longitude}, Radius: 50}
+	if err := sim.SetGeofences([]Geofence{shrunk}); err != nil {
+		t.Fatalf("SetGeofences: %v", err)
+	}
+	sim.updateState(0)
+	state := sim.GetState()
+	if !state.Geofence.Breached || state.Geofence.BreachCount != 1 || state.Geofence.LastBreach == nil {
+		t.Fatalf("geofence status = %+v, want a recorded breach", state.Geofence)
+	}
+	if state.Flight.Mode != "RTL" || state.Geofence.Action != "RTL" {
+		t.Errorf("mode = %s action = %s, want RTL", state.Flight.Mode, state.Geofence.Action)
+	}
+	if err := sim.StartMission(); !errors.Is(err, ErrOutsideGeofence) {
+		t.Errorf("StartMission after a breach = %v, want ErrOutsideGeofence", err)
+	}
+
+	// 同一次越界不重复计数，回到围栏内后恢复
+	sim.updateState(0)
+	if state := sim.GetState(); state.Geofence.BreachCount != 1 {
+		t.Errorf("breach count = %d after a second update, want 1", state.Geofence.BreachCount)
+	}
+	if err := sim.SetGeofences([]Geofence{fence}); err != nil {
+		t.Fatalf("SetGeofences: %v", err)
+	}
+	sim.updateState(0)
+	if state := sim.GetState(); state.Geofence.Breached || !state.Geofence.Inside {
+		t.Errorf("geofence status = %+v, want back inside", state.Geofence)
+	}
+}
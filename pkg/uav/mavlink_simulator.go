@@ -1,8 +1,10 @@
 package uav
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 )
@@ -12,6 +14,7 @@ type UAVState struct {
 	// 基本信息
 	UAVID      string    `json:"uav_id"`
 	NodeName   string    `json:"node_name"`
+	Profile    string    `json:"profile,omitempty"` // 飞行参数名称
 	SystemTime time.Time `json:"system_time"`
 
 	// GPS信息
@@ -32,7 +35,36 @@ type UAVState struct {
 	// 健康状态
 	Health HealthData `json:"health"`
 
-	mu sync.RWMutex
+	// 地理围栏状态
+	Geofence GeofenceStatus `json:"geofence"`
+
+	// 解锁前检查结果
+	PreArm PreArmStatus `json:"pre_arm"`
+}
+
+// Clone 返回状态的深拷贝（map和切片不与原状态共享）
+func (s *UAVState) Clone() *UAVState {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	if s.Health.SensorsHealth != nil {
+		clone.Health.SensorsHealth = make(map[string]bool, len(s.Health.SensorsHealth))
+		for sensor, healthy := range s.Health.SensorsHealth {
+			clone.Health.SensorsHealth[sensor] = healthy
+		}
+	}
+	if s.Health.Messages != nil {
+		clone.Health.Messages = append([]string(nil), s.Health.Messages...)
+	}
+	if s.Geofence.LastBreach != nil {
+		lastBreach := *s.Geofence.LastBreach
+		clone.Geofence.LastBreach = &lastBreach
+	}
+	if s.PreArm.Checks != nil {
+		clone.PreArm.Checks = append([]PreArmCheck(nil), s.PreArm.Checks...)
+	}
+	return &clone
 }
 
 // GPSData GPS数据
@@ -51,58 +83,64 @@ type GPSData struct {
 
 // AttitudeData 姿态数据
 type AttitudeData struct {
-	Roll         float64   `json:"roll"`          // 横滚角 (度)
-	Pitch        float64   `json:"pitch"`         // 俯仰角 (度)
-	Yaw          float64   `json:"yaw"`           // 偏航角/航向 (度)
-	RollRate     float64   `json:"roll_rate"`     // 横滚角速度 (度/秒)
-	PitchRate    float64   `json:"pitch_rate"`    // 俯仰角速度 (度/秒)
-	YawRate      float64   `json:"yaw_rate"`      // 偏航角速度 (度/秒)
-	Timestamp    time.Time `json:"timestamp"`
+	Roll      float64   `json:"roll"`       // 横滚角 (度)
+	Pitch     float64   `json:"pitch"`      // 俯仰角 (度)
+	Yaw       float64   `json:"yaw"`        // 偏航角/航向 (度)
+	RollRate  float64   `json:"roll_rate"`  // 横滚角速度 (度/秒)
+	PitchRate float64   `json:"pitch_rate"` // 俯仰角速度 (度/秒)
+	YawRate   float64   `json:"yaw_rate"`   // 偏航角速度 (度/秒)
+	Timestamp time.Time `json:"timestamp"`
 }
 
+// FlightModes 支持的飞行模式
+var FlightModes = []string{"MANUAL", "STABILIZE", "LOITER", "AUTO", "GUIDED", "RTL", "LAND"}
+
+// gpsModes 需要GPS定位的飞行模式
+var gpsModes = []string{"LOITER", "AUTO", "GUIDED", "RTL"}
+
 // FlightData 飞行数据
 type FlightData struct {
-	Mode            string    `json:"mode"`              // 飞行模式 (MANUAL, STABILIZE, LOITER, AUTO, RTL, LAND)
-	Armed           bool      `json:"armed"`             // 是否解锁
-	Airspeed        float64   `json:"airspeed"`          // 空速 (m/s)
-	GroundSpeed     float64   `json:"ground_speed"`      // 地速 (m/s)
-	VerticalSpeed   float64   `json:"vertical_speed"`    // 垂直速度 (m/s)
-	ThrottlePercent float64   `json:"throttle_percent"`  // 油门百分比
+	Mode            string    `json:"mode"`             // 飞行模式 (MANUAL, STABILIZE, LOITER, AUTO, RTL, LAND)
+	Armed           bool      `json:"armed"`            // 是否解锁
+	Airspeed        float64   `json:"airspeed"`         // 空速 (m/s)
+	GroundSpeed     float64   `json:"ground_speed"`     // 地速 (m/s)
+	VerticalSpeed   float64   `json:"vertical_speed"`   // 垂直速度 (m/s)
+	ThrottlePercent float64   `json:"throttle_percent"` // 油门百分比
 	Timestamp       time.Time `json:"timestamp"`
 }
 
 // BatteryData 电池数据
 type BatteryData struct {
-	Voltage            float64   `json:"voltage"`              // 电压 (V)
-	Current            float64   `json:"current"`              // 电流 (A)
-	RemainingPercent   float64   `json:"remaining_percent"`    // 剩余电量百分比
-	RemainingCapacity  float64   `json:"remaining_capacity"`   // 剩余容量 (mAh)
-	TotalCapacity      float64   `json:"total_capacity"`       // 总容量 (mAh)
-	Temperature        float64   `json:"temperature"`          // 温度 (°C)
-	CellCount          int       `json:"cell_count"`           // 电芯数量
-	TimeRemaining      int       `json:"time_remaining"`       // 预计剩余时间 (秒)
-	Timestamp          time.Time `json:"timestamp"`
+	Voltage           float64   `json:"voltage"`            // 电压 (V)
+	Current           float64   `json:"current"`            // 电流 (A)
+	RemainingPercent  float64   `json:"remaining_percent"`  // 剩余电量百分比
+	RemainingCapacity float64   `json:"remaining_capacity"` // 剩余容量 (mAh)
+	TotalCapacity     float64   `json:"total_capacity"`     // 总容量 (mAh)
+	Temperature       float64   `json:"temperature"`        // 温度 (°C)
+	CellCount         int       `json:"cell_count"`         // 电芯数量
+	TimeRemaining     int       `json:"time_remaining"`     // 预计剩余时间 (秒)
+	Timestamp         time.Time `json:"timestamp"`
 }
 
 // MissionData 任务数据
 type MissionData struct {
-	CurrentWaypoint int       `json:"current_waypoint"`  // 当前航点
-	TotalWaypoints  int       `json:"total_waypoints"`   // 总航点数
-	MissionState    string    `json:"mission_state"`     // 任务状态 (IDLE, ACTIVE, PAUSED, COMPLETED)
-	DistanceToWP    float64   `json:"distance_to_wp"`    // 到下一航点距离 (米)
-	ETAToWP         int       `json:"eta_to_wp"`         // 到达航点预计时间 (秒)
+	CurrentWaypoint int       `json:"current_waypoint"` // 当前飞往的航点（从1开始，没有执行任务时为0）
+	TotalWaypoints  int       `json:"total_waypoints"`  // 总航点数
+	MissionState    string    `json:"mission_state"`    // 任务状态 (IDLE, ACTIVE, PAUSED, COMPLETED)
+	DistanceToWP    float64   `json:"distance_to_wp"`   // 到下一航点距离 (米)
+	ETAToWP         int       `json:"eta_to_wp"`        // 到达航点预计时间 (秒)
 	Timestamp       time.Time `json:"timestamp"`
 }
 
 // HealthData 健康状态
 type HealthData struct {
-	SystemStatus     string            `json:"system_status"`      // 系统状态 (OK, WARNING, CRITICAL, ERROR)
-	SensorsHealth    map[string]bool   `json:"sensors_health"`     // 传感器健康状态
-	ErrorCount       int               `json:"error_count"`        // 错误计数
-	WarningCount     int               `json:"warning_count"`      // 警告计数
-	Messages         []string          `json:"messages"`           // 状态消息
-	LastHeartbeat    time.Time         `json:"last_heartbeat"`     // 最后心跳时间
-	Timestamp        time.Time         `json:"timestamp"`
+	SystemStatus  string          `json:"system_status"`  // 系统状态 (OK, WARNING, CRITICAL, ERROR)
+	SensorsHealth map[string]bool `json:"sensors_health"` // 传感器健康状态
+	ErrorCount    int             `json:"error_count"`    // 错误计数
+	WarningCount  int             `json:"warning_count"`  // 警告计数
+	Messages      []string        `json:"messages"`       // 状态消息
+	LastHeartbeat time.Time       `json:"last_heartbeat"` // 最后心跳时间
+	Timestamp     time.Time       `json:"timestamp"`
 }
 
 // MAVLinkSimulator MAVLink模拟器
@@ -112,14 +150,41 @@ type MAVLinkSimulator struct {
 	updateRate time.Duration // 更新频率
 	stopChan   chan struct{}
 	mu         sync.RWMutex
+	stateMu    sync.RWMutex // 保护 state 以及以下的飞行和任务状态
+
+	profile        FlightProfile
+	homeLatitude   float64    // 起飞点
+	homeLongitude  float64    // 起飞点
+	homeAltitude   float64    // 起飞点海拔（米）
+	targetAltitude float64    // GUIDED模式起飞的目标相对高度（米）
+	waypoints      []Waypoint // 已上传的任务航点
+	fences         []Geofence // 地理围栏，为空时不检查
+	holdUntil      time.Time  // 在已到达的航点悬停到该时间，为零值时不在悬停
+}
+
+// SimulatorOptions 模拟器的可选配置
+type SimulatorOptions struct {
+	Home    *GeoPoint     // 起飞点，为空时在北京附近随机选择
+	Profile FlightProfile // 飞行参数，为零值时使用 ProfileStandard
 }
 
 // NewMAVLinkSimulator 创建MAVLink模拟器
 func NewMAVLinkSimulator(uavID, nodeName string) *MAVLinkSimulator {
-	return &MAVLinkSimulator{
+	return NewMAVLinkSimulatorWithOptions(uavID, nodeName, SimulatorOptions{})
+}
+
+// NewMAVLinkSimulatorWithOptions 按指定的起飞点和飞行参数创建MAVLink模拟器（同一个Agent模拟多架UAV时使用）
+func NewMAVLinkSimulatorWithOptions(uavID, nodeName string, opts SimulatorOptions) *MAVLinkSimulator {
+	profile := opts.Profile
+	if !profile.valid() {
+		profile = ProfileStandard
+	}
+	simulator := &MAVLinkSimulator{
+		profile: profile,
 		state: &UAVState{
 			UAVID:      uavID,
 			NodeName:   nodeName,
+			Profile:    profile.Name,
 			SystemTime: time.Now(),
 			GPS: GPSData{
 				Latitude:       39.9042 + rand.Float64()*0.01, // 北京附近随机位置
@@ -140,8 +205,8 @@ func NewMAVLinkSimulator(uavID, nodeName string) *MAVLinkSimulator {
 				ThrottlePercent: 0,
 			},
 			Battery: BatteryData{
-				Voltage:           22.2,  // 6S电池
-				Current:           0.5,   // 待机电流
+				Voltage:           22.2, // 6S电池
+				Current:           0.5,  // 待机电流
 				RemainingPercent:  100.0,
 				RemainingCapacity: 5000.0,
 				TotalCapacity:     5000.0,
@@ -156,22 +221,32 @@ func NewMAVLinkSimulator(uavID, nodeName string) *MAVLinkSimulator {
 			Health: HealthData{
 				SystemStatus: "OK",
 				SensorsHealth: map[string]bool{
-					"gps":          true,
-					"compass":      true,
+					"gps":           true,
+					"compass":       true,
 					"accelerometer": true,
-					"gyroscope":    true,
-					"barometer":    true,
-					"battery":      true,
+					"gyroscope":     true,
+					"barometer":     true,
+					"battery":       true,
 				},
-				ErrorCount:   0,
-				WarningCount: 0,
-				Messages:     []string{},
+				ErrorCount:    0,
+				WarningCount:  0,
+				Messages:      []string{},
 				LastHeartbeat: time.Now(),
 			},
 		},
 		updateRate: 100 * time.Millisecond, // 10Hz更新频率
 		stopChan:   make(chan struct{}),
 	}
+	if opts.Home != nil {
+		simulator.state.GPS.Latitude = opts.Home.Latitude
+		simulator.state.GPS.Longitude = opts.Home.Longitude
+	}
+	// 初始位置即起飞点，返航时回到这里
+	simulator.homeLatitude = simulator.state.GPS.Latitude
+	simulator.homeLongitude = simulator.state.GPS.Longitude
+	simulator.homeAltitude = simulator.state.GPS.Altitude
+	simulator.state.PreArm = simulator.preArmStatus()
+	return simulator
 }
 
 // Start 启动模拟器
@@ -203,45 +278,77 @@ func (m *MAVLinkSimulator) Stop() {
 
 // GetState 获取当前状态（线程安全）
 func (m *MAVLinkSimulator) GetState() UAVState {
-	m.state.mu.RLock()
-	defer m.state.mu.RUnlock()
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
 
-	// 返回状态副本
-	return *m.state
+	// 返回状态的深拷贝，调用方读取时不会与模拟器更新竞争
+	return *m.state.Clone()
 }
 
-// SetFlightMode 设置飞行模式
-func (m *MAVLinkSimulator) SetFlightMode(mode string) {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+// SetFlightMode 设置飞行模式（未知模式返回 ErrInvalidCommand，需要GPS的模式在没有3D定位时返回 ErrNoGPSFix）
+func (m *MAVLinkSimulator) SetFlightMode(mode string) error {
+	if !slices.Contains(FlightModes, mode) {
+		return fmt.Errorf("%w: unknown flight mode %q, expected one of %v", ErrInvalidCommand, mode, FlightModes)
+	}
 
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	if slices.Contains(gpsModes, mode) && m.state.GPS.FixType < minArmFixType {
+		return fmt.Errorf("%w: %s mode requires a 3D fix", ErrNoGPSFix, mode)
+	}
+	if mode != "AUTO" {
+		m.interruptMission()
+	}
 	m.state.Flight.Mode = mode
-	m.state.Health.Messages = append(m.state.Health.Messages,
-		"Flight mode changed to: "+mode)
+	m.addMessage("Flight mode changed to: " + mode)
+	return nil
 }
 
-// Arm 解锁
+// Arm 解锁。解锁前检查未通过时返回对应的错误（ErrNoGPSFix、ErrLowBattery、ErrSensorsUnhealthy、ErrOutsideGeofence），
+// 已解锁时直接返回nil
 func (m *MAVLinkSimulator) Arm() error {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
-	// 检查是否满足解锁条件
-	if m.state.GPS.FixType < 3 {
-		return nil // 实际应返回error，这里简化处理
+	if m.state.Flight.Armed {
+		return nil
+	}
+	m.state.PreArm = m.preArmStatus()
+	if err := preArmError(m.state.PreArm); err != nil {
+		m.addMessage("Arming rejected: " + err.Error())
+		return err
 	}
 
 	m.state.Flight.Armed = true
-	m.state.Health.Messages = append(m.state.Health.Messages, "Armed")
+	// 降落后仍处于LAND/RTL模式，解锁后切回STABILIZE，否则会立即再次降落上锁
+	if m.state.Flight.Mode == "LAND" || m.state.Flight.Mode == "RTL" {
+		m.state.Flight.Mode = "STABILIZE"
+	}
+	m.addMessage("Armed")
 	return nil
 }
 
-// Disarm 上锁
-func (m *MAVLinkSimulator) Disarm() {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+// Disarm 上锁（仍在空中时返回 ErrInFlight），未解锁时直接返回nil
+func (m *MAVLinkSimulator) Disarm() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
+	if !m.state.Flight.Armed {
+		return nil
+	}
+	if m.state.GPS.RelativeAltitude > landedAltitude {
+		return fmt.Errorf("%w: %.1fm above home, land before disarming", ErrInFlight, m.state.GPS.RelativeAltitude)
+	}
+	m.interruptMission()
 	m.state.Flight.Armed = false
-	m.state.Health.Messages = append(m.state.Health.Messages, "Disarmed")
+	m.addMessage("Disarmed")
+	return nil
+}
+
+// addMessage 追加一条状态消息。调用方需持有 stateMu
+func (m *MAVLinkSimulator) addMessage(message string) {
+	m.state.Health.Messages = append(m.state.Health.Messages, message)
 }
 
 // simulationLoop 模拟循环
@@ -263,32 +370,20 @@ func (m *MAVLinkSimulator) simulationLoop() {
 
 // updateState 更新状态
 func (m *MAVLinkSimulator) updateState(elapsedTime float64) {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
 	now := time.Now()
 
-	// 更新GPS（模拟飞行轨迹）
-	if m.state.Flight.Armed && m.state.Flight.Mode == "AUTO" {
-		// 模拟圆形飞行轨迹
-		radius := 0.001 // 约100米半径
-		omega := 0.1    // 角速度
-
-		centerLat := 39.9042
-		centerLon := 116.4074
-
-		m.state.GPS.Latitude = centerLat + radius*math.Cos(omega*elapsedTime)
-		m.state.GPS.Longitude = centerLon + radius*math.Sin(omega*elapsedTime)
-		m.state.GPS.RelativeAltitude = 50.0 + 10.0*math.Sin(0.05*elapsedTime)
-		m.state.GPS.GroundSpeed = 5.0 + rand.Float64()*0.5
-		m.state.GPS.CourseOverGround = math.Mod(omega*elapsedTime*180/math.Pi, 360)
-	}
+	// 更新GPS（按飞行模式移动）
+	m.fly(now, m.updateRate.Seconds())
+	m.checkGeofence(now)
 	m.state.GPS.Timestamp = now
 
 	// 更新姿态（模拟飞行姿态变化）
 	if m.state.Flight.Armed {
-		m.state.Attitude.Roll = 5.0 * math.Sin(0.5*elapsedTime) + rand.Float64()*0.5
-		m.state.Attitude.Pitch = 3.0 * math.Cos(0.3*elapsedTime) + rand.Float64()*0.3
+		m.state.Attitude.Roll = 5.0*math.Sin(0.5*elapsedTime) + rand.Float64()*0.5
+		m.state.Attitude.Pitch = 3.0*math.Cos(0.3*elapsedTime) + rand.Float64()*0.3
 		m.state.Attitude.Yaw = math.Mod(m.state.GPS.CourseOverGround, 360)
 		m.state.Attitude.RollRate = rand.Float64()*2.0 - 1.0
 		m.state.Attitude.PitchRate = rand.Float64()*2.0 - 1.0
@@ -300,18 +395,18 @@ func (m *MAVLinkSimulator) updateState(elapsedTime float64) {
 	if m.state.Flight.Armed {
 		m.state.Flight.Airspeed = m.state.GPS.GroundSpeed + rand.Float64()*0.5
 		m.state.Flight.GroundSpeed = m.state.GPS.GroundSpeed
-		m.state.Flight.VerticalSpeed = math.Cos(0.05*elapsedTime) * 2.0
 		m.state.Flight.ThrottlePercent = 50.0 + 20.0*math.Sin(0.1*elapsedTime)
 	} else {
+		m.state.Flight.Airspeed = 0
+		m.state.Flight.GroundSpeed = 0
 		m.state.Flight.ThrottlePercent = 0
-		m.state.Flight.VerticalSpeed = 0
 	}
 	m.state.Flight.Timestamp = now
 
 	// 更新电池（模拟放电）
 	if m.state.Flight.Armed {
-		// 每秒消耗约0.1%电量
-		dischargeRate := 0.1 / (1.0 / m.updateRate.Seconds())
+		// 按飞行参数的耗电速度放电（默认每秒约0.1%）
+		dischargeRate := m.profile.DischargeRate * m.updateRate.Seconds()
 		m.state.Battery.RemainingPercent -= dischargeRate
 		if m.state.Battery.RemainingPercent < 0 {
 			m.state.Battery.RemainingPercent = 0
@@ -346,6 +441,8 @@ func (m *MAVLinkSimulator) updateState(elapsedTime float64) {
 		m.state.Health.Messages = append(m.state.Health.Messages, "Critical battery level - RTL recommended")
 	}
 
+	m.state.PreArm = m.preArmStatus()
+
 	// 限制消息数量
 	if len(m.state.Health.Messages) > 10 {
 		m.state.Health.Messages = m.state.Health.Messages[len(m.state.Health.Messages)-10:]
@@ -354,35 +451,99 @@ func (m *MAVLinkSimulator) updateState(elapsedTime float64) {
 	m.state.SystemTime = now
 }
 
-// TakeOff 起飞
-func (m *MAVLinkSimulator) TakeOff(altitude float64) {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+// fly 按飞行模式移动一个更新周期：AUTO执行任务航点，GUIDED爬升到起飞高度，RTL飞回起飞点上空后降落，
+// LAND原地降落并在着地后自动上锁，其他模式悬停。调用方需持有 stateMu
+func (m *MAVLinkSimulator) fly(now time.Time, dt float64) {
+	gps := &m.state.GPS
+	profile := m.profile
+	var groundSpeed, verticalSpeed float64
+
+	if m.state.Flight.Armed {
+		switch m.state.Flight.Mode {
+		case "AUTO":
+			if m.state.Mission.MissionState == MissionActive {
+				groundSpeed, verticalSpeed = m.flyMission(now, dt)
+			}
+		case "GUIDED":
+			groundSpeed, verticalSpeed, _, _ = m.moveTo(gps.Latitude, gps.Longitude, m.targetAltitude, 0, profile.ClimbRate, dt)
+		case "RTL":
+			var horizontal float64
+			groundSpeed, verticalSpeed, horizontal, _ = m.moveTo(m.homeLatitude, m.homeLongitude,
+				math.Max(gps.RelativeAltitude, rtlAltitude), profile.CruiseSpeed, profile.ClimbRate, dt)
+			if horizontal <= waypointAcceptRadius {
+				m.state.Flight.Mode = "LAND"
+				m.addMessage("Reached home, landing")
+			}
+		case "LAND":
+			groundSpeed, verticalSpeed, _, _ = m.moveTo(gps.Latitude, gps.Longitude, 0, 0, profile.LandRate, dt)
+			if gps.RelativeAltitude <= 0 {
+				m.state.Flight.Armed = false
+				m.addMessage("Landed, disarmed")
+			}
+		}
+	}
+
+	gps.GroundSpeed = groundSpeed
+	m.state.Flight.VerticalSpeed = verticalSpeed
+}
+
+// TakeOff 起飞到目标相对高度。未解锁时返回 ErrNotArmed，电量不足时返回 ErrLowBattery，
+// 当前位置或目标高度在地理围栏之外时返回 ErrOutsideGeofence
+func (m *MAVLinkSimulator) TakeOff(altitude float64) error {
+	if math.IsNaN(altitude) || altitude <= 0 || altitude > MaxMissionAltitude {
+		return fmt.Errorf("%w: altitude must be between 0 and %.0f meters", ErrInvalidCommand, MaxMissionAltitude)
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
 	if !m.state.Flight.Armed {
-		return
+		return fmt.Errorf("%w: arm before taking off", ErrNotArmed)
+	}
+	if m.state.GPS.FixType < minArmFixType {
+		return fmt.Errorf("%w: taking off requires a 3D fix", ErrNoGPSFix)
+	}
+	if remaining := m.state.Battery.RemainingPercent; remaining < MinArmBattery {
+		return fmt.Errorf("%w: %.1f%% remaining, at least %.0f%% is required to take off", ErrLowBattery, remaining, MinArmBattery)
+	}
+	if gps := m.state.GPS; !insideGeofences(m.fences, gps.Latitude, gps.Longitude, altitude) {
+		return fmt.Errorf("%w: cannot take off to %.1fm at %.6f,%.6f", ErrOutsideGeofence, altitude, gps.Latitude, gps.Longitude)
 	}
 
-	m.state.Flight.Mode = "AUTO"
-	m.state.Mission.MissionState = "ACTIVE"
-	m.state.Health.Messages = append(m.state.Health.Messages,
-		"Taking off to altitude: " + string(rune(altitude)))
+	m.interruptMission()
+	m.targetAltitude = altitude
+	m.state.Flight.Mode = "GUIDED"
+	m.addMessage(fmt.Sprintf("Taking off to altitude: %.1fm", altitude))
+	return nil
 }
 
-// Land 降落
-func (m *MAVLinkSimulator) Land() {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+// Land 原地降落（未解锁时返回 ErrNotArmed）
+func (m *MAVLinkSimulator) Land() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
+	if !m.state.Flight.Armed {
+		return fmt.Errorf("%w: nothing to land", ErrNotArmed)
+	}
+	m.interruptMission()
 	m.state.Flight.Mode = "LAND"
-	m.state.Health.Messages = append(m.state.Health.Messages, "Landing initiated")
+	m.addMessage("Landing initiated")
+	return nil
 }
 
-// ReturnToLaunch 返航
-func (m *MAVLinkSimulator) ReturnToLaunch() {
-	m.state.mu.Lock()
-	defer m.state.mu.Unlock()
+// ReturnToLaunch 返航（未解锁时返回 ErrNotArmed，没有3D定位时返回 ErrNoGPSFix）
+func (m *MAVLinkSimulator) ReturnToLaunch() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 
+	if !m.state.Flight.Armed {
+		return fmt.Errorf("%w: nothing to return", ErrNotArmed)
+	}
+	if m.state.GPS.FixType < minArmFixType {
+		return fmt.Errorf("%w: RTL requires a 3D fix", ErrNoGPSFix)
+	}
+	m.interruptMission()
 	m.state.Flight.Mode = "RTL"
-	m.state.Health.Messages = append(m.state.Health.Messages, "Returning to launch")
+	m.addMessage("Returning to launch")
+	return nil
 }
@@ -0,0 +1,290 @@
+package uav
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 任务状态
+const (
+	MissionIdle      = "IDLE"
+	MissionActive    = "ACTIVE"
+	MissionPaused    = "PAUSED"
+	MissionCompleted = "COMPLETED"
+)
+
+// 任务和飞行参数
+const (
+	MaxWaypoints       = 200   // 单个任务的最大航点数
+	MaxMissionAltitude = 500.0 // 航点的最大相对高度（米）
+	MaxMissionSpeed    = 30.0  // 航点的最大飞行速度（m/s）
+	DefaultCruiseSpeed = 5.0   // 默认飞行参数中航点未指定速度时的飞行速度（m/s）
+
+	rtlAltitude          = 30.0 // 返航的最低相对高度（米）
+	waypointAcceptRadius = 2.0  // 距航点小于该距离（米）时视为到达
+	metersPerDegree      = 111320.0
+)
+
+var (
+	// ErrInvalidMission 航点列表不合法
+	ErrInvalidMission = errors.New("invalid mission")
+	// ErrNoMission 没有上传任务
+	ErrNoMission = errors.New("no mission uploaded")
+	// ErrMissionInProgress 任务执行或暂停中，不能替换
+	ErrMissionInProgress = errors.New("mission in progress")
+	// ErrMissionNotActive 任务不在执行或暂停中
+	ErrMissionNotActive = errors.New("mission is not active")
+)
+
+// Waypoint 任务航点
+type Waypoint struct {
+	Latitude    float64 `json:"latitude"`               // 纬度（度）
+	Longitude   float64 `json:"longitude"`              // 经度（度）
+	Altitude    float64 `json:"altitude"`               // 相对起飞点高度（米）
+	Speed       float64 `json:"speed,omitempty"`        // 飞往该航点的速度（m/s），为0时使用默认速度
+	HoldSeconds int     `json:"hold_seconds,omitempty"` // 到达后悬停的时间（秒）
+}
+
+// MissionProgress 任务进度：任务状态和已上传的航点
+type MissionProgress struct {
+	MissionData
+	Waypoints []Waypoint `json:"waypoints"`
+}
+
+// validateMission 校验航点列表
+func validateMission(waypoints []Waypoint) error {
+	if len(waypoints) == 0 {
+		return fmt.Errorf("%w: at least one waypoint is required", ErrInvalidMission)
+	}
+	if len(waypoints) > MaxWaypoints {
+		return fmt.Errorf("%w: at most %d waypoints are allowed, got %d", ErrInvalidMission, MaxWaypoints, len(waypoints))
+	}
+	for i, wp := range waypoints {
+		switch {
+		case math.IsNaN(wp.Latitude) || wp.Latitude < -90 || wp.Latitude > 90:
+			return fmt.Errorf("%w: waypoint %d latitude must be between -90 and 90", ErrInvalidMission, i+1)
+		case math.IsNaN(wp.Longitude) || wp.Longitude < -180 || wp.Longitude > 180:
+			return fmt.Errorf("%w: waypoint %d longitude must be between -180 and 180", ErrInvalidMission, i+1)
+		case math.IsNaN(wp.Altitude) || wp.Altitude <= 0 || wp.Altitude > MaxMissionAltitude:
+			return fmt.Errorf("%w: waypoint %d altitude must be between 0 and %.0f meters", ErrInvalidMission, i+1, MaxMissionAltitude)
+		case math.IsNaN(wp.Speed) || wp.Speed < 0 || wp.Speed > MaxMissionSpeed:
+			return fmt.Errorf("%w: waypoint %d speed must be between 0 and %.0f m/s", ErrInvalidMission, i+1, MaxMissionSpeed)
+		case wp.HoldSeconds < 0:
+			return fmt.Errorf("%w: waypoint %d hold_seconds must not be negative", ErrInvalidMission, i+1)
+		}
+	}
+	return nil
+}
+
+// UploadMission 上传任务航点，替换之前的任务（执行或暂停中时返回 ErrMissionInProgress，
+// 航点在地理围栏之外时返回 ErrInvalidMission）
+func (m *MAVLinkSimulator) UploadMission(waypoints []Waypoint) error {
+	if err := validateMission(waypoints); err != nil {
+		return err
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	if state := m.state.Mission.MissionState; state == MissionActive || state == MissionPaused {
+		return ErrMissionInProgress
+	}
+	for i, wp := range waypoints {
+		if !insideGeofences(m.fences, wp.Latitude, wp.Longitude, wp.Altitude) {
+			return fmt.Errorf("%w: waypoint %d is %s", ErrInvalidMission, i+1, ErrOutsideGeofence)
+		}
+	}
+	m.waypoints = append([]Waypoint(nil), waypoints...)
+	m.holdUntil = time.Time{}
+	m.state.Mission = MissionData{
+		TotalWaypoints: len(waypoints),
+		MissionState:   MissionIdle,
+		Timestamp:      time.Now(),
+	}
+	m.addMessage(fmt.Sprintf("Mission uploaded: %d waypoints", len(waypoints)))
+	return nil
+}
+
+// StartMission 切换到AUTO模式执行任务：暂停的任务从当前航点继续，否则从第一个航点开始
+func (m *MAVLinkSimulator) StartMission() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	if len(m.waypoints) == 0 {
+		return ErrNoMission
+	}
+	if !m.state.Flight.Armed {
+		return fmt.Errorf("%w: arm before starting the mission", ErrNotArmed)
+	}
+	if m.state.GPS.FixType < minArmFixType {
+		return fmt.Errorf("%w: AUTO mode requires a 3D fix", ErrNoGPSFix)
+	}
+	if m.state.Geofence.Breached {
+		return fmt.Errorf("%w: cannot start a mission after a geofence breach", ErrOutsideGeofence)
+	}
+
+	mission := &m.state.Mission
+	switch mission.MissionState {
+	case MissionActive:
+	case MissionPaused:
+		m.addMessage(fmt.Sprintf("Mission resumed at waypoint %d/%d", mission.CurrentWaypoint, mission.TotalWaypoints))
+	default:
+		mission.CurrentWaypoint = 1
+		m.holdUntil = time.Time{}
+		m.addMessage("Mission started")
+	}
+	mission.MissionState = MissionActive
+	mission.Timestamp = time.Now()
+	m.state.Flight.Mode = "AUTO"
+	return nil
+}
+
+// PauseMission 暂停任务，在当前位置悬停（LOITER）
+func (m *MAVLinkSimulator) PauseMission() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	mission := &m.state.Mission
+	if mission.MissionState != MissionActive {
+		return ErrMissionNotActive
+	}
+	mission.MissionState = MissionPaused
+	mission.Timestamp = time.Now()
+	m.holdUntil = time.Time{}
+	m.state.Flight.Mode = "LOITER"
+	m.addMessage(fmt.Sprintf("Mission paused at waypoint %d/%d", mission.CurrentWaypoint, mission.TotalWaypoints))
+	return nil
+}
+
+// AbortMission 中止任务并在当前位置悬停，任务航点保留，可以重新开始
+func (m *MAVLinkSimulator) AbortMission() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	mission := &m.state.Mission
+	if mission.MissionState != MissionActive && mission.MissionState != MissionPaused {
+		return ErrMissionNotActive
+	}
+	m.state.Mission = MissionData{
+		TotalWaypoints: len(m.waypoints),
+		MissionState:   MissionIdle,
+		Timestamp:      time.Now(),
+	}
+	m.holdUntil = time.Time{}
+	if m.state.Flight.Mode == "AUTO" {
+		m.state.Flight.Mode = "LOITER"
+	}
+	m.addMessage("Mission aborted")
+	return nil
+}
+
+// GetMissionProgress 获取任务进度（线程安全）
+func (m *MAVLinkSimulator) GetMissionProgress() MissionProgress {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+
+	return MissionProgress{
+		MissionData: m.state.Mission,
+		Waypoints:   append([]Waypoint{}, m.waypoints...),
+	}
+}
+
+// interruptMission 切换到其他飞行模式（降落、返航等）时暂停执行中的任务。调用方需持有 stateMu
+func (m *MAVLinkSimulator) interruptMission() {
+	if m.state.Mission.MissionState != MissionActive {
+		return
+	}
+	m.state.Mission.MissionState = MissionPaused
+	m.state.Mission.Timestamp = time.Now()
+	m.holdUntil = time.Time{}
+}
+
+// flyMission AUTO模式下飞往当前航点，到达后悬停指定时间再前往下一个航点。调用方需持有 stateMu
+func (m *MAVLinkSimulator) flyMission(now time.Time, dt float64) (groundSpeed, verticalSpeed float64) {
+	mission := &m.state.Mission
+	if mission.CurrentWaypoint < 1 || mission.CurrentWaypoint > len(m.waypoints) {
+		return 0, 0
+	}
+	wp := m.waypoints[mission.CurrentWaypoint-1]
+
+	if !m.holdUntil.IsZero() {
+		if now.Before(m.holdUntil) {
+			mission.DistanceToWP = 0
+			mission.ETAToWP = int(math.Ceil(m.holdUntil.Sub(now).Seconds()))
+			mission.Timestamp = now
+			return 0, 0
+		}
+		m.holdUntil = time.Time{}
+		m.nextWaypoint(now)
+		return 0, 0
+	}
+
+	speed := wp.Speed
+	if speed <= 0 {
+		speed = m.profile.CruiseSpeed
+	}
+	climbRate := m.profile.ClimbRate
+	groundSpeed, verticalSpeed, horizontal, vertical := m.moveTo(wp.Latitude, wp.Longitude, wp.Altitude, speed, climbRate, dt)
+	mission.DistanceToWP = math.Hypot(horizontal, vertical)
+	mission.ETAToWP = int(math.Ceil(math.Max(horizontal/speed, math.Abs(vertical)/climbRate)))
+	mission.Timestamp = now
+
+	if mission.DistanceToWP <= waypointAcceptRadius {
+		m.addMessage(fmt.Sprintf("Reached waypoint %d/%d", mission.CurrentWaypoint, mission.TotalWaypoints))
+		if wp.HoldSeconds > 0 {
+			m.holdUntil = now.Add(time.Duration(wp.HoldSeconds) * time.Second)
+		} else {
+			m.nextWaypoint(now)
+		}
+	}
+	return groundSpeed, verticalSpeed
+}
+
+// nextWaypoint 前往下一个航点，最后一个航点之后任务完成并悬停
+func (m *MAVLinkSimulator) nextWaypoint(now time.Time) {
+	mission := &m.state.Mission
+	if mission.CurrentWaypoint >= len(m.waypoints) {
+		mission.MissionState = MissionCompleted
+		mission.DistanceToWP = 0
+		mission.ETAToWP = 0
+		mission.Timestamp = now
+		m.state.Flight.Mode = "LOITER"
+		m.addMessage("Mission completed")
+		return
+	}
+	mission.CurrentWaypoint++
+}
+
+// moveTo 以给定的水平速度和垂直速度向目标位置移动一个更新周期，返回本周期的地速、垂直速度和剩余的水平、垂直距离（米）。
+// 调用方需持有 stateMu
+func (m *MAVLinkSimulator) moveTo(lat, lon, alt, speed, vRate, dt float64) (groundSpeed, verticalSpeed, horizontal, vertical float64) {
+	gps := &m.state.GPS
+
+	north := (lat - gps.Latitude) * metersPerDegree
+	east := (lon - gps.Longitude) * metersPerDegree * math.Cos(gps.Latitude*math.Pi/180)
+	horizontal = math.Hypot(north, east)
+	if horizontal > 0 && speed > 0 {
+		step := math.Min(horizontal, speed*dt)
+		if step == horizontal {
+			gps.Latitude, gps.Longitude = lat, lon
+		} else {
+			gps.Latitude += north * step / horizontal / metersPerDegree
+			gps.Longitude += east * step / horizontal / (metersPerDegree * math.Cos(gps.Latitude*math.Pi/180))
+		}
+		gps.CourseOverGround = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+		groundSpeed = step / dt
+		horizontal -= step
+	}
+
+	vertical = alt - gps.RelativeAltitude
+	if vertical != 0 && vRate > 0 {
+		step := math.Copysign(math.Min(math.Abs(vertical), vRate*dt), vertical)
+		gps.RelativeAltitude += step
+		verticalSpeed = step / dt
+		vertical -= step
+	}
+	gps.Altitude = m.homeAltitude + gps.RelativeAltitude
+	return groundSpeed, verticalSpeed, horizontal, vertical
+}
@@ -0,0 +1,134 @@
+package uav
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// runSimulator 推进模拟器直到 done 返回true，最多 maxSteps 个更新周期
+func runSimulator(t *testing.T, sim *MAVLinkSimulator, maxSteps int, done func(UAVState) bool) UAVState {
+	t.Helper()
+	for i := 0; i < maxSteps; i++ {
+		sim.updateState(float64(i) * sim.updateRate.Seconds())
+		if state := sim.GetState(); done(state) {
+			return state
+		}
+	}
+	t.Fatalf("simulator did not reach the expected state in %d steps: %+v", maxSteps, sim.GetState().Mission)
+	return UAVState{}
+}
+
+// distanceMeters 当前位置到给定坐标的水平距离（米）
+func distanceMeters(gps GPSData, lat, lon float64) float64 {
+	north := (lat - gps.Latitude) * metersPerDegree
+	east := (lon - gps.Longitude) * metersPerDegree * math.Cos(gps.Latitude*math.Pi/180)
+	return math.Hypot(north, east)
+}
+
+func TestMissionFlight(t *testing.T) {
+	sim := NewMAVLinkSimulator("UAV-test", "node-a")
+	start := sim.GetState().GPS
+
+	// 向北约50米、再向东约50米
+	waypoints := []Waypoint{
+		{Latitude: start.Latitude + 50/metersPerDegree, Longitude: start.Longitude, Altitude: 20, Speed: 10},
+		{Latitude: start.Latitude + 50/metersPerDegree, Longitude: start.Longitude + 50/(metersPerDegree*math.Cos(start.Latitude*math.Pi/180)), Altitude: 20},
+	}
+	if err := sim.StartMission(); !errors.Is(err, ErrNoMission) {
+		t.Fatalf("StartMission without a mission = %v, want ErrNoMission", err)
+	}
+	if err := sim.UploadMission(waypoints); err != nil {
+		t.Fatalf("UploadMission: %v", err)
+	}
+	if err := sim.StartMission(); !errors.Is(err, ErrNotArmed) {
+		t.Fatalf("StartMission while disarmed = %v, want ErrNotArmed", err)
+	}
+	if err := sim.Arm(); err != nil {
+		t.Fatalf("Arm: %v", err)
+	}
+	if err := sim.StartMission(); err != nil {
+		t.Fatalf("StartMission: %v", err)
+	}
+	if err := sim.UploadMission(waypoints); !errors.Is(err, ErrMissionInProgress) {
+		t.Fatalf("UploadMission during a mission = %v, want ErrMissionInProgress", err)
+	}
+
+	sim.updateState(0)
+	first := sim.GetState()
+	if first.Mission.CurrentWaypoint != 1 || first.Mission.DistanceToWP <= 0 || first.Mission.ETAToWP <= 0 {
+		t.Fatalf("mission after one step = %+v, want progress towards waypoint 1", first.Mission)
+	}
+	if first.GPS.GroundSpeed != 10 || first.GPS.CourseOverGround > 1 {
+		t.Errorf("ground speed %.1f course %.1f, want 10 m/s heading north", first.GPS.GroundSpeed, first.GPS.CourseOverGround)
+	}
+
+	// 暂停时原地悬停，恢复后从当前航点继续
+	if err := sim.PauseMission(); err != nil {
+		t.Fatalf("PauseMission: %v", err)
+	}
+	sim.updateState(0)
+	if paused := sim.GetState(); paused.GPS.Latitude != first.GPS.Latitude || paused.Flight.Mode != "LOITER" {
+		t.Fatalf("paused UAV moved or mode = %s", paused.Flight.Mode)
+	}
+	if err := sim.StartMission(); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	reached := runSimulator(t, sim, 1000, func(s UAVState) bool { return s.Mission.CurrentWaypoint == 2 })
+	if reached.Mission.MissionState != MissionActive {
+		t.Fatalf("mission state = %s after waypoint 1", reached.Mission.MissionState)
+	}
+
+	done := runSimulator(t, sim, 1000, func(s UAVState) bool { return s.Mission.MissionState == MissionCompleted })
+	last := waypoints[len(waypoints)-1]
+	if distanceMeters(done.GPS, last.Latitude, last.Longitude) > waypointAcceptRadius ||
+		math.Abs(done.GPS.RelativeAltitude-last.Altitude) > waypointAcceptRadius {
+		t.Errorf("completed at %.6f,%.6f %.1fm, want the last waypoint", done.GPS.Latitude, done.GPS.Longitude, done.GPS.RelativeAltitude)
+	}
+	if done.Flight.Mode != "LOITER" || done.Mission.DistanceToWP != 0 {
+		t.Errorf("after completion mode = %s distance = %.1f", done.Flight.Mode, done.Mission.DistanceToWP)
+	}
+	if err := sim.AbortMission(); !errors.Is(err, ErrMissionNotActive) {
+		t.Errorf("AbortMission after completion = %v, want ErrMissionNotActive", err)
+	}
+
+	// 返航后降落并自动上锁
+	if err := sim.ReturnToLaunch(); err != nil {
+		t.Fatalf("ReturnToLaunch: %v", err)
+	}
+	landed := runSimulator(t, sim, 2000, func(s UAVState) bool { return !s.Flight.Armed })
+	if landed.GPS.RelativeAltitude != 0 || distanceMeters(landed.GPS, start.Latitude, start.Longitude) > waypointAcceptRadius {
+		t.Errorf("landed at %.6f %.1fm, want home on the ground", landed.GPS.Latitude, landed.GPS.RelativeAltitude)
+	}
+}
+
+func TestValidateMission(t *testing.T) {
+	valid := Waypoint{Latitude: 39.9, Longitude: 116.4, Altitude: 30}
+	tests := []struct {
+		name      string
+		waypoints []Waypoint
+		wantErr   bool
+	}{
+		{"valid", []Waypoint{valid}, false},
+		{"empty", nil, true},
+		{"latitude out of range", []Waypoint{{Latitude: 91, Longitude: 116.4, Altitude: 30}}, true},
+		{"longitude out of range", []Waypoint{{Latitude: 39.9, Longitude: -181, Altitude: 30}}, true},
+		{"ground level", []Waypoint{{Latitude: 39.9, Longitude: 116.4}}, true},
+		{"too high", []Waypoint{{Latitude: 39.9, Longitude: 116.4, Altitude: MaxMissionAltitude + 1}}, true},
+		{"too fast", []Waypoint{{Latitude: 39.9, Longitude: 116.4, Altitude: 30, Speed: MaxMissionSpeed + 1}}, true},
+		{"negative hold", []Waypoint{{Latitude: 39.9, Longitude: 116.4, Altitude: 30, HoldSeconds: -1}}, true},
+		{"too many", make([]Waypoint, MaxWaypoints+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMission(tt.waypoints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateMission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidMission) {
+				t.Errorf("error %v does not wrap ErrInvalidMission", err)
+			}
+		})
+	}
+}
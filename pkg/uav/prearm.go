@@ -0,0 +1,110 @@
+package uav
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 解锁和起飞条件
+const (
+	MinArmBattery = 20.0 // 解锁和起飞的最低剩余电量（%），与低电量警告阈值一致
+
+	minArmFixType    = 3   // 要求3D定位
+	minArmSatellites = 6   // 最少卫星数
+	maxArmHDOP       = 2.0 // 最大水平精度因子
+	landedAltitude   = 0.5 // 相对高度低于该值（米）时视为在地面
+)
+
+// 解锁前检查项
+const (
+	PreArmGPS      = "gps"
+	PreArmBattery  = "battery"
+	PreArmSensors  = "sensors"
+	PreArmGeofence = "geofence"
+)
+
+// PreArmCheck 一项解锁前检查的结果
+type PreArmCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// PreArmStatus 解锁前检查结果，全部通过时 Ready 为true
+type PreArmStatus struct {
+	Ready  bool          `json:"ready"`
+	Checks []PreArmCheck `json:"checks"`
+}
+
+// preArmStatus 按当前状态执行解锁前检查。调用方需持有 stateMu
+func (m *MAVLinkSimulator) preArmStatus() PreArmStatus {
+	gps := m.state.GPS
+	battery := m.state.Battery
+
+	gpsCheck := PreArmCheck{Name: PreArmGPS, Passed: true,
+		Message: fmt.Sprintf("3D fix, %d satellites, HDOP %.1f", gps.SatelliteCount, gps.HDOP)}
+	switch {
+	case gps.FixType < minArmFixType:
+		gpsCheck.Passed, gpsCheck.Message = false, fmt.Sprintf("fix type %d, a 3D fix is required", gps.FixType)
+	case gps.SatelliteCount < minArmSatellites:
+		gpsCheck.Passed, gpsCheck.Message = false, fmt.Sprintf("%d satellites, at least %d are required", gps.SatelliteCount, minArmSatellites)
+	case gps.HDOP > maxArmHDOP:
+		gpsCheck.Passed, gpsCheck.Message = false, fmt.Sprintf("HDOP %.1f exceeds %.1f", gps.HDOP, maxArmHDOP)
+	}
+
+	batteryCheck := PreArmCheck{Name: PreArmBattery, Passed: battery.RemainingPercent >= MinArmBattery,
+		Message: fmt.Sprintf("%.1f%% remaining", battery.RemainingPercent)}
+	if !batteryCheck.Passed {
+		batteryCheck.Message += fmt.Sprintf(", at least %.0f%% is required", MinArmBattery)
+	}
+
+	var unhealthy []string
+	for sensor, healthy := range m.state.Health.SensorsHealth {
+		if !healthy {
+			unhealthy = append(unhealthy, sensor)
+		}
+	}
+	sort.Strings(unhealthy)
+	sensorsCheck := PreArmCheck{Name: PreArmSensors, Passed: len(unhealthy) == 0, Message: "all sensors healthy"}
+	if !sensorsCheck.Passed {
+		sensorsCheck.Message = "unhealthy: " + strings.Join(unhealthy, ", ")
+	}
+
+	geofenceCheck := PreArmCheck{Name: PreArmGeofence, Passed: true, Message: "no geofence configured"}
+	if m.state.Geofence.Enabled {
+		geofenceCheck.Passed = insideGeofences(m.fences, gps.Latitude, gps.Longitude, gps.RelativeAltitude)
+		geofenceCheck.Message = "inside the geofence"
+		if !geofenceCheck.Passed {
+			geofenceCheck.Message = "outside the geofence"
+		}
+	}
+
+	status := PreArmStatus{Ready: true, Checks: []PreArmCheck{gpsCheck, batteryCheck, sensorsCheck, geofenceCheck}}
+	for _, check := range status.Checks {
+		status.Ready = status.Ready && check.Passed
+	}
+	return status
+}
+
+// preArmError 返回第一项未通过的检查对应的错误，全部通过时返回nil
+func preArmError(status PreArmStatus) error {
+	for _, check := range status.Checks {
+		if check.Passed {
+			continue
+		}
+		var reason error
+		switch check.Name {
+		case PreArmGPS:
+			reason = ErrNoGPSFix
+		case PreArmBattery:
+			reason = ErrLowBattery
+		case PreArmSensors:
+			reason = ErrSensorsUnhealthy
+		case PreArmGeofence:
+			reason = ErrOutsideGeofence
+		}
+		return fmt.Errorf("%w: pre-arm check %s failed: %s", reason, check.Name, check.Message)
+	}
+	return nil
+}
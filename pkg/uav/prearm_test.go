@@ -0,0 +1,113 @@
+package uav
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArmPreArmChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		degrade func(s *UAVState)
+		check   string
+		want    error
+	}{
+		{"no 3D fix", func(s *UAVState) { s.GPS.FixType = 2 }, PreArmGPS, ErrNoGPSFix},
+		{"too few satellites", func(s *UAVState) { s.GPS.SatelliteCount = 4 }, PreArmGPS, ErrNoGPSFix},
+		{"poor HDOP", func(s *UAVState) { s.GPS.HDOP = 3.5 }, PreArmGPS, ErrNoGPSFix},
+		{"low battery", func(s *UAVState) { s.Battery.RemainingPercent = 15 }, PreArmBattery, ErrLowBattery},
+		{"unhealthy compass", func(s *UAVState) { s.Health.SensorsHealth["compass"] = false }, PreArmSensors, ErrSensorsUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := NewMAVLinkSimulator("UAV-test", "node-a")
+			tt.degrade(sim.state)
+
+			if err := sim.Arm(); !errors.Is(err, tt.want) {
+				t.Fatalf("Arm() = %v, want %v", err, tt.want)
+			}
+			state := sim.GetState()
+			if state.Flight.Armed {
+				t.Fatal("UAV armed although a pre-arm check failed")
+			}
+			if state.PreArm.Ready {
+				t.Error("PreArm.Ready = true, want false")
+			}
+			for _, check := range state.PreArm.Checks {
+				if got := check.Name != tt.check; check.Passed != got {
+					t.Errorf("check %s passed = %v (%s), want %v", check.Name, check.Passed, check.Message, got)
+				}
+			}
+		})
+	}
+
+	sim := NewMAVLinkSimulator("UAV-test", "node-a")
+	home := sim.GetState().GPS
+	away := Geofence{Type: GeofenceCylinder, Center: GeoPoint{Latitude: home.Latitude + 0.01, Longitude: home.Longitude}, Radius: 50}
+	if err := sim.SetGeofences([]Geofence{away}); err != nil {
+		t.Fatalf("SetGeofences: %v", err)
+	}
+	if err := sim.Arm(); !errors.Is(err, ErrOutsideGeofence) {
+		t.Fatalf("Arm() outside the geofence = %v, want ErrOutsideGeofence", err)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	sim := NewMAVLinkSimulator("UAV-test", "node-a")
+	if state := sim.GetState(); !state.PreArm.Ready || len(state.PreArm.Checks) != 4 {
+		t.Fatalf("PreArm = %+v, want 4 passing checks", state.PreArm)
+	}
+
+	// 未解锁时不能起飞、降落或返航
+	if err := sim.TakeOff(20); !errors.Is(err, ErrNotArmed) {
+		t.Errorf("TakeOff while disarmed = %v, want ErrNotArmed", err)
+	}
+	if err := sim.Land(); !errors.Is(err, ErrNotArmed) {
+		t.Errorf("Land while disarmed = %v, want ErrNotArmed", err)
+	}
+	if err := sim.ReturnToLaunch(); !errors.Is(err, ErrNotArmed) {
+		t.Errorf("ReturnToLaunch while disarmed = %v, want ErrNotArmed", err)
+	}
+	if err := sim.SetFlightMode("CIRCLE"); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("SetFlightMode(CIRCLE) = %v, want ErrInvalidCommand", err)
+	}
+
+	if err := sim.Arm(); err != nil {
+		t.Fatalf("Arm: %v", err)
+	}
+	if err := sim.TakeOff(-5); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("TakeOff(-5) = %v, want ErrInvalidCommand", err)
+	}
+	sim.state.Battery.RemainingPercent = 15
+	if err := sim.TakeOff(20); !errors.Is(err, ErrLowBattery) {
+		t.Errorf("TakeOff with low battery = %v, want ErrLowBattery", err)
+	}
+	sim.state.Battery.RemainingPercent = 80
+
+	if err := sim.TakeOff(20); err != nil {
+		t.Fatalf("TakeOff: %v", err)
+	}
+	runSimulator(t, sim, 200, func(s UAVState) bool { return s.GPS.RelativeAltitude > 10 })
+	if err := sim.Disarm(); !errors.Is(err, ErrInFlight) {
+		t.Errorf("Disarm in flight = %v, want ErrInFlight", err)
+	}
+
+	// GPS丢失后不能切换到需要定位的模式
+	sim.state.GPS.FixType = 0
+	if err := sim.SetFlightMode("LOITER"); !errors.Is(err, ErrNoGPSFix) {
+		t.Errorf("SetFlightMode(LOITER) without fix = %v, want ErrNoGPSFix", err)
+	}
+	if err := sim.ReturnToLaunch(); !errors.Is(err, ErrNoGPSFix) {
+		t.Errorf("ReturnToLaunch without fix = %v, want ErrNoGPSFix", err)
+	}
+	if err := sim.Land(); err != nil {
+		t.Fatalf("Land: %v", err)
+	}
+	landed := runSimulator(t, sim, 500, func(s UAVState) bool { return !s.Flight.Armed })
+	if landed.GPS.RelativeAltitude > landedAltitude {
+		t.Errorf("landed at %.1fm", landed.GPS.RelativeAltitude)
+	}
+	if err := sim.Disarm(); err != nil {
+		t.Errorf("Disarm after landing = %v, want nil", err)
+	}
+}
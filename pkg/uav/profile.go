@@ -0,0 +1,43 @@
+package uav
+
+import "math"
+
+// FlightProfile 飞行参数：不同机型的巡航速度、爬升和降落速度以及耗电速度
+type FlightProfile struct {
+	Name          string  `json:"name"`
+	CruiseSpeed   float64 `json:"cruise_speed"`   // 航点未指定速度和返航时的飞行速度（m/s）
+	ClimbRate     float64 `json:"climb_rate"`     // 爬升/下降速度（m/s）
+	LandRate      float64 `json:"land_rate"`      // 降落速度（m/s）
+	DischargeRate float64 `json:"discharge_rate"` // 解锁后每秒消耗的电量（%）
+}
+
+// 内置的飞行参数
+var (
+	// ProfileStandard 默认参数
+	ProfileStandard = FlightProfile{Name: "standard", CruiseSpeed: DefaultCruiseSpeed, ClimbRate: 2.5, LandRate: 1.0, DischargeRate: 0.1}
+	// ProfileSurvey 测绘机：慢速、长航时
+	ProfileSurvey = FlightProfile{Name: "survey", CruiseSpeed: 3.0, ClimbRate: 1.5, LandRate: 0.8, DischargeRate: 0.05}
+	// ProfileSprint 竞速机：高速、短航时
+	ProfileSprint = FlightProfile{Name: "sprint", CruiseSpeed: 12.0, ClimbRate: 4.0, LandRate: 1.5, DischargeRate: 0.25}
+)
+
+// FlightProfiles 内置的飞行参数，一个Agent模拟多架UAV时按序号依次使用
+var FlightProfiles = []FlightProfile{ProfileStandard, ProfileSurvey, ProfileSprint}
+
+// valid 各项速度都为正数
+func (p FlightProfile) valid() bool {
+	for _, v := range []float64{p.CruiseSpeed, p.ClimbRate, p.LandRate, p.DischargeRate} {
+		if math.IsNaN(v) || v <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Offset 向北、向东偏移给定距离（米）后的坐标
+func (p GeoPoint) Offset(north, east float64) GeoPoint {
+	return GeoPoint{
+		Latitude:  p.Latitude + north/metersPerDegree,
+		Longitude: p.Longitude + east/(metersPerDegree*math.Cos(p.Latitude*math.Pi/180)),
+	}
+}
@@ -0,0 +1,46 @@
+package uav
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimulatorOptions(t *testing.T) {
+	standard := NewMAVLinkSimulator("UAV-node-a", "node-a")
+	first := standard.GetState().GPS
+	home := GeoPoint{Latitude: first.Latitude, Longitude: first.Longitude}.Offset(0, 30)
+	sprint := NewMAVLinkSimulatorWithOptions("UAV-node-a-1", "node-a", SimulatorOptions{Home: &home, Profile: ProfileSprint})
+
+	state := sprint.GetState()
+	if state.Profile != ProfileSprint.Name || standard.GetState().Profile != ProfileStandard.Name {
+		t.Errorf("profiles = %q/%q, want %q/%q", standard.GetState().Profile, state.Profile, ProfileStandard.Name, ProfileSprint.Name)
+	}
+	if d := distanceMeters(state.GPS, first.Latitude, first.Longitude); math.Abs(d-30) > 0.01 {
+		t.Errorf("home is %.2fm from the first UAV, want 30m", d)
+	}
+
+	// 同样的起飞指令，竞速机的爬升速度更快、耗电更多
+	for _, sim := range []*MAVLinkSimulator{standard, sprint} {
+		if err := sim.Arm(); err != nil {
+			t.Fatalf("Arm: %v", err)
+		}
+		if err := sim.TakeOff(100); err != nil {
+			t.Fatalf("TakeOff: %v", err)
+		}
+		for i := 0; i < 50; i++ {
+			sim.updateState(float64(i) * sim.updateRate.Seconds())
+		}
+	}
+	slow, fast := standard.GetState(), sprint.GetState()
+	if want := ProfileSprint.ClimbRate / ProfileStandard.ClimbRate; math.Abs(fast.GPS.RelativeAltitude/slow.GPS.RelativeAltitude-want) > 0.01 {
+		t.Errorf("altitudes after 5s = %.1fm/%.1fm, want a ratio of %.1f", slow.GPS.RelativeAltitude, fast.GPS.RelativeAltitude, want)
+	}
+	if fast.Battery.RemainingPercent >= slow.Battery.RemainingPercent {
+		t.Errorf("battery = %.2f%%/%.2f%%, want the sprint profile to drain faster", slow.Battery.RemainingPercent, fast.Battery.RemainingPercent)
+	}
+
+	// 无效的飞行参数回退为默认参数
+	if got := NewMAVLinkSimulatorWithOptions("UAV-x", "node-a", SimulatorOptions{Profile: FlightProfile{Name: "broken"}}).GetState().Profile; got != ProfileStandard.Name {
+		t.Errorf("invalid profile fell back to %q, want %q", got, ProfileStandard.Name)
+	}
+}